@@ -0,0 +1,73 @@
+// Package buildinfo describes the build of the running server.
+package buildinfo
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Injected at build time, for example:
+//
+//	go build -ldflags "-X github.com/mark3labs/mcp-filesystem-server/buildinfo.version=v1.2.3
+//	  -X github.com/mark3labs/mcp-filesystem-server/buildinfo.commit=$(git rev-parse HEAD)
+//	  -X github.com/mark3labs/mcp-filesystem-server/buildinfo.date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Values that are not injected are taken from the version control
+// information the Go toolchain embeds, when available.
+var (
+	version string
+	commit  string
+	date    string
+)
+
+// Info identifies a server build
+type Info struct {
+	// Version is the release version, or "dev" for an unreleased build
+	Version string `json:"version"`
+	// Commit is the source revision the server was built from
+	Commit string `json:"commit,omitempty"`
+	// BuildDate is when the server was built or, failing that, the commit time
+	BuildDate string `json:"build_date,omitempty"`
+	// GoVersion is the Go release the server was built with
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build information of the running server
+func Get() Info {
+	info := Info{
+		Version:   version,
+		Commit:    commit,
+		BuildDate: date,
+		GoVersion: runtime.Version(),
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			info.Version = bi.Main.Version
+		}
+		for _, setting := range bi.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildDate == "":
+				info.BuildDate = setting.Value
+			}
+		}
+	}
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	return info
+}
+
+// String formats the information for --version
+func (i Info) String() string {
+	s := "mcp-filesystem-server " + i.Version
+	if i.Commit != "" {
+		s += fmt.Sprintf(" (commit %s)", i.Commit)
+	}
+	if i.BuildDate != "" {
+		s += ", built " + i.BuildDate
+	}
+	return s + " with " + i.GoVersion
+}
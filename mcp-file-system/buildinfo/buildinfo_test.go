@@ -0,0 +1,20 @@
+package buildinfo
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+	info := Get()
+	assert.NotEmpty(t, info.Version)
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+
+	// Injected values take precedence
+	version, commit, date = "v1.2.3", "0123abc", "2026-10-16T09:00:00Z"
+	t.Cleanup(func() { version, commit, date = "", "", "" })
+	assert.Equal(t, Info{Version: "v1.2.3", Commit: "0123abc", BuildDate: "2026-10-16T09:00:00Z", GoVersion: runtime.Version()}, Get())
+	assert.Equal(t, "mcp-filesystem-server v1.2.3 (commit 0123abc), built 2026-10-16T09:00:00Z with "+runtime.Version(), Get().String())
+}
@@ -0,0 +1,545 @@
+// Package config loads the optional server configuration file.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mark3labs/mcp-filesystem-server/filesystemserver"
+	"github.com/mark3labs/mcp-filesystem-server/filesystemserver/handler"
+	"github.com/mark3labs/mcp-filesystem-server/selfupdate"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPath names the environment variable holding the config file path when
+// no --config flag is given
+const EnvPath = "MCP_FS_CONFIG"
+
+// Transport types
+const (
+	TransportStdio = "stdio"
+	TransportSSE   = "sse"
+	TransportHTTP  = "http"
+)
+
+// DefaultAddress is the listen address for the sse and http transports.
+// It only accepts local connections, as the tools are unauthenticated
+// unless tenants are configured.
+const DefaultAddress = "127.0.0.1:8080"
+
+// Config is the on-disk server configuration. Settings that are not present
+// keep their defaults (including those read from MCP_FS_* variables).
+type Config struct {
+	AllowedDirectories []Directory `yaml:"allowed_directories"`
+	// ReadOnly rejects every modifying tool in all directories and SMB shares
+	ReadOnly bool `yaml:"read_only,omitempty"`
+	// DryRun makes every modifying tool report its changes without applying them
+	DryRun bool `yaml:"dry_run,omitempty"`
+	// ResponseFormat is the format of tool results when a call does not pass
+	// one: text (default) or json
+	ResponseFormat string `yaml:"response_format,omitempty"`
+	// BaseDirectory is where relative paths in tool arguments resolve; it
+	// must lie within the allowed directories
+	BaseDirectory string `yaml:"base_directory,omitempty"`
+	// Aliases name directories within the allowed directories, so tools
+	// accept paths such as "@inbox/report.pdf"
+	Aliases map[string]string `yaml:"aliases,omitempty"`
+	// Tools toggles tool groups on or off; groups other than admin are
+	// enabled by default
+	Tools         map[string]bool              `yaml:"tools,omitempty"`
+	StateDir      string                       `yaml:"state_dir,omitempty"`
+	Croc          handler.CrocConfig           `yaml:"croc,omitempty"`
+	Limits        handler.Limits               `yaml:"limits,omitempty"`
+	Tuning        handler.Tuning               `yaml:"tuning,omitempty"`
+	Logging       Logging                      `yaml:"logging,omitempty"`
+	Tracing       Tracing                      `yaml:"tracing,omitempty"`
+	Transport     Transport                    `yaml:"transport,omitempty"`
+	OSTrash       *bool                        `yaml:"os_trash,omitempty"`
+	WatchPaths    []string                     `yaml:"watch_paths,omitempty"`
+	Webhooks      []handler.WebhookConfig      `yaml:"webhooks,omitempty"`
+	Notifiers     []handler.Notifier           `yaml:"notifiers,omitempty"`
+	Backups       []handler.BackupSchedule     `yaml:"backups,omitempty"`
+	BackupRemotes map[string]string            `yaml:"backup_remotes,omitempty"`
+	SMBShares     []handler.SMBShare           `yaml:"smb_shares,omitempty"`
+	ConvertRouter *handler.ConvertRouterConfig `yaml:"convert_router,omitempty"`
+	Formatters    map[string][]string          `yaml:"formatters,omitempty"`
+	// TemplatesDir holds the directory templates instantiated by scaffold
+	TemplatesDir string `yaml:"templates_dir,omitempty"`
+	// KeepVersions is the number of earlier versions kept of files
+	// overwritten by write_file and modify_file; 0 disables versioning
+	KeepVersions int `yaml:"keep_versions,omitempty"`
+	// FileBackups sets where the backup option of write_file, modify_file,
+	// edit_lines and delete_file keeps copies, and whether it is the default
+	FileBackups *handler.FileBackupConfig `yaml:"file_backups,omitempty"`
+	// Scratch enables get_scratch_dir, giving each session a temporary
+	// directory of its own
+	Scratch *handler.ScratchConfig `yaml:"scratch,omitempty"`
+	// NextActions route tool results to the call an orchestrator should make next
+	NextActions map[string]handler.NextActionRoute `yaml:"next_actions,omitempty"`
+	// OperationLog exports every tool call to rotating JSONL files
+	OperationLog *handler.OperationLogConfig `yaml:"operation_log,omitempty"`
+	// SelfUpdate sets where self_update and --self-update find releases
+	SelfUpdate selfupdate.Config `yaml:"self_update,omitempty"`
+	// Telemetry opts in to anonymous usage statistics
+	Telemetry handler.TelemetryConfig `yaml:"telemetry,omitempty"`
+	// ExtraTools are site-specific tools served by external commands
+	ExtraTools []handler.CommandTool `yaml:"extra_tools,omitempty"`
+	// Tenants give clients of the sse and http transports their own
+	// allowed directories, selected by bearer token
+	Tenants []Tenant `yaml:"tenants,omitempty"`
+
+	// telemetry is the collector shared by the top-level and tenant servers
+	telemetry *handler.Telemetry
+}
+
+// Directory is an allowed directory and its permissions
+type Directory struct {
+	// Path is a local directory or an smb:// URL
+	Path string `yaml:"path"`
+	// ReadOnly allows reading but rejects every modifying tool
+	ReadOnly bool `yaml:"read_only,omitempty"`
+}
+
+// Tenant is a client identity with an isolated view of the filesystem. It
+// shares the other settings with the top-level configuration, except
+// backups, watch paths and smb_shares, which only apply at the top level.
+type Tenant struct {
+	Name string `yaml:"name"`
+	// Token authenticates the tenant with "Authorization: Bearer <token>"
+	Token              string      `yaml:"token"`
+	AllowedDirectories []Directory `yaml:"allowed_directories"`
+	// ReadOnly rejects every modifying tool for this tenant
+	ReadOnly bool `yaml:"read_only,omitempty"`
+	// Tools toggles tool groups on top of the top-level tools setting
+	Tools map[string]bool `yaml:"tools,omitempty"`
+	// BaseDirectory is where the tenant's relative paths resolve
+	BaseDirectory string `yaml:"base_directory,omitempty"`
+	// Aliases name directories within the tenant's allowed directories
+	Aliases map[string]string `yaml:"aliases,omitempty"`
+}
+
+// Directories returns the tenant's allowed directory paths
+func (t Tenant) Directories() []string {
+	return directoryPaths(t.AllowedDirectories)
+}
+
+// Logging configures structured log output
+type Logging struct {
+	// File receives log output instead of stderr
+	File string `yaml:"file,omitempty"`
+	// Level is debug, info (default), warn or error
+	Level string `yaml:"level,omitempty"`
+	// Format is text (default) or json
+	Format string `yaml:"format,omitempty"`
+}
+
+// NewLogger creates the slog logger described by the settings, writing to w
+func (l Logging) NewLogger(w io.Writer) (*slog.Logger, error) {
+	var level slog.Level
+	if l.Level != "" {
+		if err := level.UnmarshalText([]byte(l.Level)); err != nil {
+			return nil, fmt.Errorf("logging: invalid level %q (valid: debug, info, warn, error)", l.Level)
+		}
+	}
+	opts := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(l.Format) {
+	case "", "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("logging: unknown format %q (valid: text, json)", l.Format)
+	}
+}
+
+// Tracing configures export of OpenTelemetry spans. The standard
+// OTEL_EXPORTER_OTLP_* and OTEL_SERVICE_NAME variables are honoured as well.
+type Tracing struct {
+	// Endpoint is an OTLP/HTTP collector URL such as "http://localhost:4318"
+	Endpoint string `yaml:"endpoint,omitempty"`
+	// ServiceName overrides the reported service name (default: mcp-filesystem-server)
+	ServiceName string `yaml:"service_name,omitempty"`
+}
+
+// Transport selects how MCP clients connect
+type Transport struct {
+	// Type is stdio (default), sse or http (streamable HTTP)
+	Type string `yaml:"type,omitempty"`
+	// Address is the listen address for sse and http (default: "127.0.0.1:8080")
+	Address string `yaml:"address,omitempty"`
+	// BaseURL is the externally reachable URL advertised by the sse transport
+	BaseURL string `yaml:"base_url,omitempty"`
+	// AdminToken enables POST /admin/reload for sse and http, authenticated
+	// with "Authorization: Bearer <token>"
+	AdminToken string `yaml:"admin_token,omitempty"`
+}
+
+// Load reads a YAML (.yaml, .yml) or TOML (.toml) config file. Relative
+// directory paths are resolved against the directory of the file.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+	case ".toml":
+		// TOML is normalised to YAML so both formats share the yaml struct tags
+		var raw map[string]any
+		if _, err := toml.Decode(string(data), &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+		}
+		if data, err = yaml.Marshal(raw); err != nil {
+			return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config format %q: use .yaml, .yml or .toml", ext)
+	}
+
+	cfg := &Config{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	base := filepath.Dir(path)
+	resolveDirectories(base, cfg.AllowedDirectories)
+	cfg.BaseDirectory = resolvePath(base, cfg.BaseDirectory)
+	resolveAliases(base, cfg.Aliases)
+	for i, tenant := range cfg.Tenants {
+		resolveDirectories(base, tenant.AllowedDirectories)
+		cfg.Tenants[i].BaseDirectory = resolvePath(base, tenant.BaseDirectory)
+		resolveAliases(base, tenant.Aliases)
+	}
+	if cfg.StateDir != "" && !filepath.IsAbs(cfg.StateDir) {
+		cfg.StateDir = filepath.Join(base, cfg.StateDir)
+	}
+	if cfg.TemplatesDir != "" && !filepath.IsAbs(cfg.TemplatesDir) {
+		cfg.TemplatesDir = filepath.Join(base, cfg.TemplatesDir)
+	}
+	if cfg.OperationLog != nil && cfg.OperationLog.Dir != "" && !filepath.IsAbs(cfg.OperationLog.Dir) {
+		cfg.OperationLog.Dir = filepath.Join(base, cfg.OperationLog.Dir)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// resolvePath makes a relative path relative to base. Directory aliases
+// are left for the server to expand.
+func resolvePath(base, path string) string {
+	if path != "" && !strings.HasPrefix(path, "@") && !filepath.IsAbs(path) {
+		return filepath.Join(base, path)
+	}
+	return path
+}
+
+// resolveAliases makes relative alias targets relative to base
+func resolveAliases(base string, aliases map[string]string) {
+	for name, dir := range aliases {
+		aliases[name] = resolvePath(base, dir)
+	}
+}
+
+// resolveDirectories makes relative local directory paths relative to base
+func resolveDirectories(base string, dirs []Directory) {
+	for i, dir := range dirs {
+		if dir.Path != "" && !strings.HasPrefix(dir.Path, "smb://") && !filepath.IsAbs(dir.Path) {
+			dirs[i].Path = filepath.Join(base, dir.Path)
+		}
+	}
+}
+
+// Validate checks settings that cannot be caught while decoding
+func (c *Config) Validate() error {
+	if err := validateDirectories("allowed_directories", c.AllowedDirectories); err != nil {
+		return err
+	}
+	if err := validateTools("tools", c.Tools); err != nil {
+		return err
+	}
+	if err := validateAliases("aliases", c.Aliases); err != nil {
+		return err
+	}
+	names := make(map[string]bool, len(c.Tenants))
+	tokens := make(map[string]bool, len(c.Tenants))
+	for _, tenant := range c.Tenants {
+		if tenant.Name == "" {
+			return errors.New("tenants: name is required")
+		}
+		if names[tenant.Name] {
+			return fmt.Errorf("tenants: duplicate tenant %q", tenant.Name)
+		}
+		names[tenant.Name] = true
+		if tenant.Token == "" {
+			return fmt.Errorf("tenants.%s: token is required", tenant.Name)
+		}
+		if tokens[tenant.Token] {
+			return fmt.Errorf("tenants.%s: token is shared with another tenant", tenant.Name)
+		}
+		tokens[tenant.Token] = true
+		if len(tenant.AllowedDirectories) == 0 {
+			return fmt.Errorf("tenants.%s: allowed_directories is required", tenant.Name)
+		}
+		if err := validateDirectories("tenants."+tenant.Name+".allowed_directories", tenant.AllowedDirectories); err != nil {
+			return err
+		}
+		if err := validateTools("tenants."+tenant.Name+".tools", tenant.Tools); err != nil {
+			return err
+		}
+		if err := validateAliases("tenants."+tenant.Name+".aliases", tenant.Aliases); err != nil {
+			return err
+		}
+	}
+	if c.ResponseFormat != "" && c.ResponseFormat != handler.FormatText && c.ResponseFormat != handler.FormatJSON {
+		return fmt.Errorf("response_format: must be %q or %q, got %q", handler.FormatText, handler.FormatJSON, c.ResponseFormat)
+	}
+	if c.OperationLog != nil && c.OperationLog.Dir == "" {
+		return errors.New("operation_log: dir is required")
+	}
+	if _, err := selfupdate.New(c.SelfUpdate); err != nil {
+		return fmt.Errorf("self_update: %w", err)
+	}
+	if c.Telemetry.Enabled {
+		if u, err := url.Parse(c.Telemetry.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("telemetry: endpoint must be an http or https URL, got %q", c.Telemetry.Endpoint)
+		}
+	}
+	for _, tool := range c.ExtraTools {
+		if err := tool.Validate(); err != nil {
+			return fmt.Errorf("extra_tools: %w", err)
+		}
+	}
+	if _, err := c.Logging.NewLogger(io.Discard); err != nil {
+		return err
+	}
+	switch c.Transport.Type {
+	case "", TransportStdio, TransportSSE, TransportHTTP:
+	default:
+		return fmt.Errorf("transport: unknown type %q (valid: stdio, sse, http)", c.Transport.Type)
+	}
+	return nil
+}
+
+// validateDirectories checks a list of allowed directories
+func validateDirectories(key string, dirs []Directory) error {
+	for _, dir := range dirs {
+		if dir.Path == "" {
+			return fmt.Errorf("%s: path is required", key)
+		}
+		if dir.ReadOnly && strings.HasPrefix(dir.Path, "smb://") {
+			return fmt.Errorf("%s: read_only is not supported for SMB shares: %s", key, dir.Path)
+		}
+	}
+	return nil
+}
+
+// validateTools checks that tools only names known tool groups
+func validateTools(key string, tools map[string]bool) error {
+	for group := range tools {
+		if !slices.Contains(filesystemserver.ToolGroups, group) {
+			return fmt.Errorf("%s: unknown tool group %q (valid: %s)", key, group, strings.Join(filesystemserver.ToolGroups, ", "))
+		}
+	}
+	return nil
+}
+
+// validateAliases checks alias names; their targets are checked against the
+// allowed directories when the server starts
+func validateAliases(key string, aliases map[string]string) error {
+	for name, dir := range aliases {
+		if !handler.ValidAliasName(strings.TrimPrefix(name, "@")) {
+			return fmt.Errorf("%s: invalid alias %q: names may only contain letters, digits, '-' and '_'", key, name)
+		}
+		if dir == "" {
+			return fmt.Errorf("%s.%s: directory is required", key, name)
+		}
+	}
+	return nil
+}
+
+// Directories returns the allowed directory paths
+func (c *Config) Directories() []string {
+	return directoryPaths(c.AllowedDirectories)
+}
+
+// directoryPaths returns the paths of dirs
+func directoryPaths(dirs []Directory) []string {
+	paths := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		paths = append(paths, dir.Path)
+	}
+	return paths
+}
+
+// Options converts the configuration into NewFilesystemServer options
+func (c *Config) Options() filesystemserver.Options {
+	return c.options("", c.AllowedDirectories, c.BaseDirectory, c.Aliases, c.ReadOnly, c.Tools, true)
+}
+
+// TenantOptions converts the configuration into NewFilesystemServer options
+// for the tenant's server
+func (c *Config) TenantOptions(t Tenant) filesystemserver.Options {
+	tools := maps.Clone(c.Tools)
+	if tools == nil {
+		tools = make(map[string]bool, len(t.Tools))
+	}
+	maps.Copy(tools, t.Tools)
+	return c.options(t.Name, t.AllowedDirectories, t.BaseDirectory, t.Aliases, c.ReadOnly || t.ReadOnly, tools, false)
+}
+
+// options builds server options for the given directories and permissions.
+// Backups, watch paths and smb_shares refer to top-level locations and are
+// left out of tenant servers, which are identified by name and cannot use
+// self_update.
+func (c *Config) options(name string, dirs []Directory, baseDir string, aliases map[string]string, readOnlyMode bool, tools map[string]bool, topLevel bool) filesystemserver.Options {
+	var opts []handler.HandlerOption
+	var readOnly []string
+	for _, dir := range dirs {
+		if dir.ReadOnly {
+			readOnly = append(readOnly, dir.Path)
+		}
+	}
+	if len(readOnly) > 0 {
+		opts = append(opts, handler.WithReadOnlyDirs(readOnly))
+	}
+	if readOnlyMode {
+		opts = append(opts, handler.WithReadOnly(true))
+	}
+	if c.DryRun {
+		opts = append(opts, handler.WithDryRun(true))
+	}
+	if c.ResponseFormat != "" {
+		opts = append(opts, handler.WithResponseFormat(c.ResponseFormat))
+	}
+	if baseDir != "" {
+		opts = append(opts, handler.WithBaseDirectory(baseDir))
+	}
+	if len(aliases) > 0 {
+		opts = append(opts, handler.WithAliases(aliases))
+	}
+	switch {
+	case !topLevel:
+		// Tenants keep their backup history, journals, croc transfers and
+		// file backups apart
+		stateDir := c.StateDir
+		if stateDir == "" {
+			stateDir = handler.DefaultStateDir()
+		}
+		opts = append(opts, handler.WithStateDir(filepath.Join(stateDir, "tenants", name)))
+	case c.StateDir != "":
+		opts = append(opts, handler.WithStateDir(c.StateDir))
+	}
+	if c.Croc != (handler.CrocConfig{}) {
+		opts = append(opts, handler.WithCroc(c.Croc))
+	}
+	opts = append(opts, handler.WithLimits(c.Limits), handler.WithTuning(c.Tuning))
+	if c.OSTrash != nil {
+		opts = append(opts, handler.WithOSTrash(*c.OSTrash))
+	}
+	switch {
+	case !topLevel:
+		// Also overrides MCP_FS_WATCH_PATHS, which names top-level paths
+		opts = append(opts, handler.WithWatchPaths(nil))
+	case len(c.WatchPaths) > 0:
+		opts = append(opts, handler.WithWatchPaths(c.WatchPaths))
+	}
+	if len(c.Webhooks) > 0 {
+		opts = append(opts, handler.WithWebhooks(c.Webhooks))
+	}
+	if len(c.Notifiers) > 0 {
+		opts = append(opts, handler.WithNotifiers(c.Notifiers))
+	}
+	if topLevel && len(c.Backups) > 0 {
+		opts = append(opts, handler.WithBackupSchedules(c.Backups))
+	}
+	if len(c.BackupRemotes) > 0 {
+		opts = append(opts, handler.WithBackupRemotes(c.BackupRemotes))
+	}
+	if topLevel && len(c.SMBShares) > 0 {
+		opts = append(opts, handler.WithSMBShares(c.SMBShares))
+	}
+	if c.ConvertRouter != nil {
+		opts = append(opts, handler.WithConvertRouter(*c.ConvertRouter))
+	}
+	if len(c.Formatters) > 0 {
+		opts = append(opts, handler.WithFormatters(c.Formatters))
+	}
+	if c.TemplatesDir != "" {
+		opts = append(opts, handler.WithTemplatesDir(c.TemplatesDir))
+	}
+	if c.KeepVersions > 0 {
+		opts = append(opts, handler.WithVersioning(c.KeepVersions))
+	}
+	if c.FileBackups != nil {
+		opts = append(opts, handler.WithFileBackups(*c.FileBackups))
+	}
+	if c.Scratch != nil {
+		scratch := *c.Scratch
+		if !topLevel && filepath.IsAbs(scratch.Dir) {
+			// Tenants keep their scratch directories apart; relative ones
+			// already are, under the tenant's state directory
+			scratch.Dir = filepath.Join(scratch.Dir, name)
+		}
+		opts = append(opts, handler.WithScratch(scratch))
+	}
+	if len(c.NextActions) > 0 {
+		opts = append(opts, handler.WithNextActions(c.NextActions))
+	}
+	if c.OperationLog != nil {
+		operationLog := *c.OperationLog
+		if !topLevel {
+			// Tenants record to their own files
+			operationLog.Dir = filepath.Join(operationLog.Dir, name)
+		}
+		opts = append(opts, handler.WithOperationLog(operationLog))
+	}
+	if !topLevel {
+		// Tenants may not replace the binary they share
+		opts = append(opts, handler.WithSelfUpdate(nil))
+	} else if c.SelfUpdate != (selfupdate.Config{}) {
+		selfUpdate := c.SelfUpdate
+		opts = append(opts, handler.WithSelfUpdate(&selfUpdate))
+	}
+	if c.Telemetry.Enabled {
+		if c.telemetry == nil {
+			c.telemetry = handler.NewTelemetry(c.Telemetry)
+		}
+		opts = append(opts, handler.WithTelemetry(c.telemetry))
+	}
+
+	var disabled, enabled []string
+	for _, group := range filesystemserver.ToolGroups {
+		on, ok := tools[group]
+		switch {
+		case ok && !on:
+			disabled = append(disabled, group)
+		case on && slices.Contains(filesystemserver.OptInToolGroups, group):
+			enabled = append(enabled, group)
+		}
+	}
+	var extra []filesystemserver.ExtraTool
+	for _, tool := range c.ExtraTools {
+		extra = append(extra, filesystemserver.NewCommandTool(tool))
+	}
+	return filesystemserver.Options{
+		HandlerOptions:     opts,
+		DisabledToolGroups: disabled,
+		EnabledToolGroups:  enabled,
+		ExtraTools:         extra,
+	}
+}
@@ -0,0 +1,367 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-filesystem-server/filesystemserver"
+	"github.com/mark3labs/mcp-filesystem-server/filesystemserver/handler"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoadYAML(t *testing.T) {
+	path := writeConfig(t, "server.yaml", `
+allowed_directories:
+  - path: /srv/data
+  - path: docs
+    read_only: true
+tools:
+  croc: false
+  backup: true
+croc:
+  relay: relay.example.com:9009
+limits:
+  max_inline_size: 1048576
+logging:
+  file: /var/log/mcp-fs.log
+  level: debug
+  format: json
+transport:
+  type: http
+  address: 127.0.0.1:9000
+extra_tools:
+  - name: checksum
+    command: [sha256sum]
+    timeout: 5s
+    arguments:
+      - name: path
+        path: true
+        required: true
+`)
+	cfg, err := Load(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"/srv/data", filepath.Join(filepath.Dir(path), "docs")}, cfg.Directories())
+	assert.True(t, cfg.AllowedDirectories[1].ReadOnly)
+	assert.Equal(t, "relay.example.com:9009", cfg.Croc.Relay)
+	assert.Equal(t, int64(1048576), cfg.Limits.MaxInlineSize)
+	assert.Equal(t, "/var/log/mcp-fs.log", cfg.Logging.File)
+	assert.Equal(t, TransportHTTP, cfg.Transport.Type)
+	assert.Equal(t, "127.0.0.1:9000", cfg.Transport.Address)
+
+	require.Len(t, cfg.ExtraTools, 1)
+	assert.Equal(t, 5*time.Second, cfg.ExtraTools[0].Timeout)
+
+	opts := cfg.Options()
+	assert.Equal(t, []string{filesystemserver.ToolGroupCroc}, opts.DisabledToolGroups)
+	assert.NotEmpty(t, opts.HandlerOptions)
+	require.Len(t, opts.ExtraTools, 1)
+	assert.Equal(t, "checksum", opts.ExtraTools[0].Tool.Name)
+}
+
+func TestLoadTOML(t *testing.T) {
+	path := writeConfig(t, "server.toml", `
+[[allowed_directories]]
+path = "/srv/data"
+read_only = true
+
+[tools]
+write = false
+
+[transport]
+type = "sse"
+base_url = "https://fs.example.com"
+
+[[webhooks]]
+url = "https://hooks.example.com/fs"
+events = ["croc.*"]
+`)
+	cfg, err := Load(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Directory{{Path: "/srv/data", ReadOnly: true}}, cfg.AllowedDirectories)
+	assert.Equal(t, TransportSSE, cfg.Transport.Type)
+	assert.Equal(t, "https://fs.example.com", cfg.Transport.BaseURL)
+	require.Len(t, cfg.Webhooks, 1)
+	assert.Equal(t, []string{"croc.*"}, cfg.Webhooks[0].Events)
+	assert.Equal(t, []string{filesystemserver.ToolGroupWrite}, cfg.Options().DisabledToolGroups)
+}
+
+func TestLoadRejectsInvalidConfig(t *testing.T) {
+	for name, content := range map[string]string{
+		"unknown key":    "allowed_directorys:\n  - path: /srv\n",
+		"unknown group":  "tools:\n  shell: false\n",
+		"bad transport":  "transport:\n  type: websocket\n",
+		"bad log level":  "logging:\n  level: verbose\n",
+		"bad log format": "logging:\n  format: xml\n",
+		"smb read only":  "allowed_directories:\n  - path: smb://nas/share\n    read_only: true\n",
+		"tool command":   "extra_tools:\n  - name: checksum\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, "server.yml", content))
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := Load(writeConfig(t, "server.json", "{}"))
+	assert.ErrorContains(t, err, "unsupported config format")
+}
+
+func TestTenants(t *testing.T) {
+	path := writeConfig(t, "server.yaml", `
+allowed_directories:
+  - path: /srv/shared
+tools:
+  croc: false
+watch_paths: [/srv/shared]
+tenants:
+  - name: alice
+    token: alice-token
+    allowed_directories:
+      - path: alice
+      - path: /srv/reference
+        read_only: true
+    tools:
+      write: false
+  - name: bob
+    token: bob-token
+    read_only: true
+    allowed_directories:
+      - path: /srv/bob
+transport:
+  type: http
+`)
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, cfg.Tenants, 2)
+	assert.Equal(t, []string{filepath.Join(filepath.Dir(path), "alice"), "/srv/reference"}, cfg.Tenants[0].Directories())
+
+	opts := cfg.TenantOptions(cfg.Tenants[0])
+	assert.ElementsMatch(t, []string{filesystemserver.ToolGroupCroc, filesystemserver.ToolGroupWrite}, opts.DisabledToolGroups)
+	// The top-level tools setting is not modified by the tenant's
+	assert.Equal(t, []string{filesystemserver.ToolGroupCroc}, cfg.Options().DisabledToolGroups)
+
+	for name, content := range map[string]string{
+		"missing token":     "tenants:\n  - name: a\n    allowed_directories: [{path: /a}]\n",
+		"missing dirs":      "tenants:\n  - name: a\n    token: t\n",
+		"duplicate name":    "tenants:\n  - {name: a, token: t1, allowed_directories: [{path: /a}]}\n  - {name: a, token: t2, allowed_directories: [{path: /b}]}\n",
+		"shared token":      "tenants:\n  - {name: a, token: t, allowed_directories: [{path: /a}]}\n  - {name: b, token: t, allowed_directories: [{path: /b}]}\n",
+		"unknown tool":      "tenants:\n  - {name: a, token: t, allowed_directories: [{path: /a}], tools: {shell: false}}\n",
+		"smb read only dir": "tenants:\n  - {name: a, token: t, allowed_directories: [{path: smb://nas/a, read_only: true}]}\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, "server.yaml", content))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestTenantState(t *testing.T) {
+	stateDir := t.TempDir()
+	path := writeConfig(t, "server.yaml", `
+allowed_directories:
+  - path: /srv/shared
+state_dir: `+stateDir+`
+tenants:
+  - name: alice
+    token: alice-token
+    allowed_directories:
+      - path: /srv/alice
+  - name: bob
+    token: bob-token
+    allowed_directories:
+      - path: /srv/bob
+`)
+	cfg, err := Load(path)
+	require.NoError(t, err)
+
+	// Each tenant's backup runs are recorded under its own state directory
+	for dir, source := range map[string]string{
+		stateDir: "/srv/shared",
+		filepath.Join(stateDir, "tenants", "alice"): "/srv/alice",
+	} {
+		require.NoError(t, os.MkdirAll(dir, 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "backup_history.jsonl"), []byte(`{"id":"run","source":"`+source+`"}`+"\n"), 0600))
+	}
+	history := func(opts filesystemserver.Options) string {
+		h, err := handler.NewFilesystemHandler([]string{t.TempDir()}, opts.HandlerOptions...)
+		require.NoError(t, err)
+		res, err := h.HandleBackupHistory(context.Background(), mcp.CallToolRequest{})
+		require.NoError(t, err)
+		return res.Content[0].(mcp.TextContent).Text
+	}
+	assert.Contains(t, history(cfg.Options()), "/srv/shared")
+	alice := history(cfg.TenantOptions(cfg.Tenants[0]))
+	assert.Contains(t, alice, "/srv/alice")
+	assert.NotContains(t, alice, "/srv/shared")
+	assert.Equal(t, "No backups recorded.", history(cfg.TenantOptions(cfg.Tenants[1])))
+}
+
+func TestTenantJournals(t *testing.T) {
+	path := writeConfig(t, "server.yaml", `
+state_dir: `+t.TempDir()+`
+tenants:
+  - name: alice
+    token: alice-token
+    allowed_directories:
+      - path: /srv/alice
+  - name: bob
+    token: bob-token
+    allowed_directories:
+      - path: /srv/bob
+`)
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	call := func(tenant Tenant, fn func(*handler.FilesystemHandler) handler.ToolHandlerFunc, args map[string]any) string {
+		h, err := handler.NewFilesystemHandler([]string{t.TempDir()}, cfg.TenantOptions(tenant).HandlerOptions...)
+		require.NoError(t, err)
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		res, err := fn(h)(context.Background(), request)
+		require.NoError(t, err)
+		return res.Content[0].(mcp.TextContent).Text
+	}
+	appendEntry := func(h *handler.FilesystemHandler) handler.ToolHandlerFunc { return h.HandleJournalAppend }
+	read := func(h *handler.FilesystemHandler) handler.ToolHandlerFunc { return h.HandleJournalRead }
+
+	call(cfg.Tenants[0], appendEntry, map[string]any{"journal": "plan", "message": "alice's secret step"})
+	assert.Contains(t, call(cfg.Tenants[0], read, map[string]any{"journal": "plan"}), "alice's secret step")
+	// The same journal name reaches a different journal for bob
+	assert.NotContains(t, call(cfg.Tenants[1], read, map[string]any{"journal": "plan"}), "alice's secret step")
+}
+
+func TestOperationLog(t *testing.T) {
+	path := writeConfig(t, "server.yaml", `
+allowed_directories:
+  - path: /srv/staging
+tools:
+  admin: true
+operation_log:
+  dir: operations
+  max_files: 3
+tenants:
+  - name: alice
+    token: alice-token
+    allowed_directories:
+      - path: /srv/alice
+`)
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	require.NotNil(t, cfg.OperationLog)
+	assert.Equal(t, filepath.Join(filepath.Dir(path), "operations"), cfg.OperationLog.Dir)
+	assert.Equal(t, []string{filesystemserver.ToolGroupAdmin}, cfg.Options().EnabledToolGroups)
+	assert.Empty(t, cfg.Options().DisabledToolGroups)
+
+	_, err = Load(writeConfig(t, "server.yaml", "operation_log:\n  max_files: 3\n"))
+	assert.Error(t, err)
+}
+
+func TestTuning(t *testing.T) {
+	cfg, err := Load(writeConfig(t, "server.yaml", `
+allowed_directories:
+  - path: /srv
+tuning:
+  tool_timeout: 2m
+  tool_timeouts:
+    convert_to_markdown: 30m
+  max_concurrent_calls: 8
+  walker_parallelism: 2
+  croc_receive_timeout: 1h
+`))
+	require.NoError(t, err)
+	assert.Equal(t, handler.Tuning{
+		ToolTimeout:        2 * time.Minute,
+		ToolTimeouts:       map[string]time.Duration{"convert_to_markdown": 30 * time.Minute},
+		MaxConcurrentCalls: 8,
+		WalkerParallelism:  2,
+		CrocReceiveTimeout: time.Hour,
+	}, cfg.Tuning)
+}
+
+func TestTelemetry(t *testing.T) {
+	t.Setenv("DO_NOT_TRACK", "")
+	_, err := Load(writeConfig(t, "server.yaml", "allowed_directories:\n  - path: /srv\ntelemetry:\n  enabled: true\n"))
+	assert.ErrorContains(t, err, "telemetry: endpoint must be an http or https URL")
+
+	cfg, err := Load(writeConfig(t, "server.yaml", "allowed_directories:\n  - path: /srv\ntelemetry:\n  enabled: true\n  endpoint: https://telemetry.example.com/v1/usage\n  interval: 12h\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 12*time.Hour, cfg.Telemetry.Interval)
+	// Tenants share one collector with the top-level server
+	cfg.Options()
+	require.NotNil(t, cfg.telemetry)
+	first := cfg.telemetry
+	cfg.TenantOptions(Tenant{Name: "alice"})
+	assert.Same(t, first, cfg.telemetry)
+}
+
+func TestResponseFormat(t *testing.T) {
+	_, err := Load(writeConfig(t, "server.yaml", "allowed_directories:\n  - path: /srv\nresponse_format: xml\n"))
+	assert.ErrorContains(t, err, `response_format: must be "text" or "json"`)
+
+	cfg, err := Load(writeConfig(t, "server.yaml", "allowed_directories:\n  - path: /srv\nresponse_format: json\n"))
+	require.NoError(t, err)
+	assert.Equal(t, handler.FormatJSON, cfg.ResponseFormat)
+}
+
+func TestBaseDirectory(t *testing.T) {
+	path := writeConfig(t, "server.yaml", `allowed_directories:
+  - path: /srv
+base_directory: srv/project
+tenants:
+  - name: alice
+    token: secret
+    allowed_directories:
+      - path: /home/alice
+    base_directory: /home/alice/work
+`)
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(filepath.Dir(path), "srv/project"), cfg.BaseDirectory)
+	assert.Equal(t, "/home/alice/work", cfg.Tenants[0].BaseDirectory)
+}
+
+func TestAliases(t *testing.T) {
+	_, err := Load(writeConfig(t, "server.yaml", "allowed_directories:\n  - path: /srv\naliases:\n  in/box: /srv/inbox\n"))
+	assert.ErrorContains(t, err, `aliases: invalid alias "in/box"`)
+
+	path := writeConfig(t, "server.yaml", `allowed_directories:
+  - path: /srv
+aliases:
+  "@inbox": /srv/mineru/inbox
+  projects: projects
+base_directory: "@projects"
+tenants:
+  - name: alice
+    token: secret
+    allowed_directories:
+      - path: /home/alice
+    aliases:
+      work: /home/alice/work
+`)
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"@inbox": "/srv/mineru/inbox", "projects": filepath.Join(filepath.Dir(path), "projects")}, cfg.Aliases)
+	assert.Equal(t, "@projects", cfg.BaseDirectory)
+	assert.Equal(t, map[string]string{"work": "/home/alice/work"}, cfg.Tenants[0].Aliases)
+}
+
+func TestSelfUpdate(t *testing.T) {
+	_, err := Load(writeConfig(t, "server.yaml", "allowed_directories:\n  - path: /srv\nself_update:\n  public_key: not-a-key\n"))
+	assert.ErrorContains(t, err, "self_update: public_key must be a base64 Ed25519 public key")
+
+	cfg, err := Load(writeConfig(t, "server.yaml", "allowed_directories:\n  - path: /srv\nself_update:\n  repository: example/mcp-filesystem-server\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "example/mcp-filesystem-server", cfg.SelfUpdate.Repository)
+}
@@ -0,0 +1,279 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-filesystem-server/filesystemserver/handler"
+)
+
+// Environment variables read by ApplyEnv. Handler features that predate the
+// config file (webhooks, notifications, SMB credentials, ...) read their own
+// MCP_FS_* variables directly.
+const (
+	// EnvAllowedDirs lists allowed directories, separated like PATH
+	EnvAllowedDirs = "MCP_FS_ALLOWED_DIRS"
+	// EnvReadOnlyDirs lists read-only allowed directories, separated like PATH
+	EnvReadOnlyDirs = "MCP_FS_READ_ONLY_DIRS"
+	// EnvReadOnly puts the whole server in read-only mode
+	EnvReadOnly = "MCP_FS_READ_ONLY"
+	// EnvBaseDir is where relative paths in tool arguments resolve
+	EnvBaseDir = "MCP_FS_BASE_DIR"
+	// EnvAliases lists name=directory aliases, separated like PATH
+	EnvAliases = "MCP_FS_ALIASES"
+	// EnvDryRun makes modifying tools report their changes without applying them
+	EnvDryRun = "MCP_FS_DRY_RUN"
+	// EnvResponseFormat sets the default format of tool results: text or json
+	EnvResponseFormat = "MCP_FS_RESPONSE_FORMAT"
+	// EnvDisabledTools lists comma separated tool groups to disable
+	EnvDisabledTools = "MCP_FS_DISABLED_TOOLS"
+	// EnvEnabledTools lists comma separated opt-in tool groups to enable
+	EnvEnabledTools = "MCP_FS_ENABLED_TOOLS"
+	// EnvOperationLogDir enables the operation log in the given directory
+	EnvOperationLogDir = "MCP_FS_OPERATION_LOG_DIR"
+	// EnvTemplatesDir holds the directory templates instantiated by scaffold
+	EnvTemplatesDir = "MCP_FS_TEMPLATES_DIR"
+	// EnvKeepVersions keeps this many earlier versions of overwritten files
+	EnvKeepVersions = "MCP_FS_KEEP_VERSIONS"
+	// EnvScratchDir enables per-session scratch directories in the given directory
+	EnvScratchDir = "MCP_FS_SCRATCH_DIR"
+	// EnvFileBackupDir keeps the backups made by the backup option in the
+	// given directory instead of next to each file
+	EnvFileBackupDir = "MCP_FS_FILE_BACKUP_DIR"
+	// EnvTelemetry opts in to anonymous usage statistics
+	EnvTelemetry = "MCP_FS_TELEMETRY"
+	// EnvTelemetryEndpoint receives the usage statistics
+	EnvTelemetryEndpoint = "MCP_FS_TELEMETRY_ENDPOINT"
+
+	EnvCrocBinary        = "MCP_FS_CROC_BINARY"
+	EnvCrocRelay         = "MCP_FS_CROC_RELAY"
+	EnvCrocRelayPassword = "MCP_FS_CROC_RELAY_PASSWORD"
+
+	EnvMaxInlineSize     = "MCP_FS_MAX_INLINE_SIZE"
+	EnvMaxBase64Size     = "MCP_FS_MAX_BASE64_SIZE"
+	EnvMaxSearchResults  = "MCP_FS_MAX_SEARCH_RESULTS"
+	EnvMaxSearchableSize = "MCP_FS_MAX_SEARCHABLE_SIZE"
+	EnvMaxResponseSize   = "MCP_FS_MAX_RESPONSE_SIZE"
+	EnvMaxBatchReadSize  = "MCP_FS_MAX_BATCH_READ_SIZE"
+
+	EnvToolTimeout        = "MCP_FS_TOOL_TIMEOUT"
+	EnvMaxConcurrentCalls = "MCP_FS_MAX_CONCURRENT_CALLS"
+	EnvWalkerParallelism  = "MCP_FS_WALKER_PARALLELISM"
+	EnvCrocReceiveTimeout = "MCP_FS_CROC_RECEIVE_TIMEOUT"
+
+	EnvLogFile   = "MCP_FS_LOG_FILE"
+	EnvLogLevel  = "MCP_FS_LOG_LEVEL"
+	EnvLogFormat = "MCP_FS_LOG_FORMAT"
+
+	EnvTransport = "MCP_FS_TRANSPORT"
+	EnvAddress   = "MCP_FS_ADDRESS"
+	EnvBaseURL   = "MCP_FS_BASE_URL"
+	// EnvAdminToken enables the authenticated reload endpoint in HTTP modes
+	EnvAdminToken = "MCP_FS_ADMIN_TOKEN"
+)
+
+// ApplyEnv overlays settings from MCP_FS_* environment variables, which take
+// precedence over the config file. Directories from the environment are added
+// to those already configured.
+func (c *Config) ApplyEnv() error {
+	for _, dir := range splitList(os.Getenv(EnvAllowedDirs)) {
+		c.AllowedDirectories = append(c.AllowedDirectories, Directory{Path: dir})
+	}
+	for _, dir := range splitList(os.Getenv(EnvReadOnlyDirs)) {
+		c.AllowedDirectories = append(c.AllowedDirectories, Directory{Path: dir, ReadOnly: true})
+	}
+	if err := envBool(EnvReadOnly, &c.ReadOnly); err != nil {
+		return err
+	}
+	if err := envBool(EnvDryRun, &c.DryRun); err != nil {
+		return err
+	}
+	if dir := os.Getenv(EnvBaseDir); dir != "" {
+		c.BaseDirectory = dir
+	}
+	for _, alias := range splitList(os.Getenv(EnvAliases)) {
+		name, dir, ok := strings.Cut(alias, "=")
+		if !ok || name == "" || dir == "" {
+			return fmt.Errorf("%s: expected name=directory, got %q", EnvAliases, alias)
+		}
+		if c.Aliases == nil {
+			c.Aliases = make(map[string]string)
+		}
+		c.Aliases[strings.TrimPrefix(name, "@")] = dir
+	}
+	if format := os.Getenv(EnvResponseFormat); format != "" {
+		if format != handler.FormatText && format != handler.FormatJSON {
+			return fmt.Errorf("%s: must be %q or %q, got %q", EnvResponseFormat, handler.FormatText, handler.FormatJSON, format)
+		}
+		c.ResponseFormat = format
+	}
+	for _, toggle := range []struct {
+		name    string
+		enabled bool
+	}{
+		{EnvEnabledTools, true},
+		{EnvDisabledTools, false},
+	} {
+		raw := os.Getenv(toggle.name)
+		if raw == "" {
+			continue
+		}
+		if c.Tools == nil {
+			c.Tools = make(map[string]bool)
+		}
+		for _, group := range strings.Split(raw, ",") {
+			if group = strings.TrimSpace(group); group != "" {
+				c.Tools[group] = toggle.enabled
+			}
+		}
+	}
+	if dir := os.Getenv(EnvOperationLogDir); dir != "" {
+		if c.OperationLog == nil {
+			c.OperationLog = &handler.OperationLogConfig{}
+		}
+		c.OperationLog.Dir = dir
+	}
+	envString(EnvTemplatesDir, &c.TemplatesDir)
+	if dir := os.Getenv(EnvScratchDir); dir != "" {
+		if c.Scratch == nil {
+			c.Scratch = &handler.ScratchConfig{}
+		}
+		c.Scratch.Dir = dir
+	}
+	if dir := os.Getenv(EnvFileBackupDir); dir != "" {
+		if c.FileBackups == nil {
+			c.FileBackups = &handler.FileBackupConfig{}
+		}
+		c.FileBackups.Dir = dir
+	}
+	if err := envBool(EnvTelemetry, &c.Telemetry.Enabled); err != nil {
+		return err
+	}
+	envString(EnvTelemetryEndpoint, &c.Telemetry.Endpoint)
+
+	envString(EnvCrocBinary, &c.Croc.Binary)
+	envString(EnvCrocRelay, &c.Croc.Relay)
+	envString(EnvCrocRelayPassword, &c.Croc.RelayPassword)
+
+	for _, limit := range []struct {
+		name  string
+		value *int64
+	}{
+		{EnvMaxInlineSize, &c.Limits.MaxInlineSize},
+		{EnvMaxBase64Size, &c.Limits.MaxBase64Size},
+		{EnvMaxSearchableSize, &c.Limits.MaxSearchableSize},
+		{EnvMaxResponseSize, &c.Limits.MaxResponseSize},
+		{EnvMaxBatchReadSize, &c.Limits.MaxBatchReadSize},
+	} {
+		if err := envInt64(limit.name, limit.value); err != nil {
+			return err
+		}
+	}
+	for _, n := range []struct {
+		name  string
+		value *int
+	}{
+		{EnvMaxSearchResults, &c.Limits.MaxSearchResults},
+		{EnvMaxConcurrentCalls, &c.Tuning.MaxConcurrentCalls},
+		{EnvWalkerParallelism, &c.Tuning.WalkerParallelism},
+		{EnvKeepVersions, &c.KeepVersions},
+	} {
+		if err := envInt(n.name, n.value); err != nil {
+			return err
+		}
+	}
+	if err := envDuration(EnvToolTimeout, &c.Tuning.ToolTimeout); err != nil {
+		return err
+	}
+	if err := envDuration(EnvCrocReceiveTimeout, &c.Tuning.CrocReceiveTimeout); err != nil {
+		return err
+	}
+
+	envString(EnvLogFile, &c.Logging.File)
+	envString(EnvLogLevel, &c.Logging.Level)
+	envString(EnvLogFormat, &c.Logging.Format)
+	envString(EnvTransport, &c.Transport.Type)
+	envString(EnvAddress, &c.Transport.Address)
+	envString(EnvBaseURL, &c.Transport.BaseURL)
+	envString(EnvAdminToken, &c.Transport.AdminToken)
+
+	return c.Validate()
+}
+
+// splitList splits a PATH-style list, dropping empty entries
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range filepath.SplitList(raw) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// envString sets *dst when the variable is non-empty
+func envString(name string, dst *string) {
+	if v := os.Getenv(name); v != "" {
+		*dst = v
+	}
+}
+
+// envBool sets *dst when the variable holds a boolean
+func envBool(name string, dst *bool) error {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return nil
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		return fmt.Errorf("%s: expected true or false, got %q", name, raw)
+	}
+	*dst = v
+	return nil
+}
+
+// envInt sets *dst when the variable holds a positive integer
+func envInt(name string, dst *int) error {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil || v <= 0 {
+		return fmt.Errorf("%s: expected a positive integer, got %q", name, raw)
+	}
+	*dst = v
+	return nil
+}
+
+// envDuration sets *dst when the variable holds a positive duration such as "90s"
+func envDuration(name string, dst *time.Duration) error {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return nil
+	}
+	v, err := time.ParseDuration(raw)
+	if err != nil || v <= 0 {
+		return fmt.Errorf("%s: expected a positive duration such as 90s, got %q", name, raw)
+	}
+	*dst = v
+	return nil
+}
+
+// envInt64 sets *dst when the variable holds a positive integer
+func envInt64(name string, dst *int64) error {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return nil
+	}
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || v <= 0 {
+		return fmt.Errorf("%s: expected a positive integer, got %q", name, raw)
+	}
+	*dst = v
+	return nil
+}
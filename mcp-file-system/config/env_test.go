@@ -0,0 +1,91 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-filesystem-server/filesystemserver"
+	"github.com/mark3labs/mcp-filesystem-server/filesystemserver/handler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyEnv(t *testing.T) {
+	t.Setenv(EnvAllowedDirs, strings.Join([]string{"/srv/a", "/srv/b"}, string(os.PathListSeparator)))
+	t.Setenv(EnvReadOnlyDirs, "/srv/ref")
+	t.Setenv(EnvReadOnly, "true")
+	t.Setenv(EnvDryRun, "1")
+	t.Setenv(EnvResponseFormat, "json")
+	t.Setenv(EnvBaseDir, "/srv/a/project")
+	t.Setenv(EnvAliases, strings.Join([]string{"inbox=/srv/a/inbox", "@ref=/srv/ref"}, string(os.PathListSeparator)))
+	t.Setenv(EnvDisabledTools, "croc, backup")
+	t.Setenv(EnvEnabledTools, "admin")
+	t.Setenv(EnvOperationLogDir, "/var/log/mcp-fs")
+	t.Setenv(EnvTemplatesDir, "/srv/templates")
+	t.Setenv(EnvKeepVersions, "5")
+	t.Setenv(EnvScratchDir, "/tmp/mcp-scratch")
+	t.Setenv(EnvFileBackupDir, "/var/backups/mcp")
+	t.Setenv(EnvCrocRelay, "relay.internal:9009")
+	t.Setenv(EnvMaxInlineSize, "2048")
+	t.Setenv(EnvMaxSearchResults, "50")
+	t.Setenv(EnvTransport, "sse")
+	t.Setenv(EnvToolTimeout, "90s")
+	t.Setenv(EnvMaxConcurrentCalls, "4")
+	t.Setenv(EnvTelemetry, "true")
+	t.Setenv(EnvTelemetryEndpoint, "https://telemetry.example.com/v1/usage")
+
+	// Environment settings override the file and add to its directories
+	path := filepath.Join(t.TempDir(), "server.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("allowed_directories:\n  - path: /srv/base\ncroc:\n  relay: relay.example.com:9009\n"), 0644))
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	require.NoError(t, cfg.ApplyEnv())
+
+	assert.Equal(t, []Directory{
+		{Path: "/srv/base"},
+		{Path: "/srv/a"},
+		{Path: "/srv/b"},
+		{Path: "/srv/ref", ReadOnly: true},
+	}, cfg.AllowedDirectories)
+	assert.True(t, cfg.ReadOnly)
+	assert.True(t, cfg.DryRun)
+	assert.Equal(t, handler.FormatJSON, cfg.ResponseFormat)
+	assert.Equal(t, "/srv/a/project", cfg.BaseDirectory)
+	assert.Equal(t, map[string]string{"inbox": "/srv/a/inbox", "ref": "/srv/ref"}, cfg.Aliases)
+	assert.Equal(t, "relay.internal:9009", cfg.Croc.Relay)
+	assert.Equal(t, int64(2048), cfg.Limits.MaxInlineSize)
+	assert.Equal(t, 50, cfg.Limits.MaxSearchResults)
+	assert.Equal(t, TransportSSE, cfg.Transport.Type)
+	assert.Equal(t, []string{filesystemserver.ToolGroupCroc, filesystemserver.ToolGroupBackup}, cfg.Options().DisabledToolGroups)
+	assert.Equal(t, []string{filesystemserver.ToolGroupAdmin}, cfg.Options().EnabledToolGroups)
+	assert.Equal(t, "/var/log/mcp-fs", cfg.OperationLog.Dir)
+	assert.Equal(t, "/srv/templates", cfg.TemplatesDir)
+	assert.Equal(t, 5, cfg.KeepVersions)
+	assert.Equal(t, "/tmp/mcp-scratch", cfg.Scratch.Dir)
+	assert.Equal(t, "/var/backups/mcp", cfg.FileBackups.Dir)
+	assert.Equal(t, 90*time.Second, cfg.Tuning.ToolTimeout)
+	assert.Equal(t, 4, cfg.Tuning.MaxConcurrentCalls)
+	assert.Equal(t, handler.TelemetryConfig{Enabled: true, Endpoint: "https://telemetry.example.com/v1/usage"}, cfg.Telemetry)
+}
+
+func TestApplyEnvRejectsInvalidValues(t *testing.T) {
+	for name, value := range map[string]string{
+		EnvReadOnly:         "sometimes",
+		EnvMaxBase64Size:    "1MB",
+		EnvMaxSearchResults: "-1",
+		EnvTransport:        "grpc",
+		EnvToolTimeout:      "soon",
+		EnvTelemetry:        "maybe",
+		EnvResponseFormat:   "xml",
+		EnvAliases:          "inbox",
+		EnvDisabledTools:    "shell",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			assert.Error(t, (&Config{}).ApplyEnv())
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package filesystemserver
+
+import (
+	"github.com/mark3labs/mcp-filesystem-server/filesystemserver/handler"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ExtraTool is a site-specific tool registered alongside the built-in tools
+// in the ToolGroupExtra group
+type ExtraTool struct {
+	Tool mcp.Tool
+	// Handler builds the tool's handler. It receives the server's
+	// FilesystemHandler so paths can be confined with ValidatePath and
+	// ValidateWritablePath.
+	Handler func(h *handler.FilesystemHandler) handler.ToolHandlerFunc
+}
+
+// WithExtraTools registers additional tools. Calls are logged, traced and
+// drained on shutdown like those of the built-in tools.
+func WithExtraTools(tools ...ExtraTool) Option {
+	return func(o *Options) {
+		o.ExtraTools = append(o.ExtraTools, tools...)
+	}
+}
+
+// NewCommandTool adapts a tool served by an external process
+func NewCommandTool(c handler.CommandTool) ExtraTool {
+	return ExtraTool{
+		Tool: c.MCPTool(),
+		Handler: func(h *handler.FilesystemHandler) handler.ToolHandlerFunc {
+			return h.HandleCommandTool(c)
+		},
+	}
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// aliasPrefix starts a directory alias in a path argument, as in "@inbox/report.pdf"
+const aliasPrefix = "@"
+
+// aliasNamePattern restricts alias names so they cannot be confused with paths
+var aliasNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// WithAliases names directories so prompts can refer to them as "@name"
+// regardless of where a deployment keeps them. Keys may be given with or
+// without the leading "@"; every target must lie within the allowed
+// directories.
+func WithAliases(aliases map[string]string) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		fs.aliases = make(map[string]string, len(aliases))
+		for name, dir := range aliases {
+			fs.aliases[strings.TrimPrefix(name, aliasPrefix)] = dir
+		}
+	}
+}
+
+// ValidAliasName reports whether name (without "@") can be used as an alias
+func ValidAliasName(name string) bool {
+	return aliasNamePattern.MatchString(name)
+}
+
+// validateAliases checks the alias names and resolves their targets
+func (fs *FilesystemHandler) validateAliases() error {
+	for name, dir := range fs.aliases {
+		if !ValidAliasName(name) {
+			return fmt.Errorf("alias %q: names may only contain letters, digits, '-' and '_'", name)
+		}
+		validPath, err := fs.validatePath(dir)
+		if err != nil {
+			return fmt.Errorf("alias %s%s: %w", aliasPrefix, name, err)
+		}
+		if info, err := os.Stat(validPath); err != nil || !info.IsDir() {
+			return fmt.Errorf("alias %s%s: not a directory: %s", aliasPrefix, name, validPath)
+		}
+		fs.aliases[name] = validPath
+	}
+	return nil
+}
+
+// currentAliases returns the configured aliases
+func (fs *FilesystemHandler) currentAliases() map[string]string {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	return fs.aliases
+}
+
+// expandAlias replaces the "@name" that path starts with by the directory
+// it names
+func (fs *FilesystemHandler) expandAlias(path string) (string, error) {
+	name, rest := strings.TrimPrefix(path, aliasPrefix), ""
+	if i := strings.IndexAny(name, "/"+string(filepath.Separator)); i >= 0 {
+		name, rest = name[:i], name[i+1:]
+	}
+	dir, ok := fs.currentAliases()[name]
+	if !ok {
+		return "", toolError(CodeNotFound, "unknown directory alias %s%s", aliasPrefix, name).With("alias", aliasPrefix+name)
+	}
+	return filepath.Join(dir, rest), nil
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAliases(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	inbox := filepath.Join(dir, "mineru", "inbox")
+	require.NoError(t, os.MkdirAll(inbox, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(inbox, "report.txt"), []byte("report"), 0644))
+
+	_, err := NewFilesystemHandler([]string{dir}, WithAliases(map[string]string{"outside": t.TempDir()}))
+	assert.ErrorContains(t, err, "alias @outside: access denied")
+	_, err = NewFilesystemHandler([]string{dir}, WithAliases(map[string]string{"../up": inbox}))
+	assert.ErrorContains(t, err, `alias "../up"`)
+
+	fsHandler, err := NewFilesystemHandler([]string{dir},
+		WithAliases(map[string]string{"@inbox": inbox, "root": dir}),
+		WithBaseDirectory("@inbox"),
+	)
+	require.NoError(t, err)
+	read := fsHandler.ResolveToolCall(fsHandler.HandleReadFile)
+
+	for _, path := range []string{"@inbox/report.txt", "@root/mineru/inbox/report.txt", "report.txt"} {
+		res := callTool(t, read, map[string]any{"path": path})
+		require.False(t, res.IsError, path)
+		assert.Equal(t, "report", res.Content[0].(mcp.TextContent).Text)
+	}
+
+	res := callTool(t, read, map[string]any{"path": "@outbox/report.txt"})
+	te := ResultError(res)
+	assert.Equal(t, CodeNotFound, te.Code)
+	assert.Equal(t, map[string]any{"alias": "@outbox"}, te.Details)
+
+	res = callTool(t, read, map[string]any{"path": "@inbox/../../../../etc/passwd"})
+	assert.Equal(t, CodePathNotAllowed, ResultError(res).Code)
+
+	list := fsHandler.FormatToolCall(fsHandler.HandleListAllowedDirectories)
+	res = callTool(t, list, nil)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "@inbox -> "+inbox)
+	res = callTool(t, list, map[string]any{"format": FormatJSON})
+	var dirs AllowedDirectories
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &dirs))
+	assert.Equal(t, map[string]string{"@inbox": inbox, "@root": dir}, dirs.Aliases)
+	assert.Equal(t, inbox, dirs.BaseDirectory)
+}
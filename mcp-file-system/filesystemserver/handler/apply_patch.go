@@ -0,0 +1,557 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// defaultPatchFuzz and maxPatchFuzz bound how many context lines at
+	// either end of a hunk may be ignored when it does not apply as is
+	defaultPatchFuzz = 2
+	maxPatchFuzz     = 3
+	// devNull stands for the missing side of a created or deleted file
+	devNull = "/dev/null"
+)
+
+// Actions of apply_patch on a file
+const (
+	PatchModified = "modified"
+	PatchCreated  = "created"
+	PatchDeleted  = "deleted"
+	PatchRenamed  = "renamed"
+)
+
+// hunkHeader matches the @@ -start,count +start,count @@ line of a hunk
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// patchHunk is one hunk of a unified diff
+type patchHunk struct {
+	oldStart int
+	ops      []diffOp
+}
+
+// filePatch is the part of a unified diff that changes one file
+type filePatch struct {
+	oldPath, newPath string
+	hunks            []patchHunk
+}
+
+// PatchedFile describes what apply_patch did to one file
+type PatchedFile struct {
+	Path   string `json:"path"`
+	Action string `json:"action"`
+	// From is the previous path of a renamed file
+	From  string `json:"from,omitempty"`
+	Hunks int    `json:"hunks"`
+	// Notes tell which hunks applied at an offset or with fuzz
+	Notes []string `json:"notes,omitempty"`
+}
+
+// PatchResult is the JSON result of apply_patch
+type PatchResult struct {
+	Files  []PatchedFile `json:"files"`
+	DryRun bool          `json:"dry_run,omitempty"`
+}
+
+// patchChange is the planned outcome of a patch for one file
+type patchChange struct {
+	PatchedFile
+	target   string
+	from     string
+	original string
+	content  string
+}
+
+// HandleApplyPatch handles the apply_patch tool
+func (fs *FilesystemHandler) HandleApplyPatch(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	text, err := request.RequireString("patch")
+	if err != nil {
+		return nil, err
+	}
+	fuzz := request.GetInt("fuzz", defaultPatchFuzz)
+	if fuzz < 0 || fuzz > maxPatchFuzz {
+		return errorResult(toolError(CodeInvalidArgument, "Error: fuzz must be between 0 and %d", maxPatchFuzz).With("fuzz", fuzz)), nil
+	}
+	strip := request.GetInt("strip", -1)
+	dir := request.GetString("path", "")
+	dryRun := request.GetBool("dry_run", false) || fs.dryRunMode()
+
+	patches, err := parsePatch(text)
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	// Every file is patched in memory first, so a hunk that does not apply
+	// leaves all of them untouched
+	validate := fs.validateWritablePath
+	if dryRun {
+		validate = fs.validatePath
+	}
+	var changes []*patchChange
+	planned := make(map[string]*patchChange)
+	for _, patch := range patches {
+		change, err := fs.planPatch(ctx, patch, dir, strip, fuzz, validate, planned)
+		if err != nil {
+			return errorResult(err), nil
+		}
+		if prior, ok := planned[change.target]; ok {
+			// A later part of the patch changing the same file again
+			prior.content = change.content
+			prior.Hunks += change.Hunks
+			prior.Notes = append(prior.Notes, change.Notes...)
+			continue
+		}
+		changes = append(changes, change)
+		planned[change.target] = change
+		if change.from != "" {
+			planned[change.from] = &patchChange{PatchedFile: PatchedFile{Action: PatchDeleted}, target: change.from}
+		}
+	}
+
+	result := PatchResult{DryRun: dryRun}
+	for _, change := range changes {
+		result.Files = append(result.Files, change.PatchedFile)
+	}
+	if dryRun {
+		lines := []string{fmt.Sprintf("Would patch %d file(s):", len(changes))}
+		for _, change := range changes {
+			lines = append(lines, describePatchedFile(change.PatchedFile))
+		}
+		for _, change := range changes {
+			from := change.target
+			if change.from != "" {
+				from = change.from
+			}
+			if diff := unifiedDiff(from, change.target, change.original, change.content); diff != "" {
+				lines = append(lines, "", strings.TrimSuffix(diff, "\n"))
+			}
+		}
+		if wantsJSON(ctx) {
+			return jsonResult(ctx, result)
+		}
+		return dryRunResult(lines...), nil
+	}
+
+	if err := fs.commitPatch(changes); err != nil {
+		fs.reportQuotaError(changes[0].target, err)
+		return errorResult(fmt.Errorf("Error: the patch was not applied: %w", err)), nil
+	}
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, result)
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Applied the patch to %d file(s):\n", len(changes)))
+	for _, file := range result.Files {
+		sb.WriteString(describePatchedFile(file) + "\n")
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// planPatch validates the file a patch changes and applies it in memory,
+// on top of the changes already planned for that file
+func (fs *FilesystemHandler) planPatch(ctx context.Context, patch filePatch, dir string, strip, fuzz int, validate func(string) (string, error), planned map[string]*patchChange) (*patchChange, error) {
+	if strip < 0 {
+		strip = 0
+		if (patch.oldPath == devNull || strings.HasPrefix(patch.oldPath, "a/")) &&
+			(patch.newPath == devNull || strings.HasPrefix(patch.newPath, "b/")) {
+			strip = 1
+		}
+	}
+	resolve := func(p string) (string, error) {
+		if p == devNull {
+			return "", nil
+		}
+		stripped, err := stripPatchPath(p, strip)
+		if err != nil {
+			return "", err
+		}
+		if dir != "" && !filepath.IsAbs(stripped) {
+			stripped = filepath.Join(dir, stripped)
+		} else if stripped, err = fs.resolvePath(ctx, stripped); err != nil {
+			return "", err
+		}
+		if err := fs.checkScratchAccess(ctx, stripped); err != nil {
+			return "", err
+		}
+		return stripped, nil
+	}
+	oldPath, err := resolve(patch.oldPath)
+	if err != nil {
+		return nil, err
+	}
+	newPath, err := resolve(patch.newPath)
+	if err != nil {
+		return nil, err
+	}
+
+	change := &patchChange{PatchedFile: PatchedFile{Hunks: len(patch.hunks)}}
+	source := oldPath
+	switch {
+	case oldPath == "" && newPath == "":
+		return nil, toolError(CodeInvalidArgument, "Error: a file in the patch has neither an old nor a new path")
+	case oldPath == "":
+		change.Action = PatchCreated
+		if change.target, err = fs.validateCreatablePath(newPath, true); err != nil {
+			return nil, fmt.Errorf("Error: %w", err)
+		}
+	case newPath == "":
+		change.Action = PatchDeleted
+		if change.target, err = validate(oldPath); err != nil {
+			return nil, fmt.Errorf("Error: %w", err)
+		}
+		source = change.target
+	case filepath.Clean(oldPath) != filepath.Clean(newPath):
+		change.Action = PatchRenamed
+		if source, err = validate(oldPath); err != nil {
+			return nil, fmt.Errorf("Error: %w", err)
+		}
+		if change.target, err = fs.validateCreatablePath(newPath, true); err != nil {
+			return nil, fmt.Errorf("Error: %w", err)
+		}
+		change.from, change.From = source, source
+	default:
+		change.Action = PatchModified
+		if change.target, err = validate(newPath); err != nil {
+			return nil, fmt.Errorf("Error: %w", err)
+		}
+		source = change.target
+	}
+	change.Path = change.target
+
+	// Only modifications can follow an earlier change to the same file
+	for _, p := range []string{source, change.target} {
+		if prior, ok := planned[p]; ok && (prior.Action != PatchModified || change.Action != PatchModified) {
+			return nil, toolError(CodeInvalidArgument, "Error: the patch changes %s more than once", p).With("path", p)
+		}
+	}
+
+	// The content the hunks apply to: what earlier parts of the patch left,
+	// or the file on disk
+	if change.Action != PatchCreated {
+		if prior, ok := planned[source]; ok {
+			change.original = prior.content
+		} else {
+			info, err := os.Stat(source)
+			if err != nil {
+				return nil, fmt.Errorf("Error: %w", err)
+			}
+			if !info.Mode().IsRegular() {
+				return nil, toolError(CodeWrongType, "Error: %s is not a regular file", source).With("path", source)
+			}
+			content, err := os.ReadFile(source)
+			if err != nil {
+				return nil, fmt.Errorf("Error reading %s: %w", source, err)
+			}
+			change.original = string(content)
+		}
+	}
+	if change.Action == PatchCreated || change.Action == PatchRenamed {
+		if _, err := os.Lstat(change.target); err == nil {
+			return nil, toolError(CodeAlreadyExists, "Error: the patch creates %s, which already exists", change.target).With("path", change.target)
+		}
+	}
+
+	content, notes, err := applyHunks(change.target, change.original, patch.hunks, fuzz)
+	if err != nil {
+		return nil, err
+	}
+
+	if change.Action == PatchDeleted && content != "" {
+		return nil, toolError(CodeNotFound, "Error: the patch deletes %s, but the file has content the patch does not remove", change.target).With("path", change.target)
+	}
+	change.content, change.Notes = content, notes
+	return change, nil
+}
+
+// commitPatch applies the planned changes all or nothing. The new contents
+// are written with commitWrites, and the files deleted or renamed away are
+// moved aside until those are in place, so a failure puts every file back. Overwritten and deleted
+// content is saved as a version when versioning is on.
+func (fs *FilesystemHandler) commitPatch(changes []*patchChange) (err error) {
+	var writes []*stagedWrite
+	var removals []string
+	for _, change := range changes {
+		switch change.Action {
+		case PatchDeleted:
+			removals = append(removals, change.target)
+			continue
+		case PatchRenamed:
+			removals = append(removals, change.from)
+		}
+		write := &stagedWrite{path: change.target, content: []byte(change.content)}
+		if _, err := os.Stat(change.target); err == nil {
+			write.existed = true
+		}
+		writes = append(writes, write)
+	}
+
+	aside := make(map[string]string)
+	defer func() {
+		for path, moved := range aside {
+			if err != nil {
+				os.Rename(moved, path)
+			} else {
+				os.Remove(moved)
+			}
+		}
+	}()
+	for _, path := range removals {
+		if err := fs.saveVersion(path); err != nil {
+			return fmt.Errorf("failed to save the previous version of %s: %w", path, err)
+		}
+		moved := filepath.Join(filepath.Dir(path), fmt.Sprintf(".%s.%d.deleted", filepath.Base(path), time.Now().UnixNano()))
+		if err := os.Rename(path, moved); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		aside[path] = moved
+	}
+	return fs.commitWrites(writes, false)
+}
+
+// describePatchedFile renders what apply_patch did to a file as one line
+func describePatchedFile(file PatchedFile) string {
+	text := fmt.Sprintf("  %s %s", file.Action, file.Path)
+	if file.From != "" {
+		text = fmt.Sprintf("  %s %s -> %s", file.Action, file.From, file.Path)
+	}
+	text += fmt.Sprintf(" (%d hunk(s))", file.Hunks)
+	if len(file.Notes) > 0 {
+		text += "; " + strings.Join(file.Notes, ", ")
+	}
+	return text
+}
+
+// stripPatchPath removes the first n components of a path in a patch, like
+// patch -p
+func stripPatchPath(p string, n int) (string, error) {
+	stripped := p
+	for i := 0; i < n; i++ {
+		slash := strings.Index(stripped, "/")
+		if slash < 0 {
+			return "", toolError(CodeInvalidArgument, "Error: cannot strip %d leading component(s) from %s", n, p).With("strip", n)
+		}
+		stripped = stripped[slash+1:]
+	}
+	return filepath.FromSlash(stripped), nil
+}
+
+// parsePatch parses a unified diff, such as the output of diff -u or git
+// diff, into the changes it makes to each file. Lines outside the file
+// headers and hunks, like git's extended headers, are ignored.
+func parsePatch(text string) ([]filePatch, error) {
+	lines := splitLines(text)
+	var patches []filePatch
+	for i := 0; i < len(lines); {
+		if !strings.HasPrefix(lines[i], "--- ") || i+1 >= len(lines) || !strings.HasPrefix(lines[i+1], "+++ ") {
+			i++
+			continue
+		}
+		patch := filePatch{oldPath: patchHeaderPath(lines[i]), newPath: patchHeaderPath(lines[i+1])}
+		i += 2
+		for i < len(lines) && hunkHeader.MatchString(lines[i]) {
+			var hunk patchHunk
+			hunk, i = parseHunk(lines, i)
+			patch.hunks = append(patch.hunks, hunk)
+		}
+		if len(patch.hunks) == 0 {
+			return nil, toolError(CodeInvalidArgument, "Error: the patch for %s has no hunks", patch.newPath).With("path", patch.newPath)
+		}
+		patches = append(patches, patch)
+	}
+	if len(patches) == 0 {
+		return nil, toolError(CodeInvalidArgument, "Error: no file changes found; expected a unified diff with ---, +++ and @@ lines")
+	}
+	return patches, nil
+}
+
+// parseHunk parses the hunk whose header is lines[i], returning it and the
+// index of the line after it. Hunk line counts are not trusted, since
+// hand-written diffs often get them wrong; the hunk ends at the next hunk
+// or file header. An empty line is taken as an empty context line whose
+// leading space was lost.
+func parseHunk(lines []string, i int) (patchHunk, int) {
+	match := hunkHeader.FindStringSubmatch(lines[i])
+	oldStart, _ := strconv.Atoi(match[1])
+	hunk := patchHunk{oldStart: oldStart}
+	var blank int
+	for i++; i < len(lines); i++ {
+		line := lines[i]
+		if hunkHeader.MatchString(line) || strings.HasPrefix(line, "diff ") ||
+			(strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ ")) {
+			break
+		}
+		switch {
+		case strings.TrimRight(line, "\r\n") == "":
+			hunk.ops = append(hunk.ops, diffOp{' ', line})
+			blank++
+			continue
+		case line[0] == ' ' || line[0] == '-' || line[0] == '+':
+			hunk.ops = append(hunk.ops, diffOp{line[0], line[1:]})
+		case line[0] == '\\':
+			// "\ No newline at end of file" applies to the line before it
+			if n := len(hunk.ops); n > 0 {
+				hunk.ops[n-1].line = strings.TrimRight(hunk.ops[n-1].line, "\r\n")
+			}
+		default:
+			// Anything else, like git's extended headers, ends the hunk
+			hunk.ops = hunk.ops[:len(hunk.ops)-blank]
+			return hunk, i
+		}
+		blank = 0
+	}
+	// Empty lines at the end separate it from what follows
+	hunk.ops = hunk.ops[:len(hunk.ops)-blank]
+	return hunk, i
+}
+
+// patchHeaderPath returns the path of a --- or +++ line, without the
+// timestamp diff -u adds after a tab
+func patchHeaderPath(line string) string {
+	p := strings.TrimRight(line[4:], "\r\n")
+	if tab := strings.IndexByte(p, '\t'); tab >= 0 {
+		p = p[:tab]
+	}
+	p = strings.TrimSpace(p)
+	if unquoted, err := strconv.Unquote(p); err == nil {
+		p = unquoted
+	}
+	return p
+}
+
+// applyHunks applies hunks to content in order. A hunk whose lines are not
+// where its header says is looked for nearby, and with fuzz, up to that
+// many context lines at either end of it may be ignored. Lines are compared
+// without their line endings, and added lines take the file's line ending.
+func applyHunks(name, content string, hunks []patchHunk, fuzz int) (string, []string, error) {
+	lines := splitLines(content)
+	crlf := len(lines) > 0 && strings.HasSuffix(lines[0], "\r\n")
+	var out, notes []string
+	pos, shift := 0, 0
+	for n, hunk := range hunks {
+		placed := -1
+		var ops []diffOp
+		var used int
+		for f := 0; f <= fuzz && placed < 0; f++ {
+			var trimmed int
+			ops, trimmed = trimHunkContext(hunk.ops, f)
+			placed = findHunk(lines, pos, hunk.oldStart-1+shift+trimmed, hunkOldLines(ops))
+			used = f
+		}
+		if placed < 0 {
+			return "", nil, toolError(CodeNotFound, "Error: hunk %d of %s (at line %d) does not apply: its context and removed lines were not found", n+1, name, hunk.oldStart).
+				With("path", name).With("hunk", n+1)
+		}
+		if offset := placed - (hunk.oldStart - 1); used > 0 || (offset != 0 && len(hunkOldLines(ops)) > 0) {
+			note := fmt.Sprintf("hunk %d at line %d", n+1, placed+1)
+			if used > 0 {
+				note += fmt.Sprintf(" with fuzz %d", used)
+			}
+			notes = append(notes, note)
+		}
+		shift = placed - (hunk.oldStart - 1)
+
+		out = append(out, lines[pos:placed]...)
+		at := placed
+		for _, op := range ops {
+			switch op.kind {
+			case ' ':
+				out = append(out, lines[at])
+				at++
+			case '-':
+				at++
+			case '+':
+				line := op.line
+				if crlf && strings.HasSuffix(line, "\n") && !strings.HasSuffix(line, "\r\n") {
+					line = strings.TrimSuffix(line, "\n") + "\r\n"
+				}
+				out = append(out, line)
+			}
+		}
+		pos = at
+	}
+	out = append(out, lines[pos:]...)
+
+	// A line left without its line break by an edit after it gets one back
+	eol := "\n"
+	if crlf {
+		eol = "\r\n"
+	}
+	var sb strings.Builder
+	for i, line := range out {
+		sb.WriteString(line)
+		if i < len(out)-1 && !strings.HasSuffix(line, "\n") {
+			sb.WriteString(eol)
+		}
+	}
+	return sb.String(), notes, nil
+}
+
+// trimHunkContext drops up to n context lines from either end of a hunk,
+// and returns how many it dropped from the start
+func trimHunkContext(ops []diffOp, n int) ([]diffOp, int) {
+	start, end := 0, len(ops)
+	for start < end && start < n && ops[start].kind == ' ' {
+		start++
+	}
+	for end > start && len(ops)-end < n && ops[end-1].kind == ' ' {
+		end--
+	}
+	return ops[start:end], start
+}
+
+// hunkOldLines returns the lines a hunk expects to find: its context and
+// removed lines
+func hunkOldLines(ops []diffOp) []string {
+	var old []string
+	for _, op := range ops {
+		if op.kind != '+' {
+			old = append(old, op.line)
+		}
+	}
+	return old
+}
+
+// findHunk returns where old occurs in lines at or after from, preferring
+// the position closest to want, or -1. With nothing to match, a hunk that
+// only adds lines goes where it was meant to.
+func findHunk(lines []string, from, want int, old []string) int {
+	last := len(lines) - len(old)
+	if len(old) == 0 {
+		// An insertion's header names the line it follows
+		want++
+		return max(from, min(want, len(lines)))
+	}
+	for d := 0; want-d >= from || want+d <= last; d++ {
+		for _, p := range []int{want - d, want + d} {
+			if p >= from && p <= last && linesMatch(lines[p:p+len(old)], old) {
+				return p
+			}
+			if d == 0 {
+				break
+			}
+		}
+	}
+	return -1
+}
+
+// linesMatch compares lines without their line endings
+func linesMatch(a, b []string) bool {
+	for i := range a {
+		if strings.TrimRight(a[i], "\r\n") != strings.TrimRight(b[i], "\r\n") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPatch(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hello\")\n}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "old.txt"), []byte("bye\n"), 0644))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	patch := `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -4,4 +4,5 @@
+ 
+ func main() {
+-	fmt.Println("hello")
++	fmt.Println("hello, world")
++	fmt.Println("again")
+ }
+--- /dev/null
++++ b/docs/notes.md
+@@ -0,0 +1,2 @@
++# Notes
++First
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+`
+	res := callTool(t, fsHandler.HandleApplyPatch, map[string]any{"patch": patch, "path": dir, "dry_run": true})
+	require.False(t, res.IsError, res.Content)
+	text := res.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Dry run: no changes were made.")
+	assert.Contains(t, text, "+\tfmt.Println(\"again\")")
+	assert.NoFileExists(t, filepath.Join(dir, "docs", "notes.md"))
+
+	res = callTool(t, fsHandler.FormatToolCall(fsHandler.HandleApplyPatch), map[string]any{"patch": patch, "path": dir, "format": FormatJSON})
+	require.False(t, res.IsError, res.Content)
+	var result PatchResult
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &result))
+	require.Len(t, result.Files, 3)
+	assert.Equal(t, PatchModified, result.Files[0].Action)
+	assert.Empty(t, result.Files[0].Notes)
+	assert.Equal(t, PatchCreated, result.Files[1].Action)
+	assert.Equal(t, PatchDeleted, result.Files[2].Action)
+
+	content, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hello, world\")\n\tfmt.Println(\"again\")\n}\n", string(content))
+	content, err = os.ReadFile(filepath.Join(dir, "docs", "notes.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Notes\nFirst\n", string(content))
+	assert.NoFileExists(t, filepath.Join(dir, "old.txt"))
+}
+
+func TestApplyPatchIsAllOrNothing(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("one\ntwo\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.txt"), []byte("three\n"), 0644))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	patch := "--- a.txt\n+++ a.txt\n@@ -1,2 +1,2 @@\n one\n-two\n+2\n--- b.txt\n+++ b.txt\n@@ -1 +1 @@\n-four\n+4\n"
+	res := callTool(t, fsHandler.HandleApplyPatch, map[string]any{"patch": patch, "path": dir})
+	assert.Equal(t, CodeNotFound, ResultError(res).Code)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "hunk 1 of "+filepath.Join(dir, "b.txt"))
+	content, err := os.ReadFile(filepath.Join(dir, "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\n", string(content))
+
+	// A failure while writing puts back the files changed and deleted so far
+	changes := []*patchChange{
+		{PatchedFile: PatchedFile{Action: PatchModified}, target: filepath.Join(dir, "a.txt"), content: "changed\n"},
+		{PatchedFile: PatchedFile{Action: PatchDeleted}, target: filepath.Join(dir, "b.txt")},
+		{PatchedFile: PatchedFile{Action: PatchCreated}, target: filepath.Join(dir, "new", "c.txt"), content: "c\n"},
+		{PatchedFile: PatchedFile{Action: PatchCreated}, target: filepath.Join(dir, "a.txt", "d.txt"), content: "not in a directory\n"},
+	}
+	require.Error(t, fsHandler.commitPatch(changes))
+	content, err = os.ReadFile(filepath.Join(dir, "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\n", string(content))
+	content, err = os.ReadFile(filepath.Join(dir, "b.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "three\n", string(content))
+	assert.NoDirExists(t, filepath.Join(dir, "new"))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	res = callTool(t, fsHandler.HandleApplyPatch, map[string]any{"patch": "not a diff", "path": dir})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	res = callTool(t, fsHandler.HandleApplyPatch, map[string]any{"patch": "--- /dev/null\n+++ a.txt\n@@ -0,0 +1 @@\n+x\n", "path": dir})
+	assert.Equal(t, CodeAlreadyExists, ResultError(res).Code)
+}
+
+func TestApplyHunksOffsetAndFuzz(t *testing.T) {
+	content := "header\nextra\na\nb\nc\nd\ne\n"
+	hunks := []patchHunk{{oldStart: 1, ops: []diffOp{{' ', "a\n"}, {' ', "b\n"}, {'-', "c\n"}, {'+', "C\n"}, {' ', "d\n"}}}}
+
+	// The hunk moved down by two lines
+	patched, notes, err := applyHunks("f", content, hunks, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "header\nextra\na\nb\nC\nd\ne\n", patched)
+	assert.Equal(t, []string{"hunk 1 at line 3"}, notes)
+
+	// A context line that differs needs fuzz
+	hunks[0].ops[0] = diffOp{' ', "changed\n"}
+	_, _, err = applyHunks("f", content, hunks, 0)
+	assert.Equal(t, CodeNotFound, errorCode(err))
+	patched, notes, err = applyHunks("f", content, hunks, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "header\nextra\na\nb\nC\nd\ne\n", patched)
+	assert.Equal(t, []string{"hunk 1 at line 4 with fuzz 1"}, notes)
+
+	// Added lines take the file's line endings
+	patched, _, err = applyHunks("f", "x\r\ny\r\n", []patchHunk{{oldStart: 1, ops: []diffOp{{' ', "x\n"}, {'+', "new\n"}}}}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "x\r\nnew\r\ny\r\n", patched)
+}
+
+func TestParsePatch(t *testing.T) {
+	// Blank context lines that lost their leading space, wrong counts and
+	// a missing final newline are tolerated
+	patches, err := parsePatch("--- a/f.txt\t2024-01-01\n+++ b/f.txt\n@@ -1,9 +1,9 @@\n one\n\n-two\n+2\n\\ No newline at end of file\n\n")
+	require.NoError(t, err)
+	require.Len(t, patches, 1)
+	assert.Equal(t, "a/f.txt", patches[0].oldPath)
+	assert.Equal(t, []diffOp{{' ', "one\n"}, {' ', "\n"}, {'-', "two\n"}, {'+', "2"}}, patches[0].hunks[0].ops)
+}
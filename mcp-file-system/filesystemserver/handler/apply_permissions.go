@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// PermissionsResult is the JSON result of apply_permissions
+type PermissionsResult struct {
+	Path      string              `json:"path"`
+	FileMode  string              `json:"file_mode,omitempty"`
+	DirMode   string              `json:"dir_mode,omitempty"`
+	Changed   int                 `json:"changed"`
+	Unchanged int                 `json:"unchanged"`
+	Failed    []PermissionFailure `json:"failed"`
+}
+
+// PermissionFailure is an entry whose mode could not be set
+type PermissionFailure struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// parseMode parses an octal permission mode such as "644" or "0755"; an
+// empty string leaves the mode unset
+func parseMode(arg, value string) (*os.FileMode, error) {
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseUint(strings.TrimPrefix(value, "0o"), 8, 32)
+	if err != nil || n > 0o777 {
+		return nil, toolError(CodeInvalidArgument, "Error: %s must be an octal mode between 000 and 777, such as 644", arg).With(arg, value)
+	}
+	mode := os.FileMode(n)
+	return &mode, nil
+}
+
+// HandleApplyPermissions handles the apply_permissions tool
+func (fs *FilesystemHandler) HandleApplyPermissions(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	fileMode, err := parseMode("file_mode", request.GetString("file_mode", ""))
+	if err != nil {
+		return errorResult(err), nil
+	}
+	dirMode, err := parseMode("dir_mode", request.GetString("dir_mode", ""))
+	if err != nil {
+		return errorResult(err), nil
+	}
+	if fileMode == nil && dirMode == nil {
+		return errorResult(toolError(CodeInvalidArgument, "Error: give file_mode, dir_mode or both")), nil
+	}
+	var include, exclude glob.Glob
+	if pattern := request.GetString("glob", ""); pattern != "" {
+		if include, err = glob.Compile(pattern, '/'); err != nil {
+			return errorResult(toolError(CodeInvalidArgument, "Error: invalid glob %q: %w", pattern, err)), nil
+		}
+	}
+	if pattern := request.GetString("exclude", ""); pattern != "" {
+		if exclude, err = glob.Compile(pattern, '/'); err != nil {
+			return errorResult(toolError(CodeInvalidArgument, "Error: invalid exclude glob %q: %w", pattern, err)), nil
+		}
+	}
+	dryRun := request.GetBool("dry_run", false)
+
+	// A dry run only previews the changes, so the path need not be writable
+	validate := fs.validateWritablePath
+	if dryRun {
+		validate = fs.validatePath
+	}
+	validPath, err := validate(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if _, err := os.Stat(validPath); err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+
+	result := PermissionsResult{Path: validPath, Failed: []PermissionFailure{}}
+	if fileMode != nil {
+		result.FileMode = fmt.Sprintf("%03o", *fileMode)
+	}
+	if dirMode != nil {
+		result.DirMode = fmt.Sprintf("%03o", *dirMode)
+	}
+	var lines []string
+	err = filepath.WalkDir(validPath, func(p string, d os.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			result.Failed = append(result.Failed, PermissionFailure{Path: p, Error: err.Error()})
+			return nil
+		}
+		rel, _ := filepath.Rel(validPath, p)
+		rel = filepath.ToSlash(rel)
+		if rel != "." && exclude != nil && exclude.Match(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// The trash and saved versions keep the modes their files had
+		if !fs.isPathInAllowedDirs(p) || (!dryRun && p != validPath && fs.isReadOnly(p)) ||
+			(p != validPath && (fs.isInTrash(p) || fs.isInVersions(p))) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Symbolic links are left alone, as chmod would change their targets
+		want := fileMode
+		if d.IsDir() {
+			want = dirMode
+		} else if !d.Type().IsRegular() {
+			return nil
+		}
+		if want == nil || (include != nil && rel != "." && !include.Match(rel)) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			result.Failed = append(result.Failed, PermissionFailure{Path: p, Error: err.Error()})
+			return nil
+		}
+		if info.Mode().Perm() == *want {
+			result.Unchanged++
+			return nil
+		}
+		if dryRun || fs.dryRunMode() {
+			lines = append(lines, fmt.Sprintf("Would change %s from %03o to %03o", p, info.Mode().Perm(), *want))
+			result.Changed++
+			return nil
+		}
+		if err := os.Chmod(p, *want); err != nil {
+			result.Failed = append(result.Failed, PermissionFailure{Path: p, Error: err.Error()})
+			return nil
+		}
+		result.Changed++
+		return nil
+	})
+	if err != nil {
+		return errorResult(fmt.Errorf("Error applying permissions: %w", err)), nil
+	}
+
+	if dryRun || fs.dryRunMode() {
+		lines = append(lines, fmt.Sprintf("%d path(s) would change, %d already have the mode", result.Changed, result.Unchanged))
+		return dryRunResult(lines...), nil
+	}
+	if wantsJSON(ctx) && len(result.Failed) == 0 {
+		return jsonResult(ctx, result)
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Changed the mode of %d path(s) under %s; %d already had it", result.Changed, validPath, result.Unchanged))
+	for _, failure := range result.Failed {
+		sb.WriteString(fmt.Sprintf("\n  failed %s: %s", failure.Path, failure.Error))
+	}
+	if len(result.Failed) > 0 {
+		return errorResult(toolError(CodeInternal, "%s", sb.String()).
+			With("succeeded", result.Changed).With("failed", len(result.Failed))), nil
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission modes are not supported on Windows")
+	}
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	for name, mode := range map[string]os.FileMode{
+		"run.sh":          0600,
+		"src/main.go":     0700,
+		"src/lib/util.go": 0666,
+		".git/HEAD":       0600,
+		// The trash and saved versions are skipped
+		trashDirName + "/files/old.sh": 0600,
+		versionsDirName + "/run.sh":    0600,
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		require.NoError(t, os.WriteFile(path, nil, mode))
+	}
+	require.NoError(t, os.Chmod(filepath.Join(dir, "src", "lib"), 0700))
+	modeOf := func(name string) os.FileMode {
+		info, err := os.Stat(filepath.Join(dir, name))
+		require.NoError(t, err)
+		return info.Mode().Perm()
+	}
+
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	apply := fsHandler.FormatToolCall(fsHandler.HandleApplyPermissions)
+
+	res := callTool(t, apply, map[string]any{"path": dir, "file_mode": "644", "exclude": ".git", "dry_run": true})
+	require.False(t, res.IsError, res.Content)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Would change "+filepath.Join(dir, "run.sh")+" from 600 to 644")
+	assert.Equal(t, os.FileMode(0600), modeOf("run.sh"))
+
+	res = callTool(t, apply, map[string]any{"path": dir, "file_mode": "0644", "dir_mode": "755", "exclude": ".git", "format": FormatJSON})
+	require.False(t, res.IsError, res.Content)
+	var got PermissionsResult
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &got))
+	assert.Equal(t, "644", got.FileMode)
+	assert.Equal(t, "755", got.DirMode)
+	assert.Equal(t, 6, got.Changed+got.Unchanged)
+	assert.Empty(t, got.Failed)
+	assert.Equal(t, os.FileMode(0644), modeOf("src/lib/util.go"))
+	assert.Equal(t, os.FileMode(0755), modeOf("src/lib"))
+	assert.Equal(t, os.FileMode(0600), modeOf(".git/HEAD"))
+	assert.Equal(t, os.FileMode(0600), modeOf(trashDirName+"/files/old.sh"))
+	assert.Equal(t, os.FileMode(0700), modeOf(versionsDirName))
+
+	// glob narrows the change to matching entries
+	res = callTool(t, apply, map[string]any{"path": dir, "file_mode": "755", "glob": "*.sh"})
+	require.False(t, res.IsError, res.Content)
+	assert.Equal(t, os.FileMode(0755), modeOf("run.sh"))
+	assert.Equal(t, os.FileMode(0644), modeOf("src/main.go"))
+
+	for _, args := range []map[string]any{
+		{"path": dir},
+		{"path": dir, "file_mode": "rw-r--r--"},
+		{"path": dir, "dir_mode": "1755"},
+	} {
+		res = callTool(t, apply, args)
+		assert.Equal(t, CodeInvalidArgument, ResultError(res).Code, args)
+	}
+}
@@ -0,0 +1,702 @@
+package handler
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// backupHistoryFile is the JSONL file under the state directory recording backup runs
+	backupHistoryFile = "backup_history.jsonl"
+	// backupKeyEnv holds the base64 or hex encoded 32-byte key used for encrypted backups
+	backupKeyEnv = "MCP_FS_BACKUP_KEY"
+	// backupEncMagic prefixes encrypted backup archives
+	backupEncMagic = "MCPFSENC1"
+	// backupEncChunk is the plaintext size of each encrypted chunk
+	backupEncChunk = 64 * 1024
+)
+
+// BackupSchedule describes a recurring backup job
+type BackupSchedule struct {
+	Name    string `json:"name" yaml:"name"`
+	Path    string `json:"path" yaml:"path"`
+	Remote  string `json:"remote" yaml:"remote"`
+	Encrypt bool   `json:"encrypt" yaml:"encrypt"`
+	Cron    string `json:"cron" yaml:"cron"`
+}
+
+// BackupRun records a single backup execution
+type BackupRun struct {
+	ID          string    `json:"id"`
+	Job         string    `json:"job,omitempty"`
+	Source      string    `json:"source"`
+	Archive     string    `json:"archive"`
+	Remote      string    `json:"remote"`
+	Destination string    `json:"destination,omitempty"`
+	CrocCode    string    `json:"croc_code,omitempty"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256,omitempty"`
+	Encrypted   bool      `json:"encrypted"`
+	Status      string    `json:"status"` // "completed", "sending" or "failed"
+	Error       string    `json:"error,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+	FinishedAt  time.Time `json:"finished_at"`
+}
+
+// backupHistoryMu serialises appends to the backup history file
+var backupHistoryMu sync.Mutex
+
+// HandleBackupDirectory handles the backup_directory tool
+func (fs *FilesystemHandler) HandleBackupDirectory(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return errorResult(toolError(CodeInvalidArgument, "path is required")), nil
+	}
+	remote, err := request.RequireString("remote")
+	if err != nil || remote == "" {
+		return errorResult(toolError(CodeInvalidArgument, "remote is required")), nil
+	}
+	encrypt := false
+	if val, err := request.RequireBool("encrypt"); err == nil {
+		encrypt = val
+	}
+	name, _ := request.RequireString("name")
+	if remote, err = fs.backupRemote(remote, false); err != nil {
+		return errorResult(err), nil
+	}
+
+	if fs.dryRunMode() {
+		lines, err := fs.describeBackup(path, remote, encrypt)
+		if err != nil {
+			return errorResult(toolError(CodeExternalFailed, "backup failed: %w", err)), nil
+		}
+		return dryRunResult(lines...), nil
+	}
+
+	run, err := fs.runBackup(ctx, name, path, remote, encrypt)
+	if err != nil {
+		return errorResult(toolError(CodeExternalFailed, "backup failed: %w", err)), nil
+	}
+
+	jsonBytes, err := json.Marshal(run)
+	if err != nil {
+		return errorResult(fmt.Errorf("failed to marshal response: %w", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// HandleBackupHistory handles the backup_history tool
+func (fs *FilesystemHandler) HandleBackupHistory(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	limit := 20
+	if val, err := request.RequireFloat("limit"); err == nil && val > 0 {
+		limit = int(val)
+	}
+
+	runs, err := fs.readBackupHistory()
+	if err != nil {
+		return errorResult(fmt.Errorf("failed to read backup history: %w", err)), nil
+	}
+	if len(runs) > limit {
+		runs = runs[len(runs)-limit:]
+	}
+	if len(runs) == 0 {
+		return mcp.NewToolResultText("No backups recorded."), nil
+	}
+
+	jsonBytes, err := json.MarshalIndent(runs, "", "  ")
+	if err != nil {
+		return errorResult(fmt.Errorf("failed to marshal response: %w", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// StartBackupScheduler runs the configured backup schedules until ctx is cancelled
+func (fs *FilesystemHandler) StartBackupScheduler(ctx context.Context) error {
+	type job struct {
+		schedule BackupSchedule
+		cron     *cronSchedule
+	}
+	jobs := make([]job, 0, len(fs.backupSchedules))
+	for _, s := range fs.backupSchedules {
+		c, err := parseCron(s.Cron)
+		if err != nil {
+			return fmt.Errorf("backup schedule %q: %w", s.Name, err)
+		}
+		jobs = append(jobs, job{schedule: s, cron: c})
+	}
+
+	if len(jobs) == 0 {
+		return nil
+	}
+	ctx, cancel := fs.untilStopped(ctx)
+	var running sync.WaitGroup
+	for _, j := range jobs {
+		running.Add(1)
+		go func(j job) {
+			defer running.Done()
+			for {
+				next := j.cron.Next(time.Now())
+				if next.IsZero() {
+					fs.logger.Warn("backup schedule never fires, disabling", "job", j.schedule.Name, "cron", j.schedule.Cron)
+					return
+				}
+				timer := time.NewTimer(time.Until(next))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+				remote, _ := fs.backupRemote(j.schedule.Remote, true)
+				if _, err := fs.runBackup(ctx, j.schedule.Name, j.schedule.Path, remote, j.schedule.Encrypt); err != nil {
+					fs.logger.Error("scheduled backup failed", "job", j.schedule.Name, "path", j.schedule.Path, "error", err)
+				}
+			}
+		}(j)
+	}
+	fs.background.Add(1)
+	go func() {
+		defer fs.background.Done()
+		running.Wait()
+		cancel()
+	}()
+	return nil
+}
+
+// backupRemote resolves the remote of a backup: croc, a local directory or
+// the name of a configured remote. Only schedules from the config file,
+// which are trusted, may give an s3:// or sftp:// URL directly; a caller of
+// backup_directory could otherwise ship the allowed directories anywhere.
+func (fs *FilesystemHandler) backupRemote(remote string, trusted bool) (string, error) {
+	if dest, ok := fs.backupRemotes[remote]; ok {
+		return dest, nil
+	}
+	if remote == "croc" || trusted || !strings.Contains(remote, "://") || strings.HasPrefix(remote, "file://") {
+		return remote, nil
+	}
+	names := slices.Sorted(maps.Keys(fs.backupRemotes))
+	if len(names) == 0 {
+		return "", toolError(CodeInvalidArgument, "remote %q is not allowed: no backup remotes are configured, so only croc and allowed directories can be used", remote).With("remote", remote)
+	}
+	return "", toolError(CodeInvalidArgument, "remote %q is not allowed: use croc, an allowed directory or one of the configured remotes: %s", remote, strings.Join(names, ", ")).
+		With("remote", remote).With("remotes", names)
+}
+
+// runBackup archives path, optionally encrypts it, ships it to remote and
+// records the run in the persistent history
+func (fs *FilesystemHandler) runBackup(ctx context.Context, job, path, remote string, encrypt bool) (*BackupRun, error) {
+	run := &BackupRun{
+		ID:        fmt.Sprintf("%d", time.Now().UnixNano()),
+		Job:       job,
+		Source:    path,
+		Remote:    remote,
+		Encrypted: encrypt,
+		StartedAt: time.Now(),
+	}
+
+	err := fs.executeBackup(ctx, run, path, remote, encrypt)
+	run.FinishedAt = time.Now()
+	if err != nil {
+		run.Status = "failed"
+		run.Error = err.Error()
+	}
+	if histErr := fs.appendBackupHistory(run); histErr != nil && err == nil {
+		err = histErr
+	}
+
+	data := map[string]any{
+		"id":          run.ID,
+		"job":         run.Job,
+		"remote":      run.Remote,
+		"destination": run.Destination,
+		"size":        run.Size,
+		"status":      run.Status,
+		"duration":    run.FinishedAt.Sub(run.StartedAt).Round(time.Millisecond).String(),
+	}
+	if err != nil {
+		data["error"] = err.Error()
+		fs.emitEvent(EventBackupFailed, run.Source, data)
+	} else {
+		fs.emitEvent(EventBackupCompleted, run.Source, data)
+	}
+	return run, err
+}
+
+// describeBackup validates a backup request and describes what it would do
+func (fs *FilesystemHandler) describeBackup(path, remote string, encrypt bool) ([]string, error) {
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("path is not a directory: %s", path)
+	}
+	if encrypt {
+		if _, err := backupKey(); err != nil {
+			return nil, err
+		}
+	}
+
+	archive := filepath.Base(validPath) + ".tar.gz"
+	if encrypt {
+		archive += " (encrypted)"
+	}
+	dest := remote
+	switch {
+	case remote == "croc", strings.HasPrefix(remote, "s3://"), strings.HasPrefix(remote, "sftp://"):
+	default:
+		destDir, err := fs.validateWritablePath(strings.TrimPrefix(remote, "file://"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid backup destination: %w", err)
+		}
+		dest = destDir
+	}
+	return []string{
+		fmt.Sprintf("Would archive %s as %s", describeTree(validPath), archive),
+		fmt.Sprintf("Would store the archive in %s and record the run in the backup history", dest),
+	}, nil
+}
+
+func (fs *FilesystemHandler) executeBackup(ctx context.Context, run *BackupRun, path, remote string, encrypt bool) error {
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("path is not a directory: %s", path)
+	}
+	run.Source = validPath
+
+	var key []byte
+	if encrypt {
+		key, err = backupKey()
+		if err != nil {
+			return err
+		}
+	}
+
+	run.Archive = fmt.Sprintf("%s-%s.tar.gz", filepath.Base(validPath), run.StartedAt.Format("20060102-150405"))
+	if encrypt {
+		run.Archive += ".enc"
+	}
+
+	stateDir, err := fs.ensureStateDir()
+	if err != nil {
+		return err
+	}
+	spoolDir := filepath.Join(stateDir, "backups")
+	if err := os.MkdirAll(spoolDir, 0700); err != nil {
+		return fmt.Errorf("failed to create backup spool directory: %w", err)
+	}
+	archivePath := filepath.Join(spoolDir, run.Archive)
+
+	size, sum, err := fs.writeBackupArchive(archivePath, validPath, key)
+	if err != nil {
+		os.Remove(archivePath)
+		return err
+	}
+	run.Size = size
+	run.SHA256 = sum
+
+	switch {
+	case remote == "croc":
+		code, _, err := fs.startCrocSend(ctx, archivePath)
+		if err != nil {
+			os.Remove(archivePath)
+			return err
+		}
+		// The spooled archive must stay on disk until the receiver connects.
+		run.CrocCode = code
+		run.Destination = archivePath
+		run.Status = "sending"
+		return nil
+
+	case strings.HasPrefix(remote, "s3://"):
+		dest := strings.TrimSuffix(remote, "/") + "/" + run.Archive
+		run.Destination = dest
+		defer os.Remove(archivePath)
+		if err := runBackupCommand(ctx, "aws", "s3", "cp", "--only-show-errors", "--", archivePath, dest); err != nil {
+			return err
+		}
+
+	case strings.HasPrefix(remote, "sftp://"):
+		u, err := url.Parse(remote)
+		if err != nil {
+			return fmt.Errorf("invalid sftp remote: %w", err)
+		}
+		host := u.Hostname()
+		if u.User != nil {
+			host = u.User.Username() + "@" + host
+		}
+		if host == "" || strings.HasPrefix(host, "-") {
+			return fmt.Errorf("invalid sftp remote host %q", host)
+		}
+		remoteDir := strings.TrimPrefix(u.Path, "/")
+		if remoteDir == "" {
+			remoteDir = "."
+		}
+		dest := host + ":" + strings.TrimSuffix(remoteDir, "/") + "/" + run.Archive
+		run.Destination = "sftp://" + u.Host + "/" + strings.TrimSuffix(remoteDir, "/") + "/" + run.Archive
+		args := []string{"-B", "-q"}
+		if port := u.Port(); port != "" {
+			args = append(args, "-P", port)
+		}
+		args = append(args, "--", archivePath, dest)
+		defer os.Remove(archivePath)
+		if err := runBackupCommand(ctx, "scp", args...); err != nil {
+			return err
+		}
+
+	default:
+		destDir, err := fs.validateWritablePath(strings.TrimPrefix(remote, "file://"))
+		if err != nil {
+			return fmt.Errorf("invalid backup destination: %w", err)
+		}
+		if err := os.MkdirAll(destDir, 0755); err != nil {
+			return fmt.Errorf("failed to create backup destination: %w", err)
+		}
+		dest := filepath.Join(destDir, run.Archive)
+		run.Destination = dest
+		if err := os.Rename(archivePath, dest); err != nil {
+			// Rename fails across filesystems, fall back to a copy
+			if err := copyFile(archivePath, dest); err != nil {
+				os.Remove(archivePath)
+				return fmt.Errorf("failed to store backup: %w", err)
+			}
+			os.Remove(archivePath)
+		}
+	}
+
+	run.Status = "completed"
+	return nil
+}
+
+// writeBackupArchive writes a gzip-compressed tarball of root to archivePath,
+// encrypting it when key is non-nil. It returns the archive size and SHA256.
+func (fs *FilesystemHandler) writeBackupArchive(archivePath, root string, key []byte) (int64, string, error) {
+	f, err := os.OpenFile(archivePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(f, hasher)}
+
+	var sink io.WriteCloser = nopWriteCloser{counter}
+	if key != nil {
+		sink, err = newEncryptWriter(counter, key)
+		if err != nil {
+			return 0, "", err
+		}
+	}
+
+	gz := gzip.NewWriter(sink)
+	tw := tar.NewWriter(gz)
+	if err := fs.addDirToTar(tw, root); err != nil {
+		return 0, "", fmt.Errorf("failed to archive directory: %w", err)
+	}
+	if err := tw.Close(); err != nil {
+		return 0, "", err
+	}
+	if err := gz.Close(); err != nil {
+		return 0, "", err
+	}
+	if err := sink.Close(); err != nil {
+		return 0, "", err
+	}
+	if err := f.Sync(); err != nil {
+		return 0, "", err
+	}
+
+	return counter.n, hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// addDirToTar adds the contents of root to tw, using paths relative to the
+// parent of root so the archive unpacks into a single directory
+func (fs *FilesystemHandler) addDirToTar(tw *tar.Writer, root string) error {
+	base := filepath.Dir(root)
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		// Skip symlinks, consistent with copy_file
+		if info.Mode()&os.ModeSymlink != 0 {
+			return nil
+		}
+		if _, err := fs.validatePath(path); err != nil {
+			return nil
+		}
+
+		rel, err := filepath.Rel(base, path)
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(tw, file)
+		return err
+	})
+}
+
+// runBackupCommand runs an external upload command, including its output in errors
+func runBackupCommand(ctx context.Context, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s failed: %v: %s", name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// appendBackupHistory appends run to the persistent history file
+func (fs *FilesystemHandler) appendBackupHistory(run *BackupRun) error {
+	stateDir, err := fs.ensureStateDir()
+	if err != nil {
+		return err
+	}
+
+	backupHistoryMu.Lock()
+	defer backupHistoryMu.Unlock()
+
+	f, err := os.OpenFile(filepath.Join(stateDir, backupHistoryFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open backup history: %w", err)
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(run)
+}
+
+// readBackupHistory returns all recorded backup runs, oldest first
+func (fs *FilesystemHandler) readBackupHistory() ([]BackupRun, error) {
+	backupHistoryMu.Lock()
+	defer backupHistoryMu.Unlock()
+
+	f, err := os.Open(filepath.Join(fs.stateDir, backupHistoryFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var runs []BackupRun
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var run BackupRun
+		if err := json.Unmarshal(scanner.Bytes(), &run); err != nil {
+			continue // Skip corrupt lines
+		}
+		runs = append(runs, run)
+	}
+	return runs, scanner.Err()
+}
+
+// backupKey loads the backup encryption key from the environment
+func backupKey() ([]byte, error) {
+	raw := strings.TrimSpace(os.Getenv(backupKeyEnv))
+	if raw == "" {
+		return nil, fmt.Errorf("encryption requested but %s is not set", backupKeyEnv)
+	}
+	if key, err := hex.DecodeString(raw); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(raw); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%s must be a 32-byte key encoded as hex or base64", backupKeyEnv)
+}
+
+// encryptWriter encrypts a stream as a sequence of AES-256-GCM sealed chunks.
+// Each chunk is prefixed with its sealed length; the final chunk is
+// authenticated with a distinct additional-data byte so truncation is detected.
+type encryptWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	prefix  []byte
+	counter uint32
+	buf     []byte
+}
+
+func newEncryptWriter(w io.Writer, key []byte) (*encryptWriter, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	prefix := make([]byte, aead.NonceSize()-4)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(append([]byte(backupEncMagic), prefix...)); err != nil {
+		return nil, err
+	}
+	return &encryptWriter{w: w, aead: aead, prefix: prefix, buf: make([]byte, 0, backupEncChunk)}, nil
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := min(backupEncChunk-len(e.buf), len(p))
+		e.buf = append(e.buf, p[:n]...)
+		p = p[n:]
+		written += n
+		if len(e.buf) == backupEncChunk {
+			if err := e.flush(false); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+func (e *encryptWriter) Close() error {
+	return e.flush(true)
+}
+
+func (e *encryptWriter) flush(final bool) error {
+	nonce := make([]byte, e.aead.NonceSize())
+	copy(nonce, e.prefix)
+	binary.BigEndian.PutUint32(nonce[len(e.prefix):], e.counter)
+	e.counter++
+
+	ad := []byte{0}
+	if final {
+		ad[0] = 1
+	}
+	sealed := e.aead.Seal(nil, nonce, e.buf, ad)
+	e.buf = e.buf[:0]
+
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(sealed)))
+	if _, err := e.w.Write(length[:]); err != nil {
+		return err
+	}
+	_, err := e.w.Write(sealed)
+	return err
+}
+
+// DecryptBackup decrypts an archive produced by an encrypted backup_directory run
+func DecryptBackup(r io.Reader, w io.Writer, key []byte) error {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, len(backupEncMagic)+aead.NonceSize()-4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return fmt.Errorf("failed to read header: %w", err)
+	}
+	if string(header[:len(backupEncMagic)]) != backupEncMagic {
+		return errors.New("not an encrypted backup archive")
+	}
+	prefix := header[len(backupEncMagic):]
+
+	br := bufio.NewReader(r)
+	for counter := uint32(0); ; counter++ {
+		var length [4]byte
+		if _, err := io.ReadFull(br, length[:]); err != nil {
+			return fmt.Errorf("truncated archive: %w", err)
+		}
+		sealed := make([]byte, binary.BigEndian.Uint32(length[:]))
+		if _, err := io.ReadFull(br, sealed); err != nil {
+			return fmt.Errorf("truncated archive: %w", err)
+		}
+
+		nonce := make([]byte, aead.NonceSize())
+		copy(nonce, prefix)
+		binary.BigEndian.PutUint32(nonce[len(prefix):], counter)
+
+		_, peekErr := br.Peek(1)
+		final := peekErr == io.EOF
+		ad := []byte{0}
+		if final {
+			ad[0] = 1
+		}
+		plain, err := aead.Open(nil, nonce, sealed, ad)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt chunk %d: %w", counter, err)
+		}
+		if _, err := w.Write(plain); err != nil {
+			return err
+		}
+		if final {
+			return nil
+		}
+	}
+}
+
+// countingWriter counts bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
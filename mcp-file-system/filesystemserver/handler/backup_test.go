@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tarEntries(t *testing.T, r io.Reader) map[string]string {
+	t.Helper()
+	gz, err := gzip.NewReader(r)
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+	entries := make(map[string]string)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		entries[header.Name] = string(content)
+	}
+	return entries
+}
+
+func TestHandleBackupDirectory(t *testing.T) {
+	dir := t.TempDir()
+	allowedDirs := resolveAllowedDirs(t, dir)
+	handler, err := NewFilesystemHandler(allowedDirs, WithStateDir(t.TempDir()))
+	require.NoError(t, err)
+
+	src := filepath.Join(dir, "project")
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "a.txt"), []byte("alpha"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "sub", "b.txt"), []byte("beta"), 0644))
+	backups := filepath.Join(dir, "backups")
+	require.NoError(t, os.Mkdir(backups, 0755))
+
+	t.Run("local remote", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{
+			"path":   src,
+			"remote": backups,
+			"name":   "nightly",
+		}
+		result, err := handler.HandleBackupDirectory(context.Background(), request)
+		require.NoError(t, err)
+		require.False(t, result.IsError, result.Content)
+
+		var run BackupRun
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &run))
+		assert.Equal(t, "completed", run.Status)
+		assert.Equal(t, "nightly", run.Job)
+
+		f, err := os.Open(run.Destination)
+		require.NoError(t, err)
+		defer f.Close()
+		entries := tarEntries(t, f)
+		assert.Equal(t, "alpha", entries["project/a.txt"])
+		assert.Equal(t, "beta", entries["project/sub/b.txt"])
+	})
+
+	t.Run("encrypted", func(t *testing.T) {
+		key := bytes.Repeat([]byte{7}, 32)
+		t.Setenv(backupKeyEnv, hex.EncodeToString(key))
+
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{
+			"path":    src,
+			"remote":  backups,
+			"encrypt": true,
+		}
+		result, err := handler.HandleBackupDirectory(context.Background(), request)
+		require.NoError(t, err)
+		require.False(t, result.IsError, result.Content)
+
+		var run BackupRun
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &run))
+		assert.True(t, run.Encrypted)
+
+		f, err := os.Open(run.Destination)
+		require.NoError(t, err)
+		defer f.Close()
+		var plain bytes.Buffer
+		require.NoError(t, DecryptBackup(f, &plain, key))
+		entries := tarEntries(t, &plain)
+		assert.Equal(t, "alpha", entries["project/a.txt"])
+	})
+
+	t.Run("history", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		result, err := handler.HandleBackupHistory(context.Background(), request)
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+
+		var runs []BackupRun
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &runs))
+		assert.Len(t, runs, 2)
+	})
+
+	t.Run("destination outside allowed directories", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{
+			"path":   src,
+			"remote": t.TempDir(),
+		}
+		result, err := handler.HandleBackupDirectory(context.Background(), request)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+	})
+}
+
+func TestBackupRemotes(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "project")
+	require.NoError(t, os.MkdirAll(src, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "a.txt"), []byte("alpha"), 0644))
+	offsite := filepath.Join(dir, "offsite")
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir), WithStateDir(t.TempDir()),
+		WithBackupRemotes(map[string]string{"offsite": offsite}))
+	require.NoError(t, err)
+
+	// Remote URLs must be configured; callers cannot name their own
+	for _, remote := range []string{"s3://attacker-bucket/loot", "sftp://-oProxyCommand=touch%20pwned/dir"} {
+		res := callTool(t, handler.HandleBackupDirectory, map[string]any{"path": src, "remote": remote})
+		require.True(t, res.IsError, remote)
+		assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+		assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "offsite")
+	}
+
+	res := callTool(t, handler.HandleBackupDirectory, map[string]any{"path": src, "remote": "offsite"})
+	require.False(t, res.IsError, res.Content)
+	var run BackupRun
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &run))
+	assert.Equal(t, filepath.Join(offsite, run.Archive), run.Destination)
+
+	// Even a configured sftp URL cannot smuggle options to scp
+	run = BackupRun{StartedAt: time.Now()}
+	err = handler.executeBackup(context.Background(), &run, src, "sftp://-oProxyCommand=id/dir", false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid sftp remote host")
+}
+
+func TestParseCron(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC) // Monday
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, 1, 1, 10, 45, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2024, 1, 2, 2, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)},
+		{"@every 6h", base.Add(6 * time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			c, err := parseCron(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, c.Next(base))
+		})
+	}
+
+	for _, bad := range []string{"* * *", "61 * * * *", "@every 5s", "a b c d e"} {
+		_, err := parseCron(bad)
+		assert.Error(t, err, bad)
+	}
+}
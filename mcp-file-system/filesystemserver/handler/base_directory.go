@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// pathArguments are the tool arguments holding local paths, which may be
+// given relative to the base directory
+var pathArguments = []string{"path", "source", "destination", "output_dir", "output_path", "thumbnail_path", "path_a", "path_b"}
+
+// pathListArguments are the tool arguments holding lists of local paths
+var pathListArguments = []string{"paths", "sources"}
+
+// WithBaseDirectory sets the directory that relative paths in tool arguments
+// are resolved against, such as "src/main.go". It must lie within the
+// allowed directories and may be a directory alias. Sessions can choose
+// their own with set_base_directory.
+func WithBaseDirectory(dir string) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		fs.baseDir = dir
+	}
+}
+
+// sessionID returns the ID of the MCP session behind ctx, if any
+func sessionID(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
+
+// baseDirectory returns the base directory of the session behind ctx, or
+// the server-wide one. It is empty when neither is set.
+func (fs *FilesystemHandler) baseDirectory(ctx context.Context) string {
+	if base, ok := fs.sessionBases.Load(sessionID(ctx)); ok {
+		return base.(string)
+	}
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	return fs.baseDir
+}
+
+// resolvePath expands a leading directory alias and makes a relative local
+// path absolute against the base directory of ctx. Absolute and SMB paths
+// are returned unchanged, and so are relative paths when there is no base
+// directory.
+func (fs *FilesystemHandler) resolvePath(ctx context.Context, path string) (string, error) {
+	if strings.HasPrefix(path, aliasPrefix) {
+		return fs.expandAlias(path)
+	}
+	if path == "" || isSMBPath(path) || filepath.IsAbs(path) {
+		return path, nil
+	}
+	base := fs.baseDirectory(ctx)
+	if base == "" {
+		return path, nil
+	}
+	return filepath.Join(base, path), nil
+}
+
+// ResolveToolCall wraps a tool handler so directory aliases and relative
+// paths in its path arguments are resolved before the handler validates
+// them. The operation log and call logs see the resolved paths.
+func (fs *FilesystemHandler) ResolveToolCall(next ToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.GetArguments()
+		if len(args) == 0 {
+			return next(ctx, request)
+		}
+		resolved := maps.Clone(args)
+		for _, key := range pathArguments {
+			if path, ok := args[key].(string); ok {
+				resolvedPath, err := fs.resolvePath(ctx, path)
+				if err != nil {
+					return errorResult(fmt.Errorf("Error: %w", err)), nil
+				}
+				resolved[key] = resolvedPath
+			}
+		}
+		for _, key := range pathListArguments {
+			paths, ok := args[key].([]any)
+			if !ok {
+				continue
+			}
+			list := make([]any, len(paths))
+			for i, p := range paths {
+				if path, ok := p.(string); ok {
+					resolvedPath, err := fs.resolvePath(ctx, path)
+					if err != nil {
+						return errorResult(fmt.Errorf("Error: %w", err)), nil
+					}
+					p = resolvedPath
+				}
+				list[i] = p
+			}
+			resolved[key] = list
+		}
+		request.Params.Arguments = resolved
+		return next(ctx, request)
+	}
+}
+
+// ForgetSession drops the base directory chosen by a session that ended,
+// removes its scratch directory and closes its journal, which stays on disk
+func (fs *FilesystemHandler) ForgetSession(id string) {
+	fs.sessionBases.Delete(id)
+	fs.forgetScratch(id)
+	if journal, ok := fs.journals.LoadAndDelete(id); ok {
+		journal.(*operationLog).close()
+	}
+}
+
+// HandleSetBaseDirectory sets the base directory for relative paths for the
+// rest of the session, or restores the server-wide one when path is empty
+func (fs *FilesystemHandler) HandleSetBaseDirectory(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path := request.GetString("path", "")
+	if path == "" {
+		fs.sessionBases.Delete(sessionID(ctx))
+		if base := fs.baseDirectory(ctx); base != "" {
+			return mcp.NewToolResultText(fmt.Sprintf("Relative paths resolve against the server base directory %s", base)), nil
+		}
+		return mcp.NewToolResultText("Relative paths resolve against the server's working directory"), nil
+	}
+
+	resolvedPath, err := fs.resolvePath(ctx, path)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	validPath, err := fs.validatePath(resolvedPath)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if !info.IsDir() {
+		return errorResult(toolError(CodeWrongType, "Error: %s is not a directory", validPath).With("path", validPath)), nil
+	}
+	fs.sessionBases.Store(sessionID(ctx), validPath)
+	return mcp.NewToolResultText(fmt.Sprintf("Relative paths now resolve against %s", validPath)), nil
+}
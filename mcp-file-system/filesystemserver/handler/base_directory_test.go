@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseDirectory(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "project", "src"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "project", "src", "main.go"), []byte("package main"), 0644))
+
+	_, err := NewFilesystemHandler([]string{dir}, WithBaseDirectory(t.TempDir()))
+	assert.ErrorContains(t, err, "base directory: access denied")
+
+	fsHandler, err := NewFilesystemHandler([]string{dir}, WithBaseDirectory(filepath.Join(dir, "project")))
+	require.NoError(t, err)
+	read := fsHandler.ResolveToolCall(fsHandler.HandleReadFile)
+	res := callTool(t, read, map[string]any{"path": "src/main.go"})
+	require.False(t, res.IsError)
+	assert.Equal(t, "package main", res.Content[0].(mcp.TextContent).Text)
+
+	// Relative paths cannot climb out of the allowed directories
+	res = callTool(t, read, map[string]any{"path": "../../etc/passwd"})
+	assert.Equal(t, CodePathNotAllowed, ResultError(res).Code)
+
+	res = callTool(t, fsHandler.HandleListAllowedDirectories, nil)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Relative paths resolve against "+filepath.Join(dir, "project"))
+}
+
+func TestSetBaseDirectory(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "project", "src"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "project", "src", "main.go"), []byte("package main"), 0644))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	setBase := fsHandler.ResolveToolCall(fsHandler.HandleSetBaseDirectory)
+	read := fsHandler.ResolveToolCall(fsHandler.HandleReadFile)
+
+	res := callTool(t, setBase, map[string]any{"path": filepath.Join(dir, "project", "src", "main.go")})
+	assert.Equal(t, CodeWrongType, ResultError(res).Code)
+
+	res = callTool(t, setBase, map[string]any{"path": filepath.Join(dir, "project")})
+	require.False(t, res.IsError)
+	res = callTool(t, read, map[string]any{"path": "src/main.go"})
+	require.False(t, res.IsError)
+
+	// A relative base is itself resolved against the current one
+	res = callTool(t, setBase, map[string]any{"path": "src"})
+	require.False(t, res.IsError)
+	res = callTool(t, read, map[string]any{"path": "main.go"})
+	require.False(t, res.IsError)
+
+	res = callTool(t, setBase, nil)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "working directory")
+	res = callTool(t, read, map[string]any{"path": "main.go"})
+	assert.True(t, res.IsError)
+}
@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// BatchTransferItem is the outcome for one source of a batch copy or move
+type BatchTransferItem struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	OK          bool   `json:"ok"`
+	Error       string `json:"error,omitempty"`
+}
+
+// BatchTransferResult is the JSON result of copy_file or move_file with
+// sources or a glob
+type BatchTransferResult struct {
+	Operation   string              `json:"operation"`
+	Destination string              `json:"destination"`
+	Results     []BatchTransferItem `json:"results"`
+	Succeeded   int                 `json:"succeeded"`
+	Failed      int                 `json:"failed"`
+}
+
+// isBatchTransfer reports whether a copy_file or move_file call names
+// several sources, either as a list or as a glob
+func isBatchTransfer(request mcp.CallToolRequest) bool {
+	return request.GetString("glob", "") != "" || len(request.GetStringSlice("sources", nil)) > 0
+}
+
+// handleBatchTransfer copies or moves every source into the destination
+// directory, keeping their names. Sources come from the sources list and
+// from the files under the source directory matching glob. Each item is
+// attempted even when an earlier one fails.
+func (fs *FilesystemHandler) handleBatchTransfer(ctx context.Context, request mcp.CallToolRequest, move bool) (*mcp.CallToolResult, error) {
+	operation, verb := "copy", "copied"
+	validateSource := fs.validatePath
+	if move {
+		operation, verb = "move", "moved"
+		validateSource = fs.validateWritablePath
+	}
+
+	destination, err := request.RequireString("destination")
+	if err != nil {
+		return nil, err
+	}
+	onConflict, err := conflictPolicy(request)
+	if err != nil {
+		return errorResult(err), nil
+	}
+	sources := request.GetStringSlice("sources", nil)
+	root := request.GetString("source", "")
+	pattern := request.GetString("glob", "")
+	if pattern != "" && root == "" {
+		return errorResult(toolError(CodeInvalidArgument, "Error: glob requires source, the directory to match it under")), nil
+	}
+	for _, path := range append([]string{root, destination}, sources...) {
+		if isSMBPath(path) {
+			return errorResult(toolError(CodeInvalidArgument, "Error: sources and glob are not supported for SMB paths").With("path", path)), nil
+		}
+	}
+
+	validDest, err := fs.validateCreatablePath(destination, request.GetBool("create_parents", false))
+	if err != nil {
+		return errorResult(fmt.Errorf("Error with destination path: %w", err)), nil
+	}
+	if info, err := os.Stat(validDest); err == nil && !info.IsDir() {
+		return errorResult(toolError(CodeWrongType, "Error: with several sources, destination must be a directory: %s", destination).With("path", destination)), nil
+	}
+
+	if pattern != "" {
+		validRoot, err := validateSource(root)
+		if err != nil {
+			return errorResult(fmt.Errorf("Error with source path: %w", err)), nil
+		}
+		if info, err := os.Stat(validRoot); err != nil {
+			return errorResult(fmt.Errorf("Error accessing source: %w", err)), nil
+		} else if !info.IsDir() {
+			return errorResult(toolError(CodeWrongType, "Error: with glob, source must be a directory: %s", root).With("path", root)), nil
+		}
+		matches, _, err := fs.globMatches(ctx, validRoot, pattern, false, validateSource)
+		if err != nil {
+			return errorResult(err), nil
+		}
+		for _, path := range matches {
+			// Files already in the destination stay where they are
+			if filepath.Dir(path) != validDest {
+				sources = append(sources, path)
+			}
+		}
+	}
+
+	items := make([]BatchTransferItem, len(sources))
+	targets := make(map[string]int, len(sources))
+	isTarget := func(path string) bool {
+		_, ok := targets[path]
+		return ok
+	}
+	for i, source := range sources {
+		items[i] = BatchTransferItem{Source: source}
+		validSource, err := validateSource(source)
+		if err != nil {
+			items[i].Error = err.Error()
+			continue
+		}
+		items[i].Source = validSource
+		items[i].Destination = filepath.Join(validDest, filepath.Base(validSource))
+		if _, err := os.Lstat(validSource); err != nil {
+			items[i].Error = err.Error()
+		} else if first, ok := targets[items[i].Destination]; ok && onConflict != ConflictAutoSuffix {
+			items[i].Error = fmt.Sprintf("%s also goes to %s", items[first].Source, items[i].Destination)
+		} else if items[i].Destination == validSource {
+			items[i].Error = "source is already in the destination directory"
+		} else if dest, err := resolveConflict(items[i].Destination, onConflict, isTarget); err != nil {
+			items[i].Error = err.Error()
+		} else {
+			items[i].Destination = dest
+			targets[dest] = i
+		}
+	}
+
+	if fs.dryRunMode() {
+		var lines []string
+		for _, dir := range missingParents(filepath.Join(validDest, "_")) {
+			lines = append(lines, fmt.Sprintf("Would create directory %s", dir))
+		}
+		for _, item := range items {
+			if item.Error != "" {
+				lines = append(lines, fmt.Sprintf("Would fail to %s %s: %s", operation, item.Source, item.Error))
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("Would %s %s to %s", operation, describeTree(item.Source), item.Destination))
+			if _, err := os.Lstat(item.Destination); err == nil {
+				lines = append(lines, fmt.Sprintf("Would replace %s", describeTree(item.Destination)))
+			}
+		}
+		if len(items) == 0 {
+			lines = append(lines, fmt.Sprintf("No sources to %s", operation))
+		}
+		return dryRunResult(lines...), nil
+	}
+
+	if len(items) > 0 {
+		if err := os.MkdirAll(validDest, 0755); err != nil {
+			return errorResult(fmt.Errorf("Error creating destination directory: %w", err)), nil
+		}
+	}
+	result := BatchTransferResult{Operation: operation, Destination: validDest, Results: items}
+	for i := range items {
+		item := &items[i]
+		if item.Error == "" {
+			if err := fs.transferItem(ctx, item.Source, item.Destination, move); err != nil {
+				item.Error = err.Error()
+			} else {
+				item.OK = true
+			}
+		}
+		if item.OK {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+	}
+
+	if wantsJSON(ctx) && result.Failed == 0 {
+		return jsonResult(ctx, result)
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s %d of %d item(s) to %s", strings.ToUpper(verb[:1])+verb[1:], result.Succeeded, len(items), validDest))
+	if len(items) > 0 {
+		sb.WriteString(":\n")
+	}
+	for _, item := range items {
+		if item.OK {
+			sb.WriteString(fmt.Sprintf("  %s %s -> %s\n", verb, item.Source, item.Destination))
+		} else {
+			sb.WriteString(fmt.Sprintf("  failed %s: %s\n", item.Source, item.Error))
+		}
+	}
+	if result.Failed > 0 {
+		return errorResult(toolError(CodeInternal, "%s", strings.TrimSuffix(sb.String(), "\n")).
+			With("succeeded", result.Succeeded).With("failed", result.Failed)), nil
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// transferItem copies or moves one validated source to dest
+func (fs *FilesystemHandler) transferItem(ctx context.Context, source, dest string, move bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if _, err := fs.validateWritablePath(dest); err != nil {
+		return err
+	}
+	if move {
+		return os.Rename(source, dest)
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return copyDir(source, dest)
+	}
+	return copyFile(source, dest)
+}
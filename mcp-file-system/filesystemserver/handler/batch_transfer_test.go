@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchTransfer(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	inbox := filepath.Join(dir, "inbox")
+	for _, name := range []string{"a.pdf", "b.pdf", "notes.txt", "sub/c.pdf", "processed/old.pdf"} {
+		path := filepath.Join(inbox, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(name), 0644))
+	}
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	processed := filepath.Join(inbox, "processed")
+
+	res := callTool(t, fsHandler.HandleMoveFile, map[string]any{"source": inbox, "glob": "**.pdf", "destination": processed})
+	require.False(t, res.IsError, res.Content)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Moved 3 of 3 item(s)")
+	assert.FileExists(t, filepath.Join(processed, "c.pdf"))
+	assert.FileExists(t, filepath.Join(processed, "old.pdf"))
+	assert.NoFileExists(t, filepath.Join(inbox, "a.pdf"))
+	assert.FileExists(t, filepath.Join(inbox, "notes.txt"))
+
+	// Each item is reported, and one failure does not stop the others
+	backup := filepath.Join(dir, "backup")
+	res = callTool(t, fsHandler.HandleCopyFile, map[string]any{
+		"sources":        []any{filepath.Join(processed, "a.pdf"), filepath.Join(inbox, "missing.pdf"), filepath.Join(inbox, "notes.txt")},
+		"destination":    backup,
+		"create_parents": true,
+	})
+	require.True(t, res.IsError)
+	te := ResultError(res)
+	assert.Equal(t, 2, te.Details["succeeded"])
+	assert.Equal(t, 1, te.Details["failed"])
+	assert.Contains(t, te.Message, "failed "+filepath.Join(inbox, "missing.pdf"))
+	assert.FileExists(t, filepath.Join(backup, "a.pdf"))
+	assert.FileExists(t, filepath.Join(backup, "notes.txt"))
+	assert.FileExists(t, filepath.Join(processed, "a.pdf"))
+
+	res = callTool(t, fsHandler.HandleCopyFile, map[string]any{"glob": "*.pdf", "destination": backup})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	res = callTool(t, fsHandler.HandleCopyFile, map[string]any{"sources": []any{filepath.Join(inbox, "notes.txt")}, "destination": filepath.Join(backup, "a.pdf")})
+	assert.Equal(t, CodeWrongType, ResultError(res).Code)
+}
@@ -0,0 +1,314 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxBulkRenames is the most files one bulk_rename call renames
+const maxBulkRenames = 5000
+
+// renamePlaceholder matches the {name}, {stem}, {ext} and {n} placeholders
+// of a bulk_rename template; {n:3} pads the number to three digits
+var renamePlaceholder = regexp.MustCompile(`\{(name|stem|ext|n)(?::(\d+))?\}`)
+
+// BulkRenameResult is the JSON result of bulk_rename
+type BulkRenameResult struct {
+	Path    string       `json:"path"`
+	Renames []RenameItem `json:"renames"`
+	// Unchanged counts matching files whose new name is their current one
+	Unchanged int  `json:"unchanged,omitempty"`
+	DryRun    bool `json:"dry_run,omitempty"`
+}
+
+// RenameItem is one file renamed by bulk_rename
+type RenameItem struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Replaces is set when an existing file at To is overwritten
+	Replaces bool `json:"replaces,omitempty"`
+	// temp holds the file between the two phases of the rename
+	temp string
+}
+
+// renamer computes the new names of bulk_rename
+type renamer struct {
+	match    glob.Glob
+	re       *regexp.Regexp
+	template string
+}
+
+// HandleBulkRename handles the bulk_rename tool
+func (fs *FilesystemHandler) HandleBulkRename(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	template, err := request.RequireString("template")
+	if err != nil {
+		return nil, err
+	}
+	r, err := newRenamer(request.GetString("glob", ""), request.GetString("regex", ""), template)
+	if err != nil {
+		return errorResult(err), nil
+	}
+	onConflict, err := conflictPolicyOr(request, ConflictFail)
+	if err != nil {
+		return errorResult(err), nil
+	}
+	dryRun := request.GetBool("dry_run", false) || fs.dryRunMode()
+
+	// A dry run only previews the renames, so the directory need not be writable
+	validate := fs.validateWritablePath
+	if dryRun {
+		validate = fs.validatePath
+	}
+	validPath, err := validate(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if info, err := os.Stat(validPath); err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	} else if !info.IsDir() {
+		return errorResult(toolError(CodeWrongType, "Error: %s is not a directory", path).With("path", path)), nil
+	}
+
+	sources, err := fs.renameSources(ctx, validPath, r, request.GetBool("recursive", false))
+	if err != nil {
+		return errorResult(err), nil
+	}
+	result, err := fs.planRenames(validPath, sources, r, onConflict)
+	if err != nil {
+		return errorResult(err), nil
+	}
+	result.DryRun = dryRun
+
+	if dryRun {
+		if wantsJSON(ctx) {
+			return jsonResult(ctx, result)
+		}
+		lines := make([]string, 0, len(result.Renames)+1)
+		for _, item := range result.Renames {
+			lines = append(lines, fmt.Sprintf("Would rename %s to %s", item.From, filepath.Base(item.To)))
+			if item.Replaces {
+				lines = append(lines, fmt.Sprintf("Would replace %s", describeTree(item.To)))
+			}
+		}
+		if len(lines) == 0 {
+			lines = append(lines, "No files would be renamed")
+		}
+		return dryRunResult(lines...), nil
+	}
+
+	if err := commitRenames(result.Renames); err != nil {
+		return errorResult(fmt.Errorf("Error: no files were renamed: %w", err)), nil
+	}
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, result)
+	}
+	if len(result.Renames) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No files in %s needed renaming", validPath)), nil
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Renamed %d file(s) in %s:\n", len(result.Renames), validPath))
+	for _, item := range result.Renames {
+		sb.WriteString(fmt.Sprintf("  %s -> %s\n", item.From, filepath.Base(item.To)))
+	}
+	if result.Unchanged > 0 {
+		sb.WriteString(fmt.Sprintf("%d matching file(s) already had their new name\n", result.Unchanged))
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// newRenamer checks the selection and template of bulk_rename. Exactly one
+// of pattern, a glob, and expr, a regular expression matched against the
+// whole name, selects the files.
+func newRenamer(pattern, expr, template string) (*renamer, error) {
+	r := &renamer{template: template}
+	switch {
+	case (pattern == "") == (expr == ""):
+		return nil, toolError(CodeInvalidArgument, "Error: give either glob or regex to select the files to rename")
+	case pattern != "":
+		g, err := glob.Compile(pattern)
+		if err != nil {
+			return nil, toolError(CodeInvalidArgument, "Error: invalid glob %q: %w", pattern, err)
+		}
+		r.match = g
+	default:
+		re, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, toolError(CodeInvalidArgument, "Error: invalid regular expression %q: %w", expr, err)
+		}
+		r.re = re
+	}
+	if strings.TrimSpace(template) == "" {
+		return nil, toolError(CodeInvalidArgument, "Error: template must not be empty")
+	}
+	return r, nil
+}
+
+// matches reports whether a file name is selected
+func (r *renamer) matches(name string) bool {
+	if r.re != nil {
+		return r.re.MatchString(name)
+	}
+	return r.match.Match(name)
+}
+
+// newName fills the template for the nth selected file, counting from 1.
+// Capture groups are expanded first, as $1 or ${name}, then placeholders.
+func (r *renamer) newName(name string, n int) string {
+	out := r.template
+	if r.re != nil {
+		out = string(r.re.ExpandString(nil, r.template, name, r.re.FindStringSubmatchIndex(name)))
+	}
+	stem, ext := splitExt(name)
+	return renamePlaceholder.ReplaceAllStringFunc(out, func(placeholder string) string {
+		m := renamePlaceholder.FindStringSubmatch(placeholder)
+		switch m[1] {
+		case "name":
+			return name
+		case "stem":
+			return stem
+		case "ext":
+			return ext
+		}
+		width, _ := strconv.Atoi(m[2])
+		return fmt.Sprintf("%0*d", width, n)
+	})
+}
+
+// renameSources collects the files under root whose names r selects,
+// descending into subdirectories when recursive
+func (fs *FilesystemHandler) renameSources(ctx context.Context, root string, r *renamer, recursive bool) (map[string]bool, error) {
+	sources := make(map[string]bool)
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil || path == root {
+			return nil // Skip unreadable entries
+		}
+		if d.IsDir() {
+			// Renaming in the trash would break its ids, and in saved
+			// versions their link to the file they came from
+			if !recursive || !fs.isPathInAllowedDirs(path) || fs.isInTrash(path) || fs.isInVersions(path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if r.matches(d.Name()) && fs.isPathInAllowedDirs(path) {
+			if len(sources) == maxBulkRenames {
+				return toolError(CodeTooLarge, "Error: more than %d files match; narrow the selection", maxBulkRenames).With("limit", maxBulkRenames)
+			}
+			sources[path] = true
+		}
+		return nil
+	})
+	return sources, err
+}
+
+// planRenames works out every new name before anything is renamed, so
+// one bad name renames nothing. A target that another file of the call is
+// renamed away from is free.
+func (fs *FilesystemHandler) planRenames(root string, sources map[string]bool, r *renamer, onConflict string) (*BulkRenameResult, error) {
+	paths := make([]string, 0, len(sources))
+	for path := range sources {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	result := &BulkRenameResult{Path: root, Renames: []RenameItem{}}
+	names := make([]string, len(paths))
+	vacated := make(map[string]bool, len(paths))
+	for i, path := range paths {
+		names[i] = r.newName(filepath.Base(path), i+1)
+		if names[i] == "." || names[i] == ".." || strings.ContainsAny(names[i], `/\`) {
+			return nil, toolError(CodeInvalidArgument, "Error: the new name of %s, %q, is not a plain file name", path, names[i]).With("path", path)
+		}
+		if names[i] != filepath.Base(path) {
+			vacated[path] = true
+		}
+	}
+
+	targets := make(map[string]string, len(paths))
+	isTarget := func(path string) bool {
+		_, ok := targets[path]
+		return ok
+	}
+	for i, path := range paths {
+		if !vacated[path] {
+			result.Unchanged++
+			continue
+		}
+		target := filepath.Join(filepath.Dir(path), names[i])
+		if first, ok := targets[target]; ok && onConflict != ConflictAutoSuffix {
+			return nil, toolError(CodeAlreadyExists, "Error: %s and %s would both be renamed to %s", first, path, names[i]).With("path", target)
+		}
+		if !vacated[target] || isTarget(target) {
+			resolved, err := resolveConflict(target, onConflict, isTarget)
+			if err != nil {
+				return nil, fmt.Errorf("Error renaming %s: %w", path, err)
+			}
+			target = resolved
+		}
+		if _, err := fs.validateWritablePath(target); err != nil {
+			return nil, fmt.Errorf("Error: %w", err)
+		}
+		targets[target] = path
+		result.Renames = append(result.Renames, RenameItem{From: path, To: target, Replaces: exists(target) && !vacated[target]})
+	}
+	return result, nil
+}
+
+// commitRenames renames in two phases, first every file to a temporary
+// name and then each to its target, so files can swap names. On failure
+// the files renamed so far are put back.
+func commitRenames(items []RenameItem) (err error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	tag := hex.EncodeToString(suffix)
+	var staged, placed []int
+	defer func() {
+		if err == nil {
+			return
+		}
+		for _, i := range placed {
+			os.Rename(items[i].To, items[i].temp)
+		}
+		for _, i := range staged {
+			os.Rename(items[i].temp, items[i].From)
+		}
+	}()
+
+	for i := range items {
+		items[i].temp = filepath.Join(filepath.Dir(items[i].From), fmt.Sprintf(".%s.%s.%d.rename", filepath.Base(items[i].From), tag, i))
+		if err := os.Rename(items[i].From, items[i].temp); err != nil {
+			return fmt.Errorf("failed to rename %s: %w", items[i].From, err)
+		}
+		staged = append(staged, i)
+	}
+	for i := range items {
+		if err := os.Rename(items[i].temp, items[i].To); err != nil {
+			return fmt.Errorf("failed to rename %s to %s: %w", items[i].From, items[i].To, err)
+		}
+		placed = append(placed, i)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkRename(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	write := func(names ...string) {
+		for _, name := range names {
+			path := filepath.Join(dir, name)
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+			require.NoError(t, os.WriteFile(path, []byte(name), 0644))
+		}
+	}
+	listing := func(sub string) []string {
+		entries, err := os.ReadDir(filepath.Join(dir, sub))
+		require.NoError(t, err)
+		var names []string
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				names = append(names, entry.Name())
+			}
+		}
+		return names
+	}
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	rename := fsHandler.FormatToolCall(fsHandler.HandleBulkRename)
+	run := func(args map[string]any) *mcp.CallToolResult {
+		args["path"] = dir
+		return callTool(t, rename, args)
+	}
+
+	write("IMG_0042.jpeg", "IMG_0043.jpeg", "notes.txt", "sub/IMG_0001.jpeg")
+	res := run(map[string]any{"regex": `IMG_(\d+)\.jpeg`, "template": "photo-$1.jpg", "dry_run": true})
+	require.False(t, res.IsError, res.Content)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Would rename "+filepath.Join(dir, "IMG_0042.jpeg")+" to photo-0042.jpg")
+	assert.Equal(t, []string{"IMG_0042.jpeg", "IMG_0043.jpeg", "notes.txt"}, listing(""))
+
+	res = run(map[string]any{"regex": `IMG_(\d+)\.jpeg`, "template": "photo-$1.jpg", "format": FormatJSON})
+	require.False(t, res.IsError, res.Content)
+	var result BulkRenameResult
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &result))
+	assert.Len(t, result.Renames, 2)
+	assert.Equal(t, []string{"notes.txt", "photo-0042.jpg", "photo-0043.jpg"}, listing(""))
+	assert.Equal(t, []string{"IMG_0001.jpeg"}, listing("sub"), "subdirectories need recursive")
+
+	// Placeholders, and names shifting onto each other within one call
+	res = run(map[string]any{"glob": "photo-*.jpg", "template": "{n:2}{ext}"})
+	require.False(t, res.IsError, res.Content)
+	assert.Equal(t, []string{"01.jpg", "02.jpg", "notes.txt"}, listing(""))
+	res = run(map[string]any{"glob": "0[12].jpg", "regex": "", "template": "{n:2}.jpg"})
+	require.False(t, res.IsError, res.Content)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "No files in")
+	write("03.jpg")
+	res = run(map[string]any{"regex": `0(\d)\.jpg`, "template": "0${1}0.jpg"})
+	require.False(t, res.IsError, res.Content)
+	res = run(map[string]any{"glob": "0*0.jpg", "template": "{stem}x{ext}", "recursive": true})
+	require.False(t, res.IsError, res.Content)
+	assert.Equal(t, []string{"010x.jpg", "020x.jpg", "030x.jpg", "notes.txt"}, listing(""))
+	content, err := os.ReadFile(filepath.Join(dir, "030x.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, "03.jpg", string(content))
+
+	// Each name is taken over from a file renamed away in the same call
+	write("logs/2.log", "logs/3.log", "logs/a.log")
+	res = callTool(t, rename, map[string]any{"path": filepath.Join(dir, "logs"), "glob": "*.log", "template": "{n}.log"})
+	require.False(t, res.IsError, res.Content)
+	assert.Equal(t, []string{"1.log", "2.log", "3.log"}, listing("logs"))
+	content, err = os.ReadFile(filepath.Join(dir, "logs", "3.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "logs/a.log", string(content))
+
+	// A clash renames nothing
+	res = run(map[string]any{"glob": "*.jpg", "template": "same.jpg"})
+	assert.Equal(t, CodeAlreadyExists, ResultError(res).Code)
+	assert.Equal(t, []string{"010x.jpg", "020x.jpg", "030x.jpg", "notes.txt"}, listing(""))
+	res = run(map[string]any{"glob": "*.txt", "template": "010x.jpg"})
+	assert.Equal(t, CodeAlreadyExists, ResultError(res).Code)
+	res = run(map[string]any{"glob": "*.txt", "template": "010x.jpg", "on_conflict": ConflictAutoSuffix})
+	require.False(t, res.IsError, res.Content)
+	assert.FileExists(t, filepath.Join(dir, "010x (1).jpg"))
+
+	// The trash and saved versions are left alone
+	write(trashDirName+"/files/notes-1.txt", trashDirName+"/info/notes-1.txt.trashinfo", versionsDirName+"/notes.txt")
+	res = run(map[string]any{"glob": "notes*", "template": "old-{name}", "recursive": true})
+	require.False(t, res.IsError, res.Content)
+	assert.Equal(t, []string{"notes-1.txt"}, listing(trashDirName+"/files"))
+	assert.Equal(t, []string{"notes-1.txt.trashinfo"}, listing(trashDirName+"/info"))
+	assert.Equal(t, []string{"notes.txt"}, listing(versionsDirName))
+
+	res = run(map[string]any{"glob": "*.jpg", "template": "../{name}"})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	res = run(map[string]any{"template": "{name}"})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// Checksum algorithms
+const (
+	HashMD5    = "md5"
+	HashSHA1   = "sha1"
+	HashSHA256 = "sha256"
+	HashSHA512 = "sha512"
+	// HashXXHash is the 64-bit xxHash (XXH64), fast but not cryptographic
+	HashXXHash = "xxhash"
+)
+
+// HashAlgorithms lists the checksum algorithms, in the order tools offer them
+var HashAlgorithms = []string{HashMD5, HashSHA1, HashSHA256, HashSHA512, HashXXHash}
+
+// hashConstructors make a hash for each of HashAlgorithms
+var hashConstructors = map[string]func() hash.Hash{
+	HashMD5:    md5.New,
+	HashSHA1:   sha1.New,
+	HashSHA256: sha256.New,
+	HashSHA512: sha512.New,
+	HashXXHash: func() hash.Hash { return xxhash.New() },
+}
+
+// newHash returns a hash for the algorithm named by arg, ignoring case and
+// a dash such as in "SHA-256"
+func newHash(arg, name string) (hash.Hash, string, error) {
+	name = strings.ReplaceAll(strings.ToLower(name), "-", "")
+	if newFn, ok := hashConstructors[name]; ok {
+		return newFn(), name, nil
+	}
+	return nil, "", toolError(CodeInvalidArgument, "Error: %s must be one of %s", arg, strings.Join(HashAlgorithms, ", ")).With(arg, name)
+}
+
+// hashAlgorithmForDigest guesses the algorithm of a hex digest from its
+// length, or returns "" when none has that length
+func hashAlgorithmForDigest(digest string) string {
+	for _, name := range HashAlgorithms {
+		if len(digest) == hashConstructors[name]().Size()*2 {
+			return name
+		}
+	}
+	return ""
+}
+
+// hashStream writes everything r holds to h, checking ctx between chunks,
+// and returns the number of bytes hashed
+func hashStream(ctx context.Context, r io.Reader, h hash.Hash) (int64, error) {
+	var total int64
+	buf := make([]byte, 64*1024)
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		n, err := r.Read(buf)
+		if n > 0 {
+			h.Write(buf[:n])
+			total += int64(n)
+		}
+		if errors.Is(err, io.EOF) {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
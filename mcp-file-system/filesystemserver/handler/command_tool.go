@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultCommandToolTimeout bounds a command tool call unless configured otherwise
+const defaultCommandToolTimeout = 60 * time.Second
+
+// CommandTool is a site-specific tool served by an external process. Each
+// call runs Command with the tool arguments written to its stdin as a JSON
+// object; stdout becomes the tool result. A non-zero exit status is reported
+// as a tool error carrying stderr.
+type CommandTool struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Command is the executable and its fixed arguments
+	Command []string `json:"command" yaml:"command"`
+	// Arguments are the tool's input parameters
+	Arguments []CommandToolArgument `json:"arguments,omitempty" yaml:"arguments,omitempty"`
+	// Timeout bounds each call (default: 60s)
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// CommandToolArgument describes one input parameter of a CommandTool
+type CommandToolArgument struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Type is string (default), number or boolean
+	Type     string `json:"type,omitempty" yaml:"type,omitempty"`
+	Required bool   `json:"required,omitempty" yaml:"required,omitempty"`
+	// Path marks a file path that must lie within the allowed directories;
+	// the command receives it resolved to an absolute path
+	Path bool `json:"path,omitempty" yaml:"path,omitempty"`
+	// Writable marks a path the command modifies, which is rejected in
+	// read-only directories and read-only mode. It implies Path.
+	Writable bool `json:"writable,omitempty" yaml:"writable,omitempty"`
+}
+
+// Validate checks that the tool can be registered
+func (c CommandTool) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("command tool: name is required")
+	}
+	if len(c.Command) == 0 {
+		return fmt.Errorf("command tool %s: command is required", c.Name)
+	}
+	seen := make(map[string]bool, len(c.Arguments))
+	for _, arg := range c.Arguments {
+		if arg.Name == "" {
+			return fmt.Errorf("command tool %s: argument name is required", c.Name)
+		}
+		if seen[arg.Name] {
+			return fmt.Errorf("command tool %s: duplicate argument %q", c.Name, arg.Name)
+		}
+		seen[arg.Name] = true
+		switch arg.Type {
+		case "", "string":
+		case "number", "boolean":
+			if arg.Path || arg.Writable {
+				return fmt.Errorf("command tool %s: path argument %q must be a string", c.Name, arg.Name)
+			}
+		default:
+			return fmt.Errorf("command tool %s: argument %q has unknown type %q (valid: string, number, boolean)", c.Name, arg.Name, arg.Type)
+		}
+	}
+	return nil
+}
+
+// writes reports whether the tool declares a writable path argument
+func (c CommandTool) writes() bool {
+	for _, arg := range c.Arguments {
+		if arg.Writable {
+			return true
+		}
+	}
+	return false
+}
+
+// MCPTool returns the MCP tool definition
+func (c CommandTool) MCPTool() mcp.Tool {
+	opts := []mcp.ToolOption{mcp.WithDescription(c.Description)}
+	for _, arg := range c.Arguments {
+		props := []mcp.PropertyOption{mcp.Description(arg.Description)}
+		if arg.Required {
+			props = append(props, mcp.Required())
+		}
+		switch arg.Type {
+		case "number":
+			opts = append(opts, mcp.WithNumber(arg.Name, props...))
+		case "boolean":
+			opts = append(opts, mcp.WithBoolean(arg.Name, props...))
+		default:
+			opts = append(opts, mcp.WithString(arg.Name, props...))
+		}
+	}
+	return mcp.NewTool(c.Name, opts...)
+}
+
+// HandleCommandTool returns the handler that runs c for each call
+func (fs *FilesystemHandler) HandleCommandTool(c CommandTool) ToolHandlerFunc {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = defaultCommandToolTimeout
+	}
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.GetArguments()
+		input := make(map[string]any, len(c.Arguments))
+		for _, arg := range c.Arguments {
+			value, ok := args[arg.Name]
+			if !ok || value == nil {
+				if arg.Required {
+					return errorResult(toolError(CodeInvalidArgument, "%s is required", arg.Name)), nil
+				}
+				continue
+			}
+			if arg.Path || arg.Writable {
+				path, ok := value.(string)
+				if !ok {
+					return errorResult(toolError(CodeInvalidArgument, "%s must be a string", arg.Name)), nil
+				}
+				validate := fs.validatePath
+				if arg.Writable {
+					validate = fs.validateWritablePath
+				}
+				resolvedPath, err := fs.resolvePath(ctx, path)
+				if err != nil {
+					return errorResult(fmt.Errorf("%s: %w", arg.Name, err)), nil
+				}
+				validPath, err := validate(resolvedPath)
+				if err != nil {
+					return errorResult(fmt.Errorf("%s: %w", arg.Name, err)), nil
+				}
+				value = validPath
+			}
+			input[arg.Name] = value
+		}
+		body, err := json.Marshal(input)
+		if err != nil {
+			return errorResult(fmt.Errorf("failed to encode arguments: %w", err)), nil
+		}
+		// The command's effects are unknown, so only tools that modify
+		// declared paths are skipped
+		if fs.dryRunMode() && c.writes() {
+			return dryRunResult(fmt.Sprintf("Would run %s with arguments %s", strings.Join(c.Command, " "), body)), nil
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, c.Command[0], c.Command[1:]...)
+		cmd.Stdin = bytes.NewReader(body)
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return errorResult(toolError(CodeTimeout, "%s timed out after %s", c.Name, timeout)), nil
+			}
+			msg := strings.TrimSpace(stderr.String())
+			if msg == "" {
+				msg = err.Error()
+			}
+			return errorResult(toolError(CodeExternalFailed, "%s failed: %s", c.Name, msg)), nil
+		}
+		return mcp.NewToolResultText(stdout.String()), nil
+	}
+}
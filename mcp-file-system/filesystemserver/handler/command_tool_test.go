@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleCommandTool(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script")
+	}
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	readOnly, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	fsHandler, err := NewFilesystemHandler([]string{dir, readOnly}, WithReadOnlyDirs([]string{readOnly}))
+	require.NoError(t, err)
+
+	// The script echoes the JSON arguments it receives on stdin
+	script := filepath.Join(t.TempDir(), "tool.sh")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\ncat\n"), 0755))
+	tool := CommandTool{
+		Name:    "echo_args",
+		Command: []string{script},
+		Arguments: []CommandToolArgument{
+			{Name: "target", Required: true, Writable: true},
+			{Name: "count", Type: "number"},
+		},
+	}
+	require.NoError(t, tool.Validate())
+	assert.Contains(t, tool.MCPTool().InputSchema.Required, "target")
+
+	call := func(args map[string]any) *mcp.CallToolResult {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		res, err := fsHandler.HandleCommandTool(tool)(context.Background(), req)
+		require.NoError(t, err)
+		return res
+	}
+
+	res := call(map[string]any{"target": filepath.Join(dir, "out.txt"), "count": 2})
+	require.False(t, res.IsError)
+	assert.JSONEq(t,
+		`{"target": "`+filepath.Join(dir, "out.txt")+`", "count": 2}`,
+		res.Content[0].(mcp.TextContent).Text,
+	)
+
+	assert.True(t, call(map[string]any{}).IsError)
+	assert.True(t, call(map[string]any{"target": "/etc/passwd"}).IsError)
+	assert.True(t, call(map[string]any{"target": filepath.Join(readOnly, "out.txt")}).IsError)
+
+	tool.Command = []string{"sh", "-c", "echo boom >&2; exit 3"}
+	res = call(map[string]any{"target": filepath.Join(dir, "out.txt")})
+	require.True(t, res.IsError)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "boom")
+}
@@ -0,0 +1,298 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Ways compare_directories decides whether two files differ
+const (
+	// CompareMetadata compares size and modification time
+	CompareMetadata = "metadata"
+	// CompareSize compares only size
+	CompareSize = "size"
+	// CompareHash compares size and then SHA-256 digests
+	CompareHash = "hash"
+)
+
+// maxCompareDiffSize is the largest file compare_directories diffs
+const maxCompareDiffSize = 64 * 1024
+
+// DirectoryComparison is the JSON result of compare_directories. Paths are
+// relative to the compared directories; a directory present on one side
+// only is listed once, with a trailing slash.
+type DirectoryComparison struct {
+	PathA     string           `json:"path_a"`
+	PathB     string           `json:"path_b"`
+	Compare   string           `json:"compare"`
+	Identical int              `json:"identical"`
+	OnlyInA   []string         `json:"only_in_a"`
+	OnlyInB   []string         `json:"only_in_b"`
+	Differing []FileDifference `json:"differing"`
+}
+
+// FileDifference is a path present in both directories that differs
+type FileDifference struct {
+	Path string `json:"path"`
+	// Reason is "type", "size", "modified" or "content"
+	Reason string `json:"reason"`
+	SizeA  int64  `json:"size_a"`
+	SizeB  int64  `json:"size_b"`
+	// Diff is a unified diff, for small text files when asked for
+	Diff string `json:"diff,omitempty"`
+}
+
+// treeEntry is a file or directory found by scanTree
+type treeEntry struct {
+	dir     bool
+	size    int64
+	modTime time.Time
+	// link is set for symbolic links, which only sync_directories lists
+	link bool
+}
+
+// HandleCompareDirectories handles the compare_directories tool
+func (fs *FilesystemHandler) HandleCompareDirectories(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	pathA, err := request.RequireString("path_a")
+	if err != nil {
+		return nil, err
+	}
+	pathB, err := request.RequireString("path_b")
+	if err != nil {
+		return nil, err
+	}
+	compare := request.GetString("compare", CompareMetadata)
+	if !slices.Contains([]string{CompareMetadata, CompareSize, CompareHash}, compare) {
+		return errorResult(toolError(CodeInvalidArgument, "Error: compare must be one of %s, %s, %s", CompareMetadata, CompareSize, CompareHash).With("compare", compare)), nil
+	}
+	withDiffs := request.GetBool("diff", false)
+
+	roots := make([]string, 2)
+	for i, path := range []string{pathA, pathB} {
+		validPath, err := fs.validatePath(path)
+		if err != nil {
+			return errorResult(fmt.Errorf("Error: %w", err)), nil
+		}
+		info, err := os.Stat(validPath)
+		if err != nil {
+			return errorResult(fmt.Errorf("Error: %w", err)), nil
+		}
+		if !info.IsDir() {
+			return errorResult(toolError(CodeWrongType, "Error: %s is not a directory", path).With("path", path)), nil
+		}
+		roots[i] = validPath
+	}
+
+	result, err := fs.compareDirectories(ctx, roots[0], roots[1], compare, withDiffs)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error comparing directories: %w", err)), nil
+	}
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, result)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Compared %s with %s by %s: %d identical, %d only in the first, %d only in the second, %d differing\n",
+		result.PathA, result.PathB, compare, result.Identical, len(result.OnlyInA), len(result.OnlyInB), len(result.Differing)))
+	for _, list := range []struct {
+		title string
+		paths []string
+	}{{"Only in " + result.PathA, result.OnlyInA}, {"Only in " + result.PathB, result.OnlyInB}} {
+		if len(list.paths) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n%s:\n", list.title))
+		for _, path := range list.paths {
+			sb.WriteString(fmt.Sprintf("  %s\n", path))
+		}
+	}
+	if len(result.Differing) > 0 {
+		sb.WriteString("\nDiffering:\n")
+		for _, d := range result.Differing {
+			sb.WriteString(fmt.Sprintf("  %s (%s; %d vs %d bytes)\n", d.Path, d.Reason, d.SizeA, d.SizeB))
+			if d.Diff != "" {
+				sb.WriteString(d.Diff)
+			}
+		}
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// compareDirectories compares the trees below rootA and rootB
+func (fs *FilesystemHandler) compareDirectories(ctx context.Context, rootA, rootB, compare string, withDiffs bool) (*DirectoryComparison, error) {
+	a, err := fs.scanTree(ctx, rootA)
+	if err != nil {
+		return nil, err
+	}
+	b, err := fs.scanTree(ctx, rootB)
+	if err != nil {
+		return nil, err
+	}
+	result := &DirectoryComparison{PathA: rootA, PathB: rootB, Compare: compare, OnlyInA: []string{}, OnlyInB: []string{}, Differing: []FileDifference{}}
+	result.OnlyInA = onlyIn(a, b)
+	result.OnlyInB = onlyIn(b, a)
+
+	for _, rel := range slices.Sorted(maps.Keys(a)) {
+		ea, eb := a[rel], b[rel]
+		if eb == nil {
+			continue
+		}
+		if ea.dir && eb.dir {
+			continue
+		}
+		diff := FileDifference{Path: rel, SizeA: ea.size, SizeB: eb.size}
+		diff.Reason, err = differsBy(ctx, ea, eb, filepath.Join(rootA, rel), filepath.Join(rootB, rel), compare)
+		if err != nil {
+			return nil, err
+		}
+		if diff.Reason == "" {
+			result.Identical++
+			continue
+		}
+		if withDiffs && diff.Reason != "type" {
+			diff.Diff = textFileDiff(filepath.Join(rootA, rel), filepath.Join(rootB, rel), rel)
+		}
+		result.Differing = append(result.Differing, diff)
+	}
+	return result, nil
+}
+
+// differsBy returns how the entries at pathA and pathB differ under the
+// compare mode, or "" when they do not
+func differsBy(ctx context.Context, ea, eb *treeEntry, pathA, pathB, compare string) (string, error) {
+	switch {
+	case ea.dir != eb.dir:
+		return "type", nil
+	case ea.dir:
+		return "", nil
+	case ea.size != eb.size:
+		return "size", nil
+	case compare == CompareMetadata && !ea.modTime.Equal(eb.modTime):
+		return "modified", nil
+	case compare == CompareHash:
+		same, err := sameContent(ctx, pathA, pathB)
+		if err != nil || same {
+			return "", err
+		}
+		return "content", nil
+	}
+	return "", nil
+}
+
+// scanTree lists the files and directories below root by their slash
+// separated relative paths, without following symbolic links or entering
+// the trash and versions directories
+func (fs *FilesystemHandler) scanTree(ctx context.Context, root string) (map[string]*treeEntry, error) {
+	entries := make(map[string]*treeEntry)
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil || path == root {
+			return nil
+		}
+		if !fs.isPathInAllowedDirs(path) || fs.isInTrash(path) || fs.isInVersions(path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		entries[filepath.ToSlash(rel)] = &treeEntry{dir: d.IsDir(), size: info.Size(), modTime: info.ModTime()}
+		return nil
+	})
+	return entries, err
+}
+
+// onlyIn lists the paths of a missing from b, leaving out the contents of
+// directories that are missing as a whole
+func onlyIn(a, b map[string]*treeEntry) []string {
+	missing := []string{}
+	var skip string
+	for _, rel := range slices.Sorted(maps.Keys(a)) {
+		if skip != "" && strings.HasPrefix(rel, skip) {
+			continue
+		}
+		skip = ""
+		if b[rel] != nil {
+			continue
+		}
+		if a[rel].dir {
+			skip = rel + "/"
+			missing = append(missing, skip)
+			continue
+		}
+		missing = append(missing, rel)
+	}
+	return missing
+}
+
+// sameContent reports whether two files have the same SHA-256 digest
+func sameContent(ctx context.Context, pathA, pathB string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	digest := func(path string) ([]byte, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return nil, err
+		}
+		return h.Sum(nil), nil
+	}
+	da, err := digest(pathA)
+	if err != nil {
+		return false, err
+	}
+	db, err := digest(pathB)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(da, db), nil
+}
+
+// textFileDiff returns a unified diff of two small text files, or "" when
+// either is too large or binary
+func textFileDiff(pathA, pathB, rel string) string {
+	var texts [2]string
+	for i, path := range []string{pathA, pathB} {
+		info, err := os.Stat(path)
+		if err != nil || info.Size() > maxCompareDiffSize {
+			return ""
+		}
+		content, err := os.ReadFile(path)
+		if err != nil || looksBinary(content) {
+			return ""
+		}
+		texts[i] = string(content)
+	}
+	return unifiedDiff("a/"+rel, "b/"+rel, texts[0], texts[1])
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareDirectories(t *testing.T) {
+	dirs := resolveAllowedDirs(t, t.TempDir(), t.TempDir())
+	a, b := dirs[0], dirs[1]
+	write := func(root, name, content string) {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	write(a, "same.txt", "same\n")
+	write(b, "same.txt", "same\n")
+	write(a, "edited.txt", "one\ntwo\n")
+	write(b, "edited.txt", "one\nTWO\n")
+	write(a, "longer.txt", "short\n")
+	write(b, "longer.txt", "much longer\n")
+	write(a, "only-a.txt", "a")
+	write(a, "gone/x.txt", "x")
+	write(a, "gone/y/z.txt", "z")
+	write(b, "only-b/new.txt", "b")
+	write(a, "kind", "file")
+	require.NoError(t, os.MkdirAll(filepath.Join(b, "kind"), 0755))
+	// Give the identical file different times, as a copy would have
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(b, "same.txt"), old, old))
+
+	fsHandler, err := NewFilesystemHandler(dirs)
+	require.NoError(t, err)
+	compare := fsHandler.FormatToolCall(fsHandler.HandleCompareDirectories)
+
+	run := func(args map[string]any) DirectoryComparison {
+		args["path_a"], args["path_b"], args["format"] = a, b, FormatJSON
+		res := callTool(t, compare, args)
+		require.False(t, res.IsError, res.Content)
+		var got DirectoryComparison
+		require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &got))
+		return got
+	}
+
+	got := run(map[string]any{"compare": CompareHash, "diff": true})
+	assert.Equal(t, []string{"gone/", "only-a.txt"}, got.OnlyInA)
+	assert.Equal(t, []string{"only-b/"}, got.OnlyInB)
+	assert.Equal(t, 1, got.Identical)
+	require.Len(t, got.Differing, 3)
+	assert.Equal(t, "edited.txt", got.Differing[0].Path)
+	assert.Equal(t, "content", got.Differing[0].Reason)
+	assert.Contains(t, got.Differing[0].Diff, "-two\n+TWO\n")
+	assert.Equal(t, FileDifference{Path: "kind", Reason: "type", SizeA: 4, SizeB: got.Differing[1].SizeB}, got.Differing[1])
+	assert.Equal(t, "size", got.Differing[2].Reason)
+
+	// Identical content with different times differs by metadata
+	got = run(map[string]any{})
+	reasons := map[string]string{}
+	for _, d := range got.Differing {
+		reasons[d.Path] = d.Reason
+		assert.Empty(t, d.Diff)
+	}
+	assert.Equal(t, "modified", reasons["same.txt"])
+	assert.Equal(t, "size", reasons["longer.txt"])
+
+	got = run(map[string]any{"compare": CompareSize})
+	assert.Equal(t, 2, got.Identical)
+
+	res := callTool(t, compare, map[string]any{"path_a": a, "path_b": filepath.Join(a, "same.txt")})
+	assert.Equal(t, CodeWrongType, ResultError(res).Code)
+	res = callTool(t, compare, map[string]any{"path_a": a, "path_b": b, "compare": "mtime"})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+}
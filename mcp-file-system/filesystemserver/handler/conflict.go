@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// What copy_file, move_file and croc_receive do when a destination exists
+const (
+	// ConflictOverwrite replaces the existing file, as these tools always did
+	ConflictOverwrite = "overwrite"
+	// ConflictFail leaves the existing file alone and fails
+	ConflictFail = "fail"
+	// ConflictAutoSuffix picks a free name such as "report (1).pdf"
+	ConflictAutoSuffix = "auto_suffix"
+)
+
+// maxConflictSuffix bounds the search for a free name
+const maxConflictSuffix = 10000
+
+// conflictPolicy returns the on_conflict argument of request
+func conflictPolicy(request mcp.CallToolRequest) (string, error) {
+	return conflictPolicyOr(request, ConflictOverwrite)
+}
+
+// conflictPolicyOr returns the on_conflict argument of request, or def when
+// it is absent
+func conflictPolicyOr(request mcp.CallToolRequest, def string) (string, error) {
+	policy := request.GetString("on_conflict", def)
+	if !slices.Contains([]string{ConflictOverwrite, ConflictFail, ConflictAutoSuffix}, policy) {
+		return "", toolError(CodeInvalidArgument, "Error: on_conflict must be one of %s, %s, %s", ConflictOverwrite, ConflictFail, ConflictAutoSuffix).With("on_conflict", policy)
+	}
+	return policy, nil
+}
+
+// exists reports whether something is at path, without following a
+// symbolic link there
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// resolveConflict returns where to put something destined for path under
+// policy. taken reports names already claimed by other items of the same
+// call, and may be nil.
+func resolveConflict(path, policy string, taken func(string) bool) (string, error) {
+	inUse := func(p string) bool { return exists(p) || (taken != nil && taken(p)) }
+	if !inUse(path) {
+		return path, nil
+	}
+	switch policy {
+	case ConflictFail:
+		return "", toolError(CodeAlreadyExists, "destination already exists: %s", path).With("path", path)
+	case ConflictAutoSuffix:
+		dir, name := filepath.Split(path)
+		stem, ext := splitExt(name)
+		for n := 1; n <= maxConflictSuffix; n++ {
+			candidate := filepath.Join(dir, fmt.Sprintf("%s (%d)%s", stem, n, ext))
+			if !inUse(candidate) {
+				return candidate, nil
+			}
+		}
+		return "", toolError(CodeAlreadyExists, "no free name for %s", path).With("path", path)
+	}
+	return path, nil
+}
+
+// splitExt splits a file name before its extension, keeping compound
+// extensions such as ".tar.gz" whole and treating dot files as having none
+func splitExt(name string) (string, string) {
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	if stem == "" {
+		return name, ""
+	}
+	if inner := filepath.Ext(stem); strings.EqualFold(inner, ".tar") {
+		return strings.TrimSuffix(stem, inner), inner + ext
+	}
+	return stem, ext
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitExt(t *testing.T) {
+	for name, want := range map[string][2]string{
+		"report.pdf":     {"report", ".pdf"},
+		"backup.tar.gz":  {"backup", ".tar.gz"},
+		"Makefile":       {"Makefile", ""},
+		".bashrc":        {".bashrc", ""},
+		"archive.v2.zip": {"archive.v2", ".zip"},
+	} {
+		stem, ext := splitExt(name)
+		assert.Equal(t, want, [2]string{stem, ext}, name)
+	}
+}
+
+func TestOnConflict(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	src := filepath.Join(dir, "report.pdf")
+	dest := filepath.Join(dir, "out", "report.pdf")
+	require.NoError(t, os.MkdirAll(filepath.Dir(dest), 0755))
+	require.NoError(t, os.WriteFile(src, []byte("new"), 0644))
+	require.NoError(t, os.WriteFile(dest, []byte("old"), 0644))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	res := callTool(t, fsHandler.HandleCopyFile, map[string]any{"source": src, "destination": dest, "on_conflict": ConflictFail})
+	assert.Equal(t, CodeAlreadyExists, ResultError(res).Code)
+	content, _ := os.ReadFile(dest)
+	assert.Equal(t, "old", string(content))
+
+	res = callTool(t, fsHandler.HandleCopyFile, map[string]any{"source": src, "destination": dest, "on_conflict": ConflictAutoSuffix})
+	require.False(t, res.IsError, res.Content)
+	res = callTool(t, fsHandler.HandleMoveFile, map[string]any{"source": src, "destination": dest, "on_conflict": ConflictAutoSuffix})
+	require.False(t, res.IsError, res.Content)
+	for name, want := range map[string]string{"report.pdf": "old", "report (1).pdf": "new", "report (2).pdf": "new"} {
+		content, err := os.ReadFile(filepath.Join(dir, "out", name))
+		require.NoError(t, err)
+		assert.Equal(t, want, string(content), name)
+	}
+	assert.NoFileExists(t, src)
+
+	// Sources sharing a name get distinct suffixes in a batch
+	for _, sub := range []string{"a", "b"} {
+		path := filepath.Join(dir, sub, "report.pdf")
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(sub), 0644))
+	}
+	res = callTool(t, fsHandler.HandleCopyFile, map[string]any{
+		"sources":     []any{filepath.Join(dir, "a", "report.pdf"), filepath.Join(dir, "b", "report.pdf")},
+		"destination": filepath.Join(dir, "out"),
+		"on_conflict": ConflictAutoSuffix,
+	})
+	require.False(t, res.IsError, res.Content)
+	for name, want := range map[string]string{"report (3).pdf": "a", "report (4).pdf": "b"} {
+		content, err := os.ReadFile(filepath.Join(dir, "out", name))
+		require.NoError(t, err)
+		assert.Equal(t, want, string(content), name)
+	}
+
+	res = callTool(t, fsHandler.HandleMoveFile, map[string]any{"source": src, "destination": dest, "on_conflict": "rename"})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+}
+
+func TestSettleReceived(t *testing.T) {
+	dir := t.TempDir()
+	staging := filepath.Join(dir, ".croc-receive-1")
+	require.NoError(t, os.Mkdir(staging, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(staging, "photo.jpg"), []byte("new"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "photo.jpg"), []byte("old"), 0644))
+
+	_, err := settleReceived(staging, dir, ConflictFail)
+	assert.Equal(t, CodeAlreadyExists, errorCode(err))
+	assert.FileExists(t, filepath.Join(staging, "photo.jpg"))
+
+	renamed, err := settleReceived(staging, dir, ConflictAutoSuffix)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"photo (1).jpg"}, renamed)
+	assert.FileExists(t, filepath.Join(dir, "photo (1).jpg"))
+	assert.NoDirExists(t, staging)
+}
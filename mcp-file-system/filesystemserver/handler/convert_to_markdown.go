@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// convertRouterURLEnv is the streamable HTTP endpoint of the remote convert-router
+	convertRouterURLEnv = "MCP_FS_CONVERT_ROUTER_URL"
+	// convertRouterTokenEnv is an optional bearer token for the convert-router
+	convertRouterTokenEnv = "MCP_FS_CONVERT_ROUTER_TOKEN"
+	// DefaultConvertTimeout bounds the whole send/convert round trip (seconds)
+	DefaultConvertTimeout = 600
+)
+
+// ConvertRouterConfig describes how to reach the remote convert-router MCP server
+type ConvertRouterConfig struct {
+	URL     string            `json:"url" yaml:"url"`
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers"`
+}
+
+// WithConvertRouter configures the remote convert-router used by convert_to_markdown
+func WithConvertRouter(cfg ConvertRouterConfig) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		fs.convertRouter = cfg
+	}
+}
+
+// convertRouterFromEnv builds the convert-router configuration from the environment
+func convertRouterFromEnv() ConvertRouterConfig {
+	cfg := ConvertRouterConfig{URL: os.Getenv(convertRouterURLEnv)}
+	if token := os.Getenv(convertRouterTokenEnv); token != "" {
+		cfg.Headers = map[string]string{"Authorization": "Bearer " + token}
+	}
+	return cfg
+}
+
+// convertRouterResult is the subset of the convert-router response used here
+type convertRouterResult struct {
+	OK           bool   `json:"ok"`
+	MarkdownText string `json:"markdown_text"`
+	EngineUsed   string `json:"engine_used"`
+	ErrorCode    string `json:"error_code"`
+	ErrorMessage string `json:"error_message"`
+	RequestID    string `json:"request_id"`
+}
+
+// ConvertToMarkdownResponse is the JSON response for convert_to_markdown
+type ConvertToMarkdownResponse struct {
+	Source     string `json:"source"`
+	OutputPath string `json:"output_path"`
+	Engine     string `json:"engine,omitempty"`
+	Bytes      int    `json:"bytes"`
+	RequestID  string `json:"request_id,omitempty"`
+	Duration   string `json:"duration"`
+}
+
+// HandleConvertToMarkdown handles the convert_to_markdown client tool. It sends
+// a local file with croc, asks the remote convert-router to receive and convert
+// it, and writes the resulting Markdown into an allowed directory.
+func (fs *FilesystemHandler) HandleConvertToMarkdown(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return errorResult(toolError(CodeInvalidArgument, "path is required")), nil
+	}
+
+	if fs.convertRouter.URL == "" {
+		return errorResult(toolError(CodeUnavailable, "convert-router endpoint is not configured (set %s)", convertRouterURLEnv)), nil
+	}
+
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("path validation failed: %w", err)), nil
+	}
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return errorResult(fmt.Errorf("failed to get file info: %w", err)), nil
+	}
+	if info.IsDir() {
+		return errorResult(toolError(CodeWrongType, "path must be a file")), nil
+	}
+
+	// Resolve and validate the output location before starting the transfer
+	outputPath, _ := request.RequireString("output_path")
+	if outputPath == "" {
+		outputPath = strings.TrimSuffix(validPath, filepath.Ext(validPath)) + ".md"
+	}
+	validOutput, err := fs.validateWritablePath(outputPath)
+	if err != nil {
+		return errorResult(fmt.Errorf("output path validation failed: %w", err)), nil
+	}
+	if info, err := os.Stat(validOutput); err == nil && info.IsDir() {
+		return errorResult(toolError(CodeWrongType, "output_path is a directory")), nil
+	}
+
+	if fs.dryRunMode() {
+		return dryRunResult(
+			fmt.Sprintf("Would send %s to %s via croc for conversion", describeTree(validPath), fs.convertRouter.URL),
+			fmt.Sprintf("Would write the Markdown to %s", validOutput),
+		), nil
+	}
+
+	timeout := DefaultConvertTimeout * time.Second
+	if val, err := request.RequireFloat("timeout_seconds"); err == nil && val > 0 {
+		timeout = time.Duration(val) * time.Second
+	}
+
+	arguments := map[string]any{
+		"return_mode":          "text",
+		"croc_timeout_seconds": int(timeout.Seconds()),
+	}
+	if val, err := request.RequireBool("enable_ocr"); err == nil {
+		arguments["enable_ocr"] = val
+	}
+	if val, err := request.RequireString("language"); err == nil && val != "" {
+		arguments["language"] = val
+	}
+	if val, err := request.RequireString("page_ranges"); err == nil && val != "" {
+		arguments["page_ranges"] = val
+	}
+
+	start := time.Now()
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	code, pid, err := fs.startCrocSend(ctx, validPath)
+	if err != nil {
+		return errorResult(err), nil
+	}
+	arguments["croc_code"] = code
+
+	// Make sure the sender doesn't linger waiting for a receiver that never comes
+	stopSender := func() {
+		if proc, ok := crocManager.GetProcess(pid); ok && proc.cancel != nil {
+			proc.cancel()
+		}
+	}
+	result, err := fs.callConvertRouter(callCtx, pid, arguments)
+	if err != nil {
+		stopSender()
+		return errorResult(toolError(CodeExternalFailed, "conversion failed: %w", err)), nil
+	}
+	if !result.OK {
+		// The router may have refused the file without receiving it
+		stopSender()
+		return errorResult(toolError(CodeExternalFailed, "conversion failed: %s: %s", result.ErrorCode, result.ErrorMessage).With("upstream_code", result.ErrorCode)), nil
+	}
+
+	if err := os.WriteFile(validOutput, []byte(result.MarkdownText), 0644); err != nil {
+		return errorResult(fmt.Errorf("failed to write markdown: %w", err)), nil
+	}
+
+	response := ConvertToMarkdownResponse{
+		Source:     validPath,
+		OutputPath: validOutput,
+		Engine:     result.EngineUsed,
+		Bytes:      len(result.MarkdownText),
+		RequestID:  result.RequestID,
+		Duration:   time.Since(start).Round(time.Millisecond).String(),
+	}
+	jsonBytes, err := json.Marshal(response)
+	if err != nil {
+		return errorResult(fmt.Errorf("failed to marshal response: %w", err)), nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: string(jsonBytes),
+			},
+			mcp.EmbeddedResource{
+				Type: "resource",
+				Resource: mcp.TextResourceContents{
+					URI:      pathToResourceURI(validOutput),
+					MIMEType: "text/markdown",
+					Text:     fmt.Sprintf("Markdown: %s (%d bytes)", validOutput, len(result.MarkdownText)),
+				},
+			},
+		},
+	}, nil
+}
+
+// callConvertRouter invokes convert_to_markdown on the remote convert-router
+// while polling the local croc sender, so a failed send aborts the wait early
+func (fs *FilesystemHandler) callConvertRouter(ctx context.Context, pid int, arguments map[string]any) (*convertRouterResult, error) {
+	var opts []transport.StreamableHTTPCOption
+	if len(fs.convertRouter.Headers) > 0 {
+		opts = append(opts, transport.WithHTTPHeaders(fs.convertRouter.Headers))
+	}
+	c, err := client.NewStreamableHttpClient(fs.convertRouter.URL, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create convert-router client: %w", err)
+	}
+	defer c.Close()
+
+	if err := c.Start(ctx); err != nil {
+		return nil, fmt.Errorf("failed to connect to convert-router: %w", err)
+	}
+
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{
+		Name:    "secure-filesystem-server",
+		Version: "convert-client",
+	}
+	if _, err := c.Initialize(ctx, initRequest); err != nil {
+		return nil, fmt.Errorf("failed to initialize convert-router session: %w", err)
+	}
+
+	type callResult struct {
+		res *mcp.CallToolResult
+		err error
+	}
+	done := make(chan callResult, 1)
+	go func() {
+		callRequest := mcp.CallToolRequest{}
+		callRequest.Params.Name = "convert_to_markdown"
+		callRequest.Params.Arguments = arguments
+		res, err := c.CallTool(ctx, callRequest)
+		done <- callResult{res, err}
+	}()
+
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case r := <-done:
+			if r.err != nil {
+				return nil, r.err
+			}
+			return parseConvertRouterResult(r.res)
+		case <-ticker.C:
+			if proc, ok := crocManager.GetProcess(pid); ok && proc.status == "failed" {
+				return nil, fmt.Errorf("croc send failed before the convert-router received the file")
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// parseConvertRouterResult extracts the JSON payload from a convert-router tool result
+func parseConvertRouterResult(res *mcp.CallToolResult) (*convertRouterResult, error) {
+	for _, content := range res.Content {
+		text, ok := content.(mcp.TextContent)
+		if !ok {
+			continue
+		}
+		var result convertRouterResult
+		if err := json.Unmarshal([]byte(text.Text), &result); err != nil {
+			if res.IsError {
+				return nil, fmt.Errorf("%s", text.Text)
+			}
+			return nil, fmt.Errorf("unexpected convert-router response: %w", err)
+		}
+		return &result, nil
+	}
+	return nil, fmt.Errorf("empty convert-router response")
+}
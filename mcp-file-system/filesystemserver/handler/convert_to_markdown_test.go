@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConvertRouter starts an in-memory convert-router that records the croc code it receives
+func fakeConvertRouter(t *testing.T, markdown string) (string, *string) {
+	t.Helper()
+	var receivedCode string
+	s := server.NewMCPServer("mcp-convert-router", "test")
+	s.AddTool(mcp.NewTool("convert_to_markdown"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		receivedCode, _ = request.RequireString("croc_code")
+		payload, _ := json.Marshal(map[string]any{
+			"ok":            true,
+			"markdown_text": markdown,
+			"engine_used":   "pandoc",
+		})
+		return mcp.NewToolResultText(string(payload)), nil
+	})
+	ts := server.NewTestStreamableHTTPServer(s)
+	t.Cleanup(ts.Close)
+	return ts.URL + "/mcp", &receivedCode
+}
+
+func TestHandleConvertToMarkdown(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "report.docx")
+	require.NoError(t, os.WriteFile(source, []byte("fake docx"), 0644))
+
+	t.Run("endpoint not configured", func(t *testing.T) {
+		handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir), WithConvertRouter(ConvertRouterConfig{}))
+		require.NoError(t, err)
+
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"path": source}
+		result, err := handler.HandleConvertToMarkdown(context.Background(), request)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, convertRouterURLEnv)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		// Stand in for croc with a script that exits successfully
+		fakeCroc := filepath.Join(t.TempDir(), "croc")
+		require.NoError(t, os.WriteFile(fakeCroc, []byte("#!/bin/sh\nexit 0\n"), 0755))
+		oldBinary := crocBinary
+		crocBinary = fakeCroc
+		t.Cleanup(func() { crocBinary = oldBinary })
+
+		url, receivedCode := fakeConvertRouter(t, "# Report\n")
+		handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir), WithConvertRouter(ConvertRouterConfig{URL: url}))
+		require.NoError(t, err)
+
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"path": source}
+		result, err := handler.HandleConvertToMarkdown(context.Background(), request)
+		require.NoError(t, err)
+		require.False(t, result.IsError, result.Content)
+
+		content, err := os.ReadFile(filepath.Join(dir, "report.md"))
+		require.NoError(t, err)
+		assert.Equal(t, "# Report\n", string(content))
+		assert.NotEmpty(t, *receivedCode)
+	})
+
+	t.Run("rejected by the router", func(t *testing.T) {
+		// A sender that would wait for a receiver indefinitely
+		fakeCroc := filepath.Join(t.TempDir(), "croc")
+		require.NoError(t, os.WriteFile(fakeCroc, []byte("#!/bin/sh\nexec sleep 60\n"), 0755))
+		oldBinary := crocBinary
+		crocBinary = fakeCroc
+		t.Cleanup(func() { crocBinary = oldBinary })
+
+		s := server.NewMCPServer("mcp-convert-router", "test")
+		s.AddTool(mcp.NewTool("convert_to_markdown"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			payload, _ := json.Marshal(map[string]any{"ok": false, "error_code": "UNSUPPORTED_FORMAT", "error_message": "no"})
+			return mcp.NewToolResultText(string(payload)), nil
+		})
+		ts := server.NewTestStreamableHTTPServer(s)
+		t.Cleanup(ts.Close)
+		rejected := filepath.Join(dir, "rejected.docx")
+		require.NoError(t, os.WriteFile(rejected, []byte("fake docx"), 0644))
+		handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir), WithConvertRouter(ConvertRouterConfig{URL: ts.URL + "/mcp"}))
+		require.NoError(t, err)
+
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"path": rejected}
+		result, err := handler.HandleConvertToMarkdown(context.Background(), request)
+		require.NoError(t, err)
+		assert.Equal(t, CodeExternalFailed, ResultError(result).Code)
+
+		// The sender is stopped rather than left waiting
+		var sender *crocProcess
+		for pid, proc := range crocManager.ListProcesses() {
+			if proc.filePath == rejected {
+				sender = proc
+				t.Cleanup(func() { crocManager.RemoveProcess(pid) })
+			}
+		}
+		require.NotNil(t, sender)
+		assert.Eventually(t, func() bool { return sender.status == "failed" }, 5*time.Second, 10*time.Millisecond)
+		require.NoError(t, handler.Shutdown(context.Background()))
+	})
+
+	t.Run("output outside allowed directories", func(t *testing.T) {
+		handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir), WithConvertRouter(ConvertRouterConfig{URL: "http://127.0.0.1:1/mcp"}))
+		require.NoError(t, err)
+
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{
+			"path":        source,
+			"output_path": filepath.Join(t.TempDir(), "out.md"),
+		}
+		result, err := handler.HandleConvertToMarkdown(context.Background(), request)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+	})
+}
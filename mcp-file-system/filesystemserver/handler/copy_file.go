@@ -14,6 +14,14 @@ func (fs *FilesystemHandler) HandleCopyFile(
 	ctx context.Context,
 	request mcp.CallToolRequest,
 ) (*mcp.CallToolResult, error) {
+	if isBatchTransfer(request) {
+		return fs.handleBatchTransfer(ctx, request, false)
+	}
+	onConflict, err := conflictPolicy(request)
+	if err != nil {
+		return errorResult(err), nil
+	}
+
 	source, err := request.RequireString("source")
 	if err != nil {
 		return nil, err
@@ -27,124 +35,66 @@ func (fs *FilesystemHandler) HandleCopyFile(
 	if source == "." || source == "./" {
 		cwd, err := os.Getwd()
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					mcp.TextContent{
-						Type: "text",
-						Text: fmt.Sprintf("Error resolving current directory: %v", err),
-					},
-				},
-				IsError: true,
-			}, nil
+			return errorResult(fmt.Errorf("Error resolving current directory: %w", err)), nil
 		}
 		source = cwd
 	}
 	if destination == "." || destination == "./" {
 		cwd, err := os.Getwd()
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					mcp.TextContent{
-						Type: "text",
-						Text: fmt.Sprintf("Error resolving current directory: %v", err),
-					},
-				},
-				IsError: true,
-			}, nil
+			return errorResult(fmt.Errorf("Error resolving current directory: %w", err)), nil
 		}
 		destination = cwd
 	}
 
 	validSource, err := fs.validatePath(source)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error with source path: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error with source path: %w", err)), nil
 	}
 
 	// Check if source exists
 	srcInfo, err := os.Stat(validSource)
 	if os.IsNotExist(err) {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error: Source does not exist: %s", source),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(toolError(CodeNotFound, "Error: Source does not exist: %s", source).With("path", source)), nil
 	} else if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error accessing source: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error accessing source: %w", err)), nil
 	}
 
-	validDest, err := fs.validatePath(destination)
+	validDest, err := fs.validateCreatablePath(destination, request.GetBool("create_parents", false))
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error with destination path: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error with destination path: %w", err)), nil
+	}
+	if validDest, err = resolveConflict(validDest, onConflict, nil); err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+
+	if fs.dryRunMode() {
+		lines := []string{fmt.Sprintf("Would copy %s to %s", describeTree(validSource), validDest)}
+		if _, err := os.Lstat(validDest); err == nil {
+			lines = append(lines, fmt.Sprintf("Would overwrite %s", describeTree(validDest)))
+		}
+		for _, dir := range missingParents(validDest) {
+			lines = append(lines, fmt.Sprintf("Would create directory %s", dir))
+		}
+		return dryRunResult(lines...), nil
 	}
 
 	// Create parent directory for destination if it doesn't exist
 	destDir := filepath.Dir(validDest)
 	if err := os.MkdirAll(destDir, 0755); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error creating destination directory: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error creating destination directory: %w", err)), nil
 	}
 
 	// Perform the copy operation based on whether source is a file or directory
 	if srcInfo.IsDir() {
 		// It's a directory, copy recursively
 		if err := copyDir(validSource, validDest); err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					mcp.TextContent{
-						Type: "text",
-						Text: fmt.Sprintf("Error copying directory: %v", err),
-					},
-				},
-				IsError: true,
-			}, nil
+			return errorResult(fmt.Errorf("Error copying directory: %w", err)), nil
 		}
 	} else {
 		// It's a file, copy directly
 		if err := copyFile(validSource, validDest); err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					mcp.TextContent{
-						Type: "text",
-						Text: fmt.Sprintf("Error copying file: %v", err),
-					},
-				},
-				IsError: true,
-			}, nil
+			return errorResult(fmt.Errorf("Error copying file: %w", err)), nil
 		}
 	}
 
@@ -156,7 +106,7 @@ func (fs *FilesystemHandler) HandleCopyFile(
 				Text: fmt.Sprintf(
 					"Successfully copied %s to %s",
 					source,
-					destination,
+					validDest,
 				),
 			},
 			mcp.EmbeddedResource{
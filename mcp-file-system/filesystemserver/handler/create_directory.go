@@ -17,35 +17,23 @@ func (fs *FilesystemHandler) HandleCreateDirectory(
 		return nil, err
 	}
 
+	if isSMBPath(path) {
+		return fs.smbCreateDirectory(ctx, path)
+	}
+
 	// Handle empty or relative paths like "." or "./" by converting to absolute path
 	if path == "." || path == "./" {
 		// Get current working directory
 		cwd, err := os.Getwd()
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					mcp.TextContent{
-						Type: "text",
-						Text: fmt.Sprintf("Error resolving current directory: %v", err),
-					},
-				},
-				IsError: true,
-			}, nil
+			return errorResult(fmt.Errorf("Error resolving current directory: %w", err)), nil
 		}
 		path = cwd
 	}
 
-	validPath, err := fs.validatePath(path)
+	validPath, err := fs.validateWritablePath(path)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
 	}
 
 	// Check if path already exists
@@ -69,27 +57,15 @@ func (fs *FilesystemHandler) HandleCreateDirectory(
 				},
 			}, nil
 		}
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error: Path exists but is not a directory: %s", path),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(toolError(CodeWrongType, "Error: Path exists but is not a directory: %s", path).With("path", path)), nil
+	}
+
+	if fs.dryRunMode() {
+		return dryRunResult(fmt.Sprintf("Would create directory %s", validPath)), nil
 	}
 
 	if err := os.MkdirAll(validPath, 0755); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error creating directory: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error creating directory: %w", err)), nil
 	}
 
 	resourceURI := pathToResourceURI(validPath)
@@ -0,0 +1,30 @@
+//go:build !windows
+
+package handler
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// findCrocProcess returns the process with the given pid if it is running
+// and, where the command line can be read, is croc. This guards against the
+// pid having been reused since it was recorded.
+func findCrocProcess(pid int) (*os.Process, bool) {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return nil, false
+	}
+	if err := process.Signal(syscall.Signal(0)); err != nil && !errors.Is(err, syscall.EPERM) {
+		return nil, false
+	}
+	// /proc only exists on Linux; elsewhere the pid alone has to do
+	if cmdline, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/cmdline"); err == nil &&
+		!strings.Contains(strings.ToLower(string(cmdline)), "croc") {
+		return nil, false
+	}
+	return process, true
+}
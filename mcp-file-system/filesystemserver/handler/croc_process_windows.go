@@ -0,0 +1,14 @@
+//go:build windows
+
+package handler
+
+import "os"
+
+// findCrocProcess returns the process with the given pid if it is running
+func findCrocProcess(pid int) (*os.Process, bool) {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return nil, false
+	}
+	return process, true
+}
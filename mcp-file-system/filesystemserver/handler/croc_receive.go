@@ -5,11 +5,13 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // CrocReceiveResult contains the result of a croc receive operation
@@ -24,45 +26,73 @@ type CrocReceiveResult struct {
 func (fs *FilesystemHandler) HandleCrocReceive(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	code, err := request.RequireString("code")
 	if err != nil || code == "" {
-		return mcp.NewToolResultError("code is required"), nil
+		return errorResult(toolError(CodeInvalidArgument, "code is required")), nil
+	}
+	onConflict, err := conflictPolicy(request)
+	if err != nil {
+		return errorResult(err), nil
 	}
 
 	// Get output directory (optional, defaults to first allowed directory)
 	outputDir, _ := request.RequireString("output_dir")
 	if outputDir == "" {
-		if len(fs.allowedDirs) > 0 {
+		if len(fs.dirs()) > 0 {
 			// Remove trailing separator for display
-			outputDir = strings.TrimSuffix(fs.allowedDirs[0], string(os.PathSeparator))
+			outputDir = strings.TrimSuffix(fs.dirs()[0], string(os.PathSeparator))
 		} else {
-			return mcp.NewToolResultError("no allowed directories configured"), nil
+			return errorResult(toolError(CodeUnavailable, "no allowed directories configured")), nil
 		}
 	}
 
 	// Validate output directory is within allowed directories
-	validDir, err := fs.validatePath(outputDir)
+	validDir, err := fs.validateWritablePath(outputDir)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("output directory validation failed: %v", err)), nil
+		return errorResult(fmt.Errorf("output directory validation failed: %w", err)), nil
 	}
 
 	// Check if output directory exists
 	info, err := os.Stat(validDir)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return mcp.NewToolResultError(fmt.Sprintf("output directory does not exist: %s", validDir)), nil
+			return errorResult(toolError(CodeNotFound, "output directory does not exist: %s", validDir).With("path", validDir)), nil
 		}
-		return mcp.NewToolResultError(fmt.Sprintf("failed to check output directory: %v", err)), nil
+		return errorResult(fmt.Errorf("failed to check output directory: %w", err)), nil
 	}
 	if !info.IsDir() {
-		return mcp.NewToolResultError(fmt.Sprintf("output path is not a directory: %s", validDir)), nil
+		return errorResult(toolError(CodeWrongType, "output path is not a directory: %s", validDir).With("path", validDir)), nil
+	}
+
+	if fs.dryRunMode() {
+		return dryRunResult(fmt.Sprintf("Would receive the croc transfer into %s", validDir)), nil
+	}
+
+	// Unless existing files may be overwritten, the transfer lands in a
+	// staging directory first and is moved into place once complete
+	receiveDir, staging := validDir, ""
+	if onConflict != ConflictOverwrite {
+		if staging, err = os.MkdirTemp(validDir, ".croc-receive-"); err != nil {
+			return errorResult(fmt.Errorf("failed to create staging directory: %w", err)), nil
+		}
+		receiveDir = staging
+	}
+	discardStaging := func() {
+		if staging != "" {
+			os.RemoveAll(staging)
+		}
 	}
 
+	_, span := fs.tracer.Start(ctx, "croc receive",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("fs.output_dir", validDir)),
+	)
+	defer span.End()
+
 	// Create context with cancel for process management
 	procCtx, cancel := context.WithCancel(context.Background())
 
 	// Start croc receive process with --yes to auto-accept and --out for output directory.
 	// croc v10+ defaults to the new mode; code must be provided via CROC_SECRET (not as a positional arg).
-	cmd := exec.CommandContext(procCtx, "croc", "--yes", "--out", validDir)
-	cmd.Env = append(os.Environ(), fmt.Sprintf("CROC_SECRET=%s", code))
+	cmd := fs.crocCommand(procCtx, code, "--yes", "--out", receiveDir)
 
 	// Set working directory to output directory
 	cmd.Dir = validDir
@@ -70,22 +100,26 @@ func (fs *FilesystemHandler) HandleCrocReceive(ctx context.Context, request mcp.
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		cancel()
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create stdout pipe: %v", err)), nil
+		discardStaging()
+		return errorResult(toolError(CodeCrocFailed, "failed to create stdout pipe: %w", err)), nil
 	}
 
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
 		cancel()
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create stderr pipe: %v", err)), nil
+		discardStaging()
+		return errorResult(toolError(CodeCrocFailed, "failed to create stderr pipe: %w", err)), nil
 	}
 
 	// Start the command
 	if err := cmd.Start(); err != nil {
 		cancel()
-		return mcp.NewToolResultError(fmt.Sprintf("failed to start croc: %v", err)), nil
+		discardStaging()
+		return errorResult(toolError(CodeCrocFailed, "failed to start croc: %w", err)), nil
 	}
 
 	pid := cmd.Process.Pid
+	span.SetAttributes(attribute.Int("process.pid", pid))
 
 	// Create process tracker
 	proc := &crocProcess{
@@ -94,6 +128,8 @@ func (fs *FilesystemHandler) HandleCrocReceive(ctx context.Context, request mcp.
 		startTime: time.Now(),
 		filePath:  validDir,
 		status:    "receiving",
+		direction: "receive",
+		stateDir:  fs.stateDir,
 	}
 	crocManager.AddProcess(pid, proc)
 
@@ -130,6 +166,18 @@ func (fs *FilesystemHandler) HandleCrocReceive(ctx context.Context, request mcp.
 		}
 	}()
 
+	// Report the outcome to webhooks
+	failed := func(reason string) {
+		discardStaging()
+		markSpanError(span, reason)
+		data := map[string]any{"direction": "receive", "pid": pid, "error": reason}
+		fs.emitEvent(EventCrocFailed, validDir, data)
+		if strings.Contains(strings.ToLower(reason), "no space left") ||
+			strings.Contains(strings.ToLower(reason), "quota exceeded") {
+			fs.emitEvent(EventQuotaExceeded, validDir, data)
+		}
+	}
+
 	// Wait for process to complete or timeout
 	doneChan := make(chan error, 1)
 	go func() {
@@ -144,12 +192,19 @@ func (fs *FilesystemHandler) HandleCrocReceive(ctx context.Context, request mcp.
 			// Check if there's stderr output
 			select {
 			case stderrErr := <-errChan:
-				return mcp.NewToolResultError(fmt.Sprintf("croc receive failed: %v", stderrErr)), nil
+				failed(stderrErr.Error())
+				return errorResult(toolError(CodeCrocFailed, "croc receive failed: %w", stderrErr)), nil
 			default:
-				return mcp.NewToolResultError(fmt.Sprintf("croc receive failed: %v", err)), nil
+				failed(err.Error())
+				return errorResult(toolError(CodeCrocFailed, "croc receive failed: %w", err)), nil
 			}
 		}
 		proc.status = "completed"
+		fs.emitEvent(EventCrocCompleted, validDir, map[string]any{
+			"direction": "receive",
+			"pid":       pid,
+			"duration":  time.Since(proc.startTime).Round(time.Millisecond).String(),
+		})
 
 		// Get output info
 		var output string
@@ -159,6 +214,16 @@ func (fs *FilesystemHandler) HandleCrocReceive(ctx context.Context, request mcp.
 			output = "File received"
 		}
 
+		if staging != "" {
+			renamed, err := settleReceived(staging, validDir, onConflict)
+			if err != nil {
+				return errorResult(fmt.Errorf("croc receive completed, but the files were left in %s: %w", staging, err)), nil
+			}
+			for _, name := range renamed {
+				output += "\nSaved as " + name + " to avoid a name conflict"
+			}
+		}
+
 		return mcp.NewToolResultText(fmt.Sprintf(
 			"Croc receive completed successfully.\nOutput directory: %s\n\nDetails:\n%s",
 			validDir, output,
@@ -167,16 +232,50 @@ func (fs *FilesystemHandler) HandleCrocReceive(ctx context.Context, request mcp.
 	case err := <-errChan:
 		cancel()
 		crocManager.RemoveProcess(pid)
-		return mcp.NewToolResultError(fmt.Sprintf("croc error: %v", err)), nil
+		failed(err.Error())
+		return errorResult(toolError(CodeCrocFailed, "croc error: %w", err)), nil
 
-	case <-time.After(10 * time.Minute):
+	case <-time.After(fs.tuning.CrocReceiveTimeout):
 		cancel()
 		crocManager.RemoveProcess(pid)
-		return mcp.NewToolResultError("timeout waiting for croc transfer to complete"), nil
+		reason := fmt.Sprintf("timeout waiting for croc transfer to complete after %s", fs.tuning.CrocReceiveTimeout)
+		failed(reason)
+		return errorResult(toolError(CodeTimeout, "%s", reason)), nil
 
 	case <-ctx.Done():
 		cancel()
 		crocManager.RemoveProcess(pid)
-		return mcp.NewToolResultError("operation cancelled"), nil
+		discardStaging()
+		return errorResult(toolError(CodeCancelled, "operation cancelled")), nil
+	}
+}
+
+// settleReceived moves what croc received into staging to dir under policy,
+// then removes staging. It returns the names of entries given a suffix. With
+// ConflictFail nothing is moved if any entry exists in dir already.
+func settleReceived(staging, dir, policy string) ([]string, error) {
+	entries, err := os.ReadDir(staging)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]string, len(entries))
+	claimed := make(map[string]bool, len(entries))
+	for i, entry := range entries {
+		target, err := resolveConflict(filepath.Join(dir, entry.Name()), policy, func(p string) bool { return claimed[p] })
+		if err != nil {
+			return nil, err
+		}
+		targets[i] = target
+		claimed[target] = true
+	}
+	var renamed []string
+	for i, entry := range entries {
+		if err := os.Rename(filepath.Join(staging, entry.Name()), targets[i]); err != nil {
+			return renamed, err
+		}
+		if name := filepath.Base(targets[i]); name != entry.Name() {
+			renamed = append(renamed, name)
+		}
 	}
+	return renamed, os.Remove(staging)
 }
@@ -14,6 +14,8 @@ import (
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // CrocSendResult contains the result of a croc send operation
@@ -31,17 +33,27 @@ type crocProcess struct {
 	code      string
 	startTime time.Time
 	filePath  string
-	status    string // "waiting", "transferring", "completed", "failed"
+	status    string // "waiting", "transferring", "completed", "failed", "orphaned"
+	direction string // "send" or "receive"
+	// stateDir is where the process is recorded for recovery after a restart
+	stateDir string
+	// recovered marks processes picked up from a previous run of the server;
+	// process is set for those still running
+	recovered bool
+	process   *os.Process
 }
 
 // CrocProcessManager manages croc processes
 type CrocProcessManager struct {
 	mu        sync.RWMutex
 	processes map[int]*crocProcess
+	// recovered lists the state directories whose processes have been recovered
+	recovered map[string]bool
 }
 
 var crocManager = &CrocProcessManager{
 	processes: make(map[int]*crocProcess),
+	recovered: make(map[string]bool),
 }
 
 // CleanupAllProcesses terminates all active croc processes
@@ -50,14 +62,31 @@ func (m *CrocProcessManager) CleanupAllProcesses() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	stateDirs := make(map[string]bool)
 	for pid, proc := range m.processes {
-		if proc.cancel != nil {
-			proc.cancel()
-		}
-		if proc.cmd != nil && proc.cmd.Process != nil {
-			proc.cmd.Process.Kill()
+		// Orphaned transfers stay listed until dismissed
+		if proc.status == "orphaned" {
+			continue
 		}
+		proc.kill()
 		delete(m.processes, pid)
+		stateDirs[proc.stateDir] = true
+	}
+	for stateDir := range stateDirs {
+		m.saveLocked(stateDir)
+	}
+}
+
+// kill stops the process, whether started or adopted by this server
+func (p *crocProcess) kill() {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	if p.cmd != nil && p.cmd.Process != nil {
+		p.cmd.Process.Kill()
+	}
+	if p.process != nil {
+		p.process.Kill()
 	}
 }
 
@@ -71,6 +100,7 @@ func (m *CrocProcessManager) AddProcess(pid int, proc *crocProcess) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.processes[pid] = proc
+	m.saveLocked(proc.stateDir)
 }
 
 // GetProcess gets a process by PID
@@ -85,7 +115,12 @@ func (m *CrocProcessManager) GetProcess(pid int) (*crocProcess, bool) {
 func (m *CrocProcessManager) RemoveProcess(pid int) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	proc, ok := m.processes[pid]
+	if !ok {
+		return
+	}
 	delete(m.processes, pid)
+	m.saveLocked(proc.stateDir)
 }
 
 // ListProcesses returns all active processes
@@ -99,20 +134,62 @@ func (m *CrocProcessManager) ListProcesses() map[int]*crocProcess {
 	return result
 }
 
+// crocBinary is the croc executable to run; overridable in tests
+var crocBinary = "croc"
+
+// CrocConfig customises how croc transfers are run
+type CrocConfig struct {
+	// Binary is the croc executable (default: "croc" from PATH)
+	Binary string `json:"binary,omitempty" yaml:"binary,omitempty"`
+	// Relay is a self-hosted relay address such as "relay.example.com:9009"
+	Relay string `json:"relay,omitempty" yaml:"relay,omitempty"`
+	// RelayPassword authenticates against the relay
+	RelayPassword string `json:"-" yaml:"relay_password,omitempty"`
+}
+
+// WithCroc configures the croc binary and relay used by transfer tools
+func WithCroc(cfg CrocConfig) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		fs.croc = cfg
+	}
+}
+
+// crocCommand builds a croc invocation for the given transfer code,
+// applying the configured binary and relay
+func (fs *FilesystemHandler) crocCommand(ctx context.Context, code string, args ...string) *exec.Cmd {
+	cfg := fs.crocConfig()
+	binary := cfg.Binary
+	if binary == "" {
+		binary = crocBinary
+	}
+	var global []string
+	if cfg.Relay != "" {
+		global = append(global, "--relay", cfg.Relay)
+	}
+	cmd := exec.CommandContext(ctx, binary, append(global, args...)...)
+	// croc v10+ defaults to the new mode; provide code via CROC_SECRET (not via --code).
+	cmd.Env = append(os.Environ(), fmt.Sprintf("CROC_SECRET=%s", code))
+	if cfg.RelayPassword != "" {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("CROC_PASS=%s", cfg.RelayPassword))
+	}
+	return cmd
+}
+
 // Default timeout for waiting for recipient (seconds)
 const DefaultCrocSendTimeout = 300
 
 // CrocSendResponse is the JSON response for croc_send
 type CrocSendResponse struct {
-	Code     string `json:"code"`
-	Status   string `json:"status"`
-	FileName string `json:"file_name"`
-	FileSize int64  `json:"file_size"`
-	PID      int    `json:"pid"`
+	Code       string      `json:"code"`
+	Status     string      `json:"status"`
+	FileName   string      `json:"file_name"`
+	FileSize   int64       `json:"file_size"`
+	PID        int         `json:"pid"`
 	NextAction *NextAction `json:"next_action,omitempty"`
 }
 
-// NextAction describes a machine-executable "what to call next" hint for orchestration layers.
+// NextAction describes a machine-executable "what to call next" hint for
+// orchestration layers, added to results by the routes of WithNextActions.
 type NextAction struct {
 	Tool      string         `json:"tool"`
 	MCP       string         `json:"mcp,omitempty"`
@@ -145,57 +222,89 @@ func generateRandomCode() string {
 func (fs *FilesystemHandler) HandleCrocSend(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	path, err := request.RequireString("path")
 	if err != nil {
-		return mcp.NewToolResultError("path is required"), nil
+		return errorResult(toolError(CodeInvalidArgument, "path is required")), nil
 	}
 
 	// Validate path is within allowed directories
 	validPath, err := fs.validatePath(path)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("path validation failed: %v", err)), nil
+		return errorResult(fmt.Errorf("path validation failed: %w", err)), nil
 	}
 
 	// Get file info for the response
 	fileInfo, err := os.Stat(validPath)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to get file info: %v", err)), nil
+		return errorResult(fmt.Errorf("failed to get file info: %w", err)), nil
 	}
 	fileName := fileInfo.Name()
 	fileSize := fileInfo.Size()
 
+	code, pid, err := fs.startCrocSend(ctx, validPath)
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	// Return immediately with the generated code (async pattern)
+	response := CrocSendResponse{
+		Code:     code,
+		Status:   "waiting_for_receiver",
+		FileName: fileName,
+		FileSize: fileSize,
+		PID:      pid,
+	}
+
+	jsonBytes, err := json.Marshal(response)
+	if err != nil {
+		return errorResult(fmt.Errorf("failed to marshal response: %w", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// startCrocSend launches a background croc send for an already validated path
+// and returns the generated code and process ID
+func (fs *FilesystemHandler) startCrocSend(ctx context.Context, validPath string) (string, int, error) {
 	// Generate random code
 	code := generateRandomCode()
 
+	// The span covers the whole transfer and ends when croc exits
+	_, span := fs.tracer.Start(ctx, "croc send",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("fs.path", validPath)),
+	)
+	fail := func(err error) (string, int, error) {
+		markSpanError(span, err.Error())
+		span.End()
+		return "", 0, err
+	}
+
 	// Create context with cancel for process management
 	procCtx, cancel := context.WithCancel(context.Background())
 
 	// Build croc send command with generated code
-	// croc v10+ defaults to the new mode; provide code via CROC_SECRET (not via --code).
-	args := []string{"--yes", "send", validPath}
-
-	// Start croc send process
-	cmd := exec.CommandContext(procCtx, "croc", args...)
-	cmd.Env = append(os.Environ(), fmt.Sprintf("CROC_SECRET=%s", code))
+	cmd := fs.crocCommand(procCtx, code, "--yes", "send", validPath)
 
 	// Get stdout and stderr pipes for monitoring
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		cancel()
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create stdout pipe: %v", err)), nil
+		return fail(toolError(CodeCrocFailed, "failed to create stdout pipe: %w", err))
 	}
 
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
 		cancel()
-		return mcp.NewToolResultError(fmt.Sprintf("failed to create stderr pipe: %v", err)), nil
+		return fail(toolError(CodeCrocFailed, "failed to create stderr pipe: %w", err))
 	}
 
 	// Start the command
 	if err := cmd.Start(); err != nil {
 		cancel()
-		return mcp.NewToolResultError(fmt.Sprintf("failed to start croc: %v", err)), nil
+		return fail(toolError(CodeCrocFailed, "failed to start croc: %w", err))
 	}
 
 	pid := cmd.Process.Pid
+	span.SetAttributes(attribute.Int("process.pid", pid))
 
 	// Create process tracker
 	proc := &crocProcess{
@@ -205,8 +314,11 @@ func (fs *FilesystemHandler) HandleCrocSend(ctx context.Context, request mcp.Cal
 		startTime: time.Now(),
 		filePath:  validPath,
 		status:    "waiting_for_receiver",
+		direction: "send",
+		stateDir:  fs.stateDir,
 	}
 	crocManager.AddProcess(pid, proc)
+	fs.logger.Debug("croc send started", "path", validPath, "pid", pid)
 
 	// Monitor process in background
 	go func() {
@@ -231,41 +343,38 @@ func (fs *FilesystemHandler) HandleCrocSend(ctx context.Context, request mcp.Cal
 	}()
 
 	// Monitor process completion in background
+	fs.background.Add(1)
 	go func() {
+		defer fs.background.Done()
 		err := cmd.Wait()
+		data := map[string]any{
+			"direction": "send",
+			"pid":       pid,
+			"code":      code,
+			"duration":  time.Since(proc.startTime).Round(time.Millisecond).String(),
+		}
 		if err != nil {
 			proc.status = "failed"
+			data["error"] = err.Error()
+			fs.logger.Warn("croc send failed", "path", validPath, "pid", pid, "error", err)
+			fs.emitEvent(EventCrocFailed, validPath, data)
 		} else {
 			proc.status = "completed"
+			fs.logger.Info("croc send completed", "path", validPath, "pid", pid, "duration", time.Since(proc.startTime))
+			fs.emitEvent(EventCrocCompleted, validPath, data)
 		}
+		crocManager.save(proc.stateDir)
+		if err != nil {
+			markSpanError(span, err.Error())
+		}
+		span.End()
 		// Clean up after 5 minutes
 		time.AfterFunc(5*time.Minute, func() {
 			crocManager.RemoveProcess(pid)
 		})
 	}()
 
-	// Return immediately with the generated code (async pattern)
-	response := CrocSendResponse{
-		Code:     code,
-		Status:   "waiting_for_receiver",
-		FileName: fileName,
-		FileSize: fileSize,
-		PID:      pid,
-		NextAction: &NextAction{
-			Tool: "convert_to_markdown",
-			MCP:  "convert-router（服务端）",
-			Arguments: map[string]any{
-				"croc_code": code,
-			},
-		},
-	}
-
-	jsonBytes, err := json.Marshal(response)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal response: %v", err)), nil
-	}
-
-	return mcp.NewToolResultText(string(jsonBytes)), nil
+	return code, pid, nil
 }
 
 // formatFileSize formats a file size in bytes to a human-readable string
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// crocStateFile is the JSON file under the state directory listing the croc
+// processes that were running, so they can be recovered after a restart
+const crocStateFile = "croc_processes.json"
+
+// crocPollInterval is how often a recovered croc process is checked for exit
+const crocPollInterval = 2 * time.Second
+
+// crocRecord is the persisted form of a crocProcess
+type crocRecord struct {
+	PID       int       `json:"pid"`
+	Code      string    `json:"code,omitempty"`
+	Direction string    `json:"direction"`
+	Path      string    `json:"path"`
+	StartTime time.Time `json:"start_time"`
+	Status    string    `json:"status"`
+}
+
+// finished reports whether a croc status is final
+func finished(status string) bool {
+	switch status {
+	case "completed", "failed", "cancelled", "exited":
+		return true
+	}
+	return false
+}
+
+// saveLocked writes the unfinished processes belonging to stateDir to its
+// croc state file. m.mu must be held.
+func (m *CrocProcessManager) saveLocked(stateDir string) {
+	if stateDir == "" {
+		return
+	}
+	records := []crocRecord{}
+	for pid, proc := range m.processes {
+		if proc.stateDir != stateDir || finished(proc.status) {
+			continue
+		}
+		records = append(records, crocRecord{
+			PID:       pid,
+			Code:      proc.code,
+			Direction: proc.direction,
+			Path:      proc.filePath,
+			StartTime: proc.startTime,
+			Status:    proc.status,
+		})
+	}
+	if err := writeCrocState(stateDir, records); err != nil {
+		slog.Warn("failed to save croc process state", "state_dir", stateDir, "error", err)
+	}
+}
+
+// save records the processes belonging to stateDir after a status change
+func (m *CrocProcessManager) save(stateDir string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.saveLocked(stateDir)
+}
+
+// writeCrocState replaces the croc state file atomically
+func writeCrocState(stateDir string, records []crocRecord) error {
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(stateDir, 0700); err != nil {
+		return err
+	}
+	tmp := filepath.Join(stateDir, crocStateFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(stateDir, crocStateFile))
+}
+
+// RecoverCrocProcesses picks up the croc processes recorded by a previous
+// run of the server. Processes that are still running are adopted: they are
+// listed by croc_status, can be stopped with croc_cancel and are dropped
+// once they exit. The others are listed as orphaned until dismissed with
+// croc_cancel. Each state directory is only recovered once per process. An
+// unreadable state file is logged rather than stopping the server.
+func (fs *FilesystemHandler) RecoverCrocProcesses() {
+	crocManager.mu.Lock()
+	defer crocManager.mu.Unlock()
+	if crocManager.recovered[fs.stateDir] {
+		return
+	}
+	crocManager.recovered[fs.stateDir] = true
+
+	path := filepath.Join(fs.stateDir, crocStateFile)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	var records []crocRecord
+	if err == nil {
+		err = json.Unmarshal(data, &records)
+	}
+	if err != nil {
+		fs.logger.Warn("failed to recover croc processes", "path", path, "error", err)
+		return
+	}
+
+	for _, record := range records {
+		if _, ok := crocManager.processes[record.PID]; ok {
+			continue
+		}
+		proc := &crocProcess{
+			code:      record.Code,
+			direction: record.Direction,
+			filePath:  record.Path,
+			startTime: record.StartTime,
+			status:    record.Status,
+			stateDir:  fs.stateDir,
+			recovered: true,
+		}
+		process, ok := findCrocProcess(record.PID)
+		if !ok {
+			proc.status = "orphaned"
+			crocManager.processes[record.PID] = proc
+			fs.logger.Warn("croc transfer was interrupted by a restart", "pid", record.PID, "direction", record.Direction, "path", record.Path)
+			continue
+		}
+		proc.process = process
+		crocManager.processes[record.PID] = proc
+		fs.logger.Info("adopted running croc process", "pid", record.PID, "direction", record.Direction, "path", record.Path)
+		fs.watchRecoveredCroc(record.PID, proc)
+	}
+	crocManager.saveLocked(fs.stateDir)
+}
+
+// watchRecoveredCroc polls an adopted croc process until it exits. Its exit
+// status is not available to a process that did not start it, so the
+// transfer is reported as exited rather than completed or failed.
+func (fs *FilesystemHandler) watchRecoveredCroc(pid int, proc *crocProcess) {
+	ctx, cancel := fs.untilStopped(context.Background())
+	fs.background.Add(1)
+	go func() {
+		defer fs.background.Done()
+		defer cancel()
+		ticker := time.NewTicker(crocPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if _, alive := findCrocProcess(pid); alive {
+				continue
+			}
+			proc.status = "exited"
+			fs.logger.Info("adopted croc process exited", "pid", pid, "path", proc.filePath)
+			crocManager.RemoveProcess(pid)
+			return
+		}
+	}()
+}
@@ -0,0 +1,80 @@
+//go:build !windows
+
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverCrocProcesses(t *testing.T) {
+	dir := t.TempDir()
+	stateDir := t.TempDir()
+
+	// A croc left running by the previous server, and one that has since died
+	fakeCroc := filepath.Join(t.TempDir(), "croc")
+	require.NoError(t, os.WriteFile(fakeCroc, []byte("#!/bin/sh\nsleep 30\n"), 0755))
+	running := exec.Command(fakeCroc)
+	require.NoError(t, running.Start())
+	t.Cleanup(func() {
+		running.Process.Kill()
+		running.Wait()
+	})
+	exited := exec.Command(fakeCroc)
+	require.NoError(t, exited.Start())
+	require.NoError(t, exited.Process.Kill())
+	exited.Wait()
+
+	started := time.Now().Add(-time.Minute).UTC()
+	require.NoError(t, writeCrocState(stateDir, []crocRecord{
+		{PID: running.Process.Pid, Code: "abc123", Direction: "send", Path: filepath.Join(dir, "a.txt"), StartTime: started, Status: "transferring"},
+		{PID: exited.Process.Pid, Direction: "receive", Path: dir, StartTime: started, Status: "receiving"},
+	}))
+
+	fsHandler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir), WithStateDir(stateDir))
+	require.NoError(t, err)
+	fsHandler.RecoverCrocProcesses()
+	t.Cleanup(func() {
+		crocManager.RemoveProcess(running.Process.Pid)
+		crocManager.RemoveProcess(exited.Process.Pid)
+	})
+
+	adopted, ok := crocManager.GetProcess(running.Process.Pid)
+	require.True(t, ok)
+	assert.Equal(t, "transferring", adopted.status)
+	assert.True(t, adopted.recovered)
+	orphaned, ok := crocManager.GetProcess(exited.Process.Pid)
+	require.True(t, ok)
+	assert.Equal(t, "orphaned", orphaned.status)
+
+	res, err := fsHandler.HandleCrocStatus(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	text := res.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "still running after a server restart")
+	assert.Contains(t, text, "interrupted by a server restart")
+
+	// Both can be cleaned up with croc_cancel
+	for _, pid := range []int{exited.Process.Pid, running.Process.Pid} {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]any{"pid": float64(pid)}
+		res, err := fsHandler.HandleCrocCancel(context.Background(), req)
+		require.NoError(t, err)
+		assert.False(t, res.IsError)
+	}
+	assert.Error(t, running.Wait())
+
+	data, err := os.ReadFile(filepath.Join(stateDir, crocStateFile))
+	require.NoError(t, err)
+	var records []crocRecord
+	require.NoError(t, json.Unmarshal(data, &records))
+	assert.Empty(t, records)
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
@@ -12,7 +13,31 @@ import (
 
 // HandleCrocStatus handles the croc_status tool - lists active croc processes
 func (fs *FilesystemHandler) HandleCrocStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	processes := crocManager.ListProcesses()
+	processes := fs.crocProcesses()
+
+	if wantsJSON(ctx) {
+		status := CrocStatus{Transfers: make([]CrocTransfer, 0, len(processes))}
+		for pid, proc := range processes {
+			transfer := CrocTransfer{
+				PID:             pid,
+				Status:          proc.status,
+				Direction:       proc.direction,
+				Path:            proc.filePath,
+				Code:            proc.code,
+				StartedAt:       proc.startTime,
+				DurationSeconds: int64(time.Since(proc.startTime).Seconds()),
+			}
+			switch {
+			case proc.status == "orphaned":
+				transfer.Note = "interrupted by a server restart"
+			case proc.recovered:
+				transfer.Note = "still running after a server restart; progress is no longer reported"
+			}
+			status.Transfers = append(status.Transfers, transfer)
+		}
+		slices.SortFunc(status.Transfers, func(a, b CrocTransfer) int { return a.PID - b.PID })
+		return jsonResult(ctx, status)
+	}
 
 	if len(processes) == 0 {
 		return mcp.NewToolResultText("No active croc transfers."), nil
@@ -25,12 +50,21 @@ func (fs *FilesystemHandler) HandleCrocStatus(ctx context.Context, request mcp.C
 	for pid, proc := range processes {
 		sb.WriteString(fmt.Sprintf("PID: %d\n", pid))
 		sb.WriteString(fmt.Sprintf("  Status: %s\n", proc.status))
+		if proc.direction != "" {
+			sb.WriteString(fmt.Sprintf("  Direction: %s\n", proc.direction))
+		}
 		sb.WriteString(fmt.Sprintf("  File/Dir: %s\n", proc.filePath))
 		if proc.code != "" {
 			sb.WriteString(fmt.Sprintf("  Code: %s\n", proc.code))
 		}
-		sb.WriteString(fmt.Sprintf("  Started: %s\n", proc.startTime.Format(time.RFC3339)))
+		sb.WriteString(fmt.Sprintf("  Started: %s\n", formatTime(proc.startTime)))
 		sb.WriteString(fmt.Sprintf("  Duration: %s\n", time.Since(proc.startTime).Round(time.Second)))
+		switch {
+		case proc.status == "orphaned":
+			sb.WriteString("  Note: interrupted by a server restart; croc_cancel dismisses it, croc_send or croc_receive starts it again\n")
+		case proc.recovered:
+			sb.WriteString("  Note: still running after a server restart; progress is no longer reported\n")
+		}
 		sb.WriteString("\n")
 	}
 
@@ -41,13 +75,19 @@ func (fs *FilesystemHandler) HandleCrocStatus(ctx context.Context, request mcp.C
 func (fs *FilesystemHandler) HandleCrocCancel(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	pidFloat, err := request.RequireFloat("pid")
 	if err != nil {
-		return mcp.NewToolResultError("pid is required and must be a number"), nil
+		return errorResult(toolError(CodeInvalidArgument, "pid is required and must be a number")), nil
 	}
 	pid := int(pidFloat)
 
-	proc, exists := crocManager.GetProcess(pid)
+	proc, exists := fs.crocProcess(pid)
 	if !exists {
-		return mcp.NewToolResultError(fmt.Sprintf("no croc process found with PID %d", pid)), nil
+		return errorResult(toolError(CodeNotFound, "no croc process found with PID %d", pid)), nil
+	}
+
+	// Orphaned transfers have no process left to stop
+	if proc.status == "orphaned" {
+		crocManager.RemoveProcess(pid)
+		return mcp.NewToolResultText(fmt.Sprintf("Orphaned croc transfer with PID %d has been dismissed.", pid)), nil
 	}
 
 	// Cancel the process
@@ -59,9 +99,39 @@ func (fs *FilesystemHandler) HandleCrocCancel(ctx context.Context, request mcp.C
 	if proc.cmd != nil && proc.cmd.Process != nil {
 		proc.cmd.Process.Signal(syscall.SIGTERM)
 	}
+	if proc.process != nil {
+		proc.process.Signal(syscall.SIGTERM)
+	}
 
 	proc.status = "cancelled"
 	crocManager.RemoveProcess(pid)
 
 	return mcp.NewToolResultText(fmt.Sprintf("Croc transfer with PID %d has been cancelled.", pid)), nil
 }
+
+// crocProcesses lists the transfers this handler may see and cancel: those
+// recorded in its state directory for a path in its allowed directories.
+// Tenant servers share one process table, and a croc code is all it takes
+// to receive a file, so no tenant sees another's transfers.
+func (fs *FilesystemHandler) crocProcesses() map[int]*crocProcess {
+	processes := crocManager.ListProcesses()
+	for pid, proc := range processes {
+		if !fs.ownsCrocProcess(proc) {
+			delete(processes, pid)
+		}
+	}
+	return processes
+}
+
+// crocProcess looks up a transfer this handler may see and cancel
+func (fs *FilesystemHandler) crocProcess(pid int) (*crocProcess, bool) {
+	proc, ok := crocManager.GetProcess(pid)
+	if !ok || !fs.ownsCrocProcess(proc) {
+		return nil, false
+	}
+	return proc, true
+}
+
+func (fs *FilesystemHandler) ownsCrocProcess(proc *crocProcess) bool {
+	return proc.stateDir == fs.stateDir && fs.isPathInAllowedDirs(proc.filePath)
+}
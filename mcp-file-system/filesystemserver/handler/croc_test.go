@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
@@ -136,3 +137,32 @@ func TestCrocStatus(t *testing.T) {
 		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "No active")
 	})
 }
+
+func TestCrocStatusPerTenant(t *testing.T) {
+	dirs := resolveAllowedDirs(t, t.TempDir(), t.TempDir())
+	stateDir := t.TempDir()
+	alice, err := NewFilesystemHandler([]string{dirs[0]}, WithStateDir(stateDir))
+	require.NoError(t, err)
+	bob, err := NewFilesystemHandler([]string{dirs[1]}, WithStateDir(stateDir))
+	require.NoError(t, err)
+
+	const pid = 999999
+	crocManager.AddProcess(pid, &crocProcess{
+		code: "secret-code", filePath: filepath.Join(dirs[0], "report.pdf"), status: "waiting_for_receiver",
+		direction: "send", startTime: time.Now(), stateDir: stateDir,
+	})
+	t.Cleanup(func() { crocManager.RemoveProcess(pid) })
+
+	ctx := context.Background()
+	res, err := bob.HandleCrocStatus(ctx, mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.NotContains(t, res.Content[0].(mcp.TextContent).Text, "secret-code")
+	res = callTool(t, bob.HandleCrocCancel, map[string]any{"pid": pid})
+	assert.Equal(t, CodeNotFound, ResultError(res).Code)
+	_, ok := crocManager.GetProcess(pid)
+	assert.True(t, ok, "another tenant cannot cancel the transfer")
+
+	res, err = alice.HandleCrocStatus(ctx, mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "secret-code")
+}
@@ -17,59 +17,46 @@ func (fs *FilesystemHandler) HandleDeleteFile(
 		return nil, err
 	}
 
+	pattern := request.GetString("glob", "")
+	if isSMBPath(path) {
+		if pattern != "" {
+			return errorResult(toolError(CodeInvalidArgument, "Error: glob is not supported for SMB paths").With("path", path)), nil
+		}
+		if request.GetBool("backup", false) {
+			return errorResult(toolError(CodeInvalidArgument, "Error: backup is not supported for SMB paths").With("path", path)), nil
+		}
+		if request.GetBool("trash", false) {
+			return errorResult(toolError(CodeInvalidArgument, "Error: trash is not supported for SMB paths; delete without it to remove the file permanently").With("path", path)), nil
+		}
+		recursive, _ := request.RequireBool("recursive")
+		return fs.smbDeleteFile(ctx, path, recursive)
+	}
+
 	// Handle empty or relative paths like "." or "./" by converting to absolute path
 	if path == "." || path == "./" {
 		// Get current working directory
 		cwd, err := os.Getwd()
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					mcp.TextContent{
-						Type: "text",
-						Text: fmt.Sprintf("Error resolving current directory: %v", err),
-					},
-				},
-				IsError: true,
-			}, nil
+			return errorResult(fmt.Errorf("Error resolving current directory: %w", err)), nil
 		}
 		path = cwd
 	}
 
-	validPath, err := fs.validatePath(path)
+	if pattern != "" {
+		return fs.handleDeleteGlob(ctx, request, path, pattern)
+	}
+
+	validPath, err := fs.validateWritablePath(path)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
 	}
 
 	// Check if path exists
 	info, err := os.Stat(validPath)
 	if os.IsNotExist(err) {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error: Path does not exist: %s", path),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(toolError(CodeNotFound, "Error: Path does not exist: %s", path).With("path", path)), nil
 	} else if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error accessing path: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error accessing path: %w", err)), nil
 	}
 
 	// Extract recursive parameter (optional, default: false)
@@ -78,31 +65,37 @@ func (fs *FilesystemHandler) HandleDeleteFile(
 		recursive = recursiveParam
 	}
 
+	// Extract trash parameter (optional, default: false)
+	trash := false
+	if trashParam, err := request.RequireBool("trash"); err == nil {
+		trash = trashParam
+	}
+
 	// Check if it's a directory and handle accordingly
 	if info.IsDir() {
 		if !recursive {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					mcp.TextContent{
-						Type: "text",
-						Text: fmt.Sprintf("Error: %s is a directory. Use recursive=true to delete directories.", path),
-					},
-				},
-				IsError: true,
-			}, nil
+			return errorResult(toolError(CodeWrongType, "Error: %s is a directory. Use recursive=true to delete directories.", path).With("path", path)), nil
+		}
+		if request.GetBool("backup", false) && !trash {
+			return errorResult(toolError(CodeInvalidArgument, "Error: backup applies to files; use trash=true to keep %s recoverable", path).With("path", path)), nil
+		}
+	}
+
+	if fs.dryRunMode() {
+		if trash && !fs.isInTrash(validPath) {
+			return dryRunResult(fmt.Sprintf("Would move %s to the trash", describeTree(validPath))), nil
+		}
+		return dryRunResult(fmt.Sprintf("Would permanently delete %s", describeTree(validPath))), nil
+	}
+
+	if info.IsDir() {
+		if trash && !fs.isInTrash(validPath) {
+			return fs.trashResult(validPath, path)
 		}
 
 		// It's a directory and recursive is true, so remove it
 		if err := os.RemoveAll(validPath); err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					mcp.TextContent{
-						Type: "text",
-						Text: fmt.Sprintf("Error deleting directory: %v", err),
-					},
-				},
-				IsError: true,
-			}, nil
+			return errorResult(fmt.Errorf("Error deleting directory: %w", err)), nil
 		}
 
 		return &mcp.CallToolResult{
@@ -115,24 +108,48 @@ func (fs *FilesystemHandler) HandleDeleteFile(
 		}, nil
 	}
 
+	if trash && !fs.isInTrash(validPath) {
+		return fs.trashResult(validPath, path)
+	}
+
+	var backup string
+	if fs.wantsBackup(request) {
+		if backup, err = fs.backupFile(validPath); err != nil {
+			return errorResult(err), nil
+		}
+	}
+
 	// It's a file, delete it
 	if err := os.Remove(validPath); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error deleting file: %v", err),
-				},
+		return errorResult(fmt.Errorf("Error deleting file: %w", err)), nil
+	}
+
+	text := fmt.Sprintf("Successfully deleted file %s", path)
+	if backup != "" {
+		text += fmt.Sprintf("\nBackup: %s (use restore_backup to put it back)", backup)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
 			},
-			IsError: true,
-		}, nil
+		},
+	}, nil
+}
+
+// trashResult moves validPath to the trash and reports the entry id for restore_from_trash
+func (fs *FilesystemHandler) trashResult(validPath, path string) (*mcp.CallToolResult, error) {
+	entry, err := fs.moveToTrash(validPath)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error moving to trash: %w", err)), nil
 	}
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			mcp.TextContent{
 				Type: "text",
-				Text: fmt.Sprintf("Successfully deleted file %s", path),
+				Text: fmt.Sprintf("Moved %s to trash (id: %s). Use restore_from_trash to recover it.", path, entry.ID),
 			},
 		},
 	}, nil
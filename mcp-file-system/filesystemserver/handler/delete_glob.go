@@ -0,0 +1,286 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gobwas/glob"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// deleteConfirmationTTL is how long a delete_file confirmation token stays valid
+const deleteConfirmationTTL = 5 * time.Minute
+
+// pendingDelete is a glob deletion previewed by delete_file and waiting for
+// its confirmation token
+type pendingDelete struct {
+	session   string
+	root      string
+	pattern   string
+	recursive bool
+	trash     bool
+	paths     []string
+	expires   time.Time
+}
+
+// GlobDeletePreview is the JSON result of the first delete_file call with a glob
+type GlobDeletePreview struct {
+	Path    string   `json:"path"`
+	Glob    string   `json:"glob"`
+	Matches []string `json:"matches"`
+	// SkippedDirectories counts matched directories left out without recursive
+	SkippedDirectories int    `json:"skipped_directories"`
+	ConfirmToken       string `json:"confirm_token,omitempty"`
+	ExpiresAt          string `json:"expires_at,omitempty"`
+}
+
+// GlobDeleteItem is the outcome for one path of a confirmed glob deletion
+type GlobDeleteItem struct {
+	Path    string `json:"path"`
+	Deleted bool   `json:"deleted"`
+	TrashID string `json:"trash_id,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// GlobDeleteResult is the JSON result of a confirmed glob deletion
+type GlobDeleteResult struct {
+	Path    string           `json:"path"`
+	Glob    string           `json:"glob"`
+	Results []GlobDeleteItem `json:"results"`
+}
+
+// handleDeleteGlob implements delete_file for a glob pattern under the
+// directory root. The first call lists the matches and returns a
+// confirmation token; only a second call with that token deletes them.
+func (fs *FilesystemHandler) handleDeleteGlob(ctx context.Context, request mcp.CallToolRequest, root, pattern string) (*mcp.CallToolResult, error) {
+	validRoot, err := fs.validateWritablePath(root)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if info, err := os.Stat(validRoot); err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	} else if !info.IsDir() {
+		return errorResult(toolError(CodeWrongType, "Error: with glob, path must be a directory: %s", root).With("path", root)), nil
+	}
+
+	if token := request.GetString("confirm_token", ""); token != "" {
+		return fs.confirmDeleteGlob(ctx, token, validRoot, pattern, request.GetBool("trash", false))
+	}
+
+	recursive := request.GetBool("recursive", false)
+	paths, skippedDirs, err := fs.globMatches(ctx, validRoot, pattern, recursive, fs.validateWritablePath)
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	preview := GlobDeletePreview{Path: validRoot, Glob: pattern, Matches: append([]string{}, paths...), SkippedDirectories: skippedDirs}
+	if len(paths) > 0 {
+		token, err := newConfirmToken()
+		if err != nil {
+			return errorResult(fmt.Errorf("Error creating confirmation token: %w", err)), nil
+		}
+		expires := time.Now().Add(deleteConfirmationTTL)
+		fs.deleteConfirmations.Store(token, &pendingDelete{
+			session:   sessionID(ctx),
+			root:      validRoot,
+			pattern:   pattern,
+			recursive: recursive,
+			trash:     request.GetBool("trash", false),
+			paths:     paths,
+			expires:   expires,
+		})
+		preview.ConfirmToken, preview.ExpiresAt = token, formatTime(expires)
+	}
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, preview)
+	}
+
+	var sb strings.Builder
+	if len(paths) == 0 {
+		sb.WriteString(fmt.Sprintf("No paths under %s match %s. Nothing would be deleted.", validRoot, pattern))
+	} else {
+		sb.WriteString(fmt.Sprintf("%d path(s) under %s match %s and would be deleted:\n", len(paths), validRoot, pattern))
+		for _, path := range paths {
+			sb.WriteString(fmt.Sprintf("  %s\n", describeTree(path)))
+		}
+	}
+	if skippedDirs > 0 {
+		sb.WriteString(fmt.Sprintf("\nSkipped %d matching director(ies); use recursive=true to include them.\n", skippedDirs))
+	}
+	if len(paths) > 0 {
+		sb.WriteString(fmt.Sprintf("\nNothing has been deleted yet. To delete these paths, call delete_file again with the same path and glob and confirm_token %q within %s.",
+			preview.ConfirmToken, deleteConfirmationTTL))
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// confirmDeleteGlob deletes the paths previewed under token. They go to the
+// trash when either call asked for it.
+func (fs *FilesystemHandler) confirmDeleteGlob(ctx context.Context, token, validRoot, pattern string, trash bool) (*mcp.CallToolResult, error) {
+	value, ok := fs.deleteConfirmations.LoadAndDelete(token)
+	if !ok {
+		return errorResult(toolError(CodeNotFound, "Error: unknown or already used confirmation token; call delete_file without confirm_token to list the matches again")), nil
+	}
+	pending := value.(*pendingDelete)
+	fs.pruneDeleteConfirmations()
+	switch {
+	case time.Now().After(pending.expires):
+		return errorResult(toolError(CodeInvalidArgument, "Error: the confirmation token has expired; call delete_file without confirm_token to list the matches again")), nil
+	case pending.session != sessionID(ctx) || pending.root != validRoot || pending.pattern != pattern:
+		return errorResult(toolError(CodeInvalidArgument, "Error: the confirmation token was issued for a different path or glob")), nil
+	}
+	pending.trash = pending.trash || trash
+
+	if fs.dryRunMode() {
+		lines := make([]string, 0, len(pending.paths))
+		for _, path := range pending.paths {
+			if pending.trash && !fs.isInTrash(path) {
+				lines = append(lines, fmt.Sprintf("Would move %s to the trash", describeTree(path)))
+			} else {
+				lines = append(lines, fmt.Sprintf("Would permanently delete %s", describeTree(path)))
+			}
+		}
+		return dryRunResult(lines...), nil
+	}
+
+	result := GlobDeleteResult{Path: validRoot, Glob: pattern, Results: make([]GlobDeleteItem, 0, len(pending.paths))}
+	failed := 0
+	for _, path := range pending.paths {
+		item := GlobDeleteItem{Path: path}
+		if err := fs.deleteMatch(ctx, path, pending, &item); err != nil {
+			item.Error = err.Error()
+			failed++
+		}
+		result.Results = append(result.Results, item)
+	}
+
+	if wantsJSON(ctx) && failed == 0 {
+		return jsonResult(ctx, result)
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Deleted %d of %d path(s) matching %s under %s:\n", len(pending.paths)-failed, len(pending.paths), pattern, validRoot))
+	for _, item := range result.Results {
+		switch {
+		case item.Error != "":
+			sb.WriteString(fmt.Sprintf("  failed %s: %s\n", item.Path, item.Error))
+		case item.TrashID != "":
+			sb.WriteString(fmt.Sprintf("  trashed %s (id: %s)\n", item.Path, item.TrashID))
+		default:
+			sb.WriteString(fmt.Sprintf("  deleted %s\n", item.Path))
+		}
+	}
+	if failed > 0 {
+		return errorResult(toolError(CodeInternal, "%s", strings.TrimSuffix(sb.String(), "\n")).
+			With("deleted", len(pending.paths)-failed).With("failed", failed)), nil
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// deleteMatch deletes or trashes one previewed path, checking it again
+// since the preview
+func (fs *FilesystemHandler) deleteMatch(ctx context.Context, path string, pending *pendingDelete, item *GlobDeleteItem) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	// Only the parent is resolved, so a matched symbolic link is deleted
+	// itself rather than what it points to
+	validParent, err := fs.validateWritablePath(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	validPath := filepath.Join(validParent, filepath.Base(path))
+	if !fs.isPathInAllowedDirs(validPath) {
+		return fmt.Errorf("access denied - path outside allowed directories: %s", path)
+	}
+	info, err := os.Lstat(validPath)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() && !pending.recursive {
+		return fmt.Errorf("%s is now a directory", validPath)
+	}
+	if pending.trash && !fs.isInTrash(validPath) {
+		entry, err := fs.moveToTrash(validPath)
+		if err != nil {
+			return err
+		}
+		item.Deleted, item.TrashID = true, entry.ID
+		return nil
+	}
+	if err := os.RemoveAll(validPath); err != nil {
+		return err
+	}
+	item.Deleted = true
+	return nil
+}
+
+// globMatches walks validRoot for the paths whose slash-separated path
+// relative to it matches pattern and that pass validate. A matching
+// directory is returned whole when withDirs is set and is otherwise only
+// counted, with its contents still considered. More than MaxSearchResults
+// matches is an error.
+func (fs *FilesystemHandler) globMatches(ctx context.Context, validRoot, pattern string, withDirs bool, validate func(string) (string, error)) ([]string, int, error) {
+	match, err := glob.Compile(pattern, '/')
+	if err != nil {
+		return nil, 0, toolError(CodeInvalidArgument, "Error: invalid glob %q: %w", pattern, err)
+	}
+	maxMatches := fs.currentLimits().MaxSearchResults
+	var paths []string
+	skippedDirs := 0
+	err = filepath.WalkDir(validRoot, func(path string, d os.DirEntry, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err != nil || path == validRoot {
+			return nil
+		}
+		rel, err := filepath.Rel(validRoot, path)
+		if err != nil || !match.Match(filepath.ToSlash(rel)) {
+			return nil
+		}
+		if _, err := validate(path); err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if !withDirs {
+				skippedDirs++
+				return nil
+			}
+			paths = append(paths, path)
+			// The directory goes with everything in it
+			return filepath.SkipDir
+		}
+		paths = append(paths, path)
+		if len(paths) > maxMatches {
+			return toolError(CodeTooLarge, "Error: %s matches more than %d paths; use a narrower glob", pattern, maxMatches).With("limit", maxMatches)
+		}
+		return nil
+	})
+	return paths, skippedDirs, err
+}
+
+// pruneDeleteConfirmations drops expired confirmation tokens
+func (fs *FilesystemHandler) pruneDeleteConfirmations() {
+	now := time.Now()
+	fs.deleteConfirmations.Range(func(key, value any) bool {
+		if now.After(value.(*pendingDelete).expires) {
+			fs.deleteConfirmations.Delete(key)
+		}
+		return true
+	})
+}
+
+// newConfirmToken returns a random confirmation token
+func newConfirmToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteFileGlob(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	for _, name := range []string{"a.log", "keep.txt", "logs/b.log", "logs/c.log", "old.log/d.txt"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(name), 0644))
+	}
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	args := map[string]any{"path": dir, "glob": "**.log"}
+
+	// The first call only lists the matches
+	res := callTool(t, fsHandler.HandleDeleteFile, args)
+	require.False(t, res.IsError, res.Content)
+	text := res.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "3 path(s)")
+	assert.Contains(t, text, "Skipped 1 matching director(ies)")
+	assert.FileExists(t, filepath.Join(dir, "a.log"))
+	token := regexp.MustCompile(`confirm_token "([0-9a-f]+)"`).FindStringSubmatch(text)
+	require.Len(t, token, 2)
+
+	res = callTool(t, fsHandler.HandleDeleteFile, map[string]any{"path": dir, "glob": "*.txt", "confirm_token": token[1]})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	// A rejected token is used up
+	res = callTool(t, fsHandler.HandleDeleteFile, map[string]any{"path": dir, "glob": "**.log", "confirm_token": token[1]})
+	assert.Equal(t, CodeNotFound, ResultError(res).Code)
+
+	res = callTool(t, fsHandler.HandleDeleteFile, args)
+	token = regexp.MustCompile(`confirm_token "([0-9a-f]+)"`).FindStringSubmatch(res.Content[0].(mcp.TextContent).Text)
+	require.Len(t, token, 2)
+	// A file created after the preview is not deleted
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "late.log"), nil, 0644))
+	res = callTool(t, fsHandler.HandleDeleteFile, map[string]any{"path": dir, "glob": "**.log", "confirm_token": token[1]})
+	require.False(t, res.IsError, res.Content)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Deleted 3 of 3 path(s)")
+	assert.NoFileExists(t, filepath.Join(dir, "a.log"))
+	assert.NoFileExists(t, filepath.Join(dir, "logs", "c.log"))
+	assert.FileExists(t, filepath.Join(dir, "late.log"))
+	assert.FileExists(t, filepath.Join(dir, "keep.txt"))
+	assert.FileExists(t, filepath.Join(dir, "old.log", "d.txt"))
+
+	// A matched link to a directory is deleted, not the directory
+	data := filepath.Join(dir, "data")
+	require.NoError(t, os.MkdirAll(filepath.Join(data, "inner"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(data, "inner", "f.txt"), []byte("keep"), 0644))
+	work := filepath.Join(dir, "work")
+	require.NoError(t, os.Mkdir(work, 0755))
+	require.NoError(t, os.Symlink(data, filepath.Join(work, "link")))
+	for _, trash := range []bool{false, true} {
+		if trash {
+			require.NoError(t, os.Symlink(data, filepath.Join(work, "link")))
+		}
+		res = callTool(t, fsHandler.HandleDeleteFile, map[string]any{"path": work, "glob": "*", "recursive": true, "trash": trash})
+		token = regexp.MustCompile(`confirm_token "([0-9a-f]+)"`).FindStringSubmatch(res.Content[0].(mcp.TextContent).Text)
+		require.Len(t, token, 2)
+		res = callTool(t, fsHandler.HandleDeleteFile, map[string]any{"path": work, "glob": "*", "confirm_token": token[1]})
+		require.False(t, res.IsError, res.Content)
+		_, err = os.Lstat(filepath.Join(work, "link"))
+		assert.True(t, os.IsNotExist(err), "the link is removed")
+		assert.FileExists(t, filepath.Join(data, "inner", "f.txt"), "trash=%v", trash)
+	}
+
+	res = callTool(t, fsHandler.HandleDeleteFile, map[string]any{"path": dir, "glob": "[", "recursive": true})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+}
@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// fileTypeSampleSize is how much of a file detect_file_type reads
+const fileTypeSampleSize = 64 * 1024
+
+// Line ending styles reported by detect_file_type
+const (
+	LineEndingLF    = "lf"
+	LineEndingCRLF  = "crlf"
+	LineEndingCR    = "cr"
+	LineEndingMixed = "mixed"
+	LineEndingNone  = "none"
+)
+
+// FileTypeResult is the JSON result of detect_file_type
+type FileTypeResult struct {
+	Path     string `json:"path"`
+	MIMEType string `json:"mime_type"`
+	// Extension is the usual extension of the detected type
+	Extension string `json:"extension,omitempty"`
+	// ExtensionMIMEType is the type the file's own extension suggests, and
+	// ExtensionMismatch is set when the content says otherwise
+	ExtensionMIMEType string `json:"extension_mime_type,omitempty"`
+	ExtensionMismatch bool   `json:"extension_mismatch"`
+	Binary            bool   `json:"binary"`
+	// Encoding, BOM and the line endings are given for text files
+	Encoding   string         `json:"encoding,omitempty"`
+	BOM        bool           `json:"bom,omitempty"`
+	LineEnding string         `json:"line_ending,omitempty"`
+	LineCounts *LineEndCounts `json:"line_endings,omitempty"`
+	// Sampled is set when only the start of the file was inspected
+	Sampled bool `json:"sampled"`
+}
+
+// LineEndCounts counts each kind of line break in a text sample
+type LineEndCounts struct {
+	LF   int `json:"lf"`
+	CRLF int `json:"crlf"`
+	CR   int `json:"cr"`
+}
+
+// HandleDetectFileType handles the detect_file_type tool
+func (fs *FilesystemHandler) HandleDetectFileType(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if info.IsDir() {
+		return errorResult(toolError(CodeWrongType, "Error: %s is a directory", path).With("path", path)), nil
+	}
+	f, err := os.Open(validPath)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	defer f.Close()
+	sample := make([]byte, fileTypeSampleSize)
+	n, err := io.ReadFull(f, sample)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return errorResult(fmt.Errorf("Error reading file: %w", err)), nil
+	}
+
+	result := detectFileType(validPath, sample[:n])
+	result.Sampled = info.Size() > int64(n)
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, result)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("File type of %s:\nMIME type: %s", validPath, result.MIMEType))
+	if result.Extension != "" {
+		sb.WriteString(fmt.Sprintf(" (usually %s)", result.Extension))
+	}
+	if result.ExtensionMismatch {
+		sb.WriteString(fmt.Sprintf("\nWarning: the extension suggests %s, but the content does not match", result.ExtensionMIMEType))
+	}
+	if result.Binary {
+		sb.WriteString("\nContent: binary")
+	} else {
+		bom := ""
+		if result.BOM {
+			bom = " with a byte order mark"
+		}
+		sb.WriteString(fmt.Sprintf("\nContent: text\nEncoding: %s%s\nLine endings: %s (LF %d, CRLF %d, CR %d)",
+			result.Encoding, bom, result.LineEnding, result.LineCounts.LF, result.LineCounts.CRLF, result.LineCounts.CR))
+	}
+	if result.Sampled {
+		sb.WriteString(fmt.Sprintf("\nOnly the first %s of %s were inspected", formatFileSize(int64(n)), formatFileSize(info.Size())))
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// detectFileType describes the file at path from a sample of its start
+func detectFileType(path string, sample []byte) FileTypeResult {
+	detected := mimetype.Detect(sample)
+	result := FileTypeResult{
+		Path:              path,
+		MIMEType:          detected.String(),
+		Extension:         detected.Extension(),
+		ExtensionMIMEType: mime.TypeByExtension(filepath.Ext(path)),
+	}
+	result.ExtensionMismatch = extensionMismatch(detected, result.ExtensionMIMEType)
+
+	// UTF-16 text holds NUL bytes, so a byte order mark is checked first
+	for _, bom := range [][]byte{utf8BOM, []byte("\xff\xfe"), []byte("\xfe\xff")} {
+		result.BOM = result.BOM || bytes.HasPrefix(sample, bom)
+	}
+	// Invalid UTF-8 is still text in a legacy encoding when the sniffer
+	// says so, but NUL bytes never are
+	result.Binary = !result.BOM && looksBinary(sample) &&
+		(bytes.IndexByte(sample, 0) >= 0 || !isTextFile(strings.Split(result.MIMEType, ";")[0]))
+	if result.Binary {
+		return result
+	}
+	text, encoding, err := decodeText(sample, EncodingAuto)
+	if err != nil {
+		result.Binary = true
+		return result
+	}
+	result.Encoding = encoding
+	result.LineCounts = countLineEndings(text)
+	result.LineEnding = result.LineCounts.style()
+	return result
+}
+
+// extensionMismatch reports whether content detected as detected disagrees
+// with the type extType its extension suggests. Plain text only disagrees
+// with types that are not text, and unrecognised binary with nothing.
+func extensionMismatch(detected *mimetype.MIME, extType string) bool {
+	if extType == "" || detected.Is("application/octet-stream") {
+		return false
+	}
+	if detected.Is("text/plain") {
+		return !isTextFile(strings.TrimSpace(strings.Split(extType, ";")[0]))
+	}
+	for m := detected; m != nil; m = m.Parent() {
+		if m.Is(extType) {
+			return false
+		}
+	}
+	return true
+}
+
+// countLineEndings counts the line breaks of text by kind
+func countLineEndings(text string) *LineEndCounts {
+	counts := &LineEndCounts{}
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\n':
+			counts.LF++
+		case '\r':
+			if i+1 < len(text) && text[i+1] == '\n' {
+				counts.CRLF++
+				i++
+			} else {
+				counts.CR++
+			}
+		}
+	}
+	return counts
+}
+
+// style names the line ending used, or mixed when there are several
+func (c *LineEndCounts) style() string {
+	var styles []string
+	if c.LF > 0 {
+		styles = append(styles, LineEndingLF)
+	}
+	if c.CRLF > 0 {
+		styles = append(styles, LineEndingCRLF)
+	}
+	if c.CR > 0 {
+		styles = append(styles, LineEndingCR)
+	}
+	switch len(styles) {
+	case 0:
+		return LineEndingNone
+	case 1:
+		return styles[0]
+	}
+	return LineEndingMixed
+}
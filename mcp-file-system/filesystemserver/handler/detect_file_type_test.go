@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectFileType(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	files := map[string][]byte{
+		"unix.txt":    []byte("one\ntwo\n"),
+		"windows.txt": []byte("\xef\xbb\xbfone\r\ntwo\r\n"),
+		"mixed.txt":   []byte("one\r\ntwo\nthree"),
+		"utf16.txt":   {0xff, 0xfe, 'h', 0, 'i', 0, '\r', 0, '\n', 0},
+		"latin1.txt":  []byte("caf\xe9\n"),
+		"fake.png":    []byte("just text\n"),
+		"image.dat":   {0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0, 0, 0, 0x0d, 'I', 'H', 'D', 'R'},
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), content, 0644))
+	}
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	detect := fsHandler.FormatToolCall(fsHandler.HandleDetectFileType)
+	fileType := func(name string) FileTypeResult {
+		res := callTool(t, detect, map[string]any{"path": filepath.Join(dir, name), "format": FormatJSON})
+		require.False(t, res.IsError, res.Content)
+		var got FileTypeResult
+		require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &got))
+		return got
+	}
+
+	got := fileType("unix.txt")
+	assert.False(t, got.Binary)
+	assert.Equal(t, "utf-8", got.Encoding)
+	assert.Equal(t, LineEndingLF, got.LineEnding)
+	assert.False(t, got.ExtensionMismatch)
+	assert.False(t, got.Sampled)
+
+	got = fileType("windows.txt")
+	assert.True(t, got.BOM)
+	assert.Equal(t, LineEndingCRLF, got.LineEnding)
+	assert.Equal(t, &LineEndCounts{CRLF: 2}, got.LineCounts)
+
+	assert.Equal(t, LineEndingMixed, fileType("mixed.txt").LineEnding)
+
+	got = fileType("utf16.txt")
+	assert.False(t, got.Binary)
+	assert.Equal(t, "utf-16le", got.Encoding)
+	assert.Equal(t, LineEndingCRLF, got.LineEnding)
+
+	assert.Equal(t, "windows-1252", fileType("latin1.txt").Encoding)
+
+	got = fileType("fake.png")
+	assert.True(t, got.ExtensionMismatch)
+	assert.Equal(t, "image/png", got.ExtensionMIMEType)
+
+	// The content decides, whatever the extension
+	got = fileType("image.dat")
+	assert.Equal(t, "image/png", got.MIMEType)
+	assert.True(t, got.Binary)
+	assert.Empty(t, got.LineEnding)
+
+	res := callTool(t, fsHandler.HandleDetectFileType, map[string]any{"path": filepath.Join(dir, "windows.txt")})
+	require.False(t, res.IsError, res.Content)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Encoding: utf-8 with a byte order mark\nLine endings: crlf (LF 0, CRLF 2, CR 0)")
+}
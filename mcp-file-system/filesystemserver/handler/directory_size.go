@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// defaultSizeTop is how many subdirectories directory_size lists
+	defaultSizeTop = 20
+	// maxSizeTop caps the top argument of directory_size
+	maxSizeTop = 500
+	// maxSizeDepth caps the depth argument of directory_size
+	maxSizeDepth = 10
+)
+
+// DirectorySize is the JSON result of directory_size
+type DirectorySize struct {
+	Path        string `json:"path"`
+	Files       int64  `json:"file_count"`
+	Directories int64  `json:"directory_count"`
+	TotalSize   int64  `json:"total_size"`
+	// OwnFiles and OwnSize count the files directly in path
+	OwnFiles int64 `json:"own_file_count"`
+	OwnSize  int64 `json:"own_size"`
+	// Subdirectories are those down to the depth asked for, largest first
+	Subdirectories []SubdirectorySize `json:"subdirectories"`
+	// Omitted counts the subdirectories beyond top
+	Omitted int `json:"omitted,omitempty"`
+	// Excluded counts the entries skipped by exclude
+	Excluded int64 `json:"excluded,omitempty"`
+	// Unreadable counts the entries that could not be read
+	Unreadable int64 `json:"unreadable,omitempty"`
+}
+
+// SubdirectorySize is the recursive size of one subdirectory
+type SubdirectorySize struct {
+	Path      string `json:"path"`
+	Depth     int    `json:"depth"`
+	Files     int64  `json:"file_count"`
+	TotalSize int64  `json:"total_size"`
+}
+
+// HandleDirectorySize handles the directory_size tool
+func (fs *FilesystemHandler) HandleDirectorySize(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	depth := request.GetInt("depth", 1)
+	if depth < 0 || depth > maxSizeDepth {
+		return errorResult(toolError(CodeInvalidArgument, "Error: depth must be between 0 and %d", maxSizeDepth).With("depth", depth)), nil
+	}
+	top := request.GetInt("top", defaultSizeTop)
+	if top < 1 || top > maxSizeTop {
+		return errorResult(toolError(CodeInvalidArgument, "Error: top must be between 1 and %d", maxSizeTop).With("top", top)), nil
+	}
+	var exclude glob.Glob
+	if pattern := request.GetString("exclude", ""); pattern != "" {
+		if exclude, err = glob.Compile(pattern, '/'); err != nil {
+			return errorResult(toolError(CodeInvalidArgument, "Error: invalid exclude glob %q: %w", pattern, err)), nil
+		}
+	}
+
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if !info.IsDir() {
+		return errorResult(toolError(CodeWrongType, "Error: %s is not a directory", path).With("path", path)), nil
+	}
+
+	size, err := fs.directorySize(ctx, validPath, depth, exclude)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error measuring directory: %w", err)), nil
+	}
+	if len(size.Subdirectories) > top {
+		size.Omitted = len(size.Subdirectories) - top
+		size.Subdirectories = size.Subdirectories[:top]
+	}
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, size)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s in %d files and %d directories\n", size.Path, formatFileSize(size.TotalSize), size.Files, size.Directories))
+	if len(size.Subdirectories) > 0 {
+		sb.WriteString("\nLargest subdirectories:\n")
+		for _, sub := range size.Subdirectories {
+			sb.WriteString(fmt.Sprintf("  %10s  %s/ (%d files)\n", formatFileSize(sub.TotalSize), sub.Path, sub.Files))
+		}
+		if size.Omitted > 0 {
+			sb.WriteString(fmt.Sprintf("  ... and %d more\n", size.Omitted))
+		}
+	}
+	sb.WriteString(fmt.Sprintf("\nFiles directly in %s: %s in %d files\n", size.Path, formatFileSize(size.OwnSize), size.OwnFiles))
+	if size.Excluded > 0 {
+		sb.WriteString(fmt.Sprintf("Excluded: %d entries\n", size.Excluded))
+	}
+	if size.Unreadable > 0 {
+		sb.WriteString(fmt.Sprintf("Unreadable: %d entries were skipped\n", size.Unreadable))
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// directorySize walks root without following symbolic links, adding each
+// file's apparent size to the root and to its ancestors down to depth.
+// exclude is matched against both relative paths and names.
+func (fs *FilesystemHandler) directorySize(ctx context.Context, root string, depth int, exclude glob.Glob) (*DirectorySize, error) {
+	size := &DirectorySize{Path: root, Subdirectories: []SubdirectorySize{}}
+	subdirs := make(map[string]*SubdirectorySize)
+
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			size.Unreadable++
+			return nil
+		}
+		if path == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if exclude != nil && (exclude.Match(rel) || exclude.Match(d.Name())) || !fs.isPathInAllowedDirs(path) {
+			size.Excluded++
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		parts := strings.Split(rel, "/")
+		if d.IsDir() {
+			size.Directories++
+			if len(parts) <= depth {
+				subdirs[rel] = &SubdirectorySize{Path: rel, Depth: len(parts)}
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			size.Unreadable++
+			return nil
+		}
+		size.Files++
+		size.TotalSize += info.Size()
+		if len(parts) == 1 {
+			size.OwnFiles++
+			size.OwnSize += info.Size()
+		}
+		for i := 1; i < len(parts) && i <= depth; i++ {
+			if sub := subdirs[strings.Join(parts[:i], "/")]; sub != nil {
+				sub.Files++
+				sub.TotalSize += info.Size()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sub := range subdirs {
+		size.Subdirectories = append(size.Subdirectories, *sub)
+	}
+	sort.Slice(size.Subdirectories, func(i, j int) bool {
+		a, b := size.Subdirectories[i], size.Subdirectories[j]
+		return a.TotalSize > b.TotalSize || a.TotalSize == b.TotalSize && a.Path < b.Path
+	})
+	return size, nil
+}
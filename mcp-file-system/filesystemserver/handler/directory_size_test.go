@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectorySize(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	for name, size := range map[string]int{
+		"README.md":                    10,
+		"src/main.go":                  100,
+		"src/lib/util.go":              200,
+		"media/video.mp4":              5000,
+		"node_modules/pkg/index.js":    900,
+		"media/node_modules/x/data.js": 50,
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(strings.Repeat("x", size)), 0644))
+	}
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	du := fsHandler.FormatToolCall(fsHandler.HandleDirectorySize)
+	measure := func(args map[string]any) DirectorySize {
+		args["format"] = FormatJSON
+		res := callTool(t, du, args)
+		require.False(t, res.IsError, res.Content)
+		var size DirectorySize
+		require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &size))
+		return size
+	}
+
+	size := measure(map[string]any{"path": dir})
+	assert.Equal(t, int64(6), size.Files)
+	assert.Equal(t, int64(6260), size.TotalSize)
+	assert.Equal(t, int64(10), size.OwnSize)
+	assert.Equal(t, []SubdirectorySize{
+		{Path: "media", Depth: 1, Files: 2, TotalSize: 5050},
+		{Path: "node_modules", Depth: 1, Files: 1, TotalSize: 900},
+		{Path: "src", Depth: 1, Files: 2, TotalSize: 300},
+	}, size.Subdirectories)
+
+	// exclude matches names at any level, and depth breaks down further
+	size = measure(map[string]any{"path": dir, "exclude": "node_modules", "depth": 2, "top": 2})
+	assert.Equal(t, int64(5310), size.TotalSize)
+	assert.Equal(t, int64(2), size.Excluded)
+	assert.Equal(t, []SubdirectorySize{
+		{Path: "media", Depth: 1, Files: 1, TotalSize: 5000},
+		{Path: "src", Depth: 1, Files: 2, TotalSize: 300},
+	}, size.Subdirectories)
+	assert.Equal(t, 1, size.Omitted)
+
+	res := callTool(t, fsHandler.HandleDirectorySize, map[string]any{"path": dir, "depth": 0})
+	require.False(t, res.IsError, res.Content)
+	text := res.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "in 6 files and 7 directories")
+	assert.NotContains(t, text, "Largest subdirectories")
+
+	res = callTool(t, fsHandler.HandleDirectorySize, map[string]any{"path": filepath.Join(dir, "README.md")})
+	assert.Equal(t, CodeWrongType, ResultError(res).Code)
+}
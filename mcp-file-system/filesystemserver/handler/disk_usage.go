@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DiskUsageResult is the JSON result of disk_usage
+type DiskUsageResult struct {
+	Filesystems []DiskUsage `json:"filesystems"`
+	// RequiredBytes is the space asked about, if any
+	RequiredBytes int64 `json:"required_bytes,omitempty"`
+}
+
+// DiskUsage is the capacity of the filesystem holding a path
+type DiskUsage struct {
+	Path        string  `json:"path"`
+	Total       uint64  `json:"total_bytes"`
+	Used        uint64  `json:"used_bytes"`
+	Free        uint64  `json:"free_bytes"`
+	Available   uint64  `json:"available_bytes"`
+	UsedPercent float64 `json:"used_percent"`
+	// Fits reports whether the required bytes are available
+	Fits  *bool  `json:"fits,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// HandleDiskUsage handles the disk_usage tool
+func (fs *FilesystemHandler) HandleDiskUsage(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	required := int64(request.GetInt("required_bytes", 0))
+	if required < 0 {
+		return errorResult(toolError(CodeInvalidArgument, "Error: required_bytes must not be negative").With("required_bytes", required)), nil
+	}
+
+	// Without a path every allowed directory is reported
+	var paths []string
+	for _, dir := range fs.dirs() {
+		paths = append(paths, filepath.Clean(dir))
+	}
+	if path := request.GetString("path", ""); path != "" {
+		validPath, err := fs.validatePath(path)
+		if err != nil {
+			return errorResult(fmt.Errorf("Error: %w", err)), nil
+		}
+		paths = []string{validPath}
+	}
+
+	result := DiskUsageResult{Filesystems: []DiskUsage{}, RequiredBytes: required}
+	for _, path := range paths {
+		usage := DiskUsage{Path: path}
+		total, free, avail, err := volumeUsage(existingAncestor(path))
+		if err != nil {
+			usage.Error = err.Error()
+			result.Filesystems = append(result.Filesystems, usage)
+			continue
+		}
+		usage.Total, usage.Free, usage.Available = total, free, avail
+		usage.Used = total - free
+		if total > 0 {
+			usage.UsedPercent = float64(usage.Used) / float64(total) * 100
+		}
+		if required > 0 {
+			fits := uint64(required) <= avail
+			usage.Fits = &fits
+		}
+		result.Filesystems = append(result.Filesystems, usage)
+	}
+
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, result)
+	}
+	var sb strings.Builder
+	for _, usage := range result.Filesystems {
+		if usage.Error != "" {
+			sb.WriteString(fmt.Sprintf("%s: capacity unknown: %s\n", usage.Path, usage.Error))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%s: %s available, %s used of %s (%.1f%%)",
+			usage.Path, formatFileSize(int64(usage.Available)), formatFileSize(int64(usage.Used)), formatFileSize(int64(usage.Total)), usage.UsedPercent))
+		if usage.Fits != nil {
+			if *usage.Fits {
+				sb.WriteString(fmt.Sprintf("; %s fits", formatFileSize(required)))
+			} else {
+				sb.WriteString(fmt.Sprintf("; not enough space for %s", formatFileSize(required)))
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// existingAncestor returns path, or its closest ancestor that exists, so
+// the capacity for a file yet to be written can be looked up
+func existingAncestor(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskUsage(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" && runtime.GOOS != "windows" {
+		t.Skip("volume usage is not supported on " + runtime.GOOS)
+	}
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	usage := fsHandler.FormatToolCall(fsHandler.HandleDiskUsage)
+	check := func(args map[string]any) DiskUsageResult {
+		args["format"] = FormatJSON
+		res := callTool(t, usage, args)
+		require.False(t, res.IsError, res.Content)
+		var got DiskUsageResult
+		require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &got))
+		return got
+	}
+
+	got := check(map[string]any{})
+	require.Len(t, got.Filesystems, 1)
+	fsUsage := got.Filesystems[0]
+	assert.Equal(t, dir, fsUsage.Path)
+	assert.Empty(t, fsUsage.Error)
+	assert.Positive(t, fsUsage.Total)
+	assert.Equal(t, fsUsage.Total-fsUsage.Free, fsUsage.Used)
+	assert.Nil(t, fsUsage.Fits)
+
+	// A destination that does not exist yet is measured on its parent
+	got = check(map[string]any{"path": filepath.Join(dir, "incoming.iso"), "required_bytes": 1})
+	require.Len(t, got.Filesystems, 1)
+	require.NotNil(t, got.Filesystems[0].Fits)
+	assert.True(t, *got.Filesystems[0].Fits)
+	got = check(map[string]any{"path": dir, "required_bytes": int(^uint(0) >> 1)})
+	assert.False(t, *got.Filesystems[0].Fits)
+
+	res := callTool(t, usage, map[string]any{"path": filepath.Join(t.TempDir(), "x")})
+	assert.Equal(t, CodePathNotAllowed, ResultError(res).Code)
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxDryRunDiffSize is the largest file whose changes are shown as a diff in
+// dry-run reports
+const maxDryRunDiffSize = 1024 * 1024 // 1MB
+
+// WithDryRun makes every modifying tool validate its input and report what
+// it would change instead of touching disk
+func WithDryRun(dryRun bool) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		fs.dryRun = dryRun
+	}
+}
+
+// dryRunMode reports whether modifying tools only report their changes
+func (fs *FilesystemHandler) dryRunMode() bool {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	return fs.dryRun
+}
+
+// dryRunResult is the result of a modifying tool in dry-run mode. lines
+// describe the changes, one per line.
+func dryRunResult(lines ...string) *mcp.CallToolResult {
+	return mcp.NewToolResultText("Dry run: no changes were made.\n" + strings.Join(lines, "\n"))
+}
+
+// describeWrite describes writing content to path, including a diff against
+// the current content of a modestly sized existing file
+func describeWrite(path string, content []byte) []string {
+	old, err := os.ReadFile(path)
+	if err != nil {
+		return []string{fmt.Sprintf("Would create %s (%d bytes)", path, len(content))}
+	}
+	lines := []string{fmt.Sprintf("Would overwrite %s (%d bytes, currently %d bytes)", path, len(content), len(old))}
+	if len(old) <= maxDryRunDiffSize && len(content) <= maxDryRunDiffSize &&
+		isTextFile(detectMimeTypeFromContent(path, old)) {
+		if diff := unifiedDiff(path, path, string(old), string(content)); diff != "" {
+			lines = append(lines, "", diff)
+		} else {
+			lines = append(lines, "Content is unchanged")
+		}
+	}
+	return lines
+}
+
+// describeTree summarises the files and bytes beneath path
+func describeTree(path string) string {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return path
+	}
+	if !info.IsDir() {
+		return fmt.Sprintf("%s (%d bytes)", path, info.Size())
+	}
+	var files int
+	var size int64
+	filepath.WalkDir(path, func(_ string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		files++
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return fmt.Sprintf("%s (directory, %d files, %d bytes)", path, files, size)
+}
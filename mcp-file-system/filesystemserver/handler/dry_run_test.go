@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRun(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	fsHandler, err := NewFilesystemHandler([]string{dir}, WithDryRun(true))
+	require.NoError(t, err)
+
+	existing := filepath.Join(dir, "notes.txt")
+	require.NoError(t, os.WriteFile(existing, []byte("alpha\nbeta\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "a.txt"), []byte("12345"), 0644))
+
+	call := func(fn ToolHandlerFunc, args map[string]any) string {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		res, err := fn(context.Background(), req)
+		require.NoError(t, err)
+		require.False(t, res.IsError, "%v", res.Content)
+		text := res.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "Dry run: no changes were made.")
+		return text
+	}
+
+	out := call(fsHandler.HandleWriteFile, map[string]any{"path": existing, "content": "alpha\ngamma\n"})
+	assert.Contains(t, out, "Would overwrite "+existing+" (12 bytes, currently 11 bytes)")
+	assert.Contains(t, out, "-beta")
+	assert.Contains(t, out, "+gamma")
+
+	out = call(fsHandler.HandleWriteFile, map[string]any{"path": filepath.Join(dir, "new.txt"), "content": "x"})
+	assert.Contains(t, out, "Would create "+filepath.Join(dir, "new.txt")+" (1 bytes)")
+
+	out = call(fsHandler.HandleModifyFile, map[string]any{"path": existing, "find": "beta", "replace": "delta"})
+	assert.Contains(t, out, "Would make 1 replacement(s)")
+	assert.Contains(t, out, "+delta")
+
+	out = call(fsHandler.HandleDeleteFile, map[string]any{"path": filepath.Join(dir, "sub"), "recursive": true})
+	assert.Contains(t, out, "Would permanently delete "+filepath.Join(dir, "sub")+" (directory, 1 files, 5 bytes)")
+
+	call(fsHandler.HandleMoveFile, map[string]any{"source": existing, "destination": filepath.Join(dir, "moved.txt")})
+	call(fsHandler.HandleCopyFile, map[string]any{"source": existing, "destination": filepath.Join(dir, "copy.txt")})
+	call(fsHandler.HandleCreateDirectory, map[string]any{"path": filepath.Join(dir, "made")})
+
+	// Nothing on disk changed
+	content, err := os.ReadFile(existing)
+	require.NoError(t, err)
+	assert.Equal(t, "alpha\nbeta\n", string(content))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{"notes.txt", "sub"}, names)
+
+	// Invalid input is still rejected
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"path": "/etc/passwd", "content": "x"}
+	res, err := fsHandler.HandleWriteFile(context.Background(), req)
+	require.NoError(t, err)
+	assert.True(t, res.IsError)
+}
+
+func TestModifyFileDryRunArgument(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	readOnly := filepath.Join(dir, "ref")
+	require.NoError(t, os.Mkdir(readOnly, 0755))
+	file := filepath.Join(readOnly, "config.go")
+	require.NoError(t, os.WriteFile(file, []byte("timeout = 10\nretries = 10\n"), 0644))
+	fsHandler, err := NewFilesystemHandler([]string{dir}, WithReadOnlyDirs([]string{readOnly}))
+	require.NoError(t, err)
+
+	// Previews are allowed in read-only directories
+	res := callTool(t, fsHandler.HandleModifyFile, map[string]any{"path": file, "find": `\d+`, "replace": "30", "regex": true, "dry_run": true})
+	require.False(t, res.IsError)
+	text := res.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Would make 2 replacement(s) in "+file)
+	assert.Contains(t, text, "-timeout = 10\n-retries = 10\n+timeout = 30\n+retries = 30")
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "timeout = 10\nretries = 10\n", string(content))
+
+	res = callTool(t, fsHandler.HandleModifyFile, map[string]any{"path": file, "find": "10", "replace": "30"})
+	assert.Equal(t, CodeReadOnly, ResultError(res).Code)
+}
@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Operations of edit_lines
+const (
+	LineInsert  = "insert"
+	LineReplace = "replace"
+	LineDelete  = "delete"
+)
+
+// lineEdit is the change an edit_lines call makes
+type lineEdit struct {
+	operation string
+	// startLine and endLine count from 1 and are inclusive; an insert goes
+	// before startLine
+	startLine, endLine int
+	text               string
+}
+
+// HandleEditLines handles the edit_lines tool
+func (fs *FilesystemHandler) HandleEditLines(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	edit, err := parseLineEdit(request)
+	if err != nil {
+		return errorResult(err), nil
+	}
+	dryRun := request.GetBool("dry_run", false)
+
+	// A dry run only previews the change, so the file need not be writable
+	validate := fs.validateWritablePath
+	if dryRun {
+		validate = fs.validatePath
+	}
+	validPath, err := validate(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	info, err := os.Stat(validPath)
+	if os.IsNotExist(err) {
+		return errorResult(toolError(CodeNotFound, "Error: File not found: %s", path).With("path", path)), nil
+	}
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if info.IsDir() {
+		return errorResult(toolError(CodeWrongType, "Error: Cannot edit a directory")), nil
+	}
+	content, err := os.ReadFile(validPath)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error reading file: %w", err)), nil
+	}
+	if mimeType := detectMimeTypeFromContent(validPath, content); !isTextFile(mimeType) {
+		return errorResult(toolError(CodeWrongType, "Error: %s is not a text file (%s)", path, mimeType).With("path", path)), nil
+	}
+
+	modified, summary, err := edit.apply(path, string(content))
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	// Server-wide dry-run mode applies after checking the file is writable
+	if dryRun || fs.dryRunMode() {
+		lines := []string{"Would have " + summary}
+		if diff := unifiedDiff(validPath, validPath, string(content), modified); diff != "" {
+			lines = append(lines, "", diff)
+		}
+		return dryRunResult(lines...), nil
+	}
+
+	var backup string
+	if fs.wantsBackup(request) {
+		if backup, err = fs.backupFile(validPath); err != nil {
+			return errorResult(err), nil
+		}
+	}
+	written, err := fs.writeContent(validPath, []byte(modified), request.GetBool("verify", false))
+	if err != nil {
+		return errorResult(fmt.Errorf("Error writing to file: %w", err)), nil
+	}
+	written.Backup = backup
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, written)
+	}
+
+	text := fmt.Sprintf("Successfully %s (file size: %d bytes)\n%s", summary, written.Size, describeWriteResult(written))
+	if edit.operation != LineDelete {
+		// Show the new lines with their numbers, as later edits need them
+		added := splitLines(modified)[edit.startLine-1 : edit.startLine-1+len(splitLines(edit.text))]
+		text += "\n\n" + numberLines(strings.Join(added, ""), edit.startLine)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{Type: "text", Text: text},
+			mcp.EmbeddedResource{
+				Type: "resource",
+				Resource: mcp.TextResourceContents{
+					URI:      pathToResourceURI(validPath),
+					MIMEType: "text/plain",
+					Text:     fmt.Sprintf("Edited file: %s (%d bytes)", validPath, written.Size),
+				},
+			},
+		},
+	}, nil
+}
+
+// parseLineEdit reads the arguments of edit_lines
+func parseLineEdit(request mcp.CallToolRequest) (*lineEdit, error) {
+	args := request.GetArguments()
+	edit := &lineEdit{
+		operation: request.GetString("operation", ""),
+		startLine: request.GetInt("start_line", 0),
+		endLine:   request.GetInt("end_line", 0),
+	}
+	text, hasContent := args["content"].(string)
+	edit.text = text
+	_, hasEnd := args["end_line"]
+
+	switch edit.operation {
+	case LineInsert, LineReplace:
+		if !hasContent {
+			return nil, toolError(CodeInvalidArgument, "Error: %s needs content", edit.operation)
+		}
+		if edit.text == "" {
+			return nil, toolError(CodeInvalidArgument, "Error: content must not be empty; use operation delete to remove lines")
+		}
+	case LineDelete:
+		if hasContent {
+			return nil, toolError(CodeInvalidArgument, "Error: delete does not take content")
+		}
+	default:
+		return nil, toolError(CodeInvalidArgument, "Error: operation must be %s, %s or %s", LineInsert, LineReplace, LineDelete).With("operation", edit.operation)
+	}
+	if edit.startLine < 1 {
+		return nil, toolError(CodeInvalidArgument, "Error: start_line must be at least 1").With("start_line", edit.startLine)
+	}
+	if edit.operation == LineInsert {
+		if hasEnd {
+			return nil, toolError(CodeInvalidArgument, "Error: insert does not take end_line")
+		}
+		return edit, nil
+	}
+	if !hasEnd {
+		edit.endLine = edit.startLine
+	}
+	if edit.endLine < edit.startLine {
+		return nil, toolError(CodeInvalidArgument, "Error: end_line must not be before start_line").With("end_line", edit.endLine)
+	}
+	return edit, nil
+}
+
+// apply makes the edit to content, the file at path, and returns the result
+// and a summary of the change. The new lines take the file's line ending,
+// and the file keeps or lacks its final line break as before.
+func (e *lineEdit) apply(path, content string) (string, string, error) {
+	lines := splitLines(content)
+	last := len(lines)
+	if e.operation == LineInsert {
+		last++
+	}
+	if e.startLine > last || e.endLine > len(lines) {
+		line := e.startLine
+		if e.endLine > line {
+			line = e.endLine
+		}
+		return "", "", toolError(CodeInvalidArgument, "Error: %s has %d lines, so there is no line %d", path, len(lines), line).
+			With("path", path).With("lines", len(lines))
+	}
+
+	eol := "\n"
+	if len(lines) > 0 && strings.HasSuffix(lines[0], "\r\n") {
+		eol = "\r\n"
+	}
+	finalBreak := content == "" || strings.HasSuffix(content, "\n")
+
+	var added []string
+	if e.text != "" {
+		text := strings.ReplaceAll(e.text, "\r\n", "\n")
+		if !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		for _, line := range splitLines(text) {
+			added = append(added, strings.TrimSuffix(line, "\n")+eol)
+		}
+	}
+
+	start, end := e.startLine-1, e.endLine
+	if e.operation == LineInsert {
+		end = start
+	}
+	out := append(append(append([]string{}, lines[:start]...), added...), lines[end:]...)
+
+	// Untouched lines keep their own endings; only the last line is
+	// changed, to keep or lack the final line break as before
+	var sb strings.Builder
+	for i, line := range out {
+		if i == len(out)-1 && !finalBreak {
+			line = strings.TrimRight(line, "\r\n")
+		} else if !strings.HasSuffix(line, "\n") {
+			line += eol
+		}
+		sb.WriteString(line)
+	}
+
+	var summary string
+	switch e.operation {
+	case LineInsert:
+		summary = fmt.Sprintf("inserted %d line(s) before line %d of %s", len(added), e.startLine, path)
+		if e.startLine == len(lines)+1 {
+			summary = fmt.Sprintf("appended %d line(s) to %s", len(added), path)
+		}
+	case LineReplace:
+		summary = fmt.Sprintf("replaced lines %d-%d of %s with %d line(s)", e.startLine, e.endLine, path, len(added))
+	case LineDelete:
+		summary = fmt.Sprintf("deleted lines %d-%d of %s", e.startLine, e.endLine, path)
+	}
+	return sb.String(), summary, nil
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditLines(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	file := filepath.Join(dir, "notes.txt")
+	require.NoError(t, os.WriteFile(file, []byte("one\ntwo\nthree\nfour\n"), 0644))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	edit := func(args map[string]any) *mcp.CallToolResult {
+		args["path"] = file
+		return callTool(t, fsHandler.HandleEditLines, args)
+	}
+	readBack := func() string {
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	res := edit(map[string]any{"operation": "replace", "start_line": 2, "end_line": 3, "content": "2\n3\n3.5"})
+	require.False(t, res.IsError)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "replaced lines 2-3")
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "4\t3.5")
+	assert.Equal(t, "one\n2\n3\n3.5\nfour\n", readBack())
+
+	res = edit(map[string]any{"operation": "insert", "start_line": 1, "content": "zero\n"})
+	require.False(t, res.IsError)
+	res = edit(map[string]any{"operation": "insert", "start_line": 7, "content": "five"})
+	require.False(t, res.IsError)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "appended 1 line(s)")
+	assert.Equal(t, "zero\none\n2\n3\n3.5\nfour\nfive\n", readBack())
+
+	res = edit(map[string]any{"operation": "delete", "start_line": 3, "end_line": 5})
+	require.False(t, res.IsError)
+	assert.Equal(t, "zero\none\nfour\nfive\n", readBack())
+
+	// A dry run returns a diff and leaves the file alone
+	res = edit(map[string]any{"operation": "delete", "start_line": 1, "dry_run": true})
+	require.False(t, res.IsError)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "-zero")
+	assert.Equal(t, "zero\none\nfour\nfive\n", readBack())
+
+	for name, args := range map[string]map[string]any{
+		"past the end":      {"operation": "delete", "start_line": 5},
+		"insert too far":    {"operation": "insert", "start_line": 6, "content": "x"},
+		"missing content":   {"operation": "replace", "start_line": 1},
+		"unknown operation": {"operation": "swap", "start_line": 1},
+		"reversed range":    {"operation": "delete", "start_line": 3, "end_line": 2},
+	} {
+		res := edit(args)
+		require.True(t, res.IsError, name)
+		assert.Equal(t, CodeInvalidArgument, ResultError(res).Code, name)
+	}
+}
+
+func TestEditLinesLineEndings(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	file := filepath.Join(dir, "crlf.txt")
+	require.NoError(t, os.WriteFile(file, []byte("a\r\nb\r\nc"), 0644))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	res := callTool(t, fsHandler.HandleEditLines, map[string]any{"path": file, "operation": "insert", "start_line": 2, "content": "x\ny"})
+	require.False(t, res.IsError)
+	res = callTool(t, fsHandler.HandleEditLines, map[string]any{"path": file, "operation": "replace", "start_line": 5, "content": "C"})
+	require.False(t, res.IsError)
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "a\r\nx\r\ny\r\nb\r\nC", string(content))
+}
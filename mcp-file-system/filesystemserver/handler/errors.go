@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	iofs "io/fs"
+	"maps"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Error codes of failed tool calls. Agents can rely on them to decide
+// whether to retry, fix their arguments or fall back to another tool.
+const (
+	// CodeInvalidArgument is a missing or malformed argument
+	CodeInvalidArgument = "INVALID_ARGUMENT"
+	// CodePathNotAllowed is a path outside the allowed directories
+	CodePathNotAllowed = "PATH_NOT_ALLOWED"
+	// CodeReadOnly is a modification of a read-only directory or server
+	CodeReadOnly = "READ_ONLY"
+	// CodeNotFound is a file, directory, transfer or trash entry that does not exist
+	CodeNotFound = "NOT_FOUND"
+	// CodeAlreadyExists is a destination that exists and may not be replaced
+	CodeAlreadyExists = "ALREADY_EXISTS"
+	// CodeWrongType is a file given where a directory is expected, or the reverse
+	CodeWrongType = "WRONG_TYPE"
+	// CodePermissionDenied is an operating system permission error
+	CodePermissionDenied = "PERMISSION_DENIED"
+	// CodeTooLarge is content over a configured size limit
+	CodeTooLarge = "TOO_LARGE"
+	// CodeTimeout is a call that ran out of time; it may succeed when retried
+	CodeTimeout = "TIMEOUT"
+	// CodeCancelled is a call cancelled by the client or a shutdown
+	CodeCancelled = "CANCELLED"
+	// CodeUnavailable is a feature that is not configured or not running
+	CodeUnavailable = "UNAVAILABLE"
+	// CodeCrocFailed is a croc transfer that could not be started or failed
+	CodeCrocFailed = "CROC_FAILED"
+	// CodeExternalFailed is a failure of another program or service, such
+	// as convert-router, ffprobe, rclone or an extra tool's command
+	CodeExternalFailed = "EXTERNAL_FAILED"
+	// CodeVerifyFailed is a written file that did not read back as written
+	CodeVerifyFailed = "VERIFY_FAILED"
+	// CodeInternal is any other failure
+	CodeInternal = "INTERNAL"
+)
+
+// ErrorMeta is the _meta key holding the ToolError of a failed call
+const ErrorMeta = "mcp-filesystem-server/error"
+
+// ToolError is the machine-readable form of a failed tool call. Error
+// results carry it under ErrorMeta, and JSON responses return it as
+// {"error": {...}}.
+type ToolError struct {
+	Code    string         `json:"code"`
+	Message string         `json:"message"`
+	Details map[string]any `json:"details,omitempty"`
+
+	err error
+}
+
+// toolError returns a ToolError with a message formatted like fmt.Errorf,
+// wrapping the error given for %w, if any
+func toolError(code string, format string, args ...any) *ToolError {
+	err := fmt.Errorf(format, args...)
+	return &ToolError{Code: code, Message: err.Error(), err: errors.Unwrap(err)}
+}
+
+// With adds a detail to e and returns it
+func (e *ToolError) With(key string, value any) *ToolError {
+	if e.Details == nil {
+		e.Details = make(map[string]any)
+	}
+	e.Details[key] = value
+	return e
+}
+
+func (e *ToolError) Error() string { return e.Message }
+
+func (e *ToolError) Unwrap() error { return e.err }
+
+// errorCode classifies err: the code of the outermost ToolError it wraps,
+// or one derived from well-known errors
+func errorCode(err error) string {
+	var te *ToolError
+	switch {
+	case errors.As(err, &te):
+		return te.Code
+	case errors.Is(err, iofs.ErrNotExist):
+		return CodeNotFound
+	case errors.Is(err, iofs.ErrExist):
+		return CodeAlreadyExists
+	case errors.Is(err, iofs.ErrPermission):
+		return CodePermissionDenied
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeTimeout
+	case errors.Is(err, context.Canceled):
+		return CodeCancelled
+	}
+	return CodeInternal
+}
+
+// errorResult returns the result of a call that failed with err. The
+// message is err's text; the code and details come from the errors it wraps.
+func errorResult(err error) *mcp.CallToolResult {
+	te := &ToolError{Code: errorCode(err), Message: err.Error()}
+	var cause *ToolError
+	if errors.As(err, &cause) {
+		te.Details = cause.Details
+	}
+	result := mcp.NewToolResultError(te.Message)
+	result.Meta = map[string]any{ErrorMeta: te}
+	return result
+}
+
+// ResultError returns the ToolError of a failed result. Results of tools
+// that do not set one, such as extra tools, get CodeInternal.
+func ResultError(result *mcp.CallToolResult) *ToolError {
+	if result == nil || !result.IsError {
+		return nil
+	}
+	if te, ok := result.Meta[ErrorMeta].(*ToolError); ok {
+		return te
+	}
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return &ToolError{Code: CodeInternal, Message: strings.Join(texts, "\n")}
+}
+
+// ErrorToolCall wraps a tool handler so every error result carries a
+// ToolError under ErrorMeta, including those of tools that do not set one
+func (fs *FilesystemHandler) ErrorToolCall(next ToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, request)
+		if te := ResultError(result); te != nil {
+			if _, ok := result.Meta[ErrorMeta]; !ok {
+				meta := make(map[string]any, len(result.Meta)+1)
+				maps.Copy(meta, result.Meta)
+				meta[ErrorMeta] = te
+				result.Meta = meta
+			}
+		}
+		return result, err
+	}
+}
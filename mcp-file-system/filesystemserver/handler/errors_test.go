@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorCodes(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	readOnly := filepath.Join(dir, "ref")
+	require.NoError(t, os.Mkdir(readOnly, 0755))
+	fsHandler, err := NewFilesystemHandler([]string{dir}, WithReadOnlyDirs([]string{readOnly}))
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		fn      ToolHandlerFunc
+		args    map[string]any
+		code    string
+		details map[string]any
+	}{
+		"outside allowed directories": {fsHandler.HandleReadFile, map[string]any{"path": "/outside/file.txt"}, CodePathNotAllowed, map[string]any{"path": "/outside/file.txt"}},
+		"missing file":                {fsHandler.HandleReadFile, map[string]any{"path": filepath.Join(dir, "missing.txt")}, CodeNotFound, nil},
+		"read-only directory":         {fsHandler.HandleWriteFile, map[string]any{"path": filepath.Join(readOnly, "a.txt"), "content": "x"}, CodeReadOnly, map[string]any{"path": filepath.Join(readOnly, "a.txt")}},
+		"file expected":               {fsHandler.HandleModifyFile, map[string]any{"path": dir, "find": "a", "replace": "b"}, CodeWrongType, nil},
+		"invalid argument":            {fsHandler.HandleSearchWithinFiles, map[string]any{"path": dir, "substring": ""}, CodeInvalidArgument, nil},
+		"unknown trash entry":         {fsHandler.HandleRestoreFromTrash, map[string]any{"id": "nope"}, CodeNotFound, map[string]any{"id": "nope"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			res := callTool(t, fsHandler.ErrorToolCall(tc.fn), tc.args)
+			require.True(t, res.IsError)
+			te := ResultError(res)
+			require.NotNil(t, te)
+			assert.Equal(t, tc.code, te.Code)
+			assert.Equal(t, tc.details, te.Details)
+			assert.Equal(t, res.Content[0].(mcp.TextContent).Text, te.Message)
+			assert.Same(t, te, res.Meta[ErrorMeta])
+		})
+	}
+}
+
+func TestErrorToolCallFallback(t *testing.T) {
+	fsHandler, err := NewFilesystemHandler(resolveAllowedDirs(t, t.TempDir()))
+	require.NoError(t, err)
+
+	plain := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultError("something broke"), nil
+	}
+	res := callTool(t, fsHandler.ErrorToolCall(plain), nil)
+	assert.Equal(t, &ToolError{Code: CodeInternal, Message: "something broke"}, res.Meta[ErrorMeta])
+
+	ok := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("fine"), nil
+	}
+	res = callTool(t, fsHandler.ErrorToolCall(ok), nil)
+	assert.Nil(t, res.Meta)
+	assert.Nil(t, ResultError(res))
+}
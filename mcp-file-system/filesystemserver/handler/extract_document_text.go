@@ -0,0 +1,378 @@
+package handler
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxDocumentPartSize bounds the decompressed size of a single document part (50MB)
+const maxDocumentPartSize = 50 * 1024 * 1024
+
+// HandleExtractDocumentText handles the extract_document_text tool
+func (fs *FilesystemHandler) HandleExtractDocumentText(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+
+	// max_length can only lower the inline limit, not raise it
+	maxLength := int(fs.currentLimits().MaxInlineSize)
+	if val, err := request.RequireFloat("max_length"); err == nil && val > 0 && val < float64(maxLength) {
+		maxLength = int(val)
+	}
+
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+
+	var text, kind string
+	switch strings.ToLower(filepath.Ext(validPath)) {
+	case ".docx":
+		kind = "docx"
+		text, err = extractDocxText(validPath)
+	case ".epub":
+		kind = "epub"
+		text, err = extractEpubText(validPath)
+	default:
+		err = errors.New("unsupported document type (supported: .docx, .epub)")
+	}
+	if err != nil {
+		return errorResult(fmt.Errorf("Error extracting text: %w", err)), nil
+	}
+
+	truncated := false
+	if len(text) > maxLength {
+		// Cut before a character rather than within it
+		cut := maxLength
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = text[:cut]
+		truncated = true
+	}
+
+	header := fmt.Sprintf("Extracted text from %s (%s, %d characters)", path, kind, utf8.RuneCountInString(text))
+	if truncated {
+		header += fmt.Sprintf(", truncated to %d bytes", maxLength)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: header + "\n\n" + text,
+			},
+		},
+	}, nil
+}
+
+// openZipPart returns the decompressed contents of name inside the archive
+func openZipPart(zr *zip.Reader, name string) ([]byte, error) {
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		if f.UncompressedSize64 > maxDocumentPartSize {
+			return nil, toolError(CodeTooLarge, "%s is too large (%d bytes)", name, f.UncompressedSize64)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(io.LimitReader(rc, maxDocumentPartSize))
+	}
+	return nil, fmt.Errorf("missing %s", name)
+}
+
+// extractDocxText extracts paragraphs, headings, list items and tables from word/document.xml
+func extractDocxText(filePath string) (string, error) {
+	zr, err := zip.OpenReader(filePath)
+	if err != nil {
+		return "", fmt.Errorf("not a valid docx archive: %w", err)
+	}
+	defer zr.Close()
+
+	data, err := openZipPart(&zr.Reader, "word/document.xml")
+	if err != nil {
+		return "", err
+	}
+
+	decoder := xml.NewDecoder(strings.NewReader(string(data)))
+	var out, para strings.Builder
+	prefix := ""
+	tableDepth := 0
+	inText := false
+	var row []string
+
+	flushParagraph := func() {
+		text := strings.TrimSpace(para.String())
+		para.Reset()
+		if tableDepth > 0 {
+			if text != "" {
+				row = append(row, text)
+			}
+			return
+		}
+		if text != "" {
+			out.WriteString(prefix + text + "\n\n")
+		}
+		prefix = ""
+	}
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("malformed document.xml: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "pStyle":
+				if tableDepth == 0 {
+					prefix = docxHeadingPrefix(xmlAttr(t, "val"))
+				}
+			case "numPr":
+				if tableDepth == 0 && prefix == "" {
+					prefix = "- "
+				}
+			case "tab":
+				para.WriteString("\t")
+			case "br", "cr":
+				para.WriteString("\n")
+			case "tbl":
+				tableDepth++
+			case "tr":
+				row = nil
+			}
+		case xml.CharData:
+			// Only text inside w:t elements is content; other character data is formatting whitespace
+			if inText {
+				para.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				flushParagraph()
+			case "tc":
+				if para.Len() > 0 {
+					flushParagraph()
+				}
+			case "tr":
+				if tableDepth == 1 {
+					out.WriteString("| " + strings.Join(row, " | ") + " |\n")
+				}
+				row = nil
+			case "tbl":
+				tableDepth--
+				if tableDepth == 0 {
+					out.WriteString("\n")
+				}
+			}
+		}
+	}
+
+	return strings.TrimSpace(out.String()) + "\n", nil
+}
+
+// docxHeadingPrefix maps a paragraph style to a Markdown heading prefix
+func docxHeadingPrefix(style string) string {
+	lower := strings.ToLower(style)
+	switch {
+	case lower == "title":
+		return "# "
+	case strings.HasPrefix(lower, "heading"):
+		level := 1
+		if n := strings.TrimPrefix(lower, "heading"); len(n) == 1 && n[0] >= '1' && n[0] <= '6' {
+			level = int(n[0] - '0')
+		}
+		return strings.Repeat("#", level) + " "
+	case strings.Contains(lower, "list"):
+		return "- "
+	}
+	return ""
+}
+
+// xmlAttr returns the value of the attribute with the given local name
+func xmlAttr(el xml.StartElement, local string) string {
+	for _, attr := range el.Attr {
+		if attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// epubContainer is META-INF/container.xml
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// epubPackage is the subset of the OPF package document needed for reading order
+type epubPackage struct {
+	Title    string `xml:"metadata>title"`
+	Manifest []struct {
+		ID        string `xml:"id,attr"`
+		Href      string `xml:"href,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"manifest>item"`
+	Spine []struct {
+		IDRef string `xml:"idref,attr"`
+	} `xml:"spine>itemref"`
+}
+
+// extractEpubText extracts the text of each spine document in reading order
+func extractEpubText(filePath string) (string, error) {
+	zr, err := zip.OpenReader(filePath)
+	if err != nil {
+		return "", fmt.Errorf("not a valid epub archive: %w", err)
+	}
+	defer zr.Close()
+
+	data, err := openZipPart(&zr.Reader, "META-INF/container.xml")
+	if err != nil {
+		return "", err
+	}
+	var container epubContainer
+	if err := xml.Unmarshal(data, &container); err != nil || len(container.Rootfiles) == 0 {
+		return "", errors.New("invalid META-INF/container.xml")
+	}
+	opfPath := container.Rootfiles[0].FullPath
+
+	data, err = openZipPart(&zr.Reader, opfPath)
+	if err != nil {
+		return "", err
+	}
+	var pkg epubPackage
+	if err := xml.Unmarshal(data, &pkg); err != nil {
+		return "", fmt.Errorf("invalid package document: %w", err)
+	}
+
+	hrefs := make(map[string]string, len(pkg.Manifest))
+	for _, item := range pkg.Manifest {
+		hrefs[item.ID] = item.Href
+	}
+
+	var out strings.Builder
+	if title := strings.TrimSpace(pkg.Title); title != "" {
+		out.WriteString("# " + title + "\n\n")
+	}
+
+	baseDir := path.Dir(opfPath)
+	for _, ref := range pkg.Spine {
+		href, ok := hrefs[ref.IDRef]
+		if !ok {
+			continue
+		}
+		docPath := path.Clean(path.Join(baseDir, href))
+		doc, err := openZipPart(&zr.Reader, docPath)
+		if err != nil {
+			continue // Skip missing chapters rather than failing the whole book
+		}
+		text, err := xhtmlToText(doc)
+		if err != nil {
+			continue
+		}
+		if text != "" {
+			out.WriteString(text + "\n\n")
+		}
+	}
+
+	return strings.TrimSpace(out.String()) + "\n", nil
+}
+
+// xhtmlToText converts an XHTML document to text with Markdown-style headings and list items
+func xhtmlToText(data []byte) (string, error) {
+	decoder := xml.NewDecoder(strings.NewReader(string(data)))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	var out, block strings.Builder
+	prefix := ""
+	skipDepth := 0
+	inBody := false
+
+	flush := func() {
+		text := strings.Join(strings.Fields(block.String()), " ")
+		block.Reset()
+		if text != "" {
+			out.WriteString(prefix + text + "\n\n")
+		}
+		prefix = ""
+	}
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			switch name {
+			case "body":
+				inBody = true
+			case "script", "style", "head":
+				skipDepth++
+			case "h1", "h2", "h3", "h4", "h5", "h6":
+				flush()
+				prefix = strings.Repeat("#", int(name[1]-'0')) + " "
+			case "li":
+				flush()
+				prefix = "- "
+			case "p", "div", "section", "blockquote", "tr", "pre":
+				flush()
+			case "br":
+				block.WriteString(" ")
+			case "td", "th":
+				block.WriteString(" | ")
+			}
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			switch name {
+			case "script", "style", "head":
+				if skipDepth > 0 {
+					skipDepth--
+				}
+			case "h1", "h2", "h3", "h4", "h5", "h6", "li", "p", "div", "section", "blockquote", "tr", "pre":
+				flush()
+			}
+		case xml.CharData:
+			if inBody && skipDepth == 0 {
+				block.Write(t)
+			}
+		}
+	}
+	flush()
+
+	return strings.TrimSpace(out.String()), nil
+}
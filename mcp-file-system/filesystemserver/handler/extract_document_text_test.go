@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+}
+
+func TestHandleExtractDocumentText(t *testing.T) {
+	dir := t.TempDir()
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	docx := filepath.Join(dir, "report.docx")
+	writeZip(t, docx, map[string]string{
+		"word/document.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:body>
+    <w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Quarterly Report</w:t></w:r></w:p>
+    <w:p><w:r><w:t xml:space="preserve">Revenue grew </w:t></w:r><w:r><w:t>strongly.</w:t></w:r></w:p>
+    <w:p><w:pPr><w:numPr><w:ilvl w:val="0"/></w:numPr></w:pPr><w:r><w:t>First point</w:t></w:r></w:p>
+    <w:tbl>
+      <w:tr><w:tc><w:p><w:r><w:t>Region</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Sales</w:t></w:r></w:p></w:tc></w:tr>
+      <w:tr><w:tc><w:p><w:r><w:t>EU</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>42</w:t></w:r></w:p></w:tc></w:tr>
+    </w:tbl>
+  </w:body>
+</w:document>`,
+	})
+
+	epub := filepath.Join(dir, "book.epub")
+	writeZip(t, epub, map[string]string{
+		"mimetype": "application/epub+zip",
+		"META-INF/container.xml": `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles>
+</container>`,
+		"OEBPS/content.opf": `<?xml version="1.0"?>
+<package xmlns="http://www.idpf.org/2007/opf" xmlns:dc="http://purl.org/dc/elements/1.1/" version="3.0">
+  <metadata><dc:title>A Small Book</dc:title></metadata>
+  <manifest>
+    <item id="c2" href="text/ch2.xhtml" media-type="application/xhtml+xml"/>
+    <item id="c1" href="text/ch1.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine><itemref idref="c1"/><itemref idref="c2"/></spine>
+</package>`,
+		"OEBPS/text/ch1.xhtml": `<html xmlns="http://www.w3.org/1999/xhtml"><head><title>x</title><style>p{}</style></head>
+<body><h1>Chapter One</h1><p>It was a dark&nbsp;night.</p><ul><li>clue</li></ul></body></html>`,
+		"OEBPS/text/ch2.xhtml": `<html xmlns="http://www.w3.org/1999/xhtml"><body><h2>Chapter Two</h2><p>The end.</p></body></html>`,
+	})
+
+	t.Run("docx", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"path": docx}
+		result, err := handler.HandleExtractDocumentText(context.Background(), request)
+		require.NoError(t, err)
+		require.False(t, result.IsError, result.Content)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "# Quarterly Report\n\nRevenue grew strongly.\n\n- First point\n\n| Region | Sales |\n| EU | 42 |")
+	})
+
+	t.Run("epub", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"path": epub}
+		result, err := handler.HandleExtractDocumentText(context.Background(), request)
+		require.NoError(t, err)
+		require.False(t, result.IsError, result.Content)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "# A Small Book\n\n# Chapter One\n\nIt was a dark night.\n\n- clue\n\n## Chapter Two\n\nThe end.")
+		assert.NotContains(t, text, "p{}")
+	})
+
+	t.Run("max_length", func(t *testing.T) {
+		accents := filepath.Join(dir, "accents.docx")
+		writeZip(t, accents, map[string]string{
+			"word/document.xml": `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body><w:p><w:r><w:t>héllo wörld</w:t></w:r></w:p></w:body></w:document>`,
+		})
+		limited, err := NewFilesystemHandler(resolveAllowedDirs(t, dir), WithLimits(Limits{MaxInlineSize: 8}))
+		require.NoError(t, err)
+		extract := func(maxLength int) string {
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = map[string]any{"path": accents, "max_length": maxLength}
+			result, err := limited.HandleExtractDocumentText(context.Background(), request)
+			require.NoError(t, err)
+			require.False(t, result.IsError, result.Content)
+			return result.Content[0].(mcp.TextContent).Text
+		}
+
+		// A cut within é backs up to the character before it
+		assert.True(t, strings.HasSuffix(extract(2), "truncated to 2 bytes\n\nh"), extract(2))
+		// A max_length above the inline limit is capped by it
+		assert.True(t, strings.HasSuffix(extract(1000), "truncated to 8 bytes\n\nhéllo w"), extract(1000))
+		// Characters are counted, not bytes
+		assert.Contains(t, extract(1000), "(docx, 7 characters), truncated to 8 bytes")
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		other := filepath.Join(dir, "notes.txt")
+		require.NoError(t, os.WriteFile(other, []byte("x"), 0644))
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"path": other}
+		result, err := handler.HandleExtractDocumentText(context.Background(), request)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+	})
+}
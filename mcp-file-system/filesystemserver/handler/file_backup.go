@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// backupSuffix names a backup kept next to its file
+const backupSuffix = ".bak"
+
+// FileBackupConfig sets how the backup option of write_file, modify_file,
+// edit_lines and delete_file keeps a copy of a file before changing it
+type FileBackupConfig struct {
+	// Dir holds the backups, mirroring the absolute paths of the originals;
+	// a relative path is within the state directory. When empty, each
+	// backup is kept next to its file as <name>.bak.
+	Dir string `json:"dir,omitempty" yaml:"dir,omitempty"`
+	// Always backs up every file changed, as if each call set backup
+	Always bool `json:"always,omitempty" yaml:"always,omitempty"`
+}
+
+// WithFileBackups sets where backups are kept, and whether files are
+// backed up without being asked to
+func WithFileBackups(cfg FileBackupConfig) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		fs.fileBackups = cfg
+	}
+}
+
+// wantsBackup reports whether a call asked for its file to be backed up,
+// falling back to the server setting
+func (fs *FilesystemHandler) wantsBackup(request mcp.CallToolRequest) bool {
+	return request.GetBool("backup", fs.fileBackups.Always)
+}
+
+// backupPathFor returns where the backup of path is kept
+func (fs *FilesystemHandler) backupPathFor(path string) string {
+	dir := fs.fileBackups.Dir
+	if dir == "" {
+		return path + backupSuffix
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(fs.stateDir, dir)
+	}
+	// A drive letter becomes a directory of its own
+	volume := filepath.VolumeName(path)
+	return filepath.Join(dir, strings.TrimSuffix(volume, ":"), path[len(volume):])
+}
+
+// backupFile copies the file at path to its backup, replacing an earlier
+// one, and returns the backup path. A missing file has nothing to back up
+// and returns "".
+func (fs *FilesystemHandler) backupFile(path string) (string, error) {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", toolError(CodeWrongType, "Error: only regular files can be backed up; %s is not one", path).With("path", path)
+	}
+	backup := fs.backupPathFor(path)
+	if err := os.MkdirAll(filepath.Dir(backup), 0700); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	if err := copyFile(path, backup); err != nil {
+		os.Remove(backup)
+		return "", fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+	// The backup keeps the modification time of the original
+	if err := os.Chtimes(backup, info.ModTime(), info.ModTime()); err != nil {
+		return "", err
+	}
+	return backup, nil
+}
+
+// HandleRestoreBackup handles the restore_backup tool
+func (fs *FilesystemHandler) HandleRestoreBackup(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	validPath, err := fs.validateWritablePath(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if info, err := os.Stat(validPath); err == nil && info.IsDir() {
+		return errorResult(toolError(CodeWrongType, "Error: %s is a directory; backups are kept of files only", path).With("path", path)), nil
+	}
+	backup := fs.backupPathFor(validPath)
+	info, err := os.Stat(backup)
+	if os.IsNotExist(err) {
+		return errorResult(toolError(CodeNotFound, "Error: there is no backup of %s", path).With("path", path).With("backup", backup)), nil
+	}
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+
+	if fs.dryRunMode() {
+		return dryRunResult(fmt.Sprintf("Would restore %s from its backup %s (%d bytes)", validPath, backup, info.Size())), nil
+	}
+
+	// The current content is saved as a version, so a restore can be undone
+	if err := fs.saveVersion(validPath); err != nil {
+		return errorResult(fmt.Errorf("Error: failed to save the current version: %w", err)), nil
+	}
+	if err := os.MkdirAll(filepath.Dir(validPath), 0755); err != nil {
+		return errorResult(fmt.Errorf("Error creating parent directories: %w", err)), nil
+	}
+	if err := copyFile(backup, validPath); err != nil {
+		return errorResult(fmt.Errorf("Error restoring %s: %w", path, err)), nil
+	}
+	os.Chtimes(validPath, info.ModTime(), info.ModTime())
+	if err := os.Remove(backup); err != nil {
+		fs.logger.Warn("failed to remove restored backup", "path", backup, "error", err)
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Restored %s from its backup %s (%d bytes)", path, backup, info.Size())), nil
+}
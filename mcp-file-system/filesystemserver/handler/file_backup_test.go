@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileBackups(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	file := filepath.Join(dir, "config.ini")
+	require.NoError(t, os.WriteFile(file, []byte("v1"), 0644))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	// Without backup nothing is copied
+	res := callTool(t, fsHandler.HandleWriteFile, map[string]any{"path": file, "content": "v2"})
+	require.False(t, res.IsError)
+	assert.NoFileExists(t, file+".bak")
+
+	res = callTool(t, fsHandler.HandleModifyFile, map[string]any{"path": file, "find": "v2", "replace": "v3", "backup": true})
+	require.False(t, res.IsError)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Backup: "+file+".bak")
+	backup, err := os.ReadFile(file + ".bak")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(backup))
+
+	res = callTool(t, fsHandler.HandleDeleteFile, map[string]any{"path": file, "backup": true})
+	require.False(t, res.IsError)
+	assert.NoFileExists(t, file)
+	backup, err = os.ReadFile(file + ".bak")
+	require.NoError(t, err)
+	assert.Equal(t, "v3", string(backup))
+
+	res = callTool(t, fsHandler.HandleRestoreBackup, map[string]any{"path": file})
+	require.False(t, res.IsError)
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "v3", string(content))
+	assert.NoFileExists(t, file+".bak")
+
+	res = callTool(t, fsHandler.HandleRestoreBackup, map[string]any{"path": file})
+	assert.Equal(t, CodeNotFound, ResultError(res).Code)
+
+	sub := filepath.Join(dir, "sub")
+	require.NoError(t, os.Mkdir(sub, 0755))
+	res = callTool(t, fsHandler.HandleDeleteFile, map[string]any{"path": sub, "recursive": true, "backup": true})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	assert.DirExists(t, sub)
+}
+
+func TestFileBackupsDir(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	backups := t.TempDir()
+	file := filepath.Join(dir, "notes.txt")
+	require.NoError(t, os.WriteFile(file, []byte("first"), 0644))
+	fsHandler, err := NewFilesystemHandler([]string{dir}, WithFileBackups(FileBackupConfig{Dir: backups, Always: true}))
+	require.NoError(t, err)
+
+	res := callTool(t, fsHandler.FormatToolCall(fsHandler.HandleWriteFile), map[string]any{"path": file, "content": "second", "format": FormatJSON})
+	require.False(t, res.IsError)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, `"backup"`)
+	backup, err := os.ReadFile(filepath.Join(backups, file))
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(backup))
+	assert.NoFileExists(t, file+".bak")
+
+	// A call can still opt out
+	res = callTool(t, fsHandler.HandleWriteFile, map[string]any{"path": file, "content": "third", "backup": false})
+	require.False(t, res.IsError)
+	backup, err = os.ReadFile(filepath.Join(backups, file))
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(backup))
+
+	res = callTool(t, fsHandler.HandleRestoreBackup, map[string]any{"path": file})
+	require.False(t, res.IsError)
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(content))
+}
@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// binarySniffSize is how much of a file is inspected to decide whether it
+// is binary, the same amount git looks at
+const binarySniffSize = 8000
+
+// ContentDetails are the fields get_file_info computes from a file's content
+type ContentDetails struct {
+	// Binary reports whether the file looks binary rather than text
+	Binary bool `json:"binary"`
+	// Lines counts the lines of text files up to the searchable size limit
+	Lines *int64 `json:"line_count,omitempty"`
+	// SHA256 is the hex digest of the content, computed on request
+	SHA256 string `json:"sha256,omitempty"`
+	// Checksum is the hex digest of the content with ChecksumAlgorithm,
+	// computed on request
+	Checksum          string `json:"checksum,omitempty"`
+	ChecksumAlgorithm string `json:"checksum_algorithm,omitempty"`
+}
+
+// looksBinary reports whether the start of a file holds NUL bytes or
+// invalid UTF-8, allowing for a rune cut off at the end of the sample
+func looksBinary(sample []byte) bool {
+	if bytes.IndexByte(sample, 0) >= 0 {
+		return true
+	}
+	for len(sample) > 0 {
+		r, size := utf8.DecodeRune(sample)
+		if r == utf8.RuneError && size == 1 {
+			return len(sample) >= utf8.UTFMax || utf8.FullRune(sample)
+		}
+		sample = sample[size:]
+	}
+	return false
+}
+
+// contentDetails inspects the regular file at path. Lines are only counted
+// for text files no larger than the searchable size limit, so the file is
+// read in full only when that applies or a checksum algorithm is given.
+func (fs *FilesystemHandler) contentDetails(ctx context.Context, path string, size int64, algorithm string) (ContentDetails, error) {
+	var details ContentDetails
+	f, err := os.Open(path)
+	if err != nil {
+		return details, err
+	}
+	defer f.Close()
+
+	sample := make([]byte, binarySniffSize)
+	n, err := io.ReadFull(f, sample)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return details, err
+	}
+	details.Binary = looksBinary(sample[:n])
+
+	countLines := !details.Binary && size <= fs.currentLimits().MaxSearchableSize
+	if !countLines && algorithm == "" {
+		return details, nil
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return details, err
+	}
+
+	var hasher hash.Hash
+	if algorithm != "" {
+		if hasher, algorithm, err = newHash("checksum_algorithm", algorithm); err != nil {
+			return details, err
+		}
+	}
+	var lines, read int64
+	var last byte
+	buf := make([]byte, 64*1024)
+	for {
+		if err := ctx.Err(); err != nil {
+			return details, err
+		}
+		n, err := f.Read(buf)
+		if n > 0 {
+			if hasher != nil {
+				hasher.Write(buf[:n])
+			}
+			lines += int64(bytes.Count(buf[:n], []byte{'\n'}))
+			read += int64(n)
+			last = buf[n-1]
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return details, err
+		}
+	}
+	if countLines {
+		// A final line without a newline still counts
+		if read > 0 && last != '\n' {
+			lines++
+		}
+		details.Lines = &lines
+	}
+	if hasher != nil {
+		details.Checksum = hex.EncodeToString(hasher.Sum(nil))
+		details.ChecksumAlgorithm = algorithm
+		if algorithm == HashSHA256 {
+			details.SHA256 = details.Checksum
+		}
+	}
+	return details, nil
+}
+
+// symlinkTarget returns what path links to when it is a symbolic link
+func symlinkTarget(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return ""
+	}
+	if info, err := os.Lstat(abs); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return ""
+	}
+	target, err := os.Readlink(abs)
+	if err != nil {
+		return ""
+	}
+	return target
+}
+
+// symlinkDetails describes a symbolic link that is not followed
+type symlinkDetails struct {
+	path, target, resolved string
+	info                   os.FileInfo
+	outside, missing       bool
+}
+
+// unfollowedSymlink reports whether path is a link in the allowed
+// directories whose target is missing or outside them, and describes it
+func (fs *FilesystemHandler) unfollowedSymlink(path string) (*symlinkDetails, bool) {
+	link, ok := fs.inspectSymlink(path)
+	if !ok || !link.outside && !link.missing {
+		return nil, false
+	}
+	return link, true
+}
+
+// inspectSymlink describes path without following it, if it is a symbolic
+// link in the allowed directories
+func (fs *FilesystemHandler) inspectSymlink(path string) (*symlinkDetails, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil || !fs.isPathInAllowedDirs(abs) {
+		return nil, false
+	}
+	info, err := os.Lstat(abs)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return nil, false
+	}
+	link := &symlinkDetails{path: abs, target: symlinkTarget(abs), info: info}
+	if link.resolved, err = filepath.EvalSymlinks(abs); err != nil {
+		// A dangling link resolves as far as its text goes
+		link.missing = true
+		link.resolved = link.target
+		if !filepath.IsAbs(link.resolved) {
+			link.resolved = filepath.Join(filepath.Dir(abs), link.resolved)
+		}
+	}
+	link.outside = !fs.isPathInAllowedDirs(link.resolved)
+	return link, true
+}
+
+// symlinkInfoResult is the get_file_info result for an unfollowed link
+func (fs *FilesystemHandler) symlinkInfoResult(ctx context.Context, link *symlinkDetails) (*mcp.CallToolResult, error) {
+	now := time.Now()
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, FileDetails{
+			Path:                 link.path,
+			Type:                 "symlink",
+			Size:                 link.info.Size(),
+			Permissions:          fmt.Sprintf("%o", link.info.Mode().Perm()),
+			MIMEType:             "inode/symlink",
+			SymlinkTarget:        link.target,
+			ResolvedTarget:       link.resolved,
+			TargetOutsideAllowed: link.outside,
+			TargetMissing:        link.missing,
+			FileTimes:            localFileTimes(link.info, now),
+		})
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("File information for: %s\n\nType: symbolic link\nSymlink target: %s\nResolved target: %s", link.path, link.target, link.resolved))
+	if link.missing {
+		sb.WriteString("\nThe target does not exist")
+	}
+	if link.outside {
+		sb.WriteString("\nThe target is outside the allowed directories, so it is not followed")
+	}
+	sb.WriteString(fmt.Sprintf("\nModified: %s", describeTime(link.info.ModTime(), now, true)))
+	return mcp.NewToolResultText(sb.String()), nil
+}
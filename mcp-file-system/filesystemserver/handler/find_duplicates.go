@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// defaultDuplicateGroups is how many duplicate sets find_duplicates lists
+	defaultDuplicateGroups = 100
+	// maxDuplicateGroups caps the max_groups argument of find_duplicates
+	maxDuplicateGroups = 1000
+)
+
+// DuplicatesResult is the JSON result of find_duplicates
+type DuplicatesResult struct {
+	Path      string `json:"path"`
+	Algorithm string `json:"algorithm"`
+	// FilesScanned counts the files compared, and FilesHashed those whose
+	// size another file shared, so their content had to be read
+	FilesScanned int `json:"files_scanned"`
+	FilesHashed  int `json:"files_hashed"`
+	// Groups are the sets of identical files, most reclaimable bytes first
+	Groups []DuplicateGroup `json:"groups"`
+	// Omitted counts the sets beyond max_groups
+	Omitted int `json:"omitted,omitempty"`
+	// ReclaimableBytes totals every set, listed or not
+	ReclaimableBytes int64 `json:"reclaimable_bytes"`
+}
+
+// DuplicateGroup is a set of files with identical content
+type DuplicateGroup struct {
+	Size   int64    `json:"size"`
+	Digest string   `json:"digest"`
+	Files  []string `json:"files"`
+	// Reclaimable is what keeping one copy would free. Hard links to the
+	// same file count once.
+	Reclaimable int64 `json:"reclaimable"`
+}
+
+// sizedFile is a file found by find_duplicates
+type sizedFile struct {
+	path string
+	info os.FileInfo
+}
+
+// HandleFindDuplicates handles the find_duplicates tool
+func (fs *FilesystemHandler) HandleFindDuplicates(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	minSize := int64(request.GetInt("min_size", 1))
+	if minSize < 0 {
+		return errorResult(toolError(CodeInvalidArgument, "Error: min_size must not be negative").With("min_size", minSize)), nil
+	}
+	maxDepth := request.GetInt("max_depth", 0)
+	if maxDepth < 0 {
+		return errorResult(toolError(CodeInvalidArgument, "Error: max_depth must not be negative").With("max_depth", maxDepth)), nil
+	}
+	maxGroups := request.GetInt("max_groups", defaultDuplicateGroups)
+	if maxGroups < 1 || maxGroups > maxDuplicateGroups {
+		return errorResult(toolError(CodeInvalidArgument, "Error: max_groups must be between 1 and %d", maxDuplicateGroups).With("max_groups", maxGroups)), nil
+	}
+	_, algorithm, err := newHash("algorithm", request.GetString("algorithm", HashSHA256))
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if !info.IsDir() {
+		return errorResult(toolError(CodeWrongType, "Error: %s is not a directory", path).With("path", path)), nil
+	}
+	filter, err := fs.newWalkFilter(request, validPath)
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	result, err := fs.findDuplicates(ctx, validPath, minSize, maxDepth, algorithm, filter)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error finding duplicates: %w", err)), nil
+	}
+	if len(result.Groups) > maxGroups {
+		result.Omitted = len(result.Groups) - maxGroups
+		result.Groups = result.Groups[:maxGroups]
+	}
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, result)
+	}
+
+	if len(result.Groups) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No duplicate files among %d files in %s", result.FilesScanned, validPath)), nil
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Found %d set(s) of duplicate files among %d files in %s; keeping one copy of each would free %s\n",
+		len(result.Groups)+result.Omitted, result.FilesScanned, validPath, formatFileSize(result.ReclaimableBytes)))
+	for _, group := range result.Groups {
+		sb.WriteString(fmt.Sprintf("\n%d copies of %s, %s reclaimable (%s %s):\n",
+			len(group.Files), formatFileSize(group.Size), formatFileSize(group.Reclaimable), result.Algorithm, group.Digest))
+		for _, file := range group.Files {
+			sb.WriteString(fmt.Sprintf("  %s\n", file))
+		}
+	}
+	if result.Omitted > 0 {
+		sb.WriteString(fmt.Sprintf("\n... and %d more set(s); raise max_groups to list them\n", result.Omitted))
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// findDuplicates walks root without following symbolic links and groups
+// its regular files of at least minSize bytes by size, then by content.
+// Only files sharing a size are read.
+func (fs *FilesystemHandler) findDuplicates(ctx context.Context, root string, minSize int64, maxDepth int, algorithm string, filter *walkFilter) (*DuplicatesResult, error) {
+	result := &DuplicatesResult{Path: root, Algorithm: algorithm, Groups: []DuplicateGroup{}}
+	bySize := make(map[int64][]sizedFile)
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil || path == root {
+			return nil // Skip unreadable entries
+		}
+		// Trashed files and saved versions are copies by design
+		if !fs.isPathInAllowedDirs(path) || fs.isInTrash(path) || fs.isInVersions(path) || filter.skip(path, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			rel, err := filepath.Rel(root, path)
+			if err == nil && maxDepth > 0 && strings.Count(rel, string(filepath.Separator))+1 >= maxDepth {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || info.Size() < minSize {
+			return nil
+		}
+		result.FilesScanned++
+		bySize[info.Size()] = append(bySize[info.Size()], sizedFile{path, info})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for size, files := range bySize {
+		if len(files) < 2 {
+			continue
+		}
+		byDigest := make(map[string][]sizedFile)
+		for _, file := range files {
+			digest, err := fileDigest(ctx, file.path, algorithm)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, err
+				}
+				continue // Skip files that cannot be read
+			}
+			result.FilesHashed++
+			byDigest[digest] = append(byDigest[digest], file)
+		}
+		for digest, same := range byDigest {
+			group := DuplicateGroup{Size: size, Digest: digest}
+			distinct := 0
+			for i, file := range same {
+				group.Files = append(group.Files, file.path)
+				if !linkedToEarlier(same[:i], file) {
+					distinct++
+				}
+			}
+			if distinct < 2 {
+				continue
+			}
+			sort.Strings(group.Files)
+			group.Reclaimable = size * int64(distinct-1)
+			result.ReclaimableBytes += group.Reclaimable
+			result.Groups = append(result.Groups, group)
+		}
+	}
+	sort.Slice(result.Groups, func(i, j int) bool {
+		a, b := result.Groups[i], result.Groups[j]
+		if a.Reclaimable != b.Reclaimable {
+			return a.Reclaimable > b.Reclaimable
+		}
+		return a.Files[0] < b.Files[0]
+	})
+	return result, nil
+}
+
+// fileDigest hashes the content of the file at path
+func fileDigest(ctx context.Context, path, algorithm string) (string, error) {
+	h, _, err := newHash("algorithm", algorithm)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := hashStream(ctx, f, h); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// linkedToEarlier reports whether file is a hard link to one of earlier
+func linkedToEarlier(earlier []sizedFile, file sizedFile) bool {
+	for _, other := range earlier {
+		if os.SameFile(other.info, file.info) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	write := func(rel, content string) string {
+		path := filepath.Join(dir, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+	a := write("a.txt", "same content")
+	b := write("sub/b.txt", "same content")
+	c := write("sub/deep/c.txt", "same content")
+	write("other.txt", "diff content") // Same size, different content
+	big1 := write("big1.bin", strings.Repeat("x", 100))
+	big2 := write("sub/big2.bin", strings.Repeat("x", 100))
+	write("empty1", "")
+	write("empty2", "")
+	write("vendor/copy.txt", "same content")
+
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	handler := fsHandler.FormatToolCall(fsHandler.HandleFindDuplicates)
+	find := func(args map[string]any) DuplicatesResult {
+		t.Helper()
+		args["path"] = dir
+		args["format"] = FormatJSON
+		res := callTool(t, handler, args)
+		require.False(t, res.IsError, res.Content)
+		var result DuplicatesResult
+		require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &result))
+		return result
+	}
+
+	t.Run("groups identical files, largest savings first", func(t *testing.T) {
+		result := find(map[string]any{"exclude": []any{"vendor"}})
+		require.Len(t, result.Groups, 2)
+		assert.Equal(t, []string{big1, big2}, result.Groups[0].Files)
+		assert.Equal(t, int64(100), result.Groups[0].Reclaimable)
+		assert.Equal(t, []string{a, b, c}, result.Groups[1].Files)
+		assert.Equal(t, int64(24), result.Groups[1].Reclaimable)
+		assert.Equal(t, int64(124), result.ReclaimableBytes)
+		assert.Equal(t, HashSHA256, result.Algorithm)
+		assert.Equal(t, 6, result.FilesScanned, "empty files are skipped by default")
+		assert.Equal(t, 6, result.FilesHashed)
+	})
+
+	t.Run("min_size and max_depth", func(t *testing.T) {
+		result := find(map[string]any{"min_size": 50})
+		require.Len(t, result.Groups, 1)
+		assert.Equal(t, []string{big1, big2}, result.Groups[0].Files)
+
+		result = find(map[string]any{"max_depth": 2, "exclude": []any{"vendor"}})
+		require.Len(t, result.Groups, 2)
+		assert.Equal(t, []string{a, b}, result.Groups[1].Files)
+	})
+
+	t.Run("max_groups", func(t *testing.T) {
+		result := find(map[string]any{"max_groups": 1})
+		assert.Len(t, result.Groups, 1)
+		assert.Equal(t, 1, result.Omitted)
+		assert.Equal(t, int64(136), result.ReclaimableBytes)
+	})
+
+	t.Run("hard links count once", func(t *testing.T) {
+		linked := filepath.Join(dir, "linked.bin")
+		require.NoError(t, os.Link(big1, linked))
+		t.Cleanup(func() { os.Remove(linked) })
+
+		result := find(map[string]any{"min_size": 50})
+		require.Len(t, result.Groups, 1)
+		assert.Len(t, result.Groups[0].Files, 3)
+		assert.Equal(t, int64(100), result.Groups[0].Reclaimable)
+
+		require.NoError(t, os.Remove(big2))
+		t.Cleanup(func() { write("sub/big2.bin", strings.Repeat("x", 100)) })
+		result = find(map[string]any{"min_size": 50})
+		assert.Empty(t, result.Groups, "links to one file are not duplicates")
+	})
+
+	t.Run("trash and versions are skipped", func(t *testing.T) {
+		write(filepath.Join(trashDirName, "files", "old.bin"), strings.Repeat("x", 100))
+		write(filepath.Join(versionsDirName, "big1.bin"), strings.Repeat("x", 100))
+		t.Cleanup(func() {
+			os.RemoveAll(filepath.Join(dir, trashDirName))
+			os.RemoveAll(filepath.Join(dir, versionsDirName))
+		})
+
+		result := find(map[string]any{"min_size": 50})
+		require.Len(t, result.Groups, 1)
+		assert.Equal(t, []string{big1, big2}, result.Groups[0].Files)
+	})
+
+	t.Run("text output and invalid arguments", func(t *testing.T) {
+		res := callTool(t, fsHandler.HandleFindDuplicates, map[string]any{"path": dir, "algorithm": "xxhash"})
+		require.False(t, res.IsError)
+		text := res.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "Found 2 set(s) of duplicate files")
+		assert.Contains(t, text, "4 copies of 12 bytes")
+
+		res = callTool(t, fsHandler.HandleFindDuplicates, map[string]any{"path": a})
+		require.True(t, res.IsError)
+		assert.Equal(t, CodeWrongType, ResultError(res).Code)
+
+		res = callTool(t, fsHandler.HandleFindDuplicates, map[string]any{"path": dir, "algorithm": "crc"})
+		require.True(t, res.IsError)
+		assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	})
+}
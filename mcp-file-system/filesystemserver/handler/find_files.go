@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// defaultFindResults is how many paths find_files returns by default
+	defaultFindResults = 1000
+	// maxFindResults caps the max_results argument of find_files
+	maxFindResults = 10000
+)
+
+// Entry types find_files can be limited to
+const (
+	FindTypeAny       = "any"
+	FindTypeFile      = "file"
+	FindTypeDirectory = "directory"
+	FindTypeSymlink   = "symlink"
+)
+
+// errFindLimit stops the walk of find_files at max_results
+var errFindLimit = errors.New("max_results reached")
+
+// FindFilesResult is the JSON result of find_files
+type FindFilesResult struct {
+	Path    string           `json:"path"`
+	Results []DirectoryEntry `json:"results"`
+	// Truncated is set when the walk stopped at max_results
+	Truncated bool `json:"truncated"`
+}
+
+// findFilter holds the criteria of find_files. Zero values do not filter.
+type findFilter struct {
+	match                func(name string) bool
+	kind                 string
+	minSize, maxSize     int64
+	after, before        time.Time
+	maxDepth, maxResults int
+	// walk leaves out excluded and ignored entries, and what is below them
+	walk *walkFilter
+}
+
+// HandleFindFiles handles the find_files tool
+func (fs *FilesystemHandler) HandleFindFiles(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	filter, err := parseFindFilter(request)
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if !info.IsDir() {
+		return errorResult(toolError(CodeWrongType, "Error: %s is not a directory", path).With("path", path)), nil
+	}
+	if filter.walk, err = fs.newWalkFilter(request, validPath); err != nil {
+		return errorResult(err), nil
+	}
+
+	result, err := fs.findFiles(ctx, validPath, filter)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error searching files: %w", err)), nil
+	}
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, result)
+	}
+
+	if len(result.Results) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No entries in %s match the criteria", validPath)), nil
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Found %d entries in %s:\n\n", len(result.Results), validPath))
+	for _, entry := range result.Results {
+		switch entry.Type {
+		case FindTypeDirectory:
+			sb.WriteString(fmt.Sprintf("[DIR]  %s - modified %s\n", entry.Path, entry.Modified))
+		case FindTypeSymlink:
+			sb.WriteString(fmt.Sprintf("[LINK] %s - modified %s\n", entry.Path, entry.Modified))
+		default:
+			sb.WriteString(fmt.Sprintf("[FILE] %s - %s, modified %s\n", entry.Path, formatFileSize(entry.Size), entry.Modified))
+		}
+	}
+	if result.Truncated {
+		sb.WriteString(fmt.Sprintf("\nStopped after %d results; narrow the criteria or raise max_results to see more\n", len(result.Results)))
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// parseFindFilter reads the criteria of find_files
+func parseFindFilter(request mcp.CallToolRequest) (findFilter, error) {
+	filter := findFilter{
+		kind:       request.GetString("type", FindTypeAny),
+		maxDepth:   request.GetInt("max_depth", 0),
+		maxResults: request.GetInt("max_results", defaultFindResults),
+	}
+	switch filter.kind {
+	case FindTypeAny, FindTypeFile, FindTypeDirectory, FindTypeSymlink:
+	default:
+		return filter, toolError(CodeInvalidArgument, "Error: type must be %q, %q, %q or %q", FindTypeAny, FindTypeFile, FindTypeDirectory, FindTypeSymlink).With("type", filter.kind)
+	}
+	if pattern := request.GetString("name", ""); pattern != "" {
+		match, err := nameMatcher(pattern, SearchModeGlob, request.GetBool("case_sensitive", true))
+		if err != nil {
+			return filter, err
+		}
+		filter.match = match
+	}
+	filter.minSize = int64(request.GetInt("min_size", 0))
+	filter.maxSize = int64(request.GetInt("max_size", 0))
+	if filter.minSize < 0 || filter.maxSize < 0 || filter.maxSize > 0 && filter.maxSize < filter.minSize {
+		return filter, toolError(CodeInvalidArgument, "Error: min_size and max_size must be non-negative, with max_size at least min_size")
+	}
+	var err error
+	if filter.after, err = parseTimeArgument(request, "modified_after"); err != nil {
+		return filter, err
+	}
+	if filter.before, err = parseTimeArgument(request, "modified_before"); err != nil {
+		return filter, err
+	}
+	if filter.maxDepth < 0 {
+		return filter, toolError(CodeInvalidArgument, "Error: max_depth must not be negative").With("max_depth", filter.maxDepth)
+	}
+	if filter.maxResults < 1 || filter.maxResults > maxFindResults {
+		return filter, toolError(CodeInvalidArgument, "Error: max_results must be between 1 and %d", maxFindResults).With("max_results", filter.maxResults)
+	}
+	return filter, nil
+}
+
+// findFiles walks root without following symbolic links, in lexical order,
+// and collects the entries that pass filter
+func (fs *FilesystemHandler) findFiles(ctx context.Context, root string, filter findFilter) (*FindFilesResult, error) {
+	result := &FindFilesResult{Path: root, Results: []DirectoryEntry{}}
+	now := time.Now()
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil || path == root {
+			return nil // Skip unreadable entries
+		}
+		if !fs.isPathInAllowedDirs(path) || filter.walk.skip(path, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		depth := strings.Count(rel, string(filepath.Separator)) + 1
+		// Directories at the depth limit are still matched, just not entered
+		var skip error
+		if filter.maxDepth > 0 && depth >= filter.maxDepth && d.IsDir() {
+			skip = filepath.SkipDir
+		}
+
+		info, err := d.Info()
+		if err != nil || !filter.matches(d, info) {
+			return skip
+		}
+		if len(result.Results) == filter.maxResults {
+			result.Truncated = true
+			return errFindLimit
+		}
+		entry := DirectoryEntry{
+			Name:      d.Name(),
+			Path:      path,
+			Type:      entryType(d),
+			Mode:      fmt.Sprintf("%03o", info.Mode().Perm()),
+			URI:       pathToResourceURI(path),
+			FileTimes: localFileTimes(info, now),
+		}
+		if entry.Type == FindTypeFile {
+			entry.Size = info.Size()
+		}
+		result.Results = append(result.Results, entry)
+		return skip
+	})
+	if err != nil && !errors.Is(err, errFindLimit) {
+		return nil, err
+	}
+	return result, nil
+}
+
+// matches reports whether an entry passes the filter. Sizes only apply to
+// regular files.
+func (f findFilter) matches(d os.DirEntry, info os.FileInfo) bool {
+	if f.kind != FindTypeAny && entryType(d) != f.kind {
+		return false
+	}
+	if f.match != nil && !f.match(d.Name()) {
+		return false
+	}
+	if f.minSize > 0 || f.maxSize > 0 {
+		if !d.Type().IsRegular() || info.Size() < f.minSize || f.maxSize > 0 && info.Size() > f.maxSize {
+			return false
+		}
+	}
+	if !f.after.IsZero() && !info.ModTime().After(f.after) {
+		return false
+	}
+	if !f.before.IsZero() && !info.ModTime().Before(f.before) {
+		return false
+	}
+	return true
+}
+
+// entryType names the kind of a walked entry; anything that is neither a
+// directory nor a link, such as a device, counts as a file
+func entryType(d os.DirEntry) string {
+	switch {
+	case d.Type()&os.ModeSymlink != 0:
+		return FindTypeSymlink
+	case d.IsDir():
+		return FindTypeDirectory
+	}
+	return FindTypeFile
+}
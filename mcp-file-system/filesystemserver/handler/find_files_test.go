@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindFiles(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	old := time.Now().Add(-48 * time.Hour)
+	for name, size := range map[string]int{
+		"app.log":          100,
+		"notes.txt":        10,
+		"logs/old.log":     5000,
+		"logs/deep/x.log":  20,
+		"logs/deep/y.conf": 30,
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(strings.Repeat("x", size)), 0644))
+	}
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "logs/old.log"), old, old))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	find := fsHandler.FormatToolCall(fsHandler.HandleFindFiles)
+	paths := func(args map[string]any) []string {
+		args["path"] = dir
+		args["format"] = FormatJSON
+		res := callTool(t, find, args)
+		require.False(t, res.IsError, res.Content)
+		var result FindFilesResult
+		require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &result))
+		var paths []string
+		for _, entry := range result.Results {
+			rel, err := filepath.Rel(dir, entry.Path)
+			require.NoError(t, err)
+			paths = append(paths, filepath.ToSlash(rel))
+		}
+		return paths
+	}
+
+	assert.Equal(t, []string{"app.log", "logs/deep/x.log", "logs/old.log"}, paths(map[string]any{"name": "*.log"}))
+	assert.Equal(t, []string{"app.log"}, paths(map[string]any{"name": "*.log", "max_depth": 1}))
+	assert.Equal(t, []string{"logs", "logs/deep"}, paths(map[string]any{"type": FindTypeDirectory}))
+	assert.Equal(t, []string{"app.log", "logs/old.log"}, paths(map[string]any{"min_size": 50}))
+	assert.Equal(t, []string{"logs/deep/x.log", "logs/deep/y.conf"}, paths(map[string]any{"min_size": 20, "max_size": 30}))
+	assert.Equal(t, []string{"logs/old.log"}, paths(map[string]any{"type": FindTypeFile, "modified_before": time.Now().Add(-time.Hour).Format(time.RFC3339)}))
+	assert.Len(t, paths(map[string]any{"type": FindTypeFile, "modified_after": time.Now().Add(-time.Hour).Format(time.RFC3339)}), 4)
+
+	res := callTool(t, find, map[string]any{"path": dir, "format": FormatJSON, "max_results": 2})
+	var result FindFilesResult
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &result))
+	assert.Len(t, result.Results, 2)
+	assert.True(t, result.Truncated)
+
+	res = callTool(t, fsHandler.HandleFindFiles, map[string]any{"path": dir, "name": "*.conf"})
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Found 1 entries")
+
+	res = callTool(t, fsHandler.HandleFindFiles, map[string]any{"path": dir, "type": "socket"})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	res = callTool(t, fsHandler.HandleFindFiles, map[string]any{"path": dir, "min_size": 10, "max_size": 5})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	res = callTool(t, fsHandler.HandleFindFiles, map[string]any{"path": filepath.Join(dir, "notes.txt")})
+	assert.Equal(t, CodeWrongType, ResultError(res).Code)
+}
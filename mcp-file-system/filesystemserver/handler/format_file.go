@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/format"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"golang.org/x/tools/imports"
+)
+
+// formatterTimeout bounds external formatter runs
+const formatterTimeout = 30 * time.Second
+
+// defaultFormatters maps file extensions to external formatter commands. Each
+// command reads the source on stdin and writes the formatted result to stdout;
+// "{path}" in an argument is replaced with the file path.
+var defaultFormatters = map[string][]string{
+	".py":   {"black", "-q", "-"},
+	".pyi":  {"black", "-q", "--pyi", "-"},
+	".js":   {"prettier", "--stdin-filepath", "{path}"},
+	".jsx":  {"prettier", "--stdin-filepath", "{path}"},
+	".ts":   {"prettier", "--stdin-filepath", "{path}"},
+	".tsx":  {"prettier", "--stdin-filepath", "{path}"},
+	".css":  {"prettier", "--stdin-filepath", "{path}"},
+	".scss": {"prettier", "--stdin-filepath", "{path}"},
+	".html": {"prettier", "--stdin-filepath", "{path}"},
+	".json": {"prettier", "--stdin-filepath", "{path}"},
+	".md":   {"prettier", "--stdin-filepath", "{path}"},
+	".yaml": {"prettier", "--stdin-filepath", "{path}"},
+	".yml":  {"prettier", "--stdin-filepath", "{path}"},
+}
+
+// WithFormatters adds or overrides external formatter commands by file extension
+func WithFormatters(formatters map[string][]string) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		for ext, command := range formatters {
+			if !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+			fs.formatters[strings.ToLower(ext)] = command
+		}
+	}
+}
+
+// HandleFormatFile handles the format_file tool
+func (fs *FilesystemHandler) HandleFormatFile(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+
+	dryRun := false
+	if val, err := request.RequireBool("dry_run"); err == nil {
+		dryRun = val
+	}
+
+	validate := fs.validatePath
+	if !dryRun {
+		validate = fs.validateWritablePath
+	}
+	validPath, err := validate(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	// Server-wide dry-run mode applies after checking the file is writable
+	dryRun = dryRun || fs.dryRunMode()
+
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if info.IsDir() {
+		return errorResult(toolError(CodeWrongType, "Error: Cannot format a directory")), nil
+	}
+
+	original, err := os.ReadFile(validPath)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error reading file: %w", err)), nil
+	}
+
+	formatted, formatter, err := fs.formatSource(ctx, validPath, original)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error formatting file: %w", err)), nil
+	}
+
+	if bytes.Equal(original, formatted) {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("%s is already formatted (%s)", path, formatter),
+				},
+			},
+		}, nil
+	}
+
+	diff := unifiedDiff("a/"+filepath.Base(validPath), "b/"+filepath.Base(validPath), string(original), string(formatted))
+	if dryRun {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Dry run: %s would be reformatted by %s:\n\n%s", path, formatter, diff),
+				},
+			},
+		}, nil
+	}
+
+	if err := os.WriteFile(validPath, formatted, info.Mode().Perm()); err != nil {
+		return errorResult(fmt.Errorf("Error writing file: %w", err)), nil
+	}
+
+	resourceURI := pathToResourceURI(validPath)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: fmt.Sprintf("Formatted %s with %s:\n\n%s", path, formatter, diff),
+			},
+			mcp.EmbeddedResource{
+				Type: "resource",
+				Resource: mcp.TextResourceContents{
+					URI:      resourceURI,
+					MIMEType: "text/x-diff",
+					Text:     diff,
+				},
+			},
+		},
+	}, nil
+}
+
+// formatSource formats src according to the file's extension and returns the
+// result along with the name of the formatter used
+func (fs *FilesystemHandler) formatSource(ctx context.Context, path string, src []byte) ([]byte, string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+
+	// External formatters configured for .go take precedence over the built-in one
+	command, ok := fs.formatters[ext]
+	if !ok {
+		if ext == ".go" {
+			out, err := imports.Process(path, src, &imports.Options{
+				Comments:  true,
+				TabIndent: true,
+				TabWidth:  8,
+			})
+			if err != nil {
+				// Fall back to plain gofmt if import resolution fails
+				if out, fmtErr := format.Source(src); fmtErr == nil {
+					return out, "gofmt", nil
+				}
+				return nil, "", err
+			}
+			return out, "goimports", nil
+		}
+		return nil, "", fmt.Errorf("no formatter configured for %q files", ext)
+	}
+	if len(command) == 0 {
+		return nil, "", fmt.Errorf("empty formatter command for %q files", ext)
+	}
+
+	args := make([]string, len(command)-1)
+	for i, arg := range command[1:] {
+		args[i] = strings.ReplaceAll(arg, "{path}", path)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, formatterTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(runCtx, command[0], args...)
+	cmd.Dir = filepath.Dir(path)
+	cmd.Stdin = bytes.NewReader(src)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, "", fmt.Errorf("%s failed: %v: %s", command[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), command[0], nil
+}
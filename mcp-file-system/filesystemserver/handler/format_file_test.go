@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleFormatFile(t *testing.T) {
+	dir := t.TempDir()
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	unformatted := "package main\nimport \"fmt\"\nfunc main(){fmt.Println(\"hi\")}\n"
+	goFile := filepath.Join(dir, "main.go")
+
+	t.Run("dry run leaves file untouched", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(goFile, []byte(unformatted), 0644))
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{
+			"path":    goFile,
+			"dry_run": true,
+		}
+		result, err := handler.HandleFormatFile(context.Background(), request)
+		require.NoError(t, err)
+		require.False(t, result.IsError, result.Content)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "+func main() { fmt.Println(\"hi\") }")
+
+		content, err := os.ReadFile(goFile)
+		require.NoError(t, err)
+		assert.Equal(t, unformatted, string(content))
+	})
+
+	t.Run("formats go file", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(goFile, []byte(unformatted), 0644))
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"path": goFile}
+		result, err := handler.HandleFormatFile(context.Background(), request)
+		require.NoError(t, err)
+		require.False(t, result.IsError, result.Content)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "goimports")
+
+		content, err := os.ReadFile(goFile)
+		require.NoError(t, err)
+		assert.Equal(t, "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println(\"hi\") }\n", string(content))
+
+		// Running again reports nothing to do
+		result, err = handler.HandleFormatFile(context.Background(), request)
+		require.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "already formatted")
+	})
+
+	t.Run("configured external formatter", func(t *testing.T) {
+		upper, err := NewFilesystemHandler(resolveAllowedDirs(t, dir), WithFormatters(map[string][]string{
+			"txt": {"tr", "a-z", "A-Z"},
+		}))
+		require.NoError(t, err)
+
+		txtFile := filepath.Join(dir, "notes.txt")
+		require.NoError(t, os.WriteFile(txtFile, []byte("shout\n"), 0644))
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"path": txtFile}
+		result, err := upper.HandleFormatFile(context.Background(), request)
+		require.NoError(t, err)
+		require.False(t, result.IsError, result.Content)
+
+		content, err := os.ReadFile(txtFile)
+		require.NoError(t, err)
+		assert.Equal(t, "SHOUT\n", string(content))
+	})
+
+	t.Run("no formatter", func(t *testing.T) {
+		other := filepath.Join(dir, "data.bin")
+		require.NoError(t, os.WriteFile(other, []byte("x"), 0644))
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"path": other}
+		result, err := handler.HandleFormatFile(context.Background(), request)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+	})
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	assert.Equal(t, "", unifiedDiff("a", "b", "same\n", "same\n"))
+	assert.Equal(t, "--- a\n+++ b\n@@ -0,0 +1 @@\n+hello\n", unifiedDiff("a", "b", "", "hello\n"))
+	assert.Equal(t,
+		"--- a\n+++ b\n@@ -1,3 +1,3 @@\n one\n-two\n+TWO\n three\n",
+		unifiedDiff("a", "b", "one\ntwo\nthree\n", "one\nTWO\nthree\n"))
+}
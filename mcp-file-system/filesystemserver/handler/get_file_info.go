@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/djherbis/times"
@@ -19,57 +20,85 @@ func (fs *FilesystemHandler) HandleGetFileInfo(
 		return nil, err
 	}
 
+	if isSMBPath(path) {
+		return fs.smbGetFileInfo(ctx, path)
+	}
+
 	// Handle empty or relative paths like "." or "./" by converting to absolute path
 	if path == "." || path == "./" {
 		// Get current working directory
 		cwd, err := os.Getwd()
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					mcp.TextContent{
-						Type: "text",
-						Text: fmt.Sprintf("Error resolving current directory: %v", err),
-					},
-				},
-				IsError: true,
-			}, nil
+			return errorResult(fmt.Errorf("Error resolving current directory: %w", err)), nil
 		}
 		path = cwd
 	}
 
+	// A checksum is computed with sha256 unless another algorithm is named
+	algorithm := request.GetString("checksum_algorithm", "")
+	if algorithm == "" && (request.GetBool("checksum", false) || request.GetBool("sha256", false)) {
+		algorithm = HashSHA256
+	}
+	if algorithm != "" {
+		if _, _, err := newHash("checksum_algorithm", algorithm); err != nil {
+			return errorResult(err), nil
+		}
+	}
+
 	validPath, err := fs.validatePath(path)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		// A link whose target is outside the allowed directories, or
+		// missing, is described without following it
+		if link, ok := fs.unfollowedSymlink(path); ok {
+			return fs.symlinkInfoResult(ctx, link)
+		}
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
 	}
 
 	info, err := fs.getFileStats(validPath)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error getting file info: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		if link, ok := fs.unfollowedSymlink(path); ok {
+			return fs.symlinkInfoResult(ctx, link)
+		}
+		return errorResult(fmt.Errorf("Error getting file info: %w", err)), nil
 	}
 
 	// Get MIME type for files
 	mimeType := "directory"
+	var content ContentDetails
 	if info.IsFile {
 		mimeType = detectMimeType(validPath)
+		content, err = fs.contentDetails(ctx, validPath, info.Size, algorithm)
+		if err != nil {
+			return errorResult(fmt.Errorf("Error reading file: %w", err)), nil
+		}
+	}
+	target := symlinkTarget(path)
+	var resolved string
+	if target != "" {
+		resolved = validPath
 	}
 
 	resourceURI := pathToResourceURI(validPath)
+	if wantsJSON(ctx) {
+		details := FileDetails{
+			Path:           validPath,
+			Type:           "file",
+			Size:           info.Size,
+			Permissions:    info.Permissions,
+			MIMEType:       mimeType,
+			URI:            resourceURI,
+			SymlinkTarget:  target,
+			ResolvedTarget: resolved,
+			FileTimes:      newFileTimes(info.Created, info.Modified, info.Accessed, time.Now()),
+		}
+		if info.IsDirectory {
+			details.Type = "directory"
+		} else {
+			details.ContentDetails = &content
+		}
+		return jsonResult(ctx, details)
+	}
 
 	// Determine file type text
 	var fileTypeText string
@@ -79,22 +108,38 @@ func (fs *FilesystemHandler) HandleGetFileInfo(
 		fileTypeText = "File"
 	}
 
+	var extra strings.Builder
+	if info.IsFile {
+		extra.WriteString(fmt.Sprintf("\nBinary: %v", content.Binary))
+		if content.Lines != nil {
+			extra.WriteString(fmt.Sprintf("\nLines: %d", *content.Lines))
+		}
+		if content.Checksum != "" {
+			extra.WriteString(fmt.Sprintf("\n%s: %s", strings.ToUpper(content.ChecksumAlgorithm), content.Checksum))
+		}
+	}
+	if target != "" {
+		extra.WriteString(fmt.Sprintf("\nSymlink target: %s\nResolved target: %s", target, resolved))
+	}
+
+	now := time.Now()
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			mcp.TextContent{
 				Type: "text",
 				Text: fmt.Sprintf(
-					"File information for: %s\n\nSize: %d bytes\nCreated: %s\nModified: %s\nAccessed: %s\nIsDirectory: %v\nIsFile: %v\nPermissions: %s\nMIME Type: %s\nResource URI: %s",
+					"File information for: %s\n\nSize: %d bytes\nCreated: %s\nModified: %s\nAccessed: %s\nIsDirectory: %v\nIsFile: %v\nPermissions: %s\nMIME Type: %s\nResource URI: %s%s",
 					validPath,
 					info.Size,
-					info.Created.Format(time.RFC3339),
-					info.Modified.Format(time.RFC3339),
-					info.Accessed.Format(time.RFC3339),
+					describeTime(info.Created, now, false),
+					describeTime(info.Modified, now, true),
+					describeTime(info.Accessed, now, false),
 					info.IsDirectory,
 					info.IsFile,
 					info.Permissions,
 					mimeType,
 					resourceURI,
+					extra.String(),
 				),
 			},
 			mcp.EmbeddedResource{
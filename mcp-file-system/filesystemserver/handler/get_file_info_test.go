@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
@@ -109,3 +110,90 @@ func TestHandleGetFileInfo(t *testing.T) {
 		require.True(t, res.IsError)
 	})
 }
+
+func TestGetFileInfoContentDetails(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	text := filepath.Join(dir, "notes.txt")
+	require.NoError(t, os.WriteFile(text, []byte("one\ntwo\nthree"), 0644))
+	binary := filepath.Join(dir, "data.bin")
+	require.NoError(t, os.WriteFile(binary, []byte{0x7f, 'E', 'L', 'F', 0, 1, 2}, 0644))
+	link := filepath.Join(dir, "link.txt")
+	require.NoError(t, os.Symlink("notes.txt", link))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	info := fsHandler.FormatToolCall(fsHandler.HandleGetFileInfo)
+
+	details := func(args map[string]any) FileDetails {
+		args["format"] = FormatJSON
+		res := callTool(t, info, args)
+		require.False(t, res.IsError)
+		var details FileDetails
+		require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &details))
+		return details
+	}
+
+	d := details(map[string]any{"path": text})
+	require.NotNil(t, d.ContentDetails)
+	assert.False(t, d.Binary)
+	assert.Equal(t, int64(3), *d.Lines)
+	assert.Empty(t, d.SHA256)
+	assert.Empty(t, d.SymlinkTarget)
+
+	d = details(map[string]any{"path": link, "sha256": true})
+	assert.Equal(t, "notes.txt", d.SymlinkTarget)
+	assert.Equal(t, text, d.ResolvedTarget)
+	assert.False(t, d.TargetOutsideAllowed)
+	assert.Equal(t, "058053d87c818d699cde0f00d670bca0e1c6ad857caa9758ea6a556d7c64fcee", d.SHA256)
+	assert.Equal(t, HashSHA256, d.ChecksumAlgorithm)
+
+	d = details(map[string]any{"path": text, "checksum_algorithm": "MD5"})
+	assert.Equal(t, "af80be2e6d697f29a9d85b87a4999205", d.Checksum)
+	assert.Equal(t, HashMD5, d.ChecksumAlgorithm)
+	assert.Empty(t, d.SHA256)
+
+	d = details(map[string]any{"path": binary})
+	assert.True(t, d.Binary)
+	assert.Nil(t, d.Lines)
+
+	d = details(map[string]any{"path": dir})
+	assert.Nil(t, d.ContentDetails)
+
+	res := callTool(t, fsHandler.HandleGetFileInfo, map[string]any{"path": link})
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Binary: false\nLines: 3\nSymlink target: notes.txt\nResolved target: "+text)
+
+	res = callTool(t, fsHandler.HandleGetFileInfo, map[string]any{"path": text, "checksum_algorithm": "crc32"})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+}
+
+func TestGetFileInfoUnfollowedSymlink(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	outside := resolveAllowedDirs(t, t.TempDir())[0]
+	secret := filepath.Join(outside, "secret.txt")
+	require.NoError(t, os.WriteFile(secret, []byte("hidden"), 0600))
+	escaping := filepath.Join(dir, "escape")
+	require.NoError(t, os.Symlink(secret, escaping))
+	dangling := filepath.Join(dir, "dangling")
+	require.NoError(t, os.Symlink("gone.txt", dangling))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	info := fsHandler.FormatToolCall(fsHandler.HandleGetFileInfo)
+
+	res := callTool(t, info, map[string]any{"path": escaping, "format": FormatJSON})
+	require.False(t, res.IsError, res.Content)
+	var d FileDetails
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &d))
+	assert.Equal(t, "symlink", d.Type)
+	assert.Equal(t, secret, d.ResolvedTarget)
+	assert.True(t, d.TargetOutsideAllowed)
+	assert.Nil(t, d.ContentDetails)
+
+	res = callTool(t, info, map[string]any{"path": dangling})
+	require.False(t, res.IsError, res.Content)
+	text := res.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Resolved target: "+filepath.Join(dir, "gone.txt"))
+	assert.Contains(t, text, "The target does not exist")
+
+	// The target's content stays out of reach
+	res = callTool(t, fsHandler.HandleReadFile, map[string]any{"path": escaping})
+	assert.True(t, res.IsError)
+}
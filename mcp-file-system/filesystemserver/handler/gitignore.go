@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+// gitIgnore decides which paths .gitignore files exclude from a walk. Each
+// directory's .gitignore is read the first time a path below it is checked,
+// and those of the directories above the walk are included up to the root
+// of the repository or of the allowed directory. A nil *gitIgnore ignores
+// nothing, so walks can take one unconditionally.
+type gitIgnore struct {
+	top   string
+	rules map[string][]ignoreRule
+}
+
+// ignoreRule is one pattern line of a .gitignore file
+type ignoreRule struct {
+	globs []glob.Glob
+	// anchored rules match the path relative to the .gitignore's directory,
+	// the others only the name
+	anchored, negate, dirOnly bool
+}
+
+// newGitIgnore prepares a matcher for a walk starting at root
+func (fs *FilesystemHandler) newGitIgnore(root string) *gitIgnore {
+	top := filepath.Clean(root)
+	for !isDir(filepath.Join(top, ".git")) {
+		parent := filepath.Dir(top)
+		if parent == top || !fs.isPathInAllowedDirs(parent) {
+			break
+		}
+		top = parent
+	}
+	return &gitIgnore{top: top, rules: make(map[string][]ignoreRule)}
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// ignored reports whether path is excluded. The .git directory always is.
+// As in git, the last matching pattern wins, and patterns in deeper
+// directories come after those above them.
+func (g *gitIgnore) ignored(path string, isDir bool) bool {
+	if g == nil {
+		return false
+	}
+	name := filepath.Base(path)
+	if isDir && name == ".git" {
+		return true
+	}
+	rel, err := filepath.Rel(g.top, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+
+	ignored := false
+	dir := g.top
+	for i := range parts {
+		// rel is the path relative to dir, whose rules are checked next
+		rel := strings.Join(parts[i:], "/")
+		for _, rule := range g.load(dir) {
+			if rule.matches(rel, name, isDir) {
+				ignored = !rule.negate
+			}
+		}
+		dir = filepath.Join(dir, parts[i])
+	}
+	return ignored
+}
+
+// load returns the rules of dir's .gitignore, reading it once
+func (g *gitIgnore) load(dir string) []ignoreRule {
+	rules, ok := g.rules[dir]
+	if !ok {
+		if data, err := os.ReadFile(filepath.Join(dir, ".gitignore")); err == nil {
+			rules = parseGitIgnore(string(data))
+		}
+		g.rules[dir] = rules
+	}
+	return rules
+}
+
+// parseGitIgnore reads the patterns of a .gitignore file. Lines that are
+// not valid patterns are skipped, as git does.
+func parseGitIgnore(data string) []ignoreRule {
+	var rules []ignoreRule
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// Trailing spaces are dropped unless escaped
+		for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+			line = line[:len(line)-1]
+		}
+		var rule ignoreRule
+		if strings.HasPrefix(line, "!") {
+			rule.negate, line = true, line[1:]
+		} else if strings.HasPrefix(line, "\\!") || strings.HasPrefix(line, "\\#") {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly, line = true, strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		// A slash anywhere but at the end ties the pattern to the directory
+		rule.anchored = strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		for _, pattern := range expandDoubleStars(line) {
+			if g, err := glob.Compile(pattern, '/'); err == nil {
+				rule.globs = append(rule.globs, g)
+			}
+		}
+		if len(rule.globs) > 0 {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// expandDoubleStars returns the forms of a pattern in which each leading
+// **/ and inner /**/ either matches directories or none, since a glob's **
+// cannot also consume the slash next to it
+func expandDoubleStars(pattern string) []string {
+	if rest, ok := strings.CutPrefix(pattern, "**/"); ok {
+		var out []string
+		for _, tail := range expandDoubleStars(rest) {
+			out = append(out, "**/"+tail, tail)
+		}
+		return out
+	}
+	head, tail, ok := strings.Cut(pattern, "/**/")
+	if !ok {
+		return []string{pattern}
+	}
+	var out []string
+	for _, tail := range expandDoubleStars(tail) {
+		out = append(out, head+"/**/"+tail, head+"/"+tail)
+	}
+	return out
+}
+
+// matches reports whether the rule matches a path, given relative to the
+// rule's directory, and its name
+func (r ignoreRule) matches(rel, name string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	subject := name
+	if r.anchored {
+		subject = rel
+	}
+	for _, g := range r.globs {
+		if g.Match(subject) {
+			return true
+		}
+	}
+	return false
+}
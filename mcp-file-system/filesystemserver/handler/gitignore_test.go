@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitIgnore(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	files := map[string]string{
+		".gitignore":          "# build output\nnode_modules/\n/dist\n*.log\n!keep.log\ndocs/**/*.tmp\n",
+		"src/.gitignore":      "generated.go\n",
+		"src/main.go":         "",
+		"src/generated.go":    "",
+		"src/dist/app.js":     "",
+		"dist/app.js":         "",
+		"node_modules/x/i.js": "",
+		"a/node_modules":      "",
+		"debug.log":           "",
+		"keep.log":            "",
+		"docs/tmp/draft.tmp":  "",
+		"docs/draft.tmp":      "",
+		".git/HEAD":           "",
+		"sub/repo/.git/HEAD":  "",
+		"sub/repo/.gitignore": "*.md\n",
+		"sub/repo/README.md":  "",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	ignore := fsHandler.newGitIgnore(dir)
+	for name, want := range map[string]bool{
+		"src/main.go":        false,
+		"src/generated.go":   true,
+		"src/dist/app.js":    false,
+		"dist":               true,
+		"node_modules":       true,
+		"a/node_modules":     false,
+		"debug.log":          true,
+		"keep.log":           false,
+		"docs/tmp/draft.tmp": true,
+		"docs/draft.tmp":     true,
+		".git":               true,
+		"sub/repo/README.md": true,
+	} {
+		path := filepath.Join(dir, name)
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, want, ignore.ignored(path, info.IsDir()), name)
+	}
+	var none *gitIgnore
+	assert.False(t, none.ignored(filepath.Join(dir, "debug.log"), false))
+
+	// Walking from inside a repository still applies the parent's patterns
+	nested := fsHandler.newGitIgnore(filepath.Join(dir, "src"))
+	assert.True(t, nested.ignored(filepath.Join(dir, "src", "debug.log"), false))
+
+	res := callTool(t, fsHandler.FormatToolCall(fsHandler.HandleSearchFiles), map[string]any{
+		"path": dir, "pattern": "*.js", "respect_gitignore": true, "format": FormatJSON,
+	})
+	require.False(t, res.IsError, res.Content)
+	var found SearchFilesResult
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &found))
+	require.Len(t, found.Results, 1)
+	assert.Equal(t, filepath.Join(dir, "src", "dist", "app.js"), found.Results[0].Path)
+
+	res = callTool(t, fsHandler.HandleTree, map[string]any{"path": dir, "respect_gitignore": true, "depth": 5})
+	text := res.Content[0].(mcp.TextContent).Text
+	assert.NotContains(t, text, "i.js")
+	assert.NotContains(t, text, "generated.go")
+	assert.Contains(t, text, "main.go")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "debug.log"), []byte("needle"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "src", "main.go"), []byte("needle"), 0644))
+	results, err := searchWithinFiles(context.Background(), dir, "needle", 0, 100, contextLines{}, &walkFilter{root: dir, ignore: fsHandler.newGitIgnore(dir)}, fsHandler)
+	require.NoError(t, err)
+	var paths []string
+	for _, result := range results {
+		paths = append(paths, strings.TrimPrefix(result.FilePath, dir))
+	}
+	assert.Equal(t, []string{string(filepath.Separator) + filepath.Join("src", "main.go")}, paths)
+}
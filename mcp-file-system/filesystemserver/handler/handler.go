@@ -1,20 +1,176 @@
 package handler
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-filesystem-server/selfupdate"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type FilesystemHandler struct {
+	// mu guards the settings that Reload replaces at runtime: allowedDirs,
+	// readOnly, readOnlyDirs, dryRun, limits, croc, responseFormat, baseDir
+	// and aliases
+	mu          sync.RWMutex
 	allowedDirs []string
+	// readOnly rejects every modifying tool regardless of directory
+	readOnly bool
+	// readOnlyDirs are directories (with trailing separator) that tools may read but not modify
+	readOnlyDirs []string
+	// dryRun makes modifying tools report their changes instead of applying them
+	dryRun bool
+	// limits bounds inline content sizes and search work
+	limits Limits
+	// croc configures the croc binary and relay
+	croc CrocConfig
+	// responseFormat is the format of tool results when a call does not choose one
+	responseFormat string
+	// baseDir is the directory relative paths are resolved against
+	baseDir string
+	// aliases map alias names (without "@") to directories
+	aliases map[string]string
+	// sessionBases maps session IDs to the base directory chosen with set_base_directory
+	sessionBases sync.Map
+	// deleteConfirmations maps delete_file confirmation tokens to *pendingDelete
+	deleteConfirmations sync.Map
+	// continuations maps continue_result tokens to *pendingContinuation
+	continuations sync.Map
+	// logger receives structured log records
+	logger *slog.Logger
+	// tracer creates spans for tool calls and croc subprocesses
+	tracer trace.Tracer
+	// stateDir holds persistent server state such as backup history
+	stateDir string
+	// backupSchedules are run periodically by StartBackupScheduler
+	backupSchedules []BackupSchedule
+	// backupRemotes are the s3:// and sftp:// destinations backup_directory
+	// may ship to, by name
+	backupRemotes map[string]string
+	// convertRouter is the remote endpoint used by convert_to_markdown
+	convertRouter ConvertRouterConfig
+	// nextActions are the configured next action routes by tool name
+	nextActions map[string]NextActionRoute
+	// templatesDir holds the directory templates instantiated by scaffold
+	templatesDir string
+	// formatters maps file extensions to external formatter commands
+	formatters map[string][]string
+	// smbRoots are remote SMB shares exposed alongside the local allowed directories
+	smbRoots []*smbRoot
+	// keepVersions is the number of earlier versions kept of overwritten files
+	keepVersions int
+	// fileBackups sets where the backup option copies files before changing them
+	fileBackups FileBackupConfig
+	// osTrash routes trashed items to the desktop trash instead of the managed one
+	osTrash bool
+	// webhooks receive filesystem and transfer events
+	webhooks []WebhookConfig
+	// notifiers announce long-running operation outcomes to humans
+	notifiers []Notifier
+	// eventWG tracks in-flight webhook deliveries and notifications
+	eventWG sync.WaitGroup
+	// watchPaths are watched for changes reported as file.changed events
+	watchPaths []string
+	// operations records tool calls for export and replay
+	operations *operationLog
+	// journals maps journal names to their *operationLog writers
+	journals sync.Map
+	// scratch hands out per-session scratch directories; nil disables them
+	scratch *scratchSpace
+	// selfUpdate configures self_update; nil disables it
+	selfUpdate *selfupdate.Config
+	// telemetry counts tool calls for the opt-in usage statistics
+	telemetry *Telemetry
+	// tuning sets timeouts and concurrency
+	tuning Tuning
+	// slots holds a token for each executing tool call when
+	// tuning.MaxConcurrentCalls is set
+	slots chan struct{}
+	// shutdownMu guards closing, so tool calls are not added to calls
+	// while Shutdown waits for them
+	shutdownMu sync.Mutex
+	closing    bool
+	// calls tracks in-flight tool calls
+	calls sync.WaitGroup
+	// background tracks croc monitors, backup schedules and file watchers
+	background sync.WaitGroup
+	// stopped is cancelled by Shutdown to end background work and overdue calls
+	stopped context.Context
+	stop    context.CancelFunc
 }
 
-func NewFilesystemHandler(allowedDirs []string) (*FilesystemHandler, error) {
+// HandlerOption configures optional FilesystemHandler behaviour
+type HandlerOption func(*FilesystemHandler)
+
+// WithStateDir sets the directory used for persistent server state
+func WithStateDir(dir string) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		fs.stateDir = dir
+	}
+}
+
+// WithReadOnly puts the whole server, including SMB shares, in read-only mode
+func WithReadOnly(readOnly bool) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		fs.readOnly = readOnly
+	}
+}
+
+// WithReadOnlyDirs marks directories whose contents may be read but not
+// modified. They must also be passed as allowed directories to be accessible.
+func WithReadOnlyDirs(dirs []string) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		for _, dir := range dirs {
+			abs, err := filepath.Abs(dir)
+			if err != nil {
+				continue
+			}
+			fs.readOnlyDirs = append(fs.readOnlyDirs, withTrailingSeparator(abs))
+			// Resolved paths returned by validatePath must match as well
+			if real, err := filepath.EvalSymlinks(abs); err == nil && real != filepath.Clean(abs) {
+				fs.readOnlyDirs = append(fs.readOnlyDirs, withTrailingSeparator(real))
+			}
+		}
+	}
+}
+
+// WithBackupSchedules registers scheduled backup jobs
+func WithBackupSchedules(schedules []BackupSchedule) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		fs.backupSchedules = schedules
+	}
+}
+
+// WithBackupRemotes names the remote destinations backup_directory may
+// ship archives to, such as "offsite": "s3://bucket/prefix". Callers of
+// the tool can only pick one of these, never supply a URL themselves.
+func WithBackupRemotes(remotes map[string]string) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		fs.backupRemotes = remotes
+	}
+}
+
+func NewFilesystemHandler(allowedDirs []string, opts ...HandlerOption) (*FilesystemHandler, error) {
 	// Normalize and validate directories
 	normalized := make([]string, 0, len(allowedDirs))
+	var smbShares []SMBShare
 	for _, dir := range allowedDirs {
+		// SMB shares are reached over the network rather than the local filesystem
+		if isSMBPath(dir) {
+			share, err := ParseSMBURL(dir)
+			if err != nil {
+				return nil, err
+			}
+			smbShares = append(smbShares, share)
+			continue
+		}
+
 		abs, err := filepath.Abs(dir)
 		if err != nil {
 			return nil, fmt.Errorf("failed to resolve path %s: %w", dir, err)
@@ -34,15 +190,114 @@ func NewFilesystemHandler(allowedDirs []string) (*FilesystemHandler, error) {
 
 		// Ensure the path ends with a separator to prevent prefix matching issues
 		// For example, /tmp/foo should not match /tmp/foobar
-		cleanPath := filepath.Clean(abs)
-		if !strings.HasSuffix(cleanPath, string(filepath.Separator)) {
-			cleanPath = cleanPath + string(filepath.Separator)
+		normalized = append(normalized, withTrailingSeparator(abs))
+	}
+	fs := &FilesystemHandler{
+		allowedDirs:   normalized,
+		limits:        defaultLimits(),
+		tuning:        defaultTuning(),
+		logger:        slog.Default(),
+		tracer:        otel.Tracer(tracerName),
+		stateDir:      DefaultStateDir(),
+		convertRouter: convertRouterFromEnv(),
+		formatters:    make(map[string][]string, len(defaultFormatters)),
+		osTrash:       osTrashFromEnv(),
+		webhooks:      webhooksFromEnv(),
+		notifiers:     notifiersFromEnv(),
+		watchPaths:    watchPathsFromEnv(),
+		selfUpdate:    &selfupdate.Config{},
+	}
+	fs.stopped, fs.stop = context.WithCancel(context.Background())
+	for ext, command := range defaultFormatters {
+		fs.formatters[ext] = command
+	}
+	WithSMBShares(smbShares)(fs)
+	for _, opt := range opts {
+		opt(fs)
+	}
+	if fs.scratch != nil {
+		if err := fs.initScratch(); err != nil {
+			return nil, err
 		}
-		normalized = append(normalized, cleanPath)
 	}
-	return &FilesystemHandler{
-		allowedDirs: normalized,
-	}, nil
+	if fs.tuning.MaxConcurrentCalls > 0 {
+		fs.slots = make(chan struct{}, fs.tuning.MaxConcurrentCalls)
+	}
+	if err := fs.validateAliases(); err != nil {
+		return nil, err
+	}
+	if fs.baseDir != "" {
+		base, err := fs.resolvePath(context.Background(), fs.baseDir)
+		if err == nil {
+			base, err = fs.validatePath(base)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("base directory: %w", err)
+		}
+		if info, err := os.Stat(base); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("base directory is not a directory: %s", base)
+		}
+		fs.baseDir = base
+	}
+	return fs, nil
+}
+
+// DefaultStateDir returns the directory for persistent state used without
+// WithStateDir, honouring MCP_FS_STATE_DIR and falling back to the user
+// config directory
+func DefaultStateDir() string {
+	if dir := os.Getenv("MCP_FS_STATE_DIR"); dir != "" {
+		return dir
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, "mcp-filesystem-server")
+	}
+	return filepath.Join(os.TempDir(), "mcp-filesystem-server")
+}
+
+// ensureStateDir creates the state directory if needed and returns its path
+func (fs *FilesystemHandler) ensureStateDir() (string, error) {
+	if err := os.MkdirAll(fs.stateDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create state directory: %w", err)
+	}
+	return fs.stateDir, nil
+}
+
+// dirs returns the current allowed directories
+func (fs *FilesystemHandler) dirs() []string {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	return fs.allowedDirs
+}
+
+// shares returns the current SMB roots
+func (fs *FilesystemHandler) shares() []*smbRoot {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	return fs.smbRoots
+}
+
+// currentLimits returns the current size and result limits
+func (fs *FilesystemHandler) currentLimits() Limits {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	return fs.limits
+}
+
+// crocConfig returns the current croc settings
+func (fs *FilesystemHandler) crocConfig() CrocConfig {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	return fs.croc
+}
+
+// withTrailingSeparator cleans dir and appends a path separator
+func withTrailingSeparator(dir string) string {
+	cleanPath := filepath.Clean(dir)
+	if !strings.HasSuffix(cleanPath, string(filepath.Separator)) {
+		cleanPath = cleanPath + string(filepath.Separator)
+	}
+	return cleanPath
 }
 
 // pathToResourceURI converts a file path to a resource URI
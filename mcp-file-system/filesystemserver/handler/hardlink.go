@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// HardlinkResult is the JSON result of create_hardlink
+type HardlinkResult struct {
+	Path   string `json:"path"`
+	Source string `json:"source"`
+	// Replaced is set when an identical file at path was replaced by the link
+	Replaced bool `json:"replaced"`
+	// AlreadyLinked is set when path and source were already the same file
+	AlreadyLinked bool `json:"already_linked,omitempty"`
+	// SavedBytes is the space the replaced copy took up
+	SavedBytes int64 `json:"saved_bytes"`
+}
+
+// HandleCreateHardlink handles the create_hardlink tool
+func (fs *FilesystemHandler) HandleCreateHardlink(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	source, err := request.RequireString("source")
+	if err != nil {
+		return nil, err
+	}
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	replace := request.GetBool("replace_identical", false)
+
+	// The link shares the source's content and mode, so writing through it
+	// writes the source: the source must be writable too
+	validSource, err := fs.validateWritablePath(source)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	sourceInfo, err := os.Stat(validSource)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if !sourceInfo.Mode().IsRegular() {
+		return errorResult(toolError(CodeWrongType, "Error: %s is not a regular file; only files can be hard linked", source).With("source", source)), nil
+	}
+	validPath, err := fs.validateCreatablePath(path, request.GetBool("create_parents", false))
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+
+	result := HardlinkResult{Path: validPath, Source: validSource}
+	if info, err := os.Lstat(validPath); err == nil {
+		if os.SameFile(info, sourceInfo) {
+			result.AlreadyLinked = true
+			return fs.hardlinkResult(ctx, result)
+		}
+		if !replace {
+			return errorResult(toolError(CodeAlreadyExists, "Error: %s already exists; set replace_identical to replace a copy of the source", path).With("path", path)), nil
+		}
+		// Only an exact copy is replaced, so no content is ever lost
+		if !info.Mode().IsRegular() || info.Size() != sourceInfo.Size() {
+			return errorResult(toolError(CodeAlreadyExists, "Error: %s differs from %s, so it is not replaced", path, source).With("path", path)), nil
+		}
+		same, err := sameContent(ctx, validSource, validPath)
+		if err != nil {
+			return errorResult(fmt.Errorf("Error comparing files: %w", err)), nil
+		}
+		if !same {
+			return errorResult(toolError(CodeAlreadyExists, "Error: %s differs from %s, so it is not replaced", path, source).With("path", path)), nil
+		}
+		result.Replaced = true
+		result.SavedBytes = info.Size()
+	}
+
+	if fs.dryRunMode() {
+		if result.Replaced {
+			return dryRunResult(fmt.Sprintf("Would replace %s, an identical copy of %s, with a hard link to it, saving %d bytes", validPath, validSource, result.SavedBytes)), nil
+		}
+		return dryRunResult(fmt.Sprintf("Would create hard link %s to %s", validPath, validSource)), nil
+	}
+	if err := os.MkdirAll(filepath.Dir(validPath), 0755); err != nil {
+		return errorResult(fmt.Errorf("Error creating parent directories: %w", err)), nil
+	}
+	// A copy is replaced by linking beside it and renaming over it, so the
+	// path never goes missing
+	linkPath := validPath
+	if result.Replaced {
+		linkPath = filepath.Join(filepath.Dir(validPath), fmt.Sprintf(".%s.%d.link", filepath.Base(validPath), os.Getpid()))
+	}
+	if err := os.Link(validSource, linkPath); err != nil {
+		if isCrossDevice(err) {
+			return errorResult(toolError(CodeInvalidArgument, "Error: %s and %s are on different filesystems, and hard links cannot cross filesystems; use copy_file instead", source, path).
+				With("source", source).With("path", path)), nil
+		}
+		return errorResult(fmt.Errorf("Error creating hard link: %w", err)), nil
+	}
+	if result.Replaced {
+		if err := os.Rename(linkPath, validPath); err != nil {
+			os.Remove(linkPath)
+			return errorResult(fmt.Errorf("Error replacing %s: %w", path, err)), nil
+		}
+	}
+	return fs.hardlinkResult(ctx, result)
+}
+
+// hardlinkResult formats the outcome of create_hardlink
+func (fs *FilesystemHandler) hardlinkResult(ctx context.Context, result HardlinkResult) (*mcp.CallToolResult, error) {
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, result)
+	}
+	switch {
+	case result.AlreadyLinked:
+		return mcp.NewToolResultText(fmt.Sprintf("%s is already a hard link to %s", result.Path, result.Source)), nil
+	case result.Replaced:
+		return mcp.NewToolResultText(fmt.Sprintf("Replaced %s with a hard link to the identical %s, saving %s", result.Path, result.Source, formatFileSize(result.SavedBytes))), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Created hard link %s to %s", result.Path, result.Source)), nil
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateHardlink(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	source := filepath.Join(dir, "cache", "a", "lib.so")
+	copied := filepath.Join(dir, "cache", "b", "lib.so")
+	other := filepath.Join(dir, "cache", "c", "lib.so")
+	for path, content := range map[string]string{source: "artifact", copied: "artifact", other: "artifacT"} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	link := fsHandler.FormatToolCall(fsHandler.HandleCreateHardlink)
+	sameFile := func(a, b string) bool {
+		infoA, err := os.Stat(a)
+		require.NoError(t, err)
+		infoB, err := os.Stat(b)
+		require.NoError(t, err)
+		return os.SameFile(infoA, infoB)
+	}
+
+	fresh := filepath.Join(dir, "dist", "lib.so")
+	res := callTool(t, link, map[string]any{"source": source, "path": fresh, "create_parents": true})
+	require.False(t, res.IsError, res.Content)
+	assert.True(t, sameFile(source, fresh))
+
+	// An existing file is only replaced when asked, and only if identical
+	res = callTool(t, link, map[string]any{"source": source, "path": copied})
+	assert.Equal(t, CodeAlreadyExists, ResultError(res).Code)
+	res = callTool(t, link, map[string]any{"source": source, "path": copied, "replace_identical": true, "format": FormatJSON})
+	require.False(t, res.IsError, res.Content)
+	var got HardlinkResult
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &got))
+	assert.Equal(t, HardlinkResult{Path: copied, Source: source, Replaced: true, SavedBytes: 8}, got)
+	assert.True(t, sameFile(source, copied))
+
+	res = callTool(t, link, map[string]any{"source": source, "path": other, "replace_identical": true})
+	assert.Equal(t, CodeAlreadyExists, ResultError(res).Code)
+	assert.False(t, sameFile(source, other))
+
+	res = callTool(t, link, map[string]any{"source": source, "path": copied, "replace_identical": true})
+	require.False(t, res.IsError, res.Content)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "already a hard link")
+
+	res = callTool(t, link, map[string]any{"source": filepath.Join(dir, "cache"), "path": filepath.Join(dir, "dir-link")})
+	assert.Equal(t, CodeWrongType, ResultError(res).Code)
+	res = callTool(t, link, map[string]any{"source": source, "path": filepath.Join(t.TempDir(), "escape")})
+	assert.Equal(t, CodePathNotAllowed, ResultError(res).Code)
+
+	entries, err := os.ReadDir(filepath.Join(dir, "cache", "b"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary link is left behind")
+}
+
+func TestCreateHardlinkReadOnlySource(t *testing.T) {
+	dirs := resolveAllowedDirs(t, t.TempDir(), t.TempDir())
+	readOnly, writable := dirs[0], dirs[1]
+	source := filepath.Join(readOnly, "f")
+	require.NoError(t, os.WriteFile(source, []byte("original"), 0644))
+	fsHandler, err := NewFilesystemHandler(dirs, WithReadOnlyDirs([]string{readOnly}))
+	require.NoError(t, err)
+
+	// A link would let the read-only file be changed through it
+	link := filepath.Join(writable, "l")
+	res := callTool(t, fsHandler.HandleCreateHardlink, map[string]any{"source": source, "path": link})
+	require.True(t, res.IsError)
+	assert.Equal(t, CodeReadOnly, ResultError(res).Code)
+	assert.NoFileExists(t, link)
+}
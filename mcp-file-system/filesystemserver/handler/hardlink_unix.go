@@ -0,0 +1,14 @@
+//go:build !windows
+
+package handler
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isCrossDevice reports whether err is a link or rename refused because
+// its two paths are on different filesystems
+func isCrossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
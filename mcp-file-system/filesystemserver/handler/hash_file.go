@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// HashResult is the JSON result of hash_file
+type HashResult struct {
+	Path      string `json:"path"`
+	Algorithm string `json:"algorithm"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+	// Expected is the digest the file was verified against, if any
+	Expected string `json:"expected,omitempty"`
+}
+
+// HandleHashFile handles the hash_file tool
+func (fs *FilesystemHandler) HandleHashFile(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	// The expected digest may carry its algorithm as a prefix, as in
+	// "sha256:ab12..."; without one, the algorithm follows from its length
+	expected := strings.ToLower(strings.TrimSpace(request.GetString("expected", "")))
+	algorithm := request.GetString("algorithm", "")
+	if prefix, digest, ok := strings.Cut(expected, ":"); ok {
+		expected = digest
+		if algorithm == "" {
+			algorithm = prefix
+		}
+	}
+	if expected != "" {
+		if _, err := hex.DecodeString(expected); err != nil {
+			return errorResult(toolError(CodeInvalidArgument, "Error: expected must be a hex digest").With("expected", expected)), nil
+		}
+		if algorithm == "" {
+			if algorithm = hashAlgorithmForDigest(expected); algorithm == "" {
+				return errorResult(toolError(CodeInvalidArgument, "Error: no algorithm has %d-digit digests; give algorithm", len(expected)).With("expected", expected)), nil
+			}
+		}
+	}
+	if algorithm == "" {
+		algorithm = HashSHA256
+	}
+	h, algorithm, err := newHash("algorithm", algorithm)
+	if err != nil {
+		return errorResult(err), nil
+	}
+	if expected != "" && len(expected) != h.Size()*2 {
+		return errorResult(toolError(CodeInvalidArgument, "Error: expected has %d digits, but %s digests have %d", len(expected), algorithm, h.Size()*2).With("expected", expected)), nil
+	}
+
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if info.IsDir() {
+		return errorResult(toolError(CodeWrongType, "Error: %s is a directory", path).With("path", path)), nil
+	}
+	f, err := os.Open(validPath)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	defer f.Close()
+	size, err := hashStream(ctx, f, h)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error reading file: %w", err)), nil
+	}
+
+	result := HashResult{Path: validPath, Algorithm: algorithm, Digest: hex.EncodeToString(h.Sum(nil)), Size: size, Expected: expected}
+	if expected != "" && result.Digest != expected {
+		return errorResult(toolError(CodeVerifyFailed, "%s does not match: its %s is %s, expected %s", validPath, algorithm, result.Digest, expected).
+			With("path", validPath).With("algorithm", algorithm).With("digest", result.Digest).With("expected", expected)), nil
+	}
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, result)
+	}
+	text := fmt.Sprintf("%s  %s\nAlgorithm: %s\nSize: %d bytes", result.Digest, validPath, algorithm, size)
+	if expected != "" {
+		text += "\nVerified: the digest matches the expected one"
+	}
+	return mcp.NewToolResultText(text), nil
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashFile(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	path := filepath.Join(dir, "hello.txt")
+	require.NoError(t, os.WriteFile(path, []byte("hello\n"), 0644))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	hashFile := fsHandler.FormatToolCall(fsHandler.HandleHashFile)
+
+	digests := map[string]string{
+		HashMD5:    "b1946ac92492d2347c6235b4d2611184",
+		HashSHA1:   "f572d396fae9206628714fb2ce00f72e94f2258f",
+		HashSHA256: "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03",
+	}
+	for algorithm, want := range digests {
+		res := callTool(t, hashFile, map[string]any{"path": path, "algorithm": algorithm, "format": FormatJSON})
+		require.False(t, res.IsError, res.Content)
+		var got HashResult
+		require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &got))
+		assert.Equal(t, HashResult{Path: path, Algorithm: algorithm, Digest: want, Size: 6}, got, algorithm)
+	}
+
+	// sha256 is the default, and expected picks the algorithm by length or prefix
+	res := callTool(t, hashFile, map[string]any{"path": path})
+	require.False(t, res.IsError, res.Content)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, digests[HashSHA256]+"  "+path)
+	res = callTool(t, hashFile, map[string]any{"path": path, "expected": "B1946AC92492D2347C6235B4D2611184"})
+	require.False(t, res.IsError, res.Content)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Algorithm: md5\nSize: 6 bytes\nVerified")
+	// The XXH64 digest of empty input is a published test vector
+	empty := filepath.Join(dir, "empty")
+	require.NoError(t, os.WriteFile(empty, nil, 0644))
+	res = callTool(t, hashFile, map[string]any{"path": empty, "expected": "xxhash:ef46db3751d8e999"})
+	require.False(t, res.IsError, res.Content)
+
+	res = callTool(t, hashFile, map[string]any{"path": path, "expected": "sha1:" + digests[HashMD5]})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	res = callTool(t, hashFile, map[string]any{"path": path, "expected": "00000000000000000000000000000000"})
+	require.True(t, res.IsError)
+	assert.Equal(t, CodeVerifyFailed, ResultError(res).Code)
+	res = callTool(t, hashFile, map[string]any{"path": dir})
+	assert.Equal(t, CodeWrongType, ResultError(res).Code)
+	res = callTool(t, hashFile, map[string]any{"path": path, "algorithm": "crc32"})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// defaultHeadTailLines is how many lines read_file_head_tail shows from
+	// each end when neither head nor tail is given
+	defaultHeadTailLines = 10
+	// maxHeadTailLines bounds the head and tail arguments
+	maxHeadTailLines = 10000
+	// tailChunkSize is how much a tail is read back from the end at a time
+	tailChunkSize = 64 * 1024
+)
+
+// HeadTail is the JSON result of read_file_head_tail
+type HeadTail struct {
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	Head      string `json:"head"`
+	HeadLines int    `json:"head_lines"`
+	Tail      string `json:"tail"`
+	TailLines int    `json:"tail_lines"`
+	// OmittedBytes is the size of the middle part left out; Complete is set
+	// when nothing was, and the whole file is in Head
+	OmittedBytes int64 `json:"omitted_bytes"`
+	Complete     bool  `json:"complete"`
+}
+
+// HandleReadFileHeadTail handles the read_file_head_tail tool
+func (fs *FilesystemHandler) HandleReadFileHeadTail(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	args := request.GetArguments()
+	_, hasHead := args["head"]
+	_, hasTail := args["tail"]
+	head, tail := request.GetInt("head", 0), request.GetInt("tail", 0)
+	if !hasHead && !hasTail {
+		head, tail = defaultHeadTailLines, defaultHeadTailLines
+	}
+	for name, n := range map[string]int{"head": head, "tail": tail} {
+		if n < 0 || n > maxHeadTailLines {
+			return errorResult(toolError(CodeInvalidArgument, "Error: %s must be between 0 and %d", name, maxHeadTailLines).With(name, n)), nil
+		}
+	}
+	if head == 0 && tail == 0 {
+		return errorResult(toolError(CodeInvalidArgument, "Error: head or tail must be at least 1")), nil
+	}
+
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if info.IsDir() {
+		return errorResult(toolError(CodeWrongType, "Error: %s is a directory; use list_directory or workspace_stats", path).With("path", path)), nil
+	}
+	if mimeType := detectMimeType(validPath); !isTextFile(mimeType) {
+		return errorResult(toolError(CodeWrongType, "Error: %s is not a text file (%s); use preview_file or read_file with offset and length", path, mimeType).With("path", path)), nil
+	}
+
+	// Each end gets half of the inline limit
+	limit := fs.currentLimits().MaxInlineSize / 2
+	result, err := readHeadTail(ctx, validPath, info.Size(), head, tail, limit)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error reading %s: %w", path, err)), nil
+	}
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, result)
+	}
+	return mcp.NewToolResultText(describeHeadTail(result)), nil
+}
+
+// readHeadTail reads the first head and last tail lines of a file of the
+// given size, each at most limit bytes, without reading the middle
+func readHeadTail(ctx context.Context, path string, size int64, head, tail int, limit int64) (*HeadTail, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	result := &HeadTail{Path: path, Size: size}
+	headRaw, err := readHead(f, head, limit)
+	if err != nil {
+		return nil, err
+	}
+	headEnd := int64(len(headRaw))
+	tailStart := size
+	var tailRaw []byte
+	if tail > 0 {
+		if tailRaw, err = readTail(ctx, f, size, tail, limit); err != nil {
+			return nil, err
+		}
+		tailStart = size - int64(len(tailRaw))
+	}
+
+	// Both ends decode in the encoding of the start of the file, as the
+	// tail alone may lack the byte order mark or look like another encoding
+	textEncoding, err := detectFileEncoding(path)
+	if err != nil {
+		return nil, err
+	}
+	if headEnd >= tailStart {
+		// The ends meet, so the whole file is shown once
+		whole := append(headRaw, tailRaw[headEnd-tailStart:]...)
+		text, _, err := decodeText(whole, textEncoding)
+		if err != nil {
+			return nil, err
+		}
+		result.Head, result.HeadLines, result.Complete = text, countLines(text), true
+		return result, nil
+	}
+	// Characters cut by the byte limits are left out
+	headPart := &rangePart{content: headRaw}
+	headPart.trimPartialRunes(textEncoding, size)
+	tailPart := &rangePart{content: tailRaw, offset: tailStart}
+	tailPart.trimPartialRunes(textEncoding, size)
+	if result.Head, _, err = decodeText(headPart.content, textEncoding); err != nil {
+		return nil, err
+	}
+	if result.Tail, _, err = decodeText(tailPart.content, textEncoding); err != nil {
+		return nil, err
+	}
+	result.HeadLines, result.TailLines = countLines(result.Head), countLines(result.Tail)
+	result.OmittedBytes = tailPart.offset - int64(len(headPart.content))
+	return result, nil
+}
+
+// readHead reads the first n lines from f, stopping at limit bytes
+func readHead(f *os.File, n int, limit int64) ([]byte, error) {
+	var buf bytes.Buffer
+	reader := bufio.NewReader(io.LimitReader(f, limit))
+	for i := 0; i < n; i++ {
+		line, err := reader.ReadBytes('\n')
+		buf.Write(line)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// readTail reads the last n lines from f, going back from the end a chunk
+// at a time and stopping at limit bytes. A final line break does not start
+// another line.
+func readTail(ctx context.Context, f *os.File, size int64, n int, limit int64) ([]byte, error) {
+	var tail []byte
+	pos := size
+	for pos > 0 && int64(len(tail)) < limit {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		chunk := int64(tailChunkSize)
+		if chunk > pos {
+			chunk = pos
+		}
+		if int64(len(tail))+chunk > limit {
+			chunk = limit - int64(len(tail))
+		}
+		pos -= chunk
+		buf := make([]byte, chunk)
+		if _, err := f.ReadAt(buf, pos); err != nil && err != io.EOF {
+			return nil, err
+		}
+		tail = append(buf, tail...)
+
+		// The line breaks before the last n lines, ignoring a final one
+		search := bytes.TrimSuffix(tail, []byte{'\n'})
+		breaks := 0
+		for i := len(search) - 1; i >= 0; i-- {
+			if search[i] != '\n' {
+				continue
+			}
+			if breaks++; breaks == n {
+				return tail[i+1:], nil
+			}
+		}
+	}
+	if pos > 0 {
+		// The limit was reached within a line, so start at the next one
+		if i := bytes.IndexByte(tail, '\n'); i >= 0 && i+1 < len(tail) {
+			return tail[i+1:], nil
+		}
+	}
+	return tail, nil
+}
+
+// countLines counts the lines of text, where a final line break does not
+// start another line
+func countLines(text string) int {
+	if text == "" {
+		return 0
+	}
+	return strings.Count(strings.TrimSuffix(text, "\n"), "\n") + 1
+}
+
+// describeHeadTail renders the ends of a file as text
+func describeHeadTail(r *HeadTail) string {
+	var sb strings.Builder
+	section := func(title, text string) {
+		sb.WriteString(fmt.Sprintf("==> %s <==\n", title))
+		sb.WriteString(text)
+		if text != "" && !strings.HasSuffix(text, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	if r.Complete {
+		section(fmt.Sprintf("%s (%s), all %d lines", r.Path, formatFileSize(r.Size), r.HeadLines), r.Head)
+		return sb.String()
+	}
+	title := fmt.Sprintf("%s (%s), ", r.Path, formatFileSize(r.Size))
+	if r.HeadLines > 0 {
+		section(fmt.Sprintf("%sfirst %d lines", title, r.HeadLines), r.Head)
+		title = ""
+	}
+	sb.WriteString(fmt.Sprintf("==> %s omitted <==\n", formatFileSize(r.OmittedBytes)))
+	if r.TailLines > 0 {
+		section(fmt.Sprintf("%slast %d lines", title, r.TailLines), r.Tail)
+	}
+	return sb.String()
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadFileHeadTail(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	var sb strings.Builder
+	for i := 1; i <= 1000; i++ {
+		sb.WriteString(fmt.Sprintf("line %d\n", i))
+	}
+	file := filepath.Join(dir, "app.log")
+	require.NoError(t, os.WriteFile(file, []byte(sb.String()), 0644))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	res := callTool(t, fsHandler.FormatToolCall(fsHandler.HandleReadFileHeadTail), map[string]any{"path": file, "head": 2, "tail": 3, "format": FormatJSON})
+	require.False(t, res.IsError, res.Content)
+	var ends HeadTail
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &ends))
+	assert.Equal(t, "line 1\nline 2\n", ends.Head)
+	assert.Equal(t, "line 998\nline 999\nline 1000\n", ends.Tail)
+	assert.Equal(t, 3, ends.TailLines)
+	assert.Equal(t, int64(sb.Len()-len(ends.Head)-len(ends.Tail)), ends.OmittedBytes)
+	assert.False(t, ends.Complete)
+
+	res = callTool(t, fsHandler.HandleReadFileHeadTail, map[string]any{"path": file, "tail": 1})
+	require.False(t, res.IsError, res.Content)
+	text := res.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "last 1 lines <==\nline 1000\n")
+	assert.NotContains(t, text, "line 1\n")
+
+	// The ends of a short file meet, so it is returned whole
+	short := filepath.Join(dir, "short.txt")
+	require.NoError(t, os.WriteFile(short, []byte("a\nb\nc"), 0644))
+	res = callTool(t, fsHandler.FormatToolCall(fsHandler.HandleReadFileHeadTail), map[string]any{"path": short, "format": FormatJSON})
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &ends))
+	assert.True(t, ends.Complete)
+	assert.Equal(t, "a\nb\nc", ends.Head)
+	assert.Equal(t, 3, ends.HeadLines)
+
+	res = callTool(t, fsHandler.HandleReadFileHeadTail, map[string]any{"path": file, "head": 0, "tail": 0})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+}
+
+func TestReadTailAcrossChunks(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "long.log")
+	line := strings.Repeat("x", 1000) + "\n"
+	require.NoError(t, os.WriteFile(file, []byte(strings.Repeat(line, 200)+"last"), 0644))
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+	info, err := f.Stat()
+	require.NoError(t, err)
+
+	tail, err := readTail(context.Background(), f, info.Size(), 100, 1<<20)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat(line, 99)+"last", string(tail))
+
+	// The limit cuts the tail at a line break
+	tail, err = readTail(context.Background(), f, info.Size(), 100, 2500)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat(line, 2)+"last", string(tail))
+}
+
+func TestReadFileHeadTailEncoding(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	// A Latin-1 file whose last line happens to be valid UTF-8
+	file := filepath.Join(dir, "latin.txt")
+	content := "caf\xe9\n" + strings.Repeat("plain\n", 100) + "\xc3\xa9t\n"
+	require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	res := callTool(t, fsHandler.FormatToolCall(fsHandler.HandleReadFileHeadTail), map[string]any{"path": file, "head": 1, "tail": 1, "format": FormatJSON})
+	require.False(t, res.IsError, res.Content)
+	var ends HeadTail
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &ends))
+	assert.Equal(t, "café\n", ends.Head)
+	assert.Equal(t, "Ã©t\n", ends.Tail, "the tail is decoded like the head")
+}
@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"fmt"
 	"mime"
 	"os"
 	"path/filepath"
@@ -31,7 +30,7 @@ func (fs *FilesystemHandler) isPathInAllowedDirs(path string) bool {
 	}
 
 	// Check if the path is within any of the allowed directories
-	for _, dir := range fs.allowedDirs {
+	for _, dir := range fs.dirs() {
 		if strings.HasPrefix(absPath, dir) {
 			return true
 		}
@@ -40,18 +39,23 @@ func (fs *FilesystemHandler) isPathInAllowedDirs(path string) bool {
 }
 
 func (fs *FilesystemHandler) validatePath(requestedPath string) (string, error) {
+	// SMB paths are only understood by tools with explicit SMB support
+	if isSMBPath(requestedPath) {
+		return "", toolError(CodeInvalidArgument, "SMB paths are not supported by this tool: %s", requestedPath).With("path", requestedPath)
+	}
+
 	// Always convert to absolute path first
 	abs, err := filepath.Abs(requestedPath)
 	if err != nil {
-		return "", fmt.Errorf("invalid path: %w", err)
+		return "", toolError(CodeInvalidArgument, "invalid path: %w", err).With("path", requestedPath)
 	}
 
 	// Check if path is within allowed directories
 	if !fs.isPathInAllowedDirs(abs) {
-		return "", fmt.Errorf(
+		return "", toolError(CodePathNotAllowed,
 			"access denied - path outside allowed directories: %s",
 			abs,
-		)
+		).With("path", abs)
 	}
 
 	// Handle symlinks
@@ -64,27 +68,127 @@ func (fs *FilesystemHandler) validatePath(requestedPath string) (string, error)
 		parent := filepath.Dir(abs)
 		realParent, err := filepath.EvalSymlinks(parent)
 		if err != nil {
-			return "", fmt.Errorf("parent directory does not exist: %s", parent)
+			return "", toolError(CodeNotFound, "parent directory does not exist: %s", parent).With("path", parent)
 		}
 
 		if !fs.isPathInAllowedDirs(realParent) {
-			return "", fmt.Errorf(
+			return "", toolError(CodePathNotAllowed,
 				"access denied - parent directory outside allowed directories",
-			)
+			).With("path", abs)
 		}
 		return abs, nil
 	}
 
 	// Check if the real path (after resolving symlinks) is still within allowed directories
 	if !fs.isPathInAllowedDirs(realPath) {
-		return "", fmt.Errorf(
+		return "", toolError(CodePathNotAllowed,
 			"access denied - symlink target outside allowed directories",
-		)
+		).With("path", abs)
 	}
 
 	return realPath, nil
 }
 
+// validateWritablePath validates a path that a tool is about to modify,
+// additionally rejecting paths inside read-only directories
+func (fs *FilesystemHandler) validateWritablePath(requestedPath string) (string, error) {
+	validPath, err := fs.validatePath(requestedPath)
+	if err != nil {
+		return "", err
+	}
+	if fs.readOnlyMode() {
+		return "", toolError(CodeReadOnly, "access denied - server is in read-only mode")
+	}
+	if fs.isReadOnly(validPath) {
+		return "", toolError(CodeReadOnly, "access denied - path is in a read-only directory: %s", validPath).With("path", validPath)
+	}
+	return validPath, nil
+}
+
+// validateCreatablePath validates a path that a tool is about to create.
+// With createParents, its parent directories may be missing as well: the
+// nearest existing ancestor is checked like validateWritablePath does, and
+// the missing part is joined to its resolved path.
+func (fs *FilesystemHandler) validateCreatablePath(requestedPath string, createParents bool) (string, error) {
+	if !createParents || isSMBPath(requestedPath) {
+		return fs.validateWritablePath(requestedPath)
+	}
+	abs, err := filepath.Abs(requestedPath)
+	if err != nil {
+		return "", toolError(CodeInvalidArgument, "invalid path: %w", err).With("path", requestedPath)
+	}
+	if !fs.isPathInAllowedDirs(abs) {
+		return "", toolError(CodePathNotAllowed,
+			"access denied - path outside allowed directories: %s",
+			abs,
+		).With("path", abs)
+	}
+	existing := abs
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+	validExisting, err := fs.validateWritablePath(existing)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(existing, abs)
+	if err != nil {
+		return "", toolError(CodeInvalidArgument, "invalid path: %w", err).With("path", requestedPath)
+	}
+	return filepath.Join(validExisting, rel), nil
+}
+
+// missingParents returns the ancestors of path that do not exist yet,
+// outermost first
+func missingParents(path string) []string {
+	var missing []string
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if _, err := os.Lstat(dir); err == nil || filepath.Dir(dir) == dir {
+			break
+		}
+		missing = append([]string{dir}, missing...)
+	}
+	return missing
+}
+
+// ValidatePath resolves requestedPath and checks that it lies within the
+// allowed directories, for tools added outside this package
+func (fs *FilesystemHandler) ValidatePath(requestedPath string) (string, error) {
+	return fs.validatePath(requestedPath)
+}
+
+// ValidateWritablePath is ValidatePath for paths a tool is about to modify
+func (fs *FilesystemHandler) ValidateWritablePath(requestedPath string) (string, error) {
+	return fs.validateWritablePath(requestedPath)
+}
+
+// readOnlyMode reports whether the whole server is read-only
+func (fs *FilesystemHandler) readOnlyMode() bool {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	return fs.readOnly
+}
+
+// isReadOnly reports whether path lies within a read-only directory
+func (fs *FilesystemHandler) isReadOnly(path string) bool {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	withSep := withTrailingSeparator(path)
+	for _, dir := range fs.readOnlyDirs {
+		if strings.HasPrefix(withSep, dir) {
+			return true
+		}
+	}
+	return false
+}
+
 // detectMimeType tries to determine the MIME type of a file
 func detectMimeType(path string) string {
 	// Use mimetype library for more accurate detection
@@ -104,6 +208,18 @@ func detectMimeType(path string) string {
 	return mtype.String()
 }
 
+// detectMimeTypeFromContent determines the MIME type of in-memory content,
+// falling back to the file extension when no content is available
+func detectMimeTypeFromContent(name string, content []byte) string {
+	if content != nil {
+		return mimetype.Detect(content).String()
+	}
+	if mimeType := mime.TypeByExtension(filepath.Ext(name)); mimeType != "" {
+		return mimeType
+	}
+	return "application/octet-stream"
+}
+
 // isTextFile determines if a file is likely a text file based on MIME type
 func isTextFile(mimeType string) bool {
 	// Check for common text MIME types
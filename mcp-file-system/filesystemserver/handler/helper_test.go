@@ -1,9 +1,14 @@
 package handler
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRoot(t *testing.T) {
@@ -11,3 +16,49 @@ func TestRoot(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, handler.isPathInAllowedDirs("/etc/hostname"))
 }
+
+func TestReadOnlyDirs(t *testing.T) {
+	roDir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	rwDir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(roDir, "a.txt"), []byte("data"), 0644))
+
+	fsHandler, err := NewFilesystemHandler([]string{roDir, rwDir}, WithReadOnlyDirs([]string{roDir}))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"path": filepath.Join(roDir, "a.txt")}
+	res, err := fsHandler.HandleReadFile(ctx, req)
+	require.NoError(t, err)
+	assert.False(t, res.IsError)
+
+	req.Params.Arguments = map[string]any{"path": filepath.Join(roDir, "b.txt"), "content": "x"}
+	res, err = fsHandler.HandleWriteFile(ctx, req)
+	require.NoError(t, err)
+	require.True(t, res.IsError)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "read-only")
+
+	// Copying out of a read-only directory is fine, moving is not
+	req.Params.Arguments = map[string]any{"source": filepath.Join(roDir, "a.txt"), "destination": filepath.Join(rwDir, "a.txt")}
+	res, err = fsHandler.HandleCopyFile(ctx, req)
+	require.NoError(t, err)
+	assert.False(t, res.IsError)
+	res, err = fsHandler.HandleMoveFile(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, res.IsError)
+	assert.FileExists(t, filepath.Join(roDir, "a.txt"))
+}
+
+func TestReadOnlyMode(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	fsHandler, err := NewFilesystemHandler([]string{dir}, WithReadOnly(true))
+	require.NoError(t, err)
+
+	_, err = fsHandler.validatePath(filepath.Join(dir, "new.txt"))
+	assert.NoError(t, err)
+	_, err = fsHandler.validateWritablePath(filepath.Join(dir, "new.txt"))
+	assert.ErrorContains(t, err, "read-only mode")
+}
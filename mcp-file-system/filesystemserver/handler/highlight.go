@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+const (
+	// DefaultHighlightStyle is the chroma style used for HTML highlighting
+	DefaultHighlightStyle = "github"
+	// MAX_HIGHLIGHT_SIZE bounds the amount of text that will be highlighted (512KB)
+	MAX_HIGHLIGHT_SIZE = 512 * 1024
+)
+
+// HighlightToken is a single lexed token returned by the "tokens" highlight mode
+type HighlightToken struct {
+	Line  int    `json:"line"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// HighlightResult is the payload returned by the "tokens" highlight mode
+type HighlightResult struct {
+	Language  string           `json:"language"`
+	StartLine int              `json:"start_line"`
+	Tokens    []HighlightToken `json:"tokens"`
+}
+
+// highlightCode annotates content for syntax highlighting. mode is "html"
+// (inline-styled HTML fragment) or "tokens" (JSON token metadata); baseLine is
+// the line number of the first line of content.
+func highlightCode(path, content, mode, style string, baseLine int) (mimeType string, output string, err error) {
+	if len(content) > MAX_HIGHLIGHT_SIZE {
+		return "", "", toolError(CodeTooLarge, "content too large to highlight (%d bytes, max %d)", len(content), MAX_HIGHLIGHT_SIZE)
+	}
+	if baseLine < 1 {
+		baseLine = 1
+	}
+
+	lexer := lexers.Match(filepath.Base(path))
+	if lexer == nil {
+		lexer = lexers.Analyse(content)
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	lexer = chroma.Coalesce(lexer)
+
+	iterator, err := lexer.Tokenise(nil, content)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to tokenise: %w", err)
+	}
+
+	switch mode {
+	case "html":
+		if style == "" {
+			style = DefaultHighlightStyle
+		}
+		formatter := html.New(
+			html.WithClasses(false),
+			html.WithLineNumbers(true),
+			html.BaseLineNumber(baseLine),
+		)
+		var sb strings.Builder
+		if err := formatter.Format(&sb, styles.Get(style), iterator); err != nil {
+			return "", "", fmt.Errorf("failed to format: %w", err)
+		}
+		return "text/html", sb.String(), nil
+
+	case "tokens":
+		result := HighlightResult{
+			Language:  lexer.Config().Name,
+			StartLine: baseLine,
+		}
+		line := baseLine
+		for _, token := range iterator.Tokens() {
+			// Split multi-line tokens so every token maps to exactly one line
+			parts := strings.SplitAfter(token.Value, "\n")
+			for _, part := range parts {
+				if part == "" {
+					continue
+				}
+				result.Tokens = append(result.Tokens, HighlightToken{
+					Line:  line,
+					Type:  token.Type.String(),
+					Value: part,
+				})
+				if strings.HasSuffix(part, "\n") {
+					line++
+				}
+			}
+		}
+		jsonBytes, err := json.Marshal(result)
+		if err != nil {
+			return "", "", err
+		}
+		return "application/json", string(jsonBytes), nil
+
+	default:
+		return "", "", fmt.Errorf("unsupported highlight mode %q (use 'html' or 'tokens')", mode)
+	}
+}
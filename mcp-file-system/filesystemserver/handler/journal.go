@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Journal defaults
+const (
+	defaultJournalMaxSize  = 10 * 1024 * 1024 // 10MB
+	defaultJournalMaxFiles = 5
+	// maxJournalEntrySize bounds a single encoded entry
+	maxJournalEntrySize = 64 * 1024
+	// defaultJournalTail is how many entries journal_read returns by default
+	defaultJournalTail = 50
+	// defaultJournal names the journal of calls made outside a session
+	defaultJournal = "default"
+	// journalBase is the base name of a journal's files
+	journalBase = "journal"
+)
+
+// journalNamePattern matches journal names, which become directory names
+var journalNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// JournalEntry is one line of a journal
+type JournalEntry struct {
+	Time    time.Time `json:"time"`
+	Session string    `json:"session,omitempty"`
+	// Kind classifies the entry, such as note, progress or decision
+	Kind    string         `json:"kind"`
+	Message string         `json:"message"`
+	Data    map[string]any `json:"data,omitempty"`
+}
+
+// JournalResult is the JSON result of journal_read
+type JournalResult struct {
+	Journal string         `json:"journal"`
+	Entries []JournalEntry `json:"entries"`
+}
+
+// journalName returns the journal a call addresses: the one named by its
+// journal argument, or else the one of its session
+func journalName(ctx context.Context, request mcp.CallToolRequest) (string, error) {
+	name := request.GetString("journal", "")
+	if name == "" {
+		if name = sessionID(ctx); name == "" {
+			name = defaultJournal
+		}
+	}
+	if !journalNamePattern.MatchString(name) {
+		return "", toolError(CodeInvalidArgument, "Error: journal names may only contain letters, digits, '.', '_' and '-'").With("journal", name)
+	}
+	return name, nil
+}
+
+// journal returns the writer of the named journal. Journals live in the
+// state directory, outside the allowed directories, so no other tool can
+// overwrite them, and tenants, whose state directories differ, cannot
+// reach each other's.
+func (fs *FilesystemHandler) journal(name string) *operationLog {
+	journal, _ := fs.journals.LoadOrStore(name, &operationLog{
+		cfg: OperationLogConfig{
+			Dir:      filepath.Join(fs.stateDir, "journals", name),
+			MaxSize:  defaultJournalMaxSize,
+			MaxFiles: defaultJournalMaxFiles,
+		},
+		base: journalBase,
+	})
+	return journal.(*operationLog)
+}
+
+// closeJournals closes the open journal files
+func (fs *FilesystemHandler) closeJournals() {
+	fs.journals.Range(func(_, journal any) bool {
+		journal.(*operationLog).close()
+		return true
+	})
+}
+
+// HandleJournalAppend handles the journal_append tool
+func (fs *FilesystemHandler) HandleJournalAppend(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	message, err := request.RequireString("message")
+	if err != nil || message == "" {
+		return errorResult(toolError(CodeInvalidArgument, "Error: message is required")), nil
+	}
+	name, err := journalName(ctx, request)
+	if err != nil {
+		return errorResult(err), nil
+	}
+	entry := JournalEntry{
+		Time:    time.Now().UTC(),
+		Session: sessionID(ctx),
+		Kind:    request.GetString("kind", "note"),
+		Message: message,
+	}
+	if data, ok := request.GetArguments()["data"]; ok && data != nil {
+		if entry.Data, ok = data.(map[string]any); !ok {
+			return errorResult(toolError(CodeInvalidArgument, "Error: data must be an object")), nil
+		}
+	}
+	if encoded, err := json.Marshal(entry); err != nil {
+		return errorResult(toolError(CodeInvalidArgument, "Error: %w", err)), nil
+	} else if len(encoded) > maxJournalEntrySize {
+		return errorResult(toolError(CodeTooLarge, "Error: journal entries are limited to %s", formatFileSize(maxJournalEntrySize)).With("limit", maxJournalEntrySize)), nil
+	}
+
+	if err := fs.journal(name).write(entry); err != nil {
+		return errorResult(fmt.Errorf("Error writing journal %s: %w", name, err)), nil
+	}
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, entry)
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Appended a %s entry to journal %s at %s", entry.Kind, name, formatTime(entry.Time))), nil
+}
+
+// HandleJournalRead handles the journal_read tool
+func (fs *FilesystemHandler) HandleJournalRead(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	name, err := journalName(ctx, request)
+	if err != nil {
+		return errorResult(err), nil
+	}
+	tail := request.GetInt("tail", defaultJournalTail)
+	if limit := fs.currentLimits().MaxSearchResults; tail < 1 || tail > limit {
+		return errorResult(toolError(CodeInvalidArgument, "Error: tail must be between 1 and %d", limit).With("tail", tail)), nil
+	}
+	kind := request.GetString("kind", "")
+
+	entries, err := fs.journal(name).tail(ctx, tail, kind)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error reading journal %s: %w", name, err)), nil
+	}
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, JournalResult{Journal: name, Entries: entries})
+	}
+	if len(entries) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("Journal %s has no entries", name)), nil
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Last %d entries of journal %s:\n", len(entries), name))
+	for _, entry := range entries {
+		sb.WriteString(fmt.Sprintf("%s [%s] %s", formatTime(entry.Time), entry.Kind, entry.Message))
+		if len(entry.Data) > 0 {
+			data, _ := json.Marshal(entry.Data)
+			sb.WriteString(" " + string(data))
+		}
+		sb.WriteString("\n")
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// tail returns the last n entries of a journal, oldest first, reading the
+// rotated files as far back as needed. With kind, other entries are skipped.
+func (l *operationLog) tail(ctx context.Context, n int, kind string) ([]JournalEntry, error) {
+	files, err := l.rotatedFiles()
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, filepath.Join(l.cfg.Dir, l.baseName()+".jsonl"))
+
+	var entries []JournalEntry
+	for i := len(files) - 1; i >= 0 && len(entries) < n; i-- {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		found, err := readJournalFile(files[i], kind)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(found, entries...)
+	}
+	if len(entries) > n {
+		entries = entries[len(entries)-n:]
+	}
+	if entries == nil {
+		entries = []JournalEntry{}
+	}
+	return entries, nil
+}
+
+// readJournalFile returns the entries in one journal file. A missing file
+// has none, and lines that do not parse are skipped.
+func readJournalFile(path, kind string) ([]JournalEntry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 2*maxJournalEntrySize)
+	for scanner.Scan() {
+		var entry JournalEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil {
+			continue
+		}
+		if kind == "" || entry.Kind == kind {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
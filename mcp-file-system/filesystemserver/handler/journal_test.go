@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournal(t *testing.T) {
+	stateDir := t.TempDir()
+	fsHandler, err := NewFilesystemHandler([]string{t.TempDir()}, WithStateDir(stateDir))
+	require.NoError(t, err)
+	defer fsHandler.closeJournals()
+
+	res := callTool(t, fsHandler.HandleJournalAppend, map[string]any{"message": "started import"})
+	require.False(t, res.IsError, res.Content)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Appended a note entry to journal default")
+	res = callTool(t, fsHandler.HandleJournalAppend, map[string]any{
+		"message": "imported batch",
+		"kind":    "progress",
+		"data":    map[string]any{"batch": 3},
+	})
+	require.False(t, res.IsError, res.Content)
+	assert.FileExists(t, filepath.Join(stateDir, "journals", "default", "journal.jsonl"))
+
+	res = callTool(t, fsHandler.FormatToolCall(fsHandler.HandleJournalRead), map[string]any{"format": FormatJSON})
+	require.False(t, res.IsError, res.Content)
+	var journal JournalResult
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &journal))
+	assert.Equal(t, "default", journal.Journal)
+	require.Len(t, journal.Entries, 2)
+	assert.Equal(t, "started import", journal.Entries[0].Message)
+	assert.Equal(t, "progress", journal.Entries[1].Kind)
+	assert.Equal(t, float64(3), journal.Entries[1].Data["batch"])
+
+	res = callTool(t, fsHandler.HandleJournalRead, map[string]any{"kind": "progress"})
+	require.False(t, res.IsError, res.Content)
+	text := res.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, `[progress] imported batch {"batch":3}`)
+	assert.NotContains(t, text, "started import")
+
+	res = callTool(t, fsHandler.HandleJournalRead, map[string]any{"journal": "other"})
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Journal other has no entries")
+	res = callTool(t, fsHandler.HandleJournalAppend, map[string]any{"message": "x", "journal": "../escape"})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	res = callTool(t, fsHandler.HandleJournalAppend, map[string]any{"message": "x", "data": "not an object"})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+}
+
+func TestJournalRotation(t *testing.T) {
+	dir := t.TempDir()
+	journal := &operationLog{cfg: OperationLogConfig{Dir: dir, MaxSize: 150, MaxFiles: 2}, base: journalBase}
+	defer journal.close()
+	for _, message := range []string{"one", "two", "three", "four", "five"} {
+		require.NoError(t, journal.write(JournalEntry{Kind: "note", Message: message}))
+	}
+	rotated, err := journal.rotatedFiles()
+	require.NoError(t, err)
+	assert.Len(t, rotated, 2)
+
+	// The tail spans the current and rotated files
+	entries, err := journal.tail(context.Background(), 3, "")
+	require.NoError(t, err)
+	var messages []string
+	for _, entry := range entries {
+		messages = append(messages, entry.Message)
+	}
+	assert.Equal(t, []string{"three", "four", "five"}, messages)
+}
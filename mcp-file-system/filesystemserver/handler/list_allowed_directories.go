@@ -2,29 +2,86 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
+// Health of an allowed directory reported by list_allowed_directories
+const (
+	DirStatusOK           = "ok"
+	DirStatusMissing      = "missing"
+	DirStatusNotDirectory = "not_a_directory"
+	DirStatusUnreadable   = "unreadable"
+)
+
 func (fs *FilesystemHandler) HandleListAllowedDirectories(
 	ctx context.Context,
 	request mcp.CallToolRequest,
 ) (*mcp.CallToolResult, error) {
 	// Remove the trailing separator for display purposes
-	displayDirs := make([]string, len(fs.allowedDirs))
-	for i, dir := range fs.allowedDirs {
+	displayDirs := make([]string, len(fs.dirs()))
+	for i, dir := range fs.dirs() {
 		displayDirs[i] = strings.TrimSuffix(dir, string(filepath.Separator))
 	}
 
+	aliases := fs.currentAliases()
+	shares := fs.shares()
+	dirs := AllowedDirectories{
+		Directories:   make([]AllowedDirectory, 0, len(displayDirs)+len(shares)),
+		BaseDirectory: fs.baseDirectory(ctx),
+	}
+	if len(aliases) > 0 {
+		dirs.Aliases = make(map[string]string, len(aliases))
+		for name, dir := range aliases {
+			dirs.Aliases[aliasPrefix+name] = dir
+		}
+	}
+	for _, dir := range displayDirs {
+		dirs.Directories = append(dirs.Directories, fs.describeAllowedDirectory(dir, aliases))
+	}
+	for _, r := range shares {
+		dirs.Directories = append(dirs.Directories, AllowedDirectory{Path: r.root, Type: "smb", Access: fs.accessLevel(r.root)})
+	}
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, dirs)
+	}
+
 	var result strings.Builder
 	result.WriteString("Allowed directories:\n\n")
 
-	for _, dir := range displayDirs {
-		resourceURI := pathToResourceURI(dir)
-		result.WriteString(fmt.Sprintf("%s (%s)\n", dir, resourceURI))
+	for _, dir := range dirs.Directories {
+		if dir.Type == "smb" {
+			result.WriteString(fmt.Sprintf("%s (SMB share) [%s]\n", dir.Path, dir.Access))
+			continue
+		}
+		notes := []string{dir.Access, dir.Status}
+		if dir.Error != "" {
+			notes[1] += ": " + dir.Error
+		}
+		if dir.Total > 0 {
+			notes = append(notes, fmt.Sprintf("%s free of %s", formatFileSize(int64(dir.Available)), formatFileSize(int64(dir.Total))))
+		}
+		if len(dir.Aliases) > 0 {
+			notes = append(notes, "aliases "+strings.Join(dir.Aliases, ", "))
+		}
+		result.WriteString(fmt.Sprintf("%s (%s) [%s]\n", dir.Path, dir.URI, strings.Join(notes, "; ")))
+	}
+	if len(aliases) > 0 {
+		result.WriteString("\nAliases:\n\n")
+		for _, name := range slices.Sorted(maps.Keys(aliases)) {
+			result.WriteString(fmt.Sprintf("%s%s -> %s\n", aliasPrefix, name, aliases[name]))
+		}
+	}
+	if base := fs.baseDirectory(ctx); base != "" {
+		result.WriteString(fmt.Sprintf("\nRelative paths resolve against %s\n", base))
 	}
 
 	return &mcp.CallToolResult{
@@ -35,4 +92,49 @@ func (fs *FilesystemHandler) HandleListAllowedDirectories(
 			},
 		},
 	}, nil
-}
\ No newline at end of file
+}
+
+// accessLevel returns "ro" when the server or a read-only directory keeps
+// tools from modifying dir, and "rw" otherwise
+func (fs *FilesystemHandler) accessLevel(dir string) string {
+	if fs.readOnlyMode() || fs.isReadOnly(dir) {
+		return "ro"
+	}
+	return "rw"
+}
+
+// describeAllowedDirectory checks the local allowed directory dir and
+// collects its access level, free space and aliases
+func (fs *FilesystemHandler) describeAllowedDirectory(dir string, aliases map[string]string) AllowedDirectory {
+	entry := AllowedDirectory{Path: dir, Type: "local", URI: pathToResourceURI(dir), Access: fs.accessLevel(dir), Status: DirStatusOK}
+	for _, name := range slices.Sorted(maps.Keys(aliases)) {
+		if target := aliases[name]; target == dir || strings.HasPrefix(target, withTrailingSeparator(dir)) {
+			entry.Aliases = append(entry.Aliases, aliasPrefix+name)
+		}
+	}
+
+	info, err := os.Stat(dir)
+	switch {
+	case os.IsNotExist(err):
+		entry.Status = DirStatusMissing
+		return entry
+	case err != nil:
+		entry.Status, entry.Error = DirStatusUnreadable, err.Error()
+		return entry
+	case !info.IsDir():
+		entry.Status = DirStatusNotDirectory
+		return entry
+	}
+	if f, err := os.Open(dir); err != nil {
+		entry.Status, entry.Error = DirStatusUnreadable, err.Error()
+	} else {
+		if _, err := f.Readdirnames(1); err != nil && !errors.Is(err, io.EOF) {
+			entry.Status, entry.Error = DirStatusUnreadable, err.Error()
+		}
+		f.Close()
+	}
+	if total, _, avail, err := volumeUsage(dir); err == nil {
+		entry.Total, entry.Available = total, avail
+	}
+	return entry
+}
@@ -2,6 +2,9 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -64,4 +67,29 @@ func TestHandleListAllowedDirectories(t *testing.T) {
 		assert.Contains(t, textContent.Text, singleDir)
 		assert.Contains(t, textContent.Text, "file://")
 	})
+
+	t.Run("access and health", func(t *testing.T) {
+		root := resolveAllowedDirs(t, t.TempDir())[0]
+		refs := filepath.Join(root, "refs")
+		gone := filepath.Join(root, "gone")
+		require.NoError(t, os.Mkdir(refs, 0755))
+		require.NoError(t, os.Mkdir(gone, 0755))
+		h, err := NewFilesystemHandler([]string{refs, gone}, WithReadOnlyDirs([]string{refs}), WithAliases(map[string]string{"docs": refs}))
+		require.NoError(t, err)
+		require.NoError(t, os.Remove(gone))
+
+		res := callTool(t, h.FormatToolCall(h.HandleListAllowedDirectories), map[string]any{"format": FormatJSON})
+		var dirs AllowedDirectories
+		require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &dirs))
+		require.Len(t, dirs.Directories, 2)
+		assert.Equal(t, "ro", dirs.Directories[0].Access)
+		assert.Equal(t, DirStatusOK, dirs.Directories[0].Status)
+		assert.Equal(t, []string{"@docs"}, dirs.Directories[0].Aliases)
+		assert.Equal(t, "rw", dirs.Directories[1].Access)
+		assert.Equal(t, DirStatusMissing, dirs.Directories[1].Status)
+
+		res = callTool(t, h.HandleListAllowedDirectories, nil)
+		assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "[ro; ok")
+		assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "aliases @docs]")
+	})
 }
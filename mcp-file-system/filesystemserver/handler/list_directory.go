@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -19,95 +20,88 @@ func (fs *FilesystemHandler) HandleListDirectory(
 		return nil, err
 	}
 
+	opts, err := parseListOptions(request)
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	if isSMBPath(path) {
+		return fs.smbListDirectory(ctx, path, opts)
+	}
+
 	// Handle empty or relative paths like "." or "./" by converting to absolute path
 	if path == "." || path == "./" {
 		// Get current working directory
 		cwd, err := os.Getwd()
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					mcp.TextContent{
-						Type: "text",
-						Text: fmt.Sprintf("Error resolving current directory: %v", err),
-					},
-				},
-				IsError: true,
-			}, nil
+			return errorResult(fmt.Errorf("Error resolving current directory: %w", err)), nil
 		}
 		path = cwd
 	}
 
 	validPath, err := fs.validatePath(path)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
 	}
 
 	// Check if it's a directory
 	info, err := os.Stat(validPath)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
 	}
 
 	if !info.IsDir() {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: "Error: Path is not a directory",
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(toolError(CodeWrongType, "Error: Path is not a directory")), nil
 	}
 
-	entries, err := os.ReadDir(validPath)
+	dirEntries, err := os.ReadDir(validPath)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error reading directory: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error reading directory: %w", err)), nil
+	}
+	entries := make([]os.FileInfo, 0, len(dirEntries))
+	for _, entry := range dirEntries {
+		// Entries removed since the directory was read are left out
+		if info, err := entry.Info(); err == nil {
+			entries = append(entries, info)
+		}
+	}
+	page := opts.apply(entries)
+	entries = page.entries
+
+	now := time.Now()
+	if wantsJSON(ctx) {
+		listing := DirectoryListing{Path: validPath, Entries: make([]DirectoryEntry, 0, len(entries)), Total: page.total, NextCursor: page.next}
+		for _, info := range entries {
+			entryPath := filepath.Join(validPath, info.Name())
+			item := DirectoryEntry{
+				Name:      info.Name(),
+				Path:      entryPath,
+				Type:      "directory",
+				Mode:      fmt.Sprintf("%03o", info.Mode().Perm()),
+				URI:       pathToResourceURI(entryPath),
+				FileTimes: localFileTimes(info, now),
+			}
+			if !info.IsDir() {
+				item.Type, item.Size = "file", info.Size()
+			}
+			listing.Entries = append(listing.Entries, item)
+		}
+		return jsonResult(ctx, listing)
 	}
 
 	var result strings.Builder
 	result.WriteString(fmt.Sprintf("Directory listing for: %s\n\n", validPath))
 
-	for _, entry := range entries {
-		entryPath := filepath.Join(validPath, entry.Name())
-		resourceURI := pathToResourceURI(entryPath)
-
-		if entry.IsDir() {
-			result.WriteString(fmt.Sprintf("[DIR]  %s (%s)\n", entry.Name(), resourceURI))
+	for _, info := range entries {
+		resourceURI := pathToResourceURI(filepath.Join(validPath, info.Name()))
+		modified := fmt.Sprintf("modified %s (%s)", formatTime(info.ModTime()), humanizeAge(info.ModTime(), now))
+		if info.IsDir() {
+			result.WriteString(fmt.Sprintf("[DIR]  %s (%s) - %s\n", info.Name(), resourceURI, modified))
 		} else {
-			info, err := entry.Info()
-			if err == nil {
-				result.WriteString(fmt.Sprintf("[FILE] %s (%s) - %d bytes\n",
-					entry.Name(), resourceURI, info.Size()))
-			} else {
-				result.WriteString(fmt.Sprintf("[FILE] %s (%s)\n", entry.Name(), resourceURI))
-			}
+			result.WriteString(fmt.Sprintf("[FILE] %s (%s) - %d bytes, %s\n", info.Name(), resourceURI, info.Size(), modified))
 		}
 	}
+	result.WriteString(page.summary())
 
 	// Return both text content and embedded resource
 	resourceURI := pathToResourceURI(validPath)
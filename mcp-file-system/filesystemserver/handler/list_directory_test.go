@@ -2,9 +2,11 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
@@ -134,3 +136,94 @@ func TestHandleListDirectory(t *testing.T) {
 		require.True(t, res.IsError)
 	})
 }
+
+func TestListDirectoryOptions(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	now := time.Now()
+	for i, name := range []string{"b.log", "a.log", "c.txt", ".hidden.log"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, make([]byte, i+1), 0644))
+		stamp := now.Add(-time.Duration(i) * time.Hour)
+		require.NoError(t, os.Chtimes(path, stamp, stamp))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "z.log"), 0755))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	names := func(args map[string]any) []string {
+		args["path"] = dir
+		args["format"] = FormatJSON
+		res := callTool(t, fsHandler.FormatToolCall(fsHandler.HandleListDirectory), args)
+		require.False(t, res.IsError, res.Content)
+		var listing DirectoryListing
+		require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &listing))
+		var names []string
+		for _, entry := range listing.Entries {
+			names = append(names, entry.Name)
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{".hidden.log", "a.log", "b.log", "c.txt", "z.log"}, names(map[string]any{}))
+	assert.Equal(t, []string{"a.log", "b.log", "c.txt", "z.log"}, names(map[string]any{"show_hidden": false}))
+	assert.Equal(t, []string{"a.log", "b.log", "c.txt", "z.log"}, names(map[string]any{"include_hidden": false}))
+	assert.Equal(t, []string{"z.log", "b.log", "a.log"}, names(map[string]any{"glob": "*.log", "show_hidden": false, "sort_by": SortByMtime, "order": OrderDesc}))
+	assert.Equal(t, []string{"z.log", ".hidden.log", "c.txt"}, names(map[string]any{"dirs_first": true, "sort_by": SortBySize, "order": OrderDesc, "limit": 3}))
+
+	res := callTool(t, fsHandler.HandleListDirectory, map[string]any{"path": dir, "limit": 2})
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Showing 2 of 5 entries")
+
+	res = callTool(t, fsHandler.HandleListDirectory, map[string]any{"path": dir, "sort_by": "owner"})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+}
+
+func TestListDirectoryPages(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0644))
+	}
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	list := func(args map[string]any) DirectoryListing {
+		args["path"] = dir
+		args["format"] = FormatJSON
+		res := callTool(t, fsHandler.FormatToolCall(fsHandler.HandleListDirectory), args)
+		require.False(t, res.IsError, res.Content)
+		var listing DirectoryListing
+		require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &listing))
+		return listing
+	}
+	names := func(listing DirectoryListing) []string {
+		var names []string
+		for _, entry := range listing.Entries {
+			names = append(names, entry.Name)
+		}
+		return names
+	}
+
+	first := list(map[string]any{"page_size": 2})
+	assert.Equal(t, []string{"a", "b"}, names(first))
+	assert.Equal(t, 5, first.Total)
+	require.NotEmpty(t, first.NextCursor)
+
+	// Removing a listed entry and adding one ahead neither skips nor repeats
+	require.NoError(t, os.Remove(filepath.Join(dir, "a")))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bb"), nil, 0644))
+	second := list(map[string]any{"cursor": first.NextCursor})
+	assert.Equal(t, []string{"bb", "c"}, names(second))
+
+	last := list(map[string]any{"cursor": second.NextCursor})
+	assert.Equal(t, []string{"d", "e"}, names(last))
+	assert.Empty(t, last.NextCursor)
+
+	res := callTool(t, fsHandler.HandleListDirectory, map[string]any{"path": dir, "page_size": 2, "cursor": first.NextCursor})
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Showing entries 2-3 of 5. To continue, pass cursor: ")
+
+	res = callTool(t, fsHandler.HandleListDirectory, map[string]any{"path": dir, "cursor": first.NextCursor, "order": OrderDesc})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	res = callTool(t, fsHandler.HandleListDirectory, map[string]any{"path": dir, "cursor": "not-a-cursor"})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	res = callTool(t, fsHandler.HandleListDirectory, map[string]any{"path": dir, "page_size": 2, "limit": 3})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+}
@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Sort keys accepted by list_directory's sort_by argument
+const (
+	SortByName  = "name"
+	SortBySize  = "size"
+	SortByMtime = "mtime"
+)
+
+// Sort directions accepted by list_directory's order argument
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+// maxListPageSize is the largest page list_directory returns
+const maxListPageSize = 5000
+
+// listOptions select and order the entries list_directory returns
+type listOptions struct {
+	sortBy     string
+	desc       bool
+	dirsFirst  bool
+	showHidden bool
+	pattern    string
+	match      glob.Glob
+	limit      int
+	// pageSize splits the listing into pages, and after is the last entry
+	// of the previous page when a cursor was given
+	pageSize int
+	after    *listKey
+}
+
+// listKey is what entries are ordered by
+type listKey struct {
+	Name  string `json:"n"`
+	Size  int64  `json:"s"`
+	Mtime int64  `json:"m"`
+	Dir   bool   `json:"d"`
+}
+
+// listCursor is the content of a list_directory cursor. Query ties it to
+// the sorting and filtering it was made with.
+type listCursor struct {
+	After    listKey `json:"a"`
+	Query    string  `json:"q"`
+	PageSize int     `json:"p"`
+}
+
+// listPage is the part of a listing one call returns
+type listPage struct {
+	entries []os.FileInfo
+	// total counts the entries that matched, and offset those before the page
+	total, offset int
+	// next continues the listing after this page, if anything is left
+	next string
+}
+
+// parseListOptions reads the sorting and filtering arguments of
+// list_directory. Without them entries are listed by name, hidden ones
+// included, all in one page.
+func parseListOptions(request mcp.CallToolRequest) (listOptions, error) {
+	opts := listOptions{
+		sortBy:    request.GetString("sort_by", SortByName),
+		dirsFirst: request.GetBool("dirs_first", false),
+		// include_hidden is accepted as another name for show_hidden
+		showHidden: request.GetBool("show_hidden", request.GetBool("include_hidden", true)),
+	}
+	switch opts.sortBy {
+	case SortByName, SortBySize, SortByMtime:
+	default:
+		return opts, toolError(CodeInvalidArgument, "Error: sort_by must be %q, %q or %q", SortByName, SortBySize, SortByMtime)
+	}
+	switch order := request.GetString("order", OrderAsc); order {
+	case OrderAsc, OrderDesc:
+		opts.desc = order == OrderDesc
+	default:
+		return opts, toolError(CodeInvalidArgument, "Error: order must be %q or %q", OrderAsc, OrderDesc)
+	}
+	if pattern := request.GetString("glob", ""); pattern != "" {
+		match, err := glob.Compile(pattern)
+		if err != nil {
+			return opts, toolError(CodeInvalidArgument, "Error: invalid glob %q: %w", pattern, err)
+		}
+		opts.pattern, opts.match = pattern, match
+	}
+	if limit, err := request.RequireFloat("limit"); err == nil {
+		if limit < 1 {
+			return opts, toolError(CodeInvalidArgument, "Error: limit must be positive")
+		}
+		opts.limit = int(limit)
+	}
+	if pageSize, err := request.RequireFloat("page_size"); err == nil {
+		if pageSize < 1 || pageSize > maxListPageSize {
+			return opts, toolError(CodeInvalidArgument, "Error: page_size must be between 1 and %d", maxListPageSize)
+		}
+		opts.pageSize = int(pageSize)
+	}
+	if cursor := request.GetString("cursor", ""); cursor != "" {
+		if err := opts.resume(cursor); err != nil {
+			return opts, err
+		}
+	}
+	if opts.limit > 0 && opts.pageSize > 0 {
+		return opts, toolError(CodeInvalidArgument, "Error: limit cannot be combined with page_size or cursor")
+	}
+	return opts, nil
+}
+
+// query identifies the sorting and filtering a cursor belongs to
+func (o listOptions) query() string {
+	return fmt.Sprintf("%s|%t|%t|%t|%s", o.sortBy, o.desc, o.dirsFirst, o.showHidden, o.pattern)
+}
+
+// resume continues the listing a cursor was returned for. The page size
+// carries over unless page_size is given again.
+func (o *listOptions) resume(cursor string) error {
+	invalid := toolError(CodeInvalidArgument, "Error: invalid cursor; start again without one")
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return invalid
+	}
+	var c listCursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.PageSize < 1 {
+		return invalid
+	}
+	if c.Query != o.query() {
+		return toolError(CodeInvalidArgument, "Error: the cursor was made with different sort_by, order, dirs_first, show_hidden or glob arguments")
+	}
+	if o.pageSize == 0 {
+		o.pageSize = min(c.PageSize, maxListPageSize)
+	}
+	o.after = &c.After
+	return nil
+}
+
+// cursorAfter encodes a cursor continuing after key
+func (o listOptions) cursorAfter(key listKey) string {
+	raw, _ := json.Marshal(listCursor{After: key, Query: o.query(), PageSize: o.pageSize})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func keyOf(entry os.FileInfo) listKey {
+	return listKey{Name: entry.Name(), Size: entry.Size(), Mtime: entry.ModTime().UnixNano(), Dir: entry.IsDir()}
+}
+
+// less reports whether a is listed before b
+func (o listOptions) less(a, b listKey) bool {
+	if o.dirsFirst && a.Dir != b.Dir {
+		return a.Dir
+	}
+	var less, greater bool
+	switch o.sortBy {
+	case SortBySize:
+		less, greater = a.Size < b.Size, a.Size > b.Size
+	case SortByMtime:
+		less, greater = a.Mtime < b.Mtime, a.Mtime > b.Mtime
+	}
+	if !less && !greater {
+		// Ties, and sorting by name, fall back to the name
+		less, greater = a.Name < b.Name, a.Name > b.Name
+	}
+	if o.desc {
+		return greater
+	}
+	return less
+}
+
+// apply filters and sorts entries, then cuts them to the limit or the page.
+// A page starts after the cursor's entry rather than at an offset, so
+// entries added or removed meanwhile do not shift it.
+func (o listOptions) apply(entries []os.FileInfo) listPage {
+	kept := entries[:0]
+	for _, entry := range entries {
+		if !o.showHidden && strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if o.match != nil && !o.match.Match(entry.Name()) {
+			continue
+		}
+		kept = append(kept, entry)
+	}
+
+	sort.SliceStable(kept, func(i, j int) bool {
+		return o.less(keyOf(kept[i]), keyOf(kept[j]))
+	})
+
+	page := listPage{entries: kept, total: len(kept)}
+	if o.after != nil {
+		page.offset = sort.Search(len(kept), func(i int) bool {
+			return o.less(*o.after, keyOf(kept[i]))
+		})
+		page.entries = kept[page.offset:]
+	}
+	switch {
+	case o.pageSize > 0 && len(page.entries) > o.pageSize:
+		page.entries = page.entries[:o.pageSize]
+		page.next = o.cursorAfter(keyOf(page.entries[len(page.entries)-1]))
+	case o.limit > 0 && len(page.entries) > o.limit:
+		page.entries = page.entries[:o.limit]
+	}
+	return page
+}
+
+// summary is the line after a text listing that did not show every entry
+func (p listPage) summary() string {
+	switch {
+	case p.next != "":
+		return fmt.Sprintf("\nShowing entries %d-%d of %d. To continue, pass cursor: %s\n", p.offset+1, p.offset+len(p.entries), p.total, p.next)
+	case p.offset > 0 && len(p.entries) == 0:
+		return fmt.Sprintf("\nNo entries are left after the cursor, of %d\n", p.total)
+	case p.offset > 0:
+		return fmt.Sprintf("\nShowing entries %d-%d of %d, the last page\n", p.offset+1, p.offset+len(p.entries), p.total)
+	case len(p.entries) < p.total:
+		return fmt.Sprintf("\nShowing %d of %d entries\n", len(p.entries), p.total)
+	}
+	return ""
+}
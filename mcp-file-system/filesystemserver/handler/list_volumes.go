@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// mountEntry is a mounted filesystem as reported by the platform
+type mountEntry struct {
+	MountPoint string
+	Device     string
+	FSType     string
+	ReadOnly   bool
+}
+
+// VolumeInfo describes a mounted filesystem that hosts or lies within an allowed directory
+type VolumeInfo struct {
+	MountPoint  string   `json:"mount_point"`
+	Device      string   `json:"device,omitempty"`
+	FSType      string   `json:"fs_type"`
+	ReadOnly    bool     `json:"read_only"`
+	Total       uint64   `json:"total_bytes"`
+	Free        uint64   `json:"free_bytes"`
+	Available   uint64   `json:"available_bytes"`
+	UsedPercent float64  `json:"used_percent"`
+	AllowedDirs []string `json:"allowed_dirs"`
+}
+
+// HandleListVolumes handles the list_volumes tool
+func (fs *FilesystemHandler) HandleListVolumes(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	mounts, err := listMounts()
+	if err != nil {
+		return errorResult(fmt.Errorf("Error enumerating volumes: %w", err)), nil
+	}
+
+	volumes := fs.volumesForAllowedDirs(mounts)
+	for i := range volumes {
+		total, free, avail, err := volumeUsage(volumes[i].MountPoint)
+		if err != nil {
+			continue // Leave capacity at zero for volumes we cannot stat
+		}
+		volumes[i].Total, volumes[i].Free, volumes[i].Available = total, free, avail
+		if total > 0 {
+			volumes[i].UsedPercent = float64(total-free) / float64(total) * 100
+		}
+	}
+
+	jsonBytes, err := json.MarshalIndent(volumes, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	var summary strings.Builder
+	summary.WriteString(fmt.Sprintf("Found %d volume(s) backing the allowed directories:\n\n", len(volumes)))
+	for _, v := range volumes {
+		mode := "rw"
+		if v.ReadOnly {
+			mode = "ro"
+		}
+		summary.WriteString(fmt.Sprintf("%s (%s, %s): %s free of %s\n",
+			v.MountPoint, v.FSType, mode, formatFileSize(int64(v.Available)), formatFileSize(int64(v.Total))))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: summary.String(),
+			},
+			mcp.TextContent{
+				Type: "text",
+				Text: string(jsonBytes),
+			},
+		},
+	}, nil
+}
+
+// volumesForAllowedDirs keeps the mounts that host an allowed directory or are
+// mounted somewhere beneath one, recording which allowed directories each serves
+func (fs *FilesystemHandler) volumesForAllowedDirs(mounts []mountEntry) []VolumeInfo {
+	// Later entries shadow earlier ones mounted at the same point
+	byMount := make(map[string]mountEntry, len(mounts))
+	for _, m := range mounts {
+		byMount[filepath.Clean(m.MountPoint)] = m
+	}
+
+	selected := make(map[string]*VolumeInfo)
+	add := func(m mountEntry) *VolumeInfo {
+		key := filepath.Clean(m.MountPoint)
+		if v, ok := selected[key]; ok {
+			return v
+		}
+		v := &VolumeInfo{
+			MountPoint:  key,
+			Device:      m.Device,
+			FSType:      m.FSType,
+			ReadOnly:    m.ReadOnly,
+			AllowedDirs: []string{},
+		}
+		selected[key] = v
+		return v
+	}
+
+	for _, dir := range fs.dirs() {
+		dir = strings.TrimSuffix(dir, string(filepath.Separator))
+		if dir == "" {
+			dir = string(filepath.Separator)
+		}
+
+		// The volume hosting the directory is the mount with the longest matching prefix
+		var host *mountEntry
+		for point, m := range byMount {
+			if pathWithin(dir, point) && (host == nil || len(point) > len(filepath.Clean(host.MountPoint))) {
+				m := m
+				host = &m
+			}
+		}
+		if host != nil {
+			v := add(*host)
+			v.AllowedDirs = append(v.AllowedDirs, dir)
+		}
+
+		// Volumes mounted inside the directory are reachable too
+		for point, m := range byMount {
+			if point != dir && pathWithin(point, dir) {
+				v := add(m)
+				v.AllowedDirs = append(v.AllowedDirs, dir)
+			}
+		}
+	}
+
+	volumes := make([]VolumeInfo, 0, len(selected))
+	for _, v := range selected {
+		sort.Strings(v.AllowedDirs)
+		volumes = append(volumes, *v)
+	}
+	sort.Slice(volumes, func(i, j int) bool {
+		return volumes[i].MountPoint < volumes[j].MountPoint
+	})
+	return volumes
+}
+
+// pathWithin reports whether path is root or lies beneath it
+func pathWithin(path, root string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
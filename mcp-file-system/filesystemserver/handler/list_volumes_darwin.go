@@ -0,0 +1,56 @@
+//go:build darwin
+
+package handler
+
+import (
+	"syscall"
+)
+
+// mntReadOnly is MNT_RDONLY from <sys/mount.h>
+const mntReadOnly = 0x1
+
+// listMounts enumerates mounted filesystems with getfsstat(2)
+func listMounts() ([]mountEntry, error) {
+	n, err := syscall.Getfsstat(nil, 1 /* MNT_WAIT */)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]syscall.Statfs_t, n)
+	n, err = syscall.Getfsstat(buf, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	mounts := make([]mountEntry, 0, n)
+	for _, st := range buf[:n] {
+		mounts = append(mounts, mountEntry{
+			MountPoint: cString(st.Mntonname[:]),
+			Device:     cString(st.Mntfromname[:]),
+			FSType:     cString(st.Fstypename[:]),
+			ReadOnly:   st.Flags&mntReadOnly != 0,
+		})
+	}
+	return mounts, nil
+}
+
+// cString converts a NUL-terminated C char array to a string
+func cString(b []int8) string {
+	out := make([]byte, 0, len(b))
+	for _, c := range b {
+		if c == 0 {
+			break
+		}
+		out = append(out, byte(c))
+	}
+	return string(out)
+}
+
+// volumeUsage returns total, free and available-to-unprivileged bytes for the filesystem at path
+func volumeUsage(path string) (total, free, avail uint64, err error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, 0, 0, err
+	}
+	bsize := uint64(st.Bsize)
+	return st.Blocks * bsize, st.Bfree * bsize, st.Bavail * bsize, nil
+}
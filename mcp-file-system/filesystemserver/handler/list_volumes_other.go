@@ -0,0 +1,18 @@
+//go:build !linux && !darwin && !windows
+
+package handler
+
+import (
+	"errors"
+	"runtime"
+)
+
+// listMounts is not implemented on this platform
+func listMounts() ([]mountEntry, error) {
+	return nil, errors.New("volume enumeration is not supported on " + runtime.GOOS)
+}
+
+// volumeUsage is not implemented on this platform
+func volumeUsage(path string) (total, free, avail uint64, err error) {
+	return 0, 0, 0, errors.New("volume usage is not supported on " + runtime.GOOS)
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVolumesForAllowedDirs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX paths")
+	}
+	handler := &FilesystemHandler{allowedDirs: []string{"/home/user/", "/srv/"}}
+	mounts := []mountEntry{
+		{MountPoint: "/", Device: "/dev/sda1", FSType: "ext4"},
+		{MountPoint: "/home", Device: "/dev/sda2", FSType: "ext4"},
+		{MountPoint: "/home/user/media", Device: "nas:/media", FSType: "nfs", ReadOnly: true},
+		{MountPoint: "/proc", Device: "proc", FSType: "proc"},
+		{MountPoint: "/srvdata", Device: "/dev/sdb1", FSType: "xfs"},
+	}
+
+	volumes := handler.volumesForAllowedDirs(mounts)
+	require.Len(t, volumes, 3)
+
+	assert.Equal(t, "/", volumes[0].MountPoint)
+	assert.Equal(t, []string{"/srv"}, volumes[0].AllowedDirs)
+	assert.Equal(t, "/home", volumes[1].MountPoint)
+	assert.Equal(t, []string{"/home/user"}, volumes[1].AllowedDirs)
+	assert.Equal(t, "/home/user/media", volumes[2].MountPoint)
+	assert.True(t, volumes[2].ReadOnly)
+	assert.Equal(t, []string{"/home/user"}, volumes[2].AllowedDirs)
+}
+
+func TestHandleListVolumes(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" && runtime.GOOS != "windows" {
+		t.Skip("volume enumeration not supported")
+	}
+	dir := t.TempDir()
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	result, err := handler.HandleListVolumes(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	require.Len(t, result.Content, 2)
+
+	var volumes []VolumeInfo
+	require.NoError(t, json.Unmarshal([]byte(result.Content[1].(mcp.TextContent).Text), &volumes))
+	require.NotEmpty(t, volumes)
+
+	resolved, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+	found := false
+	for _, v := range volumes {
+		for _, d := range v.AllowedDirs {
+			if d == resolved {
+				found = true
+				assert.NotZero(t, v.Total)
+			}
+		}
+	}
+	assert.True(t, found, "temp dir should be served by a volume")
+}
@@ -0,0 +1,72 @@
+//go:build windows
+
+package handler
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// listMounts enumerates drive letters with their filesystem and read-only flag
+func listMounts() ([]mountEntry, error) {
+	buf := make([]uint16, 254)
+	n, err := windows.GetLogicalDriveStrings(uint32(len(buf)), &buf[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var mounts []mountEntry
+	start := 0
+	for i := 0; i < int(n); i++ {
+		if buf[i] != 0 {
+			continue
+		}
+		if i > start {
+			root := windows.UTF16ToString(buf[start:i])
+			mounts = append(mounts, windowsVolume(root))
+		}
+		start = i + 1
+	}
+	return mounts, nil
+}
+
+// windowsVolume describes the drive rooted at root, e.g. "C:\"
+func windowsVolume(root string) mountEntry {
+	entry := mountEntry{MountPoint: root}
+	rootPtr, err := windows.UTF16PtrFromString(root)
+	if err != nil {
+		return entry
+	}
+
+	switch windows.GetDriveType(rootPtr) {
+	case windows.DRIVE_REMOTE:
+		entry.Device = "network"
+	case windows.DRIVE_REMOVABLE:
+		entry.Device = "removable"
+	case windows.DRIVE_CDROM:
+		entry.Device = "cdrom"
+	case windows.DRIVE_RAMDISK:
+		entry.Device = "ramdisk"
+	default:
+		entry.Device = "fixed"
+	}
+
+	var flags uint32
+	fsName := make([]uint16, windows.MAX_PATH+1)
+	if err := windows.GetVolumeInformation(rootPtr, nil, 0, nil, nil, &flags, &fsName[0], uint32(len(fsName))); err == nil {
+		entry.FSType = windows.UTF16ToString(fsName)
+		entry.ReadOnly = flags&windows.FILE_READ_ONLY_VOLUME != 0
+	}
+	return entry
+}
+
+// volumeUsage returns total, free and available-to-caller bytes for the volume at path
+func volumeUsage(path string) (total, free, avail uint64, err error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if err := windows.GetDiskFreeSpaceEx(pathPtr, &avail, &total, &free); err != nil {
+		return 0, 0, 0, err
+	}
+	return total, free, avail, nil
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// logPathArguments are the tool arguments recorded with every tool call
+var logPathArguments = []string{"path", "source", "destination", "output_dir", "output_path", "remote"}
+
+// maxLoggedErrorLength truncates tool error messages in log records
+const maxLoggedErrorLength = 500
+
+// WithLogger sets the structured logger used by the handler (default: slog.Default())
+func WithLogger(logger *slog.Logger) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		fs.logger = logger
+	}
+}
+
+// ToolHandlerFunc is the signature shared by all Handle* tool methods
+type ToolHandlerFunc func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+
+// LogToolCall wraps a tool handler so every call is logged with the tool
+// name, its path arguments, the duration and the error, if any. Successful
+// calls are logged at info level, tool errors at warn and failures at error.
+func (fs *FilesystemHandler) LogToolCall(tool string, next ToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := next(ctx, request)
+
+		attrs := []slog.Attr{slog.String("tool", tool)}
+		args := request.GetArguments()
+		for _, key := range logPathArguments {
+			if v, ok := args[key].(string); ok && v != "" {
+				attrs = append(attrs, slog.String(key, v))
+			}
+		}
+		attrs = append(attrs, slog.Duration("duration", time.Since(start)))
+
+		level := slog.LevelInfo
+		switch {
+		case err != nil:
+			level = slog.LevelError
+			attrs = append(attrs, slog.String("error", err.Error()))
+		case result != nil && result.IsError:
+			level = slog.LevelWarn
+			attrs = append(attrs, slog.String("error", toolErrorText(result)), slog.String("code", ResultError(result).Code))
+		}
+		fs.logger.LogAttrs(ctx, level, "tool call", attrs...)
+		return result, err
+	}
+}
+
+// toolErrorText extracts the message of an error result for logging
+func toolErrorText(result *mcp.CallToolResult) string {
+	for _, c := range result.Content {
+		if text, ok := c.(mcp.TextContent); ok {
+			if len(text.Text) > maxLoggedErrorLength {
+				return text.Text[:maxLoggedErrorLength] + "..."
+			}
+			return text.Text
+		}
+	}
+	return ""
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogToolCall(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	dir := t.TempDir()
+	fsHandler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir), WithLogger(logger))
+	require.NoError(t, err)
+
+	readFile := fsHandler.LogToolCall("read_file", fsHandler.HandleReadFile)
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"path": filepath.Join(dir, "missing.txt")}
+	res, err := readFile(context.Background(), req)
+	require.NoError(t, err)
+	require.True(t, res.IsError)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 1)
+	var record map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "tool call", record["msg"])
+	assert.Equal(t, "read_file", record["tool"])
+	assert.Equal(t, filepath.Join(dir, "missing.txt"), record["path"])
+	assert.Contains(t, record, "duration")
+	assert.NotEmpty(t, record["error"])
+}
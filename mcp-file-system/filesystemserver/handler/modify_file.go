@@ -21,25 +21,14 @@ func (fs *FilesystemHandler) HandleModifyFile(
 		return nil, err
 	}
 
-	find, err := request.RequireString("find")
+	edits, err := modifyEdits(request)
 	if err != nil {
-		return nil, err
-	}
-
-	replace, err := request.RequireString("replace")
-	if err != nil {
-		return nil, err
+		return errorResult(err), nil
 	}
 
-	// Extract optional arguments with defaults
-	allOccurrences := true // Default value
-	if val, err := request.RequireBool("all_occurrences"); err == nil {
-		allOccurrences = val
-	}
-
-	useRegex := false // Default value
-	if val, err := request.RequireBool("regex"); err == nil {
-		useRegex = val
+	dryRun := false
+	if val, err := request.RequireBool("dry_run"); err == nil {
+		dryRun = val
 	}
 
 	// Handle empty or relative paths like "." or "./" by converting to absolute path
@@ -47,165 +36,236 @@ func (fs *FilesystemHandler) HandleModifyFile(
 		// Get current working directory
 		cwd, err := os.Getwd()
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					mcp.TextContent{
-						Type: "text",
-						Text: fmt.Sprintf("Error resolving current directory: %v", err),
-					},
-				},
-				IsError: true,
-			}, nil
+			return errorResult(fmt.Errorf("Error resolving current directory: %w", err)), nil
 		}
 		path = cwd
 	}
 
-	// Validate path is within allowed directories
-	validPath, err := fs.validatePath(path)
+	// Validate path is within allowed directories; a dry run only previews
+	// the change, so the file need not be writable
+	validate := fs.validateWritablePath
+	if dryRun {
+		validate = fs.validatePath
+	}
+	validPath, err := validate(path)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
 	}
 
 	// Check if it's a directory
 	if info, err := os.Stat(validPath); err == nil && info.IsDir() {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: "Error: Cannot modify a directory",
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(toolError(CodeWrongType, "Error: Cannot modify a directory")), nil
 	}
 
 	// Check if file exists
 	if _, err := os.Stat(validPath); os.IsNotExist(err) {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error: File not found: %s", path),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(toolError(CodeNotFound, "Error: File not found: %s", path).With("path", path)), nil
 	}
 
 	// Read file content
 	content, err := os.ReadFile(validPath)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error reading file: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error reading file: %w", err)), nil
 	}
 
 	originalContent := string(content)
-	modifiedContent := ""
+	modifiedContent := originalContent
 	replacementCount := 0
 
-	// Perform the replacement
-	if useRegex {
-		re, err := regexp.Compile(find)
+	// Perform the replacements in memory, so a failing edit leaves the file untouched
+	for i, edit := range edits {
+		var count int
+		modifiedContent, count, err = edit.apply(modifiedContent)
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					mcp.TextContent{
-						Type: "text",
-						Text: fmt.Sprintf("Error: Invalid regular expression: %v", err),
-					},
-				},
-				IsError: true,
-			}, nil
-		}
-
-		if allOccurrences {
-			modifiedContent = re.ReplaceAllString(originalContent, replace)
-			replacementCount = len(re.FindAllString(originalContent, -1))
-		} else {
-			matched := re.FindStringIndex(originalContent)
-			if matched != nil {
-				replacementCount = 1
-				modifiedContent = originalContent[:matched[0]] + replace + originalContent[matched[1]:]
-			} else {
-				modifiedContent = originalContent
-				replacementCount = 0
-			}
+			return errorResult(err), nil
 		}
-	} else {
-		if allOccurrences {
-			replacementCount = strings.Count(originalContent, find)
-			modifiedContent = strings.ReplaceAll(originalContent, find, replace)
-		} else {
-			if index := strings.Index(originalContent, find); index != -1 {
-				replacementCount = 1
-				modifiedContent = originalContent[:index] + replace + originalContent[index+len(find):]
-			} else {
-				modifiedContent = originalContent
-				replacementCount = 0
-			}
+		if count == 0 && len(edits) > 1 {
+			return errorResult(toolError(CodeNotFound, "Error: edit %d found no match for %q; the file was not modified", i+1, edit.find).With("edit", i+1)), nil
 		}
+		replacementCount += count
 	}
 
-	// Write modified content back to file
-	if err := os.WriteFile(validPath, []byte(modifiedContent), 0644); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error writing to file: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+	// Server-wide dry-run mode applies after checking the file is writable
+	if dryRun || fs.dryRunMode() {
+		lines := []string{fmt.Sprintf("Would make %d replacement(s) in %s", replacementCount, validPath)}
+		if diff := unifiedDiff(validPath, validPath, originalContent, modifiedContent); diff != "" {
+			lines = append(lines, "", diff)
+		}
+		return dryRunResult(lines...), nil
 	}
 
-	// Create response
-	resourceURI := pathToResourceURI(validPath)
+	var backup string
+	if fs.wantsBackup(request) {
+		if backup, err = fs.backupFile(validPath); err != nil {
+			return errorResult(err), nil
+		}
+	}
 
-	// Get file info for the response
-	info, err := os.Stat(validPath)
+	// Write modified content back to file
+	written, err := fs.writeContent(validPath, []byte(modifiedContent), request.GetBool("verify", false))
 	if err != nil {
-		// File was written but we couldn't get info
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("File modified successfully. Made %d replacement(s).", replacementCount),
-				},
-			},
-		}, nil
+		return errorResult(fmt.Errorf("Error writing to file: %w", err)), nil
+	}
+	written.Replacements = replacementCount
+	written.Backup = backup
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, written)
 	}
 
+	resourceURI := pathToResourceURI(validPath)
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			mcp.TextContent{
 				Type: "text",
-				Text: fmt.Sprintf("File modified successfully. Made %d replacement(s) in %s (file size: %d bytes)",
-					replacementCount, path, info.Size()),
+				Text: fmt.Sprintf("File modified successfully. Made %d replacement(s) in %s (file size: %d bytes)\n%s",
+					replacementCount, path, written.Size, describeWriteResult(written)),
 			},
 			mcp.EmbeddedResource{
 				Type: "resource",
 				Resource: mcp.TextResourceContents{
 					URI:      resourceURI,
 					MIMEType: "text/plain",
-					Text:     fmt.Sprintf("Modified file: %s (%d bytes)", validPath, info.Size()),
+					Text:     fmt.Sprintf("Modified file: %s (%d bytes)", validPath, written.Size),
 				},
 			},
 		},
 	}, nil
-}
\ No newline at end of file
+}
+
+// modifyEdit is one find/replace operation of modify_file
+type modifyEdit struct {
+	find, replace         string
+	regex, allOccurrences bool
+	// occurrence replaces only the nth match, counting from 1, when set
+	occurrence int
+}
+
+// modifyEdits returns the edits of a modify_file call: the edits array, or
+// the single edit given by find and replace
+func modifyEdits(request mcp.CallToolRequest) ([]modifyEdit, error) {
+	raw, ok := request.GetArguments()["edits"].([]any)
+	if !ok {
+		find, err := request.RequireString("find")
+		if err != nil {
+			return nil, toolError(CodeInvalidArgument, "Error: either find and replace or edits must be given")
+		}
+		replace, err := request.RequireString("replace")
+		if err != nil {
+			return nil, toolError(CodeInvalidArgument, "Error: %w", err)
+		}
+		edit := modifyEdit{
+			find:           find,
+			replace:        replace,
+			regex:          request.GetBool("regex", false),
+			allOccurrences: request.GetBool("all_occurrences", true),
+			occurrence:     request.GetInt("occurrence", 0),
+		}
+		if edit.occurrence < 0 {
+			return nil, toolError(CodeInvalidArgument, "Error: occurrence must be at least 1").With("occurrence", edit.occurrence)
+		}
+		return []modifyEdit{edit}, nil
+	}
+
+	if len(raw) == 0 {
+		return nil, toolError(CodeInvalidArgument, "Error: edits cannot be empty")
+	}
+	edits := make([]modifyEdit, 0, len(raw))
+	for i, item := range raw {
+		args, ok := item.(map[string]any)
+		if !ok {
+			return nil, toolError(CodeInvalidArgument, "Error: edit %d must be an object", i+1)
+		}
+		edit := modifyEdit{allOccurrences: true}
+		edit.find, _ = args["find"].(string)
+		replace, ok := args["replace"].(string)
+		if edit.find == "" || !ok {
+			return nil, toolError(CodeInvalidArgument, "Error: edit %d needs find and replace", i+1)
+		}
+		edit.replace = replace
+		if v, ok := args["regex"].(bool); ok {
+			edit.regex = v
+		}
+		if v, ok := args["all_occurrences"].(bool); ok {
+			edit.allOccurrences = v
+		}
+		if v, ok := args["occurrence"]; ok {
+			var n float64
+			switch v := v.(type) {
+			case float64:
+				n = v
+			case int:
+				n = float64(v)
+			}
+			if n < 1 || n != float64(int(n)) {
+				return nil, toolError(CodeInvalidArgument, "Error: occurrence of edit %d must be a whole number of at least 1", i+1).With("edit", i+1)
+			}
+			edit.occurrence = int(n)
+		}
+		edits = append(edits, edit)
+	}
+	return edits, nil
+}
+
+// apply performs the edit on content and returns the result and the number
+// of replacements made
+func (e modifyEdit) apply(content string) (string, int, error) {
+	if e.occurrence > 0 {
+		return e.applyOccurrence(content)
+	}
+	if e.regex {
+		re, err := regexp.Compile(e.find)
+		if err != nil {
+			return "", 0, toolError(CodeInvalidArgument, "Error: Invalid regular expression: %w", err)
+		}
+		if e.allOccurrences {
+			return re.ReplaceAllString(content, e.replace), len(re.FindAllStringIndex(content, -1)), nil
+		}
+		matched := re.FindStringIndex(content)
+		if matched == nil {
+			return content, 0, nil
+		}
+		return content[:matched[0]] + e.replace + content[matched[1]:], 1, nil
+	}
+	if e.allOccurrences {
+		return strings.ReplaceAll(content, e.find, e.replace), strings.Count(content, e.find), nil
+	}
+	index := strings.Index(content, e.find)
+	if index == -1 {
+		return content, 0, nil
+	}
+	return content[:index] + e.replace + content[index+len(e.find):], 1, nil
+}
+
+// applyOccurrence replaces only the nth match of the edit, failing when
+// there are fewer matches
+func (e modifyEdit) applyOccurrence(content string) (string, int, error) {
+	var matches [][]int
+	var re *regexp.Regexp
+	if e.regex {
+		var err error
+		if re, err = regexp.Compile(e.find); err != nil {
+			return "", 0, toolError(CodeInvalidArgument, "Error: Invalid regular expression: %w", err)
+		}
+		matches = re.FindAllStringSubmatchIndex(content, e.occurrence)
+	} else {
+		for start := 0; len(matches) < e.occurrence; {
+			index := strings.Index(content[start:], e.find)
+			if index == -1 {
+				break
+			}
+			matches = append(matches, []int{start + index, start + index + len(e.find)})
+			start += index + len(e.find)
+		}
+	}
+	if len(matches) < e.occurrence {
+		return "", 0, toolError(CodeNotFound, "Error: found %d match(es) for %q, so there is no occurrence %d", len(matches), e.find, e.occurrence).
+			With("occurrence", e.occurrence).With("matches", len(matches))
+	}
+	match := matches[e.occurrence-1]
+	replacement := e.replace
+	if re != nil {
+		// Expand $1 style references against the chosen match
+		replacement = string(re.ExpandString(nil, e.replace, content, match))
+	}
+	return content[:match[0]] + replacement + content[match[1]:], 1, nil
+}
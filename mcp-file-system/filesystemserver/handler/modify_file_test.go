@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModifyFileEdits(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	file := filepath.Join(dir, "main.go")
+	original := "func oldName() {}\n\nfunc main() {\n\toldName()\n\tlog.Println(\"v1\")\n}\n"
+	require.NoError(t, os.WriteFile(file, []byte(original), 0644))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	// A failing edit leaves the file untouched
+	res := callTool(t, fsHandler.HandleModifyFile, map[string]any{"path": file, "edits": []any{
+		map[string]any{"find": "oldName", "replace": "newName"},
+		map[string]any{"find": "fmt.Println", "replace": "log.Println"},
+	}})
+	require.True(t, res.IsError)
+	te := ResultError(res)
+	assert.Equal(t, CodeNotFound, te.Code)
+	assert.Equal(t, 2, te.Details["edit"])
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(content))
+
+	res = callTool(t, fsHandler.HandleModifyFile, map[string]any{"path": file, "edits": []any{
+		map[string]any{"find": "oldName", "replace": "newName"},
+		map[string]any{"find": `"v\d+"`, "replace": `"v2"`, "regex": true},
+		map[string]any{"find": "newName", "replace": "renamed", "all_occurrences": false},
+	}})
+	require.False(t, res.IsError)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Made 4 replacement(s)")
+	content, err = os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "func renamed() {}\n\nfunc main() {\n\tnewName()\n\tlog.Println(\"v2\")\n}\n", string(content))
+
+	res = callTool(t, fsHandler.HandleModifyFile, map[string]any{"path": file, "edits": []any{map[string]any{"find": "x"}}})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	res = callTool(t, fsHandler.HandleModifyFile, map[string]any{"path": file})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+}
+
+func TestModifyFileOccurrence(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	file := filepath.Join(dir, "list.txt")
+	require.NoError(t, os.WriteFile(file, []byte("item a1\nitem b2\nitem c3\n"), 0644))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	res := callTool(t, fsHandler.HandleModifyFile, map[string]any{"path": file, "find": "item", "replace": "entry", "occurrence": 2})
+	require.False(t, res.IsError)
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "item a1\nentry b2\nitem c3\n", string(content))
+
+	res = callTool(t, fsHandler.HandleModifyFile, map[string]any{"path": file, "edits": []any{
+		map[string]any{"find": `item (\w)(\d)`, "replace": "$2$1", "regex": true, "occurrence": 2},
+	}})
+	require.False(t, res.IsError)
+	content, err = os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "item a1\nentry b2\n3c\n", string(content))
+
+	// Too few matches fails without writing
+	res = callTool(t, fsHandler.HandleModifyFile, map[string]any{"path": file, "find": "item", "replace": "x", "occurrence": 3})
+	require.True(t, res.IsError)
+	assert.Equal(t, CodeNotFound, ResultError(res).Code)
+	content, err = os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "item a1\nentry b2\n3c\n", string(content))
+
+	res = callTool(t, fsHandler.HandleModifyFile, map[string]any{"path": file, "edits": []any{
+		map[string]any{"find": "item", "replace": "x", "occurrence": 0},
+	}})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+}
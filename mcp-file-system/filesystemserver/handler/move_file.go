@@ -13,6 +13,14 @@ func (fs *FilesystemHandler) HandleMoveFile(
 	ctx context.Context,
 	request mcp.CallToolRequest,
 ) (*mcp.CallToolResult, error) {
+	if isBatchTransfer(request) {
+		return fs.handleBatchTransfer(ctx, request, true)
+	}
+	onConflict, err := conflictPolicy(request)
+	if err != nil {
+		return errorResult(err), nil
+	}
+
 	source, err := request.RequireString("source")
 	if err != nil {
 		return nil, err
@@ -22,20 +30,19 @@ func (fs *FilesystemHandler) HandleMoveFile(
 		return nil, err
 	}
 
+	if isSMBPath(source) || isSMBPath(destination) {
+		if onConflict != ConflictOverwrite {
+			return errorResult(toolError(CodeInvalidArgument, "Error: on_conflict is not supported for SMB paths")), nil
+		}
+		return fs.smbMoveFile(ctx, source, destination)
+	}
+
 	// Handle empty or relative paths for source
 	if source == "." || source == "./" {
 		// Get current working directory
 		cwd, err := os.Getwd()
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					mcp.TextContent{
-						Type: "text",
-						Text: fmt.Sprintf("Error resolving current directory: %v", err),
-					},
-				},
-				IsError: true,
-			}, nil
+			return errorResult(fmt.Errorf("Error resolving current directory: %w", err)), nil
 		}
 		source = cwd
 	}
@@ -45,97 +52,59 @@ func (fs *FilesystemHandler) HandleMoveFile(
 		// Get current working directory
 		cwd, err := os.Getwd()
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					mcp.TextContent{
-						Type: "text",
-						Text: fmt.Sprintf("Error resolving current directory: %v", err),
-					},
-				},
-				IsError: true,
-			}, nil
+			return errorResult(fmt.Errorf("Error resolving current directory: %w", err)), nil
 		}
 		destination = cwd
 	}
 
-	validSource, err := fs.validatePath(source)
+	validSource, err := fs.validateWritablePath(source)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error with source path: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error with source path: %w", err)), nil
 	}
 
 	// Check if source exists
 	if _, err := os.Stat(validSource); os.IsNotExist(err) {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error: Source does not exist: %s", source),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(toolError(CodeNotFound, "Error: Source does not exist: %s", source).With("path", source)), nil
 	}
 
 	// For destination path, validate the parent directory first and create it if needed
 	destDir := filepath.Dir(destination)
-	validDestDir, err := fs.validatePath(destDir)
+	validDestDir, err := fs.validateCreatablePath(destDir, request.GetBool("create_parents", false))
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error with destination directory path: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error with destination directory path: %w", err)), nil
+	}
+
+	if fs.dryRunMode() {
+		dest, err := resolveConflict(filepath.Join(validDestDir, filepath.Base(destination)), onConflict, nil)
+		if err != nil {
+			return errorResult(fmt.Errorf("Error: %w", err)), nil
+		}
+		lines := []string{fmt.Sprintf("Would move %s to %s", describeTree(validSource), dest)}
+		if _, err := os.Lstat(dest); err == nil {
+			lines = append(lines, fmt.Sprintf("Would replace %s", describeTree(dest)))
+		}
+		for _, dir := range missingParents(dest) {
+			lines = append(lines, fmt.Sprintf("Would create directory %s", dir))
+		}
+		return dryRunResult(lines...), nil
 	}
 
 	// Create parent directory for destination if it doesn't exist
 	if err := os.MkdirAll(validDestDir, 0755); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error creating destination directory: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error creating destination directory: %w", err)), nil
 	}
 
 	// Now validate the full destination path
-	validDest, err := fs.validatePath(destination)
+	validDest, err := fs.validateWritablePath(destination)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error with destination path: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error with destination path: %w", err)), nil
+	}
+	if validDest, err = resolveConflict(validDest, onConflict, nil); err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
 	}
 
 	if err := os.Rename(validSource, validDest); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error moving file: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error moving file: %w", err)), nil
 	}
 
 	resourceURI := pathToResourceURI(validDest)
@@ -146,7 +115,7 @@ func (fs *FilesystemHandler) HandleMoveFile(
 				Text: fmt.Sprintf(
 					"Successfully moved %s to %s",
 					source,
-					destination,
+					validDest,
 				),
 			},
 			mcp.EmbeddedResource{
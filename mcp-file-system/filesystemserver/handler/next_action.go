@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// NextActionRoute declares the call an orchestrator should make after a
+// tool succeeds. String arguments may hold {{name}} placeholders, filled
+// from the top-level fields of a JSON result or else from the arguments of
+// the call; an argument that is just one placeholder keeps the value's
+// type. A route whose placeholders cannot all be filled is not offered.
+type NextActionRoute struct {
+	Tool      string         `json:"tool" yaml:"tool"`
+	MCP       string         `json:"mcp,omitempty" yaml:"mcp,omitempty"`
+	Arguments map[string]any `json:"arguments,omitempty" yaml:"arguments,omitempty"`
+}
+
+// placeholderPattern matches a {{name}} placeholder in a route argument
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+
+// WithNextActions sets the next action routes by the name of the tool they
+// follow. A route with an empty Tool turns off the built-in route for that
+// tool.
+func WithNextActions(routes map[string]NextActionRoute) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		if fs.nextActions == nil {
+			fs.nextActions = make(map[string]NextActionRoute, len(routes))
+		}
+		maps.Copy(fs.nextActions, routes)
+	}
+}
+
+// nextActionRoute returns the route that follows tool. Unless configured
+// otherwise, croc_send hands off to the convert-router's
+// convert_to_markdown, but only when a convert-router is set up.
+func (fs *FilesystemHandler) nextActionRoute(tool string) (NextActionRoute, bool) {
+	fs.mu.RLock()
+	route, ok := fs.nextActions[tool]
+	fs.mu.RUnlock()
+	if ok {
+		return route, route.Tool != ""
+	}
+	if tool == "croc_send" && fs.convertRouter.URL != "" {
+		return NextActionRoute{
+			Tool:      "convert_to_markdown",
+			MCP:       "convert-router（服务端）",
+			Arguments: map[string]any{"croc_code": "{{code}}"},
+		}, true
+	}
+	return NextActionRoute{}, false
+}
+
+// render fills the placeholders of the route's arguments from vars
+func (r NextActionRoute) render(vars map[string]any) (*NextAction, bool) {
+	args, ok := renderValue(r.Arguments, vars)
+	if !ok {
+		return nil, false
+	}
+	action := &NextAction{Tool: r.Tool, MCP: r.MCP, Arguments: map[string]any{}}
+	if args, ok := args.(map[string]any); ok && args != nil {
+		action.Arguments = args
+	}
+	return action, true
+}
+
+// renderValue fills placeholders in value and in the maps and lists it holds
+func renderValue(value any, vars map[string]any) (any, bool) {
+	switch v := value.(type) {
+	case string:
+		if m := placeholderPattern.FindStringSubmatch(v); m != nil && m[0] == v {
+			filled, ok := vars[m[1]]
+			return filled, ok
+		}
+		ok := true
+		filled := placeholderPattern.ReplaceAllStringFunc(v, func(s string) string {
+			filled, found := vars[placeholderPattern.FindStringSubmatch(s)[1]]
+			ok = ok && found
+			return fmt.Sprint(filled)
+		})
+		return filled, ok
+	case map[string]any:
+		filled := make(map[string]any, len(v))
+		for key, item := range v {
+			var ok bool
+			if filled[key], ok = renderValue(item, vars); !ok {
+				return nil, false
+			}
+		}
+		return filled, true
+	case []any:
+		filled := make([]any, len(v))
+		for i, item := range v {
+			var ok bool
+			if filled[i], ok = renderValue(item, vars); !ok {
+				return nil, false
+			}
+		}
+		return filled, true
+	}
+	return value, true
+}
+
+// RouteToolCall wraps a tool handler so a successful result carries the
+// next action routed from tool. JSON object results gain a "next_action"
+// field unless they already have one; other results gain a text line.
+func (fs *FilesystemHandler) RouteToolCall(tool string, next ToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, request)
+		if err != nil || result == nil || result.IsError {
+			return result, err
+		}
+		route, ok := fs.nextActionRoute(tool)
+		if !ok {
+			return result, nil
+		}
+
+		vars := maps.Clone(request.GetArguments())
+		if vars == nil {
+			vars = map[string]any{}
+		}
+		var fields map[string]json.RawMessage
+		first, isText := firstText(result)
+		if isText && json.Unmarshal([]byte(first.Text), &fields) == nil {
+			if _, exists := fields["next_action"]; exists {
+				return result, nil
+			}
+			for key, raw := range fields {
+				var value any
+				if json.Unmarshal(raw, &value) == nil {
+					vars[key] = value
+				}
+			}
+		} else {
+			fields = nil
+		}
+		action, ok := route.render(vars)
+		if !ok {
+			return result, nil
+		}
+
+		if fields == nil {
+			data, err := json.Marshal(action)
+			if err != nil {
+				return result, nil
+			}
+			result.Content = append(result.Content, mcp.NewTextContent("Next action: "+string(data)))
+			return result, nil
+		}
+		text, err := withNextAction(first.Text, action)
+		if err != nil {
+			return result, nil
+		}
+		contents := append([]mcp.Content{}, result.Content...)
+		for i, content := range contents {
+			if _, ok := content.(mcp.TextContent); ok {
+				contents[i] = mcp.NewTextContent(text)
+				break
+			}
+		}
+		result.Content = contents
+		return result, nil
+	}
+}
+
+// firstText returns the first text content of result
+func firstText(result *mcp.CallToolResult) (mcp.TextContent, bool) {
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			return text, true
+		}
+	}
+	return mcp.TextContent{}, false
+}
+
+// withNextAction adds a "next_action" field at the end of the JSON object
+// text, keeping its field order and indenting it like the rest
+func withNextAction(text string, action *NextAction) (string, error) {
+	end := strings.LastIndexByte(text, '}')
+	head := strings.TrimRight(text[:end], " \t\r\n")
+	indented := strings.Contains(text[:end], "\n")
+	var data []byte
+	var err error
+	if indented {
+		data, err = json.MarshalIndent(action, "  ", "  ")
+	} else {
+		data, err = json.Marshal(action)
+	}
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	sb.WriteString(head)
+	if !strings.HasSuffix(head, "{") {
+		sb.WriteString(",")
+	}
+	if indented {
+		sb.WriteString("\n  \"next_action\": ")
+		sb.Write(data)
+		sb.WriteString("\n")
+	} else {
+		sb.WriteString(`"next_action":`)
+		sb.Write(data)
+	}
+	sb.WriteString(text[end:])
+	return sb.String(), nil
+}
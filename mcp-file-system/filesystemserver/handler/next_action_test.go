@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouteToolCall(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	crocSend := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(`{"code":"abc123","status":"waiting_for_receiver"}`), nil
+	}
+	nextAction := func(res *mcp.CallToolResult) *NextAction {
+		var response CrocSendResponse
+		require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &response))
+		return response.NextAction
+	}
+
+	// Without a convert-router there is nothing to hand off to
+	t.Setenv(convertRouterURLEnv, "")
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	assert.Nil(t, nextAction(callTool(t, fsHandler.RouteToolCall("croc_send", crocSend), nil)))
+
+	fsHandler, err = NewFilesystemHandler([]string{dir}, WithConvertRouter(ConvertRouterConfig{URL: "http://router.example/mcp"}))
+	require.NoError(t, err)
+	action := nextAction(callTool(t, fsHandler.RouteToolCall("croc_send", crocSend), nil))
+	require.NotNil(t, action)
+	assert.Equal(t, "convert_to_markdown", action.Tool)
+	assert.Equal(t, map[string]any{"croc_code": "abc123"}, action.Arguments)
+
+	fsHandler, err = NewFilesystemHandler([]string{dir}, WithConvertRouter(ConvertRouterConfig{URL: "http://router.example/mcp"}), WithNextActions(map[string]NextActionRoute{
+		"croc_send": {},
+		"write_file": {Tool: "index_document", MCP: "search", Arguments: map[string]any{
+			"path":   "{{path}}",
+			"labels": []any{"source:{{origin}}"},
+		}},
+	}))
+	require.NoError(t, err)
+	assert.Nil(t, nextAction(callTool(t, fsHandler.RouteToolCall("croc_send", crocSend), nil)))
+
+	writeFile := fsHandler.RouteToolCall("write_file", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("Successfully wrote file"), nil
+	})
+	// A route is only offered when every placeholder can be filled
+	res := callTool(t, writeFile, map[string]any{"path": "/data/a.md"})
+	assert.Len(t, res.Content, 1)
+	res = callTool(t, writeFile, map[string]any{"path": "/data/a.md", "origin": "upload"})
+	require.Len(t, res.Content, 2)
+	assert.Equal(t, `Next action: {"tool":"index_document","mcp":"search","arguments":{"labels":["source:upload"],"path":"/data/a.md"}}`,
+		res.Content[1].(mcp.TextContent).Text)
+
+	// Indented JSON keeps its layout
+	indented := fsHandler.RouteToolCall("write_file", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return jsonResult(ctx, map[string]any{"path": "/data/b.md", "origin": "sync"})
+	})
+	res = callTool(t, indented, nil)
+	var fields map[string]any
+	text := res.Content[0].(mcp.TextContent).Text
+	require.NoError(t, json.Unmarshal([]byte(text), &fields))
+	assert.Contains(t, text, "\n  \"next_action\": {\n    \"tool\": \"index_document\"")
+	assert.Equal(t, "/data/b.md", fields["next_action"].(map[string]any)["arguments"].(map[string]any)["path"])
+}
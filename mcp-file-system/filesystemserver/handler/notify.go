@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// slackWebhookEnv is a Slack incoming-webhook URL for notifications
+	slackWebhookEnv = "MCP_FS_SLACK_WEBHOOK_URL"
+	// smtpHostEnv enables email notifications through this SMTP server
+	smtpHostEnv = "MCP_FS_SMTP_HOST"
+	// smtpPortEnv overrides the SMTP port (default: 587)
+	smtpPortEnv = "MCP_FS_SMTP_PORT"
+	// smtpUserEnv and smtpPasswordEnv enable SMTP PLAIN authentication
+	smtpUserEnv     = "MCP_FS_SMTP_USER"
+	smtpPasswordEnv = "MCP_FS_SMTP_PASSWORD"
+	// smtpFromEnv is the sender address for notification emails
+	smtpFromEnv = "MCP_FS_SMTP_FROM"
+	// notifyEmailEnv lists comma separated notification recipients
+	notifyEmailEnv = "MCP_FS_NOTIFY_EMAIL"
+	// notifyEventsEnv overrides the comma separated event filter for all notifiers
+	notifyEventsEnv = "MCP_FS_NOTIFY_EVENTS"
+)
+
+// defaultNotifyEvents are the outcomes of long-running operations worth telling a human about
+var defaultNotifyEvents = []string{"croc.transfer.*", "backup.*", EventQuotaExceeded}
+
+// SMTPConfig describes the mail server used for email notifications
+type SMTPConfig struct {
+	Host     string   `json:"host" yaml:"host"`
+	Port     int      `json:"port,omitempty" yaml:"port,omitempty"`
+	Username string   `json:"username,omitempty" yaml:"username,omitempty"`
+	Password string   `json:"-" yaml:"password,omitempty"`
+	From     string   `json:"from" yaml:"from"`
+	To       []string `json:"to" yaml:"to"`
+}
+
+// Notifier announces events to humans via Slack or email. Exactly one of
+// SlackWebhookURL and SMTP should be set.
+type Notifier struct {
+	SlackWebhookURL string      `json:"-" yaml:"slack_webhook_url,omitempty"`
+	SMTP            *SMTPConfig `json:"smtp,omitempty" yaml:"smtp,omitempty"`
+	// Events filters by type like WebhookConfig.Events; empty means transfers, backups and quota errors
+	Events []string `json:"events,omitempty" yaml:"events,omitempty"`
+}
+
+// WithNotifiers registers Slack or email notifiers for long-running operations
+func WithNotifiers(notifiers []Notifier) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		fs.notifiers = append(fs.notifiers, notifiers...)
+	}
+}
+
+// notifiersFromEnv builds notifiers from the MCP_FS_SLACK_* and MCP_FS_SMTP_* variables
+func notifiersFromEnv() []Notifier {
+	var events []string
+	if raw := os.Getenv(notifyEventsEnv); raw != "" {
+		for _, e := range strings.Split(raw, ",") {
+			if e = strings.TrimSpace(e); e != "" {
+				events = append(events, e)
+			}
+		}
+	}
+
+	var notifiers []Notifier
+	if url := os.Getenv(slackWebhookEnv); url != "" {
+		notifiers = append(notifiers, Notifier{SlackWebhookURL: url, Events: events})
+	}
+	if host := os.Getenv(smtpHostEnv); host != "" {
+		cfg := &SMTPConfig{
+			Host:     host,
+			Username: os.Getenv(smtpUserEnv),
+			Password: os.Getenv(smtpPasswordEnv),
+			From:     os.Getenv(smtpFromEnv),
+		}
+		if port, err := strconv.Atoi(os.Getenv(smtpPortEnv)); err == nil {
+			cfg.Port = port
+		}
+		for _, to := range strings.Split(os.Getenv(notifyEmailEnv), ",") {
+			if to = strings.TrimSpace(to); to != "" {
+				cfg.To = append(cfg.To, to)
+			}
+		}
+		if cfg.From != "" && len(cfg.To) > 0 {
+			notifiers = append(notifiers, Notifier{SMTP: cfg, Events: events})
+		} else {
+			slog.Warn("email notifications disabled: sender or recipients missing", "set", smtpHostEnv, "required", []string{smtpFromEnv, notifyEmailEnv})
+		}
+	}
+	return notifiers
+}
+
+// wants reports whether the notifier subscribes to an event type
+func (n Notifier) wants(eventType string) bool {
+	events := n.Events
+	if len(events) == 0 {
+		events = defaultNotifyEvents
+	}
+	return WebhookConfig{Events: events}.wants(eventType)
+}
+
+// notify sends the event to every subscribed notifier in the background
+func (fs *FilesystemHandler) notify(ev Event) {
+	for _, n := range fs.notifiers {
+		if !n.wants(ev.Type) {
+			continue
+		}
+		fs.eventWG.Add(1)
+		go func(n Notifier) {
+			defer fs.eventWG.Done()
+			subject, body := formatNotification(ev)
+			var err error
+			switch {
+			case n.SlackWebhookURL != "":
+				err = sendSlack(n.SlackWebhookURL, subject, body)
+			case n.SMTP != nil:
+				err = sendEmail(*n.SMTP, subject, body)
+			}
+			if err != nil {
+				fs.logger.Error("sending notification failed", "event", ev.Type, "error", err)
+			}
+		}(n)
+	}
+}
+
+// formatNotification renders an event as a subject line and plain-text body
+func formatNotification(ev Event) (string, string) {
+	outcome := "completed"
+	if strings.HasSuffix(ev.Type, ".failed") {
+		outcome = "FAILED"
+	}
+	var subject string
+	switch {
+	case strings.HasPrefix(ev.Type, "croc.transfer."):
+		direction, _ := ev.Data["direction"].(string)
+		subject = fmt.Sprintf("Croc %s %s", direction, outcome)
+	case strings.HasPrefix(ev.Type, "backup."):
+		subject = fmt.Sprintf("Backup %s", outcome)
+		if job, _ := ev.Data["job"].(string); job != "" {
+			subject = fmt.Sprintf("Backup %q %s", job, outcome)
+		}
+	case ev.Type == EventQuotaExceeded:
+		subject = "Disk quota exceeded"
+	default:
+		subject = ev.Type
+	}
+	if host, err := os.Hostname(); err == nil {
+		subject = fmt.Sprintf("[%s] %s", host, subject)
+	}
+
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("Event: %s\nTime: %s\n", ev.Type, formatTime(ev.Time)))
+	if ev.Path != "" {
+		body.WriteString(fmt.Sprintf("Path: %s\n", ev.Path))
+	}
+	keys := make([]string, 0, len(ev.Data))
+	for k := range ev.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if v := fmt.Sprint(ev.Data[k]); v != "" {
+			body.WriteString(fmt.Sprintf("%s: %s\n", k, v))
+		}
+	}
+	return subject, body.String()
+}
+
+// sendSlack posts a message to a Slack incoming webhook
+func sendSlack(url, subject, body string) error {
+	payload, err := json.Marshal(map[string]string{
+		"text": fmt.Sprintf("*%s*\n```%s```", subject, body),
+	})
+	if err != nil {
+		return err
+	}
+	resp, err := webhookClient.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("slack returned %s", resp.Status)
+	}
+	return nil
+}
+
+// smtpSendMail sends mail; replaceable in tests
+var smtpSendMail = smtp.SendMail
+
+// sendEmail sends a plain-text notification email
+func sendEmail(cfg SMTPConfig, subject, body string) error {
+	port := cfg.Port
+	if port == 0 {
+		port = 587
+	}
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s",
+		cfg.From, strings.Join(cfg.To, ", "), subject, time.Now().Format(time.RFC1123Z),
+		strings.ReplaceAll(body, "\n", "\r\n"))
+	return smtpSendMail(net.JoinHostPort(cfg.Host, strconv.Itoa(port)), auth, cfg.From, cfg.To, []byte(msg))
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifiers(t *testing.T) {
+	var mu sync.Mutex
+	var slackTexts []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		slackTexts = append(slackTexts, payload["text"])
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	var mails []string
+	oldSend := smtpSendMail
+	smtpSendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, "mail.example.com:2525", addr)
+		assert.Equal(t, []string{"ops@example.com"}, to)
+		mails = append(mails, string(msg))
+		return nil
+	}
+	t.Cleanup(func() { smtpSendMail = oldSend })
+
+	fsHandler, err := NewFilesystemHandler(resolveAllowedDirs(t, t.TempDir()), WithNotifiers([]Notifier{
+		{SlackWebhookURL: ts.URL},
+		{SMTP: &SMTPConfig{Host: "mail.example.com", Port: 2525, From: "fs@example.com", To: []string{"ops@example.com"}}},
+	}))
+	require.NoError(t, err)
+
+	fsHandler.emitEvent(EventBackupFailed, "/srv/data", map[string]any{"job": "nightly", "error": "disk full"})
+	// file.changed is not a default notification event
+	fsHandler.emitEvent(EventFileChanged, "/srv/data/a.txt", nil)
+	fsHandler.eventWG.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, slackTexts, 1)
+	assert.Contains(t, slackTexts[0], `Backup "nightly" FAILED`)
+	assert.Contains(t, slackTexts[0], "error: disk full")
+	require.Len(t, mails, 1)
+	assert.Contains(t, mails[0], "Subject: ")
+	assert.Contains(t, mails[0], "Path: /srv/data")
+}
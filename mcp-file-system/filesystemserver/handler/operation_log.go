@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Operation log defaults
+const (
+	defaultOperationLogMaxSize  = 100 * 1024 * 1024 // 100MB
+	defaultOperationLogMaxFiles = 10
+)
+
+// operationLogName is the file currently being written; rotated files are
+// renamed to operations-<timestamp>.jsonl
+const (
+	operationLogBase = "operations"
+	operationLogName = operationLogBase + ".jsonl"
+)
+
+// OperationLogConfig configures the JSONL export of tool calls
+type OperationLogConfig struct {
+	// Dir receives operations.jsonl and its rotated predecessors
+	Dir string `json:"dir" yaml:"dir"`
+	// MaxSize rotates the file once it reaches this many bytes (default: 100MB)
+	MaxSize int64 `json:"max_size,omitempty" yaml:"max_size,omitempty"`
+	// MaxFiles is the number of rotated files kept (default: 10)
+	MaxFiles int `json:"max_files,omitempty" yaml:"max_files,omitempty"`
+}
+
+// OperationRecord is one line of the operation log
+type OperationRecord struct {
+	Time time.Time `json:"time"`
+	Tool string    `json:"tool"`
+	// Arguments are the complete tool arguments, so mutations can be replayed
+	Arguments map[string]any `json:"arguments,omitempty"`
+	// Mutation marks calls that modify files
+	Mutation bool `json:"mutation,omitempty"`
+	// DryRun marks calls made while the server was in dry-run mode
+	DryRun   bool  `json:"dry_run,omitempty"`
+	IsError  bool  `json:"is_error,omitempty"`
+	Duration int64 `json:"duration_ms"`
+	// Summary is the start of the result text
+	Summary string `json:"summary,omitempty"`
+}
+
+// mutatingTools are the built-in tools that modify files
+var mutatingTools = []string{
+	"write_file", "create_directory", "copy_file", "move_file", "delete_file",
+	"modify_file", "format_file", "restore_from_trash", "croc_receive",
+	"convert_to_markdown", "backup_directory", "replay_operations",
+	"sync_directories", "scaffold", "apply_permissions", "restore_version",
+	"apply_patch", "edit_lines", "restore_backup", "touch", "write_files",
+	"set_permissions", "set_owner", "set_file_times", "set_xattr",
+	"create_symlink", "create_hardlink", "empty_trash", "bulk_rename",
+}
+
+// isMutation reports whether a call to tool with args modifies files
+func isMutation(tool string, args map[string]any) bool {
+	switch tool {
+	case "format_file", "modify_file", "sync_directories", "apply_permissions", "apply_patch", "edit_lines", "write_files",
+		"set_permissions", "set_owner", "empty_trash", "bulk_rename":
+		dryRun, _ := args["dry_run"].(bool)
+		return !dryRun
+	case "delete_file":
+		// A glob only lists matches until the deletion is confirmed
+		pattern, _ := args["glob"].(string)
+		token, _ := args["confirm_token"].(string)
+		return pattern == "" || token != ""
+	case "probe_media":
+		path, _ := args["thumbnail_path"].(string)
+		return path != ""
+	case "self_update":
+		checkOnly, _ := args["check_only"].(bool)
+		return !checkOnly
+	}
+	return slices.Contains(mutatingTools, tool)
+}
+
+// operationLog appends records to a size-rotated JSONL file. The file is
+// opened on first use so handlers that never record leave no trace.
+type operationLog struct {
+	cfg OperationLogConfig
+	// base names the files <base>.jsonl and <base>-<timestamp>.jsonl;
+	// empty means operationLogBase
+	base string
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// baseName returns the base name of the log files
+func (l *operationLog) baseName() string {
+	if l.base == "" {
+		return operationLogBase
+	}
+	return l.base
+}
+
+// WithOperationLog records every tool call to rotating JSONL files in cfg.Dir
+func WithOperationLog(cfg OperationLogConfig) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		if cfg.MaxSize <= 0 {
+			cfg.MaxSize = defaultOperationLogMaxSize
+		}
+		if cfg.MaxFiles <= 0 {
+			cfg.MaxFiles = defaultOperationLogMaxFiles
+		}
+		fs.operations = &operationLog{cfg: cfg}
+	}
+}
+
+// RecordToolCall wraps a tool handler so every call is appended to the
+// operation log, when one is configured
+func (fs *FilesystemHandler) RecordToolCall(tool string, next ToolHandlerFunc) ToolHandlerFunc {
+	if fs.operations == nil {
+		return next
+	}
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := next(ctx, request)
+
+		args := request.GetArguments()
+		record := OperationRecord{
+			Time:      start.UTC(),
+			Tool:      tool,
+			Arguments: args,
+			Mutation:  isMutation(tool, args),
+			DryRun:    fs.dryRunMode(),
+			Duration:  time.Since(start).Milliseconds(),
+		}
+		switch {
+		case err != nil:
+			record.IsError = true
+			record.Summary = err.Error()
+		case result != nil:
+			record.IsError = result.IsError
+			record.Summary = toolErrorText(result)
+		}
+		if err := fs.operations.write(record); err != nil {
+			fs.logger.Error("failed to record operation", "tool", tool, "error", err)
+		}
+		return result, err
+	}
+}
+
+// write appends record, rotating the file first if it has grown too large
+func (l *operationLog) write(record any) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file != nil && l.size > 0 && l.size+int64(len(line)) > l.cfg.MaxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	if l.file == nil {
+		if err := l.open(); err != nil {
+			return err
+		}
+	}
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	return err
+}
+
+// open opens the current log file for appending
+func (l *operationLog) open() error {
+	if err := os.MkdirAll(l.cfg.Dir, 0700); err != nil {
+		return fmt.Errorf("failed to create operation log directory: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(l.cfg.Dir, l.baseName()+".jsonl"), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	l.file, l.size = f, info.Size()
+	return nil
+}
+
+// rotate renames the current file aside and removes the oldest rotated
+// files beyond MaxFiles
+func (l *operationLog) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+	l.file = nil
+	rotated := fmt.Sprintf("%s-%s.jsonl", l.baseName(), time.Now().UTC().Format("20060102T150405.000000000"))
+	if err := os.Rename(filepath.Join(l.cfg.Dir, l.baseName()+".jsonl"), filepath.Join(l.cfg.Dir, rotated)); err != nil {
+		return err
+	}
+	files, err := l.rotatedFiles()
+	if err != nil {
+		return err
+	}
+	for len(files) > l.cfg.MaxFiles {
+		os.Remove(files[0])
+		files = files[1:]
+	}
+	return nil
+}
+
+// rotatedFiles returns the rotated log files, oldest first
+func (l *operationLog) rotatedFiles() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(l.cfg.Dir, l.baseName()+"-*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(files)
+	return files, nil
+}
+
+// close closes the current log file; a later write reopens it
+func (l *operationLog) close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// contains reports whether path lies in the operation log directory
+func (l *operationLog) contains(path string) bool {
+	dir, err := filepath.Abs(l.cfg.Dir)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(path, withTrailingSeparator(dir))
+}
@@ -0,0 +1,320 @@
+package handler
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// defaultPreviewLines and maxPreviewLines bound the lines preview_file shows
+	defaultPreviewLines = 20
+	maxPreviewLines     = 500
+	// previewReadSize is how much of a text file preview_file reads
+	previewReadSize = 256 * 1024
+	// previewThumbnailSize is the longest side of a preview thumbnail
+	previewThumbnailSize = 128
+	// maxPreviewPixels is the largest image preview_file decodes
+	maxPreviewPixels = 50_000_000
+	// previewEntries is how many archive entries preview_file names
+	previewEntries = 10
+)
+
+// Kinds of preview
+const (
+	PreviewText    = "text"
+	PreviewImage   = "image"
+	PreviewArchive = "archive"
+	PreviewBinary  = "binary"
+)
+
+// FilePreview is the JSON result of preview_file
+type FilePreview struct {
+	Path         string `json:"path"`
+	Kind         string `json:"kind"`
+	MIMEType     string `json:"mime_type"`
+	Size         int64  `json:"size"`
+	Modified     string `json:"modified"`
+	ModifiedUnix int64  `json:"modified_unix"`
+	// Head holds the first lines of a text file, decoded from Encoding
+	Head      string `json:"head,omitempty"`
+	Encoding  string `json:"encoding,omitempty"`
+	Lines     int    `json:"lines,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"`
+	// Width and Height are the dimensions of an image
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	// Entries counts the entries of an archive; FirstEntries names some
+	Entries          int      `json:"entry_count,omitempty"`
+	UncompressedSize int64    `json:"uncompressed_size,omitempty"`
+	FirstEntries     []string `json:"first_entries,omitempty"`
+}
+
+// HandlePreviewFile handles the preview_file tool
+func (fs *FilesystemHandler) HandlePreviewFile(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	lines := request.GetInt("lines", defaultPreviewLines)
+	if lines < 1 || lines > maxPreviewLines {
+		return errorResult(toolError(CodeInvalidArgument, "Error: lines must be between 1 and %d", maxPreviewLines).With("lines", lines)), nil
+	}
+	withThumbnail := request.GetBool("thumbnail", true)
+
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if info.IsDir() {
+		return errorResult(toolError(CodeWrongType, "Error: %s is a directory; use list_directory or workspace_stats", path).With("path", path)), nil
+	}
+
+	preview := &FilePreview{
+		Path:         validPath,
+		Kind:         PreviewBinary,
+		MIMEType:     detectMimeType(validPath),
+		Size:         info.Size(),
+		Modified:     formatTime(info.ModTime()),
+		ModifiedUnix: unixTime(info.ModTime()),
+	}
+	var thumbnail []byte
+	switch {
+	case isImageFile(preview.MIMEType):
+		preview.Kind = PreviewImage
+		thumbnail, err = previewImage(validPath, preview, withThumbnail)
+	case isArchive(preview.MIMEType, validPath):
+		preview.Kind = PreviewArchive
+		err = previewArchive(ctx, validPath, preview)
+	case isTextFile(preview.MIMEType):
+		preview.Kind = PreviewText
+		err = previewText(validPath, preview, lines)
+	}
+	if err != nil {
+		return errorResult(fmt.Errorf("Error previewing %s: %w", path, err)), nil
+	}
+
+	var result *mcp.CallToolResult
+	if wantsJSON(ctx) {
+		if result, err = jsonResult(ctx, preview); err != nil || result.IsError {
+			return result, err
+		}
+	} else {
+		result = mcp.NewToolResultText(describePreview(preview))
+	}
+	if thumbnail != nil {
+		result.Content = append(result.Content, mcp.ImageContent{
+			Type:     "image",
+			Data:     base64.StdEncoding.EncodeToString(thumbnail),
+			MIMEType: "image/png",
+		})
+	}
+	return result, nil
+}
+
+// describePreview renders a preview as text
+func describePreview(p *FilePreview) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s\nKind: %s\nMIME type: %s\nSize: %s\nModified: %s\n",
+		p.Path, p.Kind, p.MIMEType, formatFileSize(p.Size), p.Modified))
+	switch p.Kind {
+	case PreviewImage:
+		if p.Width > 0 {
+			sb.WriteString(fmt.Sprintf("Dimensions: %dx%d\n", p.Width, p.Height))
+		}
+	case PreviewArchive:
+		sb.WriteString(fmt.Sprintf("Entries: %d (%s uncompressed)\n", p.Entries, formatFileSize(p.UncompressedSize)))
+		for _, name := range p.FirstEntries {
+			sb.WriteString(fmt.Sprintf("  %s\n", name))
+		}
+		if p.Entries > len(p.FirstEntries) {
+			sb.WriteString(fmt.Sprintf("  ... and %d more\n", p.Entries-len(p.FirstEntries)))
+		}
+	case PreviewText:
+		sb.WriteString(fmt.Sprintf("Encoding: %s\n", p.Encoding))
+		if p.Truncated {
+			sb.WriteString(fmt.Sprintf("First %d lines:\n", p.Lines))
+		} else {
+			sb.WriteString(fmt.Sprintf("All %d lines:\n", p.Lines))
+		}
+		sb.WriteString("\n" + p.Head)
+		if !strings.HasSuffix(p.Head, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// previewText fills in the first lines of a text file
+func previewText(path string, p *FilePreview, lines int) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	head := make([]byte, previewReadSize)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return err
+	}
+	text, encoding, err := decodeText(head[:n], EncodingAuto)
+	if err != nil {
+		return err
+	}
+	p.Encoding = encoding
+	p.Truncated = int64(n) < p.Size
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\n' {
+			continue
+		}
+		p.Lines++
+		if p.Lines == lines {
+			p.Truncated = p.Truncated || i+1 < len(text)
+			p.Head = text[:i+1]
+			return nil
+		}
+	}
+	// The last line has no line break, or was cut off by previewReadSize
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		p.Lines++
+	}
+	p.Head = text
+	return nil
+}
+
+// previewImage reads the dimensions of an image and, with withThumbnail,
+// returns a PNG thumbnail of it. Formats the standard library cannot decode
+// are previewed without either.
+func previewImage(path string, p *FilePreview, withThumbnail bool) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return nil, nil
+	}
+	p.Width, p.Height = cfg.Width, cfg.Height
+	if !withThumbnail || cfg.Width*cfg.Height > maxPreviewPixels {
+		return nil, nil
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, nil
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, scaleImage(img, previewThumbnailSize)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// scaleImage shrinks img so its longest side is at most size, sampling the
+// nearest pixel
+func scaleImage(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= size && h <= size {
+		return img
+	}
+	tw, th := size, max(1, h*size/w)
+	if h > w {
+		tw, th = max(1, w*size/h), size
+	}
+	thumb := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		for x := 0; x < tw; x++ {
+			thumb.Set(x, y, img.At(b.Min.X+x*w/tw, b.Min.Y+y*h/th))
+		}
+	}
+	return thumb
+}
+
+// isArchive reports whether preview_file lists the entries of a file
+func isArchive(mimeType, path string) bool {
+	switch mimeType {
+	case "application/zip", "application/x-tar", "application/java-archive", "application/epub+zip":
+		return true
+	case "application/gzip", "application/x-gzip":
+		lower := strings.ToLower(path)
+		return strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz")
+	}
+	return false
+}
+
+// previewArchive counts the entries of a zip or tar archive
+func previewArchive(ctx context.Context, path string, p *FilePreview) error {
+	add := func(name string, size int64) {
+		p.Entries++
+		p.UncompressedSize += size
+		if len(p.FirstEntries) < previewEntries {
+			p.FirstEntries = append(p.FirstEntries, name)
+		}
+	}
+	isTar := p.MIMEType == "application/x-tar"
+	isGzip := p.MIMEType == "application/gzip" || p.MIMEType == "application/x-gzip"
+	if !isTar && !isGzip {
+		zr, err := zip.OpenReader(path)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		for _, f := range zr.File {
+			add(f.Name, int64(f.UncompressedSize64))
+		}
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if isGzip {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	tr := tar.NewReader(r)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		add(hdr.Name, hdr.Size)
+	}
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewFile(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	var text strings.Builder
+	for i := 1; i <= 30; i++ {
+		text.WriteString("line " + strings.Repeat("x", i) + "\n")
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte(text.String()), 0644))
+
+	img := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	f, err := os.Create(filepath.Join(dir, "photo.png"))
+	require.NoError(t, err)
+	require.NoError(t, png.Encode(f, img))
+	require.NoError(t, f.Close())
+
+	f, err = os.Create(filepath.Join(dir, "bundle.zip"))
+	require.NoError(t, err)
+	zw := zip.NewWriter(f)
+	for _, name := range []string{"a.txt", "b/c.txt"} {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte("hello"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	preview := fsHandler.FormatToolCall(fsHandler.HandlePreviewFile)
+	run := func(name string, args map[string]any) (FilePreview, *mcp.CallToolResult) {
+		args["path"], args["format"] = filepath.Join(dir, name), FormatJSON
+		res := callTool(t, preview, args)
+		require.False(t, res.IsError, res.Content)
+		var got FilePreview
+		require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &got))
+		return got, res
+	}
+
+	got, _ := run("notes.txt", map[string]any{"lines": 3})
+	assert.Equal(t, PreviewText, got.Kind)
+	assert.Equal(t, "line x\nline xx\nline xxx\n", got.Head)
+	assert.Equal(t, 3, got.Lines)
+	assert.True(t, got.Truncated)
+	assert.Equal(t, "utf-8", got.Encoding)
+
+	got, res := run("photo.png", map[string]any{})
+	assert.Equal(t, PreviewImage, got.Kind)
+	assert.Equal(t, 400, got.Width)
+	assert.Equal(t, 200, got.Height)
+	require.Len(t, res.Content, 2)
+	data, err := base64.StdEncoding.DecodeString(res.Content[1].(mcp.ImageContent).Data)
+	require.NoError(t, err)
+	thumb, err := png.DecodeConfig(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, []int{128, 64}, []int{thumb.Width, thumb.Height})
+
+	_, res = run("photo.png", map[string]any{"thumbnail": false})
+	assert.Len(t, res.Content, 1)
+
+	got, _ = run("bundle.zip", map[string]any{})
+	assert.Equal(t, PreviewArchive, got.Kind)
+	assert.Equal(t, 2, got.Entries)
+	assert.Equal(t, int64(10), got.UncompressedSize)
+	assert.Equal(t, []string{"a.txt", "b/c.txt"}, got.FirstEntries)
+
+	res = callTool(t, preview, map[string]any{"path": filepath.Join(dir, "notes.txt")})
+	require.False(t, res.IsError, res.Content)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "First 20 lines:\n\nline x\n")
+
+	res = callTool(t, preview, map[string]any{"path": dir})
+	assert.Equal(t, CodeWrongType, ResultError(res).Code)
+}
@@ -0,0 +1,268 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Media tool binaries; overridable in tests
+var (
+	ffprobeBinary = "ffprobe"
+	ffmpegBinary  = "ffmpeg"
+)
+
+// mediaToolTimeout bounds ffprobe/ffmpeg runs
+const mediaToolTimeout = 60 * time.Second
+
+// MediaStream describes a single audio, video or subtitle stream
+type MediaStream struct {
+	Index      int     `json:"index"`
+	Type       string  `json:"type"`
+	Codec      string  `json:"codec"`
+	CodecLong  string  `json:"codec_long,omitempty"`
+	Width      int     `json:"width,omitempty"`
+	Height     int     `json:"height,omitempty"`
+	FrameRate  string  `json:"frame_rate,omitempty"`
+	SampleRate int     `json:"sample_rate,omitempty"`
+	Channels   int     `json:"channels,omitempty"`
+	BitRate    int64   `json:"bit_rate,omitempty"`
+	Duration   float64 `json:"duration,omitempty"`
+	Language   string  `json:"language,omitempty"`
+}
+
+// MediaInfo is the JSON response for probe_media
+type MediaInfo struct {
+	Path      string        `json:"path"`
+	Format    string        `json:"format"`
+	Duration  float64       `json:"duration"`
+	Size      int64         `json:"size"`
+	BitRate   int64         `json:"bit_rate"`
+	Streams   []MediaStream `json:"streams"`
+	Thumbnail string        `json:"thumbnail,omitempty"`
+}
+
+// ffprobeOutput mirrors the subset of `ffprobe -print_format json` used here
+type ffprobeOutput struct {
+	Streams []struct {
+		Index         int               `json:"index"`
+		CodecType     string            `json:"codec_type"`
+		CodecName     string            `json:"codec_name"`
+		CodecLongName string            `json:"codec_long_name"`
+		Width         int               `json:"width"`
+		Height        int               `json:"height"`
+		AvgFrameRate  string            `json:"avg_frame_rate"`
+		SampleRate    string            `json:"sample_rate"`
+		Channels      int               `json:"channels"`
+		BitRate       string            `json:"bit_rate"`
+		Duration      string            `json:"duration"`
+		Tags          map[string]string `json:"tags"`
+	} `json:"streams"`
+	Format struct {
+		FormatName string `json:"format_name"`
+		Duration   string `json:"duration"`
+		Size       string `json:"size"`
+		BitRate    string `json:"bit_rate"`
+	} `json:"format"`
+}
+
+// HandleProbeMedia handles the probe_media tool
+func (fs *FilesystemHandler) HandleProbeMedia(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return errorResult(toolError(CodeInvalidArgument, "path is required")), nil
+	}
+
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("path validation failed: %w", err)), nil
+	}
+	if info, err := os.Stat(validPath); err != nil {
+		return errorResult(fmt.Errorf("failed to get file info: %w", err)), nil
+	} else if info.IsDir() {
+		return errorResult(toolError(CodeWrongType, "path must be a file")), nil
+	}
+
+	media, err := probeMedia(ctx, validPath)
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	thumbnail := false
+	if val, err := request.RequireBool("thumbnail"); err == nil {
+		thumbnail = val
+	}
+	if !thumbnail {
+		return fs.mediaResult(media, nil)
+	}
+
+	hasVideo := false
+	for _, stream := range media.Streams {
+		if stream.Type == "video" {
+			hasVideo = true
+			break
+		}
+	}
+	if !hasVideo {
+		return errorResult(toolError(CodeWrongType, "thumbnail requested but the file has no video stream")), nil
+	}
+
+	// Default to 10% into the media so we skip black lead-in frames
+	at := media.Duration / 10
+	if val, err := request.RequireFloat("thumbnail_time"); err == nil && val >= 0 {
+		at = val
+	}
+	width := 320
+	if val, err := request.RequireFloat("thumbnail_width"); err == nil && val > 0 {
+		width = int(val)
+	}
+
+	png, err := extractThumbnail(ctx, validPath, at, width)
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	if thumbPath, _ := request.RequireString("thumbnail_path"); thumbPath != "" {
+		validThumb, err := fs.validateWritablePath(thumbPath)
+		if err != nil {
+			return errorResult(fmt.Errorf("thumbnail path validation failed: %w", err)), nil
+		}
+		if fs.dryRunMode() {
+			result, err := fs.mediaResult(media, nil)
+			if err == nil && !result.IsError {
+				result.Content = append(result.Content, dryRunResult(describeWrite(validThumb, png)...).Content...)
+			}
+			return result, err
+		}
+		if err := os.WriteFile(validThumb, png, 0644); err != nil {
+			return errorResult(fmt.Errorf("failed to write thumbnail: %w", err)), nil
+		}
+		media.Thumbnail = validThumb
+		return fs.mediaResult(media, nil)
+	}
+
+	if int64(len(png)) > fs.currentLimits().MaxBase64Size {
+		return errorResult(toolError(CodeTooLarge, "thumbnail too large to inline (%d bytes); use thumbnail_path", len(png))), nil
+	}
+	return fs.mediaResult(media, png)
+}
+
+// mediaResult renders probe results, optionally with an inline PNG thumbnail
+func (fs *FilesystemHandler) mediaResult(media *MediaInfo, png []byte) (*mcp.CallToolResult, error) {
+	jsonBytes, err := json.MarshalIndent(media, "", "  ")
+	if err != nil {
+		return errorResult(fmt.Errorf("failed to marshal response: %w", err)), nil
+	}
+	result := mcp.NewToolResultText(string(jsonBytes))
+	if png != nil {
+		result.Content = append(result.Content, mcp.ImageContent{
+			Type:     "image",
+			Data:     base64.StdEncoding.EncodeToString(png),
+			MIMEType: "image/png",
+		})
+	}
+	return result, nil
+}
+
+// probeMedia runs ffprobe on path and converts its output into MediaInfo
+func probeMedia(ctx context.Context, path string) (*MediaInfo, error) {
+	runCtx, cancel := context.WithTimeout(ctx, mediaToolTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, ffprobeBinary,
+		"-v", "error",
+		"-print_format", "json",
+		"-show_format",
+		"-show_streams",
+		path,
+	)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, toolError(CodeExternalFailed, "ffprobe failed: %s", msg)
+		}
+		return nil, toolError(CodeExternalFailed, "ffprobe failed: %w", err)
+	}
+
+	var out ffprobeOutput
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return nil, toolError(CodeExternalFailed, "failed to parse ffprobe output: %w", err)
+	}
+
+	media := &MediaInfo{
+		Path:     path,
+		Format:   out.Format.FormatName,
+		Duration: parseFloat(out.Format.Duration),
+		Size:     parseInt(out.Format.Size),
+		BitRate:  parseInt(out.Format.BitRate),
+		Streams:  make([]MediaStream, 0, len(out.Streams)),
+	}
+	for _, s := range out.Streams {
+		stream := MediaStream{
+			Index:      s.Index,
+			Type:       s.CodecType,
+			Codec:      s.CodecName,
+			CodecLong:  s.CodecLongName,
+			Width:      s.Width,
+			Height:     s.Height,
+			SampleRate: int(parseInt(s.SampleRate)),
+			Channels:   s.Channels,
+			BitRate:    parseInt(s.BitRate),
+			Duration:   parseFloat(s.Duration),
+			Language:   s.Tags["language"],
+		}
+		if s.CodecType == "video" && s.AvgFrameRate != "0/0" {
+			stream.FrameRate = s.AvgFrameRate
+		}
+		media.Streams = append(media.Streams, stream)
+	}
+	return media, nil
+}
+
+// extractThumbnail grabs a single frame at the given offset as a PNG scaled to width
+func extractThumbnail(ctx context.Context, path string, at float64, width int) ([]byte, error) {
+	runCtx, cancel := context.WithTimeout(ctx, mediaToolTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, ffmpegBinary,
+		"-v", "error",
+		"-ss", strconv.FormatFloat(at, 'f', 3, 64),
+		"-i", path,
+		"-frames:v", "1",
+		"-vf", fmt.Sprintf("scale=%d:-2", width),
+		"-f", "image2",
+		"-c:v", "png",
+		"-",
+	)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, toolError(CodeExternalFailed, "ffmpeg failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if stdout.Len() == 0 {
+		return nil, toolError(CodeExternalFailed, "ffmpeg produced no frame at %.3fs", at)
+	}
+	return stdout.Bytes(), nil
+}
+
+func parseFloat(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+func parseInt(s string) int64 {
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
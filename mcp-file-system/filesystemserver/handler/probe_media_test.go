@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fakeFFprobeOutput = `{
+  "streams": [
+    {"index": 0, "codec_type": "video", "codec_name": "h264", "width": 1920, "height": 1080, "avg_frame_rate": "30000/1001", "bit_rate": "4500000"},
+    {"index": 1, "codec_type": "audio", "codec_name": "aac", "sample_rate": "48000", "channels": 2, "avg_frame_rate": "0/0", "tags": {"language": "eng"}}
+  ],
+  "format": {"format_name": "mov,mp4,m4a,3gp,3g2,mj2", "duration": "12.500000", "size": "7340032", "bit_rate": "4697620"}
+}`
+
+// fakeMediaTools replaces ffprobe and ffmpeg with shell scripts
+func fakeMediaTools(t *testing.T) {
+	t.Helper()
+	bin := t.TempDir()
+	ffprobe := filepath.Join(bin, "ffprobe")
+	require.NoError(t, os.WriteFile(ffprobe, []byte("#!/bin/sh\ncat <<'JSON'\n"+fakeFFprobeOutput+"\nJSON\n"), 0755))
+	ffmpeg := filepath.Join(bin, "ffmpeg")
+	require.NoError(t, os.WriteFile(ffmpeg, []byte("#!/bin/sh\nprintf 'PNGDATA'\n"), 0755))
+
+	oldProbe, oldMpeg := ffprobeBinary, ffmpegBinary
+	ffprobeBinary, ffmpegBinary = ffprobe, ffmpeg
+	t.Cleanup(func() { ffprobeBinary, ffmpegBinary = oldProbe, oldMpeg })
+}
+
+func TestHandleProbeMedia(t *testing.T) {
+	fakeMediaTools(t)
+	dir := t.TempDir()
+	video := filepath.Join(dir, "clip.mp4")
+	require.NoError(t, os.WriteFile(video, []byte("not really a video"), 0644))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	t.Run("metadata", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"path": video}
+		result, err := handler.HandleProbeMedia(context.Background(), request)
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+		require.Len(t, result.Content, 1)
+
+		var media MediaInfo
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &media))
+		assert.Equal(t, 12.5, media.Duration)
+		assert.Equal(t, int64(7340032), media.Size)
+		require.Len(t, media.Streams, 2)
+		assert.Equal(t, "h264", media.Streams[0].Codec)
+		assert.Equal(t, 1920, media.Streams[0].Width)
+		assert.Equal(t, "30000/1001", media.Streams[0].FrameRate)
+		assert.Equal(t, 48000, media.Streams[1].SampleRate)
+		assert.Equal(t, "eng", media.Streams[1].Language)
+		assert.Empty(t, media.Streams[1].FrameRate)
+	})
+
+	t.Run("inline thumbnail", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"path": video, "thumbnail": true}
+		result, err := handler.HandleProbeMedia(context.Background(), request)
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+		require.Len(t, result.Content, 2)
+		image := result.Content[1].(mcp.ImageContent)
+		assert.Equal(t, "image/png", image.MIMEType)
+		assert.Equal(t, "UE5HREFUQQ==", image.Data)
+	})
+
+	t.Run("thumbnail to file", func(t *testing.T) {
+		thumb := filepath.Join(dir, "clip.png")
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"path": video, "thumbnail": true, "thumbnail_path": thumb}
+		result, err := handler.HandleProbeMedia(context.Background(), request)
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+		data, err := os.ReadFile(thumb)
+		require.NoError(t, err)
+		assert.Equal(t, "PNGDATA", string(data))
+	})
+
+	t.Run("outside allowed directories", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"path": "/etc/hostname"}
+		result, err := handler.HandleProbeMedia(context.Background(), request)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+	})
+}
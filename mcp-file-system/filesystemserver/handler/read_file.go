@@ -5,6 +5,8 @@ import (
 	"encoding/base64"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -18,49 +20,60 @@ func (fs *FilesystemHandler) HandleReadFile(
 		return nil, err
 	}
 
+	// Optional syntax highlighting for text files
+	highlight, _ := request.RequireString("highlight")
+	highlightStyle, _ := request.RequireString("highlight_style")
+
+	// Optionally prefix each line of text with its number
+	lineNumbers := request.GetBool("line_numbers", false)
+
+	// A named encoding reads the file as text whatever its detected type
+	textEncoding := request.GetString("encoding", EncodingAuto)
+	forceText := !strings.EqualFold(textEncoding, EncodingAuto)
+	if forceText {
+		if _, err := lookupEncoding(textEncoding); err != nil {
+			return errorResult(err), nil
+		}
+	}
+
+	// Optionally return the raw bytes base64 encoded, whatever the file type
+	asBase64 := request.GetBool("as_base64", false)
+	if asBase64 && (forceText || lineNumbers || highlight != "") {
+		return errorResult(toolError(CodeInvalidArgument, "Error: as_base64 cannot be combined with encoding, line_numbers or highlight")), nil
+	}
+
+	// Optionally read only a byte range or a line range
+	rng, err := parseReadRange(request)
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	if isSMBPath(path) {
+		if rng != nil {
+			return errorResult(toolError(CodeInvalidArgument, "Error: offset, length, start_line and end_line are not supported on SMB shares")), nil
+		}
+		return fs.smbReadFile(ctx, path, lineNumbers, asBase64)
+	}
+
 	// Handle empty or relative paths like "." or "./" by converting to absolute path
 	if path == "." || path == "./" {
 		// Get current working directory
 		cwd, err := os.Getwd()
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					mcp.TextContent{
-						Type: "text",
-						Text: fmt.Sprintf("Error resolving current directory: %v", err),
-					},
-				},
-				IsError: true,
-			}, nil
+			return errorResult(fmt.Errorf("Error resolving current directory: %w", err)), nil
 		}
 		path = cwd
 	}
 
 	validPath, err := fs.validatePath(path)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
 	}
 
 	// Check if it's a directory
 	info, err := os.Stat(validPath)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
 	}
 
 	if info.IsDir() {
@@ -87,8 +100,31 @@ func (fs *FilesystemHandler) HandleReadFile(
 	// Determine MIME type
 	mimeType := detectMimeType(validPath)
 
+	if rng != nil {
+		return fs.readFileRange(validPath, info, mimeType, rng, readOptions{
+			encoding:       textEncoding,
+			forceText:      forceText,
+			lineNumbers:    lineNumbers,
+			highlight:      highlight,
+			highlightStyle: highlightStyle,
+			asBase64:       asBase64,
+		})
+	}
+
+	if asBase64 {
+		if info.Size() > fs.currentLimits().MaxBase64Size {
+			return errorResult(toolError(CodeTooLarge, "Error: %s is too large to return as base64 (%d bytes, limit %d); read it in parts with offset and length", path, info.Size(), fs.currentLimits().MaxBase64Size).
+				With("path", path).With("size", info.Size()).With("limit", fs.currentLimits().MaxBase64Size)), nil
+		}
+		content, err := os.ReadFile(validPath)
+		if err != nil {
+			return errorResult(fmt.Errorf("Error reading file: %w", err)), nil
+		}
+		return base64Result(validPath, pathToResourceURI(validPath), detectMimeTypeFromContent(validPath, content), content), nil
+	}
+
 	// Check file size
-	if info.Size() > MAX_INLINE_SIZE {
+	if info.Size() > fs.currentLimits().MaxInlineSize {
 		// File is too large to inline, return a resource reference
 		resourceURI := pathToResourceURI(validPath)
 		return &mcp.CallToolResult{
@@ -112,31 +148,46 @@ func (fs *FilesystemHandler) HandleReadFile(
 	// Read file content
 	content, err := os.ReadFile(validPath)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error reading file: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error reading file: %w", err)), nil
 	}
 
 	// Check if it's a text file
-	if isTextFile(mimeType) {
-		// It's a text file, return as text
-		return &mcp.CallToolResult{
+	if isTextFile(mimeType) || forceText {
+		// It's a text file, return it decoded as text
+		text, _, err := decodeText(content, textEncoding)
+		if err != nil {
+			return errorResult(err), nil
+		}
+		shown := text
+		if lineNumbers {
+			shown = numberLines(text, 1)
+		}
+		result := &mcp.CallToolResult{
 			Content: []mcp.Content{
 				mcp.TextContent{
 					Type: "text",
-					Text: string(content),
+					Text: shown,
 				},
 			},
-		}, nil
+		}
+		if highlight != "" {
+			hlMime, hlText, err := highlightCode(validPath, text, highlight, highlightStyle, 1)
+			if err != nil {
+				return errorResult(fmt.Errorf("Error highlighting file: %w", err)), nil
+			}
+			result.Content = append(result.Content, mcp.EmbeddedResource{
+				Type: "resource",
+				Resource: mcp.TextResourceContents{
+					URI:      pathToResourceURI(validPath),
+					MIMEType: hlMime,
+					Text:     hlText,
+				},
+			})
+		}
+		return result, nil
 	} else if isImageFile(mimeType) {
 		// It's an image file, return as image content
-		if info.Size() <= MAX_BASE64_SIZE {
+		if info.Size() <= fs.currentLimits().MaxBase64Size {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
 					mcp.TextContent{
@@ -174,7 +225,7 @@ func (fs *FilesystemHandler) HandleReadFile(
 		// It's another type of binary file
 		resourceURI := pathToResourceURI(validPath)
 
-		if info.Size() <= MAX_BASE64_SIZE {
+		if info.Size() <= fs.currentLimits().MaxBase64Size {
 			// Small enough for base64 encoding
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
@@ -212,4 +263,45 @@ func (fs *FilesystemHandler) HandleReadFile(
 			}, nil
 		}
 	}
-}
\ No newline at end of file
+}
+
+// numberLines prefixes each line of text with its number, counting from
+// first, right-aligned and followed by a tab like cat -n. A final newline
+// does not start another line.
+func numberLines(text string, first int) string {
+	if text == "" {
+		return ""
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	width := len(strconv.Itoa(first + len(lines) - 1))
+	var sb strings.Builder
+	sb.Grow(len(text) + len(lines)*(width+1))
+	for i, line := range lines {
+		sb.WriteString(fmt.Sprintf("%*d\t%s", width, first+i, line))
+	}
+	return sb.String()
+}
+
+// base64Result returns the raw content of a file as a base64 blob, with
+// its detected MIME type
+func base64Result(path, uri, mimeType string, content []byte) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: fmt.Sprintf("Base64 content of %s (%s, %d bytes)", path, mimeType, len(content)),
+			},
+			mcp.EmbeddedResource{
+				Type: "resource",
+				Resource: mcp.BlobResourceContents{
+					URI:      uri,
+					MIMEType: mimeType,
+					Blob:     base64.StdEncoding.EncodeToString(content),
+				},
+			},
+		},
+	}
+}
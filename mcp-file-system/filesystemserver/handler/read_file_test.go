@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -68,3 +69,189 @@ func TestReadfile_NoAccess(t *testing.T) {
 	assert.True(t, result.IsError)
 	assert.Contains(t, fmt.Sprint(result.Content[0]), "access denied - path outside allowed directories")
 }
+
+func TestReadfile_Highlight(t *testing.T) {
+	dir := t.TempDir()
+	source := "package main\n\nfunc main() {}\n"
+	err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(source), 0644)
+	require.NoError(t, err)
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	t.Run("html", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{
+			"path":      filepath.Join(dir, "main.go"),
+			"highlight": "html",
+		}
+		result, err := handler.HandleReadFile(context.Background(), request)
+		require.NoError(t, err)
+		require.Len(t, result.Content, 2)
+		assert.Equal(t, source, result.Content[0].(mcp.TextContent).Text)
+		resource := result.Content[1].(mcp.EmbeddedResource).Resource.(mcp.TextResourceContents)
+		assert.Equal(t, "text/html", resource.MIMEType)
+		assert.Contains(t, resource.Text, "<span")
+	})
+
+	t.Run("tokens", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{
+			"path":      filepath.Join(dir, "main.go"),
+			"highlight": "tokens",
+		}
+		result, err := handler.HandleReadFile(context.Background(), request)
+		require.NoError(t, err)
+		require.Len(t, result.Content, 2)
+		resource := result.Content[1].(mcp.EmbeddedResource).Resource.(mcp.TextResourceContents)
+		assert.Contains(t, resource.Text, `"language":"Go"`)
+		assert.Contains(t, resource.Text, `"type":"KeywordNamespace","value":"package"`)
+	})
+
+	t.Run("invalid mode", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{
+			"path":      filepath.Join(dir, "main.go"),
+			"highlight": "ansi",
+		}
+		result, err := handler.HandleReadFile(context.Background(), request)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+	})
+}
+
+func TestReadFileEncoding(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	gbk := filepath.Join(dir, "gbk.txt")
+	require.NoError(t, os.WriteFile(gbk, []byte{0xc4, 0xe3, 0xba, 0xc3, 0x0a}, 0644)) // "你好\n"
+	latin := filepath.Join(dir, "latin.txt")
+	require.NoError(t, os.WriteFile(latin, []byte("caf\xe9 cr\xe8me\n"), 0644))
+	utf16 := filepath.Join(dir, "utf16.txt")
+	require.NoError(t, os.WriteFile(utf16, []byte{0xff, 0xfe, 'h', 0, 'i', 0}, 0644))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	read := func(args map[string]any) string {
+		res := callTool(t, fsHandler.HandleReadFile, args)
+		require.False(t, res.IsError, res.Content)
+		return res.Content[0].(mcp.TextContent).Text
+	}
+	assert.Equal(t, "你好\n", read(map[string]any{"path": gbk, "encoding": "gbk"}))
+	assert.Equal(t, "café crème\n", read(map[string]any{"path": latin, "encoding": "latin-1"}))
+	assert.Equal(t, "café crème\n", read(map[string]any{"path": latin}))
+	assert.Equal(t, "hi", read(map[string]any{"path": utf16}))
+
+	res := callTool(t, fsHandler.HandleReadFile, map[string]any{"path": gbk, "encoding": "klingon"})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+}
+
+func TestReadFileLineNumbers(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	var content string
+	for i := 1; i <= 10; i++ {
+		content += fmt.Sprintf("line %d\n", i)
+	}
+	file := filepath.Join(dir, "notes.txt")
+	require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	res := callTool(t, fsHandler.HandleReadFile, map[string]any{"path": file, "line_numbers": true})
+	require.False(t, res.IsError, res.Content)
+	text := res.Content[0].(mcp.TextContent).Text
+	assert.True(t, strings.HasPrefix(text, " 1\tline 1\n 2\tline 2\n"), text)
+	assert.True(t, strings.HasSuffix(text, "10\tline 10\n"), text)
+
+	res = callTool(t, fsHandler.HandleReadFile, map[string]any{"path": file})
+	assert.Equal(t, content, res.Content[0].(mcp.TextContent).Text)
+
+	assert.Equal(t, "41\ta\n42\tb", numberLines("a\nb", 41))
+	assert.Equal(t, "", numberLines("", 1))
+}
+
+func TestReadFileRange(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	var content string
+	for i := 1; i <= 10; i++ {
+		content += fmt.Sprintf("line %d\n", i)
+	}
+	file := filepath.Join(dir, "app.log")
+	require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+	// The file is over the inline limit, but ranges page through it
+	fsHandler, err := NewFilesystemHandler([]string{dir}, WithLimits(Limits{MaxInlineSize: 30}))
+	require.NoError(t, err)
+
+	res := callTool(t, fsHandler.HandleReadFile, map[string]any{"path": file, "start_line": 3, "end_line": 4, "line_numbers": true})
+	require.False(t, res.IsError, res.Content)
+	assert.Equal(t, "3\tline 3\n4\tline 4\n", res.Content[0].(mcp.TextContent).Text)
+	assert.Contains(t, res.Content[1].(mcp.TextContent).Text, "Lines 3-4 of "+file+"; continue with start_line 5")
+
+	// Without end_line, the range stops at the last whole line within the limit
+	res = callTool(t, fsHandler.HandleReadFile, map[string]any{"path": file, "start_line": 8})
+	require.False(t, res.IsError, res.Content)
+	assert.Equal(t, "line 8\nline 9\nline 10\n", res.Content[0].(mcp.TextContent).Text)
+	assert.Contains(t, res.Content[1].(mcp.TextContent).Text, "Lines 8-10 of "+file+"]")
+	res = callTool(t, fsHandler.HandleReadFile, map[string]any{"path": file, "start_line": 1})
+	assert.Equal(t, "line 1\nline 2\nline 3\nline 4\n", res.Content[0].(mcp.TextContent).Text)
+
+	res = callTool(t, fsHandler.HandleReadFile, map[string]any{"path": file, "offset": 7, "length": 7, "line_numbers": true})
+	require.False(t, res.IsError, res.Content)
+	assert.Equal(t, "2\tline 2\n", res.Content[0].(mcp.TextContent).Text)
+	assert.Contains(t, res.Content[1].(mcp.TextContent).Text, "7 bytes from offset 7 of "+file+" (71 bytes); continue with offset 14")
+
+	res = callTool(t, fsHandler.HandleReadFile, map[string]any{"path": file, "offset": 100})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	res = callTool(t, fsHandler.HandleReadFile, map[string]any{"path": file, "offset": 0, "start_line": 2})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	res = callTool(t, fsHandler.HandleReadFile, map[string]any{"path": file, "start_line": 5, "end_line": 4})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+
+	// Characters cut by either edge of a byte range are left out
+	accents := filepath.Join(dir, "accents.txt")
+	require.NoError(t, os.WriteFile(accents, []byte("aéééé\n"), 0644))
+	res = callTool(t, fsHandler.HandleReadFile, map[string]any{"path": accents, "offset": 2, "length": 4})
+	require.False(t, res.IsError, res.Content)
+	assert.Equal(t, "é", res.Content[0].(mcp.TextContent).Text)
+	assert.Contains(t, res.Content[1].(mcp.TextContent).Text, "2 bytes from offset 3 of "+accents+" (10 bytes); continue with offset 5")
+
+	// A range without the byte order mark still decodes as UTF-16
+	utf16 := filepath.Join(dir, "utf16.txt")
+	require.NoError(t, os.WriteFile(utf16, []byte{0xff, 0xfe, 'h', 0, 'i', 0, '!', 0}, 0644))
+	res = callTool(t, fsHandler.HandleReadFile, map[string]any{"path": utf16, "offset": 3, "length": 4})
+	require.False(t, res.IsError, res.Content)
+	assert.Equal(t, "i", res.Content[0].(mcp.TextContent).Text)
+	assert.Contains(t, res.Content[1].(mcp.TextContent).Text, "2 bytes from offset 4 of "+utf16)
+
+	binary := filepath.Join(dir, "blob.bin")
+	require.NoError(t, os.WriteFile(binary, []byte{0, 1, 2, 3, 4, 5}, 0644))
+	res = callTool(t, fsHandler.HandleReadFile, map[string]any{"path": binary, "start_line": 1})
+	assert.Equal(t, CodeWrongType, ResultError(res).Code)
+	res = callTool(t, fsHandler.HandleReadFile, map[string]any{"path": binary, "offset": 2, "length": 2})
+	require.False(t, res.IsError, res.Content)
+	assert.Equal(t, "AgM=", res.Content[1].(mcp.EmbeddedResource).Resource.(mcp.BlobResourceContents).Blob)
+}
+
+func TestReadFileAsBase64(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	file := filepath.Join(dir, "notes.txt")
+	require.NoError(t, os.WriteFile(file, []byte("hello\r\n"), 0644))
+	fsHandler, err := NewFilesystemHandler([]string{dir}, WithLimits(Limits{MaxBase64Size: 8}))
+	require.NoError(t, err)
+
+	res := callTool(t, fsHandler.HandleReadFile, map[string]any{"path": file, "as_base64": true})
+	require.False(t, res.IsError, res.Content)
+	blob := res.Content[1].(mcp.EmbeddedResource).Resource.(mcp.BlobResourceContents)
+	assert.Equal(t, "aGVsbG8NCg==", blob.Blob)
+	assert.Equal(t, "text/plain; charset=utf-8", blob.MIMEType)
+
+	// Ranges of the raw bytes can be read past the limit
+	require.NoError(t, os.WriteFile(file, []byte("hello, world"), 0644))
+	res = callTool(t, fsHandler.HandleReadFile, map[string]any{"path": file, "as_base64": true})
+	assert.Equal(t, CodeTooLarge, ResultError(res).Code)
+	res = callTool(t, fsHandler.HandleReadFile, map[string]any{"path": file, "as_base64": true, "offset": 7})
+	require.False(t, res.IsError, res.Content)
+	assert.Equal(t, "d29ybGQ=", res.Content[1].(mcp.EmbeddedResource).Resource.(mcp.BlobResourceContents).Blob)
+
+	res = callTool(t, fsHandler.HandleReadFile, map[string]any{"path": file, "as_base64": true, "line_numbers": true})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+}
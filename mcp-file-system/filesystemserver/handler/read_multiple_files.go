@@ -4,8 +4,14 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
 
+	"github.com/gobwas/glob"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -19,43 +25,78 @@ func (fs *FilesystemHandler) HandleReadMultipleFiles(
 	}
 
 	if len(pathsSlice) == 0 {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: "No files specified to read",
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(toolError(CodeInvalidArgument, "No files specified to read")), nil
 	}
 
 	// Maximum number of files to read in a single request
 	const maxFiles = 50
 	if len(pathsSlice) > maxFiles {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Too many files requested. Maximum is %d files per request.", maxFiles),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(toolError(CodeInvalidArgument, "Too many files requested. Maximum is %d files per request.", maxFiles)), nil
+	}
+
+	// Glob patterns are expanded to the files they match
+	pathsSlice, results, err := fs.expandReadPaths(ctx, pathsSlice, maxFiles)
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	// Per-call budgets can only narrow the configured ones
+	limits := fs.currentLimits()
+	perFile, total := limits.MaxInlineSize, limits.MaxBatchReadSize
+	if n := int64(request.GetInt("max_file_size", 0)); n > 0 && n < perFile {
+		perFile = n
+	}
+	if n := int64(request.GetInt("max_total_size", 0)); n > 0 && n < total {
+		total = n
 	}
 
-	// Process each file
-	var results []mcp.Content
-	for _, path := range pathsSlice {
+	reads := fs.planBatchRead(pathsSlice, perFile, total)
+	fs.runBatchRead(ctx, reads)
+	if err := ctx.Err(); err != nil {
+		return errorResult(err), nil
+	}
+	for _, r := range reads {
+		results = append(results, r.content...)
+	}
+
+	return &mcp.CallToolResult{
+		Content: results,
+	}, nil
+}
+
+// batchRead is one file of a read_multiple_files call
+type batchRead struct {
+	path      string
+	validPath string
+	info      os.FileInfo
+	mimeType  string
+	// budget is how much of the file may be returned; a text file larger
+	// than it is cut, other files are only referred to
+	budget int64
+	// content is the result for the file, set by planBatchRead when the
+	// file is not to be read
+	content []mcp.Content
+}
+
+// planBatchRead checks the requested paths in order and shares out the
+// total budget between them, each file getting at most perFile bytes.
+// Planning before reading keeps the result the same however the reads are
+// scheduled.
+func (fs *FilesystemHandler) planBatchRead(paths []string, perFile, total int64) []*batchRead {
+	reads := make([]*batchRead, 0, len(paths))
+	remaining := total
+	for _, path := range paths {
+		r := &batchRead{path: path}
+		reads = append(reads, r)
+		note := func(format string, args ...any) {
+			r.content = []mcp.Content{mcp.TextContent{Type: "text", Text: fmt.Sprintf(format, args...)}}
+		}
+
 		// Handle empty or relative paths like "." or "./" by converting to absolute path
 		if path == "." || path == "./" {
-			// Get current working directory
 			cwd, err := os.Getwd()
 			if err != nil {
-				results = append(results, mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error resolving current directory for path '%s': %v", path, err),
-				})
+				note("Error resolving current directory for path '%s': %v", path, err)
 				continue
 			}
 			path = cwd
@@ -63,122 +104,285 @@ func (fs *FilesystemHandler) HandleReadMultipleFiles(
 
 		validPath, err := fs.validatePath(path)
 		if err != nil {
-			results = append(results, mcp.TextContent{
-				Type: "text",
-				Text: fmt.Sprintf("Error with path '%s': %v", path, err),
-			})
+			note("Error with path '%s': %v", path, err)
 			continue
 		}
-
-		// Check if it's a directory
 		info, err := os.Stat(validPath)
 		if err != nil {
-			results = append(results, mcp.TextContent{
-				Type: "text",
-				Text: fmt.Sprintf("Error accessing '%s': %v", path, err),
-			})
+			note("Error accessing '%s': %v", path, err)
 			continue
 		}
-
+		resourceURI := pathToResourceURI(validPath)
 		if info.IsDir() {
 			// For directories, return a resource reference instead
-			resourceURI := pathToResourceURI(validPath)
-			results = append(results, mcp.TextContent{
-				Type: "text",
-				Text: fmt.Sprintf("'%s' is a directory. Use list_directory tool or resource URI: %s", path, resourceURI),
-			})
+			note("'%s' is a directory. Use list_directory tool or resource URI: %s", path, resourceURI)
 			continue
 		}
+		r.validPath, r.info = validPath, info
+		r.mimeType = detectMimeType(validPath)
 
-		// Determine MIME type
-		mimeType := detectMimeType(validPath)
-
-		// Check file size
-		if info.Size() > MAX_INLINE_SIZE {
-			// File is too large to inline, return a resource reference
-			resourceURI := pathToResourceURI(validPath)
-			results = append(results, mcp.TextContent{
-				Type: "text",
-				Text: fmt.Sprintf("File '%s' is too large to display inline (%d bytes). Access it via resource URI: %s",
-					path, info.Size(), resourceURI),
-			})
+		if isTextFile(r.mimeType) {
+			// Text is cut to fit, as long as some of the budget is left
+			r.budget = min64(info.Size(), perFile, remaining)
+			if r.budget == 0 && info.Size() > 0 {
+				note("File '%s' (%d bytes) was not read: the total size limit of %d bytes for this call was reached. Read it separately, or via resource URI: %s",
+					path, info.Size(), total, resourceURI)
+				continue
+			}
+			remaining -= r.budget
 			continue
 		}
 
-		// Read file content
-		content, err := os.ReadFile(validPath)
-		if err != nil {
-			results = append(results, mcp.TextContent{
-				Type: "text",
-				Text: fmt.Sprintf("Error reading file '%s': %v", path, err),
-			})
+		// Binary files are returned whole or not at all
+		limit := min64(perFile, fs.currentLimits().MaxBase64Size)
+		switch {
+		case info.Size() > limit && isImageFile(r.mimeType):
+			note("Image file '%s' is too large to display inline (%d bytes). Access it via resource URI: %s", path, info.Size(), resourceURI)
+		case info.Size() > limit:
+			note("Binary file '%s' (%s, %d bytes). Access it via resource URI: %s", path, r.mimeType, info.Size(), resourceURI)
+		case info.Size() > remaining:
+			note("File '%s' (%s, %d bytes) was not read: the total size limit of %d bytes for this call was reached. Read it separately, or via resource URI: %s",
+				path, r.mimeType, info.Size(), total, resourceURI)
+		default:
+			r.budget = info.Size()
+			remaining -= r.budget
+		}
+	}
+	return reads
+}
+
+// min64 returns the smallest of its arguments
+func min64(first int64, rest ...int64) int64 {
+	for _, n := range rest {
+		if n < first {
+			first = n
+		}
+	}
+	return first
+}
+
+// runBatchRead reads the planned files, up to tuning.WalkerParallelism at
+// once, until ctx is done
+func (fs *FilesystemHandler) runBatchRead(ctx context.Context, reads []*batchRead) {
+	jobs := make(chan *batchRead)
+	var wg sync.WaitGroup
+	for range max(fs.tuning.WalkerParallelism, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range jobs {
+				r.content = r.read()
+			}
+		}()
+	}
+	for _, r := range reads {
+		if r.content != nil {
 			continue
 		}
+		select {
+		case jobs <- r:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
 
-		// Add file header
-		results = append(results, mcp.TextContent{
-			Type: "text",
-			Text: fmt.Sprintf("--- File: %s ---", path),
-		})
+// read reads the file within its budget and renders it
+func (r *batchRead) read() []mcp.Content {
+	content, err := readPrefix(r.validPath, r.budget)
+	if err != nil {
+		return []mcp.Content{mcp.TextContent{Type: "text", Text: fmt.Sprintf("Error reading file '%s': %v", r.path, err)}}
+	}
 
-		// Check if it's a text file
-		if isTextFile(mimeType) {
-			// It's a text file, return as text
-			results = append(results, mcp.TextContent{
-				Type: "text",
-				Text: string(content),
-			})
-		} else if isImageFile(mimeType) {
-			// It's an image file, return as image content
-			if info.Size() <= MAX_BASE64_SIZE {
-				results = append(results, mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Image file: %s (%s, %d bytes)", path, mimeType, info.Size()),
-				})
-				results = append(results, mcp.ImageContent{
-					Type:     "image",
-					Data:     base64.StdEncoding.EncodeToString(content),
-					MIMEType: mimeType,
-				})
+	// Add file header
+	results := []mcp.Content{mcp.TextContent{Type: "text", Text: fmt.Sprintf("--- File: %s ---", r.path)}}
+	size := r.info.Size()
+	switch {
+	case isTextFile(r.mimeType):
+		text := string(content)
+		if int64(len(content)) < size {
+			// Cut at the last line break, or at least not within a character
+			if i := strings.LastIndexByte(text, '\n'); i > 0 {
+				text = text[:i+1]
 			} else {
-				// Too large for base64, return a reference
-				resourceURI := pathToResourceURI(validPath)
-				results = append(results, mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Image file '%s' is too large to display inline (%d bytes). Access it via resource URI: %s",
-						path, info.Size(), resourceURI),
-				})
+				for i := 1; i <= utf8.UTFMax && i <= len(text); i++ {
+					if utf8.RuneStart(text[len(text)-i]) {
+						if !utf8.FullRuneInString(text[len(text)-i:]) {
+							text = text[:len(text)-i]
+						}
+						break
+					}
+				}
 			}
-		} else {
-			// It's another type of binary file
-			resourceURI := pathToResourceURI(validPath)
-
-			if info.Size() <= MAX_BASE64_SIZE {
-				// Small enough for base64 encoding
-				results = append(results, mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Binary file: %s (%s, %d bytes)", path, mimeType, info.Size()),
-				})
-				results = append(results, mcp.EmbeddedResource{
-					Type: "resource",
-					Resource: mcp.BlobResourceContents{
-						URI:      resourceURI,
-						MIMEType: mimeType,
-						Blob:     base64.StdEncoding.EncodeToString(content),
-					},
-				})
-			} else {
-				// Too large for base64, return a reference
-				results = append(results, mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Binary file '%s' (%s, %d bytes). Access it via resource URI: %s",
-						path, mimeType, info.Size(), resourceURI),
-				})
+		}
+		results = append(results, mcp.TextContent{Type: "text", Text: text})
+		if n := int64(len(text)); n < size {
+			results = append(results, mcp.TextContent{Type: "text", Text: fmt.Sprintf(
+				"[Truncated: showing %d of %d bytes of %s; continue with read_file offset %d]", n, size, r.path, n)})
+		}
+	case isImageFile(r.mimeType):
+		results = append(results,
+			mcp.TextContent{Type: "text", Text: fmt.Sprintf("Image file: %s (%s, %d bytes)", r.path, r.mimeType, size)},
+			mcp.ImageContent{Type: "image", Data: base64.StdEncoding.EncodeToString(content), MIMEType: r.mimeType},
+		)
+	default:
+		results = append(results,
+			mcp.TextContent{Type: "text", Text: fmt.Sprintf("Binary file: %s (%s, %d bytes)", r.path, r.mimeType, size)},
+			mcp.EmbeddedResource{
+				Type: "resource",
+				Resource: mcp.BlobResourceContents{
+					URI:      pathToResourceURI(r.validPath),
+					MIMEType: r.mimeType,
+					Blob:     base64.StdEncoding.EncodeToString(content),
+				},
+			},
+		)
+	}
+	return results
+}
+
+// readPrefix reads up to n bytes from the start of the file at path
+func readPrefix(path string, n int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, n))
+}
+
+// globMeta are the characters that make a path a glob pattern
+const globMeta = "*?[{"
+
+// expandReadPaths expands the glob patterns among paths, such as
+// src/**/*.go, to the files they match within the allowed directories.
+// Paths that exist are taken literally. At most maxFiles paths are
+// returned; the notes tell about patterns that matched nothing or were cut.
+func (fs *FilesystemHandler) expandReadPaths(ctx context.Context, paths []string, maxFiles int) ([]string, []mcp.Content, error) {
+	var expanded []string
+	var notes []mcp.Content
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			expanded = append(expanded, path)
+		}
+	}
+	for _, path := range paths {
+		if !strings.ContainsAny(path, globMeta) || isSMBPath(path) {
+			add(path)
+			continue
+		}
+		if _, err := os.Lstat(path); err == nil {
+			add(path)
+			continue
+		}
+		root, pattern := splitGlobPath(path)
+		validRoot, err := fs.validatePath(root)
+		if err != nil {
+			notes = append(notes, mcp.TextContent{Type: "text", Text: fmt.Sprintf("Error with pattern '%s': %v", path, err)})
+			continue
+		}
+		match, err := compileReadGlob(pattern)
+		if err != nil {
+			return nil, nil, toolError(CodeInvalidArgument, "Error: invalid glob %q: %w", path, err).With("path", path)
+		}
+
+		matched, cut := 0, false
+		err = filepath.WalkDir(validRoot, func(p string, d os.DirEntry, err error) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				// Saved versions and trashed files are not part of the tree
+				if p != validRoot && (fs.isInVersions(p) || fs.isInTrash(p)) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			rel, err := filepath.Rel(validRoot, p)
+			if err != nil || !match.Match(filepath.ToSlash(rel)) || seen[p] {
+				return nil
+			}
+			if _, err := fs.validatePath(p); err != nil {
+				return nil
 			}
+			if len(expanded) >= maxFiles {
+				cut = true
+				return filepath.SkipAll
+			}
+			add(p)
+			matched++
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
 		}
+		switch {
+		case cut:
+			notes = append(notes, mcp.TextContent{Type: "text", Text: fmt.Sprintf(
+				"Pattern '%s' matched more files than the limit of %d per request; only the first %d matches were read. Use a narrower pattern for the rest.", path, maxFiles, matched)})
+		case matched == 0:
+			notes = append(notes, mcp.TextContent{Type: "text", Text: fmt.Sprintf("Pattern '%s' matched no files", path)})
+		}
+	}
+	if len(expanded) > maxFiles {
+		notes = append(notes, mcp.TextContent{Type: "text", Text: fmt.Sprintf(
+			"%d files were requested, more than the limit of %d per request; only the first %d were read.", len(expanded), maxFiles, maxFiles)})
+		expanded = expanded[:maxFiles]
 	}
+	return expanded, notes, nil
+}
 
-	return &mcp.CallToolResult{
-		Content: results,
-	}, nil
-}
\ No newline at end of file
+// compileReadGlob compiles a slash-separated glob in which ** matches any
+// number of directories, including none, so src/**/*.go matches
+// src/main.go as well as src/cmd/main.go
+func compileReadGlob(pattern string) (glob.Glob, error) {
+	match, err := glob.Compile(pattern, '/')
+	if err != nil {
+		return nil, err
+	}
+	flat := strings.ReplaceAll("/"+pattern, "/**/", "/")[1:]
+	if flat == pattern {
+		return match, nil
+	}
+	flatMatch, err := glob.Compile(flat, '/')
+	if err != nil {
+		return nil, err
+	}
+	return anyGlob{match, flatMatch}, nil
+}
+
+// anyGlob matches what any of its globs matches
+type anyGlob []glob.Glob
+
+func (g anyGlob) Match(s string) bool {
+	for _, m := range g {
+		if m.Match(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitGlobPath splits a glob pattern into the directory before its first
+// wildcard and the slash-separated pattern relative to it
+func splitGlobPath(path string) (string, string) {
+	meta := strings.IndexAny(path, globMeta)
+	sep := strings.LastIndexAny(path[:meta], `/`+string(filepath.Separator))
+	if sep < 0 {
+		return ".", filepath.ToSlash(path)
+	}
+	root := path[:sep]
+	if root == "" || strings.HasSuffix(root, ":") {
+		root += string(filepath.Separator)
+	}
+	return root, filepath.ToSlash(path[sep+1:])
+}
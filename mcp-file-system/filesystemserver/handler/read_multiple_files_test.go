@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -239,3 +240,81 @@ func TestHandleReadMultipleFiles(t *testing.T) {
 		assert.Contains(t, textContent.Text, otherFile)
 	})
 }
+
+func TestReadMultipleFilesGlob(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	for _, name := range []string{"src/main.go", "src/cmd/tool.go", "src/README.md", "other.go"} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("content of "+name), 0644))
+	}
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	texts := func(res *mcp.CallToolResult) string {
+		var parts []string
+		for _, c := range res.Content {
+			if text, ok := c.(mcp.TextContent); ok {
+				parts = append(parts, text.Text)
+			}
+		}
+		return strings.Join(parts, "\n")
+	}
+
+	res := callTool(t, fsHandler.HandleReadMultipleFiles, map[string]any{"paths": []any{
+		filepath.Join(dir, "src", "**", "*.go"),
+		filepath.Join(dir, "src", "main.go"),
+		filepath.Join(dir, "*.txt"),
+	}})
+	require.False(t, res.IsError)
+	out := texts(res)
+	assert.Contains(t, out, "content of src/main.go")
+	assert.Contains(t, out, "content of src/cmd/tool.go")
+	assert.NotContains(t, out, "content of other.go")
+	assert.NotContains(t, out, "README")
+	assert.Equal(t, 1, strings.Count(out, "content of src/main.go"), "files are read once")
+	assert.Contains(t, out, "matched no files")
+
+	for i := 0; i < 60; i++ {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("log%02d.log", i)), []byte("x"), 0644))
+	}
+	res = callTool(t, fsHandler.HandleReadMultipleFiles, map[string]any{"paths": []any{filepath.Join(dir, "*.log")}})
+	require.False(t, res.IsError)
+	out = texts(res)
+	assert.Contains(t, out, "only the first 50 matches were read")
+	assert.Equal(t, 50, strings.Count(out, "--- File: "))
+}
+
+func TestReadMultipleFilesBudgets(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	long := strings.Repeat("0123456789\n", 10) // 110 bytes
+	var paths []any
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(long), 0644))
+		paths = append(paths, filepath.Join(dir, name))
+	}
+	fsHandler, err := NewFilesystemHandler([]string{dir}, WithTuning(Tuning{WalkerParallelism: 3}))
+	require.NoError(t, err)
+
+	res := callTool(t, fsHandler.HandleReadMultipleFiles, map[string]any{"paths": paths, "max_file_size": 50, "max_total_size": 120})
+	require.False(t, res.IsError)
+	var texts []string
+	for _, c := range res.Content {
+		texts = append(texts, c.(mcp.TextContent).Text)
+	}
+	require.Len(t, texts, 9)
+
+	// a.txt gets 50 bytes, cut back to the last whole line
+	assert.Equal(t, "--- File: "+paths[0].(string)+" ---", texts[0])
+	assert.Equal(t, strings.Repeat("0123456789\n", 4), texts[1])
+	assert.Equal(t, "[Truncated: showing 44 of 110 bytes of "+paths[0].(string)+"; continue with read_file offset 44]", texts[2])
+	// b.txt gets the next 50, and c.txt the 20 left
+	assert.Equal(t, "--- File: "+paths[1].(string)+" ---", texts[3])
+	assert.Equal(t, "--- File: "+paths[2].(string)+" ---", texts[6])
+	assert.Equal(t, "0123456789\n", texts[7])
+	assert.Contains(t, texts[8], "showing 11 of 110 bytes")
+
+	res = callTool(t, fsHandler.HandleReadMultipleFiles, map[string]any{"paths": paths, "max_total_size": 200})
+	require.False(t, res.IsError)
+	last := res.Content[len(res.Content)-1].(mcp.TextContent).Text
+	assert.Contains(t, last, "was not read: the total size limit of 200 bytes")
+}
@@ -0,0 +1,330 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// readRange is the part of a file read_file was asked for: either a byte
+// range or a line range
+type readRange struct {
+	// offset and length select bytes; a length of -1 reads to the end
+	offset, length int64
+	// startLine and endLine select lines, counting from 1 and inclusive; an
+	// endLine of 0 reads to the end
+	startLine, endLine int
+}
+
+// byLines reports whether the range selects lines rather than bytes
+func (r *readRange) byLines() bool {
+	return r.startLine > 0
+}
+
+// parseReadRange reads the offset, length, start_line and end_line
+// arguments of read_file. It returns nil when none is given.
+func parseReadRange(request mcp.CallToolRequest) (*readRange, error) {
+	args := request.GetArguments()
+	_, hasOffset := args["offset"]
+	_, hasLength := args["length"]
+	_, hasStart := args["start_line"]
+	_, hasEnd := args["end_line"]
+	switch {
+	case !hasOffset && !hasLength && !hasStart && !hasEnd:
+		return nil, nil
+	case (hasOffset || hasLength) && (hasStart || hasEnd):
+		return nil, toolError(CodeInvalidArgument, "Error: offset/length and start_line/end_line cannot be combined")
+	case hasOffset || hasLength:
+		r := &readRange{offset: int64(request.GetInt("offset", 0)), length: int64(request.GetInt("length", -1))}
+		if r.offset < 0 {
+			return nil, toolError(CodeInvalidArgument, "Error: offset must not be negative").With("offset", r.offset)
+		}
+		if hasLength && r.length < 1 {
+			return nil, toolError(CodeInvalidArgument, "Error: length must be at least 1").With("length", r.length)
+		}
+		return r, nil
+	default:
+		r := &readRange{startLine: request.GetInt("start_line", 1), endLine: request.GetInt("end_line", 0)}
+		if r.startLine < 1 {
+			return nil, toolError(CodeInvalidArgument, "Error: start_line must be at least 1").With("start_line", r.startLine)
+		}
+		if hasEnd && r.endLine < r.startLine {
+			return nil, toolError(CodeInvalidArgument, "Error: end_line must not be before start_line").With("end_line", r.endLine)
+		}
+		return r, nil
+	}
+}
+
+// rangePart is what was read of a range
+type rangePart struct {
+	content []byte
+	// offset is where content starts in the file, and firstLine the line
+	// it starts on (0 when not counted)
+	offset    int64
+	firstLine int
+	// lines counts the lines read by a line range
+	lines int
+	// more is set when the file continues past content, and cut when a
+	// line range stopped within a line longer than the limit
+	more, cut bool
+}
+
+// readPart reads the range of the file at path, streaming it so
+// large files are never loaded whole. At most limit bytes are returned;
+// a line range stops before the line that would exceed it. With
+// countLines, the line a byte range starts on is counted.
+func readPart(path string, size int64, r *readRange, limit int64, countLines bool) (*rangePart, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if r.byLines() {
+		return readLineRange(f, r, limit)
+	}
+
+	if r.offset > size {
+		return nil, toolError(CodeInvalidArgument, "Error: offset %d is past the end of the file (%d bytes)", r.offset, size).With("offset", r.offset).With("size", size)
+	}
+	part := &rangePart{offset: r.offset}
+	if countLines {
+		newlines, err := countNewlines(io.LimitReader(f, r.offset))
+		if err != nil {
+			return nil, err
+		}
+		part.firstLine = newlines + 1
+	}
+	if _, err := f.Seek(r.offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	length := size - r.offset
+	if r.length >= 0 && r.length < length {
+		length = r.length
+	}
+	if length > limit {
+		length = limit
+	}
+	part.content = make([]byte, length)
+	n, err := io.ReadFull(f, part.content)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	part.content = part.content[:n]
+	part.more = r.offset+int64(n) < size
+	return part, nil
+}
+
+// readLineRange reads a line range from f
+func readLineRange(f *os.File, r *readRange, limit int64) (*rangePart, error) {
+	reader := bufio.NewReader(f)
+	part := &rangePart{firstLine: r.startLine}
+	var buf bytes.Buffer
+	for line := 1; r.endLine == 0 || line <= r.endLine; line++ {
+		text, err := reader.ReadBytes('\n')
+		if len(text) == 0 && err == io.EOF {
+			return finishLineRange(part, &buf), nil
+		}
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if line < r.startLine {
+			part.offset += int64(len(text))
+			continue
+		}
+		if int64(buf.Len()+len(text)) > limit {
+			if part.lines == 0 {
+				// A single line over the limit is cut rather than skipped
+				buf.Write(text[:limit])
+				part.lines = 1
+				part.cut = true
+			}
+			part.more = true
+			return finishLineRange(part, &buf), nil
+		}
+		buf.Write(text)
+		part.lines++
+		if err == io.EOF {
+			return finishLineRange(part, &buf), nil
+		}
+	}
+	_, err := reader.Peek(1)
+	part.more = err == nil
+	return finishLineRange(part, &buf), nil
+}
+
+// finishLineRange stores the lines read in part
+func finishLineRange(part *rangePart, buf *bytes.Buffer) *rangePart {
+	part.content = buf.Bytes()
+	return part
+}
+
+// countNewlines counts the newlines read from r
+func countNewlines(r io.Reader) (int, error) {
+	buf := make([]byte, 64*1024)
+	count := 0
+	for {
+		n, err := r.Read(buf)
+		count += bytes.Count(buf[:n], []byte{'\n'})
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+	}
+}
+
+// trimPartialRunes drops the bytes of characters cut by the edges of the
+// part in the named encoding, so it decodes without replacement characters
+// and continuing at its end resumes on a whole character
+func (p *rangePart) trimPartialRunes(name string, size int64) {
+	enc, err := lookupEncoding(name)
+	if err != nil {
+		return
+	}
+	canonical, _ := htmlindex.Name(enc)
+	content := p.content
+	var lead, trail int
+	switch canonical {
+	case "utf-8":
+		if p.offset > 0 {
+			for lead < len(content) && lead < utf8.UTFMax-1 && !utf8.RuneStart(content[lead]) {
+				lead++
+			}
+		}
+		if p.offset+int64(len(content)) < size {
+			for i := 1; i <= utf8.UTFMax && i <= len(content)-lead; i++ {
+				if utf8.RuneStart(content[len(content)-i]) {
+					if !utf8.FullRune(content[len(content)-i:]) {
+						trail = i
+					}
+					break
+				}
+			}
+		}
+	case "utf-16le", "utf-16be":
+		lead = int(p.offset % 2)
+		trail = (len(content) - lead) % 2
+	}
+	p.offset += int64(lead)
+	p.content = content[lead : len(content)-trail]
+}
+
+// describe tells what part of the file at path was read, and how to
+// continue reading it
+func (p *rangePart) describe(path string, r *readRange, size int64) string {
+	if r.byLines() {
+		if p.lines == 0 {
+			return fmt.Sprintf("[%s has fewer than %d lines]", path, r.startLine)
+		}
+		if p.cut {
+			return fmt.Sprintf("[Line %d of %s is cut at %d bytes; continue with offset %d]", p.firstLine, path, len(p.content), p.offset+int64(len(p.content)))
+		}
+		text := fmt.Sprintf("[Lines %d-%d of %s]", p.firstLine, p.firstLine+p.lines-1, path)
+		if p.more {
+			text = fmt.Sprintf("[Lines %d-%d of %s; continue with start_line %d]", p.firstLine, p.firstLine+p.lines-1, path, p.firstLine+p.lines)
+		}
+		return text
+	}
+	end := p.offset + int64(len(p.content))
+	if p.more {
+		return fmt.Sprintf("[%d bytes from offset %d of %s (%d bytes); continue with offset %d]", len(p.content), p.offset, path, size, end)
+	}
+	return fmt.Sprintf("[%d bytes from offset %d of %s (%d bytes), to the end]", len(p.content), p.offset, path, size)
+}
+
+// readOptions are the read_file arguments that shape a text result
+type readOptions struct {
+	encoding       string
+	forceText      bool
+	lineNumbers    bool
+	highlight      string
+	highlightStyle string
+	asBase64       bool
+}
+
+// readFileRange implements read_file for a byte or line range of a local
+// file. Ranges are capped by the inline limits rather than refused, so a
+// large file can be paged through.
+func (fs *FilesystemHandler) readFileRange(validPath string, info os.FileInfo, mimeType string, r *readRange, opts readOptions) (*mcp.CallToolResult, error) {
+	text := (isTextFile(mimeType) || opts.forceText) && !opts.asBase64
+	if r.byLines() && !text {
+		return errorResult(toolError(CodeWrongType, "Error: start_line and end_line apply to text files; use offset and length for %s (%s)", validPath, mimeType).With("path", validPath)), nil
+	}
+	limit := fs.currentLimits().MaxInlineSize
+	if !text {
+		limit = fs.currentLimits().MaxBase64Size
+	}
+	part, err := readPart(validPath, info.Size(), r, limit, opts.lineNumbers || opts.highlight != "")
+	if err != nil {
+		var te *ToolError
+		if errors.As(err, &te) {
+			return errorResult(err), nil
+		}
+		return errorResult(fmt.Errorf("Error reading file: %w", err)), nil
+	}
+	if !text {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{Type: "text", Text: part.describe(validPath, r, info.Size())},
+				mcp.EmbeddedResource{
+					Type: "resource",
+					Resource: mcp.BlobResourceContents{
+						URI:      pathToResourceURI(validPath),
+						MIMEType: mimeType,
+						Blob:     base64.StdEncoding.EncodeToString(part.content),
+					},
+				},
+			},
+		}, nil
+	}
+
+	// The encoding is detected from the start of the file, as a range on
+	// its own may lack the byte order mark or look like another encoding
+	textEncoding := opts.encoding
+	if textEncoding == "" || strings.EqualFold(textEncoding, EncodingAuto) {
+		if textEncoding, err = detectFileEncoding(validPath); err != nil {
+			return errorResult(fmt.Errorf("Error reading file: %w", err)), nil
+		}
+	}
+	part.trimPartialRunes(textEncoding, info.Size())
+	decoded, _, err := decodeText(part.content, textEncoding)
+	if err != nil {
+		return errorResult(err), nil
+	}
+	shown := decoded
+	if opts.lineNumbers {
+		shown = numberLines(decoded, part.firstLine)
+	}
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{Type: "text", Text: shown},
+			mcp.TextContent{Type: "text", Text: part.describe(validPath, r, info.Size())},
+		},
+	}
+	if opts.highlight != "" {
+		hlMime, hlText, err := highlightCode(validPath, decoded, opts.highlight, opts.highlightStyle, part.firstLine)
+		if err != nil {
+			return errorResult(fmt.Errorf("Error highlighting file: %w", err)), nil
+		}
+		result.Content = append(result.Content, mcp.EmbeddedResource{
+			Type: "resource",
+			Resource: mcp.TextResourceContents{
+				URI:      pathToResourceURI(validPath),
+				MIMEType: hlMime,
+				Text:     hlText,
+			},
+		})
+	}
+	return result, nil
+}
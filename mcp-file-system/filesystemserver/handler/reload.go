@@ -0,0 +1,51 @@
+package handler
+
+// Reload replaces the allowed directories, SMB shares, read-only and
+// dry-run settings, limits, croc configuration, response format, base
+// directory, aliases and next action routes of a running handler, as if it
+// had been created with NewFilesystemHandler(allowedDirs, opts...).
+// Shares that are still configured keep their connections, and those
+// removed are disconnected. Open sessions, in-flight croc transfers,
+// backup schedules and file watchers are left untouched; changing those
+// requires a restart.
+func (fs *FilesystemHandler) Reload(allowedDirs []string, opts ...HandlerOption) error {
+	next, err := NewFilesystemHandler(allowedDirs, opts...)
+	if err != nil {
+		return err
+	}
+
+	// Unchanged shares keep their connections
+	var dropped []*smbRoot
+	shares := next.smbRoots
+	for _, old := range fs.shares() {
+		kept := false
+		for i, r := range shares {
+			if r.config == old.config {
+				shares[i], kept = old, true
+			}
+		}
+		if !kept {
+			dropped = append(dropped, old)
+		}
+	}
+	defer func() {
+		for _, r := range dropped {
+			r.reset()
+		}
+	}()
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.allowedDirs = next.allowedDirs
+	fs.smbRoots = shares
+	fs.readOnly = next.readOnly
+	fs.readOnlyDirs = next.readOnlyDirs
+	fs.dryRun = next.dryRun
+	fs.limits = next.limits
+	fs.croc = next.croc
+	fs.responseFormat = next.responseFormat
+	fs.baseDir = next.baseDir
+	fs.aliases = next.aliases
+	fs.nextActions = next.nextActions
+	return nil
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReload(t *testing.T) {
+	oldDir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	newDir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+
+	fsHandler, err := NewFilesystemHandler([]string{oldDir})
+	require.NoError(t, err)
+	_, err = fsHandler.validatePath(filepath.Join(newDir, "a.txt"))
+	require.Error(t, err)
+
+	require.NoError(t, fsHandler.Reload([]string{oldDir, newDir},
+		WithReadOnlyDirs([]string{oldDir}),
+		WithLimits(Limits{MaxSearchResults: 10}),
+	))
+	_, err = fsHandler.validatePath(filepath.Join(newDir, "a.txt"))
+	assert.NoError(t, err)
+	_, err = fsHandler.validateWritablePath(filepath.Join(oldDir, "a.txt"))
+	assert.ErrorContains(t, err, "read-only")
+	assert.Equal(t, 10, fsHandler.currentLimits().MaxSearchResults)
+	assert.Equal(t, int64(MAX_INLINE_SIZE), fsHandler.currentLimits().MaxInlineSize)
+
+	// An invalid configuration leaves the current one in place
+	assert.Error(t, fsHandler.Reload([]string{filepath.Join(newDir, "missing")}))
+	assert.Len(t, fsHandler.dirs(), 2)
+}
+
+func TestReloadSMBShares(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	fsHandler, err := NewFilesystemHandler([]string{dir, "smb://user@nas/projects/team", "smb://user@nas/media"})
+	require.NoError(t, err)
+	team := fsHandler.smbRoots[0]
+
+	// A share removed from the configuration is no longer reachable, and
+	// one still configured keeps its connection
+	require.NoError(t, fsHandler.Reload([]string{dir, "smb://user@nas/projects/team"}))
+	require.Len(t, fsHandler.shares(), 1)
+	assert.Same(t, team, fsHandler.shares()[0])
+	_, _, err = fsHandler.resolveSMBPath("smb://nas/media/film.mkv")
+	assert.Error(t, err)
+	_, _, err = fsHandler.resolveSMBPath("smb://nas/projects/team/notes.txt")
+	assert.NoError(t, err)
+
+	require.NoError(t, fsHandler.Reload([]string{dir}))
+	_, _, err = fsHandler.resolveSMBPath("smb://nas/projects/team/notes.txt")
+	assert.Error(t, err)
+}
@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// replayPathArguments are the path arguments rebased onto the target
+// directory when a recorded operation is replayed
+var replayPathArguments = []string{"path", "source", "destination"}
+
+// replayHandlers returns the handlers of the mutations replay_operations can
+// re-apply. The others depend on state outside the recorded arguments, such
+// as trash entries, croc codes or remote services.
+func (fs *FilesystemHandler) replayHandlers() map[string]ToolHandlerFunc {
+	return map[string]ToolHandlerFunc{
+		"write_file":       fs.HandleWriteFile,
+		"create_directory": fs.HandleCreateDirectory,
+		"copy_file":        fs.HandleCopyFile,
+		"move_file":        fs.HandleMoveFile,
+		"delete_file":      fs.HandleDeleteFile,
+		"modify_file":      fs.HandleModifyFile,
+		"format_file":      fs.HandleFormatFile,
+	}
+}
+
+// HandleReplayOperations re-applies the successful mutations recorded in an
+// operation log, with paths under from rebased onto to
+func (fs *FilesystemHandler) HandleReplayOperations(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	file, err := request.RequireString("file")
+	if err != nil {
+		return errorResult(toolError(CodeInvalidArgument, "%w", err)), nil
+	}
+	from, err := request.RequireString("from")
+	if err != nil {
+		return errorResult(toolError(CodeInvalidArgument, "%w", err)), nil
+	}
+	to, err := request.RequireString("to")
+	if err != nil {
+		return errorResult(toolError(CodeInvalidArgument, "%w", err)), nil
+	}
+	continueOnError := request.GetBool("continue_on_error", false)
+
+	// from names where the log was recorded and need not be allowed here,
+	// but aliases and relative paths are expanded as for any path argument
+	if from, err = fs.resolvePath(ctx, from); err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if to, err = fs.resolvePath(ctx, to); err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if !filepath.IsAbs(from) {
+		return errorResult(toolError(CodeInvalidArgument, "from must be an absolute path: %s", from)), nil
+	}
+	from = filepath.Clean(from)
+	target, err := fs.validateWritablePath(to)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if info, err := os.Stat(target); err != nil || !info.IsDir() {
+		return errorResult(toolError(CodeInvalidArgument, "to must be an existing directory: %s", to)), nil
+	}
+	logPath, err := fs.operationLogPath(ctx, file)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	f, err := os.Open(logPath)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error opening operation log: %w", err)), nil
+	}
+	defer f.Close()
+
+	dryRun := fs.dryRunMode()
+	handlers := fs.replayHandlers()
+	var lines []string
+	var applied, skipped, failed int
+	dec := json.NewDecoder(f)
+	for {
+		var record OperationRecord
+		if err := dec.Decode(&record); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return errorResult(fmt.Errorf("Error reading operation log after %d applied operations: %w", applied, err)), nil
+		}
+		// Reads, failed calls and changes that were never made are not replayed
+		if !record.Mutation || record.IsError || record.DryRun {
+			continue
+		}
+		handle, ok := handlers[record.Tool]
+		if !ok {
+			skipped++
+			lines = append(lines, fmt.Sprintf("skipped %s: not replayable", record.Tool))
+			continue
+		}
+		// Confirmation tokens are single use, so a confirmed glob deletion
+		// cannot be run again
+		if _, confirmed := record.Arguments["confirm_token"]; confirmed {
+			skipped++
+			lines = append(lines, fmt.Sprintf("skipped %s: confirmation tokens cannot be replayed", record.Tool))
+			continue
+		}
+		args, err := rebaseArguments(record.Arguments, from, target)
+		if err != nil {
+			skipped++
+			lines = append(lines, fmt.Sprintf("skipped %s: %v", record.Tool, err))
+			continue
+		}
+		if dryRun {
+			applied++
+			lines = append(lines, fmt.Sprintf("Would replay %s %s", record.Tool, describeReplayPaths(args)))
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return errorResult(toolError(CodeCancelled, "Replay cancelled after %d applied operations", applied)), nil
+		}
+
+		req := mcp.CallToolRequest{}
+		req.Params.Name = record.Tool
+		req.Params.Arguments = args
+		result, err := handle(ctx, req)
+		switch {
+		case err != nil:
+			failed++
+			lines = append(lines, fmt.Sprintf("failed %s %s: %v", record.Tool, describeReplayPaths(args), err))
+		case result.IsError:
+			failed++
+			lines = append(lines, fmt.Sprintf("failed %s %s: %s", record.Tool, describeReplayPaths(args), toolErrorText(result)))
+		default:
+			applied++
+			lines = append(lines, fmt.Sprintf("applied %s %s", record.Tool, describeReplayPaths(args)))
+			continue
+		}
+		if !continueOnError {
+			lines = append(lines, "Stopped at the first failure")
+			break
+		}
+	}
+
+	if dryRun {
+		return dryRunResult(append([]string{fmt.Sprintf("Would replay %d operations onto %s, skipping %d", applied, target, skipped)}, lines...)...), nil
+	}
+	text := fmt.Sprintf("Replayed %d operations onto %s (%d skipped, %d failed)", applied, target, skipped, failed)
+	if len(lines) > 0 {
+		text += "\n" + strings.Join(lines, "\n")
+	}
+	if failed > 0 {
+		return errorResult(toolError(CodeInternal, "%s", text).With("applied", applied).With("skipped", skipped).With("failed", failed)), nil
+	}
+	return mcp.NewToolResultText(text), nil
+}
+
+// operationLogPath resolves the log file to replay, which may lie in the
+// operation log directory or the allowed directories
+func (fs *FilesystemHandler) operationLogPath(ctx context.Context, file string) (string, error) {
+	if fs.operations != nil {
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(fs.operations.cfg.Dir, path)
+		}
+		if abs, err := filepath.Abs(path); err == nil && fs.operations.contains(abs) {
+			if _, err := os.Stat(abs); err == nil {
+				return abs, nil
+			}
+		}
+	}
+	resolvedPath, err := fs.resolvePath(ctx, file)
+	if err != nil {
+		return "", err
+	}
+	return fs.validatePath(resolvedPath)
+}
+
+// rebaseArguments copies args with the path arguments under from moved
+// beneath to. Paths outside from cannot be mapped and are rejected.
+func rebaseArguments(args map[string]any, from, to string) (map[string]any, error) {
+	rebased := make(map[string]any, len(args))
+	for key, value := range args {
+		rebased[key] = value
+	}
+	for _, key := range replayPathArguments {
+		path, ok := args[key].(string)
+		if !ok {
+			continue
+		}
+		rebasedPath, err := rebasePath(key, path, from, to)
+		if err != nil {
+			return nil, err
+		}
+		rebased[key] = rebasedPath
+	}
+	if sources, ok := args["sources"].([]any); ok {
+		list := make([]any, len(sources))
+		for i, source := range sources {
+			path, _ := source.(string)
+			rebasedPath, err := rebasePath("sources", path, from, to)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = rebasedPath
+		}
+		rebased["sources"] = list
+	}
+	return rebased, nil
+}
+
+// rebasePath moves the absolute path argument key from under from to under to
+func rebasePath(key, path, from, to string) (string, error) {
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("%s is not absolute: %s", key, path)
+	}
+	rel, err := filepath.Rel(from, filepath.Clean(path))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside %s: %s", key, from, path)
+	}
+	return filepath.Join(to, rel), nil
+}
+
+// describeReplayPaths lists the path arguments of a replayed operation
+func describeReplayPaths(args map[string]any) string {
+	var paths []string
+	for _, key := range replayPathArguments {
+		if path, ok := args[key].(string); ok {
+			paths = append(paths, path)
+		}
+	}
+	return strings.Join(paths, " -> ")
+}
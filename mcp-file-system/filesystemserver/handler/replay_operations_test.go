@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordToolCall(t *testing.T) {
+	dir := t.TempDir()
+	logDir := filepath.Join(t.TempDir(), "operations")
+	fsHandler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir), WithOperationLog(OperationLogConfig{Dir: logDir}))
+	require.NoError(t, err)
+
+	callTool(t, fsHandler.RecordToolCall("write_file", fsHandler.HandleWriteFile), map[string]any{"path": filepath.Join(dir, "a.txt"), "content": "hello"})
+	callTool(t, fsHandler.RecordToolCall("read_file", fsHandler.HandleReadFile), map[string]any{"path": filepath.Join(dir, "missing.txt")})
+	require.NoError(t, fsHandler.Shutdown(context.Background()))
+
+	records := readRecords(t, filepath.Join(logDir, operationLogName))
+	require.Len(t, records, 2)
+	assert.Equal(t, "write_file", records[0].Tool)
+	assert.True(t, records[0].Mutation)
+	assert.False(t, records[0].IsError)
+	assert.Equal(t, "hello", records[0].Arguments["content"])
+	assert.Equal(t, "read_file", records[1].Tool)
+	assert.False(t, records[1].Mutation)
+	assert.True(t, records[1].IsError)
+	assert.NotEmpty(t, records[1].Summary)
+}
+
+func TestOperationLogRotation(t *testing.T) {
+	logDir := t.TempDir()
+	log := &operationLog{cfg: OperationLogConfig{Dir: logDir, MaxSize: 200, MaxFiles: 2}}
+	for range 20 {
+		require.NoError(t, log.write(OperationRecord{Tool: "write_file", Arguments: map[string]any{"path": "/srv/a.txt"}}))
+	}
+	require.NoError(t, log.close())
+
+	rotated, err := log.rotatedFiles()
+	require.NoError(t, err)
+	assert.Len(t, rotated, 2)
+	info, err := os.Stat(filepath.Join(logDir, operationLogName))
+	require.NoError(t, err)
+	assert.LessOrEqual(t, info.Size(), int64(200))
+}
+
+func TestHandleReplayOperations(t *testing.T) {
+	staging := resolveAllowedDirs(t, t.TempDir())[0]
+	prod := resolveAllowedDirs(t, t.TempDir())[0]
+	logDir := t.TempDir()
+	recorder, err := NewFilesystemHandler([]string{staging}, WithOperationLog(OperationLogConfig{Dir: logDir}))
+	require.NoError(t, err)
+
+	record := func(tool string, fn ToolHandlerFunc, args map[string]any) {
+		callTool(t, recorder.RecordToolCall(tool, fn), args)
+	}
+	record("create_directory", recorder.HandleCreateDirectory, map[string]any{"path": filepath.Join(staging, "docs")})
+	record("write_file", recorder.HandleWriteFile, map[string]any{"path": filepath.Join(staging, "docs", "a.txt"), "content": "hello world"})
+	record("modify_file", recorder.HandleModifyFile, map[string]any{"path": filepath.Join(staging, "docs", "a.txt"), "find": "world", "replace": "prod"})
+	record("copy_file", recorder.HandleCopyFile, map[string]any{"source": filepath.Join(staging, "docs", "a.txt"), "destination": filepath.Join(staging, "b.txt")})
+	record("read_file", recorder.HandleReadFile, map[string]any{"path": filepath.Join(staging, "b.txt")})
+	record("delete_file", recorder.HandleDeleteFile, map[string]any{"path": filepath.Join(staging, "missing.txt")})
+	require.NoError(t, recorder.Shutdown(context.Background()))
+
+	replayer, err := NewFilesystemHandler([]string{prod}, WithOperationLog(OperationLogConfig{Dir: logDir}))
+	require.NoError(t, err)
+	args := map[string]any{"file": operationLogName, "from": staging, "to": prod}
+
+	t.Run("dry run", func(t *testing.T) {
+		WithDryRun(true)(replayer)
+		defer WithDryRun(false)(replayer)
+		res := callTool(t, replayer.HandleReplayOperations, args)
+		require.False(t, res.IsError, res.Content[0].(mcp.TextContent).Text)
+		assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Would replay 4 operations")
+		assert.NoDirExists(t, filepath.Join(prod, "docs"))
+	})
+
+	t.Run("replays mutations", func(t *testing.T) {
+		res := callTool(t, replayer.HandleReplayOperations, args)
+		require.False(t, res.IsError, res.Content[0].(mcp.TextContent).Text)
+		assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Replayed 4 operations")
+		content, err := os.ReadFile(filepath.Join(prod, "docs", "a.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "hello prod", string(content))
+		assert.FileExists(t, filepath.Join(prod, "b.txt"))
+	})
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		// create_directory fails once a file takes the directory's place
+		require.NoError(t, os.RemoveAll(filepath.Join(prod, "docs")))
+		require.NoError(t, os.WriteFile(filepath.Join(prod, "docs"), nil, 0644))
+		res := callTool(t, replayer.HandleReplayOperations, args)
+		assert.True(t, res.IsError)
+		assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Stopped at the first failure")
+		assert.NotContains(t, res.Content[0].(mcp.TextContent).Text, "applied")
+	})
+
+	t.Run("resolves aliases and relative paths", func(t *testing.T) {
+		mirror := resolveAllowedDirs(t, t.TempDir())[0]
+		logData, err := os.ReadFile(filepath.Join(logDir, operationLogName))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(mirror, "ops.jsonl"), logData, 0644))
+		aliased, err := NewFilesystemHandler([]string{mirror}, WithAliases(map[string]string{"mirror": mirror}), WithBaseDirectory(mirror))
+		require.NoError(t, err)
+		res := callTool(t, aliased.HandleReplayOperations, map[string]any{"file": "ops.jsonl", "from": staging, "to": "@mirror"})
+		require.False(t, res.IsError, res.Content[0].(mcp.TextContent).Text)
+		assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Replayed 4 operations onto "+mirror)
+		assert.FileExists(t, filepath.Join(mirror, "docs", "a.txt"))
+	})
+
+	t.Run("rejects paths outside the allowed directories", func(t *testing.T) {
+		res := callTool(t, replayer.HandleReplayOperations, map[string]any{"file": operationLogName, "from": staging, "to": staging})
+		assert.True(t, res.IsError)
+	})
+}
+
+func TestRebaseArguments(t *testing.T) {
+	args, err := rebaseArguments(map[string]any{"source": "/staging/a", "destination": "/staging/b/c", "content": "x"}, "/staging", "/prod")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"source": "/prod/a", "destination": "/prod/b/c", "content": "x"}, args)
+
+	_, err = rebaseArguments(map[string]any{"path": "/stagingx/a"}, "/staging", "/prod")
+	assert.Error(t, err)
+	_, err = rebaseArguments(map[string]any{"path": "relative/a"}, "/staging", "/prod")
+	assert.Error(t, err)
+}
+
+// callTool invokes a tool handler with args and fails the test on a Go error
+func callTool(t *testing.T, fn ToolHandlerFunc, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = args
+	res, err := fn(context.Background(), req)
+	require.NoError(t, err)
+	return res
+}
+
+// readRecords decodes every record in an operation log file
+func readRecords(t *testing.T, path string) []OperationRecord {
+	t.Helper()
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var records []OperationRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record OperationRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	require.NoError(t, scanner.Err())
+	return records
+}
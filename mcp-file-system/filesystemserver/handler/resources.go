@@ -78,7 +78,7 @@ func (fs *FilesystemHandler) HandleReadResource(
 	mimeType := detectMimeType(validPath)
 
 	// Check file size
-	if fileInfo.Size() > MAX_INLINE_SIZE {
+	if fileInfo.Size() > fs.currentLimits().MaxInlineSize {
 		// File is too large to inline, return a reference instead
 		return []mcp.ResourceContents{
 			mcp.TextResourceContents{
@@ -107,7 +107,7 @@ func (fs *FilesystemHandler) HandleReadResource(
 		}, nil
 	} else {
 		// It's a binary file
-		if fileInfo.Size() <= MAX_BASE64_SIZE {
+		if fileInfo.Size() <= fs.currentLimits().MaxBase64Size {
 			// Small enough for base64 encoding
 			return []mcp.ResourceContents{
 				mcp.BlobResourceContents{
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Response formats, chosen per call with the format argument or for the
+// whole server with WithResponseFormat
+const (
+	// FormatText returns human-oriented text (the default)
+	FormatText = "text"
+	// FormatJSON returns a single JSON document with a stable schema
+	FormatJSON = "json"
+)
+
+// formatKey is the context key of the *formatState of a tool call
+type formatKey struct{}
+
+// formatState carries the response format of a tool call to its handler,
+// and records whether the handler produced a structured JSON result itself
+type formatState struct {
+	format     string
+	structured bool
+}
+
+// WithResponseFormat sets the response format used when a call does not
+// pass one: FormatText or FormatJSON
+func WithResponseFormat(format string) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		fs.responseFormat = format
+	}
+}
+
+// defaultResponseFormat returns the server-wide response format
+func (fs *FilesystemHandler) defaultResponseFormat() string {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	if fs.responseFormat == "" {
+		return FormatText
+	}
+	return fs.responseFormat
+}
+
+// FormatToolCall wraps a tool handler so it honours the format argument.
+// In JSON mode, handlers with a structured result return it through
+// jsonResult; any other result is wrapped as {"message": text}, or as
+// {"error": ToolError} for errors, so every tool returns JSON. extra lists
+// further formats the handler renders itself, such as tree's "mermaid";
+// they are returned unchanged like text.
+func (fs *FilesystemHandler) FormatToolCall(next ToolHandlerFunc, extra ...string) ToolHandlerFunc {
+	formats := append([]string{FormatText, FormatJSON}, extra...)
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		format := request.GetString("format", "")
+		if format == "" {
+			format = fs.defaultResponseFormat()
+		}
+		if !slices.Contains(formats, format) {
+			return errorResult(toolError(CodeInvalidArgument, "Error: format must be one of %s", strings.Join(formats, ", "))), nil
+		}
+		state := &formatState{format: format}
+		result, err := next(context.WithValue(ctx, formatKey{}, state), request)
+		if err != nil || result == nil || format != FormatJSON || state.structured {
+			return result, err
+		}
+		return jsonEnvelope(result), nil
+	}
+}
+
+// wantsJSON reports whether the tool call behind ctx asked for JSON
+func wantsJSON(ctx context.Context) bool {
+	return responseFormat(ctx) == FormatJSON
+}
+
+// responseFormat returns the format the tool call behind ctx asked for
+func responseFormat(ctx context.Context) string {
+	if state, ok := ctx.Value(formatKey{}).(*formatState); ok {
+		return state.format
+	}
+	return FormatText
+}
+
+// jsonResult returns v as the structured JSON result of the call behind ctx
+func jsonResult(ctx context.Context, v any) (*mcp.CallToolResult, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return errorResult(fmt.Errorf("failed to marshal response: %w", err)), nil
+	}
+	if state, ok := ctx.Value(formatKey{}).(*formatState); ok {
+		state.structured = true
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// jsonEnvelope replaces the text of an unstructured result with a JSON
+// object holding it, or its ToolError for errors. Images and embedded
+// resources are kept after it.
+func jsonEnvelope(result *mcp.CallToolResult) *mcp.CallToolResult {
+	var texts []string
+	var others []mcp.Content
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		} else {
+			others = append(others, content)
+		}
+	}
+	var envelope any = map[string]string{"message": strings.Join(texts, "\n")}
+	if te := ResultError(result); te != nil {
+		envelope = map[string]*ToolError{"error": te}
+	}
+	data, _ := json.MarshalIndent(envelope, "", "  ")
+	wrapped := *result
+	wrapped.Content = append([]mcp.Content{mcp.NewTextContent(string(data))}, others...)
+	return &wrapped
+}
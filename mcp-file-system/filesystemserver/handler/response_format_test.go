@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseFormatJSON(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0755))
+	require.NoError(t, os.Chmod(filepath.Join(dir, "a.txt"), 0644))
+	require.NoError(t, os.Chmod(filepath.Join(dir, "sub"), 0755))
+	stamp := time.Now().Add(-49 * time.Hour).Truncate(time.Second)
+	for _, name := range []string{"a.txt", "sub"} {
+		require.NoError(t, os.Chtimes(filepath.Join(dir, name), stamp, stamp))
+	}
+	times := FileTimes{
+		Modified:     stamp.UTC().Format(time.RFC3339),
+		ModifiedUnix: stamp.Unix(),
+		ModifiedAgo:  "2 days ago",
+		Accessed:     stamp.UTC().Format(time.RFC3339),
+		AccessedUnix: stamp.Unix(),
+	}
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	res := callTool(t, fsHandler.FormatToolCall(fsHandler.HandleListDirectory), map[string]any{"path": dir, "format": FormatJSON})
+	require.False(t, res.IsError)
+	var listing DirectoryListing
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &listing))
+	assert.Equal(t, dir, listing.Path)
+	for i := range listing.Entries {
+		// Birth times depend on the filesystem
+		listing.Entries[i].Created, listing.Entries[i].CreatedUnix = "", 0
+	}
+	assert.Equal(t, []DirectoryEntry{
+		{Name: "a.txt", Path: filepath.Join(dir, "a.txt"), Type: "file", Size: 5, Mode: "644", URI: pathToResourceURI(filepath.Join(dir, "a.txt")), FileTimes: times},
+		{Name: "sub", Path: filepath.Join(dir, "sub"), Type: "directory", Mode: "755", URI: pathToResourceURI(filepath.Join(dir, "sub")), FileTimes: times},
+	}, listing.Entries)
+
+	// Tools without a structured result are wrapped in an envelope
+	res = callTool(t, fsHandler.FormatToolCall(fsHandler.HandleCreateDirectory), map[string]any{"path": filepath.Join(dir, "new"), "format": FormatJSON})
+	require.False(t, res.IsError)
+	var envelope map[string]string
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &envelope))
+	assert.Contains(t, envelope["message"], "Successfully created directory")
+
+	res = callTool(t, fsHandler.FormatToolCall(fsHandler.HandleReadFile), map[string]any{"path": "/outside", "format": FormatJSON})
+	require.True(t, res.IsError)
+	var failure struct{ Error ToolError }
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &failure))
+	assert.Equal(t, CodePathNotAllowed, failure.Error.Code)
+	assert.Contains(t, failure.Error.Message, "access denied")
+}
+
+func TestResponseFormatDefault(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	list := fsHandler.FormatToolCall(fsHandler.HandleListAllowedDirectories)
+
+	res := callTool(t, list, nil)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Allowed directories:")
+
+	res = callTool(t, list, map[string]any{"format": "xml"})
+	assert.True(t, res.IsError)
+
+	jsonHandler, err := NewFilesystemHandler([]string{dir}, WithResponseFormat(FormatJSON))
+	require.NoError(t, err)
+	res = callTool(t, jsonHandler.FormatToolCall(jsonHandler.HandleListAllowedDirectories), nil)
+	var dirs AllowedDirectories
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &dirs))
+	require.Len(t, dirs.Directories, 1)
+	assert.Equal(t, dir, dirs.Directories[0].Path)
+	assert.Equal(t, "local", dirs.Directories[0].Type)
+	assert.Equal(t, pathToResourceURI(dir), dirs.Directories[0].URI)
+
+	// A call can still ask for text
+	res = callTool(t, jsonHandler.FormatToolCall(jsonHandler.HandleListAllowedDirectories), map[string]any{"format": FormatText})
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Allowed directories:")
+}
@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// continuationTTL is how long the rest of a truncated response is kept
+	continuationTTL = 10 * time.Minute
+	// continuationReserve leaves room in a page for the truncation marker
+	continuationReserve = 512
+)
+
+// pendingContinuation is what is left of a truncated tool response
+type pendingContinuation struct {
+	session string
+	tool    string
+	isError bool
+	rest    []mcp.Content
+	// sent and total count the bytes of content returned so far and overall
+	sent, total int
+	expires     time.Time
+}
+
+// contentSize is the number of bytes content adds to a response
+func contentSize(content mcp.Content) int {
+	switch c := content.(type) {
+	case mcp.TextContent:
+		return len(c.Text)
+	case mcp.ImageContent:
+		return len(c.Data)
+	case mcp.AudioContent:
+		return len(c.Data)
+	case mcp.EmbeddedResource:
+		switch r := c.Resource.(type) {
+		case mcp.TextResourceContents:
+			return len(r.Text)
+		case mcp.BlobResourceContents:
+			return len(r.Blob)
+		}
+	}
+	return 0
+}
+
+// splitContent fills a page of at most budget bytes from contents and
+// returns the page and what is left. Text is split at a line break where
+// possible; other content that does not fit in a page of its own is
+// replaced by a note, as it cannot be split.
+func splitContent(contents []mcp.Content, budget int) (page, rest []mcp.Content) {
+	used := 0
+	for i, content := range contents {
+		size := contentSize(content)
+		if used+size <= budget {
+			page = append(page, content)
+			used += size
+			continue
+		}
+		if res, ok := content.(mcp.EmbeddedResource); ok && size > budget {
+			if text, ok := res.Resource.(mcp.TextResourceContents); ok {
+				content = mcp.NewTextContent(text.Text)
+			}
+		}
+		text, ok := content.(mcp.TextContent)
+		if !ok {
+			if len(page) > 0 {
+				return page, contents[i:]
+			}
+			note := mcp.NewTextContent(fmt.Sprintf("[%d bytes of %s content omitted: larger than the response size limit of %d bytes]", size, contentKind(content), budget))
+			return []mcp.Content{note}, contents[i+1:]
+		}
+		cut := textCut(text.Text, budget-used)
+		if cut == 0 && len(page) > 0 {
+			return page, contents[i:]
+		}
+		if cut == 0 {
+			// Always make progress, even when a single rune is over budget
+			_, cut = utf8.DecodeRuneInString(text.Text)
+		}
+		page = append(page, mcp.NewTextContent(text.Text[:cut]))
+		rest = append([]mcp.Content{mcp.NewTextContent(text.Text[cut:])}, contents[i+1:]...)
+		return page, rest
+	}
+	return page, nil
+}
+
+// textCut returns where to cut text so the first part holds at most n
+// bytes, preferring the end of a line in the second half of the page and
+// never splitting a rune
+func textCut(text string, n int) int {
+	if n <= 0 {
+		return 0
+	}
+	if n >= len(text) {
+		return len(text)
+	}
+	if i := strings.LastIndexByte(text[:n], '\n'); i >= n/2 {
+		return i + 1
+	}
+	for n > 0 && !utf8.RuneStart(text[n]) {
+		n--
+	}
+	return n
+}
+
+// contentKind names the type of non-text content
+func contentKind(content mcp.Content) string {
+	switch content.(type) {
+	case mcp.ImageContent:
+		return "image"
+	case mcp.AudioContent:
+		return "audio"
+	}
+	return "resource"
+}
+
+// PageToolCall wraps a tool handler so a result larger than
+// MaxResponseSize is cut down to that size. The rest is kept for
+// continue_result, and a marker at the end of the result names the token
+// to pass it.
+func (fs *FilesystemHandler) PageToolCall(tool string, next ToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, request)
+		if err != nil || result == nil {
+			return result, err
+		}
+		total := 0
+		for _, content := range result.Content {
+			total += contentSize(content)
+		}
+		if int64(total) <= fs.currentLimits().MaxResponseSize {
+			return result, nil
+		}
+		pending := &pendingContinuation{session: sessionID(ctx), tool: tool, isError: result.IsError, rest: result.Content, total: total}
+		return fs.nextPage(pending)
+	}
+}
+
+// nextPage returns the next page of pending, storing what remains under a
+// new token
+func (fs *FilesystemHandler) nextPage(pending *pendingContinuation) (*mcp.CallToolResult, error) {
+	budget := max(int(fs.currentLimits().MaxResponseSize)-continuationReserve, 1)
+	page, rest := splitContent(pending.rest, budget)
+	pending.sent = pending.total
+	for _, content := range rest {
+		pending.sent -= contentSize(content)
+	}
+	result := &mcp.CallToolResult{Content: page, IsError: pending.isError}
+	if len(rest) == 0 {
+		return result, nil
+	}
+
+	token, err := newConfirmToken()
+	if err != nil {
+		return errorResult(fmt.Errorf("Error creating continuation token: %w", err)), nil
+	}
+	fs.pruneContinuations()
+	pending.rest, pending.expires = rest, time.Now().Add(continuationTTL)
+	fs.continuations.Store(token, pending)
+	result.Content = append(result.Content, mcp.NewTextContent(fmt.Sprintf(
+		"\n[Response truncated: returned %d of %d bytes. Call continue_result with token %q within %s for the rest.]",
+		pending.sent, pending.total, token, continuationTTL)))
+	return result, nil
+}
+
+// HandleContinueResult returns the next page of a truncated tool response
+func (fs *FilesystemHandler) HandleContinueResult(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	token, err := request.RequireString("token")
+	if err != nil {
+		return nil, err
+	}
+	value, ok := fs.continuations.LoadAndDelete(token)
+	if !ok {
+		return errorResult(toolError(CodeNotFound, "Error: unknown or already used continuation token").With("token", token)), nil
+	}
+	pending := value.(*pendingContinuation)
+	if time.Now().After(pending.expires) {
+		return errorResult(toolError(CodeNotFound, "Error: the continuation token has expired; call %s again", pending.tool).With("token", token)), nil
+	}
+	if pending.session != sessionID(ctx) {
+		return errorResult(toolError(CodeNotFound, "Error: unknown or already used continuation token").With("token", token)), nil
+	}
+	return fs.nextPage(pending)
+}
+
+// pruneContinuations drops the rest of responses nobody came back for
+func (fs *FilesystemHandler) pruneContinuations() {
+	now := time.Now()
+	fs.continuations.Range(func(key, value any) bool {
+		if now.After(value.(*pendingContinuation).expires) {
+			fs.continuations.Delete(key)
+		}
+		return true
+	})
+}
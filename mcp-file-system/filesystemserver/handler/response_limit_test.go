@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageToolCall(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	fsHandler, err := NewFilesystemHandler([]string{dir}, WithLimits(Limits{MaxResponseSize: 2048}))
+	require.NoError(t, err)
+	var lines []string
+	for i := 0; i < 200; i++ {
+		lines = append(lines, strings.Repeat("é", 20))
+	}
+	full := strings.Join(lines, "\n")
+	big := fsHandler.PageToolCall("big", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(full), nil
+	})
+	tokenPattern := regexp.MustCompile(`continue_result with token "([0-9a-f]+)"`)
+
+	res := callTool(t, big, nil)
+	var got strings.Builder
+	for pages := 1; ; pages++ {
+		require.LessOrEqual(t, pages, 10)
+		require.False(t, res.IsError, res.Content)
+		require.NotEmpty(t, res.Content)
+		size := 0
+		for _, content := range res.Content {
+			size += contentSize(content)
+		}
+		assert.LessOrEqual(t, size, 2048)
+		got.WriteString(res.Content[0].(mcp.TextContent).Text)
+		if len(res.Content) == 1 {
+			break
+		}
+		marker := res.Content[len(res.Content)-1].(mcp.TextContent).Text
+		assert.Contains(t, marker, "Response truncated")
+		token := tokenPattern.FindStringSubmatch(marker)
+		require.Len(t, token, 2)
+		res = callTool(t, fsHandler.HandleContinueResult, map[string]any{"token": token[1]})
+	}
+	assert.Equal(t, full, got.String())
+
+	// Small results pass through, and tokens are single use
+	small := fsHandler.PageToolCall("small", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	res = callTool(t, small, nil)
+	assert.Equal(t, []mcp.Content{mcp.NewTextContent("ok")}, res.Content)
+	res = callTool(t, fsHandler.HandleContinueResult, map[string]any{"token": "0123"})
+	assert.Equal(t, CodeNotFound, ResultError(res).Code)
+
+	// Content that cannot be split is left out with a note
+	image := fsHandler.PageToolCall("image", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{
+			mcp.NewTextContent("header"),
+			mcp.NewImageContent(strings.Repeat("A", 4096), "image/png"),
+		}}, nil
+	})
+	res = callTool(t, image, nil)
+	require.Len(t, res.Content, 2)
+	token := tokenPattern.FindStringSubmatch(res.Content[1].(mcp.TextContent).Text)
+	require.Len(t, token, 2)
+	res = callTool(t, fsHandler.HandleContinueResult, map[string]any{"token": token[1]})
+	require.Len(t, res.Content, 1)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "4096 bytes of image content omitted")
+}
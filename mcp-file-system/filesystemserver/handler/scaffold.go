@@ -0,0 +1,292 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ScaffoldTemplate describes a template in the templates directory
+type ScaffoldTemplate struct {
+	Name  string `json:"name"`
+	Files int    `json:"file_count"`
+	// Variables are the {{name}} placeholders used in its paths and files
+	Variables []string `json:"variables"`
+}
+
+// ScaffoldResult is the JSON result of scaffold
+type ScaffoldResult struct {
+	Template    string   `json:"template"`
+	Destination string   `json:"destination"`
+	Created     []string `json:"created"`
+}
+
+// templateFile is a file or directory of a template, with its rendered
+// path relative to the destination
+type templateFile struct {
+	source string
+	target string
+	dir    bool
+	mode   os.FileMode
+}
+
+// WithTemplatesDir sets the directory whose subdirectories scaffold
+// instantiates. It is read by the server and need not be an allowed
+// directory.
+func WithTemplatesDir(dir string) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		fs.templatesDir = dir
+	}
+}
+
+// templateRoot returns the directory of the named template
+func (fs *FilesystemHandler) templateRoot(name string) (string, error) {
+	if fs.templatesDir == "" {
+		return "", toolError(CodeUnavailable, "no templates directory is configured")
+	}
+	if name == "" || name != filepath.Base(name) || name == "." || name == ".." {
+		return "", toolError(CodeInvalidArgument, "invalid template name: %q", name).With("template", name)
+	}
+	root := filepath.Join(fs.templatesDir, name)
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return "", toolError(CodeNotFound, "unknown template: %s", name).With("template", name)
+	}
+	return root, nil
+}
+
+// templateVariables returns the sorted placeholder names used in the paths
+// and text files below root, and the number of files
+func templateVariables(root string) ([]string, int, error) {
+	names := map[string]bool{}
+	files := 0
+	collect := func(text string) {
+		for _, m := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+			names[m[1]] = true
+		}
+	}
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil || path == root {
+			return err
+		}
+		collect(d.Name())
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		files++
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if !looksBinary(content) {
+			collect(string(content))
+		}
+		return nil
+	})
+	variables := slices.Sorted(maps.Keys(names))
+	if variables == nil {
+		variables = []string{}
+	}
+	return variables, files, err
+}
+
+// renderTemplate fills the placeholders of text from vars, collecting the
+// names that have no value in missing
+func renderTemplate(text string, vars map[string]string, missing map[string]bool) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(s string) string {
+		name := placeholderPattern.FindStringSubmatch(s)[1]
+		value, ok := vars[name]
+		if !ok {
+			missing[name] = true
+		}
+		return value
+	})
+}
+
+// HandleListTemplates handles the list_templates tool
+func (fs *FilesystemHandler) HandleListTemplates(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	if fs.templatesDir == "" {
+		return errorResult(toolError(CodeUnavailable, "Error: no templates directory is configured")), nil
+	}
+	entries, err := os.ReadDir(fs.templatesDir)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error reading templates directory: %w", err)), nil
+	}
+	templates := []ScaffoldTemplate{}
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		variables, files, err := templateVariables(filepath.Join(fs.templatesDir, entry.Name()))
+		if err != nil {
+			return errorResult(fmt.Errorf("Error reading template %s: %w", entry.Name(), err)), nil
+		}
+		templates = append(templates, ScaffoldTemplate{Name: entry.Name(), Files: files, Variables: variables})
+	}
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, templates)
+	}
+
+	if len(templates) == 0 {
+		return mcp.NewToolResultText("No templates found"), nil
+	}
+	var sb strings.Builder
+	sb.WriteString("Templates:\n")
+	for _, t := range templates {
+		sb.WriteString(fmt.Sprintf("  %s (%d files)", t.Name, t.Files))
+		if len(t.Variables) > 0 {
+			sb.WriteString(": " + strings.Join(t.Variables, ", "))
+		}
+		sb.WriteString("\n")
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// HandleScaffold handles the scaffold tool
+func (fs *FilesystemHandler) HandleScaffold(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	name, err := request.RequireString("template")
+	if err != nil {
+		return nil, err
+	}
+	destination, err := request.RequireString("destination")
+	if err != nil {
+		return nil, err
+	}
+	overwrite := request.GetBool("overwrite", false)
+	vars := map[string]string{}
+	if raw, ok := request.GetArguments()["variables"].(map[string]any); ok {
+		for key, value := range raw {
+			vars[key] = fmt.Sprint(value)
+		}
+	}
+
+	root, err := fs.templateRoot(name)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	validDest, err := fs.validateCreatablePath(destination, request.GetBool("create_parents", false))
+	if err != nil {
+		return errorResult(fmt.Errorf("Error with destination path: %w", err)), nil
+	}
+	if info, err := os.Stat(validDest); err == nil && !info.IsDir() {
+		return errorResult(toolError(CodeWrongType, "Error: %s is not a directory", destination).With("path", destination)), nil
+	}
+
+	// Render every path first, so a missing variable fails before anything is written
+	var files []templateFile
+	missing := map[string]bool{}
+	err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil || path == root {
+			return err
+		}
+		if !d.IsDir() && !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, templateFile{source: path, target: renderTemplate(rel, vars, missing), dir: d.IsDir(), mode: info.Mode().Perm()})
+		return nil
+	})
+	if err != nil {
+		return errorResult(fmt.Errorf("Error reading template: %w", err)), nil
+	}
+	contents := make([][]byte, len(files))
+	for i, file := range files {
+		if file.dir {
+			continue
+		}
+		content, err := os.ReadFile(file.source)
+		if err != nil {
+			return errorResult(fmt.Errorf("Error reading template: %w", err)), nil
+		}
+		if !looksBinary(content) {
+			content = []byte(renderTemplate(string(content), vars, missing))
+		}
+		contents[i] = content
+	}
+	if len(missing) > 0 {
+		names := slices.Sorted(maps.Keys(missing))
+		return errorResult(toolError(CodeInvalidArgument, "Error: template %s needs values for: %s", name, strings.Join(names, ", ")).
+			With("missing", names)), nil
+	}
+
+	var conflicts []string
+	for i := range files {
+		target := filepath.Join(validDest, files[i].target)
+		if !pathWithin(target, validDest) {
+			return errorResult(toolError(CodeInvalidArgument, "Error: template path %s leaves the destination", files[i].target).With("path", files[i].target)), nil
+		}
+		files[i].target = target
+		if info, err := os.Stat(target); err == nil && (!files[i].dir || !info.IsDir()) {
+			conflicts = append(conflicts, target)
+		}
+	}
+	if len(conflicts) > 0 && !overwrite {
+		return errorResult(toolError(CodeAlreadyExists, "Error: %d file(s) already exist, such as %s; set overwrite to replace them", len(conflicts), conflicts[0]).
+			With("path", conflicts[0])), nil
+	}
+
+	if fs.dryRunMode() {
+		var lines []string
+		for _, dir := range missingParents(filepath.Join(validDest, "_")) {
+			lines = append(lines, fmt.Sprintf("Would create directory %s", dir))
+		}
+		for i, file := range files {
+			if file.dir {
+				lines = append(lines, fmt.Sprintf("Would create directory %s", file.target))
+				continue
+			}
+			lines = append(lines, describeWrite(file.target, contents[i])...)
+		}
+		return dryRunResult(lines...), nil
+	}
+
+	if err := os.MkdirAll(validDest, 0755); err != nil {
+		return errorResult(fmt.Errorf("Error creating destination directory: %w", err)), nil
+	}
+	result := ScaffoldResult{Template: name, Destination: validDest, Created: []string{}}
+	for i, file := range files {
+		if err := ctx.Err(); err != nil {
+			return errorResult(err), nil
+		}
+		if _, err := fs.validateWritablePath(file.target); err != nil {
+			return errorResult(fmt.Errorf("Error: %w", err)), nil
+		}
+		if file.dir {
+			err = os.MkdirAll(file.target, file.mode|0700)
+		} else {
+			err = os.WriteFile(file.target, contents[i], file.mode)
+		}
+		if err != nil {
+			return errorResult(fmt.Errorf("Error writing %s: %w", file.target, err)), nil
+		}
+		result.Created = append(result.Created, file.target)
+	}
+
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, result)
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Created %d item(s) from template %s in %s:\n", len(result.Created), name, validDest))
+	for _, path := range result.Created {
+		sb.WriteString(fmt.Sprintf("  %s\n", path))
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScaffold(t *testing.T) {
+	templates := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(templates, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	write("service/README.md", "# {{ name }}\n\nOwned by {{owner}}.\n")
+	write("service/cmd/{{name}}/main.go", "package main // {{name}}\n")
+	write("service/logo.bin", "\x00{{name}}")
+	write("empty/.keep", "")
+
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	fsHandler, err := NewFilesystemHandler([]string{dir}, WithTemplatesDir(templates))
+	require.NoError(t, err)
+	list := fsHandler.FormatToolCall(fsHandler.HandleListTemplates)
+	scaffold := fsHandler.FormatToolCall(fsHandler.HandleScaffold)
+
+	res := callTool(t, list, map[string]any{"format": FormatJSON})
+	require.False(t, res.IsError, res.Content)
+	var listed []ScaffoldTemplate
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &listed))
+	assert.Equal(t, []ScaffoldTemplate{
+		{Name: "empty", Files: 1, Variables: []string{}},
+		{Name: "service", Files: 3, Variables: []string{"name", "owner"}},
+	}, listed)
+
+	dest := filepath.Join(dir, "billing")
+	res = callTool(t, scaffold, map[string]any{"template": "service", "destination": dest, "variables": map[string]any{"name": "billing"}})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "owner")
+	assert.NoDirExists(t, dest)
+
+	args := map[string]any{"template": "service", "destination": dest, "variables": map[string]any{"name": "billing", "owner": "ops"}}
+	res = callTool(t, scaffold, args)
+	require.False(t, res.IsError, res.Content)
+	content, err := os.ReadFile(filepath.Join(dest, "README.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# billing\n\nOwned by ops.\n", string(content))
+	content, err = os.ReadFile(filepath.Join(dest, "cmd", "billing", "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package main // billing\n", string(content))
+	content, err = os.ReadFile(filepath.Join(dest, "logo.bin"))
+	require.NoError(t, err)
+	assert.Equal(t, "\x00{{name}}", string(content))
+
+	// Existing files are only replaced with overwrite
+	res = callTool(t, scaffold, args)
+	assert.Equal(t, CodeAlreadyExists, ResultError(res).Code)
+	args["overwrite"] = true
+	res = callTool(t, scaffold, args)
+	assert.False(t, res.IsError, res.Content)
+
+	res = callTool(t, scaffold, map[string]any{"template": "../service", "destination": dest})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	res = callTool(t, scaffold, map[string]any{"template": "missing", "destination": dest})
+	assert.Equal(t, CodeNotFound, ResultError(res).Code)
+}
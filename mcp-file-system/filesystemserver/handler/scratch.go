@@ -0,0 +1,322 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Scratch directory defaults
+const (
+	defaultScratchDir     = "scratch"
+	defaultScratchTTL     = 24 * time.Hour
+	defaultScratchMaxSize = 1024 * 1024 * 1024 // 1GB
+)
+
+// ScratchConfig configures the per-session scratch directories handed out
+// by get_scratch_dir
+type ScratchConfig struct {
+	// Dir holds the scratch directories; a relative path is within the
+	// state directory (default: scratch)
+	Dir string `json:"dir,omitempty" yaml:"dir,omitempty"`
+	// TTL removes a scratch directory once it has not been requested or
+	// changed at its top level for this long (default: 24h)
+	TTL time.Duration `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+	// MaxSize is the quota of each scratch directory in bytes (default: 1GB)
+	MaxSize int64 `json:"max_size,omitempty" yaml:"max_size,omitempty"`
+}
+
+// scratchSpace tracks the scratch directory of each session
+type scratchSpace struct {
+	cfg ScratchConfig
+	// root is the resolved Dir, served as an allowed directory
+	root     string
+	mu       sync.Mutex
+	sessions map[string]string
+}
+
+// ScratchDir is the JSON result of get_scratch_dir
+type ScratchDir struct {
+	Path      string `json:"path"`
+	Used      int64  `json:"used"`
+	MaxSize   int64  `json:"max_size"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// WithScratch enables get_scratch_dir, which gives every session a
+// writable directory of its own under cfg.Dir. The directory holding them
+// becomes an allowed directory, but each session can only reach its own.
+func WithScratch(cfg ScratchConfig) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		if cfg.Dir == "" {
+			cfg.Dir = defaultScratchDir
+		}
+		if cfg.TTL <= 0 {
+			cfg.TTL = defaultScratchTTL
+		}
+		if cfg.MaxSize <= 0 {
+			cfg.MaxSize = defaultScratchMaxSize
+		}
+		fs.scratch = &scratchSpace{cfg: cfg, sessions: make(map[string]string)}
+	}
+}
+
+// initScratch creates the scratch root and adds it to the allowed directories
+func (fs *FilesystemHandler) initScratch() error {
+	root := fs.scratch.cfg.Dir
+	if !filepath.IsAbs(root) {
+		root = filepath.Join(fs.stateDir, root)
+	}
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return fmt.Errorf("failed to create scratch directory: %w", err)
+	}
+	root, err := filepath.Abs(root)
+	if err == nil {
+		root, err = filepath.EvalSymlinks(root)
+	}
+	if err != nil {
+		return fmt.Errorf("scratch directory: %w", err)
+	}
+	fs.scratch.root = root
+	fs.allowedDirs = append(fs.allowedDirs, withTrailingSeparator(root))
+	return nil
+}
+
+// sessionScratch returns the scratch directory of the session behind ctx,
+// or "" when it has none
+func (fs *FilesystemHandler) sessionScratch(ctx context.Context) string {
+	fs.scratch.mu.Lock()
+	defer fs.scratch.mu.Unlock()
+	return fs.scratch.sessions[sessionID(ctx)]
+}
+
+// checkScratchAccess rejects paths in the scratch directories of other
+// sessions, and the scratch root itself
+func (fs *FilesystemHandler) checkScratchAccess(ctx context.Context, path string) error {
+	if fs.scratch == nil || path == "" || isSMBPath(path) {
+		return nil
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil
+	}
+	candidates := []string{abs}
+	if real, err := filepath.EvalSymlinks(abs); err == nil && real != abs {
+		candidates = append(candidates, real)
+	}
+	own := fs.sessionScratch(ctx)
+	for _, p := range candidates {
+		if pathWithin(p, fs.scratch.root) && (own == "" || !pathWithin(p, own)) {
+			return toolError(CodePathNotAllowed, "access denied - %s belongs to another session's scratch directory; call get_scratch_dir for your own", path).With("path", path)
+		}
+	}
+	return nil
+}
+
+// ScratchToolCall wraps a tool handler so calls can only reach the scratch
+// directory of their own session, and modifying calls into it are refused
+// once it is over quota
+func (fs *FilesystemHandler) ScratchToolCall(tool string, next ToolHandlerFunc) ToolHandlerFunc {
+	if fs.scratch == nil {
+		return next
+	}
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.GetArguments()
+		var paths []string
+		for _, key := range pathArguments {
+			if path, ok := args[key].(string); ok {
+				paths = append(paths, path)
+			}
+		}
+		for _, key := range pathListArguments {
+			list, _ := args[key].([]any)
+			for _, p := range list {
+				if path, ok := p.(string); ok {
+					paths = append(paths, path)
+				}
+			}
+		}
+
+		own := fs.sessionScratch(ctx)
+		inOwn := false
+		for _, path := range paths {
+			if err := fs.checkScratchAccess(ctx, path); err != nil {
+				return errorResult(err), nil
+			}
+			if abs, err := filepath.Abs(path); err == nil && own != "" && pathWithin(abs, own) {
+				inOwn = true
+			}
+		}
+		if inOwn && isMutation(tool, args) {
+			used, err := dirSize(ctx, own)
+			if err != nil {
+				return errorResult(fmt.Errorf("Error measuring scratch directory: %w", err)), nil
+			}
+			content, _ := args["content"].(string)
+			if used+int64(len(content)) > fs.scratch.cfg.MaxSize {
+				return errorResult(toolError(CodeTooLarge, "Error: the scratch directory is over its quota of %s (%s used); delete files in it first",
+					formatFileSize(fs.scratch.cfg.MaxSize), formatFileSize(used)).With("limit", fs.scratch.cfg.MaxSize).With("used", used)), nil
+			}
+		}
+		return next(ctx, request)
+	}
+}
+
+// dirSize returns the total size of the files under dir
+func dirSize(ctx context.Context, dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size, err
+}
+
+// HandleGetScratchDir handles the get_scratch_dir tool
+func (fs *FilesystemHandler) HandleGetScratchDir(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	if fs.scratch == nil {
+		return errorResult(toolError(CodeUnavailable, "scratch directories are not enabled on this server")), nil
+	}
+	if fs.readOnlyMode() {
+		return errorResult(toolError(CodeReadOnly, "Error: access denied - server is in read-only mode")), nil
+	}
+
+	fs.scratch.mu.Lock()
+	dir, ok := fs.scratch.sessions[sessionID(ctx)]
+	if ok {
+		if _, err := os.Stat(dir); err != nil {
+			ok = false
+		}
+	}
+	if !ok {
+		suffix := make([]byte, 8)
+		if _, err := rand.Read(suffix); err != nil {
+			fs.scratch.mu.Unlock()
+			return errorResult(err), nil
+		}
+		dir = filepath.Join(fs.scratch.root, hex.EncodeToString(suffix))
+		if err := os.Mkdir(dir, 0700); err != nil {
+			fs.scratch.mu.Unlock()
+			return errorResult(fmt.Errorf("Error creating scratch directory: %w", err)), nil
+		}
+		fs.scratch.sessions[sessionID(ctx)] = dir
+	}
+	fs.scratch.mu.Unlock()
+
+	// Requesting the directory keeps it alive for another TTL
+	now := time.Now()
+	os.Chtimes(dir, now, now)
+	used, err := dirSize(ctx, dir)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error measuring scratch directory: %w", err)), nil
+	}
+	result := ScratchDir{
+		Path:      dir,
+		Used:      used,
+		MaxSize:   fs.scratch.cfg.MaxSize,
+		ExpiresAt: formatTime(now.Add(fs.scratch.cfg.TTL)),
+	}
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, result)
+	}
+	return mcp.NewToolResultText(fmt.Sprintf(
+		"Scratch directory: %s\nQuota: %s used of %s\nIt is removed when this session ends, or after %s without use (%s)",
+		dir, formatFileSize(used), formatFileSize(result.MaxSize), fs.scratch.cfg.TTL, result.ExpiresAt,
+	)), nil
+}
+
+// forgetScratch removes the scratch directory of a session that ended
+func (fs *FilesystemHandler) forgetScratch(id string) {
+	if fs.scratch == nil {
+		return
+	}
+	fs.scratch.mu.Lock()
+	dir, ok := fs.scratch.sessions[id]
+	delete(fs.scratch.sessions, id)
+	fs.scratch.mu.Unlock()
+	if ok {
+		if err := os.RemoveAll(dir); err != nil {
+			fs.logger.Warn("failed to remove scratch directory", "path", dir, "error", err)
+		}
+	}
+}
+
+// cleanScratch removes the scratch directories unused for longer than the
+// TTL, including those left behind by an earlier run of the server
+func (fs *FilesystemHandler) cleanScratch() {
+	entries, err := os.ReadDir(fs.scratch.root)
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-fs.scratch.cfg.TTL)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || info.ModTime().After(cutoff) {
+			continue
+		}
+		dir := filepath.Join(fs.scratch.root, entry.Name())
+		fs.scratch.mu.Lock()
+		for id, own := range fs.scratch.sessions {
+			if own == dir {
+				delete(fs.scratch.sessions, id)
+			}
+		}
+		fs.scratch.mu.Unlock()
+		if err := os.RemoveAll(dir); err != nil {
+			fs.logger.Warn("failed to remove expired scratch directory", "path", dir, "error", err)
+		} else {
+			fs.logger.Info("removed expired scratch directory", "path", dir)
+		}
+	}
+}
+
+// StartScratchCleanup removes expired scratch directories now and then
+// periodically until the handler is shut down
+func (fs *FilesystemHandler) StartScratchCleanup() {
+	if fs.scratch == nil {
+		return
+	}
+	fs.cleanScratch()
+	// Check a few times per TTL, between once a minute and once an hour
+	interval := fs.scratch.cfg.TTL / 4
+	if interval > time.Hour {
+		interval = time.Hour
+	} else if interval < time.Minute {
+		interval = time.Minute
+	}
+	fs.background.Add(1)
+	go func() {
+		defer fs.background.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-fs.stopped.Done():
+				return
+			case <-ticker.C:
+				fs.cleanScratch()
+			}
+		}
+	}()
+}
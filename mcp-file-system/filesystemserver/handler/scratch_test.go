@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScratchDir(t *testing.T) {
+	stateDir := t.TempDir()
+	fsHandler, err := NewFilesystemHandler([]string{t.TempDir()}, WithStateDir(stateDir), WithScratch(ScratchConfig{MaxSize: 10}))
+	require.NoError(t, err)
+
+	res := callTool(t, fsHandler.FormatToolCall(fsHandler.HandleGetScratchDir), map[string]any{"format": FormatJSON})
+	require.False(t, res.IsError, res.Content)
+	var scratch ScratchDir
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &scratch))
+	assert.DirExists(t, scratch.Path)
+	assert.Equal(t, int64(10), scratch.MaxSize)
+	root := resolveAllowedDirs(t, filepath.Join(stateDir, "scratch"))[0]
+	assert.True(t, pathWithin(scratch.Path, root))
+
+	// The same session gets the same directory back
+	res = callTool(t, fsHandler.HandleGetScratchDir, nil)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, scratch.Path)
+
+	// Writes into it count against the quota
+	write := fsHandler.ScratchToolCall("write_file", fsHandler.HandleWriteFile)
+	res = callTool(t, write, map[string]any{"path": filepath.Join(scratch.Path, "a.txt"), "content": "12345"})
+	require.False(t, res.IsError, res.Content)
+	res = callTool(t, write, map[string]any{"path": filepath.Join(scratch.Path, "b.txt"), "content": "123456"})
+	assert.Equal(t, CodeTooLarge, ResultError(res).Code)
+
+	// The scratch root and other sessions' directories are out of reach
+	other := filepath.Join(root, "other")
+	require.NoError(t, os.Mkdir(other, 0700))
+	fsHandler.scratch.sessions["other-session"] = other
+	list := fsHandler.ScratchToolCall("list_directory", fsHandler.HandleListDirectory)
+	for _, path := range []string{root, other, filepath.Join(other, "x.txt")} {
+		res = callTool(t, list, map[string]any{"path": path})
+		assert.Equal(t, CodePathNotAllowed, ResultError(res).Code, path)
+	}
+
+	fsHandler.forgetScratch("other-session")
+	assert.NoDirExists(t, other)
+}
+
+func TestCleanScratch(t *testing.T) {
+	fsHandler, err := NewFilesystemHandler([]string{t.TempDir()}, WithStateDir(t.TempDir()), WithScratch(ScratchConfig{TTL: time.Hour}))
+	require.NoError(t, err)
+	res := callTool(t, fsHandler.FormatToolCall(fsHandler.HandleGetScratchDir), map[string]any{"format": FormatJSON})
+	require.False(t, res.IsError, res.Content)
+	var scratch ScratchDir
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &scratch))
+
+	stale := filepath.Join(fsHandler.scratch.root, "stale")
+	require.NoError(t, os.Mkdir(stale, 0700))
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(stale, old, old))
+
+	fsHandler.cleanScratch()
+	assert.NoDirExists(t, stale)
+	assert.DirExists(t, scratch.Path)
+}
+
+func TestScratchDirNotEnabled(t *testing.T) {
+	fsHandler, err := NewFilesystemHandler([]string{t.TempDir()})
+	require.NoError(t, err)
+	res := callTool(t, fsHandler.HandleGetScratchDir, nil)
+	assert.Equal(t, CodeUnavailable, ResultError(res).Code)
+}
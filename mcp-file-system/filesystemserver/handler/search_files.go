@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/gobwas/glob"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -29,70 +31,59 @@ func (fs *FilesystemHandler) HandleSearchFiles(
 		// Get current working directory
 		cwd, err := os.Getwd()
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					mcp.TextContent{
-						Type: "text",
-						Text: fmt.Sprintf("Error resolving current directory: %v", err),
-					},
-				},
-				IsError: true,
-			}, nil
+			return errorResult(fmt.Errorf("Error resolving current directory: %w", err)), nil
 		}
 		path = cwd
 	}
 
 	validPath, err := fs.validatePath(path)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
 	}
 
 	// Check if it's a directory
 	info, err := os.Stat(validPath)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
 	}
 
 	if !info.IsDir() {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: "Error: Search path must be a directory",
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(toolError(CodeWrongType, "Error: Search path must be a directory")), nil
 	}
 
-	results, err := searchFiles(validPath, pattern, fs)
+	mode := request.GetString("mode", SearchModeGlob)
+	match, err := nameMatcher(pattern, mode, request.GetBool("case_sensitive", true))
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error searching files: %v",
-						err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(err), nil
+	}
+
+	filter, err := fs.newWalkFilter(request, validPath)
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	results, err := searchFiles(validPath, match, filter, fs)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error searching files: %w", err)), nil
+	}
+
+	if wantsJSON(ctx) {
+		now := time.Now()
+		found := SearchFilesResult{Path: validPath, Pattern: pattern, Mode: mode, Results: make([]DirectoryEntry, 0, len(results))}
+		for _, result := range results {
+			item := DirectoryEntry{Name: filepath.Base(result), Path: result, Type: "file", URI: pathToResourceURI(result)}
+			if info, err := os.Stat(result); err == nil {
+				item.FileTimes = localFileTimes(info, now)
+				item.Mode = fmt.Sprintf("%03o", info.Mode().Perm())
+				if info.IsDir() {
+					item.Type = "directory"
+				} else {
+					item.Size = info.Size()
+				}
+			}
+			found.Results = append(found.Results, item)
+		}
+		return jsonResult(ctx, found)
 	}
 
 	if len(results) == 0 {
@@ -135,9 +126,47 @@ func (fs *FilesystemHandler) HandleSearchFiles(
 	}, nil
 }
 
-func searchFiles(rootPath, pattern string, fs *FilesystemHandler) ([]string, error) {
+// Ways search_files matches names against its pattern
+const (
+	SearchModeGlob      = "glob"
+	SearchModeRegex     = "regex"
+	SearchModeSubstring = "substring"
+)
+
+// nameMatcher returns a function reporting whether a file name matches
+// pattern in the given mode. A regular expression only has to match part
+// of the name unless it is anchored with ^ and $.
+func nameMatcher(pattern, mode string, caseSensitive bool) (func(name string) bool, error) {
+	fold := func(s string) string { return s }
+	if !caseSensitive {
+		fold = strings.ToLower
+	}
+	switch mode {
+	case SearchModeGlob:
+		g, err := glob.Compile(fold(pattern))
+		if err != nil {
+			return nil, toolError(CodeInvalidArgument, "Error: invalid glob %q: %w", pattern, err)
+		}
+		return func(name string) bool { return g.Match(fold(name)) }, nil
+	case SearchModeRegex:
+		expr := pattern
+		if !caseSensitive {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, toolError(CodeInvalidArgument, "Error: invalid regular expression %q: %w", pattern, err)
+		}
+		return re.MatchString, nil
+	case SearchModeSubstring:
+		needle := fold(pattern)
+		return func(name string) bool { return strings.Contains(fold(name), needle) }, nil
+	}
+	return nil, toolError(CodeInvalidArgument, "Error: mode must be one of %s, %s, %s", SearchModeGlob, SearchModeRegex, SearchModeSubstring).With("mode", mode)
+}
+
+func searchFiles(rootPath string, match func(name string) bool, filter *walkFilter, fs *FilesystemHandler) ([]string, error) {
 	var results []string
-	globPattern := glob.MustCompile(pattern)
 
 	err := filepath.Walk(
 		rootPath,
@@ -151,7 +180,14 @@ func searchFiles(rootPath, pattern string, fs *FilesystemHandler) ([]string, err
 				return nil // Skip invalid paths
 			}
 
-			if globPattern.Match(info.Name()) {
+			if filter.skip(path, info.IsDir()) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if match(info.Name()) {
 				results = append(results, path)
 			}
 			return nil
@@ -161,4 +197,4 @@ func searchFiles(rootPath, pattern string, fs *FilesystemHandler) ([]string, err
 		return nil, err
 	}
 	return results, nil
-}
\ No newline at end of file
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
@@ -75,3 +76,44 @@ func TestSearchFiles_Pattern(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchFilesModes(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	names := []string{"test_api.py", "Test_Models.py", "api_test.py", "test_api.pyc", "README.md"}
+	for _, name := range names {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0644))
+	}
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	tests := []struct {
+		args    map[string]any
+		matches []string
+	}{
+		{map[string]any{"pattern": `^test_.*\.py$`, "mode": "regex"}, []string{"test_api.py"}},
+		{map[string]any{"pattern": `^test_.*\.py$`, "mode": "regex", "case_sensitive": false}, []string{"Test_Models.py", "test_api.py"}},
+		{map[string]any{"pattern": "test", "mode": "substring"}, []string{"api_test.py", "test_api.py", "test_api.pyc"}},
+		{map[string]any{"pattern": "readme", "mode": "substring", "case_sensitive": false}, []string{"README.md"}},
+		{map[string]any{"pattern": "test_*.py", "case_sensitive": false}, []string{"Test_Models.py", "test_api.py"}},
+	}
+	for _, test := range tests {
+		test.args["path"] = dir
+		test.args["format"] = FormatJSON
+		res := callTool(t, fsHandler.FormatToolCall(fsHandler.HandleSearchFiles), test.args)
+		require.False(t, res.IsError, res.Content)
+		var found SearchFilesResult
+		require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &found))
+		var got []string
+		for _, entry := range found.Results {
+			got = append(got, entry.Name)
+		}
+		assert.ElementsMatch(t, test.matches, got, test.args)
+	}
+
+	res := callTool(t, fsHandler.HandleSearchFiles, map[string]any{"path": dir, "pattern": "(", "mode": "regex"})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	res = callTool(t, fsHandler.HandleSearchFiles, map[string]any{"path": dir, "pattern": "[", "mode": "glob"})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	res = callTool(t, fsHandler.HandleSearchFiles, map[string]any{"path": dir, "pattern": "x", "mode": "fuzzy"})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+}
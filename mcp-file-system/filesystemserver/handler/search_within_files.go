@@ -4,9 +4,14 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -25,15 +30,7 @@ func (fs *FilesystemHandler) HandleSearchWithinFiles(
 		return nil, err
 	}
 	if substring == "" {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: "Error: substring cannot be empty",
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(toolError(CodeInvalidArgument, "Error: substring cannot be empty")), nil
 	}
 
 	// Extract optional depth parameter
@@ -41,104 +38,85 @@ func (fs *FilesystemHandler) HandleSearchWithinFiles(
 	if depthArg, err := request.RequireFloat("depth"); err == nil {
 		maxDepth = int(depthArg)
 		if maxDepth < 0 {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					mcp.TextContent{
-						Type: "text",
-						Text: "Error: depth cannot be negative",
-					},
-				},
-				IsError: true,
-			}, nil
+			return errorResult(toolError(CodeInvalidArgument, "Error: depth cannot be negative")), nil
 		}
 	}
 
 	// Extract optional max_results parameter
-	maxResults := MAX_SEARCH_RESULTS // default limit
+	maxResults := fs.currentLimits().MaxSearchResults // default limit
 	if maxResultsArg, err := request.RequireFloat("max_results"); err == nil {
 		maxResults = int(maxResultsArg)
 		if maxResults <= 0 {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					mcp.TextContent{
-						Type: "text",
-						Text: "Error: max_results must be positive",
-					},
-				},
-				IsError: true,
-			}, nil
+			return errorResult(toolError(CodeInvalidArgument, "Error: max_results must be positive")), nil
 		}
 	}
 
+	lines := contextLines{
+		before: request.GetInt("context_before", 0),
+		after:  request.GetInt("context_after", 0),
+	}
+	if lines.before < 0 || lines.before > maxContextLines || lines.after < 0 || lines.after > maxContextLines {
+		return errorResult(toolError(CodeInvalidArgument, "Error: context_before and context_after must be between 0 and %d", maxContextLines)), nil
+	}
+
 	// Handle empty or relative paths like "." or "./" by converting to absolute path
 	if path == "." || path == "./" {
 		// Get current working directory
 		cwd, err := os.Getwd()
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					mcp.TextContent{
-						Type: "text",
-						Text: fmt.Sprintf("Error resolving current directory: %v", err),
-					},
-				},
-				IsError: true,
-			}, nil
+			return errorResult(fmt.Errorf("Error resolving current directory: %w", err)), nil
 		}
 		path = cwd
 	}
 
 	validPath, err := fs.validatePath(path)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
 	}
 
 	// Check if the path is a directory
 	info, err := os.Stat(validPath)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
 	}
 
 	if !info.IsDir() {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: "Error: search path must be a directory",
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(toolError(CodeWrongType, "Error: search path must be a directory")), nil
 	}
 
 	// Perform the search
-	results, err := searchWithinFiles(validPath, substring, maxDepth, maxResults, fs)
+	filter, err := fs.newWalkFilter(request, validPath)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error searching within files: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(err), nil
+	}
+
+	results, err := searchWithinFiles(ctx, validPath, substring, maxDepth, maxResults, lines, filter, fs)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error searching within files: %w", err)), nil
+	}
+
+	if wantsJSON(ctx) {
+		found := SearchWithinFilesResult{
+			Path:      validPath,
+			Substring: substring,
+			Matches:   make([]ContentMatch, 0, len(results)),
+			Truncated: len(results) >= maxResults,
+		}
+		for _, result := range results {
+			start, end := snippetBounds(result.LineContent, result.Offset, len(substring))
+			found.Matches = append(found.Matches, ContentMatch{
+				Path:      result.FilePath,
+				Line:      result.LineNumber,
+				Column:    utf8.RuneCountInString(result.LineContent[:result.Offset]) + 1,
+				Match:     substring,
+				Snippet:   result.LineContent[start:end],
+				Truncated: start > 0 || end < len(result.LineContent),
+				URI:       result.ResourceURI,
+				Before:    result.Before,
+				After:     result.After,
+			})
+		}
+		return jsonResult(ctx, found)
 	}
 
 	if len(results) == 0 {
@@ -167,29 +145,42 @@ func (fs *FilesystemHandler) HandleSearchWithinFiles(
 		resourceURI := pathToResourceURI(filePath)
 		formattedResults.WriteString(fmt.Sprintf("File: %s (%s)\n", filePath, resourceURI))
 
-		for _, result := range fileResults {
-			// Truncate line content if too long (keeping context around the match)
-			lineContent := result.LineContent
-			if len(lineContent) > 100 {
-				// Find the substring position
-				substrPos := strings.Index(strings.ToLower(lineContent), strings.ToLower(substring))
-
-				// Calculate start and end positions for context
-				contextStart := max(0, substrPos-30)
-				contextEnd := min(len(lineContent), substrPos+len(substring)+30)
-
-				if contextStart > 0 {
-					lineContent = "..." + lineContent[contextStart:contextEnd]
-				} else {
-					lineContent = lineContent[:contextEnd]
+		// Context lines shared by nearby matches are shown once, and
+		// separate groups of lines are divided by "--" as grep does
+		shown := 0
+		for i, result := range fileResults {
+			next := math.MaxInt
+			if i+1 < len(fileResults) {
+				next = fileResults[i+1].LineNumber
+			}
+			first := result.LineNumber - len(result.Before)
+			if shown > 0 && first > shown+1 && lines != (contextLines{}) {
+				formattedResults.WriteString("  --\n")
+			}
+			for j, line := range result.Before {
+				if n := first + j; n > shown {
+					formattedResults.WriteString(fmt.Sprintf("  Line %d- %s\n", n, line))
 				}
+			}
 
-				if contextEnd < len(result.LineContent) {
-					lineContent += "..."
+			// Long lines are shortened to the context around the match
+			start, end := snippetBounds(result.LineContent, result.Offset, len(substring))
+			snippet := result.LineContent[start:end]
+			if start > 0 {
+				snippet = "..." + snippet
+			}
+			if end < len(result.LineContent) {
+				snippet += "..."
+			}
+			column := utf8.RuneCountInString(result.LineContent[:result.Offset]) + 1
+			formattedResults.WriteString(fmt.Sprintf("  Line %d, column %d: %s\n", result.LineNumber, column, snippet))
+			shown = result.LineNumber
+			for j, line := range result.After {
+				if n := result.LineNumber + 1 + j; n < next {
+					formattedResults.WriteString(fmt.Sprintf("  Line %d- %s\n", n, line))
+					shown = n
 				}
 			}
-
-			formattedResults.WriteString(fmt.Sprintf("  Line %d: %s\n", result.LineNumber, lineContent))
 		}
 		formattedResults.WriteString("\n")
 	}
@@ -209,25 +200,56 @@ func (fs *FilesystemHandler) HandleSearchWithinFiles(
 	}, nil
 }
 
-// searchWithinFiles searches for a substring within file contents
+// searchWithinFiles searches for a substring within file contents. The tree
+// is walked in order while up to tuning.WalkerParallelism files are scanned
+// at once; results are returned in walk order.
 func searchWithinFiles(
-	rootPath, substring string, maxDepth int, maxResults int, fs *FilesystemHandler,
+	ctx context.Context, rootPath, substring string, maxDepth int, maxResults int, lines contextLines, filter *walkFilter, fs *FilesystemHandler,
 ) ([]SearchResult, error) {
-	var results []SearchResult
-	resultCount := 0
-	currentDepth := 0
+	type candidate struct {
+		index int
+		path  string
+	}
+	var (
+		mu          sync.Mutex
+		fileResults = make(map[int][]SearchResult)
+		resultCount atomic.Int64
+		wg          sync.WaitGroup
+	)
+	candidates := make(chan candidate)
+	for range max(fs.tuning.WalkerParallelism, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range candidates {
+				matches := searchFile(c.path, substring, maxResults, lines)
+				if len(matches) == 0 {
+					continue
+				}
+				resultCount.Add(int64(len(matches)))
+				mu.Lock()
+				fileResults[c.index] = matches
+				mu.Unlock()
+			}
+		}()
+	}
 
 	// Walk the directory tree
+	index := 0
+	currentDepth := 0
 	err := filepath.Walk(
 		rootPath,
 		func(path string, info os.FileInfo, err error) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
 			if err != nil {
 				return nil // Skip errors and continue
 			}
 
 			// Check if we've reached the maximum number of results
-			if resultCount >= maxResults {
-				return filepath.SkipDir
+			if resultCount.Load() >= int64(maxResults) {
+				return filepath.SkipAll
 			}
 
 			// Try to validate path
@@ -236,6 +258,13 @@ func searchWithinFiles(
 				return nil // Skip invalid paths
 			}
 
+			if filter.skip(path, info.IsDir()) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
 			// Skip directories, only search files
 			if info.IsDir() {
 				// Calculate depth for this directory
@@ -259,7 +288,7 @@ func searchWithinFiles(
 			}
 
 			// Skip files that are too large
-			if info.Size() > MAX_SEARCHABLE_SIZE {
+			if info.Size() > fs.currentLimits().MaxSearchableSize {
 				return nil
 			}
 
@@ -269,56 +298,130 @@ func searchWithinFiles(
 				return nil
 			}
 
-			// Open the file and search for the substring
-			file, err := os.Open(validPath)
-			if err != nil {
-				return nil // Skip files that can't be opened
-			}
-			defer file.Close()
-
-			// Create a scanner to read the file line by line
-			scanner := bufio.NewScanner(file)
-			lineNum := 0
-
-			// Scan each line
-			for scanner.Scan() {
-				lineNum++
-				line := scanner.Text()
-
-				// Check if the line contains the substring
-				if strings.Contains(line, substring) {
-					// Add to results
-					results = append(results, SearchResult{
-						FilePath:    validPath,
-						LineNumber:  lineNum,
-						LineContent: line,
-						ResourceURI: pathToResourceURI(validPath),
-					})
-					resultCount++
-
-					// Check if we've reached the maximum results
-					if resultCount >= maxResults {
-						return filepath.SkipDir
-					}
-				}
-			}
-
-			// Check for scanner errors
-			if err := scanner.Err(); err != nil {
-				return nil // Skip files with scanning errors
-			}
-
+			candidates <- candidate{index: index, path: validPath}
+			index++
 			return nil
 		},
 	)
+	close(candidates)
+	wg.Wait()
 
 	if err != nil {
 		return nil, err
 	}
 
+	// Files are handed out in walk order, so every file before the last
+	// one scanned has been searched and the first maxResults are exact
+	var results []SearchResult
+	for i := range index {
+		results = append(results, fileResults[i]...)
+		if len(results) >= maxResults {
+			return results[:maxResults], nil
+		}
+	}
 	return results, nil
 }
 
+// maxContextLines caps context_before and context_after of search_within_files
+const maxContextLines = 20
+
+// contextLines is how many lines search_within_files returns before and
+// after each match
+type contextLines struct {
+	before, after int
+}
+
+// searchFile returns up to maxResults lines of the file containing
+// substring, with the lines around each that lines asks for
+func searchFile(path, substring string, maxResults int, lines contextLines) []SearchResult {
+	// Open the file and search for the substring
+	file, err := os.Open(path)
+	if err != nil {
+		return nil // Skip files that can't be opened
+	}
+	defer file.Close()
+
+	// Create a scanner to read the file line by line
+	var results []SearchResult
+	// recent holds the last lines.before lines; open are the matches still
+	// collecting lines after them
+	var recent []string
+	var open []int
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+
+		open = slices.DeleteFunc(open, func(i int) bool {
+			results[i].After = append(results[i].After, clipLine(line))
+			return len(results[i].After) == lines.after
+		})
+
+		// Check if the line contains the substring
+		if offset := strings.Index(line, substring); offset >= 0 && len(results) < maxResults {
+			results = append(results, SearchResult{
+				FilePath:    path,
+				LineNumber:  lineNum,
+				LineContent: line,
+				ResourceURI: pathToResourceURI(path),
+				Offset:      offset,
+				Before:      slices.Clone(recent),
+			})
+			if lines.after > 0 {
+				open = append(open, len(results)-1)
+			}
+		}
+		if len(results) >= maxResults && len(open) == 0 {
+			break
+		}
+		if lines.before > 0 {
+			recent = append(recent, clipLine(line))
+			if len(recent) > lines.before {
+				recent = recent[1:]
+			}
+		}
+	}
+	// Files with scanning errors keep the matches found so far
+	return results
+}
+
+// snippetLength is the longest line shown whole in search results; longer
+// lines are cut to snippetContext bytes either side of the match
+const (
+	snippetLength  = 100
+	snippetContext = 30
+)
+
+// snippetBounds returns the byte range of line to show around the match of
+// length n at offset. Cuts never split a character.
+func snippetBounds(line string, offset, n int) (start, end int) {
+	if len(line) <= snippetLength {
+		return 0, len(line)
+	}
+	start = max(0, offset-snippetContext)
+	for start > 0 && !utf8.RuneStart(line[start]) {
+		start--
+	}
+	end = min(len(line), offset+n+snippetContext)
+	for end < len(line) && !utf8.RuneStart(line[end]) {
+		end++
+	}
+	return start, end
+}
+
+// clipLine shortens a context line longer than snippetLength
+func clipLine(line string) string {
+	if len(line) <= snippetLength {
+		return line
+	}
+	end := snippetLength
+	for !utf8.RuneStart(line[end]) {
+		end--
+	}
+	return line[:end] + "..."
+}
+
 // Helper function since Go < 1.21 doesn't have min/max functions
 func min(a, b int) int {
 	if a < b {
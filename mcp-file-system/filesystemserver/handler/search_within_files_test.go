@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchWithinFilesParallel(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	for i := range 20 {
+		content := fmt.Sprintf("line one\nneedle %d\nneedle again %d\n", i, i)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%02d.txt", i)), []byte(content), 0644))
+	}
+	fsHandler, err := NewFilesystemHandler([]string{dir}, WithTuning(Tuning{WalkerParallelism: 4}))
+	require.NoError(t, err)
+
+	results, err := searchWithinFiles(context.Background(), dir, "needle", 0, 1000, contextLines{}, nil, fsHandler)
+	require.NoError(t, err)
+	require.Len(t, results, 40)
+	// Results keep walk order regardless of which worker scanned the file
+	for i, result := range results {
+		assert.Equal(t, filepath.Join(dir, fmt.Sprintf("file%02d.txt", i/2)), result.FilePath)
+		assert.Equal(t, 2+i%2, result.LineNumber)
+	}
+
+	// The first matches in walk order are kept when results are limited
+	results, err = searchWithinFiles(context.Background(), dir, "needle", 0, 5, contextLines{}, nil, fsHandler)
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+	assert.Equal(t, filepath.Join(dir, "file02.txt"), results[4].FilePath)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = searchWithinFiles(ctx, dir, "needle", 0, 1000, contextLines{}, nil, fsHandler)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestSearchWithinFilesJSON(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	long := strings.Repeat("é", 60) + " needle " + strings.Repeat("x", 80)
+	file := filepath.Join(dir, "a.txt")
+	require.NoError(t, os.WriteFile(file, []byte("first line\n  a needle here\n"+long+"\n"), 0644))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	res := callTool(t, fsHandler.FormatToolCall(fsHandler.HandleSearchWithinFiles), map[string]any{"path": dir, "substring": "needle", "format": FormatJSON})
+	require.False(t, res.IsError)
+	var found SearchWithinFilesResult
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &found))
+	require.Len(t, found.Matches, 2)
+	assert.Equal(t, ContentMatch{
+		Path: file, Line: 2, Column: 5, Match: "needle", Snippet: "  a needle here", URI: pathToResourceURI(file),
+	}, found.Matches[0])
+
+	hit := found.Matches[1]
+	assert.Equal(t, 3, hit.Line)
+	assert.Equal(t, 62, hit.Column)
+	assert.True(t, hit.Truncated)
+	assert.Equal(t, strings.Repeat("é", 15)+" needle "+strings.Repeat("x", 29), hit.Snippet)
+
+	res = callTool(t, fsHandler.HandleSearchWithinFiles, map[string]any{"path": dir, "substring": "needle"})
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Line 2, column 5:   a needle here\n")
+}
+
+func TestSearchWithinFilesContext(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	file := filepath.Join(dir, "a.go")
+	lines := []string{"one", "two", "needle three", "four", "needle five", "six", "seven", "eight", "nine", "needle ten", strings.Repeat("x", 150)}
+	require.NoError(t, os.WriteFile(file, []byte(strings.Join(lines, "\n")+"\n"), 0644))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	args := map[string]any{"path": dir, "substring": "needle", "context_before": 2, "context_after": 1, "format": FormatJSON}
+	res := callTool(t, fsHandler.FormatToolCall(fsHandler.HandleSearchWithinFiles), args)
+	require.False(t, res.IsError)
+	var found SearchWithinFilesResult
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &found))
+	require.Len(t, found.Matches, 3)
+	assert.Equal(t, []string{"one", "two"}, found.Matches[0].Before)
+	assert.Equal(t, []string{"four"}, found.Matches[0].After)
+	assert.Equal(t, []string{"needle three", "four"}, found.Matches[1].Before)
+	assert.Equal(t, []string{"eight", "nine"}, found.Matches[2].Before)
+	assert.Equal(t, []string{strings.Repeat("x", 100) + "..."}, found.Matches[2].After)
+
+	// Overlapping context is shown once, and separate groups are divided
+	delete(args, "format")
+	res = callTool(t, fsHandler.HandleSearchWithinFiles, args)
+	require.False(t, res.IsError)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "  Line 1- one\n  Line 2- two\n  Line 3, column 1: needle three\n"+
+		"  Line 4- four\n  Line 5, column 1: needle five\n  Line 6- six\n  --\n  Line 8- eight\n  Line 9- nine\n  Line 10, column 1: needle ten\n  Line 11- ")
+
+	// The last match still collects its context once max_results is reached
+	results, err := searchWithinFiles(context.Background(), dir, "needle", 0, 1, contextLines{after: 2}, nil, fsHandler)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, []string{"four", "needle five"}, results[0].After)
+
+	res = callTool(t, fsHandler.HandleSearchWithinFiles, map[string]any{"path": dir, "substring": "needle", "context_after": 21})
+	require.True(t, res.IsError)
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mark3labs/mcp-filesystem-server/buildinfo"
+	"github.com/mark3labs/mcp-filesystem-server/selfupdate"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// executablePath locates the running binary; replaceable in tests
+var executablePath = func() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(exe)
+}
+
+// WithSelfUpdate sets where self_update looks for releases and how it
+// verifies them. nil disables self_update, e.g. for tenants, which must not
+// replace the binary they share.
+func WithSelfUpdate(cfg *selfupdate.Config) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		fs.selfUpdate = cfg
+	}
+}
+
+// HandleSelfUpdate checks for a newer release and, unless only asked to
+// check, installs it in place of the running binary. The running server
+// keeps serving the old version until it is restarted.
+func (fs *FilesystemHandler) HandleSelfUpdate(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	if fs.selfUpdate == nil {
+		return errorResult(toolError(CodeUnavailable, "self_update is not available on this server")), nil
+	}
+	checkOnly := request.GetBool("check_only", false)
+	version := request.GetString("version", "")
+
+	updater, err := selfupdate.New(*fs.selfUpdate)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	release, err := updater.Latest(ctx, version)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	current := buildinfo.Get().Version
+	if version == "" && !selfupdate.Newer(release.Version, current) {
+		return mcp.NewToolResultText(fmt.Sprintf("No update available: running %s, latest release is %s.", current, release.Version)), nil
+	}
+	if checkOnly {
+		return mcp.NewToolResultText(fmt.Sprintf("Update available: %s -> %s.", current, release.Version)), nil
+	}
+
+	exe, err := executablePath()
+	if err != nil {
+		return errorResult(fmt.Errorf("Error locating the server binary: %w", err)), nil
+	}
+	if fs.dryRunMode() {
+		return dryRunResult(fmt.Sprintf("Would replace %s (%s) with %s", exe, current, release.Version)), nil
+	}
+	if err := updater.Apply(ctx, release, exe); err != nil {
+		return errorResult(toolError(CodeExternalFailed, "Update to %s failed: %w", release.Version, err)), nil
+	}
+	fs.logger.Info("server binary updated", "from", current, "to", release.Version, "path", exe)
+	return mcp.NewToolResultText(fmt.Sprintf(
+		"Updated %s from %s to %s. Restart the server to run the new version. The previous binary was kept as %s; run 'mcp-filesystem-server --self-update rollback' to restore it.",
+		exe, current, release.Version, selfupdate.BackupPath(exe),
+	)), nil
+}
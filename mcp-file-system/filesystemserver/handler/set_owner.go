@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/user"
+	"runtime"
+	"strconv"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// SetOwnerResult is the JSON result of set_owner
+type SetOwnerResult struct {
+	Path    string `json:"path"`
+	UID     int    `json:"uid"`
+	GID     int    `json:"gid"`
+	Owner   string `json:"owner,omitempty"`
+	Group   string `json:"group,omitempty"`
+	OldUID  int    `json:"old_uid"`
+	OldGID  int    `json:"old_gid"`
+	Changed bool   `json:"changed"`
+}
+
+// HandleSetOwner handles the set_owner tool
+func (fs *FilesystemHandler) HandleSetOwner(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	if runtime.GOOS == "windows" {
+		return errorResult(toolError(CodeUnavailable, "Error: set_owner is not supported on Windows, where files have no Unix owner or group")), nil
+	}
+	ownerArg := request.GetString("owner", "")
+	groupArg := request.GetString("group", "")
+	if ownerArg == "" && groupArg == "" {
+		return errorResult(toolError(CodeInvalidArgument, "Error: give owner, group or both")), nil
+	}
+	uid, err := lookupOwnerID("owner", ownerArg)
+	if err != nil {
+		return errorResult(err), nil
+	}
+	gid, err := lookupOwnerID("group", groupArg)
+	if err != nil {
+		return errorResult(err), nil
+	}
+	dryRun := request.GetBool("dry_run", false)
+
+	// A dry run only previews the change, so the path need not be writable
+	validate := fs.validateWritablePath
+	if dryRun {
+		validate = fs.validatePath
+	}
+	validPath, err := validate(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	info, err := os.Stat(validPath)
+	if os.IsNotExist(err) {
+		return errorResult(toolError(CodeNotFound, "Error: %s does not exist", path).With("path", path)), nil
+	}
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	oldUID, oldGID, ok := fileOwner(info)
+	if !ok {
+		return errorResult(toolError(CodeUnavailable, "Error: the owner of %s cannot be read on %s", path, runtime.GOOS)), nil
+	}
+
+	// An owner or group not given is left as it is
+	result := SetOwnerResult{Path: validPath, UID: uid, GID: gid, OldUID: oldUID, OldGID: oldGID}
+	if uid < 0 {
+		result.UID = oldUID
+	}
+	if gid < 0 {
+		result.GID = oldGID
+	}
+	result.Owner, result.Group = ownerNames(result.UID, result.GID)
+	result.Changed = result.UID != oldUID || result.GID != oldGID
+
+	if dryRun || fs.dryRunMode() {
+		if !result.Changed {
+			return dryRunResult(fmt.Sprintf("%s is already owned by %s", validPath, describeOwner(result))), nil
+		}
+		return dryRunResult(fmt.Sprintf("Would change the owner of %s from %d:%d to %s", validPath, oldUID, oldGID, describeOwner(result))), nil
+	}
+	if result.Changed {
+		if err := os.Chown(validPath, uid, gid); err != nil {
+			return errorResult(fmt.Errorf("Error changing owner: %w", err)), nil
+		}
+	}
+
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, result)
+	}
+	if !result.Changed {
+		return mcp.NewToolResultText(fmt.Sprintf("%s is already owned by %s", validPath, describeOwner(result))), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Changed the owner of %s from %d:%d to %s", validPath, oldUID, oldGID, describeOwner(result))), nil
+}
+
+// lookupOwnerID returns the id of a user or group given by name or number,
+// or -1 when value is empty
+func lookupOwnerID(kind, value string) (int, error) {
+	if value == "" {
+		return -1, nil
+	}
+	if id, err := strconv.Atoi(value); err == nil {
+		if id < 0 {
+			return 0, toolError(CodeInvalidArgument, "Error: %s must not be negative", kind).With(kind, value)
+		}
+		return id, nil
+	}
+	var id string
+	if kind == "owner" {
+		u, err := user.Lookup(value)
+		if err != nil {
+			return 0, toolError(CodeInvalidArgument, "Error: unknown user %q", value).With(kind, value)
+		}
+		id = u.Uid
+	} else {
+		g, err := user.LookupGroup(value)
+		if err != nil {
+			return 0, toolError(CodeInvalidArgument, "Error: unknown group %q", value).With(kind, value)
+		}
+		id = g.Gid
+	}
+	return strconv.Atoi(id)
+}
+
+// ownerNames returns the names of a user and group, or "" for ids without
+// a name
+func ownerNames(uid, gid int) (owner, group string) {
+	if u, err := user.LookupId(strconv.Itoa(uid)); err == nil {
+		owner = u.Username
+	}
+	if g, err := user.LookupGroupId(strconv.Itoa(gid)); err == nil {
+		group = g.Name
+	}
+	return owner, group
+}
+
+// describeOwner formats the new owner and group, like ls -l with ids
+func describeOwner(result SetOwnerResult) string {
+	owner, group := strconv.Itoa(result.UID), strconv.Itoa(result.GID)
+	if result.Owner != "" {
+		owner = fmt.Sprintf("%s (%d)", result.Owner, result.UID)
+	}
+	if result.Group != "" {
+		group = fmt.Sprintf("%s (%d)", result.Group, result.GID)
+	}
+	return owner + ":" + group
+}
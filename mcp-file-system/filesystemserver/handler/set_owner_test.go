@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetOwner(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	path := filepath.Join(dir, "app.conf")
+	require.NoError(t, os.WriteFile(path, []byte("port=80\n"), 0644))
+
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	set := fsHandler.FormatToolCall(fsHandler.HandleSetOwner)
+
+	if runtime.GOOS == "windows" {
+		res := callTool(t, set, map[string]any{"path": path, "owner": "0"})
+		require.True(t, res.IsError)
+		assert.Equal(t, CodeUnavailable, ResultError(res).Code)
+		return
+	}
+
+	// Giving a file to its own owner and group works without privileges
+	uid, gid := os.Getuid(), os.Getgid()
+	res := callTool(t, set, map[string]any{"path": path, "owner": strconv.Itoa(uid), "group": strconv.Itoa(gid), "format": FormatJSON})
+	require.False(t, res.IsError, res.Content)
+	var got SetOwnerResult
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &got))
+	assert.Equal(t, uid, got.UID)
+	assert.Equal(t, gid, got.GID)
+	assert.False(t, got.Changed)
+	if u, err := user.Current(); err == nil {
+		res = callTool(t, set, map[string]any{"path": path, "owner": u.Username})
+		require.False(t, res.IsError, res.Content)
+		assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "already owned by "+u.Username)
+	}
+
+	if os.Geteuid() == 0 {
+		res = callTool(t, set, map[string]any{"path": path, "group": "1", "dry_run": true})
+		require.False(t, res.IsError, res.Content)
+		assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Would change the owner of "+path)
+		res = callTool(t, set, map[string]any{"path": path, "owner": "1", "group": "1"})
+		require.False(t, res.IsError, res.Content)
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		owner, group, _ := fileOwner(info)
+		assert.Equal(t, []int{1, 1}, []int{owner, group})
+	}
+
+	res = callTool(t, set, map[string]any{"path": path})
+	require.True(t, res.IsError)
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	res = callTool(t, set, map[string]any{"path": path, "owner": "no-such-user-here"})
+	require.True(t, res.IsError)
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	res = callTool(t, set, map[string]any{"path": filepath.Join(dir, "missing"), "owner": strconv.Itoa(uid)})
+	require.True(t, res.IsError)
+	assert.Equal(t, CodeNotFound, ResultError(res).Code)
+}
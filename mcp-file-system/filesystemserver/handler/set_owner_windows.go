@@ -0,0 +1,10 @@
+//go:build windows
+
+package handler
+
+import "os"
+
+// fileOwner is not available on Windows, where files have no Unix owner
+func fileOwner(info os.FileInfo) (uid, gid int, ok bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// SetPermissionsResult is the JSON result of set_permissions
+type SetPermissionsResult struct {
+	Path    string `json:"path"`
+	OldMode string `json:"old_mode"`
+	Mode    string `json:"mode"`
+	Changed bool   `json:"changed"`
+}
+
+// HandleSetPermissions handles the set_permissions tool
+func (fs *FilesystemHandler) HandleSetPermissions(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	modeArg, err := request.RequireString("mode")
+	if err != nil {
+		return nil, err
+	}
+	mode, err := parseMode("mode", modeArg)
+	if err != nil {
+		return errorResult(err), nil
+	}
+	if mode == nil {
+		return errorResult(toolError(CodeInvalidArgument, "Error: mode must be an octal mode such as 644")), nil
+	}
+	dryRun := request.GetBool("dry_run", false)
+
+	// A dry run only previews the change, so the path need not be writable
+	validate := fs.validateWritablePath
+	if dryRun {
+		validate = fs.validatePath
+	}
+	validPath, err := validate(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	info, err := os.Stat(validPath)
+	if os.IsNotExist(err) {
+		return errorResult(toolError(CodeNotFound, "Error: %s does not exist", path).With("path", path)), nil
+	}
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+
+	result := SetPermissionsResult{
+		Path:    validPath,
+		OldMode: fmt.Sprintf("%03o", info.Mode().Perm()),
+		Mode:    fmt.Sprintf("%03o", *mode),
+		Changed: info.Mode().Perm() != *mode,
+	}
+	if dryRun || fs.dryRunMode() {
+		if !result.Changed {
+			return dryRunResult(fmt.Sprintf("%s already has mode %s", validPath, result.Mode)), nil
+		}
+		return dryRunResult(fmt.Sprintf("Would change %s from %s to %s", validPath, result.OldMode, result.Mode)), nil
+	}
+	if result.Changed {
+		if err := os.Chmod(validPath, *mode); err != nil {
+			return errorResult(fmt.Errorf("Error setting permissions: %w", err)), nil
+		}
+		// Windows only keeps the read-only bit, so report what was applied
+		if info, err := os.Stat(validPath); err == nil {
+			result.Mode = fmt.Sprintf("%03o", info.Mode().Perm())
+		}
+	}
+
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, result)
+	}
+	if !result.Changed {
+		return mcp.NewToolResultText(fmt.Sprintf("%s already has mode %s", validPath, result.Mode)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Changed the mode of %s from %s to %s", validPath, result.OldMode, result.Mode)), nil
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission modes are not supported on Windows")
+	}
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	script := filepath.Join(dir, "run.sh")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\n"), 0644))
+	modeOf := func(path string) os.FileMode {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		return info.Mode().Perm()
+	}
+
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	set := fsHandler.FormatToolCall(fsHandler.HandleSetPermissions)
+
+	res := callTool(t, set, map[string]any{"path": script, "mode": "755", "dry_run": true})
+	require.False(t, res.IsError, res.Content)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Would change "+script+" from 644 to 755")
+	assert.Equal(t, os.FileMode(0644), modeOf(script))
+
+	res = callTool(t, set, map[string]any{"path": script, "mode": "0755", "format": FormatJSON})
+	require.False(t, res.IsError, res.Content)
+	var got SetPermissionsResult
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &got))
+	assert.Equal(t, SetPermissionsResult{Path: script, OldMode: "644", Mode: "755", Changed: true}, got)
+	assert.Equal(t, os.FileMode(0755), modeOf(script))
+
+	res = callTool(t, set, map[string]any{"path": script, "mode": "755"})
+	require.False(t, res.IsError, res.Content)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "already has mode 755")
+
+	// Invalid modes, missing files and paths outside the allowed
+	// directories are rejected
+	res = callTool(t, set, map[string]any{"path": script, "mode": "u+x"})
+	require.True(t, res.IsError)
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	res = callTool(t, set, map[string]any{"path": script, "mode": "1777"})
+	require.True(t, res.IsError)
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	res = callTool(t, set, map[string]any{"path": filepath.Join(dir, "missing"), "mode": "600"})
+	require.True(t, res.IsError)
+	assert.Equal(t, CodeNotFound, ResultError(res).Code)
+	res = callTool(t, set, map[string]any{"path": filepath.Join(t.TempDir(), "secret"), "mode": "600"})
+	require.True(t, res.IsError)
+}
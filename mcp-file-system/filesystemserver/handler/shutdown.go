@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// errShuttingDown is returned to tool calls that arrive after Shutdown
+const errShuttingDown = "server is shutting down"
+
+// TrackToolCall wraps a tool handler so Shutdown can wait for the call to
+// finish. Calls made once shutdown has begun are rejected, and calls still
+// running when the drain deadline passes have their context cancelled.
+func (fs *FilesystemHandler) TrackToolCall(next ToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		fs.shutdownMu.Lock()
+		if fs.closing {
+			fs.shutdownMu.Unlock()
+			return errorResult(toolError(CodeUnavailable, errShuttingDown)), nil
+		}
+		fs.calls.Add(1)
+		fs.shutdownMu.Unlock()
+		defer fs.calls.Done()
+
+		ctx, cancel := fs.untilStopped(ctx)
+		defer cancel()
+		return next(ctx, request)
+	}
+}
+
+// Shutdown prepares the handler for process exit. New tool calls are
+// rejected and in-flight calls are given until ctx is done to finish; any
+// still running after that are cancelled. Croc transfers are then
+// terminated, scheduled backups and file watchers stopped, and pending
+// webhook deliveries, notifications and usage statistics flushed, and the
+// operation log is closed. It returns ctx.Err() if in-flight work did not finish in time.
+func (fs *FilesystemHandler) Shutdown(ctx context.Context) error {
+	fs.shutdownMu.Lock()
+	if fs.closing {
+		fs.shutdownMu.Unlock()
+		return nil
+	}
+	fs.closing = true
+	fs.shutdownMu.Unlock()
+
+	drained := waitGroup(ctx, &fs.calls)
+	if !drained {
+		fs.logger.Warn("shutdown deadline reached, cancelling in-flight tool calls")
+	}
+	fs.stop()
+
+	crocManager.CleanupAllProcesses()
+	// Croc monitors, backup schedules and watchers exit once stopped
+	if !waitGroup(ctx, &fs.background) || !waitGroup(ctx, &fs.eventWG) || !drained {
+		return ctx.Err()
+	}
+	if fs.telemetry != nil {
+		fs.telemetry.close(ctx)
+	}
+	fs.closeJournals()
+	if fs.operations != nil {
+		return fs.operations.close()
+	}
+	return nil
+}
+
+// untilStopped returns a context that is also cancelled when Shutdown stops
+// the handler
+func (fs *FilesystemHandler) untilStopped(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(fs.stopped, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// waitGroup waits for wg until ctx is done and reports whether it finished
+func waitGroup(ctx context.Context, wg *sync.WaitGroup) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
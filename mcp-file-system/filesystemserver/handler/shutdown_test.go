@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdownDrainsToolCalls(t *testing.T) {
+	fsHandler, err := NewFilesystemHandler(resolveAllowedDirs(t, t.TempDir()))
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	slow := fsHandler.TrackToolCall(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		<-release
+		return mcp.NewToolResultText("done"), nil
+	})
+	result := make(chan *mcp.CallToolResult, 1)
+	go func() {
+		res, _ := slow(context.Background(), mcp.CallToolRequest{})
+		result <- res
+	}()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- fsHandler.Shutdown(context.Background())
+	}()
+
+	// New calls are rejected while the slow one drains
+	require.Eventually(t, func() bool {
+		res, err := fsHandler.TrackToolCall(fsHandler.HandleListAllowedDirectories)(context.Background(), mcp.CallToolRequest{})
+		return err == nil && res.IsError
+	}, time.Second, 10*time.Millisecond)
+	select {
+	case <-shutdown:
+		t.Fatal("shutdown returned before the in-flight call finished")
+	default:
+	}
+
+	close(release)
+	assert.NoError(t, <-shutdown)
+	assert.False(t, (<-result).IsError)
+}
+
+func TestShutdownCancelsOverdueToolCalls(t *testing.T) {
+	fsHandler, err := NewFilesystemHandler(resolveAllowedDirs(t, t.TempDir()))
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	stuck := fsHandler.TrackToolCall(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+		return mcp.NewToolResultError("operation cancelled"), nil
+	})
+	go stuck(context.Background(), mcp.CallToolRequest{})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, fsHandler.Shutdown(ctx), context.DeadlineExceeded)
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("in-flight call was not cancelled after the deadline")
+	}
+}
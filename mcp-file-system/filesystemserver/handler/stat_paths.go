@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// PathStatus is the state of one path checked by stat_paths
+type PathStatus struct {
+	// Path is the path as given; ResolvedPath is where it leads
+	Path         string `json:"path"`
+	ResolvedPath string `json:"resolved_path,omitempty"`
+	// Allowed is false for paths outside the allowed directories
+	Allowed bool `json:"allowed"`
+	// Access is "rw" or "ro" for allowed paths
+	Access       string `json:"access,omitempty"`
+	Exists       bool   `json:"exists"`
+	Type         string `json:"type,omitempty"`
+	Size         int64  `json:"size"`
+	Modified     string `json:"modified,omitempty"`
+	ModifiedUnix int64  `json:"modified_unix,omitempty"`
+	// Error and Code describe why a path could not be checked
+	Error string `json:"error,omitempty"`
+	Code  string `json:"code,omitempty"`
+}
+
+// StatPathsResult is the JSON result of stat_paths
+type StatPathsResult struct {
+	Paths    []PathStatus `json:"paths"`
+	Existing int          `json:"existing"`
+	Missing  int          `json:"missing"`
+	Denied   int          `json:"denied"`
+}
+
+// HandleStatPaths handles the stat_paths tool
+func (fs *FilesystemHandler) HandleStatPaths(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	paths, err := request.RequireStringSlice("paths")
+	if err != nil {
+		return errorResult(toolError(CodeInvalidArgument, "Error: %w", err)), nil
+	}
+	if limit := fs.currentLimits().MaxSearchResults; len(paths) > limit {
+		return errorResult(toolError(CodeTooLarge, "Error: at most %d paths can be checked in one call", limit).With("limit", limit)), nil
+	}
+
+	result := StatPathsResult{Paths: make([]PathStatus, len(paths))}
+	for i, path := range paths {
+		status := fs.statPath(path)
+		switch {
+		case !status.Allowed:
+			result.Denied++
+		case status.Exists:
+			result.Existing++
+		case status.Error == "":
+			result.Missing++
+		}
+		result.Paths[i] = status
+	}
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, result)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d of %d path(s) exist, %d missing, %d outside the allowed directories:\n",
+		result.Existing, len(paths), result.Missing, result.Denied))
+	for _, status := range result.Paths {
+		sb.WriteString(fmt.Sprintf("  %s: ", status.Path))
+		switch {
+		case status.Error != "":
+			sb.WriteString(fmt.Sprintf("error: %s\n", status.Error))
+		case status.Exists:
+			sb.WriteString(fmt.Sprintf("%s, %s, modified %s [%s]\n", status.Type, formatFileSize(status.Size), status.Modified, status.Access))
+		default:
+			sb.WriteString(fmt.Sprintf("missing [%s]\n", status.Access))
+		}
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// statPath checks a single path without failing on missing or denied ones
+func (fs *FilesystemHandler) statPath(path string) PathStatus {
+	status := PathStatus{Path: path, Allowed: true}
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		status.Code = errorCode(err)
+		switch status.Code {
+		case CodePathNotAllowed:
+			status.Allowed = false
+		case CodeNotFound:
+			// The parent is missing too, which still makes the path missing
+			abs, _ := filepath.Abs(path)
+			status.Access, status.Code = fs.accessLevel(abs), ""
+			return status
+		}
+		status.Error = err.Error()
+		return status
+	}
+	status.ResolvedPath = validPath
+	status.Access = fs.accessLevel(validPath)
+	info, err := os.Stat(validPath)
+	if os.IsNotExist(err) {
+		return status
+	}
+	if err != nil {
+		status.Error, status.Code = err.Error(), errorCode(err)
+		return status
+	}
+	status.Exists = true
+	status.Type = "file"
+	if info.IsDir() {
+		status.Type = "directory"
+	}
+	status.Size = info.Size()
+	status.Modified = formatTime(info.ModTime())
+	status.ModifiedUnix = unixTime(info.ModTime())
+	return status
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatPaths(t *testing.T) {
+	dirs := resolveAllowedDirs(t, t.TempDir(), t.TempDir())
+	dir, ref := dirs[0], dirs[1]
+	file := filepath.Join(dir, "input.csv")
+	require.NoError(t, os.WriteFile(file, []byte("a,b\n"), 0644))
+	outside := filepath.Join(filepath.Dir(dir), "elsewhere.txt")
+
+	fsHandler, err := NewFilesystemHandler(dirs, WithReadOnlyDirs([]string{ref}))
+	require.NoError(t, err)
+	stat := fsHandler.FormatToolCall(fsHandler.HandleStatPaths)
+
+	res := callTool(t, stat, map[string]any{"format": FormatJSON, "paths": []any{
+		file,
+		dir,
+		filepath.Join(dir, "out", "report.pdf"),
+		filepath.Join(ref, "new.txt"),
+		outside,
+	}})
+	require.False(t, res.IsError, res.Content)
+	var got StatPathsResult
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &got))
+	require.Len(t, got.Paths, 5)
+	assert.Equal(t, 2, got.Existing)
+	assert.Equal(t, 2, got.Missing)
+	assert.Equal(t, 1, got.Denied)
+
+	assert.True(t, got.Paths[0].Exists)
+	assert.Equal(t, "file", got.Paths[0].Type)
+	assert.Equal(t, int64(4), got.Paths[0].Size)
+	assert.NotEmpty(t, got.Paths[0].Modified)
+	assert.Equal(t, "rw", got.Paths[0].Access)
+	assert.Equal(t, "directory", got.Paths[1].Type)
+	assert.Equal(t, PathStatus{Path: filepath.Join(dir, "out", "report.pdf"), Allowed: true, Access: "rw"}, got.Paths[2])
+	assert.False(t, got.Paths[3].Exists)
+	assert.Equal(t, "ro", got.Paths[3].Access)
+	assert.False(t, got.Paths[4].Allowed)
+	assert.Equal(t, CodePathNotAllowed, got.Paths[4].Code)
+
+	res = callTool(t, stat, map[string]any{"paths": []any{file, outside}})
+	require.False(t, res.IsError, res.Content)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "1 of 2 path(s) exist, 0 missing, 1 outside the allowed directories")
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// LinkResult is the JSON result of create_symlink and read_link
+type LinkResult struct {
+	Path string `json:"path"`
+	// Target is the link's text, and ResolvedTarget the absolute path it
+	// finally leads to
+	Target         string `json:"target"`
+	ResolvedTarget string `json:"resolved_target"`
+	TargetExists   bool   `json:"target_exists"`
+	// TargetOutsideAllowed is set for a link leading out of the allowed
+	// directories, which no tool follows
+	TargetOutsideAllowed bool `json:"target_outside_allowed,omitempty"`
+}
+
+// HandleCreateSymlink handles the create_symlink tool
+func (fs *FilesystemHandler) HandleCreateSymlink(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	target, err := request.RequireString("target")
+	if err != nil {
+		return nil, err
+	}
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return errorResult(toolError(CodeInvalidArgument, "Error: invalid path: %w", err).With("path", path)), nil
+	}
+	if _, err := os.Lstat(abs); err == nil {
+		return errorResult(toolError(CodeAlreadyExists, "Error: %s already exists", path).With("path", path)), nil
+	}
+	validPath, err := fs.validateCreatablePath(abs, request.GetBool("create_parents", false))
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+
+	// A relative target is relative to the link's directory, and wherever
+	// it leads must be allowed, so the link cannot be used to escape
+	resolved, err := resolveLinkTarget(filepath.Dir(validPath), target)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: invalid target: %w", err)), nil
+	}
+	if _, err := fs.validatePath(resolved); err != nil {
+		return errorResult(fmt.Errorf("Error: invalid target: %w", err)), nil
+	}
+	if request.GetBool("relative", false) && filepath.IsAbs(target) {
+		if target, err = filepath.Rel(filepath.Dir(validPath), target); err != nil {
+			return errorResult(toolError(CodeInvalidArgument, "Error: %s cannot be made relative to the link: %w", resolved, err)), nil
+		}
+	}
+	_, statErr := os.Stat(resolved)
+
+	if fs.dryRunMode() {
+		var lines []string
+		for _, dir := range missingParents(validPath) {
+			lines = append(lines, fmt.Sprintf("Would create directory %s", dir))
+		}
+		return dryRunResult(append(lines, fmt.Sprintf("Would create symbolic link %s -> %s", validPath, target))...), nil
+	}
+	if err := os.MkdirAll(filepath.Dir(validPath), 0755); err != nil {
+		return errorResult(fmt.Errorf("Error creating parent directories: %w", err)), nil
+	}
+	if err := os.Symlink(target, validPath); err != nil {
+		return errorResult(fmt.Errorf("Error creating symbolic link: %w", err)), nil
+	}
+
+	result := LinkResult{Path: validPath, Target: target, ResolvedTarget: resolved, TargetExists: statErr == nil}
+	// The filesystem may have changed since the target was checked; a
+	// dangling link keeps the resolution above
+	if link, ok := fs.inspectSymlink(validPath); ok && !link.missing {
+		if link.outside {
+			os.Remove(validPath)
+			return errorResult(toolError(CodePathNotAllowed, "Error: invalid target: %s leads outside the allowed directories", target).With("target", link.resolved)), nil
+		}
+		result.ResolvedTarget = link.resolved
+	}
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, result)
+	}
+	text := fmt.Sprintf("Created symbolic link %s -> %s", validPath, target)
+	if !result.TargetExists {
+		text += "\nThe target does not exist yet"
+	}
+	return mcp.NewToolResultText(text), nil
+}
+
+// resolveLinkTarget resolves the target of a link in dir the way the kernel
+// will: symbolic links are followed before ".." is applied, so s/.. is the
+// parent of wherever s leads, not the directory holding s. Missing parts of
+// dir are created as plain directories, but a missing part of target may
+// later become a link, so ".." after one is rejected rather than guessed.
+func resolveLinkTarget(dir, target string) (string, error) {
+	base := resolveExisting(dir)
+	current, rest := base, target
+	if filepath.IsAbs(target) {
+		vol := filepath.VolumeName(target)
+		current, rest = vol+string(filepath.Separator), target[len(vol):]
+	}
+	missing := ""
+	for _, part := range strings.Split(rest, string(filepath.Separator)) {
+		switch {
+		case part == "" || part == ".":
+		case part == "..":
+			if missing != "" {
+				return "", toolError(CodeInvalidArgument, "%s steps out of %s, which does not exist yet", target, missing).With("target", target)
+			}
+			current = filepath.Dir(current)
+		default:
+			current = filepath.Join(current, part)
+			if missing != "" {
+				continue
+			}
+			if resolved, err := filepath.EvalSymlinks(current); err == nil {
+				current = resolved
+			} else if current != base && !strings.HasPrefix(base, current+string(filepath.Separator)) {
+				missing = current
+			}
+		}
+	}
+	return current, nil
+}
+
+// resolveExisting resolves the symbolic links in the longest existing prefix
+// of path and joins the rest to it as written
+func resolveExisting(path string) string {
+	parts := strings.Split(path, string(filepath.Separator))
+	for i := len(parts); i > 0; i-- {
+		prefix := strings.Join(parts[:i], string(filepath.Separator))
+		if prefix == "" {
+			prefix = string(filepath.Separator)
+		}
+		if resolved, err := filepath.EvalSymlinks(prefix); err == nil {
+			return filepath.Join(append([]string{resolved}, parts[i:]...)...)
+		}
+	}
+	return filepath.Clean(path)
+}
+
+// HandleReadLink handles the read_link tool
+func (fs *FilesystemHandler) HandleReadLink(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return errorResult(toolError(CodeInvalidArgument, "Error: invalid path: %w", err).With("path", path)), nil
+	}
+	if !fs.isPathInAllowedDirs(abs) {
+		return errorResult(toolError(CodePathNotAllowed, "Error: access denied - path outside allowed directories: %s", abs).With("path", abs)), nil
+	}
+	if _, err := os.Lstat(abs); os.IsNotExist(err) {
+		return errorResult(toolError(CodeNotFound, "Error: %s does not exist", path).With("path", path)), nil
+	} else if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	link, ok := fs.inspectSymlink(abs)
+	if !ok {
+		return errorResult(toolError(CodeWrongType, "Error: %s is not a symbolic link", path).With("path", path)), nil
+	}
+
+	result := LinkResult{
+		Path:                 link.path,
+		Target:               link.target,
+		ResolvedTarget:       link.resolved,
+		TargetExists:         !link.missing,
+		TargetOutsideAllowed: link.outside,
+	}
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, result)
+	}
+	text := fmt.Sprintf("%s -> %s\nResolved target: %s", result.Path, result.Target, result.ResolvedTarget)
+	if !result.TargetExists {
+		text += "\nThe target does not exist"
+	}
+	if result.TargetOutsideAllowed {
+		text += "\nThe target is outside the allowed directories"
+	}
+	return mcp.NewToolResultText(text), nil
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSymlinkTools(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symbolic links needs extra rights on Windows")
+	}
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	outside := resolveAllowedDirs(t, t.TempDir())[0]
+	target := filepath.Join(dir, "releases", "v2")
+	require.NoError(t, os.MkdirAll(target, 0755))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	readLink := fsHandler.FormatToolCall(fsHandler.HandleReadLink)
+	link := func(args map[string]any) LinkResult {
+		args["format"] = FormatJSON
+		res := callTool(t, readLink, args)
+		require.False(t, res.IsError, res.Content)
+		var got LinkResult
+		require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &got))
+		return got
+	}
+
+	current := filepath.Join(dir, "current")
+	res := callTool(t, fsHandler.HandleCreateSymlink, map[string]any{"path": current, "target": target, "relative": true})
+	require.False(t, res.IsError, res.Content)
+	stored, err := os.Readlink(current)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("releases", "v2"), stored)
+	assert.Equal(t, LinkResult{Path: current, Target: stored, ResolvedTarget: target, TargetExists: true}, link(map[string]any{"path": current}))
+
+	// A target that does not exist yet is fine, in an allowed directory
+	pending := filepath.Join(dir, "links", "next")
+	res = callTool(t, fsHandler.HandleCreateSymlink, map[string]any{"path": pending, "target": "../releases/v3", "create_parents": true})
+	require.False(t, res.IsError, res.Content)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "does not exist yet")
+	got := link(map[string]any{"path": pending})
+	assert.False(t, got.TargetExists)
+	assert.Equal(t, filepath.Join(dir, "releases", "v3"), got.ResolvedTarget)
+
+	res = callTool(t, fsHandler.HandleCreateSymlink, map[string]any{"path": current, "target": target})
+	assert.Equal(t, CodeAlreadyExists, ResultError(res).Code)
+	res = callTool(t, fsHandler.HandleCreateSymlink, map[string]any{"path": filepath.Join(dir, "escape"), "target": outside})
+	assert.Equal(t, CodePathNotAllowed, ResultError(res).Code)
+	res = callTool(t, fsHandler.HandleCreateSymlink, map[string]any{"path": filepath.Join(dir, "escape"), "target": "../../.."})
+	require.True(t, res.IsError)
+	assert.NoFileExists(t, filepath.Join(dir, "escape"))
+	res = callTool(t, fsHandler.HandleCreateSymlink, map[string]any{"path": filepath.Join(outside, "link"), "target": target})
+	assert.Equal(t, CodePathNotAllowed, ResultError(res).Code)
+
+	// read_link reports a link made some other way that leads outside
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "external")))
+	got = link(map[string]any{"path": filepath.Join(dir, "external")})
+	assert.True(t, got.TargetOutsideAllowed)
+	res = callTool(t, readLink, map[string]any{"path": target})
+	assert.Equal(t, CodeWrongType, ResultError(res).Code)
+	res = callTool(t, readLink, map[string]any{"path": filepath.Join(dir, "missing")})
+	assert.Equal(t, CodeNotFound, ResultError(res).Code)
+}
+
+func TestCreateSymlinkDotDotThroughLink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symbolic links needs extra rights on Windows")
+	}
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	nested := filepath.Join(dir, "a", "b", "c")
+	require.NoError(t, os.MkdirAll(nested, 0755))
+	require.NoError(t, os.Symlink(dir, filepath.Join(nested, "s")))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	// s/.. is the parent of the allowed directory, not a/b/c
+	esc := filepath.Join(nested, "esc")
+	res := callTool(t, fsHandler.HandleCreateSymlink, map[string]any{"path": esc, "target": "s/../x"})
+	require.True(t, res.IsError)
+	assert.Equal(t, CodePathNotAllowed, ResultError(res).Code)
+	_, err = os.Lstat(esc)
+	assert.True(t, os.IsNotExist(err))
+
+	// future could be created as a link to anywhere before esc is followed
+	res = callTool(t, fsHandler.HandleCreateSymlink, map[string]any{"path": esc, "target": "future/../x"})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	res = callTool(t, fsHandler.HandleCreateSymlink, map[string]any{"path": filepath.Join(nested, "new", "esc"), "target": "future/../../x", "create_parents": true})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	_, err = os.Lstat(esc)
+	assert.True(t, os.IsNotExist(err))
+
+	res = callTool(t, fsHandler.FormatToolCall(fsHandler.HandleCreateSymlink), map[string]any{"path": esc, "target": "s/x", "format": FormatJSON})
+	require.False(t, res.IsError, res.Content)
+	var got LinkResult
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &got))
+	assert.Equal(t, filepath.Join(dir, "x"), got.ResolvedTarget)
+}
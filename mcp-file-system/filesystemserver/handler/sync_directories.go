@@ -0,0 +1,327 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Kinds of SyncAction
+const (
+	SyncCreateDirectory = "create_directory"
+	SyncCopy            = "copy"
+	SyncUpdate          = "update"
+	SyncDelete          = "delete"
+)
+
+// SyncAction is one change sync_directories makes to the destination
+type SyncAction struct {
+	Action string `json:"action"`
+	// Path is relative to the synced directories; a deleted directory has a
+	// trailing slash
+	Path string `json:"path"`
+	// Reason is how an updated file differs, as in compare_directories
+	Reason string `json:"reason,omitempty"`
+	Size   int64  `json:"size,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// SyncResult is the JSON result of sync_directories
+type SyncResult struct {
+	Source      string       `json:"source"`
+	Destination string       `json:"destination"`
+	Compare     string       `json:"compare"`
+	Actions     []SyncAction `json:"actions"`
+	Unchanged   int          `json:"unchanged"`
+	BytesCopied int64        `json:"bytes_copied"`
+	Failed      int          `json:"failed"`
+}
+
+// HandleSyncDirectories handles the sync_directories tool
+func (fs *FilesystemHandler) HandleSyncDirectories(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	source, err := request.RequireString("source")
+	if err != nil {
+		return nil, err
+	}
+	destination, err := request.RequireString("destination")
+	if err != nil {
+		return nil, err
+	}
+	compare := request.GetString("compare", CompareMetadata)
+	if !slices.Contains([]string{CompareMetadata, CompareSize, CompareHash}, compare) {
+		return errorResult(toolError(CodeInvalidArgument, "Error: compare must be one of %s, %s, %s", CompareMetadata, CompareSize, CompareHash).With("compare", compare)), nil
+	}
+	deleteExtra := request.GetBool("delete", false)
+	trash := request.GetBool("trash", false)
+	dryRun := request.GetBool("dry_run", false)
+
+	validSource, err := fs.validatePath(source)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error with source path: %w", err)), nil
+	}
+	if info, err := os.Stat(validSource); err != nil {
+		return errorResult(fmt.Errorf("Error accessing source: %w", err)), nil
+	} else if !info.IsDir() {
+		return errorResult(toolError(CodeWrongType, "Error: %s is not a directory", source).With("path", source)), nil
+	}
+	// A dry run only previews the changes, so the destination need not be writable
+	var validDest string
+	if dryRun {
+		validDest, err = fs.validatePath(destination)
+	} else {
+		validDest, err = fs.validateCreatablePath(destination, request.GetBool("create_parents", false))
+	}
+	if err != nil {
+		return errorResult(fmt.Errorf("Error with destination path: %w", err)), nil
+	}
+	if info, err := os.Stat(validDest); err == nil && !info.IsDir() {
+		return errorResult(toolError(CodeWrongType, "Error: %s is not a directory", destination).With("path", destination)), nil
+	}
+	if pathWithin(validDest, validSource) || pathWithin(validSource, validDest) {
+		return errorResult(toolError(CodeInvalidArgument, "Error: source and destination must not contain one another").
+			With("source", validSource).With("destination", validDest)), nil
+	}
+
+	result, err := fs.planSync(ctx, validSource, validDest, compare, deleteExtra)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error comparing directories: %w", err)), nil
+	}
+
+	if dryRun || fs.dryRunMode() {
+		var lines []string
+		for _, dir := range missingParents(filepath.Join(validDest, "_")) {
+			lines = append(lines, fmt.Sprintf("Would create directory %s", dir))
+		}
+		for _, action := range result.Actions {
+			lines = append(lines, describeSyncAction(action, validSource, validDest, trash))
+		}
+		lines = append(lines, fmt.Sprintf("%d file(s) already in sync", result.Unchanged))
+		return dryRunResult(lines...), nil
+	}
+
+	if err := os.MkdirAll(validDest, 0755); err != nil {
+		return errorResult(fmt.Errorf("Error creating destination directory: %w", err)), nil
+	}
+	for i := range result.Actions {
+		action := &result.Actions[i]
+		if action.Error != "" {
+			result.Failed++
+		} else if err := fs.applySyncAction(ctx, *action, validSource, validDest, trash); err != nil {
+			action.Error = err.Error()
+			result.Failed++
+		} else if action.Action == SyncCopy || action.Action == SyncUpdate {
+			result.BytesCopied += action.Size
+		}
+		reportProgress(ctx, request, i+1, len(result.Actions), fmt.Sprintf("%s %s", action.Action, action.Path))
+	}
+
+	if wantsJSON(ctx) && result.Failed == 0 {
+		return jsonResult(ctx, result)
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Synced %s to %s: %d change(s), %s copied, %d file(s) unchanged",
+		validSource, validDest, len(result.Actions)-result.Failed, formatFileSize(result.BytesCopied), result.Unchanged))
+	if len(result.Actions) > 0 {
+		sb.WriteString(":\n")
+	}
+	for _, action := range result.Actions {
+		if action.Error != "" {
+			sb.WriteString(fmt.Sprintf("  failed to %s %s: %s\n", strings.ReplaceAll(action.Action, "_", " "), action.Path, action.Error))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("  %s %s\n", action.Action, action.Path))
+	}
+	if result.Failed > 0 {
+		return errorResult(toolError(CodeInternal, "%s", strings.TrimSuffix(sb.String(), "\n")).
+			With("succeeded", len(result.Actions)-result.Failed).With("failed", result.Failed)), nil
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// planSync lists the actions that make dest mirror source, parents before
+// their contents and deletions last
+func (fs *FilesystemHandler) planSync(ctx context.Context, source, dest, compare string, deleteExtra bool) (*SyncResult, error) {
+	src, err := fs.scanTree(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	dst := map[string]*treeEntry{}
+	if _, err := os.Stat(dest); err == nil {
+		if dst, err = fs.scanTree(ctx, dest); err != nil {
+			return nil, err
+		}
+		if err := fs.scanLinks(ctx, dest, dst); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &SyncResult{Source: source, Destination: dest, Compare: compare, Actions: []SyncAction{}}
+	for _, rel := range slices.Sorted(maps.Keys(src)) {
+		es, ed := src[rel], dst[rel]
+		switch {
+		case ed == nil && es.dir:
+			result.Actions = append(result.Actions, SyncAction{Action: SyncCreateDirectory, Path: rel})
+		case ed == nil:
+			result.Actions = append(result.Actions, SyncAction{Action: SyncCopy, Path: rel, Size: es.size})
+		case ed.link:
+			// A link in the destination is replaced, never written through
+			action := SyncAction{Action: SyncUpdate, Path: rel, Reason: "type", Size: es.size}
+			if es.dir {
+				action.Size = 0
+			}
+			result.Actions = append(result.Actions, action)
+		default:
+			reason, err := differsBy(ctx, es, ed, filepath.Join(source, rel), filepath.Join(dest, rel), compare)
+			if err != nil {
+				return nil, err
+			}
+			if reason == "" {
+				if !es.dir {
+					result.Unchanged++
+				}
+				continue
+			}
+			action := SyncAction{Action: SyncUpdate, Path: rel, Reason: reason, Size: es.size}
+			if es.dir {
+				action.Size = 0
+			} else if ed.dir && !deleteExtra {
+				// A directory only gives way to a file when extraneous files may be deleted
+				action.Error = "destination is a directory; set delete to replace it"
+			}
+			result.Actions = append(result.Actions, action)
+		}
+	}
+	if !deleteExtra {
+		return result, nil
+	}
+	for _, rel := range onlyIn(dst, src) {
+		// The contents of a directory replaced by a file go with it
+		if replacedByFile(src, strings.TrimSuffix(rel, "/")) {
+			continue
+		}
+		result.Actions = append(result.Actions, SyncAction{Action: SyncDelete, Path: rel})
+	}
+	return result, nil
+}
+
+// scanLinks adds the symbolic links below root to a tree listed by
+// scanTree, so a mirror replaces or deletes them instead of following them
+func (fs *FilesystemHandler) scanLinks(ctx context.Context, root string, tree map[string]*treeEntry) error {
+	return filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil || path == root {
+			return nil
+		}
+		if !fs.isPathInAllowedDirs(path) || fs.isInTrash(path) || fs.isInVersions(path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type()&os.ModeSymlink != 0 {
+			if rel, err := filepath.Rel(root, path); err == nil {
+				tree[filepath.ToSlash(rel)] = &treeEntry{link: true}
+			}
+		}
+		return nil
+	})
+}
+
+// replacedByFile reports whether an ancestor of rel is a file in tree
+func replacedByFile(tree map[string]*treeEntry, rel string) bool {
+	for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+		if e := tree[dir]; e != nil && !e.dir {
+			return true
+		}
+	}
+	return false
+}
+
+// applySyncAction makes one change to the destination. It acts on the
+// destination entries themselves: a symbolic link there is removed or
+// replaced, and nothing is written through a linked directory. Copies keep
+// the modification time of the source, so a later metadata sync sees them
+// as unchanged.
+func (fs *FilesystemHandler) applySyncAction(ctx context.Context, action SyncAction, source, dest string, trash bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	rel := filepath.FromSlash(strings.TrimSuffix(action.Path, "/"))
+	srcPath, destPath := filepath.Join(source, rel), filepath.Join(dest, rel)
+	if err := linkedParent(dest, rel); err != nil {
+		return err
+	}
+	// Only the parent is resolved, so a link at destPath is not followed
+	if _, err := fs.validateWritablePath(filepath.Dir(destPath)); err != nil {
+		return err
+	}
+
+	if action.Action == SyncDelete {
+		if trash {
+			_, err := fs.moveToTrash(destPath)
+			return err
+		}
+		return os.RemoveAll(destPath)
+	}
+	info, err := os.Stat(srcPath)
+	if err != nil {
+		return err
+	}
+	if current, err := os.Lstat(destPath); err == nil && (action.Reason == "type" || current.Mode()&os.ModeSymlink != 0) {
+		if err := os.RemoveAll(destPath); err != nil {
+			return err
+		}
+	}
+	if info.IsDir() {
+		return os.MkdirAll(destPath, info.Mode().Perm())
+	}
+	if err := copyFile(srcPath, destPath); err != nil {
+		return err
+	}
+	return os.Chtimes(destPath, info.ModTime(), info.ModTime())
+}
+
+// linkedParent fails if a directory between dest and dest/rel is a symbolic
+// link, as can happen when replacing it failed
+func linkedParent(dest, rel string) error {
+	dir := dest
+	parts := strings.Split(rel, string(filepath.Separator))
+	for _, part := range parts[:len(parts)-1] {
+		dir = filepath.Join(dir, part)
+		if info, err := os.Lstat(dir); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("%s is a symbolic link", dir)
+		}
+	}
+	return nil
+}
+
+// describeSyncAction describes an action for a dry run
+func describeSyncAction(action SyncAction, source, dest string, trash bool) string {
+	rel := filepath.FromSlash(strings.TrimSuffix(action.Path, "/"))
+	srcPath, destPath := filepath.Join(source, rel), filepath.Join(dest, rel)
+	switch {
+	case action.Error != "":
+		return fmt.Sprintf("Would fail to %s %s: %s", strings.ReplaceAll(action.Action, "_", " "), destPath, action.Error)
+	case action.Action == SyncCreateDirectory:
+		return fmt.Sprintf("Would create directory %s", destPath)
+	case action.Action == SyncCopy:
+		return fmt.Sprintf("Would copy %s to %s", describeTree(srcPath), destPath)
+	case action.Action == SyncUpdate:
+		return fmt.Sprintf("Would update %s from %s (%s differs)", destPath, describeTree(srcPath), action.Reason)
+	case trash:
+		return fmt.Sprintf("Would move %s to the trash", describeTree(destPath))
+	}
+	return fmt.Sprintf("Would delete %s", describeTree(destPath))
+}
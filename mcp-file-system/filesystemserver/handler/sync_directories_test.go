@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncDirectories(t *testing.T) {
+	dirs := resolveAllowedDirs(t, t.TempDir(), t.TempDir())
+	src, dst := dirs[0], filepath.Join(dirs[1], "mirror")
+	write := func(root, name, content string) {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	write(src, "a.txt", "alpha")
+	write(src, "docs/b.txt", "bravo")
+	write(src, "empty/.keep", "")
+
+	fsHandler, err := NewFilesystemHandler(dirs)
+	require.NoError(t, err)
+	sync := fsHandler.FormatToolCall(fsHandler.HandleSyncDirectories)
+	run := func(args map[string]any) SyncResult {
+		args["source"], args["destination"], args["format"] = src, dst, FormatJSON
+		res := callTool(t, sync, args)
+		require.False(t, res.IsError, res.Content)
+		var got SyncResult
+		require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &got))
+		return got
+	}
+
+	// A dry run leaves the destination alone
+	res := callTool(t, sync, map[string]any{"source": src, "destination": dst, "dry_run": true})
+	require.False(t, res.IsError, res.Content)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Would copy "+filepath.Join(src, "a.txt"))
+	assert.NoDirExists(t, dst)
+
+	got := run(map[string]any{})
+	assert.Equal(t, []SyncAction{
+		{Action: SyncCopy, Path: "a.txt", Size: 5},
+		{Action: SyncCreateDirectory, Path: "docs"},
+		{Action: SyncCopy, Path: "docs/b.txt", Size: 5},
+		{Action: SyncCreateDirectory, Path: "empty"},
+		{Action: SyncCopy, Path: "empty/.keep"},
+	}, got.Actions)
+	assert.Equal(t, int64(10), got.BytesCopied)
+	content, err := os.ReadFile(filepath.Join(dst, "docs", "b.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "bravo", string(content))
+
+	// Copies keep their modification times, so nothing is left to do
+	got = run(map[string]any{})
+	assert.Empty(t, got.Actions)
+	assert.Equal(t, 3, got.Unchanged)
+
+	write(src, "a.txt", "ALPHA!")
+	write(dst, "extra/old.txt", "stale")
+	got = run(map[string]any{})
+	assert.Equal(t, []SyncAction{{Action: SyncUpdate, Path: "a.txt", Reason: "size", Size: 6}}, got.Actions)
+	assert.FileExists(t, filepath.Join(dst, "extra", "old.txt"))
+
+	got = run(map[string]any{"delete": true})
+	assert.Equal(t, []SyncAction{{Action: SyncDelete, Path: "extra/"}}, got.Actions)
+	assert.NoDirExists(t, filepath.Join(dst, "extra"))
+
+	// A directory in the way of a file is only removed with delete
+	require.NoError(t, os.Remove(filepath.Join(src, "empty", ".keep")))
+	require.NoError(t, os.Remove(filepath.Join(src, "empty")))
+	write(src, "empty", "now a file")
+	res = callTool(t, sync, map[string]any{"source": src, "destination": dst})
+	assert.Equal(t, CodeInternal, ResultError(res).Code)
+	got = run(map[string]any{"delete": true})
+	assert.Equal(t, []SyncAction{{Action: SyncUpdate, Path: "empty", Reason: "type", Size: 10}}, got.Actions)
+	assert.FileExists(t, filepath.Join(dst, "empty"))
+
+	res = callTool(t, sync, map[string]any{"source": src, "destination": filepath.Join(src, "docs")})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+}
+
+func TestSyncDirectoriesLinks(t *testing.T) {
+	dirs := resolveAllowedDirs(t, t.TempDir(), t.TempDir())
+	src, dst := dirs[0], filepath.Join(dirs[1], "mirror")
+	precious := filepath.Join(dirs[1], "precious.txt")
+	outside := filepath.Join(dirs[1], "outside")
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "f"), []byte("new"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "sub", "g"), []byte("new"), 0644))
+	require.NoError(t, os.MkdirAll(dst, 0755))
+	require.NoError(t, os.MkdirAll(outside, 0755))
+	require.NoError(t, os.WriteFile(precious, []byte("keep me"), 0644))
+	require.NoError(t, os.Symlink(precious, filepath.Join(dst, "f")))
+	require.NoError(t, os.Symlink(outside, filepath.Join(dst, "sub")))
+	require.NoError(t, os.Symlink(precious, filepath.Join(dst, "extra")))
+
+	fsHandler, err := NewFilesystemHandler(dirs)
+	require.NoError(t, err)
+	res := callTool(t, fsHandler.FormatToolCall(fsHandler.HandleSyncDirectories), map[string]any{
+		"source": src, "destination": dst, "delete": true, "format": FormatJSON,
+	})
+	require.False(t, res.IsError, res.Content)
+	var got SyncResult
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &got))
+	assert.Equal(t, []SyncAction{
+		{Action: SyncUpdate, Path: "f", Reason: "type", Size: 3},
+		{Action: SyncUpdate, Path: "sub", Reason: "type"},
+		{Action: SyncCopy, Path: "sub/g", Size: 3},
+		{Action: SyncDelete, Path: "extra"},
+	}, got.Actions)
+
+	// The links were replaced or deleted, and nothing they point to changed
+	content, err := os.ReadFile(precious)
+	require.NoError(t, err)
+	assert.Equal(t, "keep me", string(content))
+	assert.NoFileExists(t, filepath.Join(outside, "g"))
+	for _, name := range []string{"f", "sub"} {
+		info, err := os.Lstat(filepath.Join(dst, name))
+		require.NoError(t, err)
+		assert.Zero(t, info.Mode()&os.ModeSymlink, name)
+	}
+	assert.FileExists(t, filepath.Join(dst, "sub", "g"))
+	_, err = os.Lstat(filepath.Join(dst, "extra"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Nothing is written through a linked directory that is still in place
+	require.NoError(t, os.RemoveAll(filepath.Join(dst, "sub")))
+	require.NoError(t, os.Symlink(outside, filepath.Join(dst, "sub")))
+	assert.Error(t, fsHandler.applySyncAction(context.Background(), SyncAction{Action: SyncCopy, Path: "sub/g"}, src, dst, false))
+	assert.NoFileExists(t, filepath.Join(outside, "g"))
+}
@@ -0,0 +1,274 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-filesystem-server/buildinfo"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// defaultTelemetryInterval is how often usage statistics are reported
+	defaultTelemetryInterval = 24 * time.Hour
+	// telemetryTimeout bounds a single report
+	telemetryTimeout = 10 * time.Second
+	// doNotTrackEnv turns telemetry off regardless of the configuration
+	// (https://consoledonottrack.com)
+	doNotTrackEnv = "DO_NOT_TRACK"
+)
+
+// TelemetryConfig configures the opt-in anonymous usage statistics
+type TelemetryConfig struct {
+	// Enabled must be set explicitly; telemetry is off by default
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// Endpoint receives the reports as JSON POST requests
+	Endpoint string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	// Interval between reports (default: 24h)
+	Interval time.Duration `json:"interval,omitempty" yaml:"interval,omitempty"`
+}
+
+// ToolUsage counts the calls of one tool
+type ToolUsage struct {
+	Calls  int64 `json:"calls"`
+	Errors int64 `json:"errors"`
+}
+
+// UsageReport is the complete payload sent to the telemetry endpoint. It
+// holds no paths, arguments, file contents, host names or identifiers.
+type UsageReport struct {
+	Version   string               `json:"version"`
+	OS        string               `json:"os"`
+	Arch      string               `json:"arch"`
+	GoVersion string               `json:"go_version"`
+	Since     time.Time            `json:"since"`
+	Until     time.Time            `json:"until"`
+	Tools     map[string]ToolUsage `json:"tools"`
+}
+
+// Telemetry aggregates tool call counts and reports them periodically. One
+// Telemetry can be shared by several handlers, such as those of tenants, so
+// their calls are reported together.
+type Telemetry struct {
+	cfg TelemetryConfig
+
+	mu         sync.Mutex
+	since      time.Time
+	tools      map[string]ToolUsage
+	lastReport time.Time
+	lastErr    error
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// telemetryClient sends reports; replaceable in tests
+var telemetryClient = &http.Client{Timeout: telemetryTimeout}
+
+// NewTelemetry returns a collector, or nil when telemetry is not enabled,
+// has no endpoint or DO_NOT_TRACK is set
+func NewTelemetry(cfg TelemetryConfig) *Telemetry {
+	if !cfg.Enabled || cfg.Endpoint == "" || doNotTrack() {
+		return nil
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultTelemetryInterval
+	}
+	return &Telemetry{
+		cfg:   cfg,
+		since: time.Now().UTC(),
+		tools: make(map[string]ToolUsage),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+}
+
+// doNotTrack reports whether DO_NOT_TRACK asks for telemetry to be off
+func doNotTrack() bool {
+	v := strings.TrimSpace(os.Getenv(doNotTrackEnv))
+	return v != "" && v != "0" && !strings.EqualFold(v, "false")
+}
+
+// WithTelemetry counts tool calls for the shared collector t; nil disables
+// telemetry
+func WithTelemetry(t *Telemetry) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		fs.telemetry = t
+	}
+}
+
+// CountToolCall wraps a tool handler so its calls and errors are counted
+// under name when telemetry is enabled. Only the name is recorded.
+func (fs *FilesystemHandler) CountToolCall(name string, next ToolHandlerFunc) ToolHandlerFunc {
+	if fs.telemetry == nil {
+		return next
+	}
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, request)
+		fs.telemetry.count(name, err != nil || (result != nil && result.IsError))
+		return result, err
+	}
+}
+
+// StartTelemetry begins the periodic reports of a handler's collector
+func (fs *FilesystemHandler) StartTelemetry() {
+	if fs.telemetry == nil {
+		return
+	}
+	fs.telemetry.startOnce.Do(func() {
+		go fs.telemetry.run(fs)
+	})
+}
+
+// count records one call of tool
+func (t *Telemetry) count(tool string, failed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	usage := t.tools[tool]
+	usage.Calls++
+	if failed {
+		usage.Errors++
+	}
+	t.tools[tool] = usage
+}
+
+// report returns the statistics collected since the last successful report
+func (t *Telemetry) report() UsageReport {
+	info := buildinfo.Get()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tools := make(map[string]ToolUsage, len(t.tools))
+	for name, usage := range t.tools {
+		tools[name] = usage
+	}
+	return UsageReport{
+		Version:   info.Version,
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		GoVersion: info.GoVersion,
+		Since:     t.since,
+		Until:     time.Now().UTC(),
+		Tools:     tools,
+	}
+}
+
+// run sends a report every interval and a final one when stopped
+func (t *Telemetry) run(fs *FilesystemHandler) {
+	defer close(t.done)
+	ticker := time.NewTicker(t.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-t.stop:
+			t.send(fs)
+			return
+		}
+		t.send(fs)
+	}
+}
+
+// send posts the collected statistics, if there are any. Counts are only
+// reset once the endpoint has accepted them.
+func (t *Telemetry) send(fs *FilesystemHandler) {
+	report := t.report()
+	if len(report.Tools) == 0 {
+		return
+	}
+	err := postUsageReport(t.cfg.Endpoint, report)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastReport, t.lastErr = report.Until, err
+	if err != nil {
+		fs.logger.Warn("telemetry report failed", "endpoint", t.cfg.Endpoint, "error", err)
+		return
+	}
+	for name, sent := range report.Tools {
+		usage := t.tools[name]
+		usage.Calls -= sent.Calls
+		usage.Errors -= sent.Errors
+		if usage.Calls == 0 {
+			delete(t.tools, name)
+		} else {
+			t.tools[name] = usage
+		}
+	}
+	t.since = report.Until
+	fs.logger.Debug("telemetry report sent", "endpoint", t.cfg.Endpoint)
+}
+
+// postUsageReport delivers one report
+func postUsageReport(endpoint string, report UsageReport) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	resp, err := telemetryClient.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// close stops the periodic reports after a final one, waiting until ctx is
+// done. Closing a collector more than once, or one never started, is harmless.
+func (t *Telemetry) close(ctx context.Context) {
+	// A collector that was never started has nothing to wait for
+	t.startOnce.Do(func() { close(t.done) })
+	t.stopOnce.Do(func() { close(t.stop) })
+	select {
+	case <-t.done:
+	case <-ctx.Done():
+	}
+}
+
+// HandleTelemetryStatus reports whether usage statistics are collected and,
+// if so, exactly what the next report contains
+func (fs *FilesystemHandler) HandleTelemetryStatus(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	t := fs.telemetry
+	if t == nil {
+		reason := "it has not been enabled in the configuration"
+		if doNotTrack() {
+			reason = doNotTrackEnv + " is set"
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Telemetry is off: %s. No usage statistics are collected or sent.", reason)), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Telemetry is on.\nEndpoint: %s\nInterval: %s\n", t.cfg.Endpoint, t.cfg.Interval)
+	t.mu.Lock()
+	lastReport, lastErr := t.lastReport, t.lastErr
+	t.mu.Unlock()
+	switch {
+	case lastReport.IsZero():
+		b.WriteString("Last report: none yet\n")
+	case lastErr != nil:
+		fmt.Fprintf(&b, "Last report: %s (failed: %v)\n", formatTime(lastReport), lastErr)
+	default:
+		fmt.Fprintf(&b, "Last report: %s\n", formatTime(lastReport))
+	}
+	next, err := json.MarshalIndent(t.report(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(&b, "Next report:\n%s", next)
+	return mcp.NewToolResultText(b.String()), nil
+}
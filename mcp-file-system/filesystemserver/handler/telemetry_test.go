@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-filesystem-server/buildinfo"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelemetryIsOptIn(t *testing.T) {
+	t.Setenv(doNotTrackEnv, "")
+	assert.Nil(t, NewTelemetry(TelemetryConfig{}))
+	assert.Nil(t, NewTelemetry(TelemetryConfig{Enabled: true}))
+
+	fsHandler, err := NewFilesystemHandler(resolveAllowedDirs(t, t.TempDir()))
+	require.NoError(t, err)
+	res := callTool(t, fsHandler.HandleTelemetryStatus, nil)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Telemetry is off: it has not been enabled")
+
+	t.Setenv(doNotTrackEnv, "1")
+	assert.Nil(t, NewTelemetry(TelemetryConfig{Enabled: true, Endpoint: "http://localhost"}))
+	res = callTool(t, fsHandler.HandleTelemetryStatus, nil)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Telemetry is off: DO_NOT_TRACK is set")
+}
+
+func TestTelemetryReportsCountsOnly(t *testing.T) {
+	t.Setenv(doNotTrackEnv, "")
+	reports := make(chan UsageReport, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var report UsageReport
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&report))
+		reports <- report
+	}))
+	defer ts.Close()
+
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0644))
+	telemetry := NewTelemetry(TelemetryConfig{Enabled: true, Endpoint: ts.URL})
+	require.NotNil(t, telemetry)
+	fsHandler, err := NewFilesystemHandler([]string{dir}, WithTelemetry(telemetry))
+	require.NoError(t, err)
+	fsHandler.StartTelemetry()
+
+	read := fsHandler.CountToolCall("read_file", fsHandler.HandleReadFile)
+	callTool(t, read, map[string]any{"path": filepath.Join(dir, "secret.txt")})
+	callTool(t, read, map[string]any{"path": filepath.Join(dir, "missing.txt")})
+
+	res := callTool(t, fsHandler.HandleTelemetryStatus, nil)
+	text := res.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Telemetry is on.")
+	assert.Contains(t, text, `"read_file": {`)
+
+	// Shutdown sends the final report
+	require.NoError(t, fsHandler.Shutdown(context.Background()))
+	report := <-reports
+	assert.Equal(t, buildinfo.Get().Version, report.Version)
+	assert.Equal(t, map[string]ToolUsage{"read_file": {Calls: 2, Errors: 1}}, report.Tools)
+
+	body, err := json.Marshal(report)
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "secret")
+	assert.NotContains(t, string(body), dir)
+}
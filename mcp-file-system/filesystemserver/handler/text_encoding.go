@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// EncodingAuto detects the encoding of a text file: a byte order mark
+// decides first, then valid UTF-8, and anything else is read as Windows-1252
+// (a superset of Latin-1)
+const EncodingAuto = "auto"
+
+// Encodings of the content argument of write_file
+const (
+	EncodingUTF8   = "utf-8"
+	EncodingBase64 = "base64"
+)
+
+// encodingAliases maps common spellings the WHATWG labels do not include
+var encodingAliases = map[string]string{
+	"latin-1": "latin1",
+	"utf8":    "utf-8",
+	"utf16le": "utf-16le",
+	"utf16be": "utf-16be",
+}
+
+// lookupEncoding returns the encoding with the given name, such as "gbk",
+// "shift_jis", "latin-1" or "utf-16le"
+func lookupEncoding(name string) (encoding.Encoding, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := encodingAliases[name]; ok {
+		name = alias
+	}
+	enc, err := htmlindex.Get(name)
+	if err != nil {
+		return nil, toolError(CodeInvalidArgument, "Error: unknown encoding %q", name).With("encoding", name)
+	}
+	return enc, nil
+}
+
+// decodeText converts content in the named encoding, or EncodingAuto, to
+// UTF-8. It returns the text and the name of the encoding used; bytes that
+// are invalid in it become U+FFFD.
+func decodeText(content []byte, name string) (string, string, error) {
+	var enc encoding.Encoding
+	if name == "" || strings.EqualFold(name, EncodingAuto) {
+		enc, name = detectEncoding(content)
+	} else {
+		var err error
+		if enc, err = lookupEncoding(name); err != nil {
+			return "", "", err
+		}
+		if canonical, err := htmlindex.Name(enc); err == nil {
+			name = canonical
+		}
+	}
+	if enc == unicode.UTF8 {
+		return string(bytes.TrimPrefix(content, utf8BOM)), "utf-8", nil
+	}
+	// A byte order mark overrides the encoding and is dropped
+	decoded, _, err := transform.Bytes(unicode.BOMOverride(enc.NewDecoder()), content)
+	if err != nil {
+		return "", "", toolError(CodeInvalidArgument, "Error: cannot decode content as %s: %w", name, err)
+	}
+	return string(decoded), name, nil
+}
+
+// utf8BOM is the byte order mark some editors put before UTF-8 text
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// detectEncoding guesses the encoding of text content and names it
+func detectEncoding(content []byte) (encoding.Encoding, string) {
+	switch {
+	case bytes.HasPrefix(content, utf8BOM):
+		return unicode.UTF8, "utf-8"
+	case bytes.HasPrefix(content, []byte("\xff\xfe")):
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), "utf-16le"
+	case bytes.HasPrefix(content, []byte("\xfe\xff")):
+		return unicode.UTF16(unicode.BigEndian, unicode.UseBOM), "utf-16be"
+	case utf8.Valid(content):
+		return unicode.UTF8, "utf-8"
+	}
+	return charmap.Windows1252, "windows-1252"
+}
+
+// detectFileEncoding names the encoding of the text file at path, detected
+// from its first bytes
+func detectFileEncoding(path string) (string, error) {
+	sample, err := readPrefix(path, binarySniffSize)
+	if err != nil {
+		return "", err
+	}
+	if len(sample) == binarySniffSize {
+		// A character cut off at the end of the sample is no sign of
+		// invalid UTF-8
+		for i := 1; i <= utf8.UTFMax && i <= len(sample); i++ {
+			if utf8.RuneStart(sample[len(sample)-i]) {
+				if !utf8.FullRune(sample[len(sample)-i:]) {
+					sample = sample[:len(sample)-i]
+				}
+				break
+			}
+		}
+	}
+	_, name := detectEncoding(sample)
+	return name, nil
+}
+
+// decodeContent returns the bytes a content argument stands for: its UTF-8
+// text, or the data it holds in base64
+func decodeContent(content, name string) ([]byte, error) {
+	switch strings.ToLower(name) {
+	case "", EncodingUTF8, "utf8":
+		return []byte(content), nil
+	case EncodingBase64:
+		data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(content), ""))
+		if err != nil {
+			return nil, toolError(CodeInvalidArgument, "Error: content is not valid base64: %w", err)
+		}
+		return data, nil
+	default:
+		return nil, toolError(CodeInvalidArgument, "Error: encoding must be %q or %q, got %q", EncodingUTF8, EncodingBase64, name).With("encoding", name)
+	}
+}
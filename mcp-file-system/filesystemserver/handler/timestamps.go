@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/djherbis/times"
+)
+
+// FileTimes are the timestamps reported for a file, each as RFC 3339 in
+// UTC and as Unix seconds. Created is omitted where the filesystem does not
+// record it.
+type FileTimes struct {
+	Created      string `json:"created,omitempty"`
+	CreatedUnix  int64  `json:"created_unix,omitempty"`
+	Modified     string `json:"modified"`
+	ModifiedUnix int64  `json:"modified_unix"`
+	// ModifiedAgo is the age of the last modification, such as "5 minutes ago"
+	ModifiedAgo  string `json:"modified_ago"`
+	Accessed     string `json:"accessed,omitempty"`
+	AccessedUnix int64  `json:"accessed_unix,omitempty"`
+}
+
+// newFileTimes converts file times; zero times are left out
+func newFileTimes(created, modified, accessed, now time.Time) FileTimes {
+	return FileTimes{
+		Created:      formatTime(created),
+		CreatedUnix:  unixTime(created),
+		Modified:     formatTime(modified),
+		ModifiedUnix: unixTime(modified),
+		ModifiedAgo:  humanizeAge(modified, now),
+		Accessed:     formatTime(accessed),
+		AccessedUnix: unixTime(accessed),
+	}
+}
+
+// localFileTimes returns the times of a local file from its FileInfo
+func localFileTimes(info os.FileInfo, now time.Time) FileTimes {
+	ts := times.Get(info)
+	var created time.Time
+	if ts.HasBirthTime() {
+		created = ts.BirthTime()
+	}
+	return newFileTimes(created, ts.ModTime(), ts.AccessTime(), now)
+}
+
+// formatTime formats t the way every tool reports times: RFC 3339 in UTC,
+// or "" for the zero time
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// unixTime returns t in Unix seconds, or 0 for the zero time
+func unixTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// humanizeAge describes how long before now t was, such as "5 minutes ago"
+func humanizeAge(t, now time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	d := now.Sub(t)
+	var n int
+	var unit string
+	switch {
+	case d < -time.Minute:
+		return "in the future"
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		n, unit = int(d/time.Minute), "minute"
+	case d < 24*time.Hour:
+		n, unit = int(d/time.Hour), "hour"
+	case d < 30*24*time.Hour:
+		n, unit = int(d/(24*time.Hour)), "day"
+	case d < 365*24*time.Hour:
+		n, unit = int(d/(30*24*time.Hour)), "month"
+	default:
+		n, unit = int(d/(365*24*time.Hour)), "year"
+	}
+	if n != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("%d %s ago", n, unit)
+}
+
+// describeTime renders t for text output: RFC 3339, Unix seconds and, with
+// age set, how long ago it was
+func describeTime(t, now time.Time, age bool) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+	if age {
+		return fmt.Sprintf("%s (unix %d, %s)", formatTime(t), t.Unix(), humanizeAge(t, now))
+	}
+	return fmt.Sprintf("%s (unix %d)", formatTime(t), t.Unix())
+}
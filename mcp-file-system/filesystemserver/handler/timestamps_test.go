@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHumanizeAge(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	for age, want := range map[time.Duration]string{
+		10 * time.Second:     "just now",
+		time.Minute:          "1 minute ago",
+		45 * time.Minute:     "45 minutes ago",
+		3 * time.Hour:        "3 hours ago",
+		36 * time.Hour:       "1 day ago",
+		65 * 24 * time.Hour:  "2 months ago",
+		800 * 24 * time.Hour: "2 years ago",
+		-10 * time.Minute:    "in the future",
+		-30 * time.Second:    "just now",
+	} {
+		assert.Equal(t, want, humanizeAge(now.Add(-age), now), age.String())
+	}
+	assert.Empty(t, humanizeAge(time.Time{}, now))
+}
+
+func TestFileInfoTimes(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	file := filepath.Join(dir, "a.txt")
+	require.NoError(t, os.WriteFile(file, []byte("hello"), 0644))
+	stamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600))
+	require.NoError(t, os.Chtimes(file, stamp, stamp))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	res := callTool(t, fsHandler.HandleGetFileInfo, map[string]any{"path": file})
+	require.False(t, res.IsError)
+	text := res.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Modified: 2024-01-02T02:04:05Z (unix 1704161045, ")
+	assert.Contains(t, text, "years ago)")
+	assert.Contains(t, text, "Accessed: 2024-01-02T02:04:05Z (unix 1704161045)")
+
+	res = callTool(t, fsHandler.HandleListDirectory, map[string]any{"path": dir})
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "- 5 bytes, modified 2024-01-02T02:04:05Z (")
+}
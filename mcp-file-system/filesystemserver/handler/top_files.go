@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// defaultTopFiles is how many files top_files lists
+	defaultTopFiles = 20
+	// maxTopFiles caps the top argument of top_files
+	maxTopFiles = 1000
+)
+
+// TopFilesResult is the JSON result of top_files
+type TopFilesResult struct {
+	Path string `json:"path"`
+	// By is the ranking, size for the largest files or mtime for the oldest
+	By    string           `json:"by"`
+	Files []DirectoryEntry `json:"files"`
+	// Scanned counts the files ranked, and ListedSize totals those listed
+	Scanned    int   `json:"scanned"`
+	ListedSize int64 `json:"listed_size"`
+}
+
+// HandleTopFiles handles the top_files tool
+func (fs *FilesystemHandler) HandleTopFiles(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	by := request.GetString("by", SortBySize)
+	if by != SortBySize && by != SortByMtime {
+		return errorResult(toolError(CodeInvalidArgument, "Error: by must be %q or %q", SortBySize, SortByMtime).With("by", by)), nil
+	}
+	top := request.GetInt("top", defaultTopFiles)
+	if top < 1 || top > maxTopFiles {
+		return errorResult(toolError(CodeInvalidArgument, "Error: top must be between 1 and %d", maxTopFiles).With("top", top)), nil
+	}
+	minSize := int64(request.GetInt("min_size", 0))
+	if minSize < 0 {
+		return errorResult(toolError(CodeInvalidArgument, "Error: min_size must not be negative").With("min_size", minSize)), nil
+	}
+	maxDepth := request.GetInt("max_depth", 0)
+	if maxDepth < 0 {
+		return errorResult(toolError(CodeInvalidArgument, "Error: max_depth must not be negative").With("max_depth", maxDepth)), nil
+	}
+
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if !info.IsDir() {
+		return errorResult(toolError(CodeWrongType, "Error: %s is not a directory", path).With("path", path)), nil
+	}
+	filter, err := fs.newWalkFilter(request, validPath)
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	result, err := fs.topFiles(ctx, validPath, by, top, minSize, maxDepth, filter)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error ranking files: %w", err)), nil
+	}
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, result)
+	}
+
+	if len(result.Files) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No files found in %s", validPath)), nil
+	}
+	var sb strings.Builder
+	ranking := "largest"
+	if by == SortByMtime {
+		ranking = "oldest"
+	}
+	sb.WriteString(fmt.Sprintf("The %d %s of %d files in %s, %s in total:\n\n",
+		len(result.Files), ranking, result.Scanned, validPath, formatFileSize(result.ListedSize)))
+	for _, entry := range result.Files {
+		sb.WriteString(fmt.Sprintf("  %s - %s, modified %s\n", entry.Path, formatFileSize(entry.Size), entry.ModifiedAgo))
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// topFiles walks root without following symbolic links and keeps the top
+// regular files by size, largest first, or by modification time, oldest
+// first
+func (fs *FilesystemHandler) topFiles(ctx context.Context, root, by string, top int, minSize int64, maxDepth int, filter *walkFilter) (*TopFilesResult, error) {
+	result := &TopFilesResult{Path: root, By: by, Files: []DirectoryEntry{}}
+	var candidates []sizedFile
+	// The candidates are trimmed as they grow so memory stays bounded
+	trim := func() {
+		sort.Slice(candidates, func(i, j int) bool {
+			a, b := candidates[i], candidates[j]
+			if by == SortBySize && a.info.Size() != b.info.Size() {
+				return a.info.Size() > b.info.Size()
+			}
+			if by == SortByMtime && !a.info.ModTime().Equal(b.info.ModTime()) {
+				return a.info.ModTime().Before(b.info.ModTime())
+			}
+			return a.path < b.path
+		})
+		candidates = candidates[:min(len(candidates), top)]
+	}
+
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil || path == root {
+			return nil // Skip unreadable entries
+		}
+		if !fs.isPathInAllowedDirs(path) || fs.isInTrash(path) || fs.isInVersions(path) || filter.skip(path, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			rel, err := filepath.Rel(root, path)
+			if err == nil && maxDepth > 0 && strings.Count(rel, string(filepath.Separator))+1 >= maxDepth {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || info.Size() < minSize {
+			return nil
+		}
+		result.Scanned++
+		candidates = append(candidates, sizedFile{path, info})
+		if len(candidates) > 4*top {
+			trim()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	trim()
+
+	now := time.Now()
+	for _, file := range candidates {
+		result.Files = append(result.Files, DirectoryEntry{
+			Name:      filepath.Base(file.path),
+			Path:      file.path,
+			Type:      FindTypeFile,
+			Size:      file.info.Size(),
+			Mode:      fmt.Sprintf("%03o", file.info.Mode().Perm()),
+			URI:       pathToResourceURI(file.path),
+			FileTimes: localFileTimes(file.info, now),
+		})
+		result.ListedSize += file.info.Size()
+	}
+	return result, nil
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopFiles(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	now := time.Now()
+	for name, file := range map[string]struct {
+		size int
+		age  time.Duration
+	}{
+		"small.txt":          {10, time.Hour},
+		"big.bin":            {5000, 2 * time.Hour},
+		"logs/old.log":       {100, 72 * time.Hour},
+		"logs/deep/mid.log":  {1000, 24 * time.Hour},
+		"node_modules/x.js":  {9000, 96 * time.Hour},
+		"logs/deep/empty.md": {0, 48 * time.Hour},
+		// The trash and saved versions are not ranked
+		trashDirName + "/files/huge.bin": {20000, 200 * time.Hour},
+		versionsDirName + "/big.bin":     {8000, 100 * time.Hour},
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(strings.Repeat("x", file.size)), 0644))
+		mtime := now.Add(-file.age)
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+	}
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	top := fsHandler.FormatToolCall(fsHandler.HandleTopFiles)
+	rank := func(args map[string]any) ([]string, TopFilesResult) {
+		t.Helper()
+		args["path"] = dir
+		args["format"] = FormatJSON
+		res := callTool(t, top, args)
+		require.False(t, res.IsError, res.Content)
+		var result TopFilesResult
+		require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &result))
+		var names []string
+		for _, entry := range result.Files {
+			rel, err := filepath.Rel(dir, entry.Path)
+			require.NoError(t, err)
+			names = append(names, filepath.ToSlash(rel))
+		}
+		return names, result
+	}
+
+	names, result := rank(map[string]any{"top": 3})
+	assert.Equal(t, []string{"node_modules/x.js", "big.bin", "logs/deep/mid.log"}, names)
+	assert.Equal(t, 6, result.Scanned)
+	assert.Equal(t, int64(15000), result.ListedSize)
+
+	names, _ = rank(map[string]any{"top": 2, "by": SortByMtime, "exclude": []any{"node_modules"}})
+	assert.Equal(t, []string{"logs/old.log", "logs/deep/empty.md"}, names)
+
+	names, _ = rank(map[string]any{"by": SortByMtime, "min_size": 1, "max_depth": 2})
+	assert.Equal(t, []string{"node_modules/x.js", "logs/old.log", "big.bin", "small.txt"}, names)
+
+	// Trimming as the walk goes keeps the right files
+	names, _ = rank(map[string]any{"top": 1})
+	assert.Equal(t, []string{"node_modules/x.js"}, names)
+
+	res := callTool(t, fsHandler.HandleTopFiles, map[string]any{"path": dir, "top": 2})
+	require.False(t, res.IsError)
+	text := res.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "The 2 largest of 6 files")
+	assert.Contains(t, text, "big.bin - 4.88 KB")
+
+	for _, args := range []map[string]any{
+		{"path": dir, "by": "name"},
+		{"path": dir, "top": 0},
+		{"path": dir, "min_size": -1},
+	} {
+		res := callTool(t, fsHandler.HandleTopFiles, args)
+		require.True(t, res.IsError, args)
+		assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	}
+	res = callTool(t, fsHandler.HandleTopFiles, map[string]any{"path": filepath.Join(dir, "big.bin")})
+	require.True(t, res.IsError)
+	assert.Equal(t, CodeWrongType, ResultError(res).Code)
+}
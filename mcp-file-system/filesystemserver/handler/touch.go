@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/djherbis/times"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// TouchResult is the JSON result of touch
+type TouchResult struct {
+	Path string `json:"path"`
+	// Created is set when the file did not exist before
+	Created bool `json:"created"`
+	FileTimes
+}
+
+// HandleTouch handles the touch tool
+func (fs *FilesystemHandler) HandleTouch(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	mtime, err := parseTimeArgument(request, "mtime")
+	if err != nil {
+		return errorResult(err), nil
+	}
+	atime, err := parseTimeArgument(request, "atime")
+	if err != nil {
+		return errorResult(err), nil
+	}
+	noCreate := request.GetBool("no_create", false)
+
+	validPath, err := fs.validateCreatablePath(path, request.GetBool("create_parents", false) && !noCreate)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if _, err := os.Stat(validPath); os.IsNotExist(err) && noCreate {
+		return errorResult(toolError(CodeNotFound, "Error: %s does not exist and no_create is set", path).With("path", path)), nil
+	}
+	return fs.touch(ctx, validPath, mtime, atime)
+}
+
+// HandleSetFileTimes handles the set_file_times tool, which is touch for
+// an existing path with at least one time given
+func (fs *FilesystemHandler) HandleSetFileTimes(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	mtime, err := parseTimeArgument(request, "mtime")
+	if err != nil {
+		return errorResult(err), nil
+	}
+	atime, err := parseTimeArgument(request, "atime")
+	if err != nil {
+		return errorResult(err), nil
+	}
+	if mtime.IsZero() && atime.IsZero() {
+		return errorResult(toolError(CodeInvalidArgument, "Error: give mtime, atime or both")), nil
+	}
+
+	validPath, err := fs.validateWritablePath(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if _, err := os.Stat(validPath); os.IsNotExist(err) {
+		return errorResult(toolError(CodeNotFound, "Error: %s does not exist", path).With("path", path)), nil
+	}
+	return fs.touch(ctx, validPath, mtime, atime)
+}
+
+// touch sets the times of validPath, creating it as an empty file if it is
+// missing
+func (fs *FilesystemHandler) touch(ctx context.Context, validPath string, mtime, atime time.Time) (*mcp.CallToolResult, error) {
+	info, err := os.Stat(validPath)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+
+	// A time not given is set to now, unless only the other one is given,
+	// in which case it is left as it is
+	now := time.Now()
+	switch {
+	case mtime.IsZero() && atime.IsZero():
+		mtime, atime = now, now
+	case mtime.IsZero() && exists:
+		mtime = info.ModTime()
+	case atime.IsZero() && exists:
+		atime = times.Get(info).AccessTime()
+	}
+	if mtime.IsZero() {
+		mtime = now
+	}
+	if atime.IsZero() {
+		atime = now
+	}
+
+	if fs.dryRunMode() {
+		var lines []string
+		if !exists {
+			for _, dir := range missingParents(validPath) {
+				lines = append(lines, fmt.Sprintf("Would create directory %s", dir))
+			}
+			lines = append(lines, fmt.Sprintf("Would create empty file %s", validPath))
+		}
+		lines = append(lines, fmt.Sprintf("Would set the modification time of %s to %s and its access time to %s", validPath, formatTime(mtime), formatTime(atime)))
+		return dryRunResult(lines...), nil
+	}
+
+	if !exists {
+		if err := os.MkdirAll(filepath.Dir(validPath), 0755); err != nil {
+			return errorResult(fmt.Errorf("Error creating parent directories: %w", err)), nil
+		}
+		// O_EXCL keeps a file created meanwhile from being truncated
+		f, err := os.OpenFile(validPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil && !os.IsExist(err) {
+			return errorResult(fmt.Errorf("Error creating file: %w", err)), nil
+		}
+		if f != nil {
+			f.Close()
+		}
+	}
+	if err := os.Chtimes(validPath, atime, mtime); err != nil {
+		return errorResult(fmt.Errorf("Error setting times: %w", err)), nil
+	}
+	info, err = os.Stat(validPath)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+
+	result := TouchResult{Path: validPath, Created: !exists, FileTimes: localFileTimes(info, time.Now())}
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, result)
+	}
+	action := "Updated the times of"
+	if result.Created {
+		action = "Created empty file"
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("%s %s\nModified: %s\nAccessed: %s",
+		action, validPath, describeTime(info.ModTime(), now, false), describeTime(times.Get(info).AccessTime(), now, false))), nil
+}
+
+// parseTimeArgument reads a time argument given as RFC 3339 or as Unix
+// seconds, returning the zero time when it is absent
+func parseTimeArgument(request mcp.CallToolRequest, name string) (time.Time, error) {
+	switch v := request.GetArguments()[name].(type) {
+	case nil:
+		return time.Time{}, nil
+	case float64:
+		return time.Unix(int64(v), 0), nil
+	case int:
+		return time.Unix(int64(v), 0), nil
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t, nil
+		}
+		if seconds, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return time.Unix(seconds, 0), nil
+		}
+	}
+	return time.Time{}, toolError(CodeInvalidArgument, "Error: %s must be an RFC 3339 time such as 2024-05-01T12:00:00Z or Unix seconds", name).With(name, request.GetArguments()[name])
+}
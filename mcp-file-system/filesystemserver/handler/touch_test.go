@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/djherbis/times"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTouch(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	file := filepath.Join(dir, "build", "stamp")
+	res := callTool(t, fsHandler.HandleTouch, map[string]any{"path": file})
+	assert.Equal(t, CodeNotFound, ResultError(res).Code)
+
+	res = callTool(t, fsHandler.HandleTouch, map[string]any{"path": file, "create_parents": true, "mtime": "2024-05-01T12:00:00Z"})
+	require.False(t, res.IsError)
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	assert.Zero(t, info.Size())
+	assert.True(t, info.ModTime().Equal(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)))
+
+	// Content is kept, and only the time given changes
+	require.NoError(t, os.WriteFile(file, []byte("keep"), 0644))
+	require.NoError(t, os.Chtimes(file, time.Unix(1000, 0), time.Unix(2000, 0)))
+	res = callTool(t, fsHandler.HandleTouch, map[string]any{"path": file, "atime": "1700000000"})
+	require.False(t, res.IsError)
+	info, err = os.Stat(file)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2000), info.ModTime().Unix())
+	assert.Equal(t, int64(1700000000), times.Get(info).AccessTime().Unix())
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "keep", string(content))
+
+	res = callTool(t, fsHandler.HandleTouch, map[string]any{"path": file})
+	require.False(t, res.IsError)
+	info, err = os.Stat(file)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), info.ModTime(), time.Minute)
+
+	res = callTool(t, fsHandler.HandleTouch, map[string]any{"path": filepath.Join(dir, "missing"), "no_create": true})
+	assert.Equal(t, CodeNotFound, ResultError(res).Code)
+	assert.NoFileExists(t, filepath.Join(dir, "missing"))
+
+	res = callTool(t, fsHandler.HandleTouch, map[string]any{"path": file, "mtime": "yesterday"})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+}
+
+func TestSetFileTimes(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	file := filepath.Join(dir, "build.o")
+	require.NoError(t, os.WriteFile(file, []byte("obj"), 0644))
+	require.NoError(t, os.Chtimes(file, time.Unix(1000, 0), time.Unix(2000, 0)))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	res := callTool(t, fsHandler.HandleSetFileTimes, map[string]any{"path": file, "mtime": "2024-05-01T12:00:00Z"})
+	require.False(t, res.IsError, res.Content)
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	assert.True(t, info.ModTime().Equal(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)))
+	assert.Equal(t, int64(1000), times.Get(info).AccessTime().Unix())
+
+	// Unlike touch, a time is required and a missing file is not created
+	res = callTool(t, fsHandler.HandleSetFileTimes, map[string]any{"path": file})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	res = callTool(t, fsHandler.HandleSetFileTimes, map[string]any{"path": filepath.Join(dir, "missing"), "mtime": 0})
+	assert.Equal(t, CodeNotFound, ResultError(res).Code)
+	assert.NoFileExists(t, filepath.Join(dir, "missing"))
+}
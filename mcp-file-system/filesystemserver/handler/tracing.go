@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies spans created by this package
+const tracerName = "github.com/mark3labs/mcp-filesystem-server/filesystemserver/handler"
+
+// WithTracerProvider sets the OpenTelemetry provider used for tool and croc
+// spans (default: the global provider, a no-op unless one is installed)
+func WithTracerProvider(tp trace.TracerProvider) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		fs.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// TraceToolCall wraps a tool handler in a span carrying the tool name and
+// its path arguments, both as given and as resolved within the allowed
+// directories. A W3C trace context in the request's _meta (traceparent,
+// tracestate) continues the caller's trace.
+func (fs *FilesystemHandler) TraceToolCall(tool string, next ToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = extractMetaTraceContext(ctx, request)
+		ctx, span := fs.tracer.Start(ctx, "tools/call "+tool,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attribute.String("mcp.tool.name", tool)),
+		)
+		defer span.End()
+
+		args := request.GetArguments()
+		for _, key := range logPathArguments {
+			v, ok := args[key].(string)
+			if !ok || v == "" {
+				continue
+			}
+			span.SetAttributes(attribute.String("fs."+key, v))
+			if key == "remote" || isSMBPath(v) {
+				continue
+			}
+			if resolved, err := fs.validatePath(v); err == nil {
+				span.SetAttributes(attribute.String("fs."+key+".resolved", resolved))
+			}
+		}
+
+		result, err := next(ctx, request)
+		switch {
+		case err != nil:
+			markSpanError(span, err.Error())
+		case result != nil && result.IsError:
+			markSpanError(span, toolErrorText(result))
+		}
+		return result, err
+	}
+}
+
+// extractMetaTraceContext continues a trace propagated in the request _meta
+// unless ctx already carries one, e.g. from HTTP headers
+func extractMetaTraceContext(ctx context.Context, request mcp.CallToolRequest) context.Context {
+	if trace.SpanContextFromContext(ctx).IsValid() || request.Params.Meta == nil {
+		return ctx
+	}
+	carrier := propagation.MapCarrier{}
+	for key, value := range request.Params.Meta.AdditionalFields {
+		if s, ok := value.(string); ok {
+			carrier[key] = s
+		}
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
+
+// markSpanError marks span as failed with msg; the caller still ends the span
+func markSpanError(span trace.Span, msg string) {
+	span.SetStatus(codes.Error, msg)
+	span.SetAttributes(attribute.String("error.message", msg))
+}
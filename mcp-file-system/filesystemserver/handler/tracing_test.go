@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTraceToolCall(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	oldPropagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(oldPropagator) })
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0644))
+	fsHandler, err := NewFilesystemHandler([]string{dir}, WithTracerProvider(tp))
+	require.NoError(t, err)
+	readFile := fsHandler.TraceToolCall("read_file", fsHandler.HandleReadFile)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"path": filepath.Join(dir, ".", "a.txt")}
+	req.Params.Meta = &mcp.Meta{AdditionalFields: map[string]any{
+		"traceparent": "00-" + traceID + "-00f067aa0ba902b7-01",
+	}}
+	_, err = readFile(context.Background(), req)
+	require.NoError(t, err)
+
+	req = mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"path": filepath.Join(dir, "missing.txt")}
+	_, err = readFile(context.Background(), req)
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "tools/call read_file", spans[0].Name())
+	assert.Equal(t, traceID, spans[0].SpanContext().TraceID().String())
+	assert.Contains(t, spans[0].Attributes(), attribute.String("mcp.tool.name", "read_file"))
+	assert.Contains(t, spans[0].Attributes(), attribute.String("fs.path.resolved", filepath.Join(dir, "a.txt")))
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}
@@ -0,0 +1,544 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// trashDirName is the managed trash directory created inside each allowed directory
+	trashDirName = ".mcp-trash"
+	// trashInfoExt is the suffix of the metadata file kept for every trashed item
+	trashInfoExt = ".trashinfo"
+	// trashDateLayout is the DeletionDate format used by the XDG trash specification
+	trashDateLayout = "2006-01-02T15:04:05"
+	// osTrashEnv enables the desktop trash (XDG Trash) when set to a true value
+	osTrashEnv = "MCP_FS_OS_TRASH"
+)
+
+// TrashEntry describes an item that was moved to a trash can
+type TrashEntry struct {
+	ID           string    `json:"id"`
+	OriginalPath string    `json:"original_path"`
+	DeletedAt    time.Time `json:"deleted_at"`
+	Size         int64     `json:"size"`
+	IsDirectory  bool      `json:"is_directory"`
+	Trash        string    `json:"trash"` // "local" or "os"
+}
+
+// WithOSTrash routes trashed items to the desktop trash (XDG Trash) when supported
+func WithOSTrash(enabled bool) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		fs.osTrash = enabled
+	}
+}
+
+// osTrashFromEnv reports whether MCP_FS_OS_TRASH requests the desktop trash
+func osTrashFromEnv() bool {
+	switch strings.ToLower(os.Getenv(osTrashEnv)) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+// trashCan is a directory laid out like an XDG trash: files/ holds the trashed
+// items and info/ holds a .trashinfo file recording where each came from
+type trashCan struct {
+	dir  string
+	kind string
+}
+
+// xdgTrashDir returns the home trash directory from the XDG trash specification
+func xdgTrashDir() (string, error) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		return "", fmt.Errorf("desktop trash is not supported on %s", runtime.GOOS)
+	}
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		return filepath.Join(dataHome, "Trash"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "Trash"), nil
+}
+
+// trashCans returns every trash can visible to this handler, managed ones first
+func (fs *FilesystemHandler) trashCans() []trashCan {
+	cans := make([]trashCan, 0, len(fs.dirs())+1)
+	for _, dir := range fs.dirs() {
+		cans = append(cans, trashCan{dir: filepath.Join(dir, trashDirName), kind: "local"})
+	}
+	if fs.osTrash {
+		if dir, err := xdgTrashDir(); err == nil {
+			cans = append(cans, trashCan{dir: dir, kind: "os"})
+		}
+	}
+	return cans
+}
+
+// allowedRootOf returns the innermost allowed directory containing path,
+// or "" when there is none
+func (fs *FilesystemHandler) allowedRootOf(path string) string {
+	var best string
+	for _, dir := range fs.dirs() {
+		if strings.HasPrefix(path+string(filepath.Separator), dir) && len(dir) > len(best) {
+			best = dir
+		}
+	}
+	return best
+}
+
+// localTrashFor returns the managed trash can of the allowed directory that contains path
+func (fs *FilesystemHandler) localTrashFor(path string) (trashCan, error) {
+	best := fs.allowedRootOf(path)
+	if best == "" {
+		return trashCan{}, fmt.Errorf("no allowed directory contains %s", path)
+	}
+	return trashCan{dir: filepath.Join(best, trashDirName), kind: "local"}, nil
+}
+
+// isInTrash reports whether path lies inside one of the trash cans
+func (fs *FilesystemHandler) isInTrash(path string) bool {
+	for _, can := range fs.trashCans() {
+		if pathWithin(path, can.dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// moveToTrash moves validPath into the desktop trash when enabled, falling
+// back to the managed trash of its allowed directory
+func (fs *FilesystemHandler) moveToTrash(validPath string) (TrashEntry, error) {
+	for _, dir := range fs.dirs() {
+		if filepath.Clean(dir) == filepath.Clean(validPath) {
+			return TrashEntry{}, errors.New("cannot trash an allowed directory")
+		}
+	}
+	if fs.osTrash {
+		if dir, err := xdgTrashDir(); err == nil {
+			entry, err := trashCan{dir: dir, kind: "os"}.put(validPath)
+			if err == nil {
+				return entry, nil
+			}
+			// Typically a cross-device rename; the managed trash lives on the same volume
+		}
+	}
+	can, err := fs.localTrashFor(validPath)
+	if err != nil {
+		return TrashEntry{}, err
+	}
+	return can.put(validPath)
+}
+
+// put moves path into the can and writes its .trashinfo record
+func (c trashCan) put(path string) (TrashEntry, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return TrashEntry{}, err
+	}
+	filesDir := filepath.Join(c.dir, "files")
+	infoDir := filepath.Join(c.dir, "info")
+	if err := os.MkdirAll(filesDir, 0700); err != nil {
+		return TrashEntry{}, fmt.Errorf("failed to create trash: %w", err)
+	}
+	if err := os.MkdirAll(infoDir, 0700); err != nil {
+		return TrashEntry{}, fmt.Errorf("failed to create trash: %w", err)
+	}
+
+	// Reserve a unique name by creating the info file exclusively
+	now := time.Now()
+	var name string
+	var infoFile *os.File
+	for attempt := 0; ; attempt++ {
+		suffix := make([]byte, 4)
+		if _, err := rand.Read(suffix); err != nil {
+			return TrashEntry{}, err
+		}
+		name = fmt.Sprintf("%s.%s.%s", filepath.Base(path), now.Format("20060102T150405"), hex.EncodeToString(suffix))
+		infoFile, err = os.OpenFile(filepath.Join(infoDir, name+trashInfoExt), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) || attempt > 8 {
+			return TrashEntry{}, fmt.Errorf("failed to reserve trash entry: %w", err)
+		}
+	}
+	_, err = fmt.Fprintf(infoFile, "[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		(&url.URL{Path: filepath.ToSlash(path)}).EscapedPath(), now.Format(trashDateLayout))
+	if closeErr := infoFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(filepath.Join(infoDir, name+trashInfoExt))
+		return TrashEntry{}, fmt.Errorf("failed to write trash info: %w", err)
+	}
+
+	if err := os.Rename(path, filepath.Join(filesDir, name)); err != nil {
+		os.Remove(filepath.Join(infoDir, name+trashInfoExt))
+		return TrashEntry{}, err
+	}
+	return TrashEntry{
+		ID:           name,
+		OriginalPath: path,
+		DeletedAt:    now,
+		Size:         info.Size(),
+		IsDirectory:  info.IsDir(),
+		Trash:        c.kind,
+	}, nil
+}
+
+// list returns the entries recorded in the can
+func (c trashCan) list() ([]TrashEntry, error) {
+	infos, err := os.ReadDir(filepath.Join(c.dir, "info"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	entries := make([]TrashEntry, 0, len(infos))
+	for _, e := range infos {
+		if !strings.HasSuffix(e.Name(), trashInfoExt) {
+			continue
+		}
+		entry, err := c.entry(strings.TrimSuffix(e.Name(), trashInfoExt))
+		if err != nil {
+			continue // Skip records whose payload is gone or unreadable
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// entry loads the record for a single trashed item
+func (c trashCan) entry(name string) (TrashEntry, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return TrashEntry{}, fmt.Errorf("invalid trash id: %s", name)
+	}
+	f, err := os.Open(filepath.Join(c.dir, "info", name+trashInfoExt))
+	if err != nil {
+		return TrashEntry{}, err
+	}
+	defer f.Close()
+
+	entry := TrashEntry{ID: name, Trash: c.kind}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Path":
+			unescaped, err := url.PathUnescape(value)
+			if err != nil {
+				return TrashEntry{}, err
+			}
+			entry.OriginalPath = filepath.FromSlash(unescaped)
+			if !filepath.IsAbs(entry.OriginalPath) {
+				// Relative paths are relative to the volume holding the trash
+				entry.OriginalPath = filepath.Join(filepath.Dir(c.dir), entry.OriginalPath)
+			}
+		case "DeletionDate":
+			if t, err := time.ParseInLocation(trashDateLayout, value, time.Local); err == nil {
+				entry.DeletedAt = t
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return TrashEntry{}, err
+	}
+	if entry.OriginalPath == "" {
+		return TrashEntry{}, fmt.Errorf("trash info for %s has no Path", name)
+	}
+
+	info, err := os.Lstat(filepath.Join(c.dir, "files", name))
+	if err != nil {
+		return TrashEntry{}, err
+	}
+	entry.Size = info.Size()
+	entry.IsDirectory = info.IsDir()
+	return entry, nil
+}
+
+// findTrashEntry locates an entry by id across all trash cans, ignoring
+// entries that did not originate from an allowed directory
+func (fs *FilesystemHandler) findTrashEntry(id string) (trashCan, TrashEntry, error) {
+	for _, can := range fs.trashCans() {
+		entry, err := can.entry(id)
+		if err == nil && fs.isPathInAllowedDirs(filepath.Dir(entry.OriginalPath)) {
+			return can, entry, nil
+		}
+	}
+	return trashCan{}, TrashEntry{}, toolError(CodeNotFound, "no trash entry with id %q", id).With("id", id)
+}
+
+// visibleTrashEntries lists entries from all cans whose original location is allowed
+func (fs *FilesystemHandler) visibleTrashEntries() ([]TrashEntry, error) {
+	var all []TrashEntry
+	for _, can := range fs.trashCans() {
+		entries, err := can.list()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read trash %s: %w", can.dir, err)
+		}
+		for _, e := range entries {
+			// The desktop trash is shared with other applications
+			if fs.isPathInAllowedDirs(filepath.Dir(e.OriginalPath)) {
+				all = append(all, e)
+			}
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].DeletedAt.After(all[j].DeletedAt)
+	})
+	return all, nil
+}
+
+// HandleListTrash handles the list_trash tool
+func (fs *FilesystemHandler) HandleListTrash(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	entries, err := fs.visibleTrashEntries()
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	if path, _ := request.RequireString("path"); path != "" {
+		validPath, err := fs.validatePath(path)
+		if err != nil {
+			return errorResult(fmt.Errorf("Error: %w", err)), nil
+		}
+		filtered := entries[:0]
+		for _, e := range entries {
+			if pathWithin(e.OriginalPath, validPath) {
+				filtered = append(filtered, e)
+			}
+		}
+		entries = filtered
+	}
+
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, TrashListing{Entries: append([]TrashEntry{}, entries...)})
+	}
+	if len(entries) == 0 {
+		return mcp.NewToolResultText("Trash is empty."), nil
+	}
+	jsonBytes, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return errorResult(fmt.Errorf("failed to marshal response: %w", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// HandleRestoreFromTrash handles the restore_from_trash tool
+func (fs *FilesystemHandler) HandleRestoreFromTrash(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	id, err := request.RequireString("id")
+	if err != nil || id == "" {
+		return errorResult(toolError(CodeInvalidArgument, "id is required")), nil
+	}
+	overwrite := false
+	if val, err := request.RequireBool("overwrite"); err == nil {
+		overwrite = val
+	}
+
+	can, entry, err := fs.findTrashEntry(id)
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	target := entry.OriginalPath
+	if dest, _ := request.RequireString("destination"); dest != "" {
+		target = dest
+	}
+	validTarget, err := fs.validateWritablePath(target)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if fs.isInTrash(validTarget) {
+		return errorResult(toolError(CodeInvalidArgument, "Error: cannot restore into a trash directory")), nil
+	}
+
+	_, statErr := os.Lstat(validTarget)
+	if statErr == nil && !overwrite {
+		return errorResult(toolError(CodeAlreadyExists, "Error: %s already exists. Use overwrite=true or choose a destination.", validTarget).With("path", validTarget)), nil
+	}
+	if fs.dryRunMode() {
+		var lines []string
+		if statErr == nil {
+			lines = append(lines, fmt.Sprintf("Would replace %s", describeTree(validTarget)))
+		}
+		lines = append(lines, fmt.Sprintf("Would restore %s to %s", describeTree(filepath.Join(can.dir, "files", entry.ID)), validTarget))
+		return dryRunResult(lines...), nil
+	}
+	if err := os.MkdirAll(filepath.Dir(validTarget), 0755); err != nil {
+		return errorResult(fmt.Errorf("Error creating parent directories: %w", err)), nil
+	}
+	// The existing target is moved aside rather than removed, so a failed
+	// restore can put it back
+	var aside string
+	if statErr == nil {
+		aside = filepath.Join(filepath.Dir(validTarget), fmt.Sprintf(".%s.%d.replaced", filepath.Base(validTarget), time.Now().UnixNano()))
+		if err := os.Rename(validTarget, aside); err != nil {
+			return errorResult(fmt.Errorf("Error replacing existing %s: %w", validTarget, err)), nil
+		}
+	}
+
+	src := filepath.Join(can.dir, "files", entry.ID)
+	if err := os.Rename(src, validTarget); err != nil {
+		// Fall back to copy and remove across volumes
+		var copyErr error
+		if entry.IsDirectory {
+			copyErr = copyDir(src, validTarget)
+		} else {
+			copyErr = copyFile(src, validTarget)
+		}
+		if copyErr != nil {
+			os.RemoveAll(validTarget)
+			if aside != "" {
+				os.Rename(aside, validTarget)
+			}
+			return errorResult(fmt.Errorf("Error restoring %s: %w", entry.ID, copyErr)), nil
+		}
+		os.RemoveAll(src)
+	}
+	if aside != "" {
+		os.RemoveAll(aside)
+	}
+	os.Remove(filepath.Join(can.dir, "info", entry.ID+trashInfoExt))
+
+	return mcp.NewToolResultText(fmt.Sprintf("Restored %s to %s", entry.ID, validTarget)), nil
+}
+
+// EmptyTrashResult is the JSON result of empty_trash
+type EmptyTrashResult struct {
+	Removed []TrashEntry `json:"removed"`
+	// FreedBytes totals the sizes of the removed items, directories included
+	FreedBytes int64 `json:"freed_bytes"`
+	DryRun     bool  `json:"dry_run,omitempty"`
+}
+
+// trashedItem is a trash entry together with the can holding it
+type trashedItem struct {
+	can   trashCan
+	entry TrashEntry
+}
+
+// payload is where the trashed item itself is kept
+func (t trashedItem) payload() string {
+	return filepath.Join(t.can.dir, "files", t.entry.ID)
+}
+
+// HandleEmptyTrash handles the empty_trash tool
+func (fs *FilesystemHandler) HandleEmptyTrash(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if fs.readOnlyMode() {
+		return errorResult(toolError(CodeReadOnly, "Error: access denied - server is in read-only mode")), nil
+	}
+	id := request.GetString("id", "")
+	path := request.GetString("path", "")
+	var cutoff time.Time
+	if days, err := request.RequireFloat("older_than_days"); err == nil {
+		if days < 0 {
+			return errorResult(toolError(CodeInvalidArgument, "Error: older_than_days must not be negative")), nil
+		}
+		cutoff = time.Now().Add(-time.Duration(days * float64(24*time.Hour)))
+	}
+	// Emptying everything has to be asked for explicitly
+	if id == "" && path == "" && cutoff.IsZero() && !request.GetBool("all", false) {
+		return errorResult(toolError(CodeInvalidArgument, "Error: give id, path or older_than_days, or all=true to empty the whole trash")), nil
+	}
+	dryRun := request.GetBool("dry_run", false) || fs.dryRunMode()
+
+	var within string
+	if path != "" {
+		validPath, err := fs.validatePath(path)
+		if err != nil {
+			return errorResult(fmt.Errorf("Error: %w", err)), nil
+		}
+		within = validPath
+	}
+	var candidates []trashedItem
+	if id != "" {
+		can, entry, err := fs.findTrashEntry(id)
+		if err != nil {
+			return errorResult(err), nil
+		}
+		candidates = append(candidates, trashedItem{can, entry})
+	} else {
+		for _, can := range fs.trashCans() {
+			entries, err := can.list()
+			if err != nil {
+				return errorResult(fmt.Errorf("failed to read trash %s: %w", can.dir, err)), nil
+			}
+			for _, e := range entries {
+				// The desktop trash is shared with other applications
+				if fs.isPathInAllowedDirs(filepath.Dir(e.OriginalPath)) {
+					candidates = append(candidates, trashedItem{can, e})
+				}
+			}
+		}
+	}
+	var items []trashedItem
+	for _, item := range candidates {
+		switch {
+		case within != "" && !pathWithin(item.entry.OriginalPath, within):
+		case !cutoff.IsZero() && !item.entry.DeletedAt.Before(cutoff):
+		default:
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].entry.DeletedAt.Before(items[j].entry.DeletedAt)
+	})
+
+	result := EmptyTrashResult{Removed: []TrashEntry{}, DryRun: dryRun}
+	var lines []string
+	for _, item := range items {
+		size := item.entry.Size
+		if item.entry.IsDirectory {
+			if n, err := dirSize(ctx, item.payload()); err == nil {
+				size = n
+			}
+		}
+		if dryRun {
+			lines = append(lines, fmt.Sprintf("Would permanently delete %s, originally %s", describeTree(item.payload()), item.entry.OriginalPath))
+		} else {
+			if err := os.RemoveAll(item.payload()); err != nil {
+				return errorResult(fmt.Errorf("Error deleting %s from the trash after removing %d items: %w", item.entry.ID, len(result.Removed), err)), nil
+			}
+			os.Remove(filepath.Join(item.can.dir, "info", item.entry.ID+trashInfoExt))
+		}
+		result.Removed = append(result.Removed, item.entry)
+		result.FreedBytes += size
+	}
+
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, result)
+	}
+	if len(result.Removed) == 0 {
+		return mcp.NewToolResultText("Nothing in the trash matches."), nil
+	}
+	if dryRun {
+		return dryRunResult(lines...), nil
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Permanently deleted %d item(s) from the trash, freeing %s:\n", len(result.Removed), formatFileSize(result.FreedBytes)))
+	for _, entry := range result.Removed {
+		sb.WriteString(fmt.Sprintf("  %s (originally %s)\n", entry.ID, entry.OriginalPath))
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
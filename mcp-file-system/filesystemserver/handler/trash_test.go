@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrashDeleteListRestore(t *testing.T) {
+	tmpDir := t.TempDir()
+	fsHandler, err := NewFilesystemHandler(resolveAllowedDirs(t, tmpDir), WithOSTrash(false))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	resolved, err := filepath.EvalSymlinks(tmpDir)
+	require.NoError(t, err)
+	filePath := filepath.Join(resolved, "notes.txt")
+	require.NoError(t, os.WriteFile(filePath, []byte("keep me"), 0644))
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"path": filePath, "trash": true}
+	res, err := fsHandler.HandleDeleteFile(ctx, req)
+	require.NoError(t, err)
+	require.False(t, res.IsError, res.Content[0].(mcp.TextContent).Text)
+	_, err = os.Stat(filePath)
+	assert.True(t, os.IsNotExist(err))
+
+	res, err = fsHandler.HandleListTrash(ctx, mcp.CallToolRequest{})
+	require.NoError(t, err)
+	require.False(t, res.IsError)
+	var entries []TrashEntry
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &entries))
+	require.Len(t, entries, 1)
+	assert.Equal(t, filePath, entries[0].OriginalPath)
+	assert.Equal(t, "local", entries[0].Trash)
+	assert.Equal(t, int64(7), entries[0].Size)
+
+	// Restoring onto an existing file requires overwrite
+	require.NoError(t, os.WriteFile(filePath, []byte("new"), 0644))
+	req = mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"id": entries[0].ID}
+	res, err = fsHandler.HandleRestoreFromTrash(ctx, req)
+	require.NoError(t, err)
+	require.True(t, res.IsError)
+
+	req.Params.Arguments = map[string]any{"id": entries[0].ID, "overwrite": true}
+	res, err = fsHandler.HandleRestoreFromTrash(ctx, req)
+	require.NoError(t, err)
+	require.False(t, res.IsError, res.Content[0].(mcp.TextContent).Text)
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "keep me", string(content))
+
+	res, err = fsHandler.HandleListTrash(ctx, mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "Trash is empty.", res.Content[0].(mcp.TextContent).Text)
+}
+
+func TestTrashRestoreToDestination(t *testing.T) {
+	tmpDir := t.TempDir()
+	fsHandler, err := NewFilesystemHandler(resolveAllowedDirs(t, tmpDir), WithOSTrash(false))
+	require.NoError(t, err)
+
+	resolved, err := filepath.EvalSymlinks(tmpDir)
+	require.NoError(t, err)
+	dirPath := filepath.Join(resolved, "build")
+	require.NoError(t, os.MkdirAll(filepath.Join(dirPath, "out"), 0755))
+
+	entry, err := fsHandler.moveToTrash(dirPath)
+	require.NoError(t, err)
+	assert.True(t, entry.IsDirectory)
+
+	dest := filepath.Join(resolved, "build-restored")
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"id": entry.ID, "destination": dest}
+	res, err := fsHandler.HandleRestoreFromTrash(context.Background(), req)
+	require.NoError(t, err)
+	require.False(t, res.IsError, res.Content[0].(mcp.TextContent).Text)
+	assert.DirExists(t, filepath.Join(dest, "out"))
+
+	_, err = fsHandler.moveToTrash(resolved)
+	assert.Error(t, err, "allowed directories cannot be trashed")
+}
+
+func TestTrashRestoreFailureKeepsTarget(t *testing.T) {
+	// Restoring across devices falls back to copying, which a socket in the
+	// trashed directory makes fail
+	shm, err := os.MkdirTemp("/dev/shm", "trash")
+	if err != nil {
+		t.Skip(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(shm) })
+	dirs := resolveAllowedDirs(t, shm, t.TempDir())
+	probe := filepath.Join(dirs[0], "probe")
+	require.NoError(t, os.WriteFile(probe, nil, 0644))
+	if os.Rename(probe, filepath.Join(dirs[1], "probe")) == nil {
+		t.Skip("temporary directories share a device")
+	}
+	require.NoError(t, os.Remove(probe))
+	fsHandler, err := NewFilesystemHandler(dirs, WithOSTrash(false))
+	require.NoError(t, err)
+
+	build := filepath.Join(dirs[0], "build")
+	require.NoError(t, os.Mkdir(build, 0755))
+	entry, err := fsHandler.moveToTrash(build)
+	require.NoError(t, err)
+	listener, err := net.Listen("unix", filepath.Join(dirs[0], trashDirName, "files", entry.ID, "s"))
+	if err != nil {
+		t.Skip(err)
+	}
+	defer listener.Close()
+
+	target := filepath.Join(dirs[1], "build")
+	require.NoError(t, os.WriteFile(target, []byte("current"), 0644))
+	res := callTool(t, fsHandler.HandleRestoreFromTrash, map[string]any{"id": entry.ID, "destination": target, "overwrite": true})
+	require.True(t, res.IsError)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Error restoring "+entry.ID)
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "current", string(content))
+	entries, err := os.ReadDir(dirs[1])
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "nothing is left aside")
+	assert.DirExists(t, filepath.Join(dirs[0], trashDirName, "files", entry.ID))
+}
+
+func TestXDGTrashIgnoresForeignEntries(t *testing.T) {
+	if _, err := xdgTrashDir(); err != nil {
+		t.Skip(err)
+	}
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	xdg, err := xdgTrashDir()
+	require.NoError(t, err)
+
+	tmpDir := t.TempDir()
+	fsHandler, err := NewFilesystemHandler(resolveAllowedDirs(t, tmpDir), WithOSTrash(true))
+	require.NoError(t, err)
+
+	// An item trashed by another application from outside the allowed directories
+	foreign := filepath.Join(t.TempDir(), "secret.txt")
+	require.NoError(t, os.WriteFile(foreign, []byte("x"), 0644))
+	entry, err := trashCan{dir: xdg, kind: "os"}.put(foreign)
+	require.NoError(t, err)
+
+	entries, err := fsHandler.visibleTrashEntries()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	_, _, err = fsHandler.findTrashEntry(entry.ID)
+	assert.Error(t, err)
+}
+
+func TestEmptyTrash(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	fsHandler, err := NewFilesystemHandler([]string{dir}, WithOSTrash(false))
+	require.NoError(t, err)
+	for _, name := range []string{"a.txt", "keep/b.txt", "old/c.txt"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("12345"), 0644))
+	}
+	trash := func(path string) TrashEntry {
+		entry, err := fsHandler.moveToTrash(filepath.Join(dir, path))
+		require.NoError(t, err)
+		return entry
+	}
+	a, b, c := trash("a.txt"), trash("keep/b.txt"), trash("old")
+	// Age the directory's record as if it was trashed long ago
+	stale := time.Now().AddDate(0, 0, -40).Format(trashDateLayout)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, trashDirName, "info", c.ID+trashInfoExt),
+		[]byte("[Trash Info]\nPath="+filepath.ToSlash(c.OriginalPath)+"\nDeletionDate="+stale+"\n"), 0600))
+
+	empty := fsHandler.FormatToolCall(fsHandler.HandleEmptyTrash)
+	run := func(args map[string]any) EmptyTrashResult {
+		args["format"] = FormatJSON
+		res := callTool(t, empty, args)
+		require.False(t, res.IsError, res.Content)
+		var result EmptyTrashResult
+		require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &result))
+		return result
+	}
+	ids := func(result EmptyTrashResult) []string {
+		var ids []string
+		for _, entry := range result.Removed {
+			ids = append(ids, entry.ID)
+		}
+		return ids
+	}
+
+	res := callTool(t, fsHandler.HandleEmptyTrash, map[string]any{})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+
+	preview := run(map[string]any{"older_than_days": 30, "dry_run": true})
+	assert.Equal(t, []string{c.ID}, ids(preview))
+	assert.True(t, preview.DryRun)
+	assert.DirExists(t, filepath.Join(dir, trashDirName, "files", c.ID))
+
+	removed := run(map[string]any{"older_than_days": 30})
+	assert.Equal(t, []string{c.ID}, ids(removed))
+	assert.Equal(t, int64(5), removed.FreedBytes)
+	assert.NoDirExists(t, filepath.Join(dir, trashDirName, "files", c.ID))
+
+	assert.Equal(t, []string{b.ID}, ids(run(map[string]any{"path": filepath.Join(dir, "keep")})))
+	assert.Empty(t, ids(run(map[string]any{"id": a.ID, "path": filepath.Join(dir, "keep")})))
+
+	res = callTool(t, fsHandler.HandleEmptyTrash, map[string]any{"all": true})
+	require.False(t, res.IsError, res.Content)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Permanently deleted 1 item(s)")
+	entries, err := fsHandler.visibleTrashEntries()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
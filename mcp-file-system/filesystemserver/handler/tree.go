@@ -24,15 +24,7 @@ func (fs *FilesystemHandler) HandleTree(
 		// Get current working directory
 		cwd, err := os.Getwd()
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					mcp.TextContent{
-						Type: "text",
-						Text: fmt.Sprintf("Error resolving current directory: %v", err),
-					},
-				},
-				IsError: true,
-			}, nil
+			return errorResult(fmt.Errorf("Error resolving current directory: %w", err)), nil
 		}
 		path = cwd
 	}
@@ -52,69 +44,50 @@ func (fs *FilesystemHandler) HandleTree(
 	// Validate the path is within allowed directories
 	validPath, err := fs.validatePath(path)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
 	}
 
 	// Check if it's a directory
 	info, err := os.Stat(validPath)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
 	}
 
 	if !info.IsDir() {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: "Error: The specified path is not a directory",
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(toolError(CodeWrongType, "Error: The specified path is not a directory")), nil
+	}
+
+	filter, err := fs.newWalkFilter(request, validPath)
+	if err != nil {
+		return errorResult(err), nil
 	}
 
 	// Build the tree structure
-	tree, err := fs.buildTree(validPath, depth, 0, followSymlinks)
+	tree, err := fs.buildTree(validPath, depth, 0, followSymlinks, filter)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error building directory tree: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error building directory tree: %w", err)), nil
+	}
+	if request.GetBool("rollup", false) {
+		if err := fs.addRollups(ctx, tree, 0, depth, filter); err != nil {
+			return errorResult(fmt.Errorf("Error totalling directory sizes: %w", err)), nil
+		}
+	}
+
+	switch responseFormat(ctx) {
+	case FormatJSON:
+		return jsonResult(ctx, tree)
+	case TreeFormatASCII:
+		return mcp.NewToolResultText(renderTreeASCII(tree)), nil
+	case TreeFormatMermaid:
+		return mcp.NewToolResultText(renderTreeMermaid(tree)), nil
+	case TreeFormatGraphviz:
+		return mcp.NewToolResultText(renderTreeGraphviz(tree)), nil
 	}
 
 	// Convert to JSON
 	jsonData, err := json.MarshalIndent(tree, "", "  ")
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error generating JSON: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error generating JSON: %w", err)), nil
 	}
 
 	// Create resource URI for the directory
@@ -139,8 +112,9 @@ func (fs *FilesystemHandler) HandleTree(
 	}, nil
 }
 
-// buildTree builds a tree representation of the filesystem starting at the given path
-func (fs *FilesystemHandler) buildTree(path string, maxDepth int, currentDepth int, followSymlinks bool) (*FileNode, error) {
+// buildTree builds a tree representation of the filesystem starting at the given path,
+// leaving out entries that filter skips
+func (fs *FilesystemHandler) buildTree(path string, maxDepth int, currentDepth int, followSymlinks bool, filter *walkFilter) (*FileNode, error) {
 	// Validate the path
 	validPath, err := fs.validatePath(path)
 	if err != nil {
@@ -155,9 +129,10 @@ func (fs *FilesystemHandler) buildTree(path string, maxDepth int, currentDepth i
 
 	// Create the node
 	node := &FileNode{
-		Name:     filepath.Base(validPath),
-		Path:     validPath,
-		Modified: info.ModTime(),
+		Name:         filepath.Base(validPath),
+		Path:         validPath,
+		Modified:     formatTime(info.ModTime()),
+		ModifiedUnix: unixTime(info.ModTime()),
 	}
 
 	// Set type and size
@@ -175,6 +150,9 @@ func (fs *FilesystemHandler) buildTree(path string, maxDepth int, currentDepth i
 			// Process each entry
 			for _, entry := range entries {
 				entryPath := filepath.Join(validPath, entry.Name())
+				if filter.skip(entryPath, entry.IsDir()) {
+					continue
+				}
 
 				// Handle symlinks
 				if entry.Type()&os.ModeSymlink != 0 {
@@ -200,7 +178,7 @@ func (fs *FilesystemHandler) buildTree(path string, maxDepth int, currentDepth i
 				}
 
 				// Recursively build child node
-				childNode, err := fs.buildTree(entryPath, maxDepth, currentDepth+1, followSymlinks)
+				childNode, err := fs.buildTree(entryPath, maxDepth, currentDepth+1, followSymlinks, filter)
 				if err != nil {
 					// Skip entries with errors
 					continue
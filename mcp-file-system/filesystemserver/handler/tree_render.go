@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Formats tree renders beyond text and json
+const (
+	// TreeFormatASCII draws the tree with box-drawing characters, like tree(1)
+	TreeFormatASCII = "ascii"
+	// TreeFormatMermaid returns a Mermaid flowchart
+	TreeFormatMermaid = "mermaid"
+	// TreeFormatGraphviz returns a Graphviz DOT digraph
+	TreeFormatGraphviz = "graphviz"
+)
+
+// TreeFormats lists the extra formats of the tree tool
+var TreeFormats = []string{TreeFormatASCII, TreeFormatMermaid, TreeFormatGraphviz}
+
+// nodeLabel names a node with its size and modification time, marking
+// directories with a trailing slash
+func nodeLabel(node *FileNode) (name, details string) {
+	name = node.Name
+	if node.Type == "directory" {
+		name += "/"
+		if r := node.Rollup; r != nil {
+			return name, fmt.Sprintf("%d files, %d bytes, modified %s", r.Files, r.TotalSize, node.Modified)
+		}
+		return name, "modified " + node.Modified
+	}
+	return name, fmt.Sprintf("%d bytes, modified %s", node.Size, node.Modified)
+}
+
+// renderTreeASCII draws tree with its root path on the first line
+func renderTreeASCII(tree *FileNode) string {
+	var sb strings.Builder
+	_, details := nodeLabel(tree)
+	sb.WriteString(fmt.Sprintf("%s (%s)\n", tree.Path, details))
+	var walk func(node *FileNode, prefix string)
+	walk = func(node *FileNode, prefix string) {
+		for i, child := range node.Children {
+			branch, indent := "├── ", "│   "
+			if i == len(node.Children)-1 {
+				branch, indent = "└── ", "    "
+			}
+			name, details := nodeLabel(child)
+			sb.WriteString(fmt.Sprintf("%s%s%s (%s)\n", prefix, branch, name, details))
+			walk(child, prefix+indent)
+		}
+	}
+	walk(tree, "")
+	return sb.String()
+}
+
+// renderTreeMermaid returns tree as a top-down Mermaid flowchart
+func renderTreeMermaid(tree *FileNode) string {
+	var sb strings.Builder
+	sb.WriteString("flowchart TD\n")
+	quote := strings.NewReplacer(`"`, "#quot;").Replace
+	id := 0
+	var walk func(node *FileNode) int
+	walk = func(node *FileNode) int {
+		nodeID := id
+		id++
+		name, details := nodeLabel(node)
+		sb.WriteString(fmt.Sprintf("    n%d[\"%s<br/>%s\"]\n", nodeID, quote(name), details))
+		for _, child := range node.Children {
+			childID := walk(child)
+			sb.WriteString(fmt.Sprintf("    n%d --> n%d\n", nodeID, childID))
+		}
+		return nodeID
+	}
+	walk(tree)
+	return sb.String()
+}
+
+// renderTreeGraphviz returns tree as a Graphviz DOT digraph
+func renderTreeGraphviz(tree *FileNode) string {
+	var sb strings.Builder
+	sb.WriteString("digraph tree {\n    rankdir=LR;\n    node [shape=box];\n")
+	quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace
+	id := 0
+	var walk func(node *FileNode) int
+	walk = func(node *FileNode) int {
+		nodeID := id
+		id++
+		name, details := nodeLabel(node)
+		sb.WriteString(fmt.Sprintf("    n%d [label=\"%s\\n%s\"];\n", nodeID, quote(name), details))
+		for _, child := range node.Children {
+			childID := walk(child)
+			sb.WriteString(fmt.Sprintf("    n%d -> n%d;\n", nodeID, childID))
+		}
+		return nodeID
+	}
+	walk(tree)
+	sb.WriteString("}\n")
+	return sb.String()
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+)
+
+// TreeRollup totals the files below a directory of a tree, however deep
+type TreeRollup struct {
+	Files     int64 `json:"file_count"`
+	TotalSize int64 `json:"total_size"`
+	// Largest is the biggest file found, if any
+	Largest *LargestFile `json:"largest_file,omitempty"`
+}
+
+// LargestFile is the biggest file below a directory
+type LargestFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// addFile counts a file of the given size
+func (r *TreeRollup) addFile(path string, size int64) {
+	r.Files++
+	r.TotalSize += size
+	if r.Largest == nil || size > r.Largest.Size {
+		r.Largest = &LargestFile{Path: path, Size: size}
+	}
+}
+
+// merge adds the totals of another rollup
+func (r *TreeRollup) merge(other *TreeRollup) {
+	r.Files += other.Files
+	r.TotalSize += other.TotalSize
+	if other.Largest != nil && (r.Largest == nil || other.Largest.Size > r.Largest.Size) {
+		r.Largest = other.Largest
+	}
+}
+
+// addRollups sets the rollup of every directory in the tree below node,
+// which sits at depth. Directories the tree expanded add up their
+// children; those at the depth cutoff are walked in full, without
+// following symbolic links or counting what filter skips.
+func (fs *FilesystemHandler) addRollups(ctx context.Context, node *FileNode, depth, maxDepth int, filter *walkFilter) error {
+	if node.Type != "directory" {
+		return nil
+	}
+	node.Rollup = &TreeRollup{}
+	if depth >= maxDepth {
+		return fs.walkRollup(ctx, node.Path, node.Rollup, filter)
+	}
+	for _, child := range node.Children {
+		if child.Type != "directory" {
+			node.Rollup.addFile(child.Path, child.Size)
+			continue
+		}
+		if err := fs.addRollups(ctx, child, depth+1, maxDepth, filter); err != nil {
+			return err
+		}
+		node.Rollup.merge(child.Rollup)
+	}
+	return nil
+}
+
+// walkRollup totals the regular files below dir that lie within the
+// allowed directories, skipping what cannot be read
+func (fs *FilesystemHandler) walkRollup(ctx context.Context, dir string, rollup *TreeRollup, filter *walkFilter) error {
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			return nil
+		}
+		if path != dir && filter.skip(path, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if !fs.isPathInAllowedDirs(path) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rollup.addFile(path, info.Size())
+		return nil
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
@@ -222,3 +223,79 @@ func TestHandleTree(t *testing.T) {
 		require.True(t, res.IsError)
 	})
 }
+
+func TestTreeFormats(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", `say "hi".txt`), []byte("hi"), 0644))
+	stamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, name := range []string{"", "sub", "a.txt", filepath.Join("sub", `say "hi".txt`)} {
+		require.NoError(t, os.Chtimes(filepath.Join(dir, name), stamp, stamp))
+	}
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	tree := fsHandler.FormatToolCall(fsHandler.HandleTree, TreeFormats...)
+	render := func(format string) string {
+		res := callTool(t, tree, map[string]any{"path": dir, "format": format})
+		require.False(t, res.IsError)
+		return res.Content[0].(mcp.TextContent).Text
+	}
+
+	assert.Equal(t, dir+" (modified 2024-01-02T03:04:05Z)\n"+
+		"├── a.txt (5 bytes, modified 2024-01-02T03:04:05Z)\n"+
+		"└── sub/ (modified 2024-01-02T03:04:05Z)\n"+
+		"    └── say \"hi\".txt (2 bytes, modified 2024-01-02T03:04:05Z)\n", render(TreeFormatASCII))
+
+	mermaid := render(TreeFormatMermaid)
+	assert.True(t, strings.HasPrefix(mermaid, "flowchart TD\n"))
+	assert.Contains(t, mermaid, `n3["say #quot;hi#quot;.txt<br/>2 bytes, modified 2024-01-02T03:04:05Z"]`)
+	assert.Contains(t, mermaid, "n2 --> n3")
+
+	graphviz := render(TreeFormatGraphviz)
+	assert.Contains(t, graphviz, `n3 [label="say \"hi\".txt\n2 bytes, modified 2024-01-02T03:04:05Z"];`)
+	assert.Contains(t, graphviz, "n0 -> n2;")
+
+	var node FileNode
+	require.NoError(t, json.Unmarshal([]byte(render(FormatJSON)), &node))
+	assert.Equal(t, stamp.Unix(), node.Children[0].ModifiedUnix)
+
+	res := callTool(t, tree, map[string]any{"path": dir, "format": "svg"})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+}
+
+func TestTreeRollup(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	for name, size := range map[string]int{"a.txt": 10, "data/x.bin": 300, "data/deep/y.bin": 5000, "data/deep/z.bin": 20} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, make([]byte, size), 0644))
+	}
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	tree := fsHandler.FormatToolCall(fsHandler.HandleTree, TreeFormats...)
+
+	res := callTool(t, tree, map[string]any{"path": dir, "depth": 1, "rollup": true, "format": FormatJSON})
+	require.False(t, res.IsError, res.Content)
+	var root FileNode
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &root))
+	require.NotNil(t, root.Rollup)
+	assert.Equal(t, TreeRollup{Files: 4, TotalSize: 5330, Largest: &LargestFile{Path: filepath.Join(dir, "data", "deep", "y.bin"), Size: 5000}}, *root.Rollup)
+	var data *FileNode
+	for _, child := range root.Children {
+		if child.Name == "data" {
+			data = child
+		}
+	}
+	// data sits at the depth cutoff, so its children are not listed but still counted
+	require.NotNil(t, data)
+	assert.Empty(t, data.Children)
+	assert.Equal(t, int64(3), data.Rollup.Files)
+	assert.Equal(t, int64(5320), data.Rollup.TotalSize)
+
+	res = callTool(t, tree, map[string]any{"path": dir, "depth": 1, "rollup": true, "format": TreeFormatASCII})
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "data/ (3 files, 5320 bytes, modified")
+
+	res = callTool(t, tree, map[string]any{"path": dir, "format": FormatJSON})
+	assert.NotContains(t, res.Content[0].(mcp.TextContent).Text, "rollup")
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultCrocReceiveTimeout bounds croc_receive unless configured otherwise
+const defaultCrocReceiveTimeout = 10 * time.Minute
+
+// Tuning sets timeouts and concurrency. Zero fields keep the defaults.
+type Tuning struct {
+	// ToolTimeout bounds every tool call (default: no limit). Tools stop at
+	// their next cancellation point, such as between files or by killing a
+	// subprocess; a file operation already under way is completed.
+	ToolTimeout time.Duration `json:"tool_timeout,omitempty" yaml:"tool_timeout,omitempty"`
+	// ToolTimeouts overrides ToolTimeout for individual tools by name
+	ToolTimeouts map[string]time.Duration `json:"tool_timeouts,omitempty" yaml:"tool_timeouts,omitempty"`
+	// MaxConcurrentCalls limits the tool calls executing at once; further
+	// calls wait for a free slot (default: unlimited)
+	MaxConcurrentCalls int `json:"max_concurrent_calls,omitempty" yaml:"max_concurrent_calls,omitempty"`
+	// WalkerParallelism is the number of files search_within_files scans,
+	// and read_multiple_files reads, at once (default: the number of CPUs)
+	WalkerParallelism int `json:"walker_parallelism,omitempty" yaml:"walker_parallelism,omitempty"`
+	// CrocReceiveTimeout bounds a croc_receive transfer (default: 10m)
+	CrocReceiveTimeout time.Duration `json:"croc_receive_timeout,omitempty" yaml:"croc_receive_timeout,omitempty"`
+}
+
+// defaultTuning returns the built-in timeouts and concurrency
+func defaultTuning() Tuning {
+	return Tuning{
+		WalkerParallelism:  runtime.GOMAXPROCS(0),
+		CrocReceiveTimeout: defaultCrocReceiveTimeout,
+	}
+}
+
+// WithTuning overrides the default timeouts and concurrency. Unlike Limits,
+// tuning is fixed when the handler is created and not changed by Reload.
+func WithTuning(tuning Tuning) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		if tuning.ToolTimeout > 0 {
+			fs.tuning.ToolTimeout = tuning.ToolTimeout
+		}
+		if len(tuning.ToolTimeouts) > 0 {
+			fs.tuning.ToolTimeouts = tuning.ToolTimeouts
+		}
+		if tuning.MaxConcurrentCalls > 0 {
+			fs.tuning.MaxConcurrentCalls = tuning.MaxConcurrentCalls
+		}
+		if tuning.WalkerParallelism > 0 {
+			fs.tuning.WalkerParallelism = tuning.WalkerParallelism
+		}
+		if tuning.CrocReceiveTimeout > 0 {
+			fs.tuning.CrocReceiveTimeout = tuning.CrocReceiveTimeout
+		}
+	}
+}
+
+// LimitToolCall wraps a tool handler so the call waits for a free slot when
+// MaxConcurrentCalls is set and is cancelled once its timeout passes
+func (fs *FilesystemHandler) LimitToolCall(tool string, next ToolHandlerFunc) ToolHandlerFunc {
+	timeout := fs.tuning.ToolTimeout
+	if t, ok := fs.tuning.ToolTimeouts[tool]; ok {
+		timeout = t
+	}
+	if timeout <= 0 && fs.slots == nil {
+		return next
+	}
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if fs.slots != nil {
+			select {
+			case fs.slots <- struct{}{}:
+				defer func() { <-fs.slots }()
+			case <-ctx.Done():
+				return errorResult(toolError(CodeCancelled, "operation cancelled while waiting for other tool calls to finish")), nil
+			}
+		}
+		if timeout <= 0 {
+			return next(ctx, request)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		result, err := next(ctx, request)
+		// A call that finished despite the deadline keeps its result
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && (err != nil || result == nil || result.IsError) {
+			return errorResult(toolError(CodeTimeout, "%s timed out after %s", tool, timeout)), nil
+		}
+		return result, err
+	}
+}
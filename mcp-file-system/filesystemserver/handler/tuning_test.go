@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitToolCallTimeout(t *testing.T) {
+	fsHandler, err := NewFilesystemHandler(resolveAllowedDirs(t, t.TempDir()), WithTuning(Tuning{
+		ToolTimeout:  time.Hour,
+		ToolTimeouts: map[string]time.Duration{"slow_tool": 20 * time.Millisecond},
+	}))
+	require.NoError(t, err)
+
+	slow := fsHandler.LimitToolCall("slow_tool", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		<-ctx.Done()
+		return mcp.NewToolResultError("operation cancelled"), nil
+	})
+	res, err := slow(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.True(t, res.IsError)
+	assert.Equal(t, "slow_tool timed out after 20ms", res.Content[0].(mcp.TextContent).Text)
+
+	// Other tools get the default timeout
+	var deadline time.Time
+	other := fsHandler.LimitToolCall("read_file", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		deadline, _ = ctx.Deadline()
+		return mcp.NewToolResultText("ok"), nil
+	})
+	res, err = other(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.False(t, res.IsError)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), deadline, time.Minute)
+}
+
+func TestLimitToolCallConcurrency(t *testing.T) {
+	fsHandler, err := NewFilesystemHandler(resolveAllowedDirs(t, t.TempDir()), WithTuning(Tuning{MaxConcurrentCalls: 2}))
+	require.NoError(t, err)
+
+	var running, peak atomic.Int32
+	release := make(chan struct{})
+	call := fsHandler.LimitToolCall("read_file", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		<-release
+		running.Add(-1)
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	done := make(chan struct{})
+	for range 4 {
+		go func() {
+			call(context.Background(), mcp.CallToolRequest{})
+			done <- struct{}{}
+		}()
+	}
+	require.Eventually(t, func() bool { return running.Load() == 2 }, time.Second, 5*time.Millisecond)
+	close(release)
+	for range 4 {
+		<-done
+	}
+	assert.Equal(t, int32(2), peak.Load())
+
+	// A call whose context ends while waiting gives up
+	fsHandler.slots <- struct{}{}
+	fsHandler.slots <- struct{}{}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	res, err := call(ctx, mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.True(t, res.IsError)
+}
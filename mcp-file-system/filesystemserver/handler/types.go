@@ -11,8 +11,65 @@ const (
 	MAX_SEARCH_RESULTS = 1000
 	// Maximum file size in bytes to search within (10MB)
 	MAX_SEARCHABLE_SIZE = 10 * 1024 * 1024
+	// Maximum size of a tool response before it is paged (8MB)
+	MAX_RESPONSE_SIZE = 8 * 1024 * 1024
+	// Maximum total size of the files read by one read_multiple_files call (8MB)
+	MAX_BATCH_READ_SIZE = 8 * 1024 * 1024
 )
 
+// Limits bounds how much data tools return or scan. Zero fields keep the
+// MAX_* defaults.
+type Limits struct {
+	// MaxInlineSize is the largest file returned inline by read tools
+	MaxInlineSize int64 `json:"max_inline_size,omitempty" yaml:"max_inline_size,omitempty"`
+	// MaxBase64Size is the largest binary file returned base64 encoded
+	MaxBase64Size int64 `json:"max_base64_size,omitempty" yaml:"max_base64_size,omitempty"`
+	// MaxSearchResults is the default result cap for search_within_files
+	MaxSearchResults int `json:"max_search_results,omitempty" yaml:"max_search_results,omitempty"`
+	// MaxSearchableSize is the largest file search_within_files will scan
+	MaxSearchableSize int64 `json:"max_searchable_size,omitempty" yaml:"max_searchable_size,omitempty"`
+	// MaxResponseSize is the largest tool response; longer ones are paged with continue_result
+	MaxResponseSize int64 `json:"max_response_size,omitempty" yaml:"max_response_size,omitempty"`
+	// MaxBatchReadSize is the total read_multiple_files returns in one call
+	MaxBatchReadSize int64 `json:"max_batch_read_size,omitempty" yaml:"max_batch_read_size,omitempty"`
+}
+
+// defaultLimits returns the built-in limits
+func defaultLimits() Limits {
+	return Limits{
+		MaxInlineSize:     MAX_INLINE_SIZE,
+		MaxBase64Size:     MAX_BASE64_SIZE,
+		MaxSearchResults:  MAX_SEARCH_RESULTS,
+		MaxSearchableSize: MAX_SEARCHABLE_SIZE,
+		MaxResponseSize:   MAX_RESPONSE_SIZE,
+		MaxBatchReadSize:  MAX_BATCH_READ_SIZE,
+	}
+}
+
+// WithLimits overrides the default size and result limits
+func WithLimits(limits Limits) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		if limits.MaxInlineSize > 0 {
+			fs.limits.MaxInlineSize = limits.MaxInlineSize
+		}
+		if limits.MaxBase64Size > 0 {
+			fs.limits.MaxBase64Size = limits.MaxBase64Size
+		}
+		if limits.MaxSearchResults > 0 {
+			fs.limits.MaxSearchResults = limits.MaxSearchResults
+		}
+		if limits.MaxSearchableSize > 0 {
+			fs.limits.MaxSearchableSize = limits.MaxSearchableSize
+		}
+		if limits.MaxResponseSize > 0 {
+			fs.limits.MaxResponseSize = limits.MaxResponseSize
+		}
+		if limits.MaxBatchReadSize > 0 {
+			fs.limits.MaxBatchReadSize = limits.MaxBatchReadSize
+		}
+	}
+}
+
 type FileInfo struct {
 	Size        int64     `json:"size"`
 	Created     time.Time `json:"created"`
@@ -25,11 +82,14 @@ type FileInfo struct {
 
 // FileNode represents a node in the file tree
 type FileNode struct {
-	Name     string      `json:"name"`
-	Path     string      `json:"path"`
-	Type     string      `json:"type"` // "file" or "directory"
-	Size     int64       `json:"size,omitempty"`
-	Modified time.Time   `json:"modified,omitempty"`
+	Name         string      `json:"name"`
+	Path         string      `json:"path"`
+	Type         string      `json:"type"` // "file" or "directory"
+	Size         int64       `json:"size,omitempty"`
+	Modified     string      `json:"modified,omitempty"`
+	ModifiedUnix int64       `json:"modified_unix,omitempty"`
+	// Rollup totals the files below a directory, when asked for
+	Rollup   *TreeRollup `json:"rollup,omitempty"`
 	Children []*FileNode `json:"children,omitempty"`
 }
 
@@ -39,4 +99,141 @@ type SearchResult struct {
 	LineNumber  int
 	LineContent string
 	ResourceURI string
+	// Offset is the byte offset of the match within LineContent
+	Offset int
+	// Before and After are the lines around the match asked for with
+	// context_before and context_after, long ones shortened
+	Before, After []string
+}
+
+// The types below are the stable schema of tool results in JSON mode (see
+// FormatJSON). Lists are always present, empty rather than null.
+
+// DirectoryEntry is a file or directory listed by list_directory or found
+// by search_files
+type DirectoryEntry struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Type string `json:"type"` // "file" or "directory"
+	Size int64  `json:"size"`
+	// Mode holds the permission bits in octal, such as "644"
+	Mode string `json:"mode,omitempty"`
+	URI  string `json:"uri,omitempty"`
+	FileTimes
+}
+
+// DirectoryListing is the JSON result of list_directory
+type DirectoryListing struct {
+	Path    string           `json:"path"`
+	Entries []DirectoryEntry `json:"entries"`
+	// Total counts the entries that matched, before limit was applied
+	Total int `json:"total"`
+	// NextCursor is passed as cursor to list the next page, if any
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// FileDetails is the JSON result of get_file_info
+type FileDetails struct {
+	Path        string `json:"path"`
+	Type        string `json:"type"` // "file" or "directory"
+	Size        int64  `json:"size"`
+	Permissions string `json:"permissions"`
+	MIMEType    string `json:"mime_type"`
+	URI         string `json:"uri,omitempty"`
+	// SymlinkTarget is what the requested path links to, if it is a symlink
+	SymlinkTarget string `json:"symlink_target,omitempty"`
+	// ResolvedTarget is the absolute path a symlink finally leads to
+	ResolvedTarget string `json:"resolved_target,omitempty"`
+	// TargetOutsideAllowed is set for a symlink leading out of the allowed
+	// directories; it is described without following it
+	TargetOutsideAllowed bool `json:"target_outside_allowed,omitempty"`
+	// TargetMissing is set for a symlink whose target does not exist
+	TargetMissing bool `json:"target_missing,omitempty"`
+	FileTimes
+	// ContentDetails are set for local files
+	*ContentDetails
+}
+
+// SearchFilesResult is the JSON result of search_files
+type SearchFilesResult struct {
+	Path    string           `json:"path"`
+	Pattern string           `json:"pattern"`
+	Mode    string           `json:"mode"`
+	Results []DirectoryEntry `json:"results"`
+}
+
+// ContentMatch is a match found by search_within_files
+type ContentMatch struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	// Column is the 1-based position of the match in the line, in characters
+	Column int    `json:"column"`
+	Match  string `json:"match"`
+	// Snippet is the line around the match, shortened for long lines
+	Snippet string `json:"snippet"`
+	// Truncated is set when Snippet is only part of the line
+	Truncated bool   `json:"truncated"`
+	URI       string `json:"uri"`
+	// Before and After are the lines around the match, nearest last and
+	// first, when context_before or context_after is given
+	Before []string `json:"before,omitempty"`
+	After  []string `json:"after,omitempty"`
+}
+
+// SearchWithinFilesResult is the JSON result of search_within_files
+type SearchWithinFilesResult struct {
+	Path      string         `json:"path"`
+	Substring string         `json:"substring"`
+	Matches   []ContentMatch `json:"matches"`
+	// Truncated is set when max_results stopped the search early
+	Truncated bool `json:"truncated"`
+}
+
+// AllowedDirectory is an entry of list_allowed_directories
+type AllowedDirectory struct {
+	Path string `json:"path"`
+	Type string `json:"type"` // "local" or "smb"
+	URI  string `json:"uri,omitempty"`
+	// Access is "rw" when tools may modify the directory and "ro" otherwise
+	Access string `json:"access"`
+	// Status is one of the DirStatus values; SMB shares are not checked
+	Status string `json:"status,omitempty"`
+	// Error explains a status other than DirStatusOK
+	Error string `json:"error,omitempty"`
+	// Total and Available are the size of the volume and the bytes free to the server
+	Total     uint64 `json:"total_bytes,omitempty"`
+	Available uint64 `json:"available_bytes,omitempty"`
+	// Aliases are the "@name" aliases pointing into the directory
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// AllowedDirectories is the JSON result of list_allowed_directories
+type AllowedDirectories struct {
+	Directories []AllowedDirectory `json:"directories"`
+	// BaseDirectory is where relative paths resolve, if set
+	BaseDirectory string `json:"base_directory,omitempty"`
+	// Aliases maps "@name" directory aliases to their directories
+	Aliases map[string]string `json:"aliases,omitempty"`
+}
+
+// CrocTransfer is an entry of croc_status
+type CrocTransfer struct {
+	PID             int       `json:"pid"`
+	Status          string    `json:"status"`
+	Direction       string    `json:"direction,omitempty"`
+	Path            string    `json:"path"`
+	Code            string    `json:"code,omitempty"`
+	StartedAt       time.Time `json:"started_at"`
+	DurationSeconds int64     `json:"duration_seconds"`
+	Note            string    `json:"note,omitempty"`
+}
+
+// CrocStatus is the JSON result of croc_status
+type CrocStatus struct {
+	Transfers []CrocTransfer `json:"transfers"`
+}
+
+// TrashListing is the JSON result of list_trash
+type TrashListing struct {
+	Entries []TrashEntry `json:"entries"`
 }
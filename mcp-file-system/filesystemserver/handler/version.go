@@ -0,0 +1,17 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-filesystem-server/buildinfo"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// HandleVersion returns the server build information as JSON, so clients
+// can check which features the server supports
+func (fs *FilesystemHandler) HandleVersion(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	return jsonResult(ctx, buildinfo.Get())
+}
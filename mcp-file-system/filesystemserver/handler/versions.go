@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// versionsDirName is the managed directory, inside each allowed directory,
+// holding earlier versions of overwritten files
+const versionsDirName = ".mcp-versions"
+
+// versionIDLayout names saved versions by the time they were replaced, so
+// they sort oldest first
+const versionIDLayout = "20060102T150405.000000000Z"
+
+// FileVersion is an earlier version of a file
+type FileVersion struct {
+	ID string `json:"id"`
+	// Saved is when the version was replaced; Modified is when it was written
+	Saved        string `json:"saved"`
+	Modified     string `json:"modified"`
+	ModifiedUnix int64  `json:"modified_unix"`
+	Size         int64  `json:"size"`
+}
+
+// VersionListing is the JSON result of list_versions
+type VersionListing struct {
+	Path     string        `json:"path"`
+	Versions []FileVersion `json:"versions"`
+}
+
+// WithVersioning keeps the last keep versions of every file overwritten by
+// write_file or modify_file in a .mcp-versions directory inside its allowed
+// directory. Zero, the default, disables versioning.
+func WithVersioning(keep int) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		fs.keepVersions = keep
+	}
+}
+
+// versionsDirFor returns the directory holding the versions of path
+func (fs *FilesystemHandler) versionsDirFor(path string) (string, error) {
+	root := fs.allowedRootOf(path)
+	if root == "" {
+		return "", fmt.Errorf("no allowed directory contains %s", path)
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, versionsDirName, rel), nil
+}
+
+// isInVersions reports whether path lies in a managed versions directory
+func (fs *FilesystemHandler) isInVersions(path string) bool {
+	for _, dir := range fs.dirs() {
+		if pathWithin(path, filepath.Join(dir, versionsDirName)) {
+			return true
+		}
+	}
+	return false
+}
+
+// saveVersion copies the file at path aside before it is overwritten, and
+// drops its oldest versions beyond the configured number. It does nothing
+// when versioning is off or path is not an existing regular file.
+func (fs *FilesystemHandler) saveVersion(path string) error {
+	if fs.keepVersions <= 0 || fs.isInVersions(path) || fs.isInTrash(path) {
+		return nil
+	}
+	info, err := os.Lstat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return nil
+	}
+	dir, err := fs.versionsDirFor(path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create versions directory: %w", err)
+	}
+	saved := filepath.Join(dir, time.Now().UTC().Format(versionIDLayout))
+	if err := copyFile(path, saved); err != nil {
+		os.Remove(saved)
+		return err
+	}
+	// The saved copy keeps the modification time of the version it holds
+	if err := os.Chtimes(saved, info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+
+	ids, err := versionIDs(dir)
+	if err != nil {
+		return err
+	}
+	for len(ids) > fs.keepVersions {
+		os.Remove(filepath.Join(dir, ids[0]))
+		ids = ids[1:]
+	}
+	return nil
+}
+
+// versionIDs returns the IDs of the versions saved in dir, oldest first
+func versionIDs(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, entry := range entries {
+		if _, err := time.Parse(versionIDLayout, entry.Name()); err == nil && entry.Type().IsRegular() {
+			ids = append(ids, entry.Name())
+		}
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// HandleListVersions handles the list_versions tool
+func (fs *FilesystemHandler) HandleListVersions(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	dir, err := fs.versionsDirFor(validPath)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	ids, err := versionIDs(dir)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error listing versions: %w", err)), nil
+	}
+
+	listing := VersionListing{Path: validPath, Versions: []FileVersion{}}
+	for i := len(ids) - 1; i >= 0; i-- {
+		info, err := os.Stat(filepath.Join(dir, ids[i]))
+		if err != nil {
+			continue
+		}
+		saved, _ := time.Parse(versionIDLayout, ids[i])
+		listing.Versions = append(listing.Versions, FileVersion{
+			ID:           ids[i],
+			Saved:        formatTime(saved),
+			Modified:     formatTime(info.ModTime()),
+			ModifiedUnix: unixTime(info.ModTime()),
+			Size:         info.Size(),
+		})
+	}
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, listing)
+	}
+	if len(listing.Versions) == 0 {
+		text := fmt.Sprintf("No earlier versions of %s", validPath)
+		if fs.keepVersions <= 0 {
+			text += "; versioning is not enabled on this server"
+		}
+		return mcp.NewToolResultText(text), nil
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d earlier version(s) of %s, newest first:\n", len(listing.Versions), validPath))
+	for _, v := range listing.Versions {
+		sb.WriteString(fmt.Sprintf("  %s  replaced %s, modified %s, %s\n", v.ID, v.Saved, v.Modified, formatFileSize(v.Size)))
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// HandleRestoreVersion handles the restore_version tool
+func (fs *FilesystemHandler) HandleRestoreVersion(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	id, err := request.RequireString("id")
+	if err != nil {
+		return nil, err
+	}
+	validPath, err := fs.validateWritablePath(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if info, err := os.Stat(validPath); err == nil && info.IsDir() {
+		return errorResult(toolError(CodeWrongType, "Error: %s is a directory", path).With("path", path)), nil
+	}
+	dir, err := fs.versionsDirFor(validPath)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	ids, err := versionIDs(dir)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error listing versions: %w", err)), nil
+	}
+	if !slices.Contains(ids, id) {
+		return errorResult(toolError(CodeNotFound, "Error: %s has no version %q; call list_versions for its versions", path, id).With("path", path).With("id", id)), nil
+	}
+	content, err := os.ReadFile(filepath.Join(dir, id))
+	if err != nil {
+		return errorResult(fmt.Errorf("Error reading version: %w", err)), nil
+	}
+
+	if fs.dryRunMode() {
+		return dryRunResult(describeWrite(validPath, content)...), nil
+	}
+	// The current content becomes a version itself, so a restore can be undone
+	written, err := fs.writeContent(validPath, content, false)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error restoring version: %w", err)), nil
+	}
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, written)
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Restored %s to version %s (%d bytes)\n%s", validPath, id, written.Size, describeWriteResult(written))), nil
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersioning(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	fsHandler, err := NewFilesystemHandler([]string{dir}, WithVersioning(2))
+	require.NoError(t, err)
+	path := filepath.Join(dir, "notes", "todo.md")
+
+	res := callTool(t, fsHandler.HandleWriteFile, map[string]any{"path": path, "content": "v1", "create_parents": true})
+	require.False(t, res.IsError, res.Content)
+	for _, content := range []string{"v2", "v3", "v4"} {
+		res = callTool(t, fsHandler.HandleWriteFile, map[string]any{"path": path, "content": content})
+		require.False(t, res.IsError, res.Content)
+	}
+	res = callTool(t, fsHandler.HandleModifyFile, map[string]any{"path": path, "find": "v4", "replace": "v5"})
+	require.False(t, res.IsError, res.Content)
+
+	// Only the newest two versions are kept, mirroring the file's path
+	ids, err := versionIDs(filepath.Join(dir, versionsDirName, "notes", "todo.md"))
+	require.NoError(t, err)
+	require.Len(t, ids, 2)
+
+	res = callTool(t, fsHandler.FormatToolCall(fsHandler.HandleListVersions), map[string]any{"path": path, "format": FormatJSON})
+	require.False(t, res.IsError, res.Content)
+	var listing VersionListing
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &listing))
+	require.Len(t, listing.Versions, 2)
+	assert.Equal(t, ids[1], listing.Versions[0].ID)
+	assert.Equal(t, int64(2), listing.Versions[0].Size)
+
+	res = callTool(t, fsHandler.HandleRestoreVersion, map[string]any{"path": path, "id": ids[0]})
+	require.False(t, res.IsError, res.Content)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "v3", string(content))
+
+	// The restore saved the replaced content as the newest version
+	ids, err = versionIDs(filepath.Join(dir, versionsDirName, "notes", "todo.md"))
+	require.NoError(t, err)
+	saved, err := os.ReadFile(filepath.Join(dir, versionsDirName, "notes", "todo.md", ids[len(ids)-1]))
+	require.NoError(t, err)
+	assert.Equal(t, "v5", string(saved))
+
+	res = callTool(t, fsHandler.HandleRestoreVersion, map[string]any{"path": path, "id": "20000101T000000.000000000Z"})
+	assert.Equal(t, CodeNotFound, ResultError(res).Code)
+}
+
+func TestVersioningOff(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	path := filepath.Join(dir, "a.txt")
+	for _, content := range []string{"one", "two"} {
+		res := callTool(t, fsHandler.HandleWriteFile, map[string]any{"path": path, "content": content})
+		require.False(t, res.IsError, res.Content)
+	}
+	assert.NoDirExists(t, filepath.Join(dir, versionsDirName))
+	res := callTool(t, fsHandler.HandleListVersions, map[string]any{"path": path})
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "versioning is not enabled")
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"path/filepath"
+
+	"github.com/gobwas/glob"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// walkFilter holds what a directory walk leaves out: entries matching an
+// exclude pattern, files matching no include pattern when there are any
+// and, with respect_gitignore, what .gitignore files exclude. A skipped
+// directory is not walked into. A nil *walkFilter skips nothing, so walks
+// can take one unconditionally.
+type walkFilter struct {
+	root             string
+	include, exclude []glob.Glob
+	ignore           *gitIgnore
+}
+
+// newWalkFilter reads the include, exclude and respect_gitignore arguments
+// of a walk starting at root. include and exclude are lists of globs, or
+// single ones, matched against both entry names and paths relative to root.
+func (fs *FilesystemHandler) newWalkFilter(request mcp.CallToolRequest, root string) (*walkFilter, error) {
+	filter := &walkFilter{root: root}
+	var err error
+	if filter.include, err = compileGlobs(request, "include"); err != nil {
+		return nil, err
+	}
+	if filter.exclude, err = compileGlobs(request, "exclude"); err != nil {
+		return nil, err
+	}
+	if request.GetBool("respect_gitignore", false) {
+		filter.ignore = fs.newGitIgnore(root)
+	}
+	if len(filter.include) == 0 && len(filter.exclude) == 0 && filter.ignore == nil {
+		return nil, nil
+	}
+	return filter, nil
+}
+
+// compileGlobs compiles the globs of a list argument, or of a single one
+func compileGlobs(request mcp.CallToolRequest, key string) ([]glob.Glob, error) {
+	patterns := request.GetStringSlice(key, nil)
+	if pattern := request.GetString(key, ""); pattern != "" {
+		patterns = []string{pattern}
+	}
+	var globs []glob.Glob
+	for _, pattern := range patterns {
+		g, err := glob.Compile(pattern, '/')
+		if err != nil {
+			return nil, toolError(CodeInvalidArgument, "Error: invalid %s glob %q: %w", key, pattern, err).With(key, pattern)
+		}
+		globs = append(globs, g)
+	}
+	return globs, nil
+}
+
+// skip reports whether the walk leaves out path. The root never is, and
+// include only ever leaves out files. A directory is also matched with a
+// trailing slash, so an exclude pattern like vendor/** skips it whole.
+func (f *walkFilter) skip(path string, isDir bool) bool {
+	if f == nil || path == f.root {
+		return false
+	}
+	if len(f.include) > 0 || len(f.exclude) > 0 {
+		name := filepath.Base(path)
+		rel, err := filepath.Rel(f.root, path)
+		if err != nil {
+			rel = name
+		}
+		rel = filepath.ToSlash(rel)
+		for _, g := range f.exclude {
+			if g.Match(name) || g.Match(rel) || isDir && g.Match(rel+"/") {
+				return true
+			}
+		}
+		if !isDir && len(f.include) > 0 && !matchesAny(f.include, name, rel) {
+			return true
+		}
+	}
+	return f.ignore.ignored(path, isDir)
+}
+
+// matchesAny reports whether any of globs matches one of subjects
+func matchesAny(globs []glob.Glob, subjects ...string) bool {
+	for _, g := range globs {
+		for _, subject := range subjects {
+			if g.Match(subject) {
+				return true
+			}
+		}
+	}
+	return false
+}
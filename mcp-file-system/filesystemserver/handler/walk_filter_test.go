@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalkFilterExclude(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	for _, name := range []string{"src/app.js", "src/app.min.js", "dist/app.js", "lib/dist/x.js", ".git/config.js"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, nil, 0644))
+	}
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	search := func(handler func(args map[string]any) *mcp.CallToolResult, args map[string]any) []string {
+		res := handler(args)
+		require.False(t, res.IsError, res.Content)
+		var found struct{ Results []DirectoryEntry }
+		require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &found))
+		var paths []string
+		for _, entry := range found.Results {
+			rel, err := filepath.Rel(dir, entry.Path)
+			require.NoError(t, err)
+			paths = append(paths, filepath.ToSlash(rel))
+		}
+		return paths
+	}
+	searchFiles := func(args map[string]any) *mcp.CallToolResult {
+		args["path"], args["pattern"], args["format"] = dir, "*.js", FormatJSON
+		return callTool(t, fsHandler.FormatToolCall(fsHandler.HandleSearchFiles), args)
+	}
+	findFiles := func(args map[string]any) *mcp.CallToolResult {
+		args["path"], args["type"], args["format"] = dir, FindTypeFile, FormatJSON
+		return callTool(t, fsHandler.FormatToolCall(fsHandler.HandleFindFiles), args)
+	}
+
+	exclude := []any{".git", "dist", "**/*.min.js"}
+	assert.Equal(t, []string{"src/app.js"}, search(searchFiles, map[string]any{"exclude": exclude}))
+	assert.Equal(t, []string{"src/app.js"}, search(findFiles, map[string]any{"exclude": exclude}))
+	// A single pattern may be given as a string, and relative paths match
+	assert.Equal(t, []string{".git/config.js", "dist/app.js", "src/app.js", "src/app.min.js"}, search(findFiles, map[string]any{"exclude": "lib/*"}))
+
+	res := callTool(t, fsHandler.HandleTree, map[string]any{"path": dir, "exclude": exclude})
+	text := res.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "app.js")
+	assert.NotContains(t, text, "dist")
+	assert.NotContains(t, text, "min.js")
+
+	res = callTool(t, fsHandler.HandleSearchFiles, map[string]any{"path": dir, "pattern": "*", "exclude": []any{"[bad"}})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+}
+
+func TestWalkFilterInclude(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	for _, name := range []string{"main.go", "README.md", "cmd/tool/run.go", "vendor/lib/lib.go", "web/app.ts"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("needle\n"), 0644))
+	}
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	search := func(args map[string]any) []string {
+		args["path"], args["substring"], args["format"] = dir, "needle", FormatJSON
+		res := callTool(t, fsHandler.FormatToolCall(fsHandler.HandleSearchWithinFiles), args)
+		require.False(t, res.IsError, res.Content)
+		var found SearchWithinFilesResult
+		require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &found))
+		var paths []string
+		for _, match := range found.Matches {
+			rel, err := filepath.Rel(dir, match.Path)
+			require.NoError(t, err)
+			paths = append(paths, filepath.ToSlash(rel))
+		}
+		return paths
+	}
+
+	// include only selects files, so directories are still searched
+	assert.Equal(t, []string{"cmd/tool/run.go", "main.go", "vendor/lib/lib.go"}, search(map[string]any{"include": []any{"*.go"}}))
+	assert.Equal(t, []string{"cmd/tool/run.go", "main.go"}, search(map[string]any{"include": []any{"*.go"}, "exclude": []any{"vendor/**"}}))
+	assert.Equal(t, []string{"cmd/tool/run.go", "web/app.ts"}, search(map[string]any{"include": []any{"cmd/**", "*.ts"}}))
+
+	// A directory matching an exclude pattern with a trailing /** is not entered
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]any{"exclude": "vendor/**"}}}
+	filter, err := fsHandler.newWalkFilter(request, dir)
+	require.NoError(t, err)
+	assert.True(t, filter.skip(filepath.Join(dir, "vendor"), true))
+	assert.False(t, filter.skip(filepath.Join(dir, "vendor"), false))
+
+	res := callTool(t, fsHandler.HandleSearchWithinFiles, map[string]any{"path": dir, "substring": "needle", "include": []any{"[bad"}})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+}
@@ -0,0 +1,315 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	// webhookURLEnv configures a single webhook endpoint without code changes
+	webhookURLEnv = "MCP_FS_WEBHOOK_URL"
+	// webhookSecretEnv is the HMAC key for the webhook configured via MCP_FS_WEBHOOK_URL
+	webhookSecretEnv = "MCP_FS_WEBHOOK_SECRET"
+	// watchPathsEnv lists paths (separated like PATH) whose changes are reported as events
+	watchPathsEnv = "MCP_FS_WATCH_PATHS"
+
+	// webhookAttempts is how often delivery is tried before giving up
+	webhookAttempts = 3
+	// webhookRetryBackoff is multiplied by the attempt number between retries
+	webhookRetryBackoff = time.Second
+	// webhookTimeout bounds a single delivery attempt
+	webhookTimeout = 10 * time.Second
+	// watchDebounce coalesces bursts of filesystem notifications for the same path
+	watchDebounce = 500 * time.Millisecond
+
+	webhookEventHeader     = "X-MCP-FS-Event"
+	webhookSignatureHeader = "X-MCP-FS-Signature"
+	webhookDeliveryHeader  = "X-MCP-FS-Delivery"
+)
+
+// Event types emitted to webhooks and notifiers
+const (
+	EventFileChanged     = "file.changed"
+	EventCrocCompleted   = "croc.transfer.completed"
+	EventCrocFailed      = "croc.transfer.failed"
+	EventQuotaExceeded   = "quota.exceeded"
+	EventBackupCompleted = "backup.completed"
+	EventBackupFailed    = "backup.failed"
+)
+
+// Event is the JSON payload POSTed to webhooks
+type Event struct {
+	ID   string         `json:"id"`
+	Type string         `json:"type"`
+	Time time.Time      `json:"time"`
+	Path string         `json:"path,omitempty"`
+	Data map[string]any `json:"data,omitempty"`
+}
+
+// WebhookConfig is a webhook endpoint and the events it subscribes to
+type WebhookConfig struct {
+	URL string `json:"url" yaml:"url"`
+	// Secret signs each body with HMAC-SHA256, sent as "sha256=<hex>" in X-MCP-FS-Signature
+	Secret string `json:"-" yaml:"secret,omitempty"`
+	// Events filters by type; entries may end in "*" to match a prefix. Empty means all events.
+	Events []string `json:"events,omitempty" yaml:"events,omitempty"`
+}
+
+// WithWebhooks registers webhook endpoints that receive filesystem and transfer events
+func WithWebhooks(hooks []WebhookConfig) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		fs.webhooks = append(fs.webhooks, hooks...)
+	}
+}
+
+// WithWatchPaths sets the paths whose changes are reported as file.changed events
+func WithWatchPaths(paths []string) HandlerOption {
+	return func(fs *FilesystemHandler) {
+		fs.watchPaths = paths
+	}
+}
+
+// webhooksFromEnv reads the webhook configured via MCP_FS_WEBHOOK_URL, if any
+func webhooksFromEnv() []WebhookConfig {
+	url := os.Getenv(webhookURLEnv)
+	if url == "" {
+		return nil
+	}
+	return []WebhookConfig{{URL: url, Secret: os.Getenv(webhookSecretEnv)}}
+}
+
+// watchPathsFromEnv reads MCP_FS_WATCH_PATHS
+func watchPathsFromEnv() []string {
+	raw := os.Getenv(watchPathsEnv)
+	if raw == "" {
+		return nil
+	}
+	return filepath.SplitList(raw)
+}
+
+// wants reports whether the hook subscribes to an event type
+func (w WebhookConfig) wants(eventType string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == eventType || (strings.HasSuffix(e, "*") && strings.HasPrefix(eventType, strings.TrimSuffix(e, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookClient delivers webhook requests; replaceable in tests
+var webhookClient = &http.Client{Timeout: webhookTimeout}
+
+// emitEvent delivers an event to every subscribed webhook and notifier in the background
+func (fs *FilesystemHandler) emitEvent(eventType, path string, data map[string]any) {
+	if len(fs.webhooks) == 0 && len(fs.notifiers) == 0 {
+		return
+	}
+	id := make([]byte, 8)
+	rand.Read(id)
+	ev := Event{
+		ID:   hex.EncodeToString(id),
+		Type: eventType,
+		Time: time.Now().UTC(),
+		Path: path,
+		Data: data,
+	}
+	body, err := json.Marshal(ev)
+	if err != nil {
+		fs.logger.Error("encoding event failed", "event", eventType, "error", err)
+		return
+	}
+	for _, hook := range fs.webhooks {
+		if !hook.wants(eventType) {
+			continue
+		}
+		fs.eventWG.Add(1)
+		go func(hook WebhookConfig) {
+			defer fs.eventWG.Done()
+			if err := deliverWebhook(hook, ev, body); err != nil {
+				fs.logger.Error("webhook delivery failed", "event", ev.Type, "url", hook.URL, "error", err)
+			}
+		}(hook)
+	}
+	fs.notify(ev)
+}
+
+// deliverWebhook POSTs the signed body, retrying on network errors and 5xx responses
+func deliverWebhook(hook WebhookConfig, ev Event, body []byte) error {
+	var lastErr error
+	for attempt := 0; attempt < webhookAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(webhookRetryBackoff * time.Duration(attempt))
+		}
+		req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "mcp-filesystem-server/webhook")
+		req.Header.Set(webhookEventHeader, ev.Type)
+		req.Header.Set(webhookDeliveryHeader, ev.ID)
+		if hook.Secret != "" {
+			req.Header.Set(webhookSignatureHeader, signWebhook(hook.Secret, body))
+		}
+
+		resp, err := webhookClient.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 300 {
+			return nil
+		}
+		lastErr = fmt.Errorf("unexpected status %s", resp.Status)
+		if resp.StatusCode < 500 {
+			return lastErr // Client errors will not succeed on retry
+		}
+	}
+	return lastErr
+}
+
+// signWebhook returns the X-MCP-FS-Signature value for body
+func signWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// isQuotaError reports whether err means the disk or the user's quota is full
+func isQuotaError(err error) bool {
+	return errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EDQUOT)
+}
+
+// reportQuotaError emits quota.exceeded when err is caused by a full disk or quota
+func (fs *FilesystemHandler) reportQuotaError(path string, err error) {
+	if err != nil && isQuotaError(err) {
+		fs.emitEvent(EventQuotaExceeded, path, map[string]any{"error": err.Error()})
+	}
+}
+
+// StartFileWatcher reports changes beneath the configured watch paths as
+// file.changed events until ctx is cancelled
+func (fs *FilesystemHandler) StartFileWatcher(ctx context.Context) error {
+	if len(fs.watchPaths) == 0 {
+		return nil
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to start file watcher: %w", err)
+	}
+	for _, p := range fs.watchPaths {
+		validPath, err := fs.validatePath(p)
+		if err != nil {
+			watcher.Close()
+			return fmt.Errorf("watch path %s: %w", p, err)
+		}
+		if err := addWatchTree(watcher, validPath); err != nil {
+			watcher.Close()
+			return fmt.Errorf("watch path %s: %w", p, err)
+		}
+	}
+
+	ctx, cancel := fs.untilStopped(ctx)
+	fs.background.Add(1)
+	go func() {
+		defer fs.background.Done()
+		defer cancel()
+		defer watcher.Close()
+		var mu sync.Mutex
+		pending := make(map[string]fsnotify.Op)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				fs.logger.Warn("file watcher error", "error", err)
+			case ev, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				// Newly created directories need watches of their own
+				if ev.Has(fsnotify.Create) {
+					if info, err := os.Stat(ev.Name); err == nil && info.IsDir() && !fs.isInTrash(ev.Name) && !fs.isInVersions(ev.Name) {
+						addWatchTree(watcher, ev.Name)
+					}
+				}
+				mu.Lock()
+				op, queued := pending[ev.Name]
+				pending[ev.Name] = op | ev.Op
+				mu.Unlock()
+				if queued {
+					continue
+				}
+				name := ev.Name
+				time.AfterFunc(watchDebounce, func() {
+					mu.Lock()
+					op := pending[name]
+					delete(pending, name)
+					mu.Unlock()
+					fs.emitEvent(EventFileChanged, name, map[string]any{"ops": watchOpNames(op)})
+				})
+			}
+		}
+	}()
+	return nil
+}
+
+// addWatchTree watches root and every directory beneath it
+func addWatchTree(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip unreadable entries rather than failing the whole watch
+		}
+		if d.IsDir() {
+			if d.Name() == trashDirName || d.Name() == versionsDirName {
+				return filepath.SkipDir
+			}
+			return watcher.Add(path)
+		}
+		return nil
+	})
+}
+
+// watchOpNames lists the operations in op as lowercase names
+func watchOpNames(op fsnotify.Op) []string {
+	names := []string{}
+	for _, o := range []struct {
+		op   fsnotify.Op
+		name string
+	}{
+		{fsnotify.Create, "create"},
+		{fsnotify.Write, "write"},
+		{fsnotify.Remove, "remove"},
+		{fsnotify.Rename, "rename"},
+		{fsnotify.Chmod, "chmod"},
+	} {
+		if op.Has(o.op) {
+			names = append(names, o.name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookRecorder collects events delivered to a test endpoint
+type webhookRecorder struct {
+	mu     sync.Mutex
+	events []Event
+	sigs   []string
+}
+
+func newWebhookRecorder(t *testing.T) (*webhookRecorder, string) {
+	t.Helper()
+	rec := &webhookRecorder{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var ev Event
+		if err := json.Unmarshal(body, &ev); err == nil {
+			rec.mu.Lock()
+			rec.events = append(rec.events, ev)
+			rec.sigs = append(rec.sigs, r.Header.Get(webhookSignatureHeader))
+			rec.mu.Unlock()
+		}
+		// The signature covers the exact body that was sent
+		if r.Header.Get(webhookSignatureHeader) != "" && r.Header.Get(webhookSignatureHeader) != signWebhook("s3cret", body) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return rec, ts.URL
+}
+
+func (r *webhookRecorder) snapshot() ([]Event, []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Event(nil), r.events...), append([]string(nil), r.sigs...)
+}
+
+func TestEmitEventDeliversSignedFilteredEvents(t *testing.T) {
+	rec, url := newWebhookRecorder(t)
+	fsHandler, err := NewFilesystemHandler(resolveAllowedDirs(t, t.TempDir()), WithWebhooks([]WebhookConfig{
+		{URL: url, Secret: "s3cret", Events: []string{"croc.*"}},
+	}))
+	require.NoError(t, err)
+
+	fsHandler.emitEvent(EventCrocCompleted, "/data/file.bin", map[string]any{"direction": "send"})
+	fsHandler.emitEvent(EventFileChanged, "/data/other.txt", nil)
+	fsHandler.eventWG.Wait()
+
+	events, sigs := rec.snapshot()
+	require.Len(t, events, 1)
+	assert.Equal(t, EventCrocCompleted, events[0].Type)
+	assert.Equal(t, "/data/file.bin", events[0].Path)
+	assert.Equal(t, "send", events[0].Data["direction"])
+	assert.Contains(t, sigs[0], "sha256=")
+}
+
+func TestFileWatcherEmitsChanges(t *testing.T) {
+	rec, url := newWebhookRecorder(t)
+	dir := t.TempDir()
+	resolved, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+
+	fsHandler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir),
+		WithWebhooks([]WebhookConfig{{URL: url}}),
+		WithWatchPaths([]string{resolved}),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, fsHandler.StartFileWatcher(ctx))
+
+	target := filepath.Join(resolved, "watched.txt")
+	require.NoError(t, os.WriteFile(target, []byte("hello"), 0644))
+
+	require.Eventually(t, func() bool {
+		events, _ := rec.snapshot()
+		for _, ev := range events {
+			if ev.Type == EventFileChanged && ev.Path == target {
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 50*time.Millisecond)
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// defaultStatsTop is how many largest files and deepest paths workspace_stats lists
+	defaultStatsTop = 10
+	// maxStatsTop caps the top argument of workspace_stats
+	maxStatsTop = 100
+	// noExtension groups files without an extension
+	noExtension = "(none)"
+)
+
+// WorkspaceStats is the JSON result of workspace_stats
+type WorkspaceStats struct {
+	Path        string           `json:"path"`
+	Files       int64            `json:"file_count"`
+	Directories int64            `json:"directory_count"`
+	TotalSize   int64            `json:"total_size"`
+	MaxDepth    int              `json:"max_depth"`
+	Extensions  []ExtensionStats `json:"extensions"`
+	Largest     []LargestFile    `json:"largest_files"`
+	Deepest     []DeepPath       `json:"deepest_paths"`
+}
+
+// ExtensionStats counts the files with one extension
+type ExtensionStats struct {
+	Extension string `json:"extension"`
+	Files     int64  `json:"file_count"`
+	TotalSize int64  `json:"total_size"`
+}
+
+// DeepPath is a file with the number of levels it lies below the root
+type DeepPath struct {
+	Path  string `json:"path"`
+	Depth int    `json:"depth"`
+}
+
+// HandleWorkspaceStats handles the workspace_stats tool
+func (fs *FilesystemHandler) HandleWorkspaceStats(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	top := request.GetInt("top", defaultStatsTop)
+	if top < 1 || top > maxStatsTop {
+		return errorResult(toolError(CodeInvalidArgument, "Error: top must be between 1 and %d", maxStatsTop).With("top", top)), nil
+	}
+	includeHidden := request.GetBool("include_hidden", false)
+
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if !info.IsDir() {
+		return errorResult(toolError(CodeWrongType, "Error: %s is not a directory", path).With("path", path)), nil
+	}
+
+	stats, err := fs.workspaceStats(ctx, validPath, top, includeHidden)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error collecting statistics: %w", err)), nil
+	}
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, stats)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Workspace statistics for %s:\n\n", stats.Path))
+	sb.WriteString(fmt.Sprintf("%d files, %d directories, %s in total, %d levels deep\n",
+		stats.Files, stats.Directories, formatFileSize(stats.TotalSize), stats.MaxDepth))
+	if len(stats.Extensions) > 0 {
+		sb.WriteString("\nBy extension:\n")
+		for _, ext := range stats.Extensions {
+			sb.WriteString(fmt.Sprintf("  %s: %d files, %s\n", ext.Extension, ext.Files, formatFileSize(ext.TotalSize)))
+		}
+	}
+	if len(stats.Largest) > 0 {
+		sb.WriteString("\nLargest files:\n")
+		for _, file := range stats.Largest {
+			sb.WriteString(fmt.Sprintf("  %s (%s)\n", file.Path, formatFileSize(file.Size)))
+		}
+	}
+	if len(stats.Deepest) > 0 {
+		sb.WriteString("\nDeepest paths:\n")
+		for _, deep := range stats.Deepest {
+			sb.WriteString(fmt.Sprintf("  %s (depth %d)\n", deep.Path, deep.Depth))
+		}
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// workspaceStats walks root without following symbolic links, skipping
+// entries whose names start with a dot unless includeHidden is set
+func (fs *FilesystemHandler) workspaceStats(ctx context.Context, root string, top int, includeHidden bool) (*WorkspaceStats, error) {
+	stats := &WorkspaceStats{Path: root, Extensions: []ExtensionStats{}, Largest: []LargestFile{}, Deepest: []DeepPath{}}
+	byExt := make(map[string]*ExtensionStats)
+	// The candidate lists are trimmed as they grow so memory stays bounded
+	trimLargest := func() {
+		sort.Slice(stats.Largest, func(i, j int) bool {
+			a, b := stats.Largest[i], stats.Largest[j]
+			return a.Size > b.Size || a.Size == b.Size && a.Path < b.Path
+		})
+		stats.Largest = stats.Largest[:min(len(stats.Largest), top)]
+	}
+	trimDeepest := func() {
+		sort.Slice(stats.Deepest, func(i, j int) bool {
+			a, b := stats.Deepest[i], stats.Deepest[j]
+			return a.Depth > b.Depth || a.Depth == b.Depth && a.Path < b.Path
+		})
+		stats.Deepest = stats.Deepest[:min(len(stats.Deepest), top)]
+	}
+
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil || path == root {
+			return nil
+		}
+		if !includeHidden && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !fs.isPathInAllowedDirs(path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		depth := strings.Count(rel, string(filepath.Separator)) + 1
+		stats.MaxDepth = max(stats.MaxDepth, depth)
+		if d.IsDir() {
+			stats.Directories++
+			return nil
+		}
+		// Directories are left out, as each lies on the way to a deeper file
+		stats.Deepest = append(stats.Deepest, DeepPath{Path: path, Depth: depth})
+		if len(stats.Deepest) > 4*top {
+			trimDeepest()
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		size := info.Size()
+		stats.Files++
+		stats.TotalSize += size
+		ext := strings.ToLower(filepath.Ext(d.Name()))
+		if ext == "" {
+			ext = noExtension
+		}
+		if byExt[ext] == nil {
+			byExt[ext] = &ExtensionStats{Extension: ext}
+		}
+		byExt[ext].Files++
+		byExt[ext].TotalSize += size
+		stats.Largest = append(stats.Largest, LargestFile{Path: path, Size: size})
+		if len(stats.Largest) > 4*top {
+			trimLargest()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	trimLargest()
+	trimDeepest()
+
+	for _, ext := range byExt {
+		stats.Extensions = append(stats.Extensions, *ext)
+	}
+	sort.Slice(stats.Extensions, func(i, j int) bool {
+		a, b := stats.Extensions[i], stats.Extensions[j]
+		return a.TotalSize > b.TotalSize || a.TotalSize == b.TotalSize && a.Extension < b.Extension
+	})
+	return stats, nil
+}
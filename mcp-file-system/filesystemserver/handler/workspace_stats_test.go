@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspaceStats(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	files := map[string]int{
+		"main.go":            100,
+		"util.GO":            50,
+		"Makefile":           10,
+		"docs/guide.md":      400,
+		"src/a/b/c/deep.go":  5,
+		".git/objects/pack1": 9000,
+		"assets/logo.png":    2000,
+		"assets/icons/x.png": 300,
+	}
+	for name, size := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, make([]byte, size), 0644))
+	}
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	stats := fsHandler.FormatToolCall(fsHandler.HandleWorkspaceStats)
+
+	res := callTool(t, stats, map[string]any{"path": dir, "top": 2, "format": FormatJSON})
+	require.False(t, res.IsError, res.Content)
+	var got WorkspaceStats
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &got))
+	assert.Equal(t, int64(7), got.Files)
+	assert.Equal(t, int64(2865), got.TotalSize)
+	assert.Equal(t, int64(7), got.Directories)
+	assert.Equal(t, 5, got.MaxDepth)
+	assert.Equal(t, []ExtensionStats{
+		{Extension: ".png", Files: 2, TotalSize: 2300},
+		{Extension: ".md", Files: 1, TotalSize: 400},
+		{Extension: ".go", Files: 3, TotalSize: 155},
+		{Extension: noExtension, Files: 1, TotalSize: 10},
+	}, got.Extensions)
+	assert.Equal(t, []LargestFile{
+		{Path: filepath.Join(dir, "assets", "logo.png"), Size: 2000},
+		{Path: filepath.Join(dir, "docs", "guide.md"), Size: 400},
+	}, got.Largest)
+	assert.Equal(t, []DeepPath{
+		{Path: filepath.Join(dir, "src", "a", "b", "c", "deep.go"), Depth: 5},
+		{Path: filepath.Join(dir, "assets", "icons", "x.png"), Depth: 3},
+	}, got.Deepest)
+
+	res = callTool(t, stats, map[string]any{"path": dir, "include_hidden": true})
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "8 files, 9 directories")
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Largest files:\n  "+filepath.Join(dir, ".git", "objects", "pack1"))
+
+	res = callTool(t, stats, map[string]any{"path": dir, "top": 0})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+}
@@ -17,93 +17,72 @@ func (fs *FilesystemHandler) HandleWriteFile(
 	if err != nil {
 		return nil, err
 	}
-	content, err := request.RequireString("content")
+	text, err := request.RequireString("content")
 	if err != nil {
 		return nil, err
 	}
+	// Binary content arrives base64 encoded
+	content, err := decodeContent(text, request.GetString("encoding", EncodingUTF8))
+	if err != nil {
+		return errorResult(err), nil
+	}
+
+	if isSMBPath(path) {
+		if request.GetBool("backup", false) {
+			return errorResult(toolError(CodeInvalidArgument, "Error: backup is not supported for SMB paths").With("path", path)), nil
+		}
+		return fs.smbWriteFile(ctx, path, content, request.GetBool("verify", false))
+	}
 
 	// Handle empty or relative paths like "." or "./" by converting to absolute path
 	if path == "." || path == "./" {
 		// Get current working directory
 		cwd, err := os.Getwd()
 		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					mcp.TextContent{
-						Type: "text",
-						Text: fmt.Sprintf("Error resolving current directory: %v", err),
-					},
-				},
-				IsError: true,
-			}, nil
+			return errorResult(fmt.Errorf("Error resolving current directory: %w", err)), nil
 		}
 		path = cwd
 	}
 
-	validPath, err := fs.validatePath(path)
+	createParents := request.GetBool("create_parents", false)
+	validPath, err := fs.validateCreatablePath(path, createParents)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
 	}
 
 	// Check if it's a directory
 	if info, err := os.Stat(validPath); err == nil && info.IsDir() {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: "Error: Cannot write to a directory",
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(toolError(CodeWrongType, "Error: Cannot write to a directory")), nil
+	}
+
+	if fs.dryRunMode() {
+		lines := describeWrite(validPath, content)
+		for _, dir := range missingParents(validPath) {
+			lines = append(lines, fmt.Sprintf("Would create directory %s", dir))
+		}
+		return dryRunResult(lines...), nil
 	}
 
 	// Create parent directories if they don't exist
 	parentDir := filepath.Dir(validPath)
 	if err := os.MkdirAll(parentDir, 0755); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error creating parent directories: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+		return errorResult(fmt.Errorf("Error creating parent directories: %w", err)), nil
 	}
 
-	if err := os.WriteFile(validPath, []byte(content), 0644); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error writing file: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
+	var backup string
+	if fs.wantsBackup(request) {
+		if backup, err = fs.backupFile(validPath); err != nil {
+			return errorResult(err), nil
+		}
 	}
-
-	// Get file info for the response
-	info, err := os.Stat(validPath)
+	written, err := fs.writeContent(validPath, content, request.GetBool("verify", false))
 	if err != nil {
-		// File was written but we couldn't get info
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Successfully wrote to %s", path),
-				},
-			},
-		}, nil
+		fs.reportQuotaError(validPath, err)
+		return errorResult(fmt.Errorf("Error writing file: %w", err)), nil
+	}
+	written.Backup = backup
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, written)
 	}
 
 	resourceURI := pathToResourceURI(validPath)
@@ -111,14 +90,14 @@ func (fs *FilesystemHandler) HandleWriteFile(
 		Content: []mcp.Content{
 			mcp.TextContent{
 				Type: "text",
-				Text: fmt.Sprintf("Successfully wrote %d bytes to %s", info.Size(), path),
+				Text: fmt.Sprintf("Successfully wrote %d bytes to %s\n%s", written.Size, path, describeWriteResult(written)),
 			},
 			mcp.EmbeddedResource{
 				Type: "resource",
 				Resource: mcp.TextResourceContents{
 					URI:      resourceURI,
 					MIMEType: "text/plain",
-					Text:     fmt.Sprintf("File: %s (%d bytes)", validPath, info.Size()),
+					Text:     fmt.Sprintf("File: %s (%d bytes)", validPath, written.Size),
 				},
 			},
 		},
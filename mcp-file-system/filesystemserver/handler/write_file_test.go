@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateParents(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	readOnly := filepath.Join(dir, "ref")
+	require.NoError(t, os.Mkdir(readOnly, 0755))
+	fsHandler, err := NewFilesystemHandler([]string{dir}, WithReadOnlyDirs([]string{readOnly}))
+	require.NoError(t, err)
+	nested := filepath.Join(dir, "src", "pkg", "util", "strings.go")
+
+	res := callTool(t, fsHandler.HandleWriteFile, map[string]any{"path": nested, "content": "package util"})
+	assert.Equal(t, CodeNotFound, ResultError(res).Code)
+
+	res = callTool(t, fsHandler.HandleWriteFile, map[string]any{"path": nested, "content": "package util", "create_parents": true})
+	require.False(t, res.IsError, res.Content)
+	content, err := os.ReadFile(nested)
+	require.NoError(t, err)
+	assert.Equal(t, "package util", string(content))
+
+	copied := filepath.Join(dir, "backup", "2024", "strings.go")
+	res = callTool(t, fsHandler.HandleCopyFile, map[string]any{"source": nested, "destination": copied, "create_parents": true})
+	require.False(t, res.IsError, res.Content)
+	assert.FileExists(t, copied)
+
+	moved := filepath.Join(dir, "archive", "old", "strings.go")
+	res = callTool(t, fsHandler.HandleMoveFile, map[string]any{"source": copied, "destination": moved, "create_parents": true})
+	require.False(t, res.IsError, res.Content)
+	assert.FileExists(t, moved)
+	assert.NoFileExists(t, copied)
+
+	// Missing parents are still checked against the allowed and read-only directories
+	res = callTool(t, fsHandler.HandleWriteFile, map[string]any{"path": filepath.Join(readOnly, "a", "b.txt"), "content": "x", "create_parents": true})
+	assert.Equal(t, CodeReadOnly, ResultError(res).Code)
+	res = callTool(t, fsHandler.HandleWriteFile, map[string]any{"path": filepath.Join(dir, "..", "escape", "b.txt"), "content": "x", "create_parents": true})
+	assert.Equal(t, CodePathNotAllowed, ResultError(res).Code)
+
+	dryRun, err := NewFilesystemHandler([]string{dir}, WithDryRun(true))
+	require.NoError(t, err)
+	res = callTool(t, dryRun.HandleWriteFile, map[string]any{"path": filepath.Join(dir, "new", "deep", "a.txt"), "content": "x", "create_parents": true})
+	text := res.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Would create directory "+filepath.Join(dir, "new")+"\n")
+	assert.Contains(t, text, "Would create directory "+filepath.Join(dir, "new", "deep"))
+	assert.NoDirExists(t, filepath.Join(dir, "new"))
+}
+
+func TestWriteReturnsHash(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	path := filepath.Join(dir, "main.go")
+
+	res := callTool(t, fsHandler.HandleWriteFile, map[string]any{"path": path, "content": "hello"})
+	require.False(t, res.IsError, res.Content)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "SHA256: "+newWriteResult(path, []byte("hello")).SHA256)
+
+	res = callTool(t, fsHandler.FormatToolCall(fsHandler.HandleWriteFile), map[string]any{"path": path, "content": "hello", "verify": true, "format": FormatJSON})
+	require.False(t, res.IsError, res.Content)
+	var written WriteResult
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &written))
+	assert.Equal(t, WriteResult{Path: path, Size: 5, SHA256: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", Verified: true}, written)
+
+	res = callTool(t, fsHandler.FormatToolCall(fsHandler.HandleModifyFile), map[string]any{"path": path, "find": "hello", "replace": "hi", "verify": true, "format": FormatJSON})
+	require.False(t, res.IsError, res.Content)
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &written))
+	assert.Equal(t, int64(2), written.Size)
+	assert.Equal(t, 1, written.Replacements)
+	assert.True(t, written.Verified)
+
+	// A read-back that differs from what was written fails verification
+	err = newWriteResult(path, []byte("hello")).checkReadBack([]byte("hellO"))
+	assert.Equal(t, CodeVerifyFailed, errorCode(err))
+}
+
+func TestWriteFileBase64(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	path := filepath.Join(dir, "pixel.png")
+	data := []byte{0x89, 'P', 'N', 'G', 0x0d, 0x0a, 0x1a, 0x0a, 0x00, 0xff}
+
+	res := callTool(t, fsHandler.HandleWriteFile, map[string]any{"path": path, "content": base64.StdEncoding.EncodeToString(data), "encoding": "base64"})
+	require.False(t, res.IsError, res.Content)
+	written, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, data, written)
+
+	// Line breaks in the payload are ignored
+	res = callTool(t, fsHandler.HandleWriteFile, map[string]any{"path": path, "content": "iVBO\nRw0K\n", "encoding": "base64"})
+	require.False(t, res.IsError, res.Content)
+	written, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, data[:6], written)
+
+	res = callTool(t, fsHandler.HandleWriteFile, map[string]any{"path": path, "content": "not base64!", "encoding": "base64"})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+	res = callTool(t, fsHandler.HandleWriteFile, map[string]any{"path": path, "content": "x", "encoding": "gbk"})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+}
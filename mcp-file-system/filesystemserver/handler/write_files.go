@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxWriteFiles is the most files one write_files call writes
+const maxWriteFiles = 200
+
+// WriteFilesResult is the JSON result of write_files
+type WriteFilesResult struct {
+	Files  []*WriteResult `json:"files"`
+	DryRun bool           `json:"dry_run,omitempty"`
+}
+
+// stagedWrite is one file of a write_files call
+type stagedWrite struct {
+	path    string
+	content []byte
+	// temp holds the new content until it is moved into place, and aside
+	// the replaced file until the whole group is written
+	temp, aside string
+	existed     bool
+	result      *WriteResult
+}
+
+// HandleWriteFiles handles the write_files tool
+func (fs *FilesystemHandler) HandleWriteFiles(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	raw, ok := request.GetArguments()["files"].([]any)
+	if !ok || len(raw) == 0 {
+		return errorResult(toolError(CodeInvalidArgument, "Error: files must be a non-empty list of {path, content} objects")), nil
+	}
+	if len(raw) > maxWriteFiles {
+		return errorResult(toolError(CodeInvalidArgument, "Error: at most %d files can be written per call", maxWriteFiles).With("limit", maxWriteFiles)), nil
+	}
+	createParents := request.GetBool("create_parents", false)
+	dryRun := request.GetBool("dry_run", false) || fs.dryRunMode()
+
+	// Every file is checked before any is written
+	writes := make([]*stagedWrite, 0, len(raw))
+	seen := make(map[string]int)
+	for i, item := range raw {
+		args, ok := item.(map[string]any)
+		if !ok {
+			return errorResult(toolError(CodeInvalidArgument, "Error: file %d must be an object", i+1)), nil
+		}
+		path, _ := args["path"].(string)
+		text, hasContent := args["content"].(string)
+		if path == "" || !hasContent {
+			return errorResult(toolError(CodeInvalidArgument, "Error: file %d needs path and content", i+1)), nil
+		}
+		if isSMBPath(path) {
+			return errorResult(toolError(CodeInvalidArgument, "Error: write_files does not support SMB paths; use write_file").With("path", path)), nil
+		}
+		encoding, _ := args["encoding"].(string)
+		if encoding == "" {
+			encoding = EncodingUTF8
+		}
+		content, err := decodeContent(text, encoding)
+		if err != nil {
+			return errorResult(err), nil
+		}
+		write, err := fs.checkStagedWrite(ctx, path, createParents, dryRun)
+		if err != nil {
+			return errorResult(err), nil
+		}
+		if first, ok := seen[write.path]; ok {
+			return errorResult(toolError(CodeInvalidArgument, "Error: files %d and %d both write %s", first, i+1, write.path).With("path", write.path)), nil
+		}
+		seen[write.path] = i + 1
+		write.content = content
+		writes = append(writes, write)
+	}
+
+	result := WriteFilesResult{DryRun: dryRun}
+	if dryRun {
+		var lines []string
+		created := make(map[string]bool)
+		for _, write := range writes {
+			for _, dir := range missingParents(write.path) {
+				if !created[dir] {
+					created[dir] = true
+					lines = append(lines, fmt.Sprintf("Would create directory %s", dir))
+				}
+			}
+			lines = append(lines, describeWrite(write.path, write.content)...)
+			result.Files = append(result.Files, newWriteResult(write.path, write.content))
+		}
+		if wantsJSON(ctx) {
+			return jsonResult(ctx, result)
+		}
+		return dryRunResult(lines...), nil
+	}
+
+	if err := fs.commitWrites(writes, request.GetBool("verify", false)); err != nil {
+		fs.reportQuotaError(writes[0].path, err)
+		return errorResult(fmt.Errorf("Error: no files were written: %w", err)), nil
+	}
+	for _, write := range writes {
+		result.Files = append(result.Files, write.result)
+	}
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, result)
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Successfully wrote %d file(s):\n", len(writes)))
+	for _, write := range writes {
+		action := "created"
+		if write.existed {
+			action = "overwrote"
+		}
+		sb.WriteString(fmt.Sprintf("  %s %s (%d bytes, SHA256 %s)\n", action, write.path, write.result.Size, write.result.SHA256))
+	}
+	if result.Files[0].Verified {
+		sb.WriteString("Verified: every file was synced to disk and read back\n")
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// checkStagedWrite validates one target of write_files
+func (fs *FilesystemHandler) checkStagedWrite(ctx context.Context, path string, createParents, dryRun bool) (*stagedWrite, error) {
+	resolved, err := fs.resolvePath(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if err := fs.checkScratchAccess(ctx, resolved); err != nil {
+		return nil, err
+	}
+	// A dry run only previews overwriting a file, so it need not be writable
+	var validPath string
+	if _, statErr := os.Stat(resolved); dryRun && statErr == nil {
+		validPath, err = fs.validatePath(resolved)
+	} else {
+		validPath, err = fs.validateCreatablePath(resolved, createParents)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error: %w", err)
+	}
+	write := &stagedWrite{path: validPath}
+	if info, err := os.Stat(validPath); err == nil {
+		if !info.Mode().IsRegular() {
+			return nil, toolError(CodeWrongType, "Error: %s is not a regular file", path).With("path", path)
+		}
+		write.existed = true
+	}
+	return write, nil
+}
+
+// commitWrites writes every file or none. The new contents are staged in
+// temporary files next to their targets, then moved into place; if any
+// step fails, the files replaced so far are put back and the directories
+// created for the group are removed.
+func (fs *FilesystemHandler) commitWrites(writes []*stagedWrite, verify bool) (err error) {
+	var createdDirs []string
+	var placed []*stagedWrite
+	defer func() {
+		if err == nil {
+			return
+		}
+		for i := len(placed) - 1; i >= 0; i-- {
+			write := placed[i]
+			if write.aside != "" {
+				os.Rename(write.aside, write.path)
+			} else {
+				os.Remove(write.path)
+			}
+		}
+		for _, write := range writes {
+			if write.temp != "" {
+				os.Remove(write.temp)
+			}
+		}
+		for i := len(createdDirs) - 1; i >= 0; i-- {
+			os.Remove(createdDirs[i])
+		}
+	}()
+
+	for _, write := range writes {
+		dir := filepath.Dir(write.path)
+		for _, missing := range missingParents(write.path) {
+			if err := os.Mkdir(missing, 0755); err != nil && !os.IsExist(err) {
+				return fmt.Errorf("failed to create %s: %w", missing, err)
+			}
+			createdDirs = append(createdDirs, missing)
+		}
+		if err := stageWrite(write, dir, verify); err != nil {
+			return fmt.Errorf("failed to write %s: %w", write.path, err)
+		}
+	}
+
+	for _, write := range writes {
+		if write.existed {
+			if err := fs.saveVersion(write.path); err != nil {
+				return fmt.Errorf("failed to save the previous version of %s: %w", write.path, err)
+			}
+			write.aside = write.temp + ".old"
+			if err := os.Rename(write.path, write.aside); err != nil {
+				write.aside = ""
+				return fmt.Errorf("failed to replace %s: %w", write.path, err)
+			}
+		}
+		if err := os.Rename(write.temp, write.path); err != nil {
+			if write.aside != "" {
+				os.Rename(write.aside, write.path)
+			}
+			return fmt.Errorf("failed to replace %s: %w", write.path, err)
+		}
+		write.temp = ""
+		placed = append(placed, write)
+	}
+
+	for _, write := range writes {
+		write.result = newWriteResult(write.path, write.content)
+		if !verify {
+			continue
+		}
+		onDisk, err := os.ReadFile(write.path)
+		if err != nil {
+			return fmt.Errorf("failed to read back %s: %w", write.path, err)
+		}
+		if err := write.result.checkReadBack(onDisk); err != nil {
+			return err
+		}
+	}
+
+	// The group is in place, so the replaced files can go
+	for _, write := range writes {
+		if write.aside != "" {
+			os.Remove(write.aside)
+		}
+	}
+	return nil
+}
+
+// stageWrite writes the new content of a file to a temporary file in dir,
+// keeping the mode of the file it replaces
+func stageWrite(write *stagedWrite, dir string, verify bool) error {
+	f, err := os.CreateTemp(dir, "."+filepath.Base(write.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	write.temp = f.Name()
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(write.path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if _, err := f.Write(write.content); err != nil {
+		f.Close()
+		return err
+	}
+	if verify {
+		if err := f.Sync(); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Chmod(write.temp, mode)
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFiles(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	existing := filepath.Join(dir, "go.mod")
+	require.NoError(t, os.WriteFile(existing, []byte("module old\n"), 0600))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	res := callTool(t, fsHandler.HandleWriteFiles, map[string]any{"create_parents": true, "files": []any{
+		map[string]any{"path": existing, "content": "module new\n"},
+		map[string]any{"path": filepath.Join(dir, "cmd", "app", "main.go"), "content": "package main\n"},
+		map[string]any{"path": filepath.Join(dir, "logo.bin"), "content": "AAEC", "encoding": "base64"},
+	}})
+	require.False(t, res.IsError, res.Content)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Successfully wrote 3 file(s)")
+	content, err := os.ReadFile(existing)
+	require.NoError(t, err)
+	assert.Equal(t, "module new\n", string(content))
+	info, err := os.Stat(existing)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "the mode of a replaced file is kept")
+	content, err = os.ReadFile(filepath.Join(dir, "logo.bin"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 1, 2}, content)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3, "no temporary files are left behind")
+}
+
+func TestWriteFilesAllOrNothing(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	existing := filepath.Join(dir, "a.txt")
+	require.NoError(t, os.WriteFile(existing, []byte("a"), 0644))
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+
+	// A path outside the allowed directories fails the whole group
+	res := callTool(t, fsHandler.HandleWriteFiles, map[string]any{"files": []any{
+		map[string]any{"path": existing, "content": "changed"},
+		map[string]any{"path": filepath.Join(t.TempDir(), "b.txt"), "content": "b"},
+	}})
+	require.True(t, res.IsError)
+	assert.Equal(t, CodePathNotAllowed, ResultError(res).Code)
+	content, err := os.ReadFile(existing)
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(content))
+
+	res = callTool(t, fsHandler.HandleWriteFiles, map[string]any{"files": []any{
+		map[string]any{"path": existing, "content": "1"},
+		map[string]any{"path": existing, "content": "2"},
+	}})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+
+	// A failure while placing the files puts back what was replaced
+	blocker := filepath.Join(dir, "blocked")
+	require.NoError(t, os.Mkdir(blocker, 0755))
+	writes := []*stagedWrite{
+		{path: existing, content: []byte("changed"), existed: true},
+		{path: filepath.Join(dir, "new", "c.txt"), content: []byte("c")},
+		{path: blocker, content: []byte("not a file")},
+	}
+	require.Error(t, fsHandler.commitWrites(writes, false))
+	content, err = os.ReadFile(existing)
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(content))
+	assert.NoDirExists(t, filepath.Join(dir, "new"))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	res = callTool(t, fsHandler.HandleWriteFiles, map[string]any{"dry_run": true, "files": []any{
+		map[string]any{"path": existing, "content": "b\n"},
+	}})
+	require.False(t, res.IsError)
+	assert.Contains(t, res.Content[0].(mcp.TextContent).Text, "Would overwrite")
+	content, err = os.ReadFile(existing)
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(content))
+}
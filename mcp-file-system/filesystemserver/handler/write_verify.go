@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+)
+
+// WriteResult is the JSON result of write_file and modify_file, describing
+// the file as it was left on disk
+type WriteResult struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	// Verified is set when the file was synced to disk and read back
+	Verified bool `json:"verified"`
+	// Replacements counts the replacements made by modify_file
+	Replacements int `json:"replacements,omitempty"`
+	// Backup is where the previous content was copied, when backed up
+	Backup string `json:"backup,omitempty"`
+}
+
+// newWriteResult describes content written to path
+func newWriteResult(path string, content []byte) *WriteResult {
+	sum := sha256.Sum256(content)
+	return &WriteResult{Path: path, Size: int64(len(content)), SHA256: hex.EncodeToString(sum[:])}
+}
+
+// checkReadBack compares what was read back from a file with the write it
+// describes, failing with CodeVerifyFailed on a mismatch
+func (r *WriteResult) checkReadBack(onDisk []byte) error {
+	if got := newWriteResult(r.Path, onDisk); got.SHA256 != r.SHA256 {
+		return toolError(CodeVerifyFailed, "%s does not match what was written: read back %d bytes with SHA256 %s", r.Path, got.Size, got.SHA256).
+			With("path", r.Path).With("expected_sha256", r.SHA256)
+	}
+	r.Verified = true
+	return nil
+}
+
+// writeContent writes content to path and describes the result, saving
+// the previous content as a version when versioning is on. With verify,
+// the file is synced to disk and read back.
+func (fs *FilesystemHandler) writeContent(path string, content []byte, verify bool) (*WriteResult, error) {
+	if err := fs.saveVersion(path); err != nil {
+		return nil, fmt.Errorf("failed to save the previous version: %w", err)
+	}
+	result := newWriteResult(path, content)
+	if !verify {
+		return result, os.WriteFile(path, content, 0644)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	onDisk, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read back %s: %w", path, err)
+	}
+	return result, result.checkReadBack(onDisk)
+}
+
+// describeWriteResult renders the hash of a write, and whether it was
+// verified, for text results
+func describeWriteResult(result *WriteResult) string {
+	text := "SHA256: " + result.SHA256
+	if result.Verified {
+		text += "\nVerified: the file was synced to disk and read back"
+	}
+	if result.Backup != "" {
+		text += "\nBackup: " + result.Backup + " (use restore_backup to put it back)"
+	}
+	return text
+}
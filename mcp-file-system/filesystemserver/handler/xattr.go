@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// XattrResult is the JSON result of get_xattr
+type XattrResult struct {
+	Path       string  `json:"path"`
+	Attributes []Xattr `json:"attributes"`
+}
+
+// Xattr is one extended attribute. Values that are not printable UTF-8
+// are given in base64.
+type Xattr struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Encoding string `json:"encoding"`
+	Size     int    `json:"size"`
+}
+
+// newXattr returns the attribute name with value, choosing its encoding
+func newXattr(name string, value []byte) Xattr {
+	attr := Xattr{Name: name, Value: string(value), Encoding: EncodingUTF8, Size: len(value)}
+	printable := utf8.Valid(value) && strings.IndexFunc(string(value), func(r rune) bool {
+		return !unicode.IsPrint(r) && !unicode.IsSpace(r)
+	}) < 0
+	if !printable {
+		attr.Value = base64.StdEncoding.EncodeToString(value)
+		attr.Encoding = EncodingBase64
+	}
+	return attr
+}
+
+// xattrError turns an error from the extended attribute calls into a tool
+// error
+func xattrError(err error, path, name string) error {
+	switch {
+	case isNoXattr(err):
+		return toolError(CodeNotFound, "Error: %s has no extended attribute %q", path, name).With("path", path).With("name", name)
+	case isXattrUnsupported(err):
+		return toolError(CodeUnavailable, "Error: extended attributes are not supported for %s: %w", path, err).With("path", path)
+	}
+	return fmt.Errorf("Error: %w", err)
+}
+
+// HandleGetXattr handles the get_xattr tool
+func (fs *FilesystemHandler) HandleGetXattr(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	name := request.GetString("name", "")
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if _, err := os.Stat(validPath); err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+
+	names := []string{name}
+	if name == "" {
+		if names, err = listXattrs(validPath); err != nil {
+			return errorResult(xattrError(err, path, "")), nil
+		}
+	}
+	result := XattrResult{Path: validPath, Attributes: []Xattr{}}
+	for _, n := range names {
+		value, err := getXattr(validPath, n)
+		if err != nil {
+			// An attribute removed since it was listed is skipped
+			if name == "" && isNoXattr(err) {
+				continue
+			}
+			return errorResult(xattrError(err, path, n)), nil
+		}
+		result.Attributes = append(result.Attributes, newXattr(n, value))
+	}
+
+	if wantsJSON(ctx) {
+		return jsonResult(ctx, result)
+	}
+	if len(result.Attributes) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("%s has no extended attributes", validPath)), nil
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Extended attributes of %s:\n", validPath))
+	for _, attr := range result.Attributes {
+		if attr.Encoding == EncodingBase64 {
+			sb.WriteString(fmt.Sprintf("  %s (%d bytes, base64): %s\n", attr.Name, attr.Size, attr.Value))
+		} else {
+			sb.WriteString(fmt.Sprintf("  %s: %s\n", attr.Name, attr.Value))
+		}
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// HandleSetXattr handles the set_xattr tool
+func (fs *FilesystemHandler) HandleSetXattr(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	name, err := request.RequireString("name")
+	if err != nil {
+		return nil, err
+	}
+	if !slices.ContainsFunc(xattrNamespaces, func(prefix string) bool { return strings.HasPrefix(name, prefix) }) {
+		return errorResult(toolError(CodeInvalidArgument, "Error: only attributes named %s... can be changed, not %q", strings.Join(xattrNamespaces, "... or "), name).
+			With("name", name).With("namespaces", xattrNamespaces)), nil
+	}
+	remove := request.GetBool("remove", false)
+	rawValue, hasValue := request.GetArguments()["value"].(string)
+	if remove == hasValue {
+		return errorResult(toolError(CodeInvalidArgument, "Error: give either value or remove")), nil
+	}
+	value, err := decodeContent(rawValue, request.GetString("encoding", EncodingUTF8))
+	if err != nil {
+		return errorResult(err), nil
+	}
+	validPath, err := fs.validateWritablePath(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+	if _, err := os.Stat(validPath); err != nil {
+		return errorResult(fmt.Errorf("Error: %w", err)), nil
+	}
+
+	if fs.dryRunMode() {
+		if remove {
+			return dryRunResult(fmt.Sprintf("Would remove the extended attribute %s of %s", name, validPath)), nil
+		}
+		return dryRunResult(fmt.Sprintf("Would set the extended attribute %s of %s to %d bytes", name, validPath, len(value))), nil
+	}
+	if remove {
+		if err := removeXattr(validPath, name); err != nil {
+			return errorResult(xattrError(err, path, name)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Removed the extended attribute %s of %s", name, validPath)), nil
+	}
+	if err := setXattr(validPath, name, value); err != nil {
+		return errorResult(xattrError(err, path, name)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Set the extended attribute %s of %s (%d bytes)", name, validPath, len(value))), nil
+}
@@ -0,0 +1,12 @@
+//go:build darwin
+
+package handler
+
+import "golang.org/x/sys/unix"
+
+// errNoXattr is the error for a missing extended attribute
+var errNoXattr = unix.ENOATTR
+
+// xattrNamespaces are the attribute name prefixes set_xattr may change,
+// covering Finder tags and the quarantine flag
+var xattrNamespaces = []string{"user.", "com.apple."}
@@ -0,0 +1,13 @@
+//go:build linux
+
+package handler
+
+import "golang.org/x/sys/unix"
+
+// errNoXattr is the error for a missing extended attribute
+var errNoXattr = unix.ENODATA
+
+// xattrNamespaces are the attribute name prefixes set_xattr may change.
+// The security, trusted and system namespaces hold file capabilities and
+// ACLs, which a server running as root could otherwise rewrite.
+var xattrNamespaces = []string{"user."}
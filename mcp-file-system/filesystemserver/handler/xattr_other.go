@@ -0,0 +1,45 @@
+//go:build !linux && !darwin
+
+package handler
+
+import (
+	"errors"
+	"runtime"
+)
+
+// errXattrUnsupported is returned on platforms without extended attributes
+var errXattrUnsupported = errors.New("extended attributes are not supported on " + runtime.GOOS)
+
+// xattrNamespaces are the attribute name prefixes set_xattr may change
+var xattrNamespaces = []string{"user."}
+
+// listXattrs is not implemented on this platform
+func listXattrs(path string) ([]string, error) {
+	return nil, errXattrUnsupported
+}
+
+// getXattr is not implemented on this platform
+func getXattr(path, name string) ([]byte, error) {
+	return nil, errXattrUnsupported
+}
+
+// setXattr is not implemented on this platform
+func setXattr(path, name string, value []byte) error {
+	return errXattrUnsupported
+}
+
+// removeXattr is not implemented on this platform
+func removeXattr(path, name string) error {
+	return errXattrUnsupported
+}
+
+// isNoXattr reports whether err means the attribute does not exist
+func isNoXattr(err error) bool {
+	return false
+}
+
+// isXattrUnsupported reports whether err means extended attributes are
+// not available
+func isXattrUnsupported(err error) bool {
+	return errors.Is(err, errXattrUnsupported)
+}
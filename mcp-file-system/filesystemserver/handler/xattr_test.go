@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestXattr(t *testing.T) {
+	dir := resolveAllowedDirs(t, t.TempDir())[0]
+	path := filepath.Join(dir, "report.pdf")
+	require.NoError(t, os.WriteFile(path, []byte("%PDF"), 0644))
+	if err := setXattr(path, "user.probe", []byte("1")); err != nil {
+		t.Skipf("extended attributes are not available here: %v", err)
+	}
+	require.NoError(t, removeXattr(path, "user.probe"))
+
+	fsHandler, err := NewFilesystemHandler([]string{dir})
+	require.NoError(t, err)
+	get := fsHandler.FormatToolCall(fsHandler.HandleGetXattr)
+
+	res := callTool(t, fsHandler.HandleSetXattr, map[string]any{"path": path, "name": "user.origin", "value": "croc"})
+	require.False(t, res.IsError, res.Content)
+	res = callTool(t, fsHandler.HandleSetXattr, map[string]any{"path": path, "name": "user.blob", "value": "AAEC", "encoding": EncodingBase64})
+	require.False(t, res.IsError, res.Content)
+
+	res = callTool(t, get, map[string]any{"path": path, "format": FormatJSON})
+	require.False(t, res.IsError, res.Content)
+	var got XattrResult
+	require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &got))
+	assert.ElementsMatch(t, []Xattr{
+		{Name: "user.origin", Value: "croc", Encoding: EncodingUTF8, Size: 4},
+		{Name: "user.blob", Value: "AAEC", Encoding: EncodingBase64, Size: 3},
+	}, got.Attributes)
+
+	res = callTool(t, fsHandler.HandleSetXattr, map[string]any{"path": path, "name": "user.origin", "remove": true})
+	require.False(t, res.IsError, res.Content)
+	res = callTool(t, get, map[string]any{"path": path, "name": "user.origin"})
+	require.True(t, res.IsError)
+	assert.Equal(t, CodeNotFound, ResultError(res).Code)
+	res = callTool(t, fsHandler.HandleSetXattr, map[string]any{"path": path, "name": "user.origin", "remove": true})
+	assert.Equal(t, CodeNotFound, ResultError(res).Code)
+
+	// Capabilities, ACLs and other privileged namespaces are off limits
+	for _, name := range []string{"security.capability", "trusted.overlay.opaque", "system.posix_acl_access", "origin"} {
+		res = callTool(t, fsHandler.HandleSetXattr, map[string]any{"path": path, "name": name, "value": "x"})
+		require.True(t, res.IsError, name)
+		assert.Equal(t, CodeInvalidArgument, ResultError(res).Code, name)
+	}
+
+	res = callTool(t, fsHandler.HandleSetXattr, map[string]any{"path": path, "name": "user.origin"})
+	assert.Equal(t, CodeInvalidArgument, ResultError(res).Code)
+}
@@ -0,0 +1,78 @@
+//go:build linux || darwin
+
+package handler
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// listXattrs returns the names of the extended attributes of path
+func listXattrs(path string) ([]string, error) {
+	buf, err := readXattrBuffer(func(dest []byte) (int, error) {
+		return unix.Listxattr(path, dest)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, name := range strings.Split(string(buf), "\x00") {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// getXattr returns the value of an extended attribute of path
+func getXattr(path, name string) ([]byte, error) {
+	return readXattrBuffer(func(dest []byte) (int, error) {
+		return unix.Getxattr(path, name, dest)
+	})
+}
+
+// setXattr sets an extended attribute of path, creating or replacing it
+func setXattr(path, name string, value []byte) error {
+	return unix.Setxattr(path, name, value, 0)
+}
+
+// removeXattr removes an extended attribute of path
+func removeXattr(path, name string) error {
+	return unix.Removexattr(path, name)
+}
+
+// readXattrBuffer asks read for the size it needs, then reads into a buffer
+// of that size, retrying if the attributes grew in between
+func readXattrBuffer(read func(dest []byte) (int, error)) ([]byte, error) {
+	for {
+		size, err := read(nil)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return []byte{}, nil
+		}
+		buf := make([]byte, size)
+		n, err := read(buf)
+		if errors.Is(err, unix.ERANGE) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	}
+}
+
+// isNoXattr reports whether err means the attribute does not exist
+func isNoXattr(err error) bool {
+	return errors.Is(err, errNoXattr)
+}
+
+// isXattrUnsupported reports whether err means the file system has no
+// extended attributes, or none in the namespace asked for
+func isXattrUnsupported(err error) bool {
+	return errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EOPNOTSUPP)
+}
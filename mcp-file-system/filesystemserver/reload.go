@@ -0,0 +1,39 @@
+package filesystemserver
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/mark3labs/mcp-filesystem-server/filesystemserver/handler"
+)
+
+// Reloader reconfigures a running server without dropping MCP sessions or
+// interrupting croc transfers. Pass it to NewFilesystemServer with WithReloader.
+type Reloader struct {
+	mu sync.Mutex
+	h  *handler.FilesystemHandler
+}
+
+// attach connects the reloader to the server's handler
+func (r *Reloader) attach(h *handler.FilesystemHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.h = h
+}
+
+// Reload applies new allowed directories and handler options; see
+// handler.FilesystemHandler.Reload for which settings take effect. Tool
+// groups are fixed when the server is created.
+func (r *Reloader) Reload(allowedDirs []string, opts ...Option) error {
+	var options Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.h == nil {
+		return errors.New("reloader is not attached to a server")
+	}
+	return r.h.Reload(allowedDirs, options.HandlerOptions...)
+}
@@ -1,24 +1,176 @@
 package filesystemserver
 
 import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-filesystem-server/buildinfo"
 	"github.com/mark3labs/mcp-filesystem-server/filesystemserver/handler"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
-var Version = "dev"
+// BuildInfoMeta is the _meta key of the initialize result holding the
+// server's buildinfo.Info
+const BuildInfoMeta = "mcp-filesystem-server/build"
+
+// Tool groups that can be disabled as a unit
+const (
+	ToolGroupRead    = "read"
+	ToolGroupSearch  = "search"
+	ToolGroupWrite   = "write"
+	ToolGroupTrash   = "trash"
+	ToolGroupMedia   = "media"
+	ToolGroupCroc    = "croc"
+	ToolGroupConvert = "convert"
+	ToolGroupBackup  = "backup"
+	// ToolGroupExtra holds the tools added with WithExtraTools
+	ToolGroupExtra = "extra"
+	// ToolGroupAdmin holds operator tools that are off unless enabled
+	ToolGroupAdmin = "admin"
+)
+
+// ToolGroups lists every tool group name
+var ToolGroups = []string{
+	ToolGroupRead,
+	ToolGroupSearch,
+	ToolGroupWrite,
+	ToolGroupTrash,
+	ToolGroupMedia,
+	ToolGroupCroc,
+	ToolGroupConvert,
+	ToolGroupBackup,
+	ToolGroupExtra,
+	ToolGroupAdmin,
+}
+
+// OptInToolGroups are tool groups that are only registered when listed in
+// Options.EnabledToolGroups
+var OptInToolGroups = []string{ToolGroupAdmin}
+
+// toolFormats are the formats tools render beyond text and json
+var toolFormats = map[string][]string{
+	"tree": handler.TreeFormats,
+}
+
+// Options configures NewFilesystemServer beyond the allowed directories
+type Options struct {
+	// HandlerOptions configure the underlying FilesystemHandler
+	HandlerOptions []handler.HandlerOption
+	// DisabledToolGroups are tool groups whose tools are not registered
+	DisabledToolGroups []string
+	// EnabledToolGroups are opt-in tool groups whose tools are registered
+	EnabledToolGroups []string
+	// Reloader, when set, is connected to the new server so it can be reconfigured later
+	Reloader *Reloader
+	// Shutdowner, when set, is connected to the new server so it can be drained before exit
+	Shutdowner *Shutdowner
+	// ExtraTools are site-specific tools registered after the built-in ones
+	ExtraTools []ExtraTool
+}
+
+// Option modifies Options
+type Option func(*Options)
+
+// WithHandlerOptions passes options through to the FilesystemHandler
+func WithHandlerOptions(opts ...handler.HandlerOption) Option {
+	return func(o *Options) {
+		o.HandlerOptions = append(o.HandlerOptions, opts...)
+	}
+}
+
+// WithDisabledToolGroups stops the tools in the given groups from being registered
+func WithDisabledToolGroups(groups ...string) Option {
+	return func(o *Options) {
+		o.DisabledToolGroups = append(o.DisabledToolGroups, groups...)
+	}
+}
+
+// WithEnabledToolGroups registers the tools in the given opt-in groups
+func WithEnabledToolGroups(groups ...string) Option {
+	return func(o *Options) {
+		o.EnabledToolGroups = append(o.EnabledToolGroups, groups...)
+	}
+}
+
+// WithOptions merges a prepared Options struct, e.g. one built from a config file
+func WithOptions(options Options) Option {
+	return func(o *Options) {
+		o.HandlerOptions = append(o.HandlerOptions, options.HandlerOptions...)
+		o.DisabledToolGroups = append(o.DisabledToolGroups, options.DisabledToolGroups...)
+		o.EnabledToolGroups = append(o.EnabledToolGroups, options.EnabledToolGroups...)
+		o.ExtraTools = append(o.ExtraTools, options.ExtraTools...)
+		if options.Reloader != nil {
+			o.Reloader = options.Reloader
+		}
+		if options.Shutdowner != nil {
+			o.Shutdowner = options.Shutdowner
+		}
+	}
+}
 
-func NewFilesystemServer(allowedDirs []string) (*server.MCPServer, error) {
+// WithReloader connects r to the server so its configuration can be reloaded at runtime
+func WithReloader(r *Reloader) Option {
+	return func(o *Options) {
+		o.Reloader = r
+	}
+}
+
+// WithShutdowner connects s to the server so it can be shut down gracefully
+func WithShutdowner(s *Shutdowner) Option {
+	return func(o *Options) {
+		o.Shutdowner = s
+	}
+}
 
-	h, err := handler.NewFilesystemHandler(allowedDirs)
+func NewFilesystemServer(allowedDirs []string, opts ...Option) (*server.MCPServer, error) {
+	var options Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+	for _, group := range slices.Concat(options.DisabledToolGroups, options.EnabledToolGroups) {
+		if !slices.Contains(ToolGroups, group) {
+			return nil, fmt.Errorf("unknown tool group %q", group)
+		}
+	}
+	for _, group := range OptInToolGroups {
+		if !slices.Contains(options.EnabledToolGroups, group) {
+			options.DisabledToolGroups = append(options.DisabledToolGroups, group)
+		}
+	}
+
+	h, err := handler.NewFilesystemHandler(allowedDirs, options.HandlerOptions...)
 	if err != nil {
 		return nil, err
 	}
+	if options.Reloader != nil {
+		options.Reloader.attach(h)
+	}
+	if options.Shutdowner != nil {
+		options.Shutdowner.attach(h)
+	}
 
+	// The initialize result carries the complete build information, beyond
+	// the version in serverInfo
+	info := buildinfo.Get()
+	hooks := &server.Hooks{}
+	hooks.AddAfterInitialize(func(ctx context.Context, id any, message *mcp.InitializeRequest, result *mcp.InitializeResult) {
+		if result.Meta == nil {
+			result.Meta = make(map[string]any)
+		}
+		result.Meta[BuildInfoMeta] = info
+	})
+	// Base directories chosen with set_base_directory end with their session
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		h.ForgetSession(session.SessionID())
+	})
 	s := server.NewMCPServer(
 		"secure-filesystem-server",
-		Version,
+		info.Version,
 		server.WithResourceCapabilities(true, true),
+		server.WithHooks(hooks),
 	)
 
 	// Register resource handlers
@@ -28,19 +180,80 @@ func NewFilesystemServer(allowedDirs []string) (*server.MCPServer, error) {
 		mcp.WithResourceDescription("Access to files and directories on the local file system"),
 	), h.HandleReadResource)
 
+	// addTool registers a tool with relative path resolution, call logging,
+	// recording, tracing, usage counting, response formats, error codes,
+	// shutdown tracking, timeouts and concurrency limits unless its group has
+	// been disabled
+	registered := make(map[string]bool)
+	addTool := func(group string, tool mcp.Tool, fn handler.ToolHandlerFunc) {
+		registered[tool.Name] = true
+		if slices.Contains(options.DisabledToolGroups, group) {
+			return
+		}
+		// Site-specific tool names are not reported individually
+		usageName := tool.Name
+		if group == ToolGroupExtra {
+			usageName = ToolGroupExtra
+		}
+		fn = h.ErrorToolCall(h.TrackToolCall(h.LimitToolCall(tool.Name, fn)))
+		// Extra tools may already define their own format argument
+		if _, ok := tool.InputSchema.Properties["format"]; !ok {
+			description := "Response format: text (default) or json for a single JSON document with a stable schema"
+			extra := toolFormats[tool.Name]
+			if len(extra) > 0 {
+				description += ", or " + strings.Join(extra, ", ")
+			}
+			mcp.WithString("format",
+				mcp.Description(description),
+				mcp.Enum(append([]string{handler.FormatText, handler.FormatJSON}, extra...)...),
+			)(&tool)
+			fn = h.FormatToolCall(fn, extra...)
+		}
+		fn = h.PageToolCall(tool.Name, h.RouteToolCall(tool.Name, fn))
+		s.AddTool(tool, server.ToolHandlerFunc(h.ResolveToolCall(h.ScratchToolCall(tool.Name, h.TraceToolCall(tool.Name, h.LogToolCall(tool.Name, h.RecordToolCall(tool.Name, h.CountToolCall(usageName, fn))))))))
+	}
+
 	// Register tool handlers
-	s.AddTool(mcp.NewTool(
+	addTool(ToolGroupRead, mcp.NewTool(
 		"read_file",
-		mcp.WithDescription("Read the complete contents of a file from the file system."),
+		mcp.WithDescription("Read the complete contents of a file from the file system, or a byte or line range of it."),
 		mcp.WithString("path",
 			mcp.Description("Path to the file to read"),
 			mcp.Required(),
 		),
+		mcp.WithString("highlight",
+			mcp.Description("Also return syntax-highlighted content for text files: 'html' (inline-styled fragment) or 'tokens' (JSON token metadata)"),
+			mcp.Enum("html", "tokens"),
+		),
+		mcp.WithString("highlight_style",
+			mcp.Description("Chroma style name for html highlighting (default: github)"),
+		),
+		mcp.WithString("encoding",
+			mcp.Description("Text encoding of the file, such as utf-8, gbk, shift_jis, latin-1 or utf-16le. The default, auto, honours a byte order mark and otherwise reads UTF-8, falling back to Windows-1252. A named encoding reads the file as text whatever its detected type."),
+		),
+		mcp.WithBoolean("line_numbers",
+			mcp.Description("Prefix each line of a text file with its line number and a tab, like cat -n (default: false)"),
+		),
+		mcp.WithBoolean("as_base64",
+			mcp.Description("Return the raw bytes base64 encoded with their detected MIME type, whatever the file type (default: false; binary files are always returned this way)"),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("Read from this byte offset, to page through large files (default: 0)"),
+		),
+		mcp.WithNumber("length",
+			mcp.Description("Read at most this many bytes from offset (default: to the end, up to the inline size limit)"),
+		),
+		mcp.WithNumber("start_line",
+			mcp.Description("Read text from this line, counting from 1; cannot be combined with offset or length"),
+		),
+		mcp.WithNumber("end_line",
+			mcp.Description("Read text up to and including this line (default: to the end, up to the inline size limit)"),
+		),
 	), h.HandleReadFile)
 
-	s.AddTool(mcp.NewTool(
+	addTool(ToolGroupWrite, mcp.NewTool(
 		"write_file",
-		mcp.WithDescription("Create a new file or overwrite an existing file with new content."),
+		mcp.WithDescription("Create a new file or overwrite an existing file with new content. Returns the size and SHA256 of the written file."),
 		mcp.WithString("path",
 			mcp.Description("Path where to write the file"),
 			mcp.Required(),
@@ -49,18 +262,87 @@ func NewFilesystemServer(allowedDirs []string) (*server.MCPServer, error) {
 			mcp.Description("Content to write to the file"),
 			mcp.Required(),
 		),
+		mcp.WithString("encoding",
+			mcp.Description("Encoding of content: utf-8 for text, or base64 for binary data such as images, archives or PDFs (default: utf-8)"),
+			mcp.Enum("utf-8", "base64"),
+		),
+		mcp.WithBoolean("create_parents",
+			mcp.Description("Create missing parent directories of the file (default: false)"),
+		),
+		mcp.WithBoolean("verify",
+			mcp.Description("Sync the file to disk and read it back to check it matches before returning (default: false)"),
+		),
+		mcp.WithBoolean("backup",
+			mcp.Description("Copy the current file to its backup first, restorable with restore_backup (default: the server's file_backups setting, else false)"),
+		),
 	), h.HandleWriteFile)
 
-	s.AddTool(mcp.NewTool(
+	addTool(ToolGroupWrite, mcp.NewTool(
+		"write_files",
+		mcp.WithDescription("Create or overwrite several files as a group: either every file is written or none is. Use it instead of a series of write_file calls, such as to scaffold a project."),
+		mcp.WithArray("files",
+			mcp.Description("Files to write, each with path, content and optional encoding (utf-8 or base64)"),
+			mcp.Required(),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"path":     map[string]any{"type": "string"},
+					"content":  map[string]any{"type": "string"},
+					"encoding": map[string]any{"type": "string", "enum": []string{handler.EncodingUTF8, handler.EncodingBase64}},
+				},
+				"required": []string{"path", "content"},
+			}),
+		),
+		mcp.WithBoolean("create_parents",
+			mcp.Description("Create missing parent directories of the files (default: false)"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Only report what would be written, with a unified diff for overwritten files (default: false)"),
+		),
+		mcp.WithBoolean("verify",
+			mcp.Description("Sync the files to disk and read them back to check they match before returning (default: false)"),
+		),
+	), h.HandleWriteFiles)
+
+	addTool(ToolGroupRead, mcp.NewTool(
 		"list_directory",
-		mcp.WithDescription("Get a detailed listing of all files and directories in a specified path."),
+		mcp.WithDescription("Get a detailed listing of all files and directories in a specified path. Entries can be filtered by name, sorted and limited, such as the 20 most recently modified *.log files."),
 		mcp.WithString("path",
 			mcp.Description("Path of the directory to list"),
 			mcp.Required(),
 		),
+		mcp.WithString("sort_by",
+			mcp.Description("Sort entries by name (default), size or modification time"),
+			mcp.Enum(handler.SortByName, handler.SortBySize, handler.SortByMtime),
+		),
+		mcp.WithString("order",
+			mcp.Description("Sort order: asc (default) or desc"),
+			mcp.Enum(handler.OrderAsc, handler.OrderDesc),
+		),
+		mcp.WithBoolean("dirs_first",
+			mcp.Description("List directories before files (default: false)"),
+		),
+		mcp.WithBoolean("show_hidden",
+			mcp.Description("Include entries whose names start with a dot (default: true)"),
+		),
+		mcp.WithBoolean("include_hidden",
+			mcp.Description("Same as show_hidden"),
+		),
+		mcp.WithString("glob",
+			mcp.Description("Only list entries whose names match this glob pattern, such as '*.log'"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Return at most this many entries, after sorting"),
+		),
+		mcp.WithNumber("page_size",
+			mcp.Description("Return the listing in pages of at most this many entries (up to 5000), with a cursor for the next page"),
+		),
+		mcp.WithString("cursor",
+			mcp.Description("Continue a paged listing from the next_cursor of the previous page, keeping the other arguments the same"),
+		),
 	), h.HandleListDirectory)
 
-	s.AddTool(mcp.NewTool(
+	addTool(ToolGroupWrite, mcp.NewTool(
 		"create_directory",
 		mcp.WithDescription("Create a new directory or ensure a directory exists."),
 		mcp.WithString("path",
@@ -69,33 +351,294 @@ func NewFilesystemServer(allowedDirs []string) (*server.MCPServer, error) {
 		),
 	), h.HandleCreateDirectory)
 
-	s.AddTool(mcp.NewTool(
-		"copy_file",
-		mcp.WithDescription("Copy files and directories."),
+	addTool(ToolGroupWrite, mcp.NewTool(
+		"create_symlink",
+		mcp.WithDescription("Create a symbolic link at path pointing to target. Both the link and the path its target resolves to must be within the allowed directories. On Windows this needs Developer Mode or administrator rights."),
+		mcp.WithString("path",
+			mcp.Description("Where to create the link; it must not exist"),
+			mcp.Required(),
+		),
+		mcp.WithString("target",
+			mcp.Description("What the link points to, absolute or relative to the link's directory; it need not exist yet"),
+			mcp.Required(),
+		),
+		mcp.WithBoolean("relative",
+			mcp.Description("Store an absolute target as a path relative to the link, so the link survives moving the tree (default: false)"),
+		),
+		mcp.WithBoolean("create_parents",
+			mcp.Description("Create missing parent directories of the link (default: false)"),
+		),
+	), h.HandleCreateSymlink)
+
+	addTool(ToolGroupWrite, mcp.NewTool(
+		"create_hardlink",
+		mcp.WithDescription("Create a hard link at path to the file source, so both names share one copy of the data, e.g. to deduplicate identical build artifacts. Both must be in writable allowed directories on the same filesystem. Later in-place edits through either name change both."),
 		mcp.WithString("source",
-			mcp.Description("Source path of the file or directory"),
+			mcp.Description("Existing file to link to"),
+			mcp.Required(),
+		),
+		mcp.WithString("path",
+			mcp.Description("Where to create the link"),
+			mcp.Required(),
+		),
+		mcp.WithBoolean("replace_identical",
+			mcp.Description("If path is a byte-for-byte copy of source, replace it with the link; a different file is never replaced (default: false)"),
+		),
+		mcp.WithBoolean("create_parents",
+			mcp.Description("Create missing parent directories of the link (default: false)"),
+		),
+	), h.HandleCreateHardlink)
+
+	addTool(ToolGroupWrite, mcp.NewTool(
+		"touch",
+		mcp.WithDescription("Create an empty file if it does not exist and set its modification and access times, like the touch command. Existing content is never changed."),
+		mcp.WithString("path",
+			mcp.Description("Path of the file or directory"),
 			mcp.Required(),
 		),
+		mcp.WithString("mtime",
+			mcp.Description("Modification time to set, as RFC 3339 such as 2024-05-01T12:00:00Z or Unix seconds (default: now, or unchanged when only atime is given)"),
+		),
+		mcp.WithString("atime",
+			mcp.Description("Access time to set, as RFC 3339 or Unix seconds (default: now, or unchanged when only mtime is given)"),
+		),
+		mcp.WithBoolean("no_create",
+			mcp.Description("Fail with NOT_FOUND instead of creating a missing file (default: false)"),
+		),
+		mcp.WithBoolean("create_parents",
+			mcp.Description("Create missing parent directories of the file (default: false)"),
+		),
+	), h.HandleTouch)
+
+	addTool(ToolGroupWrite, mcp.NewTool(
+		"set_file_times",
+		mcp.WithDescription("Set the modification and access times of an existing file or directory, e.g. for timestamp-sensitive builds and tests. Unlike touch, it never creates a file."),
+		mcp.WithString("path",
+			mcp.Description("Path of the file or directory"),
+			mcp.Required(),
+		),
+		mcp.WithString("mtime",
+			mcp.Description("Modification time to set, as RFC 3339 such as 2024-05-01T12:00:00Z or Unix seconds (default: unchanged)"),
+		),
+		mcp.WithString("atime",
+			mcp.Description("Access time to set, as RFC 3339 or Unix seconds (default: unchanged)"),
+		),
+	), h.HandleSetFileTimes)
+
+	addTool(ToolGroupWrite, mcp.NewTool(
+		"copy_file",
+		mcp.WithDescription("Copy files and directories. With sources or glob, every source is copied into the destination directory under its own name and the result lists the outcome of each one."),
+		mcp.WithString("source",
+			mcp.Description("Source path of the file or directory, or with glob the directory to match it under"),
+		),
 		mcp.WithString("destination",
-			mcp.Description("Destination path"),
+			mcp.Description("Destination path, or the destination directory with sources or glob"),
 			mcp.Required(),
 		),
+		mcp.WithBoolean("create_parents",
+			mcp.Description("Create missing parent directories of the destination (default: false)"),
+		),
+		mcp.WithArray("sources",
+			mcp.Description("Paths to copy into the destination directory"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithString("glob",
+			mcp.Description("Copy every file under the source directory whose relative path matches this pattern, e.g. '*.pdf' or '**/*.pdf'"),
+		),
+		mcp.WithString("on_conflict",
+			mcp.Description("What to do when the destination already exists: overwrite (default), fail, or auto_suffix to pick a free name such as 'report (1).pdf'"),
+			mcp.Enum(handler.ConflictOverwrite, handler.ConflictFail, handler.ConflictAutoSuffix),
+		),
 	), h.HandleCopyFile)
 
-	s.AddTool(mcp.NewTool(
-		"move_file",
-		mcp.WithDescription("Move or rename files and directories."),
+	addTool(ToolGroupWrite, mcp.NewTool(
+		"sync_directories",
+		mcp.WithDescription("Mirror a source directory to a destination: copy new files, update changed ones and, with delete, remove what the source lacks. Copies keep the source's modification times, so a repeated sync only transfers what changed. Sends progress notifications when the request carries a progress token."),
 		mcp.WithString("source",
-			mcp.Description("Source path of the file or directory"),
+			mcp.Description("Directory to mirror"),
+			mcp.Required(),
+		),
+		mcp.WithString("destination",
+			mcp.Description("Directory to bring in line with the source; created if missing"),
+			mcp.Required(),
+		),
+		mcp.WithString("compare",
+			mcp.Description("How changed files are found: metadata (default, size and modification time), size, or hash (size, then SHA-256 of the content)"),
+			mcp.Enum(handler.CompareMetadata, handler.CompareSize, handler.CompareHash),
+		),
+		mcp.WithBoolean("delete",
+			mcp.Description("Delete files and directories in the destination that the source lacks (default: false)"),
+		),
+		mcp.WithBoolean("trash",
+			mcp.Description("With delete, move extraneous items to the trash instead of deleting them permanently (default: false)"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("List the changes without making them (default: false)"),
+		),
+		mcp.WithBoolean("create_parents",
+			mcp.Description("Create missing parent directories of the destination (default: false)"),
+		),
+	), h.HandleSyncDirectories)
+
+	addTool(ToolGroupWrite, mcp.NewTool(
+		"scaffold",
+		mcp.WithDescription("Create files and directories from a template in the server's templates directory, replacing {{variable}} placeholders in their names and text content. Call list_templates for the templates and the variables each needs."),
+		mcp.WithString("template",
+			mcp.Description("Name of the template"),
+			mcp.Required(),
+		),
+		mcp.WithString("destination",
+			mcp.Description("Directory to create the files in"),
 			mcp.Required(),
 		),
+		mcp.WithObject("variables",
+			mcp.Description("Values for the template's placeholders, e.g. {\"project_name\": \"billing\"}"),
+		),
+		mcp.WithBoolean("overwrite",
+			mcp.Description("Replace files that already exist in the destination (default: false)"),
+		),
+		mcp.WithBoolean("create_parents",
+			mcp.Description("Create missing parent directories of the destination (default: false)"),
+		),
+	), h.HandleScaffold)
+
+	addTool(ToolGroupWrite, mcp.NewTool(
+		"move_file",
+		mcp.WithDescription("Move or rename files and directories. With sources or glob, every source is moved into the destination directory under its own name and the result lists the outcome of each one."),
+		mcp.WithString("source",
+			mcp.Description("Source path of the file or directory, or with glob the directory to match it under"),
+		),
 		mcp.WithString("destination",
-			mcp.Description("Destination path"),
+			mcp.Description("Destination path, or the destination directory with sources or glob"),
 			mcp.Required(),
 		),
+		mcp.WithBoolean("create_parents",
+			mcp.Description("Create missing parent directories of the destination (default: false)"),
+		),
+		mcp.WithArray("sources",
+			mcp.Description("Paths to move into the destination directory"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithString("glob",
+			mcp.Description("Move every file under the source directory whose relative path matches this pattern, e.g. '*.pdf' or '**/*.pdf'"),
+		),
+		mcp.WithString("on_conflict",
+			mcp.Description("What to do when the destination already exists: overwrite (default), fail, or auto_suffix to pick a free name such as 'report (1).pdf'"),
+			mcp.Enum(handler.ConflictOverwrite, handler.ConflictFail, handler.ConflictAutoSuffix),
+		),
 	), h.HandleMoveFile)
 
-	s.AddTool(mcp.NewTool(
+	addTool(ToolGroupWrite, mcp.NewTool(
+		"bulk_rename",
+		mcp.WithDescription("Rename every file in a directory whose name matches a glob or regular expression, building each new name from a template, e.g. regex 'IMG_(\\d+)\\.jpeg' with template 'photo-$1.jpg'. Every new name is checked before any file is renamed; use dry_run to preview."),
+		mcp.WithString("path",
+			mcp.Description("Directory holding the files to rename"),
+			mcp.Required(),
+		),
+		mcp.WithString("template",
+			mcp.Description("New name of each file. {name}, {stem} and {ext} stand for the current name, without and with only its extension, {n} for a counter from 1 ({n:3} pads it to 3 digits), and with regex $1 or ${group} for capture groups"),
+			mcp.Required(),
+		),
+		mcp.WithString("glob",
+			mcp.Description("Rename the files whose names match this glob, such as '*.jpeg'"),
+		),
+		mcp.WithString("regex",
+			mcp.Description("Rename the files whose whole name matches this regular expression, whose groups the template can use"),
+		),
+		mcp.WithBoolean("recursive",
+			mcp.Description("Also rename matching files in subdirectories, each within its own directory (default: false)"),
+		),
+		mcp.WithString("on_conflict",
+			mcp.Description("What to do when a new name is taken: fail (default), overwrite, or auto_suffix to pick a free name such as 'photo (1).jpg'"),
+			mcp.Enum(handler.ConflictFail, handler.ConflictOverwrite, handler.ConflictAutoSuffix),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Only list the renames without making them (default: false)"),
+		),
+	), h.HandleBulkRename)
+
+	addTool(ToolGroupWrite, mcp.NewTool(
+		"apply_permissions",
+		mcp.WithDescription("Set the permission modes of a file or of everything in a directory tree, like chmod -R, with separate modes for files and directories. Symbolic links are left alone."),
+		mcp.WithString("path",
+			mcp.Description("File or directory to start from"),
+			mcp.Required(),
+		),
+		mcp.WithString("file_mode",
+			mcp.Description("Octal mode for files, e.g. '644'"),
+		),
+		mcp.WithString("dir_mode",
+			mcp.Description("Octal mode for directories, including path itself, e.g. '755'"),
+		),
+		mcp.WithString("glob",
+			mcp.Description("Only change entries whose path relative to path matches this pattern, e.g. '**/*.sh'"),
+		),
+		mcp.WithString("exclude",
+			mcp.Description("Skip entries whose relative path matches this pattern; a matching directory is skipped with its contents, e.g. '.git'"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("List the changes without making them (default: false)"),
+		),
+	), h.HandleApplyPermissions)
+
+	addTool(ToolGroupWrite, mcp.NewTool(
+		"set_permissions",
+		mcp.WithDescription("Set the permission mode of a single file or directory, like chmod, e.g. to make a generated script executable or to restrict a secret to its owner with 600. Use apply_permissions for whole trees."),
+		mcp.WithString("path",
+			mcp.Description("File or directory to change"),
+			mcp.Required(),
+		),
+		mcp.WithString("mode",
+			mcp.Description("Octal mode, e.g. '755' or '0600'"),
+			mcp.Required(),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Report the change without making it (default: false)"),
+		),
+	), h.HandleSetPermissions)
+
+	addTool(ToolGroupWrite, mcp.NewTool(
+		"set_owner",
+		mcp.WithDescription("Change the owner and group of a file or directory, like chown, e.g. so generated files belong to a service user. Changing the owner usually needs the server to run as root. Not supported on Windows."),
+		mcp.WithString("path",
+			mcp.Description("File or directory to change"),
+			mcp.Required(),
+		),
+		mcp.WithString("owner",
+			mcp.Description("User name or numeric uid; left unchanged when omitted"),
+		),
+		mcp.WithString("group",
+			mcp.Description("Group name or numeric gid; left unchanged when omitted"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Report the change without making it (default: false)"),
+		),
+	), h.HandleSetOwner)
+
+	addTool(ToolGroupWrite, mcp.NewTool(
+		"set_xattr",
+		mcp.WithDescription("Set or remove an extended attribute of a file or directory on Linux or macOS, e.g. to tag a file or strip com.apple.quarantine from received files. Only attributes in the user. namespace can be changed, plus com.apple. on macOS."),
+		mcp.WithString("path",
+			mcp.Description("Path to the file or directory"),
+			mcp.Required(),
+		),
+		mcp.WithString("name",
+			mcp.Description("Attribute name, e.g. 'user.tag'"),
+			mcp.Required(),
+		),
+		mcp.WithString("value",
+			mcp.Description("Value to set"),
+		),
+		mcp.WithString("encoding",
+			mcp.Description("Encoding of value: utf-8 or base64 for binary values (default: utf-8)"),
+			mcp.Enum(handler.EncodingUTF8, handler.EncodingBase64),
+		),
+		mcp.WithBoolean("remove",
+			mcp.Description("Remove the attribute instead of setting it (default: false)"),
+		),
+	), h.HandleSetXattr)
+
+	addTool(ToolGroupSearch, mcp.NewTool(
 		"search_files",
 		mcp.WithDescription("Recursively search for files and directories matching a pattern."),
 		mcp.WithString("path",
@@ -106,35 +649,278 @@ func NewFilesystemServer(allowedDirs []string) (*server.MCPServer, error) {
 			mcp.Description("Search pattern to match against file names"),
 			mcp.Required(),
 		),
+		mcp.WithString("mode",
+			mcp.Description("How pattern matches names: glob (default, e.g. '*.py'), regex (e.g. '^test_.*\\.py$', matching anywhere in the name unless anchored) or substring"),
+			mcp.Enum(handler.SearchModeGlob, handler.SearchModeRegex, handler.SearchModeSubstring),
+		),
+		mcp.WithBoolean("case_sensitive",
+			mcp.Description("Whether matching is case-sensitive (default: true)"),
+		),
+		mcp.WithArray("exclude",
+			mcp.Description("Glob patterns of entries to skip, matched against names and paths relative to path, such as [\".git\", \"dist\", \"**/*.min.js\"]; matching directories are not entered"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithBoolean("respect_gitignore",
+			mcp.Description("Skip paths excluded by .gitignore files, and the .git directory (default: false)"),
+		),
 	), h.HandleSearchFiles)
 
-	s.AddTool(mcp.NewTool(
+	addTool(ToolGroupSearch, mcp.NewTool(
+		"find_files",
+		mcp.WithDescription("Walk a directory like find and return a flat list of the entries matching every given criterion: a name glob, a type, a size range and a modification time range. Symbolic links are not followed."),
+		mcp.WithString("path",
+			mcp.Description("Directory to search"),
+			mcp.Required(),
+		),
+		mcp.WithString("name",
+			mcp.Description("Only include entries whose name matches this glob, such as '*.log'"),
+		),
+		mcp.WithBoolean("case_sensitive",
+			mcp.Description("Whether name matching is case-sensitive (default: true)"),
+		),
+		mcp.WithString("type",
+			mcp.Description("Only include entries of this type (default: any)"),
+			mcp.Enum(handler.FindTypeAny, handler.FindTypeFile, handler.FindTypeDirectory, handler.FindTypeSymlink),
+		),
+		mcp.WithNumber("min_size",
+			mcp.Description("Only include files of at least this many bytes"),
+		),
+		mcp.WithNumber("max_size",
+			mcp.Description("Only include files of at most this many bytes"),
+		),
+		mcp.WithString("modified_after",
+			mcp.Description("Only include entries modified after this time, in RFC 3339 or Unix seconds"),
+		),
+		mcp.WithString("modified_before",
+			mcp.Description("Only include entries modified before this time, in RFC 3339 or Unix seconds"),
+		),
+		mcp.WithNumber("max_depth",
+			mcp.Description("How many levels below path to search; 1 is path's own entries (default: no limit)"),
+		),
+		mcp.WithNumber("max_results",
+			mcp.Description("Stop after this many entries (default: 1000, at most 10000)"),
+		),
+		mcp.WithArray("exclude",
+			mcp.Description("Glob patterns of entries to skip, matched against names and paths relative to path, such as [\".git\", \"dist\", \"**/*.min.js\"]; matching directories are not entered"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithBoolean("respect_gitignore",
+			mcp.Description("Skip paths excluded by .gitignore files, and the .git directory (default: false)"),
+		),
+	), h.HandleFindFiles)
+
+	addTool(ToolGroupSearch, mcp.NewTool(
+		"find_duplicates",
+		mcp.WithDescription("Find files with identical content under a directory: files are grouped by size, then by a hash of the content, and each set of duplicates is reported with the bytes keeping one copy would free, largest first. Symbolic links are not followed and hard links to the same file count once."),
+		mcp.WithString("path",
+			mcp.Description("Directory to search"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("min_size",
+			mcp.Description("Only compare files of at least this many bytes (default: 1, skipping empty files)"),
+		),
+		mcp.WithNumber("max_depth",
+			mcp.Description("How many levels below path to search; 1 is path's own files (default: no limit)"),
+		),
+		mcp.WithString("algorithm",
+			mcp.Description("Hash used to compare contents (default: sha256; xxhash is faster)"),
+			mcp.Enum(handler.HashAlgorithms...),
+		),
+		mcp.WithNumber("max_groups",
+			mcp.Description("How many sets of duplicates to list (default: 100, at most 1000)"),
+		),
+		mcp.WithArray("exclude",
+			mcp.Description("Glob patterns of entries to skip, matched against names and paths relative to path; matching directories are not entered"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithBoolean("respect_gitignore",
+			mcp.Description("Skip paths excluded by .gitignore files, and the .git directory (default: false)"),
+		),
+	), h.HandleFindDuplicates)
+
+	addTool(ToolGroupRead, mcp.NewTool(
+		"read_file_head_tail",
+		mcp.WithDescription("Read the first and last lines of a text file, like head and tail, without reading the middle. Suited to inspecting very large log files."),
+		mcp.WithString("path",
+			mcp.Description("Path to the file"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("head",
+			mcp.Description("Number of lines from the start (default: 10, or 0 when only tail is given)"),
+		),
+		mcp.WithNumber("tail",
+			mcp.Description("Number of lines from the end (default: 10, or 0 when only head is given)"),
+		),
+	), h.HandleReadFileHeadTail)
+
+	addTool(ToolGroupRead, mcp.NewTool(
+		"preview_file",
+		mcp.WithDescription("Preview a file for triage without reading all of it: MIME type, size and modification time, plus the first lines of a text file, the dimensions and a small thumbnail of an image, or the entry count and first entries of a zip or tar archive."),
+		mcp.WithString("path",
+			mcp.Description("Path to the file"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("lines",
+			mcp.Description("Number of lines to show for text files (default: 20, at most 500)"),
+		),
+		mcp.WithBoolean("thumbnail",
+			mcp.Description("Include a PNG thumbnail of PNG, JPEG and GIF images (default: true)"),
+		),
+	), h.HandlePreviewFile)
+
+	addTool(ToolGroupRead, mcp.NewTool(
+		"detect_file_type",
+		mcp.WithDescription("Identify a file from its content rather than its extension: MIME type, whether it is text or binary, and for text its encoding and line-ending style. Flags files whose extension does not match their content."),
+		mcp.WithString("path",
+			mcp.Description("Path to the file"),
+			mcp.Required(),
+		),
+	), h.HandleDetectFileType)
+
+	addTool(ToolGroupRead, mcp.NewTool(
+		"stat_paths",
+		mcp.WithDescription("Check many paths in one call: for each, whether it is within the allowed directories and writable, whether it exists, and its type, size and modification time. Missing and denied paths are reported, not treated as errors."),
+		mcp.WithArray("paths",
+			mcp.Description("Paths to check"),
+			mcp.Required(),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+	), h.HandleStatPaths)
+
+	addTool(ToolGroupRead, mcp.NewTool(
 		"get_file_info",
-		mcp.WithDescription("Retrieve detailed metadata about a file or directory."),
+		mcp.WithDescription("Retrieve detailed metadata about a file or directory: size, times, permissions, content-sniffed MIME type, symlink target and, for files, whether they look binary, their line count and optionally a checksum. A symlink whose target is missing or outside the allowed directories is described without being followed."),
 		mcp.WithString("path",
 			mcp.Description("Path to the file or directory"),
 			mcp.Required(),
 		),
+		mcp.WithBoolean("checksum",
+			mcp.Description("Also compute a digest of a file's content, with checksum_algorithm (default: false)"),
+		),
+		mcp.WithString("checksum_algorithm",
+			mcp.Description("Algorithm of the checksum; giving it implies checksum (default: sha256)"),
+			mcp.Enum(handler.HashAlgorithms...),
+		),
+		mcp.WithBoolean("sha256",
+			mcp.Description("Same as checksum with sha256 (default: false)"),
+		),
 	), h.HandleGetFileInfo)
 
-	s.AddTool(mcp.NewTool(
+	addTool(ToolGroupRead, mcp.NewTool(
+		"hash_file",
+		mcp.WithDescription("Compute the checksum of a file, reading it as a stream so size does not matter. With expected, verify the file against a known digest, e.g. after a croc transfer; a mismatch fails with VERIFY_FAILED."),
+		mcp.WithString("path",
+			mcp.Description("Path to the file"),
+			mcp.Required(),
+		),
+		mcp.WithString("algorithm",
+			mcp.Description("Hash algorithm (default: sha256, or the one an expected digest's length implies)"),
+			mcp.Enum(handler.HashAlgorithms...),
+		),
+		mcp.WithString("expected",
+			mcp.Description("Hex digest to verify the file against, optionally prefixed with its algorithm as in 'sha256:ab12...'"),
+		),
+	), h.HandleHashFile)
+
+	addTool(ToolGroupRead, mcp.NewTool(
+		"get_xattr",
+		mcp.WithDescription("Read the extended attributes of a file or directory on Linux or macOS, such as com.apple.quarantine or user.* tags. Values that are not printable text are returned in base64."),
+		mcp.WithString("path",
+			mcp.Description("Path to the file or directory"),
+			mcp.Required(),
+		),
+		mcp.WithString("name",
+			mcp.Description("Attribute to read, e.g. 'user.origin'; omit to read them all"),
+		),
+	), h.HandleGetXattr)
+
+	addTool(ToolGroupRead, mcp.NewTool(
+		"read_link",
+		mcp.WithDescription("Read where a symbolic link points without following it: its target as stored, the absolute path it resolves to, whether that exists and whether it is outside the allowed directories."),
+		mcp.WithString("path",
+			mcp.Description("Path of the symbolic link"),
+			mcp.Required(),
+		),
+	), h.HandleReadLink)
+
+	addTool(ToolGroupRead, mcp.NewTool(
 		"list_allowed_directories",
-		mcp.WithDescription("Returns the list of directories that this server is allowed to access."),
+		mcp.WithDescription("Returns the list of directories that this server is allowed to access, the base directory for relative paths and the directory aliases (such as @inbox) that can start any path argument."),
 	), h.HandleListAllowedDirectories)
 
-	s.AddTool(mcp.NewTool(
+	addTool(ToolGroupRead, mcp.NewTool(
+		"set_base_directory",
+		mcp.WithDescription("Set the directory that relative paths such as 'src/main.go' resolve against for the rest of this session. It must be within the allowed directories. Call without a path to go back to the server's base directory."),
+		mcp.WithString("path",
+			mcp.Description("Directory to resolve relative paths against; empty restores the server default"),
+		),
+	), h.HandleSetBaseDirectory)
+
+	addTool(ToolGroupRead, mcp.NewTool(
+		"continue_result",
+		mcp.WithDescription("Get the next part of a tool response that was truncated for being too large. The truncated response ends with the token to pass."),
+		mcp.WithString("token",
+			mcp.Description("Continuation token from the end of the truncated response"),
+			mcp.Required(),
+		),
+	), h.HandleContinueResult)
+
+	addTool(ToolGroupWrite, mcp.NewTool(
+		"get_scratch_dir",
+		mcp.WithDescription("Get a writable scratch directory for intermediate files, private to this session. It is created on first use, limited by a quota and removed when the session ends or after a period without use, so keep nothing there that must last."),
+	), h.HandleGetScratchDir)
+
+	addTool(ToolGroupWrite, mcp.NewTool(
+		"journal_append",
+		mcp.WithDescription("Append a timestamped entry to a journal kept by the server, a durable progress log and scratchpad for multi-step work that survives context resets. Entries can only be appended, and journals are stored outside the allowed directories. Read them back with journal_read."),
+		mcp.WithString("message",
+			mcp.Description("Text of the entry"),
+			mcp.Required(),
+		),
+		mcp.WithString("kind",
+			mcp.Description("Kind of entry, such as note, progress, decision or todo (default: note)"),
+		),
+		mcp.WithObject("data",
+			mcp.Description("Structured details to store with the entry, e.g. {\"step\": 3, \"files\": [\"a.csv\"]}"),
+		),
+		mcp.WithString("journal",
+			mcp.Description("Name of the journal (default: the journal of this session). Use a fixed name to continue a journal across sessions."),
+		),
+	), h.HandleJournalAppend)
+
+	addTool(ToolGroupRead, mcp.NewTool(
+		"journal_read",
+		mcp.WithDescription("Read the latest entries of a journal written with journal_append, oldest first."),
+		mcp.WithString("journal",
+			mcp.Description("Name of the journal (default: the journal of this session)"),
+		),
+		mcp.WithNumber("tail",
+			mcp.Description("Number of entries to return (default: 50)"),
+		),
+		mcp.WithString("kind",
+			mcp.Description("Only return entries of this kind"),
+		),
+	), h.HandleJournalRead)
+
+	addTool(ToolGroupRead, mcp.NewTool(
 		"read_multiple_files",
-		mcp.WithDescription("Read the contents of multiple files in a single operation."),
+		mcp.WithDescription("Read the contents of multiple files in a single operation. Paths may be glob patterns such as src/**/*.go, expanded to the matching files; at most 50 files are read per call."),
 		mcp.WithArray("paths",
-			mcp.Description("List of file paths to read"),
+			mcp.Description("List of file paths or glob patterns to read"),
 			mcp.Required(),
 			mcp.Items(map[string]any{"type": "string"}),
 		),
+		mcp.WithNumber("max_file_size",
+			mcp.Description("Bytes to return at most per file; longer text files are cut with a marker telling where to continue (default and maximum: the server's max_inline_size)"),
+		),
+		mcp.WithNumber("max_total_size",
+			mcp.Description("Bytes to return at most for all files together; files past it are listed as not read (default and maximum: the server's max_batch_read_size)"),
+		),
 	), h.HandleReadMultipleFiles)
 
-	s.AddTool(mcp.NewTool(
+	addTool(ToolGroupRead, mcp.NewTool(
 		"tree",
-		mcp.WithDescription("Returns a hierarchical JSON representation of a directory structure."),
+		mcp.WithDescription("Returns a hierarchical representation of a directory structure with the size and modification time of each node: JSON (text and json formats), an ASCII tree, or a Mermaid or Graphviz diagram."),
 		mcp.WithString("path",
 			mcp.Description("Path of the directory to traverse"),
 			mcp.Required(),
@@ -145,46 +931,368 @@ func NewFilesystemServer(allowedDirs []string) (*server.MCPServer, error) {
 		mcp.WithBoolean("follow_symlinks",
 			mcp.Description("Whether to follow symbolic links (default: false)"),
 		),
+		mcp.WithBoolean("rollup",
+			mcp.Description("Add recursive totals to each directory: file count, total size and largest file, counting files below the depth cutoff too (default: false)"),
+		),
+		mcp.WithArray("exclude",
+			mcp.Description("Glob patterns of entries to skip, matched against names and paths relative to path, such as [\".git\", \"dist\", \"**/*.min.js\"]; matching directories are not entered"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithBoolean("respect_gitignore",
+			mcp.Description("Skip paths excluded by .gitignore files, and the .git directory (default: false)"),
+		),
 	), h.HandleTree)
 
-	s.AddTool(mcp.NewTool(
+	addTool(ToolGroupWrite, mcp.NewTool(
 		"delete_file",
-		mcp.WithDescription("Delete a file or directory from the file system."),
+		mcp.WithDescription("Delete a file or directory from the file system. With glob, the first call only lists the matching paths and returns a confirmation token; a second call with the same path, glob and confirm_token deletes them."),
 		mcp.WithString("path",
-			mcp.Description("Path to the file or directory to delete"),
+			mcp.Description("Path to the file or directory to delete, or the directory to match glob under"),
 			mcp.Required(),
 		),
 		mcp.WithBoolean("recursive",
 			mcp.Description("Whether to recursively delete directories (default: false)"),
 		),
+		mcp.WithBoolean("trash",
+			mcp.Description("Move the item to the trash instead of deleting it permanently, so it can be recovered with restore_from_trash (default: false)"),
+		),
+		mcp.WithString("glob",
+			mcp.Description("Delete every path under the path directory whose relative path matches this pattern, e.g. '**/*.log'. Matching directories are only included with recursive=true"),
+		),
+		mcp.WithString("confirm_token",
+			mcp.Description("Token returned by a previous delete_file call with the same path and glob, confirming the listed paths should be deleted"),
+		),
+		mcp.WithBoolean("backup",
+			mcp.Description("Copy a file to its backup before deleting it, restorable with restore_backup; directories need trash instead (default: the server's file_backups setting, else false)"),
+		),
 	), h.HandleDeleteFile)
 
-	s.AddTool(mcp.NewTool(
+	addTool(ToolGroupTrash, mcp.NewTool(
+		"list_trash",
+		mcp.WithDescription("List items moved to the trash by delete_file (and, when enabled, items in the desktop XDG trash that came from allowed directories), newest first."),
+		mcp.WithString("path",
+			mcp.Description("Only list items originally located under this path"),
+		),
+	), h.HandleListTrash)
+
+	addTool(ToolGroupTrash, mcp.NewTool(
+		"restore_from_trash",
+		mcp.WithDescription("Restore an item from the trash to its original location or to a chosen destination."),
+		mcp.WithString("id",
+			mcp.Description("Trash entry id as reported by list_trash or delete_file"),
+			mcp.Required(),
+		),
+		mcp.WithString("destination",
+			mcp.Description("Restore to this path instead of the original location"),
+		),
+		mcp.WithBoolean("overwrite",
+			mcp.Description("Replace an existing file or directory at the target (default: false)"),
+		),
+	), h.HandleRestoreFromTrash)
+
+	addTool(ToolGroupTrash, mcp.NewTool(
+		"empty_trash",
+		mcp.WithDescription("Permanently delete items from the trash: one by id, those originally under a path, or those trashed more than some days ago. Emptying the whole trash needs all=true. Items deleted this way cannot be restored."),
+		mcp.WithString("id",
+			mcp.Description("Trash entry id as reported by list_trash or delete_file"),
+		),
+		mcp.WithString("path",
+			mcp.Description("Only delete items originally located under this path"),
+		),
+		mcp.WithNumber("older_than_days",
+			mcp.Description("Only delete items trashed more than this many days ago"),
+		),
+		mcp.WithBoolean("all",
+			mcp.Description("Delete every item in the trash when no other filter is given (default: false)"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Only list what would be deleted (default: false)"),
+		),
+	), h.HandleEmptyTrash)
+
+	addTool(ToolGroupTrash, mcp.NewTool(
+		"list_versions",
+		mcp.WithDescription("List the earlier versions kept of a file overwritten by write_file or modify_file, newest first. Versioning is enabled by the server's keep_versions setting."),
+		mcp.WithString("path",
+			mcp.Description("Path of the file"),
+			mcp.Required(),
+		),
+	), h.HandleListVersions)
+
+	addTool(ToolGroupTrash, mcp.NewTool(
+		"restore_version",
+		mcp.WithDescription("Restore a file to an earlier version listed by list_versions. The current content is kept as a version, so the restore can be undone."),
+		mcp.WithString("path",
+			mcp.Description("Path of the file"),
+			mcp.Required(),
+		),
+		mcp.WithString("id",
+			mcp.Description("Version id as reported by list_versions"),
+			mcp.Required(),
+		),
+	), h.HandleRestoreVersion)
+
+	addTool(ToolGroupTrash, mcp.NewTool(
+		"restore_backup",
+		mcp.WithDescription("Put back the backup made by the backup option of write_file, modify_file, edit_lines or delete_file, replacing the file or recreating it if it was deleted. The backup is removed once restored."),
+		mcp.WithString("path",
+			mcp.Description("Path of the original file"),
+			mcp.Required(),
+		),
+	), h.HandleRestoreBackup)
+
+	addTool(ToolGroupWrite, mcp.NewTool(
 		"modify_file",
-		mcp.WithDescription("Update file by finding and replacing text. Provides a simple pattern matching interface without needing exact character positions."),
+		mcp.WithDescription("Update file by finding and replacing text. Provides a simple pattern matching interface without needing exact character positions. Several related replacements can be made at once with edits: they are applied in order and the file is only written if every edit matches."),
 		mcp.WithString("path",
 			mcp.Description("Path to the file to modify"),
 			mcp.Required(),
 		),
 		mcp.WithString("find",
-			mcp.Description("Text to search for (exact match or regex pattern)"),
-			mcp.Required(),
+			mcp.Description("Text to search for (exact match or regex pattern); required unless edits is given"),
 		),
 		mcp.WithString("replace",
-			mcp.Description("Text to replace with"),
-			mcp.Required(),
+			mcp.Description("Text to replace with; required unless edits is given"),
+		),
+		mcp.WithArray("edits",
+			mcp.Description("Replacements to apply in order instead of find and replace, each with find, replace and optional regex, all_occurrences and occurrence"),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"find":            map[string]any{"type": "string"},
+					"replace":         map[string]any{"type": "string"},
+					"regex":           map[string]any{"type": "boolean"},
+					"all_occurrences": map[string]any{"type": "boolean"},
+					"occurrence":      map[string]any{"type": "integer", "minimum": 1},
+				},
+				"required": []string{"find", "replace"},
+			}),
 		),
 		mcp.WithBoolean("all_occurrences",
 			mcp.Description("Replace all occurrences of the matching text (default: true)"),
 		),
+		mcp.WithNumber("occurrence",
+			mcp.Description("Replace only this match, counting from 1; fails if there are fewer matches (overrides all_occurrences)"),
+		),
 		mcp.WithBoolean("regex",
 			mcp.Description("Treat the find pattern as a regular expression (default: false)"),
 		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Only return the number of matches and a unified diff of the change, without writing the file (default: false)"),
+		),
+		mcp.WithBoolean("verify",
+			mcp.Description("Sync the file to disk and read it back to check it matches before returning (default: false)"),
+		),
+		mcp.WithBoolean("backup",
+			mcp.Description("Copy the current file to its backup first, restorable with restore_backup (default: the server's file_backups setting, else false)"),
+		),
 	), h.HandleModifyFile)
 
-	s.AddTool(mcp.NewTool(
+	addTool(ToolGroupWrite, mcp.NewTool(
+		"edit_lines",
+		mcp.WithDescription("Insert text before a line, replace a range of lines, or delete a range of lines, using the line numbers shown by read_file with line_numbers. The new lines take the file's line ending, and the result shows them with their numbers."),
+		mcp.WithString("path",
+			mcp.Description("Path to the text file to edit"),
+			mcp.Required(),
+		),
+		mcp.WithString("operation",
+			mcp.Description("insert, replace or delete"),
+			mcp.Enum(handler.LineInsert, handler.LineReplace, handler.LineDelete),
+			mcp.Required(),
+		),
+		mcp.WithNumber("start_line",
+			mcp.Description("First line to replace or delete, counting from 1, or the line to insert before; one past the last line appends"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("end_line",
+			mcp.Description("Last line to replace or delete, inclusive (default: start_line)"),
+		),
+		mcp.WithString("content",
+			mcp.Description("Text to insert or to replace the lines with; required for insert and replace"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Only return a unified diff of the change, without writing the file (default: false)"),
+		),
+		mcp.WithBoolean("verify",
+			mcp.Description("Sync the file to disk and read it back to check it matches before returning (default: false)"),
+		),
+		mcp.WithBoolean("backup",
+			mcp.Description("Copy the current file to its backup first, restorable with restore_backup (default: the server's file_backups setting, else false)"),
+		),
+	), h.HandleEditLines)
+
+	addTool(ToolGroupWrite, mcp.NewTool(
+		"apply_patch",
+		mcp.WithDescription("Apply a unified diff, such as the output of diff -u or git diff, to one or more files. Hunks that moved are found nearby, and fuzz lets a few context lines differ. Either every file is patched or none is."),
+		mcp.WithString("patch",
+			mcp.Description("The unified diff. Files are created from /dev/null, deleted to /dev/null and renamed when their --- and +++ paths differ."),
+			mcp.Required(),
+		),
+		mcp.WithString("path",
+			mcp.Description("Directory the paths in the patch are relative to (default: the base directory)"),
+		),
+		mcp.WithNumber("strip",
+			mcp.Description("Leading path components to remove from the paths in the patch, like patch -p (default: 1 for git-style a/ and b/ paths, else 0)"),
+		),
+		mcp.WithNumber("fuzz",
+			mcp.Description("Context lines at either end of a hunk that may be ignored when it does not apply as is (default: 2, at most 3)"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Only report what would change, with a unified diff per file, without writing (default: false)"),
+		),
+	), h.HandleApplyPatch)
+
+	addTool(ToolGroupRead, mcp.NewTool(
+		"extract_document_text",
+		mcp.WithDescription("Extract plain text with basic structure (headings, list items, tables) from .docx and .epub files natively, without a full document conversion."),
+		mcp.WithString("path",
+			mcp.Description("Path to the .docx or .epub file"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("max_length",
+			mcp.Description("Maximum number of bytes of text to return, capped at the inline size limit (default: that limit)"),
+		),
+	), h.HandleExtractDocumentText)
+
+	addTool(ToolGroupRead, mcp.NewTool(
+		"list_templates",
+		mcp.WithDescription("List the templates scaffold can instantiate, with the variables each one uses."),
+	), h.HandleListTemplates)
+
+	addTool(ToolGroupRead, mcp.NewTool(
+		"compare_directories",
+		mcp.WithDescription("Compare two directory trees and report the paths found only in the first, only in the second, and present in both but differing. Use compare=hash to verify transferred or backed-up copies, whose modification times usually differ."),
+		mcp.WithString("path_a",
+			mcp.Description("First directory"),
+			mcp.Required(),
+		),
+		mcp.WithString("path_b",
+			mcp.Description("Second directory"),
+			mcp.Required(),
+		),
+		mcp.WithString("compare",
+			mcp.Description("How files are compared: metadata (default, size and modification time), size, or hash (size, then SHA-256 of the content)"),
+			mcp.Enum(handler.CompareMetadata, handler.CompareSize, handler.CompareHash),
+		),
+		mcp.WithBoolean("diff",
+			mcp.Description("Include a unified diff for each differing text file of up to 64 KB (default: false)"),
+		),
+	), h.HandleCompareDirectories)
+
+	addTool(ToolGroupRead, mcp.NewTool(
+		"workspace_stats",
+		mcp.WithDescription("Summarize a directory in one call: total files and bytes, file counts and sizes by extension, the largest files and the deepest paths."),
+		mcp.WithString("path",
+			mcp.Description("Directory to summarize"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("top",
+			mcp.Description("How many largest files and deepest paths to list (default: 10, at most 100)"),
+		),
+		mcp.WithBoolean("include_hidden",
+			mcp.Description("Include files and directories whose names start with a dot, such as .git (default: false)"),
+		),
+	), h.HandleWorkspaceStats)
+
+	addTool(ToolGroupRead, mcp.NewTool(
+		"top_files",
+		mcp.WithDescription("List the largest or the oldest files under a directory in one call, for storage cleanup. Symbolic links are not followed."),
+		mcp.WithString("path",
+			mcp.Description("Directory to search"),
+			mcp.Required(),
+		),
+		mcp.WithString("by",
+			mcp.Description("Rank by size, largest first, or by mtime, oldest first (default: size)"),
+			mcp.Enum(handler.SortBySize, handler.SortByMtime),
+		),
+		mcp.WithNumber("top",
+			mcp.Description("How many files to list (default: 20, at most 1000)"),
+		),
+		mcp.WithNumber("min_size",
+			mcp.Description("Only rank files of at least this many bytes"),
+		),
+		mcp.WithNumber("max_depth",
+			mcp.Description("How many levels below path to search; 1 is path's own files (default: no limit)"),
+		),
+		mcp.WithArray("exclude",
+			mcp.Description("Glob patterns of entries to skip, matched against names and paths relative to path; matching directories are not entered"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithBoolean("respect_gitignore",
+			mcp.Description("Skip paths excluded by .gitignore files, and the .git directory (default: false)"),
+		),
+	), h.HandleTopFiles)
+
+	addTool(ToolGroupRead, mcp.NewTool(
+		"directory_size",
+		mcp.WithDescription("Measure a directory like du: its total size and file count, and the same for each subdirectory down to depth, largest first. Use it to find what is taking up disk space. Symbolic links are not followed."),
+		mcp.WithString("path",
+			mcp.Description("Directory to measure"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description("How many levels of subdirectories to break the total down by; 0 gives only the total (default: 1, at most 10)"),
+		),
+		mcp.WithString("exclude",
+			mcp.Description("Skip entries whose name or relative path matches this pattern, e.g. '{.git,node_modules}'"),
+		),
+		mcp.WithNumber("top",
+			mcp.Description("How many subdirectories to list (default: 20, at most 500)"),
+		),
+	), h.HandleDirectorySize)
+
+	addTool(ToolGroupRead, mcp.NewTool(
+		"list_volumes",
+		mcp.WithDescription("List the mounted filesystems or drives that back the allowed directories, with filesystem type, capacity, free space and read-only status. Use this to pick a location with enough room before writing or receiving large files."),
+	), h.HandleListVolumes)
+
+	addTool(ToolGroupRead, mcp.NewTool(
+		"disk_usage",
+		mcp.WithDescription("Report the total, used, free and available bytes of the filesystem holding each allowed directory, or a given path. With required_bytes, also say whether that much fits, e.g. before write_file or croc_receive of a large file."),
+		mcp.WithString("path",
+			mcp.Description("Path to check, which need not exist yet (default: every allowed directory)"),
+		),
+		mcp.WithNumber("required_bytes",
+			mcp.Description("Report whether this many bytes are available"),
+		),
+	), h.HandleDiskUsage)
+
+	addTool(ToolGroupMedia, mcp.NewTool(
+		"probe_media",
+		mcp.WithDescription("Inspect a video or audio file with ffprobe and return its container format, duration, size, bitrate and per-stream codec details. Optionally extracts a single-frame PNG thumbnail with ffmpeg."),
+		mcp.WithString("path",
+			mcp.Description("Path to the media file"),
+			mcp.Required(),
+		),
+		mcp.WithBoolean("thumbnail",
+			mcp.Description("Extract a single-frame PNG thumbnail from the first video stream (default: false)"),
+		),
+		mcp.WithNumber("thumbnail_time",
+			mcp.Description("Offset in seconds to take the thumbnail from (default: 10% of the duration)"),
+		),
+		mcp.WithNumber("thumbnail_width",
+			mcp.Description("Thumbnail width in pixels; height keeps the aspect ratio (default: 320)"),
+		),
+		mcp.WithString("thumbnail_path",
+			mcp.Description("Write the thumbnail to this path instead of returning it inline"),
+		),
+	), h.HandleProbeMedia)
+
+	addTool(ToolGroupWrite, mcp.NewTool(
+		"format_file",
+		mcp.WithDescription("Format a source file in place and return a unified diff of the changes. Go files use goimports natively; other languages use configured external formatters (black for Python, prettier for JS/TS/CSS/HTML/JSON/Markdown/YAML by default)."),
+		mcp.WithString("path",
+			mcp.Description("Path to the file to format"),
+			mcp.Required(),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Only return the diff without writing the file (default: false)"),
+		),
+	), h.HandleFormatFile)
+
+	addTool(ToolGroupSearch, mcp.NewTool(
 		"search_within_files",
-		mcp.WithDescription("Search for text within file contents. Unlike search_files which only searches file names, this tool scans the actual contents of text files for matching substrings. Binary files are automatically excluded from the search. Reports file paths and line numbers where matches are found."),
+		mcp.WithDescription("Search for text within file contents. Unlike search_files which only searches file names, this tool scans the actual contents of text files for matching substrings. Binary files are automatically excluded from the search. Reports file paths, line and column numbers and a snippet of each match, optionally with the lines around it; use format json for structured hits."),
 		mcp.WithString("path",
 			mcp.Description("Starting path for the search (must be a directory)"),
 			mcp.Required(),
@@ -199,10 +1307,27 @@ func NewFilesystemServer(allowedDirs []string) (*server.MCPServer, error) {
 		mcp.WithNumber("max_results",
 			mcp.Description("Maximum number of results to return (default: 1000)"),
 		),
+		mcp.WithNumber("context_before",
+			mcp.Description("Lines to return before each match, like grep -B (default: 0, at most 20)"),
+		),
+		mcp.WithNumber("context_after",
+			mcp.Description("Lines to return after each match, like grep -A (default: 0, at most 20)"),
+		),
+		mcp.WithArray("include",
+			mcp.Description("Glob patterns of the files to search, matched against names and paths relative to path, such as [\"*.go\"]; directories are still entered (default: all text files)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithArray("exclude",
+			mcp.Description("Glob patterns of entries to skip, matched against names and paths relative to path, such as [\".git\", \"vendor/**\", \"**/*.min.js\"]; matching directories are not entered"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithBoolean("respect_gitignore",
+			mcp.Description("Skip paths excluded by .gitignore files, and the .git directory (default: false)"),
+		),
 	), h.HandleSearchWithinFiles)
 
 	// Croc file transfer tools
-	s.AddTool(mcp.NewTool(
+	addTool(ToolGroupCroc, mcp.NewTool(
 		"croc_send",
 		mcp.WithDescription(`【客户端·文件传输工具】将本地文件发送到远端服务器。
 
@@ -227,7 +1352,7 @@ func NewFilesystemServer(allowedDirs []string) (*server.MCPServer, error) {
 		),
 	), h.HandleCrocSend)
 
-	s.AddTool(mcp.NewTool(
+	addTool(ToolGroupCroc, mcp.NewTool(
 		"croc_receive",
 		mcp.WithDescription("Receive a file from another machine using croc. Requires the code provided by the sender."),
 		mcp.WithString("code",
@@ -237,14 +1362,18 @@ func NewFilesystemServer(allowedDirs []string) (*server.MCPServer, error) {
 		mcp.WithString("output_dir",
 			mcp.Description("Directory to save the received file (defaults to first allowed directory)"),
 		),
+		mcp.WithString("on_conflict",
+			mcp.Description("What to do when a received file already exists in the output directory: overwrite (default), fail, or auto_suffix to save it under a free name such as 'report (1).pdf'"),
+			mcp.Enum(handler.ConflictOverwrite, handler.ConflictFail, handler.ConflictAutoSuffix),
+		),
 	), h.HandleCrocReceive)
 
-	s.AddTool(mcp.NewTool(
+	addTool(ToolGroupCroc, mcp.NewTool(
 		"croc_status",
 		mcp.WithDescription("List all active croc file transfers and their status."),
 	), h.HandleCrocStatus)
 
-	s.AddTool(mcp.NewTool(
+	addTool(ToolGroupCroc, mcp.NewTool(
 		"croc_cancel",
 		mcp.WithDescription("Cancel an active croc file transfer by its process ID."),
 		mcp.WithNumber("pid",
@@ -253,5 +1382,122 @@ func NewFilesystemServer(allowedDirs []string) (*server.MCPServer, error) {
 		),
 	), h.HandleCrocCancel)
 
+	addTool(ToolGroupConvert, mcp.NewTool(
+		"convert_to_markdown",
+		mcp.WithDescription(`【客户端·一键转换工具】将本地文件转换为 Markdown，并把结果写回允许的目录。
+
+内部自动完成 croc_send → 调用远端 convert-router 的 convert_to_markdown（croc_code）→ 等待完成 → 写入 Markdown 文件，
+无需再分别调用两个服务。远端地址通过 MCP_FS_CONVERT_ROUTER_URL 配置（可选 MCP_FS_CONVERT_ROUTER_TOKEN）。`),
+		mcp.WithString("path",
+			mcp.Description("Path to the local file to convert"),
+			mcp.Required(),
+		),
+		mcp.WithString("output_path",
+			mcp.Description("Where to write the Markdown (default: alongside the source with a .md extension)"),
+		),
+		mcp.WithBoolean("enable_ocr",
+			mcp.Description("Enable OCR for scanned documents and images (default: false)"),
+		),
+		mcp.WithString("language",
+			mcp.Description("OCR language, e.g. 'ch' or 'en'"),
+		),
+		mcp.WithString("page_ranges",
+			mcp.Description("Page ranges to convert (MinerU only), e.g. '2,4-6'"),
+		),
+		mcp.WithNumber("timeout_seconds",
+			mcp.Description("Overall timeout for transfer and conversion (default: 600)"),
+		),
+	), h.HandleConvertToMarkdown)
+
+	// Backup tools
+	addTool(ToolGroupBackup, mcp.NewTool(
+		"backup_directory",
+		mcp.WithDescription("Archive a directory as a .tar.gz (optionally encrypted with the key in MCP_FS_BACKUP_KEY) and ship it to a remote. Every run is recorded in the persistent backup history."),
+		mcp.WithString("path",
+			mcp.Description("Path of the directory to back up"),
+			mcp.Required(),
+		),
+		mcp.WithString("remote",
+			mcp.Description("Destination: an allowed directory path, 'croc', or the name of a remote configured by the operator (an s3:// or sftp:// URL cannot be given directly)"),
+			mcp.Required(),
+		),
+		mcp.WithBoolean("encrypt",
+			mcp.Description("Encrypt the archive with AES-256-GCM (default: false)"),
+		),
+		mcp.WithString("name",
+			mcp.Description("Optional job name recorded in the backup history"),
+		),
+	), h.HandleBackupDirectory)
+
+	addTool(ToolGroupBackup, mcp.NewTool(
+		"backup_history",
+		mcp.WithDescription("List recent backup runs recorded by backup_directory and scheduled backups."),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of runs to return, newest last (default: 20)"),
+		),
+	), h.HandleBackupHistory)
+
+	addTool(ToolGroupRead, mcp.NewTool(
+		"version",
+		mcp.WithDescription("Return the server build information as JSON: version, commit, build date and Go version."),
+	), h.HandleVersion)
+
+	addTool(ToolGroupRead, mcp.NewTool(
+		"telemetry_status",
+		mcp.WithDescription("Report whether anonymous usage statistics are collected and, if so, where they are sent and exactly what the next report contains. Telemetry is off unless explicitly enabled."),
+	), h.HandleTelemetryStatus)
+
+	addTool(ToolGroupAdmin, mcp.NewTool(
+		"replay_operations",
+		mcp.WithDescription("Re-apply the successful file changes recorded in an operation log onto another directory, in their original order. Recorded paths under 'from' are rebased onto 'to'; reads, failed calls and operations that cannot be replayed (trash restores, croc transfers, conversions, backups) are skipped."),
+		mcp.WithString("file",
+			mcp.Description("Operation log to replay: a file name in the operation log directory or a path within the allowed directories"),
+			mcp.Required(),
+		),
+		mcp.WithString("from",
+			mcp.Description("Absolute directory the recorded operations were made in"),
+			mcp.Required(),
+		),
+		mcp.WithString("to",
+			mcp.Description("Directory to apply the operations to"),
+			mcp.Required(),
+		),
+		mcp.WithBoolean("continue_on_error",
+			mcp.Description("Keep going after an operation fails instead of stopping (default: false)"),
+		),
+	), h.HandleReplayOperations)
+
+	addTool(ToolGroupAdmin, mcp.NewTool(
+		"self_update",
+		mcp.WithDescription("Check GitHub releases for a newer server version and install it in place of the running binary after verifying its checksum (and signature, when a public key is configured). The previous binary is kept for rollback; the new version runs after a restart."),
+		mcp.WithBoolean("check_only",
+			mcp.Description("Only report whether an update is available (default: false)"),
+		),
+		mcp.WithString("version",
+			mcp.Description("Install this release tag instead of the latest release, e.g. 'v1.2.3'; allows downgrades"),
+		),
+	), h.HandleSelfUpdate)
+
+	// Site-specific tools may not replace built-in tools or each other
+	for _, extra := range options.ExtraTools {
+		if extra.Tool.Name == "" || extra.Handler == nil {
+			return nil, fmt.Errorf("extra tool %q needs a name and a handler", extra.Tool.Name)
+		}
+		if registered[extra.Tool.Name] {
+			return nil, fmt.Errorf("extra tool %q conflicts with an existing tool", extra.Tool.Name)
+		}
+		addTool(ToolGroupExtra, extra.Tool, extra.Handler(h))
+	}
+
+	h.RecoverCrocProcesses()
+	if err := h.StartBackupScheduler(context.Background()); err != nil {
+		return nil, err
+	}
+	if err := h.StartFileWatcher(context.Background()); err != nil {
+		return nil, err
+	}
+	h.StartTelemetry()
+	h.StartScratchCleanup()
+
 	return s, nil
 }
@@ -1,9 +1,13 @@
 package filesystemserver_test
 
 import (
+	"context"
+	"path/filepath"
 	"testing"
 
 	"github.com/mark3labs/mcp-filesystem-server/filesystemserver"
+	"github.com/mark3labs/mcp-filesystem-server/filesystemserver/handler"
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -26,3 +30,71 @@ func TestReadMultipleFilesSchema(t *testing.T) {
 	_, ok = pathsMap["items"]
 	assert.True(t, ok)
 }
+
+func TestDisabledToolGroups(t *testing.T) {
+	fsserver, err := filesystemserver.NewFilesystemServer([]string{t.TempDir()},
+		filesystemserver.WithDisabledToolGroups(filesystemserver.ToolGroupCroc, filesystemserver.ToolGroupWrite),
+	)
+	require.NoError(t, err)
+
+	mcpClient := startTestClient(t, fsserver)
+	result, err := mcpClient.ListTools(context.Background(), mcp.ListToolsRequest{})
+	require.NoError(t, err)
+	names := make([]string, 0, len(result.Tools))
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name)
+	}
+	assert.Contains(t, names, "read_file")
+	assert.NotContains(t, names, "write_file")
+	assert.NotContains(t, names, "croc_send")
+	// Opt-in groups stay off unless enabled
+	assert.NotContains(t, names, "replay_operations")
+
+	_, err = filesystemserver.NewFilesystemServer([]string{t.TempDir()}, filesystemserver.WithDisabledToolGroups("nope"))
+	assert.Error(t, err)
+}
+
+func TestEnabledToolGroups(t *testing.T) {
+	fsserver, err := filesystemserver.NewFilesystemServer([]string{t.TempDir()},
+		filesystemserver.WithEnabledToolGroups(filesystemserver.ToolGroupAdmin),
+	)
+	require.NoError(t, err)
+
+	mcpClient := startTestClient(t, fsserver)
+	assert.NotNil(t, getTool(t, mcpClient, "replay_operations"))
+
+	_, err = filesystemserver.NewFilesystemServer([]string{t.TempDir()}, filesystemserver.WithEnabledToolGroups("nope"))
+	assert.Error(t, err)
+}
+
+func TestExtraTools(t *testing.T) {
+	dir := t.TempDir()
+	hello := filesystemserver.ExtraTool{
+		Tool: mcp.NewTool("site_hello", mcp.WithString("path", mcp.Required())),
+		Handler: func(h *handler.FilesystemHandler) handler.ToolHandlerFunc {
+			return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				path, err := h.ValidatePath(request.GetString("path", ""))
+				if err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+				return mcp.NewToolResultText("hello " + filepath.Base(path)), nil
+			}
+		},
+	}
+	fsserver, err := filesystemserver.NewFilesystemServer([]string{dir}, filesystemserver.WithExtraTools(hello))
+	require.NoError(t, err)
+
+	mcpClient := startTestClient(t, fsserver)
+	require.NotNil(t, getTool(t, mcpClient, "site_hello"))
+	result, err := mcpClient.CallTool(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "site_hello", Arguments: map[string]any{"path": filepath.Join(dir, "a.txt")}},
+	})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, "hello a.txt", result.Content[0].(mcp.TextContent).Text)
+
+	// Built-in tools cannot be replaced
+	hello.Tool.Name = "read_file"
+	_, err = filesystemserver.NewFilesystemServer([]string{dir}, filesystemserver.WithExtraTools(hello))
+	assert.ErrorContains(t, err, "conflicts")
+}
@@ -0,0 +1,36 @@
+package filesystemserver
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mark3labs/mcp-filesystem-server/filesystemserver/handler"
+)
+
+// Shutdowner drains a running server before the process exits. Pass it to
+// NewFilesystemServer with WithShutdowner.
+type Shutdowner struct {
+	mu sync.Mutex
+	h  *handler.FilesystemHandler
+}
+
+// attach connects the shutdowner to the server's handler
+func (s *Shutdowner) attach(h *handler.FilesystemHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.h = h
+}
+
+// Shutdown rejects new tool calls, waits until ctx is done for in-flight
+// calls and stops croc transfers and background work; see
+// handler.FilesystemHandler.Shutdown. It does nothing if the shutdowner was
+// never attached.
+func (s *Shutdowner) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	h := s.h
+	s.mu.Unlock()
+	if h == nil {
+		return nil
+	}
+	return h.Shutdown(ctx)
+}
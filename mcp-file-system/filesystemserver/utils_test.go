@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/mark3labs/mcp-filesystem-server/buildinfo"
 	"github.com/mark3labs/mcp-filesystem-server/filesystemserver"
 	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -33,7 +34,9 @@ func startTestClient(t *testing.T, fss *server.MCPServer) client.MCPClient {
 	result, err := mcpClient.Initialize(context.Background(), initRequest)
 	require.NoError(t, err)
 	assert.Equal(t, "secure-filesystem-server", result.ServerInfo.Name)
-	assert.Equal(t, filesystemserver.Version, result.ServerInfo.Version)
+	assert.Equal(t, buildinfo.Get().Version, result.ServerInfo.Version)
+	require.IsType(t, map[string]any{}, result.Meta[filesystemserver.BuildInfoMeta])
+	assert.Equal(t, buildinfo.Get().GoVersion, result.Meta[filesystemserver.BuildInfoMeta].(map[string]any)["go_version"])
 
 	return mcpClient
 }
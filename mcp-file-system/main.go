@@ -1,33 +1,220 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/mark3labs/mcp-filesystem-server/buildinfo"
+	"github.com/mark3labs/mcp-filesystem-server/config"
 	"github.com/mark3labs/mcp-filesystem-server/filesystemserver"
-	"github.com/mark3labs/mcp-go/server"
 )
 
 func main() {
 	// Parse command line arguments
-	if len(os.Args) < 2 {
+	configPath := flag.String("config", os.Getenv(config.EnvPath), "path to a YAML or TOML config file (env: "+config.EnvPath+")")
+	service := flag.String("service", "", "manage the Windows service: install, uninstall, start or stop")
+	update := flag.String("self-update", "", "update this binary from GitHub releases: check, apply [version] or rollback")
+	showVersion := flag.Bool("version", false, "print the version, commit, build date and Go version, then exit")
+	flag.Usage = func() {
 		fmt.Fprintf(
 			os.Stderr,
-			"Usage: %s <allowed-directory> [additional-directories...]\n",
-			os.Args[0],
+			"Usage: %s [--config file] [allowed-directory|smb://host/share ...]\n\nDirectories may also come from the config file or %s.\n",
+			os.Args[0], config.EnvAllowedDirs,
 		)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(buildinfo.Get())
+		return
+	}
+	if *update != "" {
+		if err := selfUpdate(context.Background(), *update, *configPath, flag.Args()); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if *service != "" {
+		if err := controlService(*service, *configPath, flag.Args()); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	// Started by the Windows service manager, the service runs the server
+	if isService, err := runService(*configPath, flag.Args()); isService {
+		if err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// SIGINT and SIGTERM drain in-flight work; a second signal exits immediately
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := run(ctx, *configPath, flag.Args(), nil)
+	if errors.Is(err, errUsage) {
+		flag.Usage()
 		os.Exit(1)
 	}
+	if err != nil {
+		fatal("server error", err)
+	}
+}
+
+// errUsage reports that no directories or tenants were configured
+var errUsage = errors.New("no allowed directories")
+
+// logSink replaces stderr as the log destination when no log file is
+// configured. Records are formatted into the sink by the configured
+// handler, which Wrap turns into the handler that delivers them.
+type logSink interface {
+	io.Writer
+	Wrap(slog.Handler) slog.Handler
+}
+
+// run starts the server described by the config file, environment and
+// command line directories and serves until ctx is cancelled
+func run(ctx context.Context, configPath string, dirs []string, sink logSink) error {
+	cfg, err := loadConfig(configPath, dirs)
+	if err != nil {
+		return err
+	}
+	if len(cfg.AllowedDirectories) == 0 && len(cfg.Tenants) == 0 {
+		return errUsage
+	}
+	if len(cfg.Tenants) > 0 && cfg.Transport.Type != config.TransportSSE && cfg.Transport.Type != config.TransportHTTP {
+		return errors.New("tenants require the sse or http transport")
+	}
+
+	var logOut io.Writer = os.Stderr
+	if sink != nil {
+		logOut = sink
+	}
+	if cfg.Logging.File != "" {
+		logFile, err := os.OpenFile(cfg.Logging.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		defer logFile.Close()
+		logOut = logFile
+	}
+	logger, err := cfg.Logging.NewLogger(logOut)
+	if err != nil {
+		return err
+	}
+	if sink != nil && cfg.Logging.File == "" {
+		logger = slog.New(sink.Wrap(logger.Handler()))
+	}
+	// The standard log package is routed through slog as well
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := setupTracing(context.Background(), cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
 
 	// Create and start the server
-	fss, err := filesystemserver.NewFilesystemServer(os.Args[1:])
+	reloader := &filesystemserver.Reloader{}
+	shutdowner := &filesystemserver.Shutdowner{}
+	fss, err := filesystemserver.NewFilesystemServer(cfg.Directories(),
+		filesystemserver.WithOptions(cfg.Options()),
+		filesystemserver.WithReloader(reloader),
+		filesystemserver.WithShutdowner(shutdowner),
+	)
 	if err != nil {
-		log.Fatalf("Failed to create server: %v", err)
+		return fmt.Errorf("failed to create server: %w", err)
+	}
+	tenants, err := newTenants(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create server: %w", err)
 	}
 
+	// reload re-reads the config file and environment and applies them to the running server
+	reload := func() error {
+		sdReloading()
+		defer sdNotify("READY=1")
+		cfg, err := loadConfig(configPath, dirs)
+		if err != nil {
+			return err
+		}
+		if err := reloader.Reload(cfg.Directories(), filesystemserver.WithOptions(cfg.Options())); err != nil {
+			return fmt.Errorf("failed to apply config: %w", err)
+		}
+		if err := reloadTenants(tenants, cfg); err != nil {
+			return fmt.Errorf("failed to apply config: %w", err)
+		}
+		slog.Info("configuration reloaded")
+		return nil
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	go func() {
+		for range hup {
+			if err := reload(); err != nil {
+				slog.Error("reload failed, keeping previous configuration", "error", err)
+			}
+		}
+	}()
+
 	// Serve requests
-	if err := server.ServeStdio(fss); err != nil {
-		log.Fatalf("Server error: %v", err)
+	servers := make([]tenantServer, 0, len(tenants))
+	shutdowns := []func(context.Context) error{shutdowner.Shutdown}
+	for _, t := range tenants {
+		servers = append(servers, t.tenantServer)
+		shutdowns = append(shutdowns, t.shutdowner.Shutdown)
+	}
+	drain := func(ctx context.Context) error {
+		return shutdownAll(ctx, shutdowns...)
+	}
+	err = serve(ctx, fss, servers, cfg.Transport, reload, drain)
+	// Flush spans, including those of the drained tool calls
+	if err := shutdownTracing(context.Background()); err != nil {
+		slog.Warn("failed to flush traces", "error", err)
+	}
+	if err != nil {
+		return err
+	}
+	slog.Info("server stopped")
+	return nil
+}
+
+// fatal logs err and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// loadConfig builds the configuration from the optional config file, the
+// environment and the directories given on the command line
+func loadConfig(path string, dirs []string) (*config.Config, error) {
+	cfg := &config.Config{}
+	if path != "" {
+		loaded, err := config.Load(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+		cfg = loaded
+	}
+	if err := cfg.ApplyEnv(); err != nil {
+		return nil, fmt.Errorf("invalid environment configuration: %w", err)
+	}
+	for _, dir := range dirs {
+		cfg.AllowedDirectories = append(cfg.AllowedDirectories, config.Directory{Path: dir})
 	}
+	return cfg, nil
 }
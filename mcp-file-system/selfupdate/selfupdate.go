@@ -0,0 +1,338 @@
+// Package selfupdate replaces the server binary with a release downloaded
+// from GitHub.
+package selfupdate
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// DefaultRepository publishes the official releases
+	DefaultRepository = "mark3labs/mcp-filesystem-server"
+	// DefaultAPIURL is the GitHub REST API
+	DefaultAPIURL = "https://api.github.com"
+
+	// checksumsAsset lists the SHA-256 of every release archive
+	checksumsAsset = "checksums.txt"
+	// signatureAsset holds the base64 Ed25519 signature of checksums.txt
+	signatureAsset = checksumsAsset + ".sig"
+	// binaryName is the executable inside the release archives
+	binaryName = "mcp-filesystem-server"
+	// maxDownload bounds the size of a downloaded asset
+	maxDownload = 256 * 1024 * 1024
+	// backupSuffix names the previous binary kept for Rollback
+	backupSuffix = ".old"
+)
+
+// Config selects where releases come from and how they are verified
+type Config struct {
+	// Repository is the GitHub owner/name publishing releases (default: mark3labs/mcp-filesystem-server)
+	Repository string `json:"repository,omitempty" yaml:"repository,omitempty"`
+	// APIURL is the GitHub API base URL, for GitHub Enterprise or a mirror (default: https://api.github.com)
+	APIURL string `json:"api_url,omitempty" yaml:"api_url,omitempty"`
+	// PublicKey is a base64 Ed25519 public key. When set, a release is only
+	// installed if its checksums.txt.sig verifies against checksums.txt.
+	PublicKey string `json:"public_key,omitempty" yaml:"public_key,omitempty"`
+}
+
+// Release is a published version and its downloadable assets
+type Release struct {
+	Version string
+	// Assets maps asset names to download URLs
+	Assets map[string]string
+}
+
+// Updater checks for and installs releases
+type Updater struct {
+	cfg       Config
+	publicKey ed25519.PublicKey
+	client    *http.Client
+}
+
+// New returns an Updater for cfg
+func New(cfg Config) (*Updater, error) {
+	if cfg.Repository == "" {
+		cfg.Repository = DefaultRepository
+	}
+	if cfg.APIURL == "" {
+		cfg.APIURL = DefaultAPIURL
+	}
+	u := &Updater{cfg: cfg, client: &http.Client{Timeout: 5 * time.Minute}}
+	if cfg.PublicKey != "" {
+		key, err := base64.StdEncoding.DecodeString(cfg.PublicKey)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, errors.New("public_key must be a base64 Ed25519 public key")
+		}
+		u.publicKey = key
+	}
+	return u, nil
+}
+
+// Newer reports whether version is a later release than current. A current
+// version that is not a release, such as "dev", is never considered older.
+func Newer(version, current string) bool {
+	version, current = canonical(version), canonical(current)
+	return semver.IsValid(version) && semver.IsValid(current) && semver.Compare(version, current) > 0
+}
+
+// canonical adds the "v" prefix semver expects
+func canonical(version string) string {
+	if version != "" && !strings.HasPrefix(version, "v") {
+		return "v" + version
+	}
+	return version
+}
+
+// Latest returns the most recent release, or the release tagged version
+// when it is not empty
+func (u *Updater) Latest(ctx context.Context, version string) (*Release, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/releases/latest", strings.TrimSuffix(u.cfg.APIURL, "/"), u.cfg.Repository)
+	if version != "" {
+		endpoint = fmt.Sprintf("%s/repos/%s/releases/tags/%s", strings.TrimSuffix(u.cfg.APIURL, "/"), u.cfg.Repository, canonical(version))
+	}
+	body, err := u.download(ctx, endpoint, "application/vnd.github+json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up release: %w", err)
+	}
+	var payload struct {
+		TagName string `json:"tag_name"`
+		Assets  []struct {
+			Name string `json:"name"`
+			URL  string `json:"browser_download_url"`
+		} `json:"assets"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("failed to decode release: %w", err)
+	}
+	release := &Release{Version: payload.TagName, Assets: make(map[string]string, len(payload.Assets))}
+	for _, asset := range payload.Assets {
+		release.Assets[asset.Name] = asset.URL
+	}
+	return release, nil
+}
+
+// ArchiveName returns the release archive for the running platform
+func ArchiveName() string {
+	if runtime.GOOS == "windows" {
+		return fmt.Sprintf("%s_%s_%s.zip", binaryName, runtime.GOOS, runtime.GOARCH)
+	}
+	return fmt.Sprintf("%s_%s_%s.tar.gz", binaryName, runtime.GOOS, runtime.GOARCH)
+}
+
+// Apply downloads the release archive for the running platform, verifies it
+// against checksums.txt (and its signature when a public key is configured)
+// and replaces executable with the binary it contains. The new binary must
+// run "--version" successfully before it is swapped in; the previous binary
+// is kept next to it for Rollback, and restored if the swap fails.
+func (u *Updater) Apply(ctx context.Context, release *Release, executable string) error {
+	archiveName := ArchiveName()
+	archiveURL, ok := release.Assets[archiveName]
+	if !ok {
+		return fmt.Errorf("release %s has no %s", release.Version, archiveName)
+	}
+	checksumsURL, ok := release.Assets[checksumsAsset]
+	if !ok {
+		return fmt.Errorf("release %s has no %s", release.Version, checksumsAsset)
+	}
+
+	checksums, err := u.download(ctx, checksumsURL, "")
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", checksumsAsset, err)
+	}
+	if u.publicKey != nil {
+		signatureURL, ok := release.Assets[signatureAsset]
+		if !ok {
+			return fmt.Errorf("release %s is not signed: no %s", release.Version, signatureAsset)
+		}
+		encoded, err := u.download(ctx, signatureURL, "")
+		if err != nil {
+			return fmt.Errorf("failed to download %s: %w", signatureAsset, err)
+		}
+		signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+		if err != nil || !ed25519.Verify(u.publicKey, checksums, signature) {
+			return fmt.Errorf("signature of %s does not verify", checksumsAsset)
+		}
+	}
+	want, err := checksumFor(checksums, archiveName)
+	if err != nil {
+		return err
+	}
+
+	archive, err := u.download(ctx, archiveURL, "")
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", archiveName, err)
+	}
+	sum := sha256.Sum256(archive)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", archiveName, want, got)
+	}
+	binary, err := extractBinary(archiveName, archive)
+	if err != nil {
+		return err
+	}
+	return replace(ctx, executable, binary)
+}
+
+// BackupPath is where Apply keeps the binary it replaced
+func BackupPath(executable string) string {
+	return executable + backupSuffix
+}
+
+// Rollback restores the binary that the last Apply replaced
+func Rollback(executable string) error {
+	backup := BackupPath(executable)
+	if _, err := os.Stat(backup); err != nil {
+		return fmt.Errorf("no previous binary to roll back to: %w", err)
+	}
+	return os.Rename(backup, executable)
+}
+
+// download fetches url, refusing bodies larger than maxDownload
+func (u *Updater) download(ctx context.Context, url, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDownload+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxDownload {
+		return nil, fmt.Errorf("GET %s: response too large", url)
+	}
+	return body, nil
+}
+
+// checksumFor finds the SHA-256 of name in a checksums.txt file
+func checksumFor(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[1] == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("%s has no entry for %s", checksumsAsset, name)
+}
+
+// extractBinary returns the server executable from a release archive
+func extractBinary(archiveName string, archive []byte) ([]byte, error) {
+	want := binaryName
+	if runtime.GOOS == "windows" {
+		want += ".exe"
+	}
+	if strings.HasSuffix(archiveName, ".zip") {
+		zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", archiveName, err)
+		}
+		for _, f := range zr.File {
+			if path.Base(f.Name) != want {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			return io.ReadAll(io.LimitReader(rc, maxDownload))
+		}
+		return nil, fmt.Errorf("%s does not contain %s", archiveName, want)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", archiveName, err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%s does not contain %s", archiveName, want)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", archiveName, err)
+		}
+		if hdr.Typeflag == tar.TypeReg && path.Base(hdr.Name) == want {
+			return io.ReadAll(io.LimitReader(tr, maxDownload))
+		}
+	}
+}
+
+// replace swaps binary in for executable, keeping the old one as a backup
+func replace(ctx context.Context, executable string, binary []byte) error {
+	info, err := os.Stat(executable)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(executable)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(executable)+".new-*")
+	if err != nil {
+		return fmt.Errorf("cannot write next to %s: %w", executable, err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(binary)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), info.Mode().Perm()|0o100)
+	}
+	if err != nil {
+		return err
+	}
+
+	// A binary that cannot even report its version is not installed
+	check, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if out, err := exec.CommandContext(check, tmp.Name(), "--version").CombinedOutput(); err != nil {
+		return fmt.Errorf("downloaded binary does not run: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	backup := BackupPath(executable)
+	os.Remove(backup)
+	if err := os.Rename(executable, backup); err != nil {
+		return fmt.Errorf("failed to move the current binary aside: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), executable); err != nil {
+		if rollbackErr := os.Rename(backup, executable); rollbackErr != nil {
+			return fmt.Errorf("failed to install the new binary (%v) and to restore the previous one: %w", err, rollbackErr)
+		}
+		return fmt.Errorf("failed to install the new binary, the previous one was restored: %w", err)
+	}
+	return nil
+}
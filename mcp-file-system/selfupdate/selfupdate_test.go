@@ -0,0 +1,141 @@
+//go:build !windows
+
+package selfupdate
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGitHub serves release v2.0.0 containing binary, with checksums.txt
+// covering checksummed and, when key is set, a signature made with it
+func fakeGitHub(t *testing.T, binary, checksummed []byte, key ed25519.PrivateKey) *httptest.Server {
+	t.Helper()
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: binaryName, Mode: 0755, Size: int64(len(binary)), Typeflag: tar.TypeReg}))
+	_, err := tw.Write(binary)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	if checksummed == nil {
+		checksummed = archive.Bytes()
+	}
+	sum := sha256.Sum256(checksummed)
+	checksums := []byte(fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), ArchiveName()))
+
+	files := map[string][]byte{
+		"/download/" + ArchiveName():   archive.Bytes(),
+		"/download/" + checksumsAsset: checksums,
+	}
+	if key != nil {
+		files["/download/"+signatureAsset] = []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(key, checksums)))
+	}
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/example/server/releases/latest" || r.URL.Path == "/repos/example/server/releases/tags/v2.0.0" {
+			var assets []map[string]string
+			for name := range files {
+				assets = append(assets, map[string]string{"name": filepath.Base(name), "browser_download_url": ts.URL + name})
+			}
+			json.NewEncoder(w).Encode(map[string]any{"tag_name": "v2.0.0", "assets": assets})
+			return
+		}
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// installed creates a fake current binary and returns its path
+func installed(t *testing.T) string {
+	t.Helper()
+	exe := filepath.Join(t.TempDir(), binaryName)
+	require.NoError(t, os.WriteFile(exe, []byte("#!/bin/sh\necho v1.0.0\n"), 0755))
+	return exe
+}
+
+func TestNewer(t *testing.T) {
+	assert.True(t, Newer("v1.2.0", "v1.1.9"))
+	assert.True(t, Newer("1.10.0", "v1.9.0"))
+	assert.False(t, Newer("v1.2.0", "v1.2.0"))
+	assert.False(t, Newer("v1.2.0-rc1", "v1.2.0"))
+	assert.False(t, Newer("v1.2.0", "dev"))
+}
+
+func TestApplyAndRollback(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	ts := fakeGitHub(t, []byte("#!/bin/sh\necho v2.0.0\n"), nil, priv)
+	updater, err := New(Config{Repository: "example/server", APIURL: ts.URL, PublicKey: base64.StdEncoding.EncodeToString(pub)})
+	require.NoError(t, err)
+
+	release, err := updater.Latest(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, "v2.0.0", release.Version)
+
+	exe := installed(t)
+	require.NoError(t, updater.Apply(context.Background(), release, exe))
+	content, err := os.ReadFile(exe)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "v2.0.0")
+
+	require.NoError(t, Rollback(exe))
+	content, err = os.ReadFile(exe)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "v1.0.0")
+	assert.NoFileExists(t, BackupPath(exe))
+}
+
+func TestApplyRejectsUnverifiedReleases(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	_, otherKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	working := []byte("#!/bin/sh\necho v2.0.0\n")
+
+	for name, tc := range map[string]struct {
+		ts      *httptest.Server
+		wantErr string
+	}{
+		"checksum mismatch": {fakeGitHub(t, working, []byte("something else"), priv), "checksum mismatch"},
+		"wrong signature":   {fakeGitHub(t, working, nil, otherKey), "signature of checksums.txt does not verify"},
+		"unsigned":          {fakeGitHub(t, working, nil, nil), "is not signed"},
+		"broken binary":     {fakeGitHub(t, []byte("#!/bin/sh\nexit 3\n"), nil, priv), "downloaded binary does not run"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			updater, err := New(Config{Repository: "example/server", APIURL: tc.ts.URL, PublicKey: base64.StdEncoding.EncodeToString(pub)})
+			require.NoError(t, err)
+			release, err := updater.Latest(context.Background(), "v2.0.0")
+			require.NoError(t, err)
+
+			exe := installed(t)
+			assert.ErrorContains(t, updater.Apply(context.Background(), release, exe), tc.wantErr)
+			content, err := os.ReadFile(exe)
+			require.NoError(t, err)
+			assert.Contains(t, string(content), "v1.0.0")
+		})
+	}
+}
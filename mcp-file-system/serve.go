@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-filesystem-server/config"
+	"github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// adminReloadPath is the HTTP endpoint that triggers a configuration reload
+const adminReloadPath = "/admin/reload"
+
+// shutdownTimeout bounds how long in-flight tool calls may run after a
+// shutdown signal before they are cancelled
+const shutdownTimeout = 10 * time.Second
+
+// tenantServer is the MCP server holding a tenant's view of the filesystem
+type tenantServer struct {
+	name  string
+	token string
+	mcp   *server.MCPServer
+}
+
+// serve runs the MCP server on the configured transport until ctx is
+// cancelled, the client closes stdin or the listener fails. drain is then
+// given shutdownTimeout to finish in-flight work while sessions stay open to
+// receive the results. In the HTTP modes requests bearing a tenant token are
+// served by that tenant's server, and reload is exposed at /admin/reload
+// when an admin token is configured. Under systemd the HTTP modes accept a
+// socket-activated listener and report readiness with sd_notify.
+func serve(ctx context.Context, fss *server.MCPServer, tenants []tenantServer, transport config.Transport, reload func() error, drain func(context.Context) error) error {
+	addr := transport.Address
+	if addr == "" {
+		addr = config.DefaultAddress
+	}
+
+	// sessions ends stdio input processing and open SSE streams once drained
+	sessions, closeSessions := context.WithCancel(context.Background())
+	defer closeSessions()
+
+	ln, err := systemdListener()
+	if err != nil {
+		return err
+	}
+
+	if transport.Type != config.TransportSSE && transport.Type != config.TransportHTTP {
+		if ln != nil {
+			ln.Close()
+			return errors.New("socket activation requires the sse or http transport")
+		}
+		errc := make(chan error, 1)
+		go func() {
+			errc <- server.NewStdioServer(fss).Listen(sessions, os.Stdin, os.Stdout)
+		}()
+		select {
+		case err = <-errc:
+		case <-ctx.Done():
+		}
+		return errors.Join(err, drainWithTimeout(drain))
+	}
+
+	if ln == nil {
+		if ln, err = net.Listen("tcp", addr); err != nil {
+			return errors.Join(err, drainWithTimeout(drain))
+		}
+	}
+	addr = ln.Addr().String()
+
+	pattern, h := mcpHandler(fss, transport)
+	if len(tenants) > 0 {
+		routes := make(map[string]http.Handler, len(tenants))
+		for _, tenant := range tenants {
+			_, routes[tenant.token] = mcpHandler(tenant.mcp, transport)
+		}
+		h = tenantRouter(routes)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(pattern, h)
+	if transport.Type == config.TransportSSE {
+		slog.Info("serving MCP over SSE", "address", addr, "tenants", len(tenants))
+	} else {
+		slog.Info("serving MCP over streamable HTTP", "address", addr, "path", pattern, "tenants", len(tenants))
+	}
+	if transport.AdminToken != "" {
+		mux.Handle(adminReloadPath, adminReloadHandler(transport.AdminToken, reload))
+	}
+
+	srv := &http.Server{
+		Handler:     mux,
+		BaseContext: func(net.Listener) context.Context { return sessions },
+	}
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.Serve(ln)
+	}()
+	sdNotify("READY=1")
+	go sdWatchdog(ctx)
+	select {
+	case err := <-errc:
+		return errors.Join(err, drainWithTimeout(drain))
+	case <-ctx.Done():
+	}
+
+	sdNotify("STOPPING=1")
+	err = drainWithTimeout(drain)
+	closeSessions()
+	stopCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := srv.Shutdown(stopCtx); err != nil {
+		srv.Close()
+	}
+	return err
+}
+
+// drainWithTimeout runs drain bounded by shutdownTimeout
+func drainWithTimeout(drain func(context.Context) error) error {
+	slog.Info("shutting down", "timeout", shutdownTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := drain(ctx); err != nil {
+		return fmt.Errorf("shutdown incomplete: %w", err)
+	}
+	return nil
+}
+
+// mcpHandler returns the HTTP handler for fss on the sse or http transport
+// and the pattern it is mounted at
+func mcpHandler(fss *server.MCPServer, transport config.Transport) (string, http.Handler) {
+	if transport.Type == config.TransportSSE {
+		opts := []server.SSEOption{server.WithSSEContextFunc(traceContextFromRequest)}
+		if transport.BaseURL != "" {
+			opts = append(opts, server.WithBaseURL(transport.BaseURL))
+		}
+		return "/", server.NewSSEServer(fss, opts...)
+	}
+	return "/mcp", server.NewStreamableHTTPServer(fss, server.WithHTTPContextFunc(traceContextFromRequest))
+}
+
+// tenantRouter dispatches each request to the handler of the tenant whose
+// token it carries in "Authorization: Bearer <token>" and rejects the rest
+func tenantRouter(routes map[string]http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var route http.Handler
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			// Compare against every token so timing does not reveal a match
+			for candidate, h := range routes {
+				if subtle.ConstantTimeCompare([]byte(token), []byte(candidate)) == 1 {
+					route = h
+				}
+			}
+		}
+		if route == nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		route.ServeHTTP(w, r)
+	})
+}
+
+// traceContextFromRequest continues a trace propagated in HTTP headers
+func traceContextFromRequest(ctx context.Context, r *http.Request) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(r.Header))
+}
+
+// adminReloadHandler runs reload for POST requests bearing the admin token
+func adminReloadHandler(token string, reload func() error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if err := reload(); err != nil {
+			slog.Error("reload failed, keeping previous configuration", "endpoint", adminReloadPath, "error", err)
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		w.Write([]byte("configuration reloaded\n"))
+	})
+}
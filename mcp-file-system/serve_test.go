@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminReloadHandler(t *testing.T) {
+	calls := 0
+	var reloadErr error
+	h := adminReloadHandler("s3cret", func() error {
+		calls++
+		return reloadErr
+	})
+
+	do := func(method, auth string) int {
+		req := httptest.NewRequest(method, adminReloadPath, nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusMethodNotAllowed, do(http.MethodGet, "Bearer s3cret"))
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodPost, ""))
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodPost, "Bearer wrong"))
+	assert.Equal(t, 0, calls)
+
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "Bearer s3cret"))
+	reloadErr = errors.New("bad config")
+	assert.Equal(t, http.StatusUnprocessableEntity, do(http.MethodPost, "Bearer s3cret"))
+	assert.Equal(t, 2, calls)
+}
+
+func TestTenantRouter(t *testing.T) {
+	route := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(name))
+		})
+	}
+	h := tenantRouter(map[string]http.Handler{"alice-token": route("alice"), "bob-token": route("bob")})
+
+	do := func(auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, "alice", do("Bearer alice-token").Body.String())
+	assert.Equal(t, "bob", do("Bearer bob-token").Body.String())
+	assert.Equal(t, http.StatusUnauthorized, do("").Code)
+	assert.Equal(t, http.StatusUnauthorized, do("Bearer carol-token").Code)
+	assert.Equal(t, http.StatusUnauthorized, do("alice-token").Code)
+}
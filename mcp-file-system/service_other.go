@@ -0,0 +1,15 @@
+//go:build !windows
+
+package main
+
+import "errors"
+
+// controlService is only available on Windows
+func controlService(command, configPath string, dirs []string) error {
+	return errors.New("--service is only supported on Windows")
+}
+
+// runService reports that the process is never a Windows service here
+func runService(configPath string, dirs []string) (bool, error) {
+	return false, nil
+}
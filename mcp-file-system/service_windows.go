@@ -0,0 +1,228 @@
+//go:build windows
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-filesystem-server/config"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// serviceName identifies the Windows service and its event log source
+const serviceName = "mcp-filesystem-server"
+
+// serviceEventID is the event ID of every event log entry
+const serviceEventID = 1
+
+// controlService installs, uninstalls, starts or stops the Windows service.
+// The installed service runs this executable with the given config file and
+// directories, which are made absolute.
+func controlService(command, configPath string, dirs []string) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the service manager: %w", err)
+	}
+	defer m.Disconnect()
+
+	if command == "install" {
+		return installService(m, configPath, dirs)
+	}
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed: %w", serviceName, err)
+	}
+	defer s.Close()
+	switch command {
+	case "uninstall":
+		if err := s.Delete(); err != nil {
+			return fmt.Errorf("failed to remove service: %w", err)
+		}
+		eventlog.Remove(serviceName)
+	case "start":
+		if err := s.Start(); err != nil {
+			return fmt.Errorf("failed to start service: %w", err)
+		}
+	case "stop":
+		if _, err := s.Control(svc.Stop); err != nil {
+			return fmt.Errorf("failed to stop service: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown service command %q (valid: install, uninstall, start, stop)", command)
+	}
+	return nil
+}
+
+// installService registers the service to start automatically at boot
+func installService(m *mgr.Mgr, configPath string, dirs []string) error {
+	// Services do not inherit the user's environment, so the settings
+	// have to come from the config file and command line
+	cfg, err := loadConfig(configPath, dirs)
+	if err != nil {
+		return err
+	}
+	if cfg.Transport.Type != config.TransportSSE && cfg.Transport.Type != config.TransportHTTP {
+		return errors.New("the service requires the sse or http transport")
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	var args []string
+	if configPath != "" {
+		abs, err := filepath.Abs(configPath)
+		if err != nil {
+			return err
+		}
+		args = append(args, "--config", abs)
+	}
+	for _, dir := range dirs {
+		if !strings.HasPrefix(dir, "smb://") {
+			if abs, err := filepath.Abs(dir); err == nil {
+				dir = abs
+			}
+		}
+		args = append(args, dir)
+	}
+
+	if s, err := m.OpenService(serviceName); err == nil {
+		s.Close()
+		return fmt.Errorf("service %s is already installed", serviceName)
+	}
+	s, err := m.CreateService(serviceName, exe, mgr.Config{
+		DisplayName: "MCP Filesystem Server",
+		Description: "Serves allowed directories to MCP clients over HTTP",
+		StartType:   mgr.StartAutomatic,
+	}, args...)
+	if err != nil {
+		return fmt.Errorf("failed to create service: %w", err)
+	}
+	defer s.Close()
+	if err := eventlog.InstallAsEventCreate(serviceName, eventlog.Error|eventlog.Warning|eventlog.Info); err != nil {
+		s.Delete()
+		return fmt.Errorf("failed to register event log source: %w", err)
+	}
+	return nil
+}
+
+// runService runs the server under the service manager and reports whether
+// the process was started as a service. Without a log file in the config,
+// logs go to the Windows event log.
+func runService(configPath string, dirs []string) (bool, error) {
+	isService, err := svc.IsWindowsService()
+	if err != nil || !isService {
+		return false, err
+	}
+	elog, err := eventlog.Open(serviceName)
+	if err != nil {
+		return true, err
+	}
+	defer elog.Close()
+	sink := &eventLogSink{log: elog}
+	if err := svc.Run(serviceName, &service{configPath: configPath, dirs: dirs, sink: sink}); err != nil {
+		elog.Error(serviceEventID, fmt.Sprintf("service failed: %v", err))
+		return true, err
+	}
+	return true, nil
+}
+
+// service handles control requests from the service manager
+type service struct {
+	configPath string
+	dirs       []string
+	sink       logSink
+}
+
+// Execute runs the server until it fails or the service manager stops it.
+// Stopping drains in-flight tool calls like SIGTERM does elsewhere.
+func (s *service) Execute(args []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- run(ctx, s.configPath, s.dirs, s.sink)
+	}()
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				slog.Error("server error", "error", err)
+				return true, 1
+			}
+			return false, 0
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				status <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending, WaitHint: uint32((shutdownTimeout + 5*time.Second).Milliseconds())}
+				cancel()
+			}
+		}
+	}
+}
+
+// eventLogSink delivers log records to the Windows event log, as
+// information, warning or error events according to their level
+type eventLogSink struct {
+	log *eventlog.Log
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// Write collects the formatted record being handled
+func (s *eventLogSink) Write(p []byte) (int, error) {
+	return s.buf.Write(p)
+}
+
+// Wrap returns a handler that formats records with h and reports them
+func (s *eventLogSink) Wrap(h slog.Handler) slog.Handler {
+	return &eventLogHandler{Handler: h, sink: s}
+}
+
+// eventLogHandler reports each record formatted by Handler as one event
+type eventLogHandler struct {
+	slog.Handler
+	sink *eventLogSink
+}
+
+func (h *eventLogHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.sink.mu.Lock()
+	defer h.sink.mu.Unlock()
+	h.sink.buf.Reset()
+	if err := h.Handler.Handle(ctx, r); err != nil {
+		return err
+	}
+	msg := strings.TrimSpace(h.sink.buf.String())
+	switch {
+	case r.Level >= slog.LevelError:
+		return h.sink.log.Error(serviceEventID, msg)
+	case r.Level >= slog.LevelWarn:
+		return h.sink.log.Warning(serviceEventID, msg)
+	default:
+		return h.sink.log.Info(serviceEventID, msg)
+	}
+}
+
+func (h *eventLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &eventLogHandler{Handler: h.Handler.WithAttrs(attrs), sink: h.sink}
+}
+
+func (h *eventLogHandler) WithGroup(name string) slog.Handler {
+	return &eventLogHandler{Handler: h.Handler.WithGroup(name), sink: h.sink}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSdNotify(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+	t.Setenv("NOTIFY_SOCKET", socket)
+
+	sdNotify("READY=1")
+	buf := make([]byte, 64)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "READY=1", string(buf[:n]))
+
+	sdReloading()
+	n, err = conn.Read(buf)
+	require.NoError(t, err)
+	assert.Regexp(t, `^RELOADING=1\nMONOTONIC_USEC=\d+$`, string(buf[:n]))
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "30000000")
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+	assert.Equal(t, 30*time.Second, watchdogInterval())
+
+	t.Setenv("WATCHDOG_PID", "1")
+	assert.Zero(t, watchdogInterval())
+}
+
+// TestSystemdListener passes a listening socket to a child process as fd 3,
+// the way systemd does, and checks that the child serves connections on it
+func TestSystemdListener(t *testing.T) {
+	if os.Getenv("TEST_SYSTEMD_LISTENER") == "1" {
+		os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+		os.Setenv("LISTEN_FDS", "1")
+		ln, err := systemdListener()
+		if err != nil || ln == nil {
+			os.Exit(2)
+		}
+		conn, err := ln.Accept()
+		if err != nil {
+			os.Exit(3)
+		}
+		conn.Write([]byte("activated"))
+		conn.Close()
+		os.Exit(0)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	f, err := ln.(*net.TCPListener).File()
+	require.NoError(t, err)
+	defer f.Close()
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSystemdListener$")
+	cmd.Env = append(os.Environ(), "TEST_SYSTEMD_LISTENER=1")
+	cmd.ExtraFiles = []*os.File{f}
+	require.NoError(t, cmd.Start())
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	got, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	assert.Equal(t, "activated", string(got))
+	assert.NoError(t, cmd.Wait())
+
+	// Not activated: LISTEN_PID names another process
+	t.Setenv("LISTEN_PID", "1")
+	t.Setenv("LISTEN_FDS", "1")
+	none, err := systemdListener()
+	assert.NoError(t, err)
+	assert.Nil(t, none)
+}
@@ -0,0 +1,22 @@
+//go:build !linux
+
+package main
+
+import (
+	"context"
+	"net"
+)
+
+// systemdListener always returns nil: socket activation is Linux only
+func systemdListener() (net.Listener, error) {
+	return nil, nil
+}
+
+// sdNotify does nothing outside Linux
+func sdNotify(string) {}
+
+// sdReloading does nothing outside Linux
+func sdReloading() {}
+
+// sdWatchdog does nothing outside Linux
+func sdWatchdog(context.Context) {}
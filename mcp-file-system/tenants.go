@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/mark3labs/mcp-filesystem-server/config"
+	"github.com/mark3labs/mcp-filesystem-server/filesystemserver"
+	"github.com/mark3labs/mcp-filesystem-server/filesystemserver/handler"
+)
+
+// tenant is a running tenant server with the handles used to reconfigure
+// and drain it
+type tenant struct {
+	tenantServer
+	reloader   *filesystemserver.Reloader
+	shutdowner *filesystemserver.Shutdowner
+}
+
+// newTenants creates an MCP server for every tenant in cfg. Tool calls are
+// logged with the tenant name.
+func newTenants(cfg *config.Config) ([]*tenant, error) {
+	tenants := make([]*tenant, 0, len(cfg.Tenants))
+	for _, t := range cfg.Tenants {
+		ts := &tenant{
+			reloader:   &filesystemserver.Reloader{},
+			shutdowner: &filesystemserver.Shutdowner{},
+		}
+		fss, err := filesystemserver.NewFilesystemServer(t.Directories(),
+			filesystemserver.WithOptions(cfg.TenantOptions(t)),
+			filesystemserver.WithHandlerOptions(handler.WithLogger(slog.Default().With("tenant", t.Name))),
+			filesystemserver.WithReloader(ts.reloader),
+			filesystemserver.WithShutdowner(ts.shutdowner),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", t.Name, err)
+		}
+		ts.tenantServer = tenantServer{name: t.Name, token: t.Token, mcp: fss}
+		tenants = append(tenants, ts)
+	}
+	return tenants, nil
+}
+
+// reloadTenants applies cfg to the running tenants. Tenants and their tokens
+// are fixed at startup; changes to them are logged and ignored.
+func reloadTenants(tenants []*tenant, cfg *config.Config) error {
+	byName := make(map[string]config.Tenant, len(cfg.Tenants))
+	for _, t := range cfg.Tenants {
+		byName[t.Name] = t
+	}
+	for _, ts := range tenants {
+		t, ok := byName[ts.name]
+		if !ok {
+			slog.Warn("tenant removed from configuration, restart to apply", "tenant", ts.name)
+			continue
+		}
+		delete(byName, ts.name)
+		if t.Token != ts.token {
+			slog.Warn("tenant token changed, restart to apply", "tenant", ts.name)
+		}
+		if err := ts.reloader.Reload(t.Directories(), filesystemserver.WithOptions(cfg.TenantOptions(t))); err != nil {
+			return fmt.Errorf("tenant %s: %w", ts.name, err)
+		}
+	}
+	for name := range byName {
+		slog.Warn("tenant added to configuration, restart to apply", "tenant", name)
+	}
+	return nil
+}
+
+// shutdownAll runs the shutdown functions concurrently so every server stops
+// accepting tool calls at once
+func shutdownAll(ctx context.Context, shutdowns ...func(context.Context) error) error {
+	errs := make([]error, len(shutdowns))
+	var wg sync.WaitGroup
+	for i, shutdown := range shutdowns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = shutdown(ctx)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
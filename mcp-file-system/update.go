@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mark3labs/mcp-filesystem-server/buildinfo"
+	"github.com/mark3labs/mcp-filesystem-server/config"
+	"github.com/mark3labs/mcp-filesystem-server/selfupdate"
+)
+
+// selfUpdate checks for, applies or rolls back an update of this binary.
+// apply installs the latest release, or the release named by the first
+// argument. Releases are found using the self_update settings of the
+// config file.
+func selfUpdate(ctx context.Context, command, configPath string, args []string) error {
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to locate the server binary: %w", err)
+	}
+	if command == "rollback" {
+		if err := selfupdate.Rollback(exe); err != nil {
+			return err
+		}
+		fmt.Printf("Restored the previous binary at %s\n", exe)
+		return nil
+	}
+	if command != "check" && command != "apply" {
+		return fmt.Errorf("unknown --self-update command %q (valid: check, apply, rollback)", command)
+	}
+
+	cfg := &config.Config{}
+	if configPath != "" {
+		if cfg, err = config.Load(configPath); err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+	}
+	updater, err := selfupdate.New(cfg.SelfUpdate)
+	if err != nil {
+		return err
+	}
+	var version string
+	if len(args) > 0 {
+		version = args[0]
+	}
+	release, err := updater.Latest(ctx, version)
+	if err != nil {
+		return err
+	}
+	current := buildinfo.Get().Version
+	if version == "" && !selfupdate.Newer(release.Version, current) {
+		fmt.Printf("No update available: running %s, latest release is %s\n", current, release.Version)
+		return nil
+	}
+	if command == "check" {
+		fmt.Printf("Update available: %s -> %s\n", current, release.Version)
+		return nil
+	}
+	if err := updater.Apply(ctx, release, exe); err != nil {
+		return err
+	}
+	fmt.Printf("Updated %s from %s to %s; the previous binary was kept as %s\n", exe, current, release.Version, selfupdate.BackupPath(exe))
+	return nil
+}